@@ -7,11 +7,14 @@ import (
 )
 
 type DecisionMakerConfig struct {
-	Server  ServerConfig  `mapstructure:"server"`
-	Logging LoggingConfig `mapstructure:"logging"`
-	Token   TokenConfig   `mapstructure:"token"`
-	MTLS    MTLSConfig    `mapstructure:"mtls"`
-	Daemon  DaemonConfig  `mapstructure:"daemon"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Logging      LoggingConfig      `mapstructure:"logging"`
+	Token        TokenConfig        `mapstructure:"token"`
+	MTLS         MTLSConfig         `mapstructure:"mtls"`
+	Daemon       DaemonConfig       `mapstructure:"daemon"`
+	State        StateConfig        `mapstructure:"state"`
+	Registration RegistrationConfig `mapstructure:"registration"`
+	Pull         PullConfig         `mapstructure:"pull"`
 }
 
 type DaemonConfig struct {
@@ -19,6 +22,39 @@ type DaemonConfig struct {
 	TimeoutSec int    `mapstructure:"timeout_sec"`
 }
 
+// StateConfig controls where the decision maker keeps the intents and node
+// policies it accepted, so they survive a restart. An empty Path keeps them
+// in memory only.
+type StateConfig struct {
+	Path string `mapstructure:"path"`
+}
+
+// RegistrationConfig makes the decision maker register itself with the
+// manager at ManagerURL and send a heartbeat every IntervalSeconds.
+// NodeName, AdvertiseHost and AdvertisePort default to the NODE_NAME and
+// POD_IP environment variables and the port of server.host. Registration is
+// off when ManagerURL is empty.
+type RegistrationConfig struct {
+	ManagerURL      string      `mapstructure:"manager_url"`
+	Token           SecretValue `mapstructure:"token"`
+	NodeName        string      `mapstructure:"node_name"`
+	AdvertiseHost   string      `mapstructure:"advertise_host"`
+	AdvertisePort   int         `mapstructure:"advertise_port"`
+	IntervalSeconds int         `mapstructure:"interval_seconds"`
+	MonitorEnabled  bool        `mapstructure:"monitor_enabled"`
+}
+
+// PullConfig makes the decision maker pull its node's intents, node policies
+// and runtime config from the manager at ManagerURL every IntervalSeconds,
+// for clusters where the manager cannot reach the node. Requests are signed
+// with token.rsa_private_key_pem. NodeName defaults to the NODE_NAME
+// environment variable. Pull mode is off when ManagerURL is empty.
+type PullConfig struct {
+	ManagerURL      string `mapstructure:"manager_url"`
+	NodeName        string `mapstructure:"node_name"`
+	IntervalSeconds int    `mapstructure:"interval_seconds"`
+}
+
 var (
 	dmConfig *DecisionMakerConfig
 )
@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -21,6 +24,36 @@ func (s SecretValue) Value() string {
 
 type ServerConfig struct {
 	Host string `mapstructure:"host"`
+	// TrustedProxies lists the IPs and CIDRs of the reverse proxies whose
+	// X-Forwarded-For header is believed. Without any, the header is ignored.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// TrustedProxyPrefixes parses TrustedProxies, turning plain IPs into
+// single-address prefixes.
+func (c ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, entry := range c.TrustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
 }
 
 type LoggingConfig struct {
@@ -30,13 +63,17 @@ type LoggingConfig struct {
 }
 
 type ManageConfig struct {
-	Server  ServerConfig  `mapstructure:"server"`
-	Logging LoggingConfig `mapstructure:"logging"`
-	MongoDB MongoDBConfig `mapstructure:"mongodb"`
-	Key     KeyConfig     `mapstructure:"key"`
-	Account AccountConfig `mapstructure:"account"`
-	K8S     K8SConfig     `mapstructure:"k8s"`
-	MTLS    MTLSConfig    `mapstructure:"mtls"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Logging        LoggingConfig        `mapstructure:"logging"`
+	MongoDB        MongoDBConfig        `mapstructure:"mongodb"`
+	Key            KeyConfig            `mapstructure:"key"`
+	Account        AccountConfig        `mapstructure:"account"`
+	K8S            K8SConfig            `mapstructure:"k8s"`
+	MTLS           MTLSConfig           `mapstructure:"mtls"`
+	Classifier     ClassifierConfig     `mapstructure:"classifier"`
+	KEDA           KEDAConfig           `mapstructure:"keda"`
+	Discovery      DiscoveryConfig      `mapstructure:"discovery"`
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"`
 }
 
 // MTLSConfig holds the mutual TLS configuration used for Manager ↔ Decision Maker communication.
@@ -50,6 +87,36 @@ type MTLSConfig struct {
 	CAPem      SecretValue `mapstructure:"ca_pem"`
 }
 
+// DiscoveryConfig controls how the manager finds decision makers. Decision
+// makers that register themselves with RegistrationToken are preferred; the
+// others are found by the DecisionMakerLabel pod label. A registration lapses
+// when no heartbeat arrives for HeartbeatTTLSeconds.
+type DiscoveryConfig struct {
+	DecisionMakerLabel  string      `mapstructure:"decision_maker_label"`
+	RegistrationToken   SecretValue `mapstructure:"registration_token"`
+	HeartbeatTTLSeconds int         `mapstructure:"heartbeat_ttl_seconds"`
+}
+
+// Label returns the key and value of DecisionMakerLabel, "app=decisionmaker"
+// unless configured. A label without "=" matches on the key alone.
+func (c DiscoveryConfig) Label() (key, value string) {
+	label := strings.TrimSpace(c.DecisionMakerLabel)
+	if label == "" {
+		return "app", "decisionmaker"
+	}
+	key, value, _ = strings.Cut(label, "=")
+	return strings.TrimSpace(key), strings.TrimSpace(value)
+}
+
+// HeartbeatTTL returns how long a registration stays valid without a
+// heartbeat, 60s unless configured.
+func (c DiscoveryConfig) HeartbeatTTL() time.Duration {
+	if c.HeartbeatTTLSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.HeartbeatTTLSeconds) * time.Second
+}
+
 type MongoDBConfig struct {
 	Database    string      `mapstructure:"database"`
 	CAPem       SecretValue `mapstructure:"ca_pem"`
@@ -76,10 +143,157 @@ type AccountConfig struct {
 	AdminPassword SecretValue `mapstructure:"admin_password"`
 }
 
+// K8SConfig holds the cluster the manager runs in, which also stores the
+// CRs, and the further clusters it federates. ClustersDir holds one
+// kubeconfig per further cluster, named after the cluster; ClusterName names
+// the manager's own cluster, "local" unless configured.
 type K8SConfig struct {
 	KubeConfigPath string `mapstructure:"kube_config_path"`
 	IsInCluster    bool   `mapstructure:"in_cluster"`
 	CRDNamespace   string `mapstructure:"crd_namespace"`
+	ClusterName    string `mapstructure:"cluster_name"`
+	ClustersDir    string `mapstructure:"clusters_dir"`
+}
+
+// LocalClusterName returns ClusterName, "local" unless configured.
+func (c K8SConfig) LocalClusterName() string {
+	if name := strings.TrimSpace(c.ClusterName); name != "" {
+		return name
+	}
+	return "local"
+}
+
+// ClassifierConfig controls how the adaptive classifier shards its state by
+// namespace and persists it, so that classifications survive restarts and
+// stay consistent across manager replicas. ReplicaID defaults to the host
+// name; AdvertiseAddress is the base URL other replicas forward metrics of
+// this replica's shards to. ForwardSecret is shared by all replicas and
+// authenticates forwarded metrics; without it metrics are not forwarded.
+type ClassifierConfig struct {
+	Persist                 bool        `mapstructure:"persist"`
+	ShardCount              int         `mapstructure:"shard_count"`
+	SnapshotIntervalSeconds int         `mapstructure:"snapshot_interval_seconds"`
+	LeaseSeconds            int         `mapstructure:"lease_seconds"`
+	ReplicaID               string      `mapstructure:"replica_id"`
+	AdvertiseAddress        string      `mapstructure:"advertise_address"`
+	ForwardSecret           SecretValue `mapstructure:"forward_secret"`
+}
+
+// SnapshotInterval returns how often classifier state is persisted, 30s
+// unless configured.
+func (c ClassifierConfig) SnapshotInterval() time.Duration {
+	if c.SnapshotIntervalSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.SnapshotIntervalSeconds) * time.Second
+}
+
+// Lease returns how long a replica keeps its shards without syncing, three
+// snapshot intervals unless configured.
+func (c ClassifierConfig) Lease() time.Duration {
+	if c.LeaseSeconds <= 0 {
+		return 3 * c.SnapshotInterval()
+	}
+	return time.Duration(c.LeaseSeconds) * time.Second
+}
+
+// ResolvedReplicaID returns ReplicaID, falling back to the host name.
+func (c ClassifierConfig) ResolvedReplicaID() string {
+	if c.ReplicaID != "" {
+		return c.ReplicaID
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// KEDAConfig controls the KEDA ScaledObjects the manager generates from the
+// scaling hints of PodSchedulingMetrics. PrometheusAddress is the server the
+// generated prometheus triggers query.
+type KEDAConfig struct {
+	Enabled                  bool   `mapstructure:"enabled"`
+	PrometheusAddress        string `mapstructure:"prometheus_address"`
+	PollingIntervalSeconds   int32  `mapstructure:"polling_interval_seconds"`
+	ReconcileIntervalSeconds int    `mapstructure:"reconcile_interval_seconds"`
+}
+
+// ReconcileInterval returns how often ScaledObjects are reconciled, 60s
+// unless configured.
+func (c KEDAConfig) ReconcileInterval() time.Duration {
+	if c.ReconcileIntervalSeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.ReconcileIntervalSeconds) * time.Second
+}
+
+// LeaderElectionConfig controls the Kubernetes Lease manager replicas
+// campaign for. Only the replica holding it runs the intent reconciler, the
+// runtime config rollouts and the other loops that must not run twice; every
+// replica serves the API. Identity defaults to the host name and
+// LeaseNamespace to the POD_NAMESPACE environment variable.
+type LeaderElectionConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	LeaseName            string `mapstructure:"lease_name"`
+	LeaseNamespace       string `mapstructure:"lease_namespace"`
+	Identity             string `mapstructure:"identity"`
+	LeaseDurationSeconds int    `mapstructure:"lease_duration_seconds"`
+	RenewDeadlineSeconds int    `mapstructure:"renew_deadline_seconds"`
+	RetryPeriodSeconds   int    `mapstructure:"retry_period_seconds"`
+}
+
+// ResolvedLeaseName returns LeaseName, "gthulhu-manager" unless configured.
+func (c LeaderElectionConfig) ResolvedLeaseName() string {
+	if c.LeaseName != "" {
+		return c.LeaseName
+	}
+	return "gthulhu-manager"
+}
+
+// ResolvedLeaseNamespace returns LeaseNamespace, falling back to the
+// POD_NAMESPACE environment variable and then to "default".
+func (c LeaderElectionConfig) ResolvedLeaseNamespace() string {
+	if c.LeaseNamespace != "" {
+		return c.LeaseNamespace
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// ResolvedIdentity returns Identity, falling back to the host name.
+func (c LeaderElectionConfig) ResolvedIdentity() string {
+	if c.Identity != "" {
+		return c.Identity
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// LeaseDuration returns how long followers wait before taking over a lease
+// that was not renewed, 15s unless configured.
+func (c LeaderElectionConfig) LeaseDuration() time.Duration {
+	if c.LeaseDurationSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(c.LeaseDurationSeconds) * time.Second
+}
+
+// RenewDeadline returns how long the leader retries renewing its lease
+// before it steps down, 10s unless configured.
+func (c LeaderElectionConfig) RenewDeadline() time.Duration {
+	if c.RenewDeadlineSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.RenewDeadlineSeconds) * time.Second
+}
+
+// RetryPeriod returns how often replicas try to acquire or renew the lease,
+// 2s unless configured.
+func (c LeaderElectionConfig) RetryPeriod() time.Duration {
+	if c.RetryPeriodSeconds <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.RetryPeriodSeconds) * time.Second
 }
 
 var managerCfg *ManageConfig
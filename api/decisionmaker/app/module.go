@@ -25,12 +25,23 @@ func ConfigModule(cfg config.DecisionMakerConfig) (fx.Option, error) {
 		fx.Provide(func(dmCfg config.DecisionMakerConfig) config.DaemonConfig {
 			return dmCfg.Daemon
 		}),
+		fx.Provide(func(dmCfg config.DecisionMakerConfig) config.StateConfig {
+			return dmCfg.State
+		}),
+		fx.Provide(func(dmCfg config.DecisionMakerConfig) config.RegistrationConfig {
+			return dmCfg.Registration
+		}),
+		fx.Provide(func(dmCfg config.DecisionMakerConfig) config.PullConfig {
+			return dmCfg.Pull
+		}),
 	), nil
 }
 
 func ServiceModule() (fx.Option, error) {
 	return fx.Options(
 		fx.Provide(service.NewService),
+		fx.Provide(service.NewRegistrar),
+		fx.Provide(service.NewPuller),
 	), nil
 }
 
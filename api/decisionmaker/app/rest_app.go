@@ -9,6 +9,7 @@ import (
 
 	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/decisionmaker/rest"
+	"github.com/Gthulhu/api/decisionmaker/service"
 	"github.com/Gthulhu/api/pkg/logger"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
@@ -32,6 +33,9 @@ func NewRestApp(configName string, configDirPath string) (*fx.App, error) {
 	app := fx.New(
 		handlerModule,
 		fx.Invoke(StartRestApp),
+		fx.Invoke(StartRegistration),
+		fx.Invoke(StartPull),
+		fx.Invoke(StartProcessEvents),
 	)
 	return app, nil
 }
@@ -71,6 +75,59 @@ func StartRestApp(lc fx.Lifecycle, cfg config.ServerConfig, mtlsCfg config.MTLSC
 	return nil
 }
 
+// StartRegistration registers the decision maker with the manager once the
+// REST server is started, and deregisters it before the server shuts down.
+func StartRegistration(lc fx.Lifecycle, registrar *service.Registrar) {
+	if registrar == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			registrar.Start(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := registrar.Stop(ctx); err != nil {
+				logger.Logger(ctx).Warn().Err(err).Msg("failed to deregister from manager")
+			}
+			return nil
+		},
+	})
+}
+
+// StartPull starts pulling from the manager once the decision maker starts,
+// and stops before it shuts down.
+func StartPull(lc fx.Lifecycle, puller *service.Puller) {
+	if puller == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			puller.Start(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			puller.Stop()
+			return nil
+		},
+	})
+}
+
+// StartProcessEvents follows process starts and exits once the decision
+// maker starts, and stops before it shuts down.
+func StartProcessEvents(lc fx.Lifecycle, svc *service.Service) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			svc.StartProcessEvents(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			svc.StopProcessEvents()
+			return nil
+		},
+	})
+}
+
 // startTLSServer starts the Echo server with mTLS: the server presents its own certificate and
 // requires the connecting client (Manager) to present a certificate signed by the shared CA.
 func startTLSServer(ctx context.Context, engine *echo.Echo, addr string, mtlsCfg config.MTLSConfig) error {
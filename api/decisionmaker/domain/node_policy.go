@@ -11,6 +11,7 @@ type NodePolicy struct {
 	CommandRegex  string `json:"commandRegex,omitempty"`
 	Priority      int    `json:"priority,omitempty"`
 	ExecutionTime int64  `json:"executionTime,omitempty"`
+	Precedence    int    `json:"precedence,omitempty"`
 }
 
 // ProcessInfo describes a running process discovered on the node, keyed by
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // PodProcess represents a process information within a pod
 type PodProcess struct {
 	PID         int    `json:"pid"`
@@ -17,6 +19,7 @@ type PodInfo struct {
 
 type Intent struct {
 	IntentID      string            `json:"intentID,omitempty"`
+	StrategyID    string            `json:"strategyID,omitempty"`
 	PodName       string            `json:"podName,omitempty"`
 	PodID         string            `json:"podID,omitempty"`
 	NodeID        string            `json:"nodeID,omitempty"`
@@ -25,6 +28,10 @@ type Intent struct {
 	Priority      int               `json:"priority,omitempty"`
 	ExecutionTime int64             `json:"executionTime,omitempty"`
 	PodLabels     map[string]string `json:"podLabels,omitempty"`
+	// Precedence and Specificity rank the intent against others that
+	// target the same process; see pkg/intentprecedence.
+	Precedence  int `json:"precedence,omitempty"`
+	Specificity int `json:"specificity,omitempty"`
 }
 
 type SchedulingIntents struct {
@@ -39,3 +46,52 @@ type LabelSelector struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
+
+const (
+	IntentSourceStrategy   = "strategy"
+	IntentSourceNodePolicy = "nodePolicy"
+)
+
+// IntentSource identifies the strategy intent or node policy a
+// SchedulingIntents was resolved from.
+type IntentSource struct {
+	Kind string `json:"kind"`
+	// ID is the intent ID for strategies and the policy ID for node
+	// policies.
+	ID         string `json:"id,omitempty"`
+	StrategyID string `json:"strategyID,omitempty"`
+	PodID      string `json:"podID,omitempty"`
+}
+
+// IntentConflict records a process that more than one intent targeted.
+// Only Winner is applied; Reason names the precedence rule that decided.
+type IntentConflict struct {
+	PID    int          `json:"pid"`
+	Winner IntentSource `json:"winner"`
+	Loser  IntentSource `json:"loser"`
+	Reason string       `json:"reason"`
+}
+
+const (
+	// IntentApplyResolved intents were resolved to processes and handed to
+	// the scheduler, which has not acknowledged them yet.
+	IntentApplyResolved          = "Resolved"
+	IntentApplyApplied           = "Applied"
+	IntentApplyNoMatchingProcess = "NoMatchingProcess"
+	IntentApplyFailed            = "Failed"
+)
+
+// IntentApplyStatus is what became of a strategy intent the last time the
+// scheduling intents were handed to the scheduler. PIDs are the processes
+// the intent resolved to, after precedence. The intent is Applied once the
+// scheduler acknowledged the watch revision it was resolved for;
+// LastAppliedAt is the last time that happened.
+type IntentApplyStatus struct {
+	IntentID      string    `json:"intentID"`
+	StrategyID    string    `json:"strategyID,omitempty"`
+	PodID         string    `json:"podID,omitempty"`
+	State         string    `json:"state"`
+	PIDs          []int     `json:"pids,omitempty"`
+	LastAppliedAt time.Time `json:"lastAppliedAt,omitempty"`
+	Message       string    `json:"message,omitempty"`
+}
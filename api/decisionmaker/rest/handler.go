@@ -126,7 +126,7 @@ func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
 	response := VersionResponse{
 		Message:   "BSS Metrics API Server",
 		Version:   "1.0.0",
-		Endpoints: "/health, /version, POST_/api/v1/intents, GET_/api/v1/scheduling/strategies",
+		Endpoints: "/health, /version, POST_/api/v1/intents, GET_/api/v1/scheduling/strategies, GET_/api/v1/scheduling/strategies/watch, POST_/api/v1/intents/ack",
 	}
 	h.JSONResponse(r.Context(), w, http.StatusOK, response)
 }
@@ -162,9 +162,15 @@ func (h *Handler) SetupRoutes(engine *echo.Echo) error {
 		apiV1 := api.Group("/v1")
 		// auth routes
 		apiV1.POST("/intents", h.echoHandler(h.HandleIntents), echo.WrapMiddleware(authMiddleware))
+		apiV1.PATCH("/intents", h.echoHandler(h.PatchIntents), echo.WrapMiddleware(authMiddleware))
 		apiV1.GET("/intents/merkle", h.echoHandler(h.GetIntentMerkleRoot), echo.WrapMiddleware(authMiddleware))
+		apiV1.GET("/intents/merkle/tree", h.echoHandler(h.GetIntentMerkleTree), echo.WrapMiddleware(authMiddleware))
+		apiV1.GET("/intents/conflicts", h.echoHandler(h.ListIntentConflicts), echo.WrapMiddleware(authMiddleware))
+		apiV1.GET("/intents/status", h.echoHandler(h.ListIntentStatuses), echo.WrapMiddleware(authMiddleware))
+		apiV1.POST("/intents/ack", h.echoHandler(h.AckIntents), echo.WrapMiddleware(authMiddleware))
 		apiV1.DELETE("/intents", h.echoHandler(h.DeleteIntent), echo.WrapMiddleware(authMiddleware))
 		apiV1.GET("/scheduling/strategies", h.echoHandler(h.ListIntents), echo.WrapMiddleware(authMiddleware))
+		apiV1.GET("/scheduling/strategies/watch", h.echoHandler(h.WatchIntents), echo.WrapMiddleware(authMiddleware))
 		// node-level scheduling policy routes
 		apiV1.POST("/node-intents", h.echoHandler(h.HandleNodePolicies), echo.WrapMiddleware(authMiddleware))
 		apiV1.GET("/node-intents/merkle", h.echoHandler(h.GetNodePolicyMerkleRoot), echo.WrapMiddleware(authMiddleware))
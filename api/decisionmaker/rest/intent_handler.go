@@ -2,6 +2,7 @@ package rest
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Gthulhu/api/decisionmaker/domain"
@@ -13,6 +14,8 @@ type HandleIntentsRequest struct {
 }
 
 type Intent struct {
+	IntentID      string            `json:"intentID,omitempty"`
+	StrategyID    string            `json:"strategyID,omitempty"`
 	PodName       string            `json:"podName,omitempty"`
 	PodID         string            `json:"podID,omitempty"`
 	NodeID        string            `json:"nodeID,omitempty"`
@@ -21,6 +24,8 @@ type Intent struct {
 	Priority      int               `json:"priority,omitempty"`
 	ExecutionTime int64             `json:"executionTime,omitempty"`
 	PodLabels     map[string]string `json:"podLabels,omitempty"`
+	Precedence    int               `json:"precedence,omitempty"`
+	Specificity   int               `json:"specificity,omitempty"`
 }
 
 func (h *Handler) HandleIntents(w http.ResponseWriter, r *http.Request) {
@@ -31,9 +36,21 @@ func (h *Handler) HandleIntents(w http.ResponseWriter, r *http.Request) {
 		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request payload", err)
 		return
 	}
-	intents := make([]*domain.Intent, 0, len(req.Intents))
-	for _, intent := range req.Intents {
+	intents := convertIntents(req.Intents)
+	err = h.Service.ProcessIntents(r.Context(), intents)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to process intents", err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse[EmptyResponse](nil))
+}
+
+func convertIntents(reqIntents []Intent) []*domain.Intent {
+	intents := make([]*domain.Intent, 0, len(reqIntents))
+	for _, intent := range reqIntents {
 		intents = append(intents, &domain.Intent{
+			IntentID:      intent.IntentID,
+			StrategyID:    intent.StrategyID,
 			PodName:       intent.PodName,
 			PodID:         intent.PodID,
 			NodeID:        intent.NodeID,
@@ -42,11 +59,34 @@ func (h *Handler) HandleIntents(w http.ResponseWriter, r *http.Request) {
 			Priority:      intent.Priority,
 			ExecutionTime: intent.ExecutionTime,
 			PodLabels:     intent.PodLabels,
+			Precedence:    intent.Precedence,
+			Specificity:   intent.Specificity,
 		})
 	}
-	err = h.Service.ProcessIntents(r.Context(), intents)
+	return intents
+}
+
+type PatchIntentsRequest struct {
+	Intents       []Intent `json:"intents"`
+	RemovedHashes []string `json:"removedHashes"`
+}
+
+// PatchIntents applies the intents added and removed since the last sync,
+// as found by the manager walking the intent Merkle tree.
+func (h *Handler) PatchIntents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req PatchIntentsRequest
+	err := h.JSONBind(r, &req)
 	if err != nil {
-		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to process intents", err)
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	err = h.Service.PatchIntents(ctx, &service.PatchIntentsOptions{
+		Upserts:       convertIntents(req.Intents),
+		RemovedHashes: req.RemovedHashes,
+	})
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to patch intents", err)
 		return
 	}
 	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse[EmptyResponse](nil))
@@ -82,16 +122,7 @@ func (h *Handler) ListIntents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	schedulingIntents := make([]*SchedulingIntents, 0, len(intents))
-	for _, intent := range intents {
-		schedulingIntents = append(schedulingIntents, &SchedulingIntents{
-			Priority:      intent.Priority,
-			ExecutionTime: intent.ExecutionTime,
-			PID:           intent.PID,
-			Selectors:     convertMapToLabelSelectors(intent.Selectors),
-			CommandRegex:  intent.CommandRegex,
-		})
-	}
+	schedulingIntents := convertSchedulingIntents(intents)
 
 	response := ListIntentsResponse{
 		Success:    true,
@@ -102,6 +133,73 @@ func (h *Handler) ListIntents(w http.ResponseWriter, r *http.Request) {
 	h.JSONResponse(ctx, w, http.StatusOK, response)
 }
 
+// IntentSource identifies the strategy intent or node policy on either side
+// of a conflict.
+type IntentSource struct {
+	Kind       string `json:"kind"`
+	ID         string `json:"id,omitempty"`
+	StrategyID string `json:"strategyID,omitempty"`
+	PodID      string `json:"podID,omitempty"`
+}
+
+// IntentConflict is a process targeted by more than one intent; only the
+// winner is returned by GET /api/v1/scheduling/strategies.
+type IntentConflict struct {
+	PID    int          `json:"pid"`
+	Winner IntentSource `json:"winner"`
+	Loser  IntentSource `json:"loser"`
+	Reason string       `json:"reason"`
+}
+
+type ListIntentConflictsResponse struct {
+	Conflicts []IntentConflict `json:"conflicts"`
+}
+
+// ListIntentConflicts returns the conflicts found the last time the
+// scheduler listed its intents.
+func (h *Handler) ListIntentConflicts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conflicts := h.Service.ListIntentConflicts()
+	resp := ListIntentConflictsResponse{Conflicts: make([]IntentConflict, 0, len(conflicts))}
+	for _, conflict := range conflicts {
+		resp.Conflicts = append(resp.Conflicts, IntentConflict{
+			PID:    conflict.PID,
+			Winner: IntentSource(conflict.Winner),
+			Loser:  IntentSource(conflict.Loser),
+			Reason: conflict.Reason,
+		})
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&resp))
+}
+
+// IntentApplyStatus is what became of an intent the last time the scheduler
+// listed its intents; see domain.IntentApplyStatus.
+type IntentApplyStatus struct {
+	IntentID      string    `json:"intentID"`
+	StrategyID    string    `json:"strategyID,omitempty"`
+	PodID         string    `json:"podID,omitempty"`
+	State         string    `json:"state"`
+	PIDs          []int     `json:"pids,omitempty"`
+	LastAppliedAt time.Time `json:"lastAppliedAt,omitempty"`
+	Message       string    `json:"message,omitempty"`
+}
+
+type ListIntentStatusesResponse struct {
+	Statuses []IntentApplyStatus `json:"statuses"`
+}
+
+// ListIntentStatuses returns the apply status of every intent as of the last
+// time the scheduler listed its intents.
+func (h *Handler) ListIntentStatuses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	statuses := h.Service.ListIntentStatuses()
+	resp := ListIntentStatusesResponse{Statuses: make([]IntentApplyStatus, 0, len(statuses))}
+	for _, status := range statuses {
+		resp.Statuses = append(resp.Statuses, IntentApplyStatus(*status))
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&resp))
+}
+
 type MerkleRootResponse struct {
 	RootHash string `json:"rootHash"`
 }
@@ -122,6 +220,66 @@ func (h *Handler) GetIntentMerkleRoot(w http.ResponseWriter, r *http.Request) {
 	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&MerkleRootResponse{RootHash: rootHash}))
 }
 
+// MerkleNode is a node of the intent Merkle tree. Leaf is set on the hash of
+// a single intent; a node without children that is not a leaf was cut off by
+// the requested depth.
+type MerkleNode struct {
+	Hash  string      `json:"hash"`
+	Leaf  bool        `json:"leaf,omitempty"`
+	Left  *MerkleNode `json:"left,omitempty"`
+	Right *MerkleNode `json:"right,omitempty"`
+}
+
+type MerkleTreeResponse struct {
+	// RootNode is nil when the tree is empty or rootHash is not found.
+	RootNode *MerkleNode `json:"rootNode"`
+}
+
+// GetIntentMerkleTree returns the subtree under the rootHash query parameter,
+// or under the root when it is empty, down to depth levels.
+func (h *Handler) GetIntentMerkleTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	depth := int64(defaultMerkleTreeDepth)
+	if raw := r.URL.Query().Get("depth"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 || parsed > maxMerkleTreeDepth {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid depth", err)
+			return
+		}
+		depth = parsed
+	}
+	resp, err := h.Service.TraverseIntentMerkleTree(ctx, &service.TraverseIntentMerkleTreeOptions{
+		RootHash: r.URL.Query().Get("rootHash"),
+		Depth:    depth,
+	})
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to get intent merkle tree", err)
+		return
+	}
+	var root *service.Node
+	if resp != nil {
+		root = resp.RootNode
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&MerkleTreeResponse{RootNode: convertMerkleNode(root)}))
+}
+
+const (
+	defaultMerkleTreeDepth = 4
+	maxMerkleTreeDepth     = 16
+)
+
+func convertMerkleNode(node *service.Node) *MerkleNode {
+	if node == nil {
+		return nil
+	}
+	return &MerkleNode{
+		Hash:  node.Hash,
+		Leaf:  node.Leaf,
+		Left:  convertMerkleNode(node.Left),
+		Right: convertMerkleNode(node.Right),
+	}
+}
+
 func convertMapToLabelSelectors(selectorMap []domain.LabelSelector) []LabelSelector {
 	labelSelectors := make([]LabelSelector, 0, len(selectorMap))
 	for _, sel := range selectorMap {
@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+)
+
+// intentWatchHeartbeat keeps idle watch connections from being closed by
+// proxies.
+var intentWatchHeartbeat = 30 * time.Second
+
+// SchedulingIntentsWatchEvent is the data of a watch event. A snapshot event
+// carries every scheduling intent in Upserted; a diff event carries the
+// intents that were added or changed and the ones that were removed.
+type SchedulingIntentsWatchEvent struct {
+	Revision uint64               `json:"revision"`
+	Upserted []*SchedulingIntents `json:"upserted"`
+	Removed  []*SchedulingIntents `json:"removed,omitempty"`
+}
+
+// AckIntentsRequest is the watch revision whose scheduling intents the
+// scheduler applied.
+type AckIntentsRequest struct {
+	Revision uint64 `json:"revision"`
+}
+
+// AckIntents records that the scheduler applied the scheduling intents of a
+// watch revision, which turns the intents resolved for it Applied.
+func (h *Handler) AckIntents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req AckIntentsRequest
+	if err := h.JSONBind(r, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+	if req.Revision == 0 {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid revision", nil)
+		return
+	}
+	h.Service.AckSchedulingIntents(req.Revision)
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse[EmptyResponse](nil))
+}
+
+// WatchIntents streams the resolved scheduling intents as server-sent events.
+// Each event id is its revision. A client that reconnects with the last
+// revision, in the Last-Event-ID header or the revision query parameter,
+// skips the snapshot when nothing changed in between.
+func (h *Handler) WatchIntents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rawRevision := r.URL.Query().Get("revision")
+	if rawRevision == "" {
+		rawRevision = r.Header.Get("Last-Event-ID")
+	}
+	var revision uint64
+	if rawRevision != "" {
+		parsed, err := strconv.ParseUint(rawRevision, 10, 64)
+		if err != nil {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid revision", err)
+			return
+		}
+		revision = parsed
+	}
+
+	events, err := h.Service.WatchSchedulingIntents(ctx, revision)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to watch scheduling intents", err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(intentWatchHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// The watcher fell behind; the client reconnects and gets a
+				// snapshot.
+				return
+			}
+			name := "diff"
+			if event.Snapshot {
+				name = "snapshot"
+			}
+			data, err := json.Marshal(SchedulingIntentsWatchEvent{
+				Revision: event.Revision,
+				Upserted: convertSchedulingIntents(event.Upserted),
+				Removed:  convertSchedulingIntents(event.Removed),
+			})
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, name, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func convertSchedulingIntents(intents []*domain.SchedulingIntents) []*SchedulingIntents {
+	result := make([]*SchedulingIntents, 0, len(intents))
+	for _, intent := range intents {
+		result = append(result, &SchedulingIntents{
+			Priority:      intent.Priority,
+			ExecutionTime: intent.ExecutionTime,
+			PID:           intent.PID,
+			Selectors:     convertMapToLabelSelectors(intent.Selectors),
+			CommandRegex:  intent.CommandRegex,
+		})
+	}
+	return result
+}
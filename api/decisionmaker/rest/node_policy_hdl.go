@@ -19,6 +19,7 @@ type NodePolicy struct {
 	CommandRegex  string `json:"commandRegex,omitempty"`
 	Priority      int    `json:"priority,omitempty"`
 	ExecutionTime int64  `json:"executionTime,omitempty"`
+	Precedence    int    `json:"precedence,omitempty"`
 }
 
 // HandleNodePolicies receives node-level scheduling policies from the
@@ -40,6 +41,7 @@ func (h *Handler) HandleNodePolicies(w http.ResponseWriter, r *http.Request) {
 			CommandRegex:  policy.CommandRegex,
 			Priority:      policy.Priority,
 			ExecutionTime: policy.ExecutionTime,
+			Precedence:    policy.Precedence,
 		})
 	}
 
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/intentprecedence"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+// intentCandidate is the claim of one intent or node policy on a set of
+// processes, before precedence decides which claim each process gets.
+type intentCandidate struct {
+	rank     intentprecedence.Rank
+	source   domain.IntentSource
+	template domain.SchedulingIntents
+	pids     []int
+}
+
+func (c *intentCandidate) schedulingIntent(pid int) *domain.SchedulingIntents {
+	schedulingIntent := c.template
+	schedulingIntent.PID = pid
+	return &schedulingIntent
+}
+
+type resolvedIntent struct {
+	source domain.IntentSource
+	intent *domain.SchedulingIntents
+}
+
+// resolveIntentPrecedence keeps a single claim per process. Candidates claim
+// their processes in precedence order; every process a candidate loses is
+// reported as a conflict. Results keep the order of the candidates.
+func resolveIntentPrecedence(candidates []*intentCandidate) ([]resolvedIntent, []*domain.IntentConflict) {
+	ordered := make([]*intentCandidate, len(candidates))
+	copy(ordered, candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		wins, _ := intentprecedence.Outranks(ordered[i].rank, ordered[j].rank)
+		return wins
+	})
+
+	owners := make(map[int]*intentCandidate)
+	won := make(map[*intentCandidate][]int, len(ordered))
+	var conflicts []*domain.IntentConflict
+	for _, candidate := range ordered {
+		for _, pid := range candidate.pids {
+			winner, taken := owners[pid]
+			if !taken {
+				owners[pid] = candidate
+				won[candidate] = append(won[candidate], pid)
+				continue
+			}
+			if winner.source == candidate.source {
+				continue
+			}
+			_, reason := intentprecedence.Outranks(winner.rank, candidate.rank)
+			conflicts = append(conflicts, &domain.IntentConflict{
+				PID:    pid,
+				Winner: winner.source,
+				Loser:  candidate.source,
+				Reason: reason,
+			})
+		}
+	}
+
+	var resolved []resolvedIntent
+	for _, candidate := range candidates {
+		for _, pid := range won[candidate] {
+			resolved = append(resolved, resolvedIntent{source: candidate.source, intent: candidate.schedulingIntent(pid)})
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].PID < conflicts[j].PID })
+	return resolved, conflicts
+}
+
+// applyIntentCandidates resolves the candidates, replaces the
+// schedulingIntentsMap with the winning strategy entries and returns every
+// winning SchedulingIntents with the conflicts found.
+func (svc *Service) applyIntentCandidates(ctx context.Context, candidates []*intentCandidate) ([]*domain.SchedulingIntents, []*domain.IntentConflict) {
+	resolved, conflicts := resolveIntentPrecedence(candidates)
+	svc.schedulingIntentsMap.Clear()
+	schedulingIntents := make([]*domain.SchedulingIntents, 0, len(resolved))
+	for _, r := range resolved {
+		if r.source.Kind == domain.IntentSourceStrategy {
+			key := fmt.Sprintf("%s-%d", r.source.PodID, r.intent.PID)
+			logger.Logger(ctx).Info().Msgf("Created SchedulingIntent: %+v for %s", r.intent, key)
+			svc.schedulingIntentsMap.Store(key, []*domain.SchedulingIntents{r.intent})
+		}
+		schedulingIntents = append(schedulingIntents, r.intent)
+	}
+	for _, conflict := range conflicts {
+		logger.Logger(ctx).Info().Msgf("PID %d is targeted by %s %s and %s %s, keeping the first (%s)",
+			conflict.PID, conflict.Winner.Kind, conflictSourceID(conflict.Winner), conflict.Loser.Kind, conflictSourceID(conflict.Loser), conflict.Reason)
+	}
+	return schedulingIntents, conflicts
+}
+
+func conflictSourceID(source domain.IntentSource) string {
+	if source.StrategyID != "" {
+		return source.StrategyID
+	}
+	return source.ID
+}
+
+// ListIntentConflicts returns the conflicts found the last time the
+// scheduling intents were listed, so the Manager can report the losing
+// intents.
+func (svc *Service) ListIntentConflicts() []*domain.IntentConflict {
+	svc.intentConflictsMu.RLock()
+	defer svc.intentConflictsMu.RUnlock()
+	conflicts := make([]*domain.IntentConflict, len(svc.intentConflicts))
+	copy(conflicts, svc.intentConflicts)
+	return conflicts
+}
+
+func (svc *Service) setIntentConflicts(conflicts []*domain.IntentConflict) {
+	svc.intentConflictsMu.Lock()
+	svc.intentConflicts = conflicts
+	svc.intentConflictsMu.Unlock()
+}
+
+func intentRank(intent *domain.Intent) intentprecedence.Rank {
+	key := intent.StrategyID
+	if key == "" {
+		key = intent.IntentID
+	}
+	if key == "" {
+		key = intentSortKey(intent)
+	}
+	return intentprecedence.Rank{
+		Precedence:  intent.Precedence,
+		Level:       intentprecedence.LevelPod,
+		Specificity: intent.Specificity,
+		Key:         key,
+	}
+}
+
+func nodePolicyRank(policy *domain.NodePolicy) intentprecedence.Rank {
+	key := policy.PolicyID
+	if key == "" {
+		key = nodePolicySortKey(policy)
+	}
+	return intentprecedence.Rank{
+		Precedence: policy.Precedence,
+		Level:      intentprecedence.LevelNode,
+		Key:        key,
+	}
+}
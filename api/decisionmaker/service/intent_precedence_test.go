@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/intentprecedence"
+	"github.com/Gthulhu/api/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSchedulingIntentsKeepsOneIntentPerPID(t *testing.T) {
+	svc := &Service{
+		schedulingIntentsMap: util.NewGenericMap[string, []*domain.SchedulingIntents](),
+	}
+	intents := []*domain.Intent{
+		{IntentID: "i-broad", StrategyID: "65a000000000000000000001", PodID: "pod-id", CommandRegex: ".*", Priority: 1},
+		{IntentID: "i-narrow", StrategyID: "65a000000000000000000002", PodID: "pod-id", CommandRegex: "^python3$", Priority: 2, Specificity: 2},
+	}
+	podInfos := map[string]*domain.PodInfo{
+		"pod-id": {
+			Processes: []domain.PodProcess{
+				{PID: 101, Command: "python3"},
+				{PID: 102, Command: "sh"},
+			},
+		},
+	}
+
+	got := svc.resolveSchedulingIntents(context.Background(), intents, podInfos)
+
+	require.Len(t, got, 2)
+	byPID := map[int]int{}
+	for _, schedulingIntent := range got {
+		byPID[schedulingIntent.PID] = schedulingIntent.Priority
+	}
+	assert.Equal(t, map[int]int{101: 2, 102: 1}, byPID, "the more specific strategy must win pid 101")
+}
+
+func TestApplyIntentCandidatesPodOutranksNodePolicy(t *testing.T) {
+	svc := &Service{
+		schedulingIntentsMap: util.NewGenericMap[string, []*domain.SchedulingIntents](),
+		processSource:        &fakeProcessSource{snapshot: map[int]string{100: "kthreadd", 101: "python3"}},
+	}
+	require.NoError(t, svc.ProcessNodePolicies(context.Background(), []*domain.NodePolicy{
+		{PolicyID: "p1", NodeID: "node-a", CommandRegex: "^(kthreadd|python3)$", Priority: 5},
+	}))
+	candidates, err := svc.nodePolicyCandidates(context.Background())
+	require.NoError(t, err)
+	pod := &intentCandidate{
+		rank:     intentRank(&domain.Intent{StrategyID: "65a000000000000000000001"}),
+		source:   domain.IntentSource{Kind: domain.IntentSourceStrategy, ID: "i1", StrategyID: "65a000000000000000000001", PodID: "pod-id"},
+		template: domain.SchedulingIntents{Priority: 1},
+		pids:     []int{101},
+	}
+
+	got, conflicts := svc.applyIntentCandidates(context.Background(), append([]*intentCandidate{pod}, candidates...))
+	svc.setIntentConflicts(conflicts)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, 101, got[0].PID)
+	assert.Equal(t, 1, got[0].Priority, "pod-level intents outrank node policies")
+	assert.Equal(t, 100, got[1].PID)
+	assert.Equal(t, 5, got[1].Priority)
+	require.Len(t, svc.ListIntentConflicts(), 1)
+	conflict := svc.ListIntentConflicts()[0]
+	assert.Equal(t, 101, conflict.PID)
+	assert.Equal(t, intentprecedence.ReasonLevel, conflict.Reason)
+	assert.Equal(t, domain.IntentSourceStrategy, conflict.Winner.Kind)
+	assert.Equal(t, domain.IntentSource{Kind: domain.IntentSourceNodePolicy, ID: "p1"}, conflict.Loser)
+	_, cached := svc.schedulingIntentsMap.Load("pod-id-101")
+	assert.True(t, cached)
+}
+
+func TestResolveIntentPrecedenceExplicitPrecedence(t *testing.T) {
+	low := &intentCandidate{
+		rank:     intentprecedence.Rank{Level: intentprecedence.LevelPod, Specificity: 3, Key: "a"},
+		source:   domain.IntentSource{Kind: domain.IntentSourceStrategy, ID: "low"},
+		template: domain.SchedulingIntents{Priority: 1},
+		pids:     []int{1, 2},
+	}
+	high := &intentCandidate{
+		rank:     intentprecedence.Rank{Precedence: 10, Level: intentprecedence.LevelNode, Key: "b"},
+		source:   domain.IntentSource{Kind: domain.IntentSourceNodePolicy, ID: "high"},
+		template: domain.SchedulingIntents{Priority: 9},
+		pids:     []int{2},
+	}
+
+	resolved, conflicts := resolveIntentPrecedence([]*intentCandidate{low, high})
+
+	require.Len(t, resolved, 2)
+	assert.Equal(t, 1, resolved[0].intent.PID)
+	assert.Equal(t, "low", resolved[0].source.ID)
+	assert.Equal(t, 2, resolved[1].intent.PID)
+	assert.Equal(t, "high", resolved[1].source.ID)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, intentprecedence.ReasonPrecedence, conflicts[0].Reason)
+	assert.Equal(t, "low", conflicts[0].Loser.ID)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+)
+
+// recordIntentStatuses sets the apply status of every cached intent from one
+// resolution of the scheduling intents, as handed to the scheduler. Intents
+// that resolved to processes are Resolved until the scheduler acknowledges
+// them, see AckSchedulingIntents. A non-nil resolveErr means the pod
+// processes could not be read, so every intent failed. Statuses of intents
+// that are no longer cached are dropped.
+func (svc *Service) recordIntentStatuses(intents []*domain.Intent, podInfos map[string]*domain.PodInfo, candidates []*intentCandidate, conflicts []*domain.IntentConflict, resolveErr error) {
+	matched := make(map[string]map[int]struct{})
+	for _, candidate := range candidates {
+		if candidate.source.Kind != domain.IntentSourceStrategy {
+			continue
+		}
+		pids, ok := matched[candidate.source.ID]
+		if !ok {
+			pids = make(map[int]struct{})
+			matched[candidate.source.ID] = pids
+		}
+		for _, pid := range candidate.pids {
+			pids[pid] = struct{}{}
+		}
+	}
+	for _, conflict := range conflicts {
+		if conflict.Loser.Kind == domain.IntentSourceStrategy {
+			delete(matched[conflict.Loser.ID], conflict.PID)
+		}
+	}
+
+	svc.intentStatusMu.Lock()
+	defer svc.intentStatusMu.Unlock()
+	statuses := make(map[string]*domain.IntentApplyStatus, len(intents))
+	for _, intent := range intents {
+		if intent.IntentID == "" {
+			continue
+		}
+		status := &domain.IntentApplyStatus{
+			IntentID:   intent.IntentID,
+			StrategyID: intent.StrategyID,
+			PodID:      intent.PodID,
+		}
+		if prev, ok := svc.intentStatuses[intent.IntentID]; ok {
+			status.LastAppliedAt = prev.LastAppliedAt
+		}
+		statuses[intent.IntentID] = status
+
+		if resolveErr != nil {
+			status.State = domain.IntentApplyFailed
+			status.Message = resolveErr.Error()
+			continue
+		}
+		if _, err := svc.compileCommandRegex(intent.CommandRegex); err != nil {
+			status.State = domain.IntentApplyFailed
+			status.Message = fmt.Sprintf("invalid commandRegex: %v", err)
+			continue
+		}
+		for pid := range matched[intent.IntentID] {
+			status.PIDs = append(status.PIDs, pid)
+		}
+		if len(status.PIDs) > 0 {
+			sort.Ints(status.PIDs)
+			status.State = domain.IntentApplyResolved
+			continue
+		}
+		status.State = domain.IntentApplyNoMatchingProcess
+		switch podInfo := podInfos[intent.PodID]; {
+		case podInfo == nil || len(podInfo.Processes) == 0:
+			status.Message = "no processes of the pod found on the node"
+		case len(matched[intent.IntentID]) == 0 && hasIntentConflictLoser(conflicts, intent.IntentID):
+			status.Message = "all matching processes are taken by higher-ranked intents"
+		default:
+			status.Message = "no process of the pod matches commandRegex"
+		}
+	}
+	svc.intentStatuses = statuses
+	// Only a resolution for the watch gets a revision, see
+	// stampIntentStatuses.
+	svc.intentStatusRevision = 0
+}
+
+// stampIntentStatuses records that the last resolution is the one the watch
+// revision carries. Its intents are applied right away when the scheduler
+// already acknowledged that revision.
+func (svc *Service) stampIntentStatuses(revision uint64, now time.Time) {
+	svc.intentStatusMu.Lock()
+	defer svc.intentStatusMu.Unlock()
+	svc.intentStatusRevision = revision
+	svc.markIntentsAppliedLocked(now)
+}
+
+// AckSchedulingIntents records that the scheduler applied the scheduling
+// intents of a watch revision. The Resolved intents become Applied when that
+// revision is the one they were resolved for or a later one.
+func (svc *Service) AckSchedulingIntents(revision uint64) {
+	svc.intentStatusMu.Lock()
+	defer svc.intentStatusMu.Unlock()
+	if revision > svc.intentAckedRevision {
+		svc.intentAckedRevision = revision
+	}
+	svc.markIntentsAppliedLocked(time.Now())
+}
+
+func (svc *Service) markIntentsAppliedLocked(now time.Time) {
+	if svc.intentStatusRevision == 0 || svc.intentAckedRevision < svc.intentStatusRevision {
+		return
+	}
+	for _, status := range svc.intentStatuses {
+		if status.State == domain.IntentApplyResolved {
+			status.State = domain.IntentApplyApplied
+			status.LastAppliedAt = now
+		}
+	}
+}
+
+func hasIntentConflictLoser(conflicts []*domain.IntentConflict, intentID string) bool {
+	for _, conflict := range conflicts {
+		if conflict.Loser.Kind == domain.IntentSourceStrategy && conflict.Loser.ID == intentID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListIntentStatuses returns the apply status of every intent as of the last
+// time the scheduler listed its intents, sorted by intent ID. Intents the
+// scheduler has not listed since they were received have no status yet.
+func (svc *Service) ListIntentStatuses() []*domain.IntentApplyStatus {
+	svc.intentStatusMu.RLock()
+	defer svc.intentStatusMu.RUnlock()
+	statuses := make([]*domain.IntentApplyStatus, 0, len(svc.intentStatuses))
+	for _, status := range svc.intentStatuses {
+		copied := *status
+		copied.PIDs = append([]int(nil), status.PIDs...)
+		statuses = append(statuses, &copied)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].IntentID < statuses[j].IntentID })
+	return statuses
+}
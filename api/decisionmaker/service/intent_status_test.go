@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordIntentStatuses(t *testing.T) {
+	svc := &Service{}
+	intents := []*domain.Intent{
+		{IntentID: "applied", PodID: "pod-a", CommandRegex: "^nginx$"},
+		{IntentID: "lost", PodID: "pod-a", CommandRegex: "^nginx"},
+		{IntentID: "no-match", PodID: "pod-a", CommandRegex: "^redis$"},
+		{IntentID: "no-pod", PodID: "pod-b"},
+		{IntentID: "invalid", PodID: "pod-a", CommandRegex: "("},
+	}
+	podInfos := map[string]*domain.PodInfo{
+		"pod-a": {PodUID: "pod-a", Processes: []domain.PodProcess{{PID: 11, Command: "nginx"}, {PID: 10, Command: "nginx"}}},
+	}
+	source := func(id string) domain.IntentSource {
+		return domain.IntentSource{Kind: domain.IntentSourceStrategy, ID: id, PodID: "pod-a"}
+	}
+	candidates := []*intentCandidate{
+		{source: source("applied"), pids: []int{11, 10}},
+		{source: source("lost"), pids: []int{10, 11}},
+	}
+	conflicts := []*domain.IntentConflict{
+		{PID: 10, Winner: source("applied"), Loser: source("lost")},
+		{PID: 11, Winner: source("applied"), Loser: source("lost")},
+	}
+	svc.recordIntentStatuses(intents, podInfos, candidates, conflicts, nil)
+
+	statuses := make(map[string]*domain.IntentApplyStatus)
+	for _, status := range svc.ListIntentStatuses() {
+		statuses[status.IntentID] = status
+	}
+	require.Len(t, statuses, 5)
+	// Resolved, but not acknowledged by the scheduler yet.
+	assert.Equal(t, domain.IntentApplyResolved, statuses["applied"].State)
+	assert.Equal(t, []int{10, 11}, statuses["applied"].PIDs)
+	assert.True(t, statuses["applied"].LastAppliedAt.IsZero())
+	assert.Equal(t, domain.IntentApplyNoMatchingProcess, statuses["lost"].State)
+	assert.Contains(t, statuses["lost"].Message, "higher-ranked")
+	assert.Equal(t, domain.IntentApplyNoMatchingProcess, statuses["no-match"].State)
+	assert.Contains(t, statuses["no-match"].Message, "commandRegex")
+	assert.Contains(t, statuses["no-pod"].Message, "no processes")
+	assert.Equal(t, domain.IntentApplyFailed, statuses["invalid"].State)
+
+	// Polled intents have no revision to acknowledge.
+	svc.AckSchedulingIntents(7)
+	assert.Equal(t, domain.IntentApplyResolved, intentStatus(t, svc, "applied").State)
+
+	first := time.Now()
+	svc.stampIntentStatuses(8, first)
+	assert.Equal(t, domain.IntentApplyResolved, intentStatus(t, svc, "applied").State)
+	svc.AckSchedulingIntents(8)
+	applied := intentStatus(t, svc, "applied")
+	assert.Equal(t, domain.IntentApplyApplied, applied.State)
+	assert.False(t, applied.LastAppliedAt.Before(first))
+	assert.Equal(t, domain.IntentApplyNoMatchingProcess, intentStatus(t, svc, "lost").State)
+
+	// A resolution for an acknowledged revision is applied right away.
+	svc.recordIntentStatuses(intents, podInfos, candidates, conflicts, nil)
+	svc.stampIntentStatuses(8, first.Add(time.Second))
+	assert.Equal(t, first.Add(time.Second), intentStatus(t, svc, "applied").LastAppliedAt)
+	first = first.Add(time.Second)
+
+	// A failed resolution keeps the last apply time.
+	svc.recordIntentStatuses(intents[:1], nil, nil, nil, errors.New("read /proc"))
+	statuses = map[string]*domain.IntentApplyStatus{}
+	for _, status := range svc.ListIntentStatuses() {
+		statuses[status.IntentID] = status
+	}
+	require.Len(t, statuses, 1)
+	assert.Equal(t, domain.IntentApplyFailed, statuses["applied"].State)
+	assert.Equal(t, "read /proc", statuses["applied"].Message)
+	assert.Empty(t, statuses["applied"].PIDs)
+	assert.Equal(t, first, statuses["applied"].LastAppliedAt)
+}
+
+func intentStatus(t *testing.T, svc *Service, intentID string) *domain.IntentApplyStatus {
+	t.Helper()
+	for _, status := range svc.ListIntentStatuses() {
+		if status.IntentID == intentID {
+			return status
+		}
+	}
+	t.Fatalf("no status for intent %s", intentID)
+	return nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+// intentWatchResyncInterval is how often the scheduling intents are resolved
+// again while someone watches them, to pick up processes that started or
+// exited, when the process source has no running change feed. With one they
+// are resolved again after processes changed instead. Intent and node policy
+// changes are pushed right away.
+var intentWatchResyncInterval = 10 * time.Second
+
+// intentWatchProcessSettle is how long process changes are collected before
+// the intents are resolved again, so a burst of starts and exits costs one
+// refresh.
+var intentWatchProcessSettle = 200 * time.Millisecond
+
+// intentWatchBuffer is how many events a watcher may fall behind before it is
+// dropped; it reconnects and starts over from a snapshot.
+const intentWatchBuffer = 16
+
+// IntentWatchEvent is a change of the resolved scheduling intents. The first
+// event of a watch is a snapshot whose Upserted holds every intent.
+type IntentWatchEvent struct {
+	Revision uint64
+	Snapshot bool
+	Upserted []*domain.SchedulingIntents
+	// Removed are the intents that no longer apply, identified by PID.
+	Removed []*domain.SchedulingIntents
+}
+
+// intentWatchHub resolves the scheduling intents for watchers. It only runs
+// while there is at least one watcher.
+type intentWatchHub struct {
+	mu          sync.Mutex
+	revision    uint64
+	current     map[string]*domain.SchedulingIntents
+	subscribers map[chan *IntentWatchEvent]struct{}
+	stop        context.CancelFunc
+	notify      chan struct{}
+	// refreshMu serializes refreshes so revisions follow each other.
+	refreshMu sync.Mutex
+}
+
+// WatchSchedulingIntents streams changes of the resolved scheduling intents
+// until ctx is done or the watcher falls behind, when the channel is closed.
+// The first event is a snapshot, unless revision is the current one.
+func (svc *Service) WatchSchedulingIntents(ctx context.Context, revision uint64) (<-chan *IntentWatchEvent, error) {
+	hub := &svc.intentWatch
+	ch := make(chan *IntentWatchEvent, intentWatchBuffer)
+
+	hub.mu.Lock()
+	if hub.subscribers == nil {
+		hub.subscribers = make(map[chan *IntentWatchEvent]struct{})
+		hub.notify = make(chan struct{}, 1)
+		// Revisions survive a decision maker restart as long as its clock
+		// does not go back.
+		hub.revision = uint64(time.Now().UnixNano())
+	}
+	first := len(hub.subscribers) == 0
+	hub.mu.Unlock()
+
+	if first {
+		// Nobody kept the intents up to date while there was no watcher.
+		if err := svc.refreshIntentWatch(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	hub.mu.Lock()
+	if hub.current == nil {
+		hub.current = make(map[string]*domain.SchedulingIntents)
+	}
+	if revision != hub.revision {
+		ch <- &IntentWatchEvent{Revision: hub.revision, Snapshot: true, Upserted: sortedWatchIntents(hub.current)}
+	}
+	hub.subscribers[ch] = struct{}{}
+	if hub.stop == nil {
+		loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		hub.stop = cancel
+		go svc.runIntentWatch(loopCtx, hub.notify)
+	}
+	hub.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		if _, ok := hub.subscribers[ch]; ok {
+			delete(hub.subscribers, ch)
+			close(ch)
+		}
+		hub.stopIfIdleLocked()
+	}()
+	return ch, nil
+}
+
+func (hub *intentWatchHub) stopIfIdleLocked() {
+	if len(hub.subscribers) == 0 && hub.stop != nil {
+		hub.stop()
+		hub.stop = nil
+	}
+}
+
+// notifyIntentWatch asks the watch loop to resolve the intents again after
+// the cached intents or node policies changed.
+func (svc *Service) notifyIntentWatch() {
+	hub := &svc.intentWatch
+	hub.mu.Lock()
+	notify := hub.notify
+	hub.mu.Unlock()
+	if notify == nil {
+		return
+	}
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
+
+func (svc *Service) runIntentWatch(ctx context.Context, notify <-chan struct{}) {
+	feed, _ := svc.processSource.(processChangeFeed)
+	var processChanges <-chan struct{}
+	if feed != nil {
+		processChanges = feed.Changes()
+	}
+	ticker := time.NewTicker(intentWatchResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-notify:
+		case <-processChanges:
+			// Let a burst of process starts and exits settle into one
+			// refresh.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(intentWatchProcessSettle):
+			}
+			select {
+			case <-processChanges:
+			default:
+			}
+		case <-ticker.C:
+			if feed != nil && feed.Running() {
+				// Process changes arrive through the feed.
+				continue
+			}
+		}
+		if err := svc.refreshIntentWatch(ctx); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msg("failed to refresh watched scheduling intents")
+		}
+	}
+}
+
+// refreshIntentWatch resolves the scheduling intents and sends the
+// difference from the previous resolution to every watcher.
+func (svc *Service) refreshIntentWatch(ctx context.Context) error {
+	hub := &svc.intentWatch
+	hub.refreshMu.Lock()
+	defer hub.refreshMu.Unlock()
+
+	intents, err := svc.ListAllSchedulingIntents(ctx)
+	if err != nil {
+		return err
+	}
+	next := make(map[string]*domain.SchedulingIntents, len(intents))
+	for _, intent := range intents {
+		next[schedulingIntentWatchKey(intent)] = intent
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	event := &IntentWatchEvent{}
+	for key, intent := range next {
+		if prev, ok := hub.current[key]; !ok || !reflect.DeepEqual(prev, intent) {
+			event.Upserted = append(event.Upserted, intent)
+		}
+	}
+	for key, intent := range hub.current {
+		if _, ok := next[key]; !ok {
+			event.Removed = append(event.Removed, intent)
+		}
+	}
+	hub.current = next
+	if len(event.Upserted) == 0 && len(event.Removed) == 0 {
+		svc.stampIntentStatuses(hub.revision, time.Now())
+		return nil
+	}
+	sortWatchIntents(event.Upserted)
+	sortWatchIntents(event.Removed)
+	hub.revision++
+	event.Revision = hub.revision
+	svc.stampIntentStatuses(hub.revision, time.Now())
+	for ch := range hub.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logger.Logger(ctx).Warn().Msg("scheduling intent watcher fell behind, dropping it")
+			delete(hub.subscribers, ch)
+			close(ch)
+		}
+	}
+	hub.stopIfIdleLocked()
+	return nil
+}
+
+func schedulingIntentWatchKey(intent *domain.SchedulingIntents) string {
+	return "pid:" + strconv.Itoa(intent.PID)
+}
+
+func sortedWatchIntents(intents map[string]*domain.SchedulingIntents) []*domain.SchedulingIntents {
+	result := make([]*domain.SchedulingIntents, 0, len(intents))
+	for _, intent := range intents {
+		result = append(result, intent)
+	}
+	sortWatchIntents(result)
+	return result
+}
+
+func sortWatchIntents(intents []*domain.SchedulingIntents) {
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].PID < intents[j].PID
+	})
+}
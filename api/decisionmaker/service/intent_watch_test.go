@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIntentWatchTestService(procs map[int]string) *Service {
+	return &Service{
+		schedulingIntentsMap: util.NewGenericMap[string, []*domain.SchedulingIntents](),
+		podSchedCollector:    NewPodSchedMetricCollector("machine"),
+		processSource:        &fakeProcessSource{snapshot: procs},
+	}
+}
+
+func receiveIntentWatchEvent(t *testing.T, events <-chan *IntentWatchEvent) *IntentWatchEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watch closed")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no watch event")
+		return nil
+	}
+}
+
+func TestWatchSchedulingIntentsStreamsDiffs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := newIntentWatchTestService(map[int]string{10: "nginx", 20: "redis"})
+	require.NoError(t, svc.ProcessNodePolicies(ctx, []*domain.NodePolicy{
+		{PolicyID: "p1", CommandRegex: "^nginx$", Priority: 1, ExecutionTime: 1000},
+	}))
+
+	events, err := svc.WatchSchedulingIntents(ctx, 0)
+	require.NoError(t, err)
+	snapshot := receiveIntentWatchEvent(t, events)
+	assert.True(t, snapshot.Snapshot)
+	require.Len(t, snapshot.Upserted, 1)
+	assert.Equal(t, 10, snapshot.Upserted[0].PID)
+
+	// Changing the policies is pushed without waiting for the resync.
+	require.NoError(t, svc.ProcessNodePolicies(ctx, []*domain.NodePolicy{
+		{PolicyID: "p2", CommandRegex: "^redis$", Priority: 2, ExecutionTime: 1000},
+	}))
+	diff := receiveIntentWatchEvent(t, events)
+	assert.False(t, diff.Snapshot)
+	assert.Equal(t, snapshot.Revision+1, diff.Revision)
+	require.Len(t, diff.Upserted, 1)
+	assert.Equal(t, 20, diff.Upserted[0].PID)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, 10, diff.Removed[0].PID)
+
+	// A watcher that already has the current revision gets no snapshot.
+	resumed, err := svc.WatchSchedulingIntents(ctx, diff.Revision)
+	require.NoError(t, err)
+	select {
+	case event := <-resumed:
+		t.Fatalf("unexpected event %+v", event)
+	default:
+	}
+}
+
+func TestWatchSchedulingIntentsStopsWhenIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := newIntentWatchTestService(nil)
+
+	events, err := svc.WatchSchedulingIntents(ctx, 0)
+	require.NoError(t, err)
+	receiveIntentWatchEvent(t, events)
+	cancel()
+
+	for range events {
+	}
+	svc.intentWatch.mu.Lock()
+	defer svc.intentWatch.mu.Unlock()
+	assert.Empty(t, svc.intentWatch.subscribers)
+	assert.Nil(t, svc.intentWatch.stop)
+}
+
+func TestWatchSchedulingIntentsFollowsProcessChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The resync interval is far longer than this test, so the refresh below
+	// comes from the process change feed.
+	source := NewEventProcessSource(&fakeProcessSource{snapshot: map[int]string{10: "nginx"}})
+	processEvents := make(chan ProcessEvent)
+	go func() { _ = source.Run(ctx, processEvents) }()
+	require.Eventually(t, source.Running, 2*time.Second, 10*time.Millisecond)
+	svc := newIntentWatchTestService(nil)
+	svc.processSource = source
+	require.NoError(t, svc.ProcessNodePolicies(ctx, []*domain.NodePolicy{
+		{PolicyID: "p1", CommandRegex: "^nginx$", Priority: 1, ExecutionTime: 1000},
+	}))
+
+	events, err := svc.WatchSchedulingIntents(ctx, 0)
+	require.NoError(t, err)
+	receiveIntentWatchEvent(t, events)
+
+	// A started process is picked up without waiting for the resync.
+	processEvents <- ProcessEvent{PID: 11, Comm: "nginx"}
+	diff := receiveIntentWatchEvent(t, events)
+	require.Len(t, diff.Upserted, 1)
+	assert.Equal(t, 11, diff.Upserted[0].PID)
+
+	processEvents <- ProcessEvent{PID: 10, Exit: true}
+	diff = receiveIntentWatchEvent(t, events)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, 10, diff.Removed[0].PID)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/logger"
 	"github.com/Gthulhu/api/pkg/util"
 )
 
@@ -16,6 +18,9 @@ type Node struct {
 	Hash  string
 	Left  *Node
 	Right *Node
+	// Leaf marks the hash of a single intent, as opposed to a subtree cut
+	// off by Depth.
+	Leaf bool
 }
 
 type TraverseIntentMerkleTreeResp struct {
@@ -40,17 +45,56 @@ func (svc *Service) TraverseIntentMerkleTree(ctx context.Context, req *TraverseI
 		root = found
 	}
 
-	truncated := util.TruncateMerkleTree(root, req.Depth)
-	return &TraverseIntentMerkleTreeResp{RootNode: convertMerkleNode(truncated)}, nil
+	return &TraverseIntentMerkleTreeResp{RootNode: convertMerkleNode(root, req.Depth)}, nil
 }
 
-func convertMerkleNode(node *util.MerkleNode) *Node {
+// convertMerkleNode copies the tree down to depth levels below node.
+func convertMerkleNode(node *util.MerkleNode, depth int64) *Node {
 	if node == nil {
 		return nil
 	}
-	return &Node{
-		Hash:  node.Hash,
-		Left:  convertMerkleNode(node.Left),
-		Right: convertMerkleNode(node.Right),
+	result := &Node{Hash: node.Hash, Leaf: node.Left == nil && node.Right == nil}
+	if depth > 0 {
+		result.Left = convertMerkleNode(node.Left, depth-1)
+		result.Right = convertMerkleNode(node.Right, depth-1)
 	}
+	return result
+}
+
+type PatchIntentsOptions struct {
+	// Upserts are added to the cached intents.
+	Upserts []*domain.Intent
+	// RemovedHashes are the Merkle leaf hashes of the cached intents to
+	// drop; an intent that changed is removed by its old hash and added back.
+	RemovedHashes []string
+}
+
+// PatchIntents applies a delta computed by the manager from the Merkle tree,
+// instead of replacing every cached intent like ProcessIntents.
+func (svc *Service) PatchIntents(ctx context.Context, opt *PatchIntentsOptions) error {
+	if opt == nil {
+		return errors.New("nil request")
+	}
+	svc.intentCacheMu.RLock()
+	intents := mergeIntentPatch(svc.intentCache, opt.Upserts, opt.RemovedHashes)
+	svc.intentCacheMu.RUnlock()
+
+	logger.Logger(ctx).Info().Msgf("patching intents: %d upserted, %d removed, %d cached", len(opt.Upserts), len(opt.RemovedHashes), len(intents))
+	return svc.ProcessIntents(ctx, intents)
+}
+
+// mergeIntentPatch drops the cached intents whose hash is removed and appends
+// the upserts.
+func mergeIntentPatch(cached, upserts []*domain.Intent, removedHashes []string) []*domain.Intent {
+	removed := make(map[string]struct{}, len(removedHashes))
+	for _, hash := range removedHashes {
+		removed[hash] = struct{}{}
+	}
+	intents := make([]*domain.Intent, 0, len(cached)+len(upserts))
+	for _, intent := range normalizeIntentInputs(cached) {
+		if _, ok := removed[hashIntent(intent)]; !ok {
+			intents = append(intents, intent)
+		}
+	}
+	return append(intents, normalizeIntentInputs(upserts)...)
 }
@@ -294,3 +294,39 @@ func TestDeleteAllIntentsClearsCachedIntentsAndMetricTargets(t *testing.T) {
 	assert.Empty(t, svc.podSchedCollector.intentPods)
 	assert.Equal(t, util.HashStringSHA256Hex(""), svc.intentMerkleRootHash)
 }
+
+func TestTraverseIntentMerkleTreeMarksLeaves(t *testing.T) {
+	root := util.BuildMerkleTree([]string{
+		util.HashStringSHA256Hex("leaf-a"),
+		util.HashStringSHA256Hex("leaf-b"),
+		util.HashStringSHA256Hex("leaf-c"),
+		util.HashStringSHA256Hex("leaf-d"),
+	})
+	svc := &Service{intentMerkleRoot: root}
+
+	resp, err := svc.TraverseIntentMerkleTree(context.Background(), &TraverseIntentMerkleTreeOptions{Depth: 1})
+	require.NoError(t, err)
+	assert.False(t, resp.RootNode.Leaf)
+	assert.False(t, resp.RootNode.Left.Leaf, "a subtree cut off by depth is not a leaf")
+
+	resp, err = svc.TraverseIntentMerkleTree(context.Background(), &TraverseIntentMerkleTreeOptions{Depth: 2})
+	require.NoError(t, err)
+	assert.True(t, resp.RootNode.Left.Left.Leaf)
+	assert.Equal(t, util.HashStringSHA256Hex("leaf-a"), resp.RootNode.Left.Left.Hash)
+}
+
+func TestMergeIntentPatch(t *testing.T) {
+	kept := &domain.Intent{PodID: "pod-a", Priority: 1}
+	changed := &domain.Intent{PodID: "pod-b", Priority: 1}
+	removed := &domain.Intent{PodID: "pod-c", Priority: 1}
+	updated := &domain.Intent{PodID: "pod-b", Priority: 2}
+	added := &domain.Intent{PodID: "pod-d", Priority: 1}
+
+	got := mergeIntentPatch(
+		[]*domain.Intent{kept, changed, nil, removed},
+		[]*domain.Intent{updated, added},
+		[]string{hashIntent(changed), hashIntent(removed), "unknown-hash"},
+	)
+
+	assert.Equal(t, []*domain.Intent{kept, updated, added}, got)
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -38,6 +37,7 @@ func (svc *Service) ProcessNodePolicies(ctx context.Context, policies []*domain.
 	svc.nodePolicyCacheMu.Unlock()
 
 	logger.Logger(ctx).Info().Msgf("processed %d node scheduling policies", len(normalized))
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
@@ -47,7 +47,23 @@ func (svc *Service) ProcessNodePolicies(ctx context.Context, policies []*domain.
 // active node policy's CommandRegex. Matches are converted into the same
 // domain.SchedulingIntents struct already consumed by the scheduler daemon,
 // so no changes are required downstream of GET /api/v1/scheduling/strategies.
+// A process matched by several policies keeps the one that wins precedence.
 func (svc *Service) resolveNodeSchedulingIntents(ctx context.Context) ([]*domain.SchedulingIntents, error) {
+	candidates, err := svc.nodePolicyCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolved, _ := resolveIntentPrecedence(candidates)
+	var results []*domain.SchedulingIntents
+	for _, r := range resolved {
+		results = append(results, r.intent)
+	}
+	return results, nil
+}
+
+// nodePolicyCandidates returns the claim of every node policy on the
+// processes whose comm name matches its CommandRegex.
+func (svc *Service) nodePolicyCandidates(ctx context.Context) ([]*intentCandidate, error) {
 	svc.nodePolicyCacheMu.RLock()
 	policies := svc.nodePolicyCache
 	svc.nodePolicyCacheMu.RUnlock()
@@ -55,48 +71,52 @@ func (svc *Service) resolveNodeSchedulingIntents(ctx context.Context) ([]*domain
 	if len(policies) == 0 {
 		return nil, nil
 	}
-	if svc.processSource == nil {
-		return nil, nil
-	}
 
-	processes, err := svc.processSource.Snapshot(ctx)
+	processes, err := svc.processSnapshot(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("snapshot processes: %w", err)
 	}
 
-	var results []*domain.SchedulingIntents
+	var candidates []*intentCandidate
 	for _, policy := range policies {
 		if policy == nil || policy.CommandRegex == "" {
 			continue
 		}
-		re, err := regexp.Compile(policy.CommandRegex)
+		re, err := svc.compileCommandRegex(policy.CommandRegex)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msgf("invalid commandRegex %q in node policy %s", policy.CommandRegex, policy.PolicyID)
 			continue
 		}
+		candidate := &intentCandidate{
+			rank:   nodePolicyRank(policy),
+			source: domain.IntentSource{Kind: domain.IntentSourceNodePolicy, ID: policy.PolicyID},
+			template: domain.SchedulingIntents{
+				Priority:      policy.Priority,
+				ExecutionTime: uint64(policy.ExecutionTime),
+				CommandRegex:  policy.CommandRegex,
+			},
+		}
 		for pid, comm := range processes {
 			if comm == pauseCommand {
 				continue
 			}
-			if !re.MatchString(comm) {
+			if !svc.commandMatches(re, pid, comm) {
 				continue
 			}
-			results = append(results, &domain.SchedulingIntents{
-				Priority:      policy.Priority,
-				ExecutionTime: uint64(policy.ExecutionTime),
-				PID:           pid,
-				CommandRegex:  policy.CommandRegex,
-			})
+			candidate.pids = append(candidate.pids, pid)
 		}
+		if len(candidate.pids) == 0 {
+			continue
+		}
+		sort.Ints(candidate.pids)
+		candidates = append(candidates, candidate)
 	}
-	return results, nil
+	return candidates, nil
 }
 
 // DeleteNodePolicyByID removes a single node policy from the cache by ID.
 func (svc *Service) DeleteNodePolicyByID(ctx context.Context, policyID string) error {
 	svc.nodePolicyCacheMu.Lock()
-	defer svc.nodePolicyCacheMu.Unlock()
-
 	remaining := make([]*domain.NodePolicy, 0, len(svc.nodePolicyCache))
 	for _, policy := range svc.nodePolicyCache {
 		if policy != nil && policy.PolicyID == policyID {
@@ -106,18 +126,21 @@ func (svc *Service) DeleteNodePolicyByID(ctx context.Context, policyID string) e
 	}
 	svc.nodePolicyCache = remaining
 	svc.rebuildNodePolicyMerkleRootLocked()
+	svc.nodePolicyCacheMu.Unlock()
 	logger.Logger(ctx).Info().Msgf("deleted node policy %s", policyID)
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
 // DeleteAllNodePolicies clears every cached node policy.
 func (svc *Service) DeleteAllNodePolicies(ctx context.Context) error {
 	svc.nodePolicyCacheMu.Lock()
-	defer svc.nodePolicyCacheMu.Unlock()
 	count := len(svc.nodePolicyCache)
 	svc.nodePolicyCache = nil
 	svc.rebuildNodePolicyMerkleRootLocked()
+	svc.nodePolicyCacheMu.Unlock()
 	logger.Logger(ctx).Info().Msgf("deleted all %d node policies", count)
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
@@ -168,22 +191,30 @@ func sortNodePoliciesByKey(policies []*domain.NodePolicy) []*domain.NodePolicy {
 }
 
 func nodePolicySortKey(policy *domain.NodePolicy) string {
-	return strings.Join([]string{
+	key := []string{
 		policy.PolicyID,
 		policy.NodeID,
 		policy.CommandRegex,
 		strconv.Itoa(policy.Priority),
 		strconv.FormatInt(policy.ExecutionTime, 10),
-	}, "|")
+	}
+	if policy.Precedence != 0 {
+		key = append(key, strconv.Itoa(policy.Precedence))
+	}
+	return strings.Join(key, "|")
 }
 
 func hashNodePolicy(policy *domain.NodePolicy) string {
-	serialized := strings.Join([]string{
+	fields := []string{
 		"policyID=" + policy.PolicyID,
 		"nodeID=" + policy.NodeID,
 		"commandRegex=" + policy.CommandRegex,
 		"priority=" + strconv.Itoa(policy.Priority),
 		"executionTime=" + strconv.FormatInt(policy.ExecutionTime, 10),
-	}, "|")
-	return util.HashStringSHA256Hex(serialized)
+	}
+	// Must match the manager's hashNodeSchedulingIntent.
+	if policy.Precedence != 0 {
+		fields = append(fields, "precedence="+strconv.Itoa(policy.Precedence))
+	}
+	return util.HashStringSHA256Hex(strings.Join(fields, "|"))
 }
@@ -0,0 +1,204 @@
+//go:build linux
+
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/pkg/logger"
+	"golang.org/x/sys/unix"
+)
+
+// The kernel's process events connector reports the same sched_process_fork,
+// exec and exit points bpf/proc_monitor.bpf.c hooks, over netlink, see
+// include/uapi/linux/cn_proc.h.
+const (
+	cnIdxProc          = 0x1
+	cnValProc          = 0x1
+	procCnMcastListen  = 1
+	procEventFork      = 0x00000001
+	procEventExec      = 0x00000002
+	procEventExit      = 0x80000000
+	cnMsgLen           = 20
+	procEventHeaderLen = 16
+	procConnectorWait  = time.Second
+)
+
+// watchProcessEvents subscribes to the kernel's process events and sends a
+// ProcessEvent for every process that starts, execs or exits until ctx is
+// done, when the channel is closed. Threads are skipped. Subscribing needs
+// CAP_NET_ADMIN.
+func watchProcessEvents(ctx context.Context, procRoot string) (<-chan ProcessEvent, error) {
+	fd, err := openProcConnector(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("open proc connector: %w", err)
+	}
+	if err := subscribeProcConnector(fd); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	events := make(chan ProcessEvent, 256)
+	go func() {
+		defer close(events)
+		defer unix.Close(fd)
+		buf := make([]byte, os.Getpagesize())
+		for ctx.Err() == nil {
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			switch {
+			case errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR):
+				continue
+			case errors.Is(err, unix.ENOBUFS):
+				// The next resync picks up the lost events.
+				logger.Logger(ctx).Warn().Msg("proc connector overflowed, process events were lost")
+				continue
+			case err != nil:
+				logger.Logger(ctx).Warn().Err(err).Msg("failed to read process events")
+				return
+			}
+			for _, event := range parseProcConnectorMessages(buf[:n], procRoot) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// openProcConnector opens a proc connector socket in the host's network
+// namespace, that of PID 1 in procRoot, since the kernel only sends process
+// events there. A pod without host networking needs CAP_SYS_ADMIN and the
+// host PID namespace for this; when PID 1's namespace cannot be opened the
+// socket is opened in the current one.
+func openProcConnector(procRoot string) (int, error) {
+	hostNetns, err := os.Open(procRoot + "/1/ns/net")
+	if err != nil {
+		return unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	}
+	defer hostNetns.Close()
+	var host, self unix.Stat_t
+	if err := unix.Fstat(int(hostNetns.Fd()), &host); err != nil {
+		return -1, err
+	}
+	if err := unix.Stat("/proc/thread-self/ns/net", &self); err != nil {
+		return -1, err
+	}
+	if host.Ino == self.Ino && host.Dev == self.Dev {
+		return unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	}
+
+	// A socket stays in the namespace it was created in, so only this
+	// thread has to switch over for a moment.
+	runtime.LockOSThread()
+	ownNetns, err := os.Open("/proc/thread-self/ns/net")
+	if err != nil {
+		runtime.UnlockOSThread()
+		return -1, err
+	}
+	defer ownNetns.Close()
+	if err := unix.Setns(int(hostNetns.Fd()), unix.CLONE_NEWNET); err != nil {
+		runtime.UnlockOSThread()
+		return -1, fmt.Errorf("enter host network namespace: %w", err)
+	}
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if setnsErr := unix.Setns(int(ownNetns.Fd()), unix.CLONE_NEWNET); setnsErr != nil {
+		// Leave the thread locked so it exits with the goroutine instead of
+		// running others in the wrong namespace.
+		if err == nil {
+			unix.Close(fd)
+		}
+		return -1, fmt.Errorf("leave host network namespace: %w", setnsErr)
+	}
+	runtime.UnlockOSThread()
+	return fd, err
+}
+
+func subscribeProcConnector(fd int) error {
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		return fmt.Errorf("bind proc connector: %w", err)
+	}
+	// Wake up regularly so the reader notices when it should stop.
+	timeout := unix.NsecToTimeval(procConnectorWait.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout); err != nil {
+		return fmt.Errorf("set proc connector timeout: %w", err)
+	}
+
+	msg := make([]byte, unix.NLMSG_HDRLEN+cnMsgLen+4)
+	binary.NativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:], unix.NLMSG_DONE)
+	binary.NativeEndian.PutUint32(msg[12:], uint32(os.Getpid()))
+	cn := msg[unix.NLMSG_HDRLEN:]
+	binary.NativeEndian.PutUint32(cn[0:], cnIdxProc)
+	binary.NativeEndian.PutUint32(cn[4:], cnValProc)
+	binary.NativeEndian.PutUint16(cn[16:], 4)
+	binary.NativeEndian.PutUint32(cn[cnMsgLen:], procCnMcastListen)
+	if err := unix.Sendto(fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return fmt.Errorf("subscribe to proc connector: %w", err)
+	}
+	return nil
+}
+
+// parseProcConnectorMessages turns the netlink messages in buf into process
+// events. The comm of a new process is read from procRoot, since the kernel
+// does not send it; processes that are gone by then are skipped, their exit
+// follows.
+func parseProcConnectorMessages(buf []byte, procRoot string) []ProcessEvent {
+	var events []ProcessEvent
+	for len(buf) >= unix.NLMSG_HDRLEN {
+		msgLen := int(binary.NativeEndian.Uint32(buf[0:]))
+		if msgLen < unix.NLMSG_HDRLEN || msgLen > len(buf) {
+			break
+		}
+		if event, ok := parseProcEvent(buf[unix.NLMSG_HDRLEN:msgLen], procRoot); ok {
+			events = append(events, event)
+		}
+		buf = buf[nlmsgAlign(msgLen):]
+	}
+	return events
+}
+
+func parseProcEvent(data []byte, procRoot string) (ProcessEvent, bool) {
+	if len(data) < cnMsgLen+procEventHeaderLen+16 ||
+		binary.NativeEndian.Uint32(data[0:]) != cnIdxProc ||
+		binary.NativeEndian.Uint32(data[4:]) != cnValProc {
+		return ProcessEvent{}, false
+	}
+	event := data[cnMsgLen:]
+	what := binary.NativeEndian.Uint32(event[0:])
+	body := event[procEventHeaderLen:]
+	var pid, tgid uint32
+	switch what {
+	case procEventFork:
+		pid, tgid = binary.NativeEndian.Uint32(body[8:]), binary.NativeEndian.Uint32(body[12:])
+	case procEventExec, procEventExit:
+		pid, tgid = binary.NativeEndian.Uint32(body[0:]), binary.NativeEndian.Uint32(body[4:])
+	default:
+		return ProcessEvent{}, false
+	}
+	if pid != tgid {
+		return ProcessEvent{}, false
+	}
+	if what == procEventExit {
+		return ProcessEvent{PID: int(pid), Exit: true}, true
+	}
+	comm, err := os.ReadFile(procRoot + "/" + strconv.Itoa(int(pid)) + "/comm")
+	if err != nil {
+		return ProcessEvent{}, false
+	}
+	return ProcessEvent{PID: int(pid), Comm: strings.TrimSpace(string(comm))}, true
+}
+
+func nlmsgAlign(n int) int {
+	return (n + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+}
@@ -0,0 +1,50 @@
+//go:build linux
+
+package service
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// procConnectorMessage builds a netlink message carrying a proc connector
+// event with the given payload words.
+func procConnectorMessage(what uint32, words ...uint32) []byte {
+	msgLen := unix.NLMSG_HDRLEN + cnMsgLen + procEventHeaderLen + 4*len(words)
+	msg := make([]byte, nlmsgAlign(msgLen))
+	binary.NativeEndian.PutUint32(msg[0:], uint32(msgLen))
+	cn := msg[unix.NLMSG_HDRLEN:]
+	binary.NativeEndian.PutUint32(cn[0:], cnIdxProc)
+	binary.NativeEndian.PutUint32(cn[4:], cnValProc)
+	event := cn[cnMsgLen:]
+	binary.NativeEndian.PutUint32(event[0:], what)
+	for i, word := range words {
+		binary.NativeEndian.PutUint32(event[procEventHeaderLen+4*i:], word)
+	}
+	return msg
+}
+
+func TestParseProcConnectorMessages(t *testing.T) {
+	root := t.TempDir()
+	writeProcComm(t, root, "20", "redis")
+	writeProcComm(t, root, "21", "nginx")
+
+	var buf []byte
+	// A forked process and a new thread of it, which is skipped.
+	buf = append(buf, procConnectorMessage(procEventFork, 1, 1, 20, 20)...)
+	buf = append(buf, procConnectorMessage(procEventFork, 20, 20, 22, 20)...)
+	buf = append(buf, procConnectorMessage(procEventExec, 21, 21, 0, 0)...)
+	// The exec of a process that is already gone is skipped.
+	buf = append(buf, procConnectorMessage(procEventExec, 23, 23, 0, 0)...)
+	buf = append(buf, procConnectorMessage(procEventExit, 10, 10, 0, 0, 1, 1)...)
+
+	events := parseProcConnectorMessages(buf, root)
+	assert.Equal(t, []ProcessEvent{
+		{PID: 20, Comm: "redis"},
+		{PID: 21, Comm: "nginx"},
+		{PID: 10, Exit: true},
+	}, events)
+}
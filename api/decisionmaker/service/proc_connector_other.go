@@ -0,0 +1,13 @@
+//go:build !linux
+
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// watchProcessEvents needs the Linux process events connector.
+func watchProcessEvents(context.Context, string) (<-chan ProcessEvent, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+)
+
+const (
+	// processIndexRevalidateInterval bounds how long an indexed process is
+	// trusted. A PID that is reused by a process with the same comm name is
+	// only noticed when the index is rebuilt.
+	processIndexRevalidateInterval = 5 * time.Minute
+	// maxCachedCommandRegexes and maxCachedProcessMatches cap the regex
+	// caches; they are cleared once full, since patterns only churn when
+	// intents are replaced.
+	maxCachedCommandRegexes = 1024
+	maxCachedProcessMatches = 64
+)
+
+// podProcessScanner reads the pod processes /proc/<pid>/cgroup describes for
+// one PID. It returns no pods for processes outside of Kubernetes pods.
+type podProcessScanner func(ctx context.Context, pid int) (map[string]*domain.PodInfo, error)
+
+// indexedProcess is one process of the node, as of the last refresh.
+type indexedProcess struct {
+	comm string
+	// pods holds the pod processes read from the cgroup file when the
+	// process was first seen, or after it called exec.
+	pods map[string]*domain.PodInfo
+	// matches caches CommandRegex -> whether comm matches it.
+	matches map[string]bool
+}
+
+// processIndex is the node's process table with the pod of every process,
+// shared by ListAllSchedulingIntents, ProcessIntents, GetPodsPIDs and the
+// node policies. Every refresh takes a ProcessSource snapshot and only reads
+// the cgroup and stat files of processes that are new or changed their comm
+// name; exited processes are dropped. Pods are invalidated through
+// invalidatePods when their intents change.
+type processIndex struct {
+	mu          sync.Mutex
+	source      ProcessSource
+	scan        podProcessScanner
+	procs       map[int]*indexedProcess
+	regexes     map[string]*regexp.Regexp
+	regexErrors map[string]error
+	rebuiltAt   time.Time
+	now         func() time.Time
+}
+
+func newProcessIndex(source ProcessSource, scan podProcessScanner) *processIndex {
+	return &processIndex{
+		source:      source,
+		scan:        scan,
+		procs:       make(map[int]*indexedProcess),
+		regexes:     make(map[string]*regexp.Regexp),
+		regexErrors: make(map[string]error),
+		now:         time.Now,
+	}
+}
+
+// refreshLocked brings the index up to date with a new snapshot of the
+// node's processes. Callers must hold idx.mu.
+func (idx *processIndex) refreshLocked(ctx context.Context) error {
+	snapshot, err := idx.source.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if now := idx.now(); now.Sub(idx.rebuiltAt) >= processIndexRevalidateInterval {
+		idx.procs = make(map[int]*indexedProcess, len(snapshot))
+		idx.rebuiltAt = now
+	}
+	for pid := range idx.procs {
+		if _, ok := snapshot[pid]; !ok {
+			delete(idx.procs, pid)
+		}
+	}
+	for pid, comm := range snapshot {
+		if proc, ok := idx.procs[pid]; ok && proc.comm == comm {
+			continue
+		}
+		pods, err := idx.scan(ctx, pid)
+		if err != nil {
+			// The process may have exited since the snapshot; retry on the
+			// next refresh.
+			delete(idx.procs, pid)
+			continue
+		}
+		idx.procs[pid] = &indexedProcess{comm: comm, pods: pods}
+	}
+	return nil
+}
+
+// podInfos refreshes the index and returns the processes of every pod on the
+// node, keyed by pod UID, with each pod's processes sorted by PID.
+func (idx *processIndex) podInfos(ctx context.Context) (map[string]*domain.PodInfo, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refreshLocked(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read /proc directory: %v", err)
+	}
+	pids := make([]int, 0, len(idx.procs))
+	for pid, proc := range idx.procs {
+		if len(proc.pods) > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	podMap := make(map[string]*domain.PodInfo)
+	for _, pid := range pids {
+		for podUID, fragment := range idx.procs[pid].pods {
+			podInfo, ok := podMap[podUID]
+			if !ok {
+				podInfo = &domain.PodInfo{PodUID: podUID, PodID: fragment.PodID}
+				podMap[podUID] = podInfo
+			}
+			podInfo.Processes = append(podInfo.Processes, fragment.Processes...)
+		}
+	}
+	return podMap, nil
+}
+
+// processes refreshes the index and returns the comm name of every process
+// on the node, keyed by PID.
+func (idx *processIndex) processes(ctx context.Context) (map[int]string, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refreshLocked(ctx); err != nil {
+		return nil, err
+	}
+	procs := make(map[int]string, len(idx.procs))
+	for pid, proc := range idx.procs {
+		procs[pid] = proc.comm
+	}
+	return procs, nil
+}
+
+// invalidatePods drops the processes of the given pods so their cgroup files
+// are read again on the next refresh.
+func (idx *processIndex) invalidatePods(podUIDs ...string) {
+	if len(podUIDs) == 0 {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for pid, proc := range idx.procs {
+		for _, podUID := range podUIDs {
+			if _, ok := proc.pods[podUID]; ok {
+				delete(idx.procs, pid)
+				break
+			}
+		}
+	}
+}
+
+// compile returns the compiled CommandRegex, compiling each pattern once.
+func (idx *processIndex) compile(pattern string) (*regexp.Regexp, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if re, ok := idx.regexes[pattern]; ok {
+		return re, nil
+	}
+	if err, ok := idx.regexErrors[pattern]; ok {
+		return nil, err
+	}
+	if len(idx.regexes)+len(idx.regexErrors) >= maxCachedCommandRegexes {
+		idx.regexes = make(map[string]*regexp.Regexp)
+		idx.regexErrors = make(map[string]error)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		idx.regexErrors[pattern] = err
+		return nil, err
+	}
+	idx.regexes[pattern] = re
+	return re, nil
+}
+
+// matches reports whether re matches comm, caching the result on the
+// indexed process pid for as long as it keeps that comm name.
+func (idx *processIndex) matches(re *regexp.Regexp, pid int, comm string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	proc, ok := idx.procs[pid]
+	if !ok || proc.comm != comm {
+		return re.MatchString(comm)
+	}
+	pattern := re.String()
+	if matched, ok := proc.matches[pattern]; ok {
+		return matched
+	}
+	if proc.matches == nil || len(proc.matches) >= maxCachedProcessMatches {
+		proc.matches = make(map[string]bool)
+	}
+	matched := re.MatchString(comm)
+	proc.matches[pattern] = matched
+	return matched
+}
+
+// compileCommandRegex compiles an intent or node policy CommandRegex, once
+// per pattern when the process index is set up.
+func (svc *Service) compileCommandRegex(pattern string) (*regexp.Regexp, error) {
+	if svc.processIndex == nil {
+		return regexp.Compile(pattern)
+	}
+	return svc.processIndex.compile(pattern)
+}
+
+// commandMatches reports whether the process pid, named comm, matches re.
+func (svc *Service) commandMatches(re *regexp.Regexp, pid int, comm string) bool {
+	if svc.processIndex == nil {
+		return re.MatchString(comm)
+	}
+	return svc.processIndex.matches(re, pid, comm)
+}
+
+// processSnapshot returns the comm name of every process on the node.
+func (svc *Service) processSnapshot(ctx context.Context) (map[int]string, error) {
+	if svc.processIndex != nil {
+		return svc.processIndex.processes(ctx)
+	}
+	if svc.processSource == nil {
+		return nil, nil
+	}
+	return svc.processSource.Snapshot(ctx)
+}
+
+// invalidatePodProcesses re-reads the processes of pods whose intents were
+// added or removed, the pod events the Decision Maker sees.
+func (svc *Service) invalidatePodProcesses(podIDs ...string) {
+	if svc.processIndex != nil {
+		svc.processIndex.invalidatePods(podIDs...)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingPodScanner assigns every PID in pods to its pod and counts how
+// often each PID's cgroup file would have been read.
+type countingPodScanner struct {
+	pods  map[int]string
+	scans map[int]int
+}
+
+func (c *countingPodScanner) scan(_ context.Context, pid int) (map[string]*domain.PodInfo, error) {
+	c.scans[pid]++
+	podUID, ok := c.pods[pid]
+	if !ok {
+		return nil, nil
+	}
+	return map[string]*domain.PodInfo{
+		podUID: {PodUID: podUID, Processes: []domain.PodProcess{{PID: pid}}},
+	}, nil
+}
+
+func TestProcessIndexOnlyScansNewProcesses(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeProcessSource{snapshot: map[int]string{1: "init", 10: "nginx", 11: "nginx"}}
+	scanner := &countingPodScanner{pods: map[int]string{10: "pod-a", 11: "pod-a"}, scans: map[int]int{}}
+	idx := newProcessIndex(source, scanner.scan)
+
+	pods, err := idx.podInfos(ctx)
+	require.NoError(t, err)
+	require.Contains(t, pods, "pod-a")
+	assert.Equal(t, []domain.PodProcess{{PID: 10}, {PID: 11}}, pods["pod-a"].Processes)
+
+	// pid 11 exits, pid 12 starts and pid 10 calls exec.
+	source.snapshot = map[int]string{1: "init", 10: "worker", 12: "redis"}
+	scanner.pods[12] = "pod-b"
+	pods, err = idx.podInfos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PodProcess{{PID: 10}}, pods["pod-a"].Processes)
+	assert.Equal(t, []domain.PodProcess{{PID: 12}}, pods["pod-b"].Processes)
+	assert.Equal(t, map[int]int{1: 1, 10: 2, 11: 1, 12: 1}, scanner.scans)
+
+	procs, err := idx.processes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, source.snapshot, procs)
+	assert.Equal(t, 1, scanner.scans[1])
+}
+
+func TestProcessIndexInvalidatePodsAndRevalidate(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeProcessSource{snapshot: map[int]string{10: "nginx", 20: "redis"}}
+	scanner := &countingPodScanner{pods: map[int]string{10: "pod-a", 20: "pod-b"}, scans: map[int]int{}}
+	idx := newProcessIndex(source, scanner.scan)
+	now := time.Unix(1000, 0)
+	idx.now = func() time.Time { return now }
+
+	_, err := idx.podInfos(ctx)
+	require.NoError(t, err)
+	idx.invalidatePods("pod-a")
+	_, err = idx.podInfos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{10: 2, 20: 1}, scanner.scans)
+
+	now = now.Add(processIndexRevalidateInterval)
+	_, err = idx.podInfos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{10: 3, 20: 2}, scanner.scans)
+}
+
+func TestProcessIndexSnapshotError(t *testing.T) {
+	idx := newProcessIndex(&fakeProcessSource{err: errors.New("boom")}, (&countingPodScanner{scans: map[int]int{}}).scan)
+	_, err := idx.podInfos(context.Background())
+	assert.Error(t, err)
+}
+
+func TestProcessIndexCachesRegexes(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeProcessSource{snapshot: map[int]string{10: "nginx"}}
+	idx := newProcessIndex(source, (&countingPodScanner{scans: map[int]int{}}).scan)
+	_, err := idx.processes(ctx)
+	require.NoError(t, err)
+
+	re, err := idx.compile("^ngi")
+	require.NoError(t, err)
+	again, err := idx.compile("^ngi")
+	require.NoError(t, err)
+	assert.Same(t, re, again)
+	_, err = idx.compile("(")
+	assert.Error(t, err)
+
+	assert.True(t, idx.matches(re, 10, "nginx"))
+	assert.Equal(t, map[string]bool{"^ngi": true}, idx.procs[10].matches)
+	// A comm name the index does not know is matched without caching.
+	assert.False(t, idx.matches(re, 10, "redis"))
+	assert.False(t, idx.matches(regexp.MustCompile("^ngi"), 99, "redis"))
+}
+
+func TestEventProcessSourceAppliesEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seed := &fakeProcessSource{snapshot: map[int]string{1: "init", 10: "nginx"}}
+	source := NewEventProcessSource(seed)
+
+	events := make(chan ProcessEvent, 2)
+	events <- ProcessEvent{PID: 10, Exit: true}
+	events <- ProcessEvent{PID: 20, Comm: "redis"}
+	done := make(chan error, 1)
+	go func() { done <- source.Run(ctx, events) }()
+
+	require.Eventually(t, func() bool {
+		procs, err := source.Snapshot(ctx)
+		return err == nil && len(procs) == 2 && procs[20] == "redis"
+	}, 2*time.Second, 10*time.Millisecond)
+	procs, err := source.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "init", 20: "redis"}, procs)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	// Without events the seed is used again.
+	procs, err = source.Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, seed.snapshot, procs)
+}
+
+func writeProcComm(t *testing.T, root string, pid, comm string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, pid), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, pid, "comm"), []byte(comm+"\n"), 0o644))
+}
+
+func TestEventProcessSourceResyncsFromSeed(t *testing.T) {
+	prev := processEventResyncInterval
+	processEventResyncInterval = 20 * time.Millisecond
+	defer func() { processEventResyncInterval = prev }()
+
+	root := t.TempDir()
+	writeProcComm(t, root, "1", "init")
+	source := NewEventProcessSource(NewProcScanSource(root))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go source.Run(ctx, make(chan ProcessEvent))
+	require.Eventually(t, source.Running, 2*time.Second, 10*time.Millisecond)
+
+	// A process whose start event was lost is found by the next resync.
+	writeProcComm(t, root, "30", "redis")
+	select {
+	case <-source.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("resync did not report the changed processes")
+	}
+	procs, err := source.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "init", 30: "redis"}, procs)
+}
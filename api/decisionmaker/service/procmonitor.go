@@ -2,29 +2,47 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"maps"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/pkg/logger"
 )
 
+// processEventResyncInterval is how often EventProcessSource scans /proc
+// again while it is fed by events, to recover events the kernel dropped.
+var processEventResyncInterval = time.Minute
+
 // ProcessSource enumerates currently running processes on the node, keyed by
 // PID with their executable/comm name (as read from /proc/<pid>/comm).
 //
-// The default implementation (procScanSource) polls /proc directly, which
-// works everywhere without special privileges beyond what the Decision Maker
-// already requires. On kernels where the sched_ext scheduler eBPF stack is
-// available, the same information can instead be produced by
-// bpf/proc_monitor.bpf.c, which hooks tp_btf/sched_process_exec and
-// tp_btf/sched_process_exit to notify user-space of process start/exit in
-// real time (see docs/node-scheduling-policy.md). Since regex matching
-// cannot run inside the BPF program, both implementations feed the same
-// PID -> comm snapshot into resolveNodeSchedulingIntents, which performs the
-// CommandRegex matching in user-space.
+// procScanSource polls /proc directly, which works everywhere without special
+// privileges beyond what the Decision Maker already requires.
+// EventProcessSource, which the Decision Maker uses, instead learns about
+// process starts and exits in real time from the kernel's process events,
+// the same points bpf/proc_monitor.bpf.c hooks, and only scans /proc to
+// resync. Since regex matching cannot run inside the kernel, both
+// implementations feed the same PID -> comm snapshot into the process index
+// (see process_index.go), and the CommandRegex matching happens in
+// user-space.
 type ProcessSource interface {
 	// Snapshot returns the set of processes currently running on the node.
 	Snapshot(ctx context.Context) (map[int]string, error)
 }
 
+// processChangeFeed is implemented by process sources that learn about
+// process starts and exits as they happen. While Running, Changes receives a
+// value after the processes changed; changes in quick succession may be
+// coalesced into one.
+type processChangeFeed interface {
+	Running() bool
+	Changes() <-chan struct{}
+}
+
 // procScanSource implements ProcessSource by scanning /proc.
 type procScanSource struct {
 	rootDir string
@@ -64,3 +82,156 @@ func (p *procScanSource) Snapshot(_ context.Context) (map[int]string, error) {
 	}
 	return procs, nil
 }
+
+// ProcessEvent is a process start, exec or exit, as reported by the kernel's
+// process events.
+type ProcessEvent struct {
+	PID  int
+	Comm string
+	Exit bool
+}
+
+// EventProcessSource implements ProcessSource from process exec/exit events,
+// so a snapshot costs no /proc reads. It learns the processes that were
+// already running from a snapshot of seed, normally /proc scanning, takes
+// one again every processEventResyncInterval, and falls back to seed
+// whenever it is not running.
+type EventProcessSource struct {
+	seed    ProcessSource
+	changes chan struct{}
+
+	mu      sync.RWMutex
+	running bool
+	procs   map[int]string
+}
+
+// NewEventProcessSource creates an EventProcessSource; call Run to feed it.
+func NewEventProcessSource(seed ProcessSource) *EventProcessSource {
+	return &EventProcessSource{seed: seed, changes: make(chan struct{}, 1)}
+}
+
+// Changes implements processChangeFeed.
+func (s *EventProcessSource) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// Running implements processChangeFeed: it reports whether the source is fed
+// by events; otherwise snapshots come from seed and Changes stays silent.
+func (s *EventProcessSource) Running() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.running
+}
+
+// Run seeds the process table and applies events until ctx is done or
+// events is closed. Events sent while a seed snapshot is taken are applied
+// after it, so none are lost.
+func (s *EventProcessSource) Run(ctx context.Context, events <-chan ProcessEvent) error {
+	if err := s.resync(ctx); err != nil {
+		return fmt.Errorf("seed processes: %w", err)
+	}
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.procs = nil
+		s.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(processEventResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.resync(ctx); err != nil {
+				logger.Logger(ctx).Warn().Err(err).Msg("failed to resync processes")
+			}
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			s.mu.Lock()
+			if event.Exit {
+				delete(s.procs, event.PID)
+			} else {
+				s.procs[event.PID] = event.Comm
+			}
+			s.mu.Unlock()
+			s.notifyChanged()
+		}
+	}
+}
+
+// resync replaces the process table with a snapshot of seed.
+func (s *EventProcessSource) resync(ctx context.Context) error {
+	procs, err := s.seed.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	// Events change the table in place; the seed keeps its snapshot.
+	procs = maps.Clone(procs)
+	if procs == nil {
+		procs = make(map[int]string)
+	}
+	s.mu.Lock()
+	changed := s.running && !maps.Equal(s.procs, procs)
+	s.procs = procs
+	s.running = true
+	s.mu.Unlock()
+	if changed {
+		s.notifyChanged()
+	}
+	return nil
+}
+
+func (s *EventProcessSource) notifyChanged() {
+	select {
+	case s.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (s *EventProcessSource) Snapshot(ctx context.Context) (map[int]string, error) {
+	s.mu.RLock()
+	if !s.running {
+		s.mu.RUnlock()
+		return s.seed.Snapshot(ctx)
+	}
+	defer s.mu.RUnlock()
+	return maps.Clone(s.procs), nil
+}
+
+// StartProcessEvents feeds the process source with the kernel's process
+// events until StopProcessEvents. When they are not available, for example
+// without CAP_NET_ADMIN, processes keep coming from /proc scans.
+func (svc *Service) StartProcessEvents(ctx context.Context) {
+	if svc.processEvents == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	events, err := watchProcessEvents(ctx, procDir)
+	if err != nil {
+		cancel()
+		logger.Logger(ctx).Warn().Err(err).Msg("process events are not available, scanning /proc instead")
+		return
+	}
+	svc.processEventsStop = cancel
+	svc.processEventsDone = make(chan struct{})
+	go func() {
+		defer close(svc.processEventsDone)
+		defer cancel()
+		if err := svc.processEvents.Run(ctx, events); err != nil && ctx.Err() == nil {
+			logger.Logger(ctx).Warn().Err(err).Msg("stopped following process events, scanning /proc instead")
+		}
+	}()
+}
+
+// StopProcessEvents stops feeding the process source started by
+// StartProcessEvents.
+func (svc *Service) StopProcessEvents() {
+	if svc.processEventsStop != nil {
+		svc.processEventsStop()
+		<-svc.processEventsDone
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+const (
+	pullIntentsPath       = "/api/v1/decisionmakers/pull/intents"
+	pullNodePoliciesPath  = "/api/v1/decisionmakers/pull/node-intents"
+	pullRuntimeConfigPath = "/api/v1/decisionmakers/pull/runtime-config"
+	defaultPullInterval   = 10 * time.Second
+)
+
+// Puller keeps the Decision Maker in sync with the Manager over outbound
+// requests, for clusters where the Manager cannot reach the node. The Merkle
+// roots of the cached intents and node policies, and the running runtime
+// config version, are sent as If-None-Match, so nothing is sent back while
+// they are current.
+type Puller struct {
+	svc        *Service
+	managerURL string
+	nodeID     string
+	interval   time.Duration
+	client     *http.Client
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewPuller builds the puller described by cfg. It returns nil when
+// cfg.ManagerURL is empty.
+func NewPuller(cfg config.PullConfig, svc *Service) (*Puller, error) {
+	managerURL := strings.TrimRight(strings.TrimSpace(cfg.ManagerURL), "/")
+	if managerURL == "" {
+		return nil, nil
+	}
+	if _, err := url.ParseRequestURI(managerURL); err != nil {
+		return nil, fmt.Errorf("invalid pull manager_url %q: %v", cfg.ManagerURL, err)
+	}
+	nodeID := firstNonEmptyStr(strings.TrimSpace(cfg.NodeName), os.Getenv("NODE_NAME"))
+	if nodeID == "" {
+		return nil, fmt.Errorf("pull node_name is required when NODE_NAME is not set")
+	}
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultPullInterval
+	}
+	return &Puller{
+		svc:        svc,
+		managerURL: managerURL,
+		nodeID:     nodeID,
+		interval:   interval,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Start pulls from the Manager right away and then every interval until
+// Stop. Failed pulls are logged and retried on the next one.
+func (p *Puller) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			if err := p.pull(ctx); err != nil && ctx.Err() == nil {
+				logger.Logger(ctx).Warn().Err(err).Msgf("failed to pull from manager %s", p.managerURL)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the pulls.
+func (p *Puller) Stop() {
+	p.once.Do(func() {
+		if p.cancel != nil {
+			p.cancel()
+			<-p.done
+		}
+	})
+}
+
+func (p *Puller) pull(ctx context.Context) error {
+	token, err := p.svc.generatePullToken(p.nodeID)
+	if err != nil {
+		return err
+	}
+	return errors.Join(
+		p.pullIntents(ctx, token),
+		p.pullNodePolicies(ctx, token),
+		p.pullRuntimeConfig(ctx, token),
+	)
+}
+
+func (p *Puller) pullIntents(ctx context.Context, token string) error {
+	p.svc.intentCacheMu.RLock()
+	etag := p.svc.intentMerkleRootHash
+	p.svc.intentCacheMu.RUnlock()
+
+	var data struct {
+		Intents []*domain.Intent `json:"intents"`
+	}
+	status, err := p.get(ctx, pullIntentsPath, token, etag, &data)
+	if err != nil || status != http.StatusOK {
+		return err
+	}
+	if err := p.svc.ProcessIntents(ctx, data.Intents); err != nil {
+		return fmt.Errorf("process pulled intents: %w", err)
+	}
+	logger.Logger(ctx).Info().Msgf("pulled %d intents from manager", len(data.Intents))
+	return nil
+}
+
+func (p *Puller) pullNodePolicies(ctx context.Context, token string) error {
+	var data struct {
+		Policies []*domain.NodePolicy `json:"policies"`
+	}
+	status, err := p.get(ctx, pullNodePoliciesPath, token, p.svc.GetNodePolicyMerkleRootHash(), &data)
+	if err != nil || status != http.StatusOK {
+		return err
+	}
+	if err := p.svc.ProcessNodePolicies(ctx, data.Policies); err != nil {
+		return fmt.Errorf("process pulled node policies: %w", err)
+	}
+	logger.Logger(ctx).Info().Msgf("pulled %d node policies from manager", len(data.Policies))
+	return nil
+}
+
+func (p *Puller) pullRuntimeConfig(ctx context.Context, token string) error {
+	etag := ""
+	p.svc.runtimeConfigMu.RLock()
+	if p.svc.runtimeConfig != nil {
+		etag = p.svc.runtimeConfig.ConfigVersion
+	}
+	p.svc.runtimeConfigMu.RUnlock()
+
+	var cfg domain.RuntimeSchedulerConfig
+	status, err := p.get(ctx, pullRuntimeConfigPath, token, etag, &cfg)
+	if err != nil || status != http.StatusOK {
+		return err
+	}
+	if err := p.svc.ApplyRuntimeConfig(ctx, cfg); err != nil {
+		return fmt.Errorf("apply pulled runtime config %s: %w", cfg.ConfigVersion, err)
+	}
+	return nil
+}
+
+// get requests path with etag as If-None-Match and decodes the data of a 200
+// response into dst. It returns the status, which is also 204 or 304.
+func (p *Puller) get(ctx context.Context, path, token, etag string, dst any) (int, error) {
+	endpoint := p.managerURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if etag != "" {
+		req.Header.Set("If-None-Match", `"`+etag+`"`)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotModified:
+		return resp.StatusCode, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("GET %s: status %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	body := struct {
+		Data any `json:"data"`
+	}{Data: dst}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode %s: %w", endpoint, err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullerPullsUntilCurrent(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	svc := newIntentWatchTestService(nil)
+	svc.jwtPrivateKey = key
+
+	var (
+		mu          sync.Mutex
+		ifNoneMatch = map[string][]string{}
+	)
+	manager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		claims := &jwt.RegisteredClaims{}
+		_, err := jwt.ParseWithClaims(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), claims,
+			func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil },
+			jwt.WithIssuer(pullTokenIssuer), jwt.WithAudience(pullTokenAudience))
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "node-a", claims.Subject)
+
+		etag := r.Header.Get("If-None-Match")
+		ifNoneMatch[r.URL.Path] = append(ifNoneMatch[r.URL.Path], etag)
+		var data any
+		switch r.URL.Path {
+		case pullIntentsPath:
+			data = map[string]any{"intents": []map[string]any{{"intentID": "i1", "podID": "pod-a", "priority": 1}}}
+		case pullNodePoliciesPath:
+			data = map[string]any{"policies": []map[string]any{{"policyID": "p1", "commandRegex": "^nginx$"}}}
+		default:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if etag != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]any{"success": true, "data": data}))
+	}))
+	defer manager.Close()
+
+	puller, err := NewPuller(config.PullConfig{ManagerURL: manager.URL, NodeName: "node-a"}, svc)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, puller.pull(ctx))
+	require.NoError(t, puller.pull(ctx))
+
+	svc.intentCacheMu.RLock()
+	intents, intentRoot := svc.intentCache, svc.intentMerkleRootHash
+	svc.intentCacheMu.RUnlock()
+	require.Len(t, intents, 1)
+	assert.Equal(t, "i1", intents[0].IntentID)
+	assert.Equal(t, []string{"", `"` + intentRoot + `"`}, ifNoneMatch[pullIntentsPath])
+	assert.Equal(t, []string{"", `"` + svc.GetNodePolicyMerkleRootHash() + `"`}, ifNoneMatch[pullNodePoliciesPath])
+	assert.Len(t, ifNoneMatch[pullRuntimeConfigPath], 2)
+	assert.Nil(t, svc.runtimeConfig)
+}
+
+func TestNewPullerDisabledWithoutManagerURL(t *testing.T) {
+	puller, err := NewPuller(config.PullConfig{}, &Service{})
+	require.NoError(t, err)
+	assert.Nil(t, puller)
+
+	t.Setenv("NODE_NAME", "")
+	_, err = NewPuller(config.PullConfig{ManagerURL: "http://manager:8080"}, &Service{})
+	assert.ErrorContains(t, err, "node_name")
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+// Version is the Decision Maker version reported when registering with the
+// Manager. It can be set at build time with -ldflags "-X".
+var Version = "1.0.0"
+
+const (
+	registrationPath            = "/api/v1/decisionmakers/registrations"
+	defaultRegistrationInterval = 20 * time.Second
+	schedExtSysfsDir            = "/sys/kernel/sched_ext"
+	kernelReleaseFile           = "/proc/sys/kernel/osrelease"
+)
+
+// registrationCapabilities mirrors the capabilities the Manager keeps for
+// every registered Decision Maker.
+type registrationCapabilities struct {
+	SchedExtSupported bool   `json:"schedExtSupported"`
+	MonitorEnabled    bool   `json:"monitorEnabled"`
+	KernelVersion     string `json:"kernelVersion,omitempty"`
+}
+
+type registrationRequest struct {
+	NodeID       string                   `json:"nodeID"`
+	Host         string                   `json:"host"`
+	Port         int                      `json:"port"`
+	Version      string                   `json:"version,omitempty"`
+	Capabilities registrationCapabilities `json:"capabilities"`
+}
+
+// Registrar registers the Decision Maker with the Manager and keeps the
+// registration alive with heartbeats, so the Manager finds it without relying
+// on pod labels.
+type Registrar struct {
+	managerURL string
+	token      string
+	interval   time.Duration
+	request    registrationRequest
+	client     *http.Client
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewRegistrar builds the registrar described by cfg. It returns nil when
+// cfg.ManagerURL is empty.
+func NewRegistrar(cfg config.RegistrationConfig, serverCfg config.ServerConfig) (*Registrar, error) {
+	managerURL := strings.TrimRight(strings.TrimSpace(cfg.ManagerURL), "/")
+	if managerURL == "" {
+		return nil, nil
+	}
+	if _, err := url.ParseRequestURI(managerURL); err != nil {
+		return nil, fmt.Errorf("invalid registration manager_url %q: %v", cfg.ManagerURL, err)
+	}
+
+	nodeName := firstNonEmptyStr(strings.TrimSpace(cfg.NodeName), os.Getenv("NODE_NAME"))
+	if nodeName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve node name: %v", err)
+		}
+		nodeName = hostname
+	}
+	host := firstNonEmptyStr(strings.TrimSpace(cfg.AdvertiseHost), os.Getenv("POD_IP"))
+	if host == "" {
+		return nil, fmt.Errorf("registration advertise_host is required when POD_IP is not set")
+	}
+	port := cfg.AdvertisePort
+	if port == 0 {
+		var err error
+		if port, err = serverPort(serverCfg.Host); err != nil {
+			return nil, err
+		}
+	}
+
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultRegistrationInterval
+	}
+	return &Registrar{
+		managerURL: managerURL,
+		token:      cfg.Token.Value(),
+		interval:   interval,
+		request: registrationRequest{
+			NodeID:  nodeName,
+			Host:    host,
+			Port:    port,
+			Version: Version,
+			Capabilities: registrationCapabilities{
+				SchedExtSupported: schedExtSupported(),
+				MonitorEnabled:    cfg.MonitorEnabled,
+				KernelVersion:     kernelVersion(),
+			},
+		},
+		client: &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Start registers with the Manager and sends a heartbeat every interval
+// until Stop. Failed attempts are logged and retried on the next heartbeat.
+func (r *Registrar) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		registered := false
+		for {
+			if err := r.heartbeat(ctx); err != nil {
+				logger.Logger(ctx).Warn().Err(err).Msgf("failed to register with manager %s", r.managerURL)
+				registered = false
+			} else if !registered {
+				logger.Logger(ctx).Info().Msgf("registered node %s at %s:%d with manager %s", r.request.NodeID, r.request.Host, r.request.Port, r.managerURL)
+				registered = true
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the heartbeats and deregisters from the Manager, so it stops
+// sending intents to this Decision Maker right away.
+func (r *Registrar) Stop(ctx context.Context) error {
+	var err error
+	r.once.Do(func() {
+		if r.cancel != nil {
+			r.cancel()
+			<-r.done
+		}
+		err = r.deregister(ctx)
+	})
+	return err
+}
+
+func (r *Registrar) heartbeat(ctx context.Context) error {
+	body, err := json.Marshal(r.request)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, http.MethodPost, r.managerURL+registrationPath, body)
+}
+
+func (r *Registrar) deregister(ctx context.Context) error {
+	return r.do(ctx, http.MethodDelete, r.managerURL+registrationPath+"/"+url.PathEscape(r.request.NodeID), nil)
+}
+
+func (r *Registrar) do(ctx context.Context, method, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: status %d: %s", method, endpoint, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// serverPort returns the port of server.host, 8082 when it is empty.
+func serverPort(hostPort string) (int, error) {
+	if hostPort == "" {
+		return 8082, nil
+	}
+	_, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse server host %q: %v", hostPort, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse server port %q: %v", portStr, err)
+	}
+	return port, nil
+}
+
+// schedExtSupported reports whether the kernel was built with sched_ext.
+func schedExtSupported() bool {
+	_, err := os.Stat(schedExtSysfsDir)
+	return err == nil
+}
+
+func kernelVersion() string {
+	data, err := os.ReadFile(kernelReleaseFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
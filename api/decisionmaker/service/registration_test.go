@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrarRegistersAndDeregisters(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		body     registrationRequest
+	)
+	registered := make(chan struct{}, 1)
+	manager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			select {
+			case registered <- struct{}{}:
+			default:
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer manager.Close()
+
+	registrar, err := NewRegistrar(config.RegistrationConfig{
+		ManagerURL:     manager.URL + "/",
+		Token:          "secret",
+		NodeName:       "node-a",
+		AdvertiseHost:  "10.0.0.1",
+		MonitorEnabled: true,
+	}, config.ServerConfig{Host: ":8083"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	registrar.Start(ctx)
+	<-registered
+	require.NoError(t, registrar.Stop(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "node-a", body.NodeID)
+	assert.Equal(t, "10.0.0.1", body.Host)
+	assert.Equal(t, 8083, body.Port)
+	assert.Equal(t, Version, body.Version)
+	assert.True(t, body.Capabilities.MonitorEnabled)
+	require.GreaterOrEqual(t, len(requests), 2)
+	assert.Equal(t, "POST /api/v1/decisionmakers/registrations", requests[0])
+	assert.Equal(t, "DELETE /api/v1/decisionmakers/registrations/node-a", requests[len(requests)-1])
+}
+
+func TestNewRegistrarDisabledWithoutManagerURL(t *testing.T) {
+	registrar, err := NewRegistrar(config.RegistrationConfig{}, config.ServerConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, registrar)
+}
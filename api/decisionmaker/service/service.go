@@ -29,6 +29,7 @@ type Params struct {
 	fx.In
 	TokenConfig  config.TokenConfig
 	DaemonConfig config.DaemonConfig
+	StateConfig  config.StateConfig
 }
 
 func NewService(params Params) (*Service, error) {
@@ -37,6 +38,7 @@ func NewService(params Params) (*Service, error) {
 		return nil, fmt.Errorf("failed to initialize JWT private key: %v", err)
 	}
 	machineID := util.GetMachineID()
+	processEvents := NewEventProcessSource(NewProcScanSource(procDir))
 	svc := &Service{
 		schedulingIntentsMap: util.NewGenericMap[string, []*domain.SchedulingIntents](),
 		metricCollector:      NewMetricCollector(machineID),
@@ -46,11 +48,20 @@ func NewService(params Params) (*Service, error) {
 		daemonHTTPClient: &http.Client{
 			Timeout: time.Duration(max(params.DaemonConfig.TimeoutSec, 5)) * time.Second,
 		},
-		processSource: NewProcScanSource(procDir),
+		processSource: processEvents,
+		processEvents: processEvents,
+		statePath:     params.StateConfig.Path,
 	}
+	svc.processIndex = newProcessIndex(svc.processSource, func(ctx context.Context, pid int) (map[string]*domain.PodInfo, error) {
+		return svc.scanPodProcess(ctx, procDir, pid)
+	})
 	if svc.daemonEndpoint == "" {
 		svc.daemonEndpoint = "http://127.0.0.1:18080"
 	}
+	if err := svc.restoreIntentState(context.Background()); err != nil {
+		// Start empty; the Manager resends everything on its next reconcile.
+		logger.Logger(context.Background()).Warn().Err(err).Msg("failed to restore intent state")
+	}
 
 	err = prometheus.Register(svc.metricCollector)
 	if err != nil {
@@ -81,10 +92,35 @@ type Service struct {
 	// Node-level scheduling policies (target arbitrary processes on this
 	// node, not just Pod container processes). See node_policy_svc.go.
 	processSource            ProcessSource
+	processIndex             *processIndex
+	processEvents            *EventProcessSource
+	processEventsStop        context.CancelFunc
+	processEventsDone        chan struct{}
 	nodePolicyCacheMu        sync.RWMutex
 	nodePolicyCache          []*domain.NodePolicy
 	nodePolicyMerkleRoot     *util.MerkleNode
 	nodePolicyMerkleRootHash string
+
+	// Conflicts found by the last ListAllSchedulingIntents, see
+	// intent_precedence.go.
+	intentConflictsMu sync.RWMutex
+	intentConflicts   []*domain.IntentConflict
+
+	// Apply status of every intent as of the last ListAllSchedulingIntents,
+	// keyed by intent ID, the watch revision it was resolved for and the
+	// last revision the scheduler acknowledged, see intent_status.go.
+	intentStatusMu       sync.RWMutex
+	intentStatuses       map[string]*domain.IntentApplyStatus
+	intentStatusRevision uint64
+	intentAckedRevision  uint64
+
+	// Watchers of the resolved scheduling intents, see intent_watch.go.
+	intentWatch intentWatchHub
+
+	// Persisted intents and node policies, see state_store.go.
+	statePath     string
+	stateMu       sync.Mutex
+	stateRevision uint64
 }
 
 const (
@@ -96,36 +132,43 @@ const (
 // from the cached domain.Intent list, since pod processes may change over time.
 // It also merges in scheduling intents produced by node-level scheduling
 // policies (see node_policy_svc.go), which target arbitrary processes on the
-// node rather than Pod container processes.
+// node rather than Pod container processes. When several intents target the
+// same process only the one that wins precedence is returned, and the others
+// are kept for ListIntentConflicts. What became of every intent is kept for
+// ListIntentStatuses.
 func (svc *Service) ListAllSchedulingIntents(ctx context.Context) ([]*domain.SchedulingIntents, error) {
 	svc.intentCacheMu.RLock()
 	cachedIntents := svc.intentCache
 	svc.intentCacheMu.RUnlock()
 
-	var podSchedulingIntents []*domain.SchedulingIntents
+	var (
+		candidates []*intentCandidate
+		podInfos   map[string]*domain.PodInfo
+	)
 	if len(cachedIntents) > 0 {
-		podInfos, err := svc.GetAllPodInfos(ctx)
+		var err error
+		podInfos, err = svc.GetAllPodInfos(ctx)
 		if err != nil {
+			svc.recordIntentStatuses(cachedIntents, nil, nil, nil, err)
 			return nil, err
 		}
 
 		svc.podSchedCollector.UpdatePodTargets(cachedIntents, podInfos)
-		podSchedulingIntents = svc.resolveSchedulingIntents(ctx, cachedIntents, podInfos)
+		candidates = svc.podIntentCandidates(ctx, cachedIntents, podInfos)
 	} else {
 		svc.podSchedCollector.UpdatePodTargets(nil, nil)
-		svc.schedulingIntentsMap.Clear()
 	}
 
-	nodeSchedulingIntents, err := svc.resolveNodeSchedulingIntents(ctx)
+	nodeCandidates, err := svc.nodePolicyCandidates(ctx)
 	if err != nil {
 		logger.Logger(ctx).Warn().Err(err).Msg("failed to resolve node scheduling intents")
-		nodeSchedulingIntents = nil
+		nodeCandidates = nil
 	}
 
-	if len(nodeSchedulingIntents) == 0 {
-		return podSchedulingIntents, nil
-	}
-	return append(podSchedulingIntents, nodeSchedulingIntents...), nil
+	schedulingIntents, conflicts := svc.applyIntentCandidates(ctx, append(candidates, nodeCandidates...))
+	svc.setIntentConflicts(conflicts)
+	svc.recordIntentStatuses(cachedIntents, podInfos, candidates, conflicts, nil)
+	return schedulingIntents, nil
 }
 
 // ProcessIntents processes a list of scheduling intents and updates the internal map
@@ -144,6 +187,7 @@ func (svc *Service) ProcessIntents(ctx context.Context, intents []*domain.Intent
 	}
 	root := util.BuildMerkleTree(leafHashes)
 	svc.intentCacheMu.Lock()
+	changedPods := changedIntentPods(svc.intentCache, normalizedIntents)
 	svc.intentCache = normalizedIntents
 	svc.intentMerkleRoot = root
 	if root != nil {
@@ -152,25 +196,36 @@ func (svc *Service) ProcessIntents(ctx context.Context, intents []*domain.Intent
 		svc.intentMerkleRootHash = ""
 	}
 	svc.intentCacheMu.Unlock()
+	svc.invalidatePodProcesses(changedPods...)
 	svc.resolveSchedulingIntents(ctx, normalizedIntents, podInfos)
 	svc.podSchedCollector.UpdatePodTargets(normalizedIntents, podInfos)
 	logger.Logger(ctx).Info().Msgf("Discovered pods: %+v", podInfos)
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
 // resolveSchedulingIntents converts domain.Intents + PodInfos into SchedulingIntents,
 // updates the schedulingIntentsMap and returns all resolved scheduling intents.
 func (svc *Service) resolveSchedulingIntents(ctx context.Context, intents []*domain.Intent, podInfos map[string]*domain.PodInfo) []*domain.SchedulingIntents {
-	svc.schedulingIntentsMap.Clear()
-	var allSchedulingIntents []*domain.SchedulingIntents
+	schedulingIntents, _ := svc.applyIntentCandidates(ctx, svc.podIntentCandidates(ctx, intents, podInfos))
+	return schedulingIntents
+}
+
+// podIntentCandidates matches every intent's command regex against the
+// processes of its pod and returns the claims to be resolved.
+func (svc *Service) podIntentCandidates(ctx context.Context, intents []*domain.Intent, podInfos map[string]*domain.PodInfo) []*intentCandidate {
+	var candidates []*intentCandidate
 	for _, intent := range intents {
 		podInfo := podInfos[intent.PodID]
 		logger.Logger(ctx).Info().Msgf("Processing intent for PodName:%s PodID: %s on NodeID: %s, Process:%+v", intent.PodName, intent.PodID, intent.NodeID, podInfo)
-		commandRegex, err := regexp.Compile(intent.CommandRegex)
+		commandRegex, err := svc.compileCommandRegex(intent.CommandRegex)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msgf("invalid commandRegex %q for pod %s", intent.CommandRegex, intent.PodID)
 			continue
 		}
+		if podInfo == nil || len(podInfo.Processes) == 0 {
+			continue
+		}
 		labels := []domain.LabelSelector{}
 		for key, value := range intent.PodLabels {
 			labels = append(labels, domain.LabelSelector{
@@ -178,32 +233,41 @@ func (svc *Service) resolveSchedulingIntents(ctx context.Context, intents []*dom
 				Value: value,
 			})
 		}
-		if podInfo != nil && len(podInfo.Processes) > 0 {
-			for _, process := range podInfo.Processes {
-				if process.Command == pauseCommand {
-					continue
-				}
-				if !commandRegex.MatchString(process.Command) {
-					continue
-				}
-				schedulingIntent := &domain.SchedulingIntents{
-					Priority:      intent.Priority,
-					ExecutionTime: uint64(intent.ExecutionTime),
-					PID:           process.PID,
-					CommandRegex:  intent.CommandRegex,
-					Selectors:     labels,
-				}
-				logger.Logger(ctx).Info().Msgf("Created SchedulingIntent: %+v for Process PID: %d", schedulingIntent, process.PID)
-				svc.schedulingIntentsMap.Store(fmt.Sprintf("%s-%d", intent.PodID, process.PID), []*domain.SchedulingIntents{schedulingIntent})
-				allSchedulingIntents = append(allSchedulingIntents, schedulingIntent)
+		base := intentCandidate{
+			rank: intentRank(intent),
+			source: domain.IntentSource{
+				Kind:       domain.IntentSourceStrategy,
+				ID:         intent.IntentID,
+				StrategyID: intent.StrategyID,
+				PodID:      intent.PodID,
+			},
+			template: domain.SchedulingIntents{
+				Priority:      intent.Priority,
+				ExecutionTime: uint64(intent.ExecutionTime),
+				CommandRegex:  intent.CommandRegex,
+				Selectors:     labels,
+			},
+		}
+		candidate := base
+		for _, process := range podInfo.Processes {
+			if process.Command == pauseCommand || !svc.commandMatches(commandRegex, process.PID, process.Command) {
+				continue
 			}
+			candidate.pids = append(candidate.pids, process.PID)
+		}
+		if len(candidate.pids) > 0 {
+			candidates = append(candidates, &candidate)
 		}
 	}
-	return allSchedulingIntents
+	return candidates
 }
 
-// GetAllPodInfos retrieves all pod information by scanning the /proc filesystem
+// GetAllPodInfos retrieves all pod information from the process index, which
+// only reads /proc for processes it has not seen yet (see process_index.go).
 func (svc *Service) GetAllPodInfos(ctx context.Context) (map[string]*domain.PodInfo, error) {
+	if svc.processIndex != nil {
+		return svc.processIndex.podInfos(ctx)
+	}
 	return svc.FindPodInfoFrom(ctx, procDir)
 }
 
@@ -229,33 +293,49 @@ func (svc *Service) FindPodInfoFrom(ctx context.Context, rootDir string) (map[st
 			continue
 		}
 
-		// Read cgroup information for this process
-		cgroupPath := fmt.Sprintf("%s/%d/cgroup", rootDir, pid)
-		file, err := os.Open(cgroupPath)
+		pods, err := svc.scanPodProcess(ctx, rootDir, pid)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msgf("failed to open cgroup file for pid %d", pid)
 			continue
 		}
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			logger.Logger(ctx).Debug().Msgf("cgroup line for pid %d: %s", pid, line)
-			if strings.Contains(line, "kubepods") {
-				err = svc.parseCgroupToPodInfo(rootDir, line, pid, podMap)
-				if err != nil {
-					logger.Logger(ctx).Warn().Err(err).Msgf("failed to parse cgroup line for pid %d, line:%s", pid, line)
-					break
-				}
+		for podUID, fragment := range pods {
+			if podInfo, exists := podMap[podUID]; exists {
+				podInfo.Processes = append(podInfo.Processes, fragment.Processes...)
+			} else {
+				podMap[podUID] = fragment
 			}
 		}
-		if err := scanner.Err(); err != nil {
-		}
-		_ = file.Close()
 	}
 
 	return podMap, nil
 }
 
+// scanPodProcess reads the cgroup file of pid under rootDir and returns the
+// pods it belongs to with its process information.
+func (svc *Service) scanPodProcess(ctx context.Context, rootDir string, pid int) (map[string]*domain.PodInfo, error) {
+	cgroupPath := fmt.Sprintf("%s/%d/cgroup", rootDir, pid)
+	file, err := os.Open(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	podMap := make(map[string]*domain.PodInfo)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		logger.Logger(ctx).Debug().Msgf("cgroup line for pid %d: %s", pid, line)
+		if strings.Contains(line, "kubepods") {
+			err = svc.parseCgroupToPodInfo(rootDir, line, pid, podMap)
+			if err != nil {
+				logger.Logger(ctx).Warn().Err(err).Msgf("failed to parse cgroup line for pid %d, line:%s", pid, line)
+				break
+			}
+		}
+	}
+	return podMap, nil
+}
+
 // parseCgroupToPodInfo parses a cgroup line (e.g // 0::/kubelet.slice/kubelet-kubepods.slice/kubelet-kubepods-pod20da609e_6973_4463_a1f9_2db9bcc5becc.slice/cri-containerd-10ec3c89629f71226b227e6510b2d465168b24005bbdcc5d7940517080830635.scope) to extract pod info and updates the podInfoMap
 func (svc *Service) parseCgroupToPodInfo(rootDir string, line string, pid int, podInfoMap map[string]*domain.PodInfo) error {
 	parts := strings.Split(line, ":")
@@ -370,7 +450,7 @@ func hashIntent(intent *domain.Intent) string {
 		labels = append(labels, key+"="+value)
 	}
 	sort.Strings(labels)
-	serialized := strings.Join([]string{
+	fields := []string{
 		"podName=" + intent.PodName,
 		"podID=" + intent.PodID,
 		"nodeID=" + intent.NodeID,
@@ -379,8 +459,16 @@ func hashIntent(intent *domain.Intent) string {
 		"priority=" + strconv.Itoa(intent.Priority),
 		"executionTime=" + strconv.FormatInt(intent.ExecutionTime, 10),
 		"podLabels=" + strings.Join(labels, ","),
-	}, "|")
-	return util.HashStringSHA256Hex(serialized)
+	}
+	// Must match the manager's hashScheduleIntent: only non-zero ranks are
+	// part of the hash.
+	if intent.Precedence != 0 {
+		fields = append(fields, "precedence="+strconv.Itoa(intent.Precedence))
+	}
+	if intent.Specificity != 0 {
+		fields = append(fields, "specificity="+strconv.Itoa(intent.Specificity))
+	}
+	return util.HashStringSHA256Hex(strings.Join(fields, "|"))
 }
 
 func intentSortKey(intent *domain.Intent) string {
@@ -389,7 +477,7 @@ func intentSortKey(intent *domain.Intent) string {
 		labels = append(labels, key+"="+value)
 	}
 	sort.Strings(labels)
-	return strings.Join([]string{
+	key := []string{
 		intent.PodName,
 		intent.PodID,
 		intent.NodeID,
@@ -398,7 +486,14 @@ func intentSortKey(intent *domain.Intent) string {
 		strconv.Itoa(intent.Priority),
 		strconv.FormatInt(intent.ExecutionTime, 10),
 		strings.Join(labels, ","),
-	}, "|")
+	}
+	if intent.Precedence != 0 {
+		key = append(key, strconv.Itoa(intent.Precedence))
+	}
+	if intent.Specificity != 0 {
+		key = append(key, strconv.Itoa(intent.Specificity))
+	}
+	return strings.Join(key, "|")
 }
 
 func (svc *Service) refreshIntentMerkleTreeIfNeeded() {
@@ -446,6 +541,7 @@ func (svc *Service) DeleteIntentByPodID(ctx context.Context, podID string) error
 	svc.intentCache = remaining
 	svc.rebuildIntentMerkleTreeLocked()
 	svc.intentCacheMu.Unlock()
+	svc.invalidatePodProcesses(podID)
 
 	keysToDelete := []string{}
 	svc.schedulingIntentsMap.Range(func(key string, value []*domain.SchedulingIntents) bool {
@@ -459,6 +555,7 @@ func (svc *Service) DeleteIntentByPodID(ctx context.Context, podID string) error
 	}
 	svc.removePodMetricTarget(podID)
 	logger.Logger(ctx).Info().Msgf("Deleted %d scheduling intents for pod ID: %s", len(keysToDelete), podID)
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
@@ -473,9 +570,11 @@ func (svc *Service) DeleteIntentByPID(ctx context.Context, podID string, pid int
 // DeleteAllIntents clears all scheduling intents
 func (svc *Service) DeleteAllIntents(ctx context.Context) error {
 	svc.intentCacheMu.Lock()
+	changedPods := changedIntentPods(svc.intentCache, nil)
 	svc.intentCache = nil
 	svc.rebuildIntentMerkleTreeLocked()
 	svc.intentCacheMu.Unlock()
+	svc.invalidatePodProcesses(changedPods...)
 
 	keysToDelete := []string{}
 	svc.schedulingIntentsMap.Range(func(key string, value []*domain.SchedulingIntents) bool {
@@ -489,9 +588,33 @@ func (svc *Service) DeleteAllIntents(ctx context.Context) error {
 	svc.podSchedCollector.UpdatePodTargets(nil, nil)
 
 	logger.Logger(ctx).Info().Msgf("Deleted all %d scheduling intents", len(keysToDelete))
+	svc.intentStateChanged(ctx)
 	return nil
 }
 
+// changedIntentPods returns the pods that have intents in only one of prev
+// and next.
+func changedIntentPods(prev, next []*domain.Intent) []string {
+	pods := make(map[string]int)
+	for _, intent := range prev {
+		if intent != nil {
+			pods[intent.PodID] |= 1
+		}
+	}
+	for _, intent := range next {
+		if intent != nil {
+			pods[intent.PodID] |= 2
+		}
+	}
+	var changed []string
+	for podID, seen := range pods {
+		if seen != 3 {
+			changed = append(changed, podID)
+		}
+	}
+	return changed
+}
+
 func (svc *Service) rebuildIntentMerkleTreeLocked() {
 	sorted := sortIntentsByKey(normalizeIntentInputs(svc.intentCache))
 	leafHashes := make([]string, 0, len(sorted))
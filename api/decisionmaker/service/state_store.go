@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+const intentStateVersion = 1
+
+// intentState is the on-disk form of the intents and node policies the
+// Manager sent. It is restored on startup so the node keeps scheduling by
+// them until the Manager's next reconcile compares the merkle roots and sends
+// whatever changed while the Decision Maker was down.
+type intentState struct {
+	Version      int                  `json:"version"`
+	Revision     uint64               `json:"revision"`
+	SavedAt      time.Time            `json:"savedAt"`
+	Intents      []*domain.Intent     `json:"intents"`
+	NodePolicies []*domain.NodePolicy `json:"nodePolicies"`
+}
+
+// intentStateChanged persists the cached intents and node policies and wakes
+// up the intent watchers. Callers must not hold intentCacheMu or
+// nodePolicyCacheMu.
+func (svc *Service) intentStateChanged(ctx context.Context) {
+	if err := svc.saveIntentState(); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Str("path", svc.statePath).Msg("failed to persist intent state")
+	}
+	svc.notifyIntentWatch()
+}
+
+// saveIntentState writes the cached intents and node policies to
+// svc.statePath, replacing the previous file atomically.
+func (svc *Service) saveIntentState() error {
+	if svc.statePath == "" {
+		return nil
+	}
+	svc.stateMu.Lock()
+	defer svc.stateMu.Unlock()
+
+	svc.intentCacheMu.RLock()
+	intents := append([]*domain.Intent(nil), svc.intentCache...)
+	svc.intentCacheMu.RUnlock()
+	svc.nodePolicyCacheMu.RLock()
+	policies := append([]*domain.NodePolicy(nil), svc.nodePolicyCache...)
+	svc.nodePolicyCacheMu.RUnlock()
+
+	data, err := json.Marshal(intentState{
+		Version:      intentStateVersion,
+		Revision:     svc.stateRevision + 1,
+		SavedAt:      time.Now().UTC(),
+		Intents:      intents,
+		NodePolicies: policies,
+	})
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(svc.statePath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(svc.statePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), svc.statePath); err != nil {
+		return err
+	}
+	svc.stateRevision++
+	return nil
+}
+
+// restoreIntentState loads the intents and node policies saved by
+// saveIntentState, if any, and rebuilds their merkle roots.
+func (svc *Service) restoreIntentState(ctx context.Context) error {
+	if svc.statePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(svc.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state intentState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("decode %s: %w", svc.statePath, err)
+	}
+	if state.Version != intentStateVersion {
+		return fmt.Errorf("unsupported intent state version %d", state.Version)
+	}
+
+	svc.intentCacheMu.Lock()
+	svc.intentCache = normalizeIntentInputs(state.Intents)
+	svc.rebuildIntentMerkleTreeLocked()
+	intentRoot := svc.intentMerkleRootHash
+	svc.intentCacheMu.Unlock()
+
+	svc.nodePolicyCacheMu.Lock()
+	svc.nodePolicyCache = normalizeNodePolicyInputs(state.NodePolicies)
+	svc.rebuildNodePolicyMerkleRootLocked()
+	nodePolicyRoot := svc.nodePolicyMerkleRootHash
+	svc.nodePolicyCacheMu.Unlock()
+
+	svc.stateMu.Lock()
+	svc.stateRevision = state.Revision
+	svc.stateMu.Unlock()
+
+	logger.Logger(ctx).Info().Msgf(
+		"restored intent state revision %d saved at %s: %d intents (root %q), %d node policies (root %q); the manager re-verifies them on its next reconcile",
+		state.Revision, state.SavedAt.Format(time.RFC3339), len(state.Intents), intentRoot, len(state.NodePolicies), nodePolicyRoot)
+	return nil
+}
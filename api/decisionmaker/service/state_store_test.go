@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gthulhu/api/decisionmaker/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntentStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	statePath := filepath.Join(t.TempDir(), "dm", "state.json")
+	svc := newIntentWatchTestService(nil)
+	svc.statePath = statePath
+	svc.intentCache = []*domain.Intent{
+		{IntentID: "i1", PodID: "pod-a", Priority: 1, ExecutionTime: 1000},
+		{IntentID: "i2", PodID: "pod-b", Priority: 2, ExecutionTime: 2000},
+	}
+
+	require.NoError(t, svc.ProcessNodePolicies(ctx, []*domain.NodePolicy{
+		{PolicyID: "p1", CommandRegex: "^nginx$", Priority: 1, ExecutionTime: 1000},
+	}))
+	require.NoError(t, svc.DeleteIntentByPodID(ctx, "pod-b"))
+	assert.Equal(t, uint64(2), svc.stateRevision)
+
+	info, err := os.Stat(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	restarted := newIntentWatchTestService(nil)
+	restarted.statePath = statePath
+	require.NoError(t, restarted.restoreIntentState(ctx))
+	assert.Equal(t, uint64(2), restarted.stateRevision)
+	require.Len(t, restarted.intentCache, 1)
+	assert.Equal(t, "i1", restarted.intentCache[0].IntentID)
+	assert.Equal(t, svc.intentMerkleRootHash, restarted.intentMerkleRootHash)
+	assert.Equal(t, svc.GetNodePolicyMerkleRootHash(), restarted.GetNodePolicyMerkleRootHash())
+
+	// The next change continues from the restored revision.
+	require.NoError(t, restarted.DeleteAllNodePolicies(ctx))
+	assert.Equal(t, uint64(3), restarted.stateRevision)
+}
+
+func TestRestoreIntentStateWithoutFile(t *testing.T) {
+	svc := &Service{statePath: filepath.Join(t.TempDir(), "state.json")}
+	require.NoError(t, svc.restoreIntentState(context.Background()))
+	assert.Empty(t, svc.intentCache)
+	assert.Empty(t, svc.intentMerkleRootHash)
+}
+
+func TestRestoreIntentStateRejectsCorruptFile(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte("{"), 0o600))
+	svc := &Service{statePath: statePath}
+	assert.Error(t, svc.restoreIntentState(context.Background()))
+	assert.Empty(t, svc.intentCache)
+}
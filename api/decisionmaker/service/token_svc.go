@@ -69,3 +69,32 @@ type Claims struct {
 	ClientID string `json:"client_id"`
 	jwt.RegisteredClaims
 }
+
+const (
+	// pullTokenIssuer and pullTokenAudience mark the tokens the Decision
+	// Maker pulls from the Manager with; manager/service verifies them the
+	// same way.
+	pullTokenIssuer   = "decision-maker-service"
+	pullTokenAudience = "gthulhu-manager"
+	pullTokenTTL      = 5 * time.Minute
+)
+
+// generatePullToken signs a short-lived JWT for nodeID with the Decision
+// Maker's private key. The Manager verifies it with the matching public key
+// it already holds to request tokens from the Decision Maker.
+func (svc *Service) generatePullToken(nodeID string) (string, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(pullTokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    pullTokenIssuer,
+		Subject:   nodeID,
+		Audience:  jwt.ClaimStrings{pullTokenAudience},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(svc.jwtPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign pull token: %v", err)
+	}
+	return token, nil
+}
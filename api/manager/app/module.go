@@ -1,6 +1,10 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/manager/client"
 	"github.com/Gthulhu/api/manager/domain"
@@ -40,18 +44,25 @@ func ConfigModule(cfg config.ManageConfig) (fx.Option, error) {
 		fx.Provide(func(managerCfg config.ManageConfig) config.MTLSConfig {
 			return managerCfg.MTLS
 		}),
+		fx.Provide(func(managerCfg config.ManageConfig) config.ClassifierConfig {
+			return managerCfg.Classifier
+		}),
+		fx.Provide(func(managerCfg config.ManageConfig) config.KEDAConfig {
+			return managerCfg.KEDA
+		}),
+		fx.Provide(func(managerCfg config.ManageConfig) config.DiscoveryConfig {
+			return managerCfg.Discovery
+		}),
+		fx.Provide(func(managerCfg config.ManageConfig) config.LeaderElectionConfig {
+			return managerCfg.LeaderElection
+		}),
 	), nil
 }
 
 // AdapterModule creates an Fx module that provides the K8S adapter and Decision Maker client
 func AdapterModule() (fx.Option, error) {
 	return fx.Options(
-		fx.Provide(func(k8sConfig config.K8SConfig) (domain.K8SAdapter, error) {
-			return k8sadapter.NewAdapter(k8sadapter.Options{
-				KubeConfigPath: k8sConfig.KubeConfigPath,
-				InCluster:      k8sConfig.IsInCluster,
-			})
-		}),
+		fx.Provide(NewK8SAdapter),
 		fx.Provide(func(k8sConfig config.K8SConfig) (dynamic.Interface, error) {
 			return k8sadapter.NewDynamicClient(k8sadapter.Options{
 				KubeConfigPath: k8sConfig.KubeConfigPath,
@@ -59,9 +70,55 @@ func AdapterModule() (fx.Option, error) {
 			})
 		}),
 		fx.Provide(client.NewDecisionMakerClient),
+		fx.Provide(NewLeadership),
 	), nil
 }
 
+// NewLeadership returns the leadership of this manager replica, which leads
+// from the start unless leader election is enabled.
+func NewLeadership(cfg config.LeaderElectionConfig) *domain.Leadership {
+	return domain.NewLeadership(cfg.Enabled, cfg.ResolvedIdentity())
+}
+
+// NewK8SAdapter federates the cluster the manager runs in with the clusters
+// whose kubeconfigs are in k8s.clusters_dir, each with its own adapter. It
+// fails when the clusters share a node name, since nodes are told apart by
+// name only.
+func NewK8SAdapter(k8sConfig config.K8SConfig) (domain.K8SAdapter, error) {
+	local, err := k8sadapter.NewAdapter(k8sadapter.Options{
+		KubeConfigPath: k8sConfig.KubeConfigPath,
+		InCluster:      k8sConfig.IsInCluster,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusters := []k8sadapter.Cluster{{Name: k8sConfig.LocalClusterName(), Adapter: local}}
+
+	remotes, err := k8sadapter.LoadClusterKubeConfigs(k8sConfig.ClustersDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, remote := range remotes {
+		adapter, err := k8sadapter.NewAdapter(remote.Options)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", remote.Name, err)
+		}
+		clusters = append(clusters, k8sadapter.Cluster{Name: remote.Name, Adapter: adapter})
+	}
+	federated, err := k8sadapter.NewMultiClusterAdapter(clusters...)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) > 1 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := federated.VerifyUniqueNodeNames(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return federated, nil
+}
+
 // RepoModule creates an Fx module that provides the repository layer, return repository.Repository
 func RepoModule(cfg config.ManageConfig) (fx.Option, error) {
 	configModule, err := ConfigModule(cfg)
@@ -122,6 +179,8 @@ func NewFakeDynamicClient() dynamic.Interface {
 		map[schema.GroupVersionResource]string{
 			{Group: "gthulhu.io", Version: "v1alpha1", Resource: "schedulingstrategies"}: "SchedulingStrategyList",
 			{Group: "gthulhu.io", Version: "v1alpha1", Resource: "schedulingintents"}:    "SchedulingIntentList",
+			{Group: "gthulhu.io", Version: "v1alpha1", Resource: "podschedulingmetrics"}: "PodSchedulingMetricsList",
+			{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}:           "ScaledObjectList",
 		},
 	)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/manager/domain"
+	k8sadapter "github.com/Gthulhu/api/manager/k8s_adapter"
 	"github.com/Gthulhu/api/manager/migration"
 	"github.com/Gthulhu/api/manager/rest"
 	"github.com/Gthulhu/api/pkg/logger"
@@ -19,6 +20,11 @@ const (
 
 	classifierFeedInterval    = 10 * time.Second
 	classifierFeedInitialWait = 10 * time.Second
+
+	rolloutInterval    = 15 * time.Second
+	rolloutInitialWait = 5 * time.Second
+
+	scaledObjectInitialWait = 5 * time.Second
 )
 
 func NewRestApp(configName string, configDirPath string) (*fx.App, error) {
@@ -51,8 +57,13 @@ func NewRestApp(configName string, configDirPath string) (*fx.App, error) {
 		handlerModule,
 		fx.Invoke(migration.RunMongoMigration),
 		fx.Invoke(StartRestApp),
+		fx.Invoke(StartLeaderElection),
 		fx.Invoke(StartIntentReconciler),
+		fx.Invoke(StartPodEventReconciler),
+		fx.Invoke(StartClassifierStateSync),
 		fx.Invoke(StartClassifierFeeder),
+		fx.Invoke(StartRuntimeConfigRolloutController),
+		fx.Invoke(StartScaledObjectReconciler),
 	)
 	return app, nil
 }
@@ -85,12 +96,54 @@ func StartRestApp(lc fx.Lifecycle, cfg config.ServerConfig, handler *rest.Handle
 	return nil
 }
 
+// StartLeaderElection campaigns for the manager Lease in the background when
+// leader election is enabled, recording the outcome in leadership. The Lease
+// is released on shutdown so that another replica takes over right away.
+func StartLeaderElection(lc fx.Lifecycle, cfg config.LeaderElectionConfig, k8sConfig config.K8SConfig, leadership *domain.Leadership) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	elector, err := k8sadapter.NewLeaderElector(cfg, k8sadapter.Options{
+		KubeConfigPath: k8sConfig.KubeConfigPath,
+		InCluster:      k8sConfig.IsInCluster,
+	}, leadership)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			logger.Logger(ctx).Info().Msgf("leader election starting, lease %s/%s, identity %s", cfg.ResolvedLeaseNamespace(), cfg.ResolvedLeaseName(), cfg.ResolvedIdentity())
+			go func() {
+				defer close(doneCh)
+				elector.Run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-doneCh:
+			case <-stopCtx.Done():
+			}
+			logger.Logger(stopCtx).Info().Msg("leader election stopped")
+			return nil
+		},
+	})
+
+	return nil
+}
+
 // StartIntentReconciler starts a background goroutine that periodically
 // reconciles scheduling intents. This handles:
 // - Manager restart: re-sends all intents from DB to DM pods
 // - Decision Maker restart: detects Merkle root mismatch and re-sends intents
 // - Pod restart: detects stale intents and creates new ones for replacement pods
-func StartIntentReconciler(lc fx.Lifecycle, svc domain.Service) error {
+//
+// Reconciliation, which also resyncs runtime configs, only runs on the leader.
+func StartIntentReconciler(lc fx.Lifecycle, svc domain.Service, leadership *domain.Leadership) error {
 	stopCh := make(chan struct{})
 
 	lc.Append(fx.Hook{
@@ -107,9 +160,11 @@ func StartIntentReconciler(lc fx.Lifecycle, svc domain.Service) error {
 				}
 
 				// Run initial reconciliation on startup
-				logger.Logger(bgCtx).Info().Msg("running initial intent reconciliation")
-				if err := svc.ReconcileIntents(bgCtx); err != nil {
-					logger.Logger(bgCtx).Warn().Err(err).Msg("initial intent reconciliation failed")
+				if leadership.IsLeader() {
+					logger.Logger(bgCtx).Info().Msg("running initial intent reconciliation")
+					if err := svc.ReconcileIntents(bgCtx); err != nil {
+						logger.Logger(bgCtx).Warn().Err(err).Msg("initial intent reconciliation failed")
+					}
 				}
 
 				ticker := time.NewTicker(reconcileInterval)
@@ -117,6 +172,9 @@ func StartIntentReconciler(lc fx.Lifecycle, svc domain.Service) error {
 				for {
 					select {
 					case <-ticker.C:
+						if !leadership.IsLeader() {
+							continue
+						}
 						if err := svc.ReconcileIntents(bgCtx); err != nil {
 							logger.Logger(bgCtx).Warn().Err(err).Msg("periodic intent reconciliation failed")
 						}
@@ -137,11 +195,73 @@ func StartIntentReconciler(lc fx.Lifecycle, svc domain.Service) error {
 	return nil
 }
 
+// StartPodEventReconciler reconciles the intents of the strategies and nodes
+// a pod event affects as soon as pods are added, deleted, relabelled or
+// scheduled, so that a new replica gets its intents within about a second
+// instead of on the next periodic reconciliation, which stays as a safety
+// net. Events are only queued on the leader.
+func StartPodEventReconciler(lc fx.Lifecycle, svc domain.Service, k8sAdapter domain.K8SAdapter, leadership *domain.Leadership) error {
+	source, ok := k8sAdapter.(interface {
+		SetPodEventHandler(handler func(domain.PodEvent))
+	})
+	if !ok {
+		return nil
+	}
+	reconciler, ok := svc.(interface {
+		EnqueuePodEvent(event domain.PodEvent)
+		RunPodEventReconciler(ctx context.Context)
+	})
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			source.SetPodEventHandler(func(event domain.PodEvent) {
+				if leadership.IsLeader() {
+					reconciler.EnqueuePodEvent(event)
+				}
+			})
+			logger.Logger(ctx).Info().Msg("pod event reconciler starting")
+			go func() {
+				defer close(doneCh)
+				reconciler.RunPodEventReconciler(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-doneCh:
+			case <-stopCtx.Done():
+			}
+			logger.Logger(stopCtx).Info().Msg("pod event reconciler stopped")
+			return nil
+		},
+	})
+
+	return nil
+}
+
 // StartClassifierFeeder starts a background goroutine that periodically
 // fetches pod scheduling metrics from decision makers and feeds them into the
 // adaptive classifier. This is the dedicated write path for the classifier,
-// keeping GET endpoints read-only.
-func StartClassifierFeeder(lc fx.Lifecycle, svc domain.Service, handler *rest.Handler) error {
+// keeping GET endpoints read-only. The scraped counters are also recorded in
+// the pod scheduling metric history, and after every feed the classifications
+// are handed to the strategy recommendation engine.
+//
+// The feeder only runs on the leader, unless the classifier is persisted:
+// then every replica feeds the shards it holds, which already keeps replicas
+// from recording the same pods twice.
+func StartClassifierFeeder(lc fx.Lifecycle, cfg config.ClassifierConfig, svc domain.Service, handler *rest.Handler, leadership *domain.Leadership) error {
+	historySvc, _ := svc.(interface {
+		RecordPodSchedulingMetricHistory(ctx context.Context, values []*domain.PodSchedulingMetricValue) error
+	})
+	recommendationSvc, _ := svc.(interface {
+		SyncStrategyRecommendations(ctx context.Context, classifications []*domain.PodClassification, owns func(namespace string) bool) error
+	})
 	stopCh := make(chan struct{})
 
 	lc.Append(fx.Hook{
@@ -157,12 +277,25 @@ func StartClassifierFeeder(lc fx.Lifecycle, svc domain.Service, handler *rest.Ha
 				}
 
 				feed := func() {
+					if !cfg.Persist && !leadership.IsLeader() {
+						return
+					}
 					result, err := svc.ListPodSchedulingMetricValues(bgCtx)
 					if err != nil {
 						logger.Logger(bgCtx).Warn().Err(err).Msg("classifier feeder: failed to fetch pod scheduling metrics")
 						return
 					}
 					handler.IngestMetricsIntoClassifier(result)
+					if historySvc != nil {
+						if err := historySvc.RecordPodSchedulingMetricHistory(bgCtx, handler.OwnedPodSchedulingMetricValues(result)); err != nil {
+							logger.Logger(bgCtx).Warn().Err(err).Msg("classifier feeder: failed to record pod scheduling metric history")
+						}
+					}
+					if recommendationSvc != nil {
+						if err := recommendationSvc.SyncStrategyRecommendations(bgCtx, handler.PodClassifications(), handler.OwnsClassifierNamespace); err != nil {
+							logger.Logger(bgCtx).Warn().Err(err).Msg("classifier feeder: failed to sync strategy recommendations")
+						}
+					}
 				}
 
 				feed()
@@ -189,3 +322,159 @@ func StartClassifierFeeder(lc fx.Lifecycle, svc domain.Service, handler *rest.Ha
 
 	return nil
 }
+
+// StartClassifierStateSync restores the adaptive classifier from the
+// repository on startup and then periodically snapshots the shards this
+// replica ingests while renewing their leases. A final snapshot is written on
+// shutdown so a restart resumes where the classifier stopped.
+func StartClassifierStateSync(lc fx.Lifecycle, cfg config.ClassifierConfig, handler *rest.Handler) error {
+	if !cfg.Persist {
+		return nil
+	}
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	interval := cfg.SnapshotInterval()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(doneCh)
+				bgCtx := context.Background()
+				logger.Logger(bgCtx).Info().Msgf("classifier state sync starting, replica %s, interval %s", cfg.ResolvedReplicaID(), interval)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					if err := handler.SyncClassifierState(bgCtx); err != nil {
+						logger.Logger(bgCtx).Warn().Err(err).Msg("classifier state sync failed")
+					}
+					select {
+					case <-ticker.C:
+					case <-stopCh:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(stopCh)
+			<-doneCh
+			if err := handler.SyncClassifierState(ctx); err != nil {
+				logger.Logger(ctx).Warn().Err(err).Msg("final classifier state sync failed")
+			}
+			logger.Logger(ctx).Info().Msg("classifier state sync stopped")
+			return nil
+		},
+	})
+
+	return nil
+}
+
+// StartRuntimeConfigRolloutController starts a background goroutine that
+// drives running runtime config rollouts: it starts pending waves, checks the
+// health gates of baking waves and promotes or fails them. Rollouts are only
+// advanced by the leader.
+func StartRuntimeConfigRolloutController(lc fx.Lifecycle, svc domain.Service, leadership *domain.Leadership) error {
+	rolloutSvc, ok := svc.(interface {
+		AdvanceRuntimeConfigRollouts(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	stopCh := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				bgCtx := context.Background()
+				logger.Logger(bgCtx).Info().Msgf("runtime config rollout controller starting, initial wait %s, interval %s", rolloutInitialWait, rolloutInterval)
+
+				select {
+				case <-time.After(rolloutInitialWait):
+				case <-stopCh:
+					return
+				}
+
+				ticker := time.NewTicker(rolloutInterval)
+				defer ticker.Stop()
+				for {
+					if leadership.IsLeader() {
+						if err := rolloutSvc.AdvanceRuntimeConfigRollouts(bgCtx); err != nil {
+							logger.Logger(bgCtx).Warn().Err(err).Msg("runtime config rollout step failed")
+						}
+					}
+					select {
+					case <-ticker.C:
+					case <-stopCh:
+						logger.Logger(bgCtx).Info().Msg("runtime config rollout controller stopped")
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(stopCh)
+			return nil
+		},
+	})
+
+	return nil
+}
+
+// StartScaledObjectReconciler starts a background goroutine that periodically
+// reconciles the KEDA ScaledObjects generated from PodSchedulingMetrics
+// scaling hints, so drift and deleted CRs are repaired even without an API
+// call. Only the leader reconciles.
+func StartScaledObjectReconciler(lc fx.Lifecycle, cfg config.KEDAConfig, svc domain.Service, leadership *domain.Leadership) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	scalingSvc, ok := svc.(interface {
+		ReconcilePSMScaledObjects(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	stopCh := make(chan struct{})
+	interval := cfg.ReconcileInterval()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				bgCtx := context.Background()
+				logger.Logger(bgCtx).Info().Msgf("ScaledObject reconciler starting, initial wait %s, interval %s", scaledObjectInitialWait, interval)
+
+				select {
+				case <-time.After(scaledObjectInitialWait):
+				case <-stopCh:
+					return
+				}
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					if leadership.IsLeader() {
+						if err := scalingSvc.ReconcilePSMScaledObjects(bgCtx); err != nil {
+							logger.Logger(bgCtx).Warn().Err(err).Msg("ScaledObject reconciliation failed")
+						}
+					}
+					select {
+					case <-ticker.C:
+					case <-stopCh:
+						logger.Logger(bgCtx).Info().Msg("ScaledObject reconciler stopped")
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(stopCh)
+			return nil
+		},
+	})
+
+	return nil
+}
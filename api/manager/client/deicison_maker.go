@@ -8,11 +8,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	cache "github.com/Code-Hex/go-generics-cache"
 	"github.com/Gthulhu/api/config"
+	dmdomain "github.com/Gthulhu/api/decisionmaker/domain"
 	dmrest "github.com/Gthulhu/api/decisionmaker/rest"
 	"github.com/Gthulhu/api/manager/domain"
 	"github.com/Gthulhu/api/pkg/logger"
@@ -86,19 +88,7 @@ func (dm *DecisionMakerClient) SendSchedulingIntent(ctx context.Context, decisio
 	logger.Logger(ctx).Debug().Msgf("Sending %d scheduling intents to decision maker pod (host:%s nodeID:%s port:%d)", len(intents), decisionMaker.Host, decisionMaker.NodeID, decisionMaker.Port)
 
 	reqPayload := dmrest.HandleIntentsRequest{
-		Intents: make([]dmrest.Intent, 0, len(intents)),
-	}
-	for _, intent := range intents {
-		reqPayload.Intents = append(reqPayload.Intents, dmrest.Intent{
-			PodName:       intent.PodName,
-			PodID:         intent.PodID,
-			NodeID:        intent.NodeID,
-			K8sNamespace:  intent.K8sNamespace,
-			CommandRegex:  intent.CommandRegex,
-			Priority:      intent.Priority,
-			ExecutionTime: intent.ExecutionTime,
-			PodLabels:     intent.PodLabels,
-		})
+		Intents: convertScheduleIntents(intents),
 	}
 
 	jsonBody, err := json.Marshal(reqPayload)
@@ -155,6 +145,215 @@ func (dm *DecisionMakerClient) GetIntentMerkleRoot(ctx context.Context, decision
 	return merkleResp.Data.RootHash, nil
 }
 
+func convertScheduleIntents(intents []*domain.ScheduleIntent) []dmrest.Intent {
+	result := make([]dmrest.Intent, 0, len(intents))
+	for _, intent := range intents {
+		result = append(result, dmrest.Intent{
+			IntentID:      intent.ID.Hex(),
+			StrategyID:    intent.StrategyID.Hex(),
+			PodName:       intent.PodName,
+			PodID:         intent.PodID,
+			NodeID:        intent.NodeID,
+			K8sNamespace:  intent.K8sNamespace,
+			CommandRegex:  intent.CommandRegex,
+			Priority:      intent.Priority,
+			ExecutionTime: intent.ExecutionTime,
+			PodLabels:     intent.PodLabels,
+			Precedence:    intent.Precedence,
+			Specificity:   intent.Specificity,
+		})
+	}
+	return result
+}
+
+// PatchSchedulingIntents adds upserts to the decision maker's intents and
+// removes the ones whose Merkle leaf hash is in removedHashes.
+func (dm *DecisionMakerClient) PatchSchedulingIntents(ctx context.Context, decisionMaker *domain.DecisionMakerPod, upserts []*domain.ScheduleIntent, removedHashes []string) error {
+	token, err := dm.GetToken(ctx, decisionMaker)
+	if err != nil {
+		return err
+	}
+
+	logger.Logger(ctx).Debug().Msgf("Patching decision maker pod (host:%s nodeID:%s port:%d): %d upserted, %d removed intents", decisionMaker.Host, decisionMaker.NodeID, decisionMaker.Port, len(upserts), len(removedHashes))
+
+	jsonBody, err := json.Marshal(dmrest.PatchIntentsRequest{
+		Intents:       convertScheduleIntents(upserts),
+		RemovedHashes: removedHashes,
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := dm.scheme() + "://" + decisionMaker.Host + ":" + strconv.Itoa(decisionMaker.Port) + "/api/v1/intents"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := dm.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("decision maker %s returned non-OK status: %s", decisionMaker, resp.Status)
+	}
+	return nil
+}
+
+// GetIntentMerkleTree returns the decision maker's intent Merkle subtree
+// under rootHash down to depth levels, or nil when rootHash is not found.
+func (dm *DecisionMakerClient) GetIntentMerkleTree(ctx context.Context, decisionMaker *domain.DecisionMakerPod, rootHash string, depth int) (*domain.IntentMerkleNode, error) {
+	token, err := dm.GetToken(ctx, decisionMaker)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("rootHash", rootHash)
+	query.Set("depth", strconv.Itoa(depth))
+	endpoint := dm.scheme() + "://" + decisionMaker.Host + ":" + strconv.Itoa(decisionMaker.Port) + "/api/v1/intents/merkle/tree?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := dm.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("decision maker %s returned non-OK status: %s", decisionMaker, resp.Status)
+	}
+
+	var treeResp dmrest.SuccessResponse[dmrest.MerkleTreeResponse]
+	if err := json.NewDecoder(resp.Body).Decode(&treeResp); err != nil {
+		return nil, err
+	}
+	if treeResp.Data == nil {
+		return nil, fmt.Errorf("decision maker %s returned empty merkle tree", decisionMaker)
+	}
+	return convertDMMerkleNode(treeResp.Data.RootNode), nil
+}
+
+func convertDMMerkleNode(node *dmrest.MerkleNode) *domain.IntentMerkleNode {
+	if node == nil {
+		return nil
+	}
+	return &domain.IntentMerkleNode{
+		Hash:  node.Hash,
+		Leaf:  node.Leaf,
+		Left:  convertDMMerkleNode(node.Left),
+		Right: convertDMMerkleNode(node.Right),
+	}
+}
+
+// GetIntentConflicts returns the processes the decision maker found targeted
+// by more than one strategy or node policy, with the side that won.
+func (dm *DecisionMakerClient) GetIntentConflicts(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.IntentConflictReport, error) {
+	token, err := dm.GetToken(ctx, decisionMaker)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := dm.scheme() + "://" + decisionMaker.Host + ":" + strconv.Itoa(decisionMaker.Port) + "/api/v1/intents/conflicts"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := dm.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("decision maker %s returned non-OK status: %s", decisionMaker, resp.Status)
+	}
+
+	var conflictsResp dmrest.SuccessResponse[dmrest.ListIntentConflictsResponse]
+	if err := json.NewDecoder(resp.Body).Decode(&conflictsResp); err != nil {
+		return nil, err
+	}
+	if conflictsResp.Data == nil {
+		return nil, nil
+	}
+	reports := make([]*domain.IntentConflictReport, 0, len(conflictsResp.Data.Conflicts))
+	for _, conflict := range conflictsResp.Data.Conflicts {
+		reports = append(reports, &domain.IntentConflictReport{
+			NodeID:           decisionMaker.NodeID,
+			PID:              conflict.PID,
+			Reason:           conflict.Reason,
+			WinnerKind:       conflict.Winner.Kind,
+			WinnerID:         conflict.Winner.ID,
+			WinnerStrategyID: conflict.Winner.StrategyID,
+			LoserKind:        conflict.Loser.Kind,
+			LoserID:          conflict.Loser.ID,
+			LoserStrategyID:  conflict.Loser.StrategyID,
+		})
+	}
+	return reports, nil
+}
+
+// GetIntentStatuses returns what became of every intent the last time the
+// decision maker handed them to the scheduler.
+func (dm *DecisionMakerClient) GetIntentStatuses(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.IntentApplyReport, error) {
+	token, err := dm.GetToken(ctx, decisionMaker)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := dm.scheme() + "://" + decisionMaker.Host + ":" + strconv.Itoa(decisionMaker.Port) + "/api/v1/intents/status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := dm.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("decision maker %s returned non-OK status: %s", decisionMaker, resp.Status)
+	}
+
+	var statusResp dmrest.SuccessResponse[dmrest.ListIntentStatusesResponse]
+	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return nil, err
+	}
+	if statusResp.Data == nil {
+		return nil, nil
+	}
+	reports := make([]*domain.IntentApplyReport, 0, len(statusResp.Data.Statuses))
+	for _, status := range statusResp.Data.Statuses {
+		state := domain.IntentStateUnknown
+		switch status.State {
+		case dmdomain.IntentApplyResolved:
+			state = domain.IntentStateResolved
+		case dmdomain.IntentApplyApplied:
+			state = domain.IntentStateApplied
+		case dmdomain.IntentApplyNoMatchingProcess:
+			state = domain.IntentStateNoMatchingProcess
+		case dmdomain.IntentApplyFailed:
+			state = domain.IntentStateFailed
+		}
+		report := &domain.IntentApplyReport{
+			NodeID:   decisionMaker.NodeID,
+			IntentID: status.IntentID,
+			State:    state,
+			PIDs:     status.PIDs,
+			Message:  status.Message,
+		}
+		if !status.LastAppliedAt.IsZero() {
+			report.LastAppliedAt = status.LastAppliedAt.UnixMilli()
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
 func (dm *DecisionMakerClient) GetToken(ctx context.Context, decisionMaker *domain.DecisionMakerPod) (string, error) {
 	if token, ok := dm.tokenCache.Get(decisionMaker.NodeID); ok {
 		return token, nil
@@ -277,6 +476,7 @@ func (dm *DecisionMakerClient) SendNodeSchedulingPolicies(ctx context.Context, d
 			CommandRegex:  intent.CommandRegex,
 			Priority:      intent.Priority,
 			ExecutionTime: intent.ExecutionTime,
+			Precedence:    intent.Precedence,
 		})
 	}
 
@@ -43,7 +43,20 @@ var podSchedulingMetricFamilies = map[string]func(item *domain.PodSchedulingMetr
 	},
 }
 
-func (dm *DecisionMakerClient) GetPodSchedulingMetricValues(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.PodSchedulingMetricValue, error) {
+var schedulerBSSMetricFamilies = map[string]func(item *domain.SchedulerBSSMetrics, value uint64){
+	"nr_queued":            func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrQueued = value },
+	"nr_scheduled":         func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrScheduled = value },
+	"nr_running":           func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrRunning = value },
+	"nr_online_cpus":       func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrOnlineCPUs = value },
+	"nr_user_dispatches":   func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrUserDispatches = value },
+	"nr_kernel_dispatches": func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrKernelDispatches = value },
+	"nr_cancel_dispatches": func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrCancelDispatches = value },
+	"nr_bounce_dispatches": func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrBounceDispatches = value },
+	"nr_failed_dispatches": func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrFailedDispatches = value },
+	"nr_sched_congested":   func(item *domain.SchedulerBSSMetrics, value uint64) { item.NrSchedCongested = value },
+}
+
+func (dm *DecisionMakerClient) getMetricFamilies(ctx context.Context, decisionMaker *domain.DecisionMakerPod) (map[string]*dto.MetricFamily, error) {
 	endpoint := dm.scheme() + "://" + decisionMaker.Host + ":" + strconv.Itoa(decisionMaker.Port) + "/metrics"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -65,6 +78,36 @@ func (dm *DecisionMakerClient) GetPodSchedulingMetricValues(ctx context.Context,
 	if err != nil {
 		return nil, fmt.Errorf("parse prometheus metrics response: %w", err)
 	}
+	return families, nil
+}
+
+// GetSchedulerBSSMetrics returns the scheduler BSS counters last reported to
+// the decision maker. Available is false if the scheduler never reported.
+func (dm *DecisionMakerClient) GetSchedulerBSSMetrics(ctx context.Context, decisionMaker *domain.DecisionMakerPod) (domain.SchedulerBSSMetrics, error) {
+	families, err := dm.getMetricFamilies(ctx, decisionMaker)
+	if err != nil {
+		return domain.SchedulerBSSMetrics{}, err
+	}
+
+	result := domain.SchedulerBSSMetrics{}
+	for familyName, family := range families {
+		setter, ok := schedulerBSSMetricFamilies[familyName]
+		if !ok {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			setter(&result, prometheusMetricValue(metric))
+			result.Available = true
+		}
+	}
+	return result, nil
+}
+
+func (dm *DecisionMakerClient) GetPodSchedulingMetricValues(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.PodSchedulingMetricValue, error) {
+	families, err := dm.getMetricFamilies(ctx, decisionMaker)
+	if err != nil {
+		return nil, err
+	}
 
 	items := map[string]*domain.PodSchedulingMetricValue{}
 	for familyName, family := range families {
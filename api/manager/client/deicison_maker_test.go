@@ -317,6 +317,47 @@ gthulhu_pod_cpu_migrations_total{pod_name="pod-a",namespace="default",node_name=
 	assert.Equal(t, uint64(0), got[0].NUMAMigrations)
 }
 
+func TestGetSchedulerBSSMetrics(t *testing.T) {
+	metrics := `
+# TYPE nr_failed_dispatches gauge
+nr_failed_dispatches{machine_id="node-1"} 7
+# TYPE nr_user_dispatches gauge
+nr_user_dispatches{machine_id="node-1"} 120
+# TYPE gthulhu_pod_cpu_migrations_total counter
+gthulhu_pod_cpu_migrations_total{pod_name="pod-a",namespace="default",node_name="node-1"} 4
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/metrics", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(metrics))
+	}))
+	defer server.Close()
+
+	dm := newDecisionMakerPodFromServerURL(t, server.URL)
+	client := &DecisionMakerClient{Client: server.Client()}
+
+	got, err := client.GetSchedulerBSSMetrics(context.Background(), dm)
+	require.NoError(t, err)
+	assert.True(t, got.Available)
+	assert.Equal(t, uint64(7), got.NrFailedDispatches)
+	assert.Equal(t, uint64(120), got.NrUserDispatches)
+}
+
+func TestGetSchedulerBSSMetrics_NotReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("# TYPE go_goroutines gauge\ngo_goroutines 12\n"))
+	}))
+	defer server.Close()
+
+	dm := newDecisionMakerPodFromServerURL(t, server.URL)
+	client := &DecisionMakerClient{Client: server.Client()}
+
+	got, err := client.GetSchedulerBSSMetrics(context.Background(), dm)
+	require.NoError(t, err)
+	assert.False(t, got.Available)
+}
+
 // testCerts holds PEM-encoded self-signed CA + leaf cert for unit testing.
 type testCerts struct {
 	caPEM   string
@@ -1,6 +1,12 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 type UserStatus int8
 
@@ -41,6 +47,66 @@ type RolePolicy struct {
 	PolicyNamespace string        `bson:"policeNamespace,omitempty"`
 }
 
+// AllK8SNamespaces grants a policy access to every Kubernetes namespace.
+const AllK8SNamespaces = "*"
+
+// AllowedK8SNamespaces returns the Kubernetes namespaces the policy is
+// restricted to. K8SNamespace holds a comma-separated list; an empty value or
+// "*" means unrestricted, reported as nil.
+func (p RolePolicy) AllowedK8SNamespaces() []string {
+	var namespaces []string
+	for _, ns := range strings.Split(p.K8SNamespace, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == AllK8SNamespaces {
+			return nil
+		}
+		if ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// AllowsK8SNamespace reports whether the policy covers namespace.
+func (p RolePolicy) AllowsK8SNamespace(namespace string) bool {
+	allowed := p.AllowedK8SNamespaces()
+	return allowed == nil || slices.Contains(allowed, namespace)
+}
+
+// AllowsK8SNamespaces reports whether the policy covers all of namespaces.
+// An empty list selects every namespace, so only unrestricted policies
+// allow it.
+func (p RolePolicy) AllowsK8SNamespaces(namespaces []string) bool {
+	allowed := p.AllowedK8SNamespaces()
+	if allowed == nil {
+		return true
+	}
+	if len(namespaces) == 0 {
+		return false
+	}
+	for _, ns := range namespaces {
+		if !slices.Contains(allowed, ns) {
+			return false
+		}
+	}
+	return true
+}
+
+type rolePolicyKey struct{}
+
+// WithRolePolicy records the role policy a request was authorized with, so
+// that the service layer can enforce its namespace restriction.
+func WithRolePolicy(ctx context.Context, policy RolePolicy) context.Context {
+	return context.WithValue(ctx, rolePolicyKey{}, policy)
+}
+
+// RolePolicyFromContext returns the role policy recorded by WithRolePolicy.
+// Calls made by the manager itself, such as its background loops, have none.
+func RolePolicyFromContext(ctx context.Context) (RolePolicy, bool) {
+	policy, ok := ctx.Value(rolePolicyKey{}).(RolePolicy)
+	return policy, ok
+}
+
 type Permission struct {
 	ID          bson.ObjectID    `bson:"_id,omitempty"`
 	Key         PermissionKey    `bson:"key,omitempty"`
@@ -1,6 +1,10 @@
 package domain
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 type AuditLog struct {
 	ID        bson.ObjectID `bson:"_id,omitempty"`
@@ -9,4 +13,85 @@ type AuditLog struct {
 	RequestID string        `bson:"request_id,omitempty"`
 	Timestamp int64         `bson:"timestamp,omitempty"`
 	IP        string        `bson:"ip,omitempty"`
+	// Resource is the kind of object the action touched, e.g. schedule_strategy.
+	Resource   string `bson:"resource,omitempty"`
+	ResourceID string `bson:"resource_id,omitempty"`
+	// Before and After are snapshots of the resource around the change;
+	// Before is empty for creations and After for deletions.
+	Before  bson.M        `bson:"before,omitempty"`
+	After   bson.M        `bson:"after,omitempty"`
+	Changes []AuditChange `bson:"changes,omitempty"`
+}
+
+// AuditChange is one top-level field that differs between Before and After.
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before any    `bson:"before,omitempty" json:"before,omitempty"`
+	After  any    `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// Audited actions.
+const (
+	AuditActionLogin                      = "auth.login"
+	AuditActionLogout                     = "auth.logout"
+	AuditActionLogoutAll                  = "auth.logout_all"
+	AuditActionUserCreate                 = "user.create"
+	AuditActionUserUpdate                 = "user.update"
+	AuditActionUserDelete                 = "user.delete"
+	AuditActionUserPasswordChange         = "user.password.change"
+	AuditActionUserPasswordReset          = "user.password.reset"
+	AuditActionUserPermissionUpdate       = "user.permission.update"
+	AuditActionRoleCreate                 = "role.create"
+	AuditActionRoleUpdate                 = "role.update"
+	AuditActionScheduleStrategyCreate     = "schedule_strategy.create"
+	AuditActionScheduleStrategyUpdate     = "schedule_strategy.update"
+	AuditActionScheduleStrategyDelete     = "schedule_strategy.delete"
+	AuditActionScheduleIntentDelete       = "schedule_intent.delete"
+	AuditActionNodeSchedulingPolicyCreate = "node_scheduling_policy.create"
+	AuditActionNodeSchedulingPolicyUpdate = "node_scheduling_policy.update"
+	AuditActionNodeSchedulingPolicyDelete = "node_scheduling_policy.delete"
+	AuditActionNodeSchedulingIntentDelete = "node_scheduling_intent.delete"
+	AuditActionPSMCreate                  = "pod_scheduling_metrics.create"
+	AuditActionPSMUpdate                  = "pod_scheduling_metrics.update"
+	AuditActionPSMDelete                  = "pod_scheduling_metrics.delete"
+	AuditActionSchedulerConfigApply       = "scheduler_config.apply"
+	AuditActionSchedulerConfigRollout     = "scheduler_config.rollout"
+	AuditActionSchedulerConfigPause       = "scheduler_config.rollout.pause"
+	AuditActionSchedulerConfigResume      = "scheduler_config.rollout.resume"
+	AuditActionSchedulerConfigRollback    = "scheduler_config.rollout.rollback"
+	AuditActionRecommendationApply        = "strategy_recommendation.apply"
+	AuditActionRecommendationReject       = "strategy_recommendation.reject"
+	AuditActionRecommendationRevert       = "strategy_recommendation.revert"
+	AuditActionRecommendationAutoApplySet = "strategy_recommendation.auto_apply.update"
+)
+
+// Audited resources; they match Permission.Resource.
+const (
+	AuditResourceUser                 = "user"
+	AuditResourceRole                 = "role"
+	AuditResourceScheduleStrategy     = "schedule_strategy"
+	AuditResourceScheduleIntent       = "schedule_intent"
+	AuditResourceNodeSchedulingPolicy = "node_scheduling_policy"
+	AuditResourceNodeSchedulingIntent = "node_scheduling_intent"
+	AuditResourcePSM                  = "pod_scheduling_metrics"
+	AuditResourceSchedulerConfig      = "scheduler_config"
+	AuditResourceRecommendation       = "strategy_recommendation"
+)
+
+// RequestInfo identifies the HTTP request a service call is serving, so audit
+// entries can be correlated with access logs.
+type RequestInfo struct {
+	RequestID string
+	IP        string
+}
+
+type requestInfoKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
 }
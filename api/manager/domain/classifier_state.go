@@ -0,0 +1,48 @@
+package domain
+
+// ClassifierShardState is the persisted state of one adaptive classifier
+// shard together with the lease of the manager replica ingesting into it.
+// Payload is the encoded shard snapshot; only the lease holder writes it.
+type ClassifierShardState struct {
+	Shard          int    `bson:"_id"`
+	Owner          string `bson:"owner"`
+	OwnerAddress   string `bson:"ownerAddress"`
+	LeaseExpiresAt int64  `bson:"leaseExpiresAt"`
+	Payload        []byte `bson:"payload,omitempty"`
+	SnapshotAt     int64  `bson:"snapshotAt"`
+	UpdatedAt      int64  `bson:"updatedAt"`
+}
+
+// LeaseActive reports whether a replica holds the shard at the given time
+// (milliseconds since epoch).
+func (s *ClassifierShardState) LeaseActive(nowMilli int64) bool {
+	return s.Owner != "" && s.LeaseExpiresAt > nowMilli
+}
+
+// ClassifierReplica is a manager replica taking part in classifier ingestion.
+// Replicas heartbeat on every sync so that shards can be spread evenly.
+type ClassifierReplica struct {
+	ID         string `bson:"_id"`
+	Address    string `bson:"address"`
+	LastSeenAt int64  `bson:"lastSeenAt"`
+}
+
+// ClassifierShardSync is one classifier sync round of a replica.
+type ClassifierShardSync struct {
+	Replica      ClassifierReplica
+	ShardCount   int
+	LeaseSeconds int64
+	// Snapshots holds the encoded state of the shards the replica ingests,
+	// keyed by shard.
+	Snapshots map[int][]byte
+}
+
+type QueryClassifierShardStateOptions struct {
+	Shards []int
+	Result []*ClassifierShardState
+}
+
+type QueryClassifierReplicaOptions struct {
+	SeenAfter int64
+	Result    []*ClassifierReplica
+}
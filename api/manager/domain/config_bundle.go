@@ -0,0 +1,63 @@
+package domain
+
+const (
+	ConfigBundleAPIVersion = "gthulhu.io/v1"
+	ConfigBundleKind       = "ConfigBundle"
+)
+
+// ConfigBundle is the declarative state of the manager objects that can be
+// exported and applied as a whole. Strategies and node scheduling policies
+// are the ones owned by the operator, the rest are cluster-wide.
+type ConfigBundle struct {
+	Strategies           []*ScheduleStrategy
+	NodePolicies         []*NodeSchedulingPolicy
+	PodSchedulingMetrics []*PodSchedulingMetrics
+	Roles                []*Role
+	NodeRuntimeConfigs   []*NodeRuntimeConfig
+}
+
+type ConfigBundleResource string
+
+const (
+	ConfigBundleResourceStrategy             ConfigBundleResource = "strategy"
+	ConfigBundleResourceNodePolicy           ConfigBundleResource = "nodeSchedulingPolicy"
+	ConfigBundleResourcePodSchedulingMetrics ConfigBundleResource = "podSchedulingMetrics"
+	ConfigBundleResourceRole                 ConfigBundleResource = "role"
+	ConfigBundleResourceNodeRuntimeConfig    ConfigBundleResource = "nodeRuntimeConfig"
+)
+
+type ConfigBundleOperation string
+
+const (
+	ConfigBundleOperationCreate    ConfigBundleOperation = "create"
+	ConfigBundleOperationUpdate    ConfigBundleOperation = "update"
+	ConfigBundleOperationDelete    ConfigBundleOperation = "delete"
+	ConfigBundleOperationUnchanged ConfigBundleOperation = "unchanged"
+)
+
+// ConfigBundleChange is one step of the plan computed by applying a bundle.
+// Key is the ID of strategies, node scheduling policies and pod scheduling
+// metrics, the name of roles and the node ID of runtime configs. Error is set
+// when the step was refused or executed and failed. BypassesRollout marks
+// runtime configs that are sent to the node at once instead of through a
+// staged rollout.
+type ConfigBundleChange struct {
+	Resource        ConfigBundleResource  `json:"resource"`
+	Key             string                `json:"key"`
+	Operation       ConfigBundleOperation `json:"operation"`
+	BypassesRollout bool                  `json:"bypassesRollout,omitempty"`
+	Error           string                `json:"error,omitempty"`
+}
+
+type ApplyConfigBundleOptions struct {
+	Bundle *ConfigBundle
+	// Prune deletes strategies, node scheduling policies and pod scheduling
+	// metrics that are missing from the bundle. Roles and node runtime
+	// configs are never deleted.
+	Prune bool
+	// DryRun only computes the plan.
+	DryRun bool
+	// SkipRollout applies changed node runtime configs directly, bypassing
+	// staged rollouts. Without it they are refused.
+	SkipRollout bool
+}
@@ -0,0 +1,26 @@
+package domain
+
+// PullIntentsResult is what a decision maker in pull mode gets for its node.
+// ETag is the Merkle root of the intents; NotModified is set and Intents left
+// empty when the decision maker already holds it.
+type PullIntentsResult struct {
+	ETag        string
+	NotModified bool
+	Intents     []*ScheduleIntent
+}
+
+// PullNodeIntentsResult is PullIntentsResult for node scheduling intents.
+type PullNodeIntentsResult struct {
+	ETag        string
+	NotModified bool
+	Intents     []*NodeSchedulingIntent
+}
+
+// PullRuntimeConfigResult is the runtime config a decision maker in pull mode
+// gets for its node. ETag is the config version; Config is nil when no
+// runtime config was applied to the node.
+type PullRuntimeConfigResult struct {
+	ETag        string
+	NotModified bool
+	Config      *RuntimeSchedulerConfig
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// DecisionMakerCapabilities is what a decision maker reports about its node
+// when it registers.
+type DecisionMakerCapabilities struct {
+	SchedExtSupported bool   `json:"schedExtSupported" bson:"schedExtSupported"`
+	MonitorEnabled    bool   `json:"monitorEnabled" bson:"monitorEnabled"`
+	KernelVersion     string `json:"kernelVersion,omitempty" bson:"kernelVersion,omitempty"`
+}
+
+// DecisionMakerRegistration is a decision maker that registered itself with
+// the manager and keeps sending heartbeats. Online is false once the last
+// heartbeat is older than the heartbeat TTL. ExpireAt is when the stored
+// registration is dropped.
+type DecisionMakerRegistration struct {
+	NodeID        string                    `json:"nodeID" bson:"_id"`
+	Host          string                    `json:"host" bson:"host"`
+	Port          int                       `json:"port" bson:"port"`
+	Version       string                    `json:"version,omitempty" bson:"version,omitempty"`
+	Capabilities  DecisionMakerCapabilities `json:"capabilities" bson:"capabilities"`
+	RegisteredAt  time.Time                 `json:"registeredAt" bson:"registeredAt"`
+	LastHeartbeat time.Time                 `json:"lastHeartbeat" bson:"lastHeartbeat"`
+	Online        bool                      `json:"online" bson:"-"`
+	ExpireAt      time.Time                 `json:"-" bson:"expireAt"`
+}
+
+// DecisionMakerPod returns the address the manager reaches the decision
+// maker at.
+func (r *DecisionMakerRegistration) DecisionMakerPod() *DecisionMakerPod {
+	state := NodeStateOffline
+	if r.Online {
+		state = NodeStateOnline
+	}
+	return &DecisionMakerPod{
+		NodeID: r.NodeID,
+		Host:   r.Host,
+		Port:   r.Port,
+		State:  state,
+	}
+}
+
+// DecisionMakerPull records when the decision maker of a node in pull mode
+// last pulled from any manager replica.
+type DecisionMakerPull struct {
+	NodeID   string    `bson:"_id"`
+	PulledAt time.Time `bson:"pulledAt"`
+	ExpireAt time.Time `bson:"expireAt"`
+}
+
+type QueryDecisionMakerRegistrationOptions struct {
+	HeartbeatAfter time.Time
+	Result         []*DecisionMakerRegistration
+}
+
+type QueryDecisionMakerPullOptions struct {
+	PulledAfter time.Time
+	Result      []*DecisionMakerPull
+}
@@ -33,6 +33,14 @@ const (
 	NodeSchedulingPolicyDelete PermissionKey = "node_scheduling_policy.delete"
 	NodeSchedulingIntentRead   PermissionKey = "node_scheduling_intent.read"
 	NodeSchedulingIntentDelete PermissionKey = "node_scheduling_intent.delete"
+
+	AuditLogRead PermissionKey = "audit_log.read"
+
+	StrategyRecommendationRead   PermissionKey = "strategy_recommendation.read"
+	StrategyRecommendationUpdate PermissionKey = "strategy_recommendation.update"
+
+	ConfigBundleExport PermissionKey = "config_bundle.export"
+	ConfigBundleApply  PermissionKey = "config_bundle.apply"
 )
 
 const (
@@ -53,4 +61,31 @@ const (
 	IntentStateUnknown IntentState = iota
 	IntentStateInitialized
 	IntentStateSent
+	// IntentStateApplied and the states after it are reported back by the
+	// decision maker once the scheduler has listed the intent.
+	IntentStateApplied
+	IntentStateNoMatchingProcess
+	IntentStateFailed
+	// IntentStateResolved intents were resolved to processes, but the
+	// scheduler has not acknowledged applying them yet.
+	IntentStateResolved
 )
+
+func (s IntentState) String() string {
+	switch s {
+	case IntentStateInitialized:
+		return "Initialized"
+	case IntentStateSent:
+		return "Sent"
+	case IntentStateApplied:
+		return "Applied"
+	case IntentStateNoMatchingProcess:
+		return "NoMatchingProcess"
+	case IntentStateFailed:
+		return "Failed"
+	case IntentStateResolved:
+		return "Resolved"
+	default:
+		return "Unknown"
+	}
+}
@@ -7,4 +7,5 @@ var (
 	ErrNoKubeConfig  = errors.New("kubernetes configuration not provided")
 	ErrNilQueryInput = errors.New("query options is nil")
 	ErrNoClient      = errors.New("kubernetes client is not initialized")
+	ErrConflict      = errors.New("resource was modified concurrently")
 )
@@ -29,7 +29,16 @@ type QueryAuditLogOptions struct {
 	TimestampGTE int64
 	TimestampLTE int64
 	UserIDs      []bson.ObjectID
-	Result       []*AuditLog
+	Resources    []string
+	ResourceIDs  []string
+	Actions      []string
+	// Skip and Limit page through the results, newest first; Limit 0 returns
+	// everything.
+	Skip   int64
+	Limit  int64
+	Result []*AuditLog
+	// Total is the number of entries matching the filters, ignoring paging.
+	Total int64
 }
 
 type QueryStrategyOptions struct {
@@ -60,6 +69,7 @@ type QueryIntentOptions struct {
 	StrategyIDs   []bson.ObjectID
 	States        []IntentState
 	PodIDs        []string
+	NodeIDs       []string
 	Result        []*ScheduleIntent
 	CreatorIDs    []bson.ObjectID
 }
@@ -101,6 +111,12 @@ type Repository interface {
 	DeleteNodePolicy(ctx context.Context, policyID bson.ObjectID) error
 	DeleteNodeIntents(ctx context.Context, intentIDs []bson.ObjectID) error
 	DeleteNodeIntentsByPolicyID(ctx context.Context, policyID bson.ObjectID) error
+
+	UpsertDecisionMakerRegistration(ctx context.Context, reg *DecisionMakerRegistration) (bool, error)
+	DeleteDecisionMakerRegistration(ctx context.Context, nodeID string) (bool, error)
+	QueryDecisionMakerRegistrations(ctx context.Context, opt *QueryDecisionMakerRegistrationOptions) error
+	MarkDecisionMakerPull(ctx context.Context, pull *DecisionMakerPull) error
+	QueryDecisionMakerPulls(ctx context.Context, opt *QueryDecisionMakerPullOptions) error
 }
 
 type Service interface {
@@ -146,23 +162,30 @@ type Service interface {
 	DeleteNodeSchedulingIntents(ctx context.Context, operator *Claims, intentIDs []string) error
 }
 
+// Clusters in the query options below limits the query to the named
+// clusters; empty queries every cluster.
+
 type QueryPodsOptions struct {
+	Clusters       []string
 	K8SNamespace   []string
 	LabelSelectors []LabelSelector
 }
 
 type QueryDecisionMakerPodsOptions struct {
+	Clusters           []string
 	K8SNamespace       []string
 	NodeIDs            []string
 	DecisionMakerLabel LabelSelector
 }
 
 type QueryNodesOptions struct {
+	Clusters      []string
 	NodeSelectors []LabelSelector
 	NodeNames     []string
 }
 
 type QueryNodesByDRAOptions struct {
+	Clusters     []string
 	DRASelectors []DRASelector
 }
 
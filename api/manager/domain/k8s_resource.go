@@ -4,23 +4,33 @@ import "strconv"
 
 // Node represents a Kubernetes node
 type Node struct {
-	Name   string            `json:"name"`
-	Labels map[string]string `json:"labels,omitempty"`
-	Status string            `json:"status"`
+	// Cluster is the name of the cluster the node belongs to.
+	Cluster string            `json:"cluster,omitempty"`
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Status  string            `json:"status"`
+	// DecisionMaker is set when the node's decision maker registered itself.
+	DecisionMaker *DecisionMakerRegistration `json:"decisionMaker,omitempty"`
 }
 
 type DecisionMakerPod struct {
-	NodeID string
-	Port   int
-	Host   string
-	State  NodeState
+	Cluster string
+	NodeID  string
+	Port    int
+	Host    string
+	State   NodeState
 }
 
 func (d *DecisionMakerPod) String() string {
-	return "(" + d.NodeID + ")" + d.Host + ":" + strconv.Itoa(d.Port)
+	node := d.NodeID
+	if d.Cluster != "" {
+		node = d.Cluster + "/" + node
+	}
+	return "(" + node + ")" + d.Host + ":" + strconv.Itoa(d.Port)
 }
 
 type Pod struct {
+	Cluster      string
 	Name         string
 	K8SNamespace string
 	Labels       map[string]string
@@ -29,6 +39,24 @@ type Pod struct {
 	Containers   []Container
 }
 
+// PodEventType is the kind of change a PodEvent reports.
+type PodEventType string
+
+const (
+	PodEventAdded   PodEventType = "added"
+	PodEventUpdated PodEventType = "updated"
+	PodEventDeleted PodEventType = "deleted"
+)
+
+// PodEvent is a change of a pod seen by the pod watcher of a K8S adapter.
+// Pod is the pod after the change, or as last seen when it was deleted.
+// OldLabels are the labels of an updated pod before the change.
+type PodEvent struct {
+	Type      PodEventType
+	Pod       *Pod
+	OldLabels map[string]string
+}
+
 func (p *Pod) LabelsToSelectors() []LabelSelector {
 	selectors := make([]LabelSelector, 0, len(p.Labels))
 	for k, v := range p.Labels {
@@ -0,0 +1,59 @@
+package domain
+
+import "sync"
+
+// LeaderElectionStatus is the leader election state of a manager replica.
+type LeaderElectionStatus struct {
+	Enabled bool
+	// Identity is the identity this replica campaigns with.
+	Identity string
+	// Leader is the identity of the replica holding the lease, if known.
+	Leader   string
+	IsLeader bool
+}
+
+// Leadership tracks whether this manager replica leads, and so runs the
+// background loops that must not run on several replicas at once. Without
+// leader election the replica always leads, and so does a nil Leadership.
+type Leadership struct {
+	mu     sync.RWMutex
+	status LeaderElectionStatus
+}
+
+// NewLeadership returns the leadership of a replica campaigning as identity.
+// It leads from the start unless leader election is enabled.
+func NewLeadership(enabled bool, identity string) *Leadership {
+	return &Leadership{status: LeaderElectionStatus{
+		Enabled:  enabled,
+		Identity: identity,
+		IsLeader: !enabled,
+	}}
+}
+
+// IsLeader reports whether the replica leads.
+func (l *Leadership) IsLeader() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.status.IsLeader
+}
+
+// Status returns a copy of the leader election state.
+func (l *Leadership) Status() LeaderElectionStatus {
+	if l == nil {
+		return LeaderElectionStatus{IsLeader: true}
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.status
+}
+
+// Update changes the leader election state under the lock, so that checks
+// and changes made by fn are atomic.
+func (l *Leadership) Update(fn func(status *LeaderElectionStatus)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(&l.status)
+}
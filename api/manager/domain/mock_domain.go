@@ -449,6 +449,72 @@ func (_c *MockRepository_CreateUser_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// DeleteDecisionMakerRegistration provides a mock function for the type MockRepository
+func (_mock *MockRepository) DeleteDecisionMakerRegistration(ctx context.Context, nodeID string) (bool, error) {
+	ret := _mock.Called(ctx, nodeID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDecisionMakerRegistration")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, nodeID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, nodeID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, nodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_DeleteDecisionMakerRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDecisionMakerRegistration'
+type MockRepository_DeleteDecisionMakerRegistration_Call struct {
+	*mock.Call
+}
+
+// DeleteDecisionMakerRegistration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - nodeID string
+func (_e *MockRepository_Expecter) DeleteDecisionMakerRegistration(ctx interface{}, nodeID interface{}) *MockRepository_DeleteDecisionMakerRegistration_Call {
+	return &MockRepository_DeleteDecisionMakerRegistration_Call{Call: _e.mock.On("DeleteDecisionMakerRegistration", ctx, nodeID)}
+}
+
+func (_c *MockRepository_DeleteDecisionMakerRegistration_Call) Run(run func(ctx context.Context, nodeID string)) *MockRepository_DeleteDecisionMakerRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_DeleteDecisionMakerRegistration_Call) Return(b bool, err error) *MockRepository_DeleteDecisionMakerRegistration_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockRepository_DeleteDecisionMakerRegistration_Call) RunAndReturn(run func(ctx context.Context, nodeID string) (bool, error)) *MockRepository_DeleteDecisionMakerRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteIntents provides a mock function for the type MockRepository
 func (_mock *MockRepository) DeleteIntents(ctx context.Context, intentIDs []bson.ObjectID) error {
 	ret := _mock.Called(ctx, intentIDs)
@@ -1088,6 +1154,63 @@ func (_c *MockRepository_InsertStrategyAndIntents_Call) RunAndReturn(run func(ct
 	return _c
 }
 
+// MarkDecisionMakerPull provides a mock function for the type MockRepository
+func (_mock *MockRepository) MarkDecisionMakerPull(ctx context.Context, pull *DecisionMakerPull) error {
+	ret := _mock.Called(ctx, pull)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDecisionMakerPull")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DecisionMakerPull) error); ok {
+		r0 = returnFunc(ctx, pull)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_MarkDecisionMakerPull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkDecisionMakerPull'
+type MockRepository_MarkDecisionMakerPull_Call struct {
+	*mock.Call
+}
+
+// MarkDecisionMakerPull is a helper method to define mock.On call
+//   - ctx context.Context
+//   - pull *DecisionMakerPull
+func (_e *MockRepository_Expecter) MarkDecisionMakerPull(ctx interface{}, pull interface{}) *MockRepository_MarkDecisionMakerPull_Call {
+	return &MockRepository_MarkDecisionMakerPull_Call{Call: _e.mock.On("MarkDecisionMakerPull", ctx, pull)}
+}
+
+func (_c *MockRepository_MarkDecisionMakerPull_Call) Run(run func(ctx context.Context, pull *DecisionMakerPull)) *MockRepository_MarkDecisionMakerPull_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *DecisionMakerPull
+		if args[1] != nil {
+			arg1 = args[1].(*DecisionMakerPull)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_MarkDecisionMakerPull_Call) Return(err error) *MockRepository_MarkDecisionMakerPull_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_MarkDecisionMakerPull_Call) RunAndReturn(run func(ctx context.Context, pull *DecisionMakerPull) error) *MockRepository_MarkDecisionMakerPull_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // QueryAuditLogs provides a mock function for the type MockRepository
 func (_mock *MockRepository) QueryAuditLogs(ctx context.Context, opt *QueryAuditLogOptions) error {
 	ret := _mock.Called(ctx, opt)
@@ -1145,6 +1268,120 @@ func (_c *MockRepository_QueryAuditLogs_Call) RunAndReturn(run func(ctx context.
 	return _c
 }
 
+// QueryDecisionMakerPulls provides a mock function for the type MockRepository
+func (_mock *MockRepository) QueryDecisionMakerPulls(ctx context.Context, opt *QueryDecisionMakerPullOptions) error {
+	ret := _mock.Called(ctx, opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryDecisionMakerPulls")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *QueryDecisionMakerPullOptions) error); ok {
+		r0 = returnFunc(ctx, opt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_QueryDecisionMakerPulls_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryDecisionMakerPulls'
+type MockRepository_QueryDecisionMakerPulls_Call struct {
+	*mock.Call
+}
+
+// QueryDecisionMakerPulls is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opt *QueryDecisionMakerPullOptions
+func (_e *MockRepository_Expecter) QueryDecisionMakerPulls(ctx interface{}, opt interface{}) *MockRepository_QueryDecisionMakerPulls_Call {
+	return &MockRepository_QueryDecisionMakerPulls_Call{Call: _e.mock.On("QueryDecisionMakerPulls", ctx, opt)}
+}
+
+func (_c *MockRepository_QueryDecisionMakerPulls_Call) Run(run func(ctx context.Context, opt *QueryDecisionMakerPullOptions)) *MockRepository_QueryDecisionMakerPulls_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *QueryDecisionMakerPullOptions
+		if args[1] != nil {
+			arg1 = args[1].(*QueryDecisionMakerPullOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_QueryDecisionMakerPulls_Call) Return(err error) *MockRepository_QueryDecisionMakerPulls_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_QueryDecisionMakerPulls_Call) RunAndReturn(run func(ctx context.Context, opt *QueryDecisionMakerPullOptions) error) *MockRepository_QueryDecisionMakerPulls_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueryDecisionMakerRegistrations provides a mock function for the type MockRepository
+func (_mock *MockRepository) QueryDecisionMakerRegistrations(ctx context.Context, opt *QueryDecisionMakerRegistrationOptions) error {
+	ret := _mock.Called(ctx, opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryDecisionMakerRegistrations")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *QueryDecisionMakerRegistrationOptions) error); ok {
+		r0 = returnFunc(ctx, opt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRepository_QueryDecisionMakerRegistrations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryDecisionMakerRegistrations'
+type MockRepository_QueryDecisionMakerRegistrations_Call struct {
+	*mock.Call
+}
+
+// QueryDecisionMakerRegistrations is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opt *QueryDecisionMakerRegistrationOptions
+func (_e *MockRepository_Expecter) QueryDecisionMakerRegistrations(ctx interface{}, opt interface{}) *MockRepository_QueryDecisionMakerRegistrations_Call {
+	return &MockRepository_QueryDecisionMakerRegistrations_Call{Call: _e.mock.On("QueryDecisionMakerRegistrations", ctx, opt)}
+}
+
+func (_c *MockRepository_QueryDecisionMakerRegistrations_Call) Run(run func(ctx context.Context, opt *QueryDecisionMakerRegistrationOptions)) *MockRepository_QueryDecisionMakerRegistrations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *QueryDecisionMakerRegistrationOptions
+		if args[1] != nil {
+			arg1 = args[1].(*QueryDecisionMakerRegistrationOptions)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_QueryDecisionMakerRegistrations_Call) Return(err error) *MockRepository_QueryDecisionMakerRegistrations_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRepository_QueryDecisionMakerRegistrations_Call) RunAndReturn(run func(ctx context.Context, opt *QueryDecisionMakerRegistrationOptions) error) *MockRepository_QueryDecisionMakerRegistrations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // QueryIntents provides a mock function for the type MockRepository
 func (_mock *MockRepository) QueryIntents(ctx context.Context, opt *QueryIntentOptions) error {
 	ret := _mock.Called(ctx, opt)
@@ -1943,6 +2180,72 @@ func (_c *MockRepository_UpdateUser_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// UpsertDecisionMakerRegistration provides a mock function for the type MockRepository
+func (_mock *MockRepository) UpsertDecisionMakerRegistration(ctx context.Context, reg *DecisionMakerRegistration) (bool, error) {
+	ret := _mock.Called(ctx, reg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertDecisionMakerRegistration")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DecisionMakerRegistration) (bool, error)); ok {
+		return returnFunc(ctx, reg)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DecisionMakerRegistration) bool); ok {
+		r0 = returnFunc(ctx, reg)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *DecisionMakerRegistration) error); ok {
+		r1 = returnFunc(ctx, reg)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRepository_UpsertDecisionMakerRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertDecisionMakerRegistration'
+type MockRepository_UpsertDecisionMakerRegistration_Call struct {
+	*mock.Call
+}
+
+// UpsertDecisionMakerRegistration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - reg *DecisionMakerRegistration
+func (_e *MockRepository_Expecter) UpsertDecisionMakerRegistration(ctx interface{}, reg interface{}) *MockRepository_UpsertDecisionMakerRegistration_Call {
+	return &MockRepository_UpsertDecisionMakerRegistration_Call{Call: _e.mock.On("UpsertDecisionMakerRegistration", ctx, reg)}
+}
+
+func (_c *MockRepository_UpsertDecisionMakerRegistration_Call) Run(run func(ctx context.Context, reg *DecisionMakerRegistration)) *MockRepository_UpsertDecisionMakerRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *DecisionMakerRegistration
+		if args[1] != nil {
+			arg1 = args[1].(*DecisionMakerRegistration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRepository_UpsertDecisionMakerRegistration_Call) Return(b bool, err error) *MockRepository_UpsertDecisionMakerRegistration_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockRepository_UpsertDecisionMakerRegistration_Call) RunAndReturn(run func(ctx context.Context, reg *DecisionMakerRegistration) (bool, error)) *MockRepository_UpsertDecisionMakerRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockService creates a new instance of MockService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockService(t interface {
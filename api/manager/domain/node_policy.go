@@ -31,6 +31,12 @@ type NodeSchedulingPolicy struct {
 	CommandRegex  string          `bson:"commandRegex,omitempty"`
 	Priority      int             `bson:"priority,omitempty"`
 	ExecutionTime int64           `bson:"executionTime,omitempty"`
+	// Precedence ranks the policy against strategies and other policies
+	// that target the same process. Without it strategies win.
+	Precedence int `bson:"precedence,omitempty"`
+	// ClusterSelector names the clusters whose nodes the policy matches;
+	// empty matches the nodes of every cluster.
+	ClusterSelector []string `bson:"clusterSelector,omitempty"`
 }
 
 // NodeSchedulingIntent is the per-node resolution of a NodeSchedulingPolicy,
@@ -39,21 +45,28 @@ type NodeSchedulingPolicy struct {
 type NodeSchedulingIntent struct {
 	BaseEntity    `bson:",inline"`
 	PolicyID      bson.ObjectID `bson:"policyID,omitempty"`
+	Cluster       string        `bson:"cluster,omitempty"`
 	NodeID        string        `bson:"nodeID,omitempty"`
 	CommandRegex  string        `bson:"commandRegex,omitempty"`
 	Priority      int           `bson:"priority,omitempty"`
 	ExecutionTime int64         `bson:"executionTime,omitempty"`
 	State         IntentState   `bson:"state,omitempty"`
+	Precedence    int           `bson:"precedence,omitempty"`
+	// Overrides lists the processes on the node that the decision maker
+	// gave to a strategy or another node policy instead.
+	Overrides []IntentOverride `bson:"overrides,omitempty"`
 }
 
 func NewNodeSchedulingIntent(policy *NodeSchedulingPolicy, node *Node) NodeSchedulingIntent {
 	return NodeSchedulingIntent{
 		BaseEntity:    NewBaseEntity(util.Ptr(policy.CreatorID), util.Ptr(policy.UpdaterID)),
 		PolicyID:      policy.ID,
+		Cluster:       node.Cluster,
 		NodeID:        node.Name,
 		CommandRegex:  policy.CommandRegex,
 		Priority:      policy.Priority,
 		ExecutionTime: policy.ExecutionTime,
 		State:         IntentStateInitialized,
+		Precedence:    policy.Precedence,
 	}
 }
@@ -0,0 +1,126 @@
+package domain
+
+import "time"
+
+// PodSchedulingMetricHistoryTier is one resolution of the pod scheduling
+// metric history. Like the tiers of the classifier buffer, coarser tiers trade
+// resolution for a longer window: a tier keeps one bucket per Step for
+// Retention.
+type PodSchedulingMetricHistoryTier struct {
+	Step      time.Duration
+	Retention time.Duration
+}
+
+// PodSchedulingMetricHistoryTiers are ordered from the finest to the coarsest
+// resolution.
+var PodSchedulingMetricHistoryTiers = []PodSchedulingMetricHistoryTier{
+	{Step: 10 * time.Second, Retention: 24 * time.Hour},
+	{Step: 5 * time.Minute, Retention: 30 * 24 * time.Hour},
+}
+
+// PodSchedulingMetricCounters are the cumulative scheduling counters of a pod
+// as exported by the decision makers, or their increase over a history step.
+type PodSchedulingMetricCounters struct {
+	VoluntaryCtxSwitches   uint64 `bson:"voluntaryCtxSwitches"`
+	InvoluntaryCtxSwitches uint64 `bson:"involuntaryCtxSwitches"`
+	CPUTimeNs              uint64 `bson:"cpuTimeNs"`
+	WaitTimeNs             uint64 `bson:"waitTimeNs"`
+	RunCount               uint64 `bson:"runCount"`
+	CPUMigrations          uint64 `bson:"cpuMigrations"`
+	SMTMigrations          uint64 `bson:"smtMigrations"`
+	L3Migrations           uint64 `bson:"l3Migrations"`
+	NUMAMigrations         uint64 `bson:"numaMigrations"`
+}
+
+// Counters returns the cumulative counters of the metric value.
+func (v *PodSchedulingMetricValue) Counters() PodSchedulingMetricCounters {
+	return PodSchedulingMetricCounters{
+		VoluntaryCtxSwitches:   v.VoluntaryCtxSwitches,
+		InvoluntaryCtxSwitches: v.InvoluntaryCtxSwitches,
+		CPUTimeNs:              v.CPUTimeNs,
+		WaitTimeNs:             v.WaitTimeNs,
+		RunCount:               v.RunCount,
+		CPUMigrations:          v.CPUMigrations,
+		SMTMigrations:          v.SMTMigrations,
+		L3Migrations:           v.L3Migrations,
+		NUMAMigrations:         v.NUMAMigrations,
+	}
+}
+
+// IncreaseSince returns how much every counter grew since prev. A counter
+// that went down was reset, for example because the pod's processes
+// restarted, and its current value is the increase.
+func (c PodSchedulingMetricCounters) IncreaseSince(prev PodSchedulingMetricCounters) PodSchedulingMetricCounters {
+	increase := func(cur, prev uint64) uint64 {
+		if cur < prev {
+			return cur
+		}
+		return cur - prev
+	}
+	return PodSchedulingMetricCounters{
+		VoluntaryCtxSwitches:   increase(c.VoluntaryCtxSwitches, prev.VoluntaryCtxSwitches),
+		InvoluntaryCtxSwitches: increase(c.InvoluntaryCtxSwitches, prev.InvoluntaryCtxSwitches),
+		CPUTimeNs:              increase(c.CPUTimeNs, prev.CPUTimeNs),
+		WaitTimeNs:             increase(c.WaitTimeNs, prev.WaitTimeNs),
+		RunCount:               increase(c.RunCount, prev.RunCount),
+		CPUMigrations:          increase(c.CPUMigrations, prev.CPUMigrations),
+		SMTMigrations:          increase(c.SMTMigrations, prev.SMTMigrations),
+		L3Migrations:           increase(c.L3Migrations, prev.L3Migrations),
+		NUMAMigrations:         increase(c.NUMAMigrations, prev.NUMAMigrations),
+	}
+}
+
+// Add accumulates other into the counters.
+func (c *PodSchedulingMetricCounters) Add(other PodSchedulingMetricCounters) {
+	c.VoluntaryCtxSwitches += other.VoluntaryCtxSwitches
+	c.InvoluntaryCtxSwitches += other.InvoluntaryCtxSwitches
+	c.CPUTimeNs += other.CPUTimeNs
+	c.WaitTimeNs += other.WaitTimeNs
+	c.RunCount += other.RunCount
+	c.CPUMigrations += other.CPUMigrations
+	c.SMTMigrations += other.SMTMigrations
+	c.L3Migrations += other.L3Migrations
+	c.NUMAMigrations += other.NUMAMigrations
+}
+
+// PodSchedulingMetricBucket holds the counters of a pod on a node as last
+// observed within one step of a history tier. Counters are cumulative, so
+// downsampling only keeps the latest observation of each bucket.
+type PodSchedulingMetricBucket struct {
+	Namespace  string                      `bson:"namespace"`
+	PodName    string                      `bson:"podName"`
+	NodeID     string                      `bson:"nodeId"`
+	StepMillis int64                       `bson:"stepMillis"`
+	Timestamp  int64                       `bson:"timestamp"`
+	ObservedAt int64                       `bson:"observedAt"`
+	Counters   PodSchedulingMetricCounters `bson:"counters"`
+	ExpireAt   time.Time                   `bson:"expireAt"`
+}
+
+type QueryPodSchedulingMetricBucketOptions struct {
+	Namespace  string
+	PodName    string
+	StepMillis int64
+	From       int64
+	To         int64
+	Result     []*PodSchedulingMetricBucket
+}
+
+// PodSchedulingMetricHistoryPoint is the increase of a pod's counters over
+// the step starting at Timestamp, summed over the nodes the pod ran on.
+type PodSchedulingMetricHistoryPoint struct {
+	Timestamp int64
+	PodSchedulingMetricCounters
+}
+
+// QueryPodSchedulingMetricHistoryOptions selects a pod's history between From
+// and To (Unix milliseconds). A zero Step picks one from the range; the step
+// actually used is written back.
+type QueryPodSchedulingMetricHistoryOptions struct {
+	Namespace string
+	PodName   string
+	From      int64
+	To        int64
+	Step      time.Duration
+	Result    []*PodSchedulingMetricHistoryPoint
+}
@@ -3,13 +3,14 @@ package domain
 // PodSchedulingMetrics represents a PodSchedulingMetrics CRD instance.
 type PodSchedulingMetrics struct {
 	BaseEntity                `bson:",inline"`
-	LabelSelectors            []LabelSelector `bson:"labelSelectors,omitempty"`
-	K8sNamespaces             []string        `bson:"k8sNamespaces,omitempty"`
-	CommandRegex              string          `bson:"commandRegex,omitempty"`
-	CollectionIntervalSeconds int32           `bson:"collectionIntervalSeconds,omitempty"`
-	Enabled                   bool            `bson:"enabled"`
-	Metrics                   *PSMMetrics     `bson:"metrics,omitempty"`
-	Scaling                   *PSMScaling     `bson:"scaling,omitempty"`
+	LabelSelectors            []LabelSelector   `bson:"labelSelectors,omitempty"`
+	K8sNamespaces             []string          `bson:"k8sNamespaces,omitempty"`
+	CommandRegex              string            `bson:"commandRegex,omitempty"`
+	CollectionIntervalSeconds int32             `bson:"collectionIntervalSeconds,omitempty"`
+	Enabled                   bool              `bson:"enabled"`
+	Metrics                   *PSMMetrics       `bson:"metrics,omitempty"`
+	Scaling                   *PSMScaling       `bson:"scaling,omitempty"`
+	ScalingStatus             *PSMScalingStatus `bson:"scalingStatus,omitempty"`
 }
 
 // PSMMetrics controls which scheduling metrics to collect.
@@ -22,13 +23,14 @@ type PSMMetrics struct {
 	CPUMigrations          bool `bson:"cpuMigrations"`
 }
 
-// PSMScaling contains optional KEDA auto-scaling hints.
+// PSMScaling contains optional KEDA auto-scaling hints. MinReplicaCount is
+// a pointer because zero, scaling to zero, differs from unset.
 type PSMScaling struct {
 	Enabled         bool               `bson:"enabled"`
 	MetricName      string             `bson:"metricName,omitempty"`
 	TargetValue     string             `bson:"targetValue,omitempty"`
 	ScaleTargetRef  *PSMScaleTargetRef `bson:"scaleTargetRef,omitempty"`
-	MinReplicaCount int32              `bson:"minReplicaCount,omitempty"`
+	MinReplicaCount *int32             `bson:"minReplicaCount,omitempty"`
 	MaxReplicaCount int32              `bson:"maxReplicaCount,omitempty"`
 	CooldownPeriod  int32              `bson:"cooldownPeriod,omitempty"`
 }
@@ -40,6 +42,48 @@ type PSMScaleTargetRef struct {
 	Name       string `bson:"name,omitempty"`
 }
 
+// PSMScalingStatus reports the ScaledObjects the manager generated from the
+// scaling hints and why generating them failed, if it did.
+type PSMScalingStatus struct {
+	Ready              bool     `bson:"ready"`
+	ScaledObjects      []string `bson:"scaledObjects,omitempty"` // namespace/name
+	Message            string   `bson:"message,omitempty"`
+	LastTransitionTime int64    `bson:"lastTransitionTime,omitempty"`
+}
+
+// PSMScaledObject is a KEDA ScaledObject generated from the scaling hints of
+// a PodSchedulingMetrics in one of its namespaces.
+type PSMScaledObject struct {
+	Namespace       string
+	Name            string
+	PSMID           string
+	ScaleTargetRef  PSMScaleTargetRef
+	PollingInterval int32
+	CooldownPeriod  int32
+	MinReplicaCount int32
+	MaxReplicaCount int32
+	Trigger         PSMPrometheusTrigger
+}
+
+// Key returns namespace/name of the ScaledObject.
+func (so *PSMScaledObject) Key() string {
+	return so.Namespace + "/" + so.Name
+}
+
+// PSMPrometheusTrigger is the KEDA prometheus trigger of a PSMScaledObject.
+type PSMPrometheusTrigger struct {
+	ServerAddress string
+	Query         string
+	Threshold     string
+}
+
+// QueryPSMScaledObjectOptions lists the ScaledObjects managed by the manager,
+// optionally only those generated from the given PodSchedulingMetrics.
+type QueryPSMScaledObjectOptions struct {
+	PSMIDs []string
+	Result []*PSMScaledObject
+}
+
 // QueryPSMOptions is the query option struct for listing PodSchedulingMetrics.
 type QueryPSMOptions struct {
 	IDs        []interface{} // either bson.ObjectID or string names
@@ -2,6 +2,7 @@ package domain
 
 // PodSchedulingMetricValue represents the latest collected scheduling metrics for a pod.
 type PodSchedulingMetricValue struct {
+	Cluster                string `json:"cluster,omitempty"`
 	Namespace              string `json:"namespace"`
 	PodName                string `json:"podName"`
 	NodeID                 string `json:"nodeID,omitempty"`
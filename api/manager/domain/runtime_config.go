@@ -1,15 +1,16 @@
 package domain
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/Gthulhu/api/pkg/schedconfig"
 )
 
 const (
-	SchedulerModeNone    = "none"
-	SchedulerModeGthulhu = "gthulhu"
-	SchedulerModeSimple  = "simple"
-	SchedulerModeSCX     = "scx"
+	SchedulerModeNone    = schedconfig.ModeNone
+	SchedulerModeGthulhu = schedconfig.ModeGthulhu
+	SchedulerModeSimple  = schedconfig.ModeSimple
+	SchedulerModeSCX     = schedconfig.ModeSCX
 )
 
 type RuntimeSchedulerConfig struct {
@@ -48,24 +49,16 @@ func (c *RuntimeSchedulerConfig) Normalize() {
 	}
 }
 
+// Validate reports every invalid field using the rules shared with the
+// node daemon.
 func (c RuntimeSchedulerConfig) Validate() error {
-	switch c.Mode {
-	case SchedulerModeNone, SchedulerModeGthulhu, SchedulerModeSimple:
-		return nil
-	case SchedulerModeSCX:
-		if c.SchedulerName == "" {
-			return fmt.Errorf("schedulerName is required when mode is scx")
-		}
-		if strings.Contains(c.SchedulerName, "/") || strings.Contains(c.SchedulerName, "\\") {
-			return fmt.Errorf("schedulerName must be a binary name")
-		}
-		if !strings.HasPrefix(c.SchedulerName, "scx_") {
-			return fmt.Errorf("schedulerName must be an scx scheduler binary")
-		}
-		return nil
-	default:
-		return fmt.Errorf("mode must be one of none, gthulhu, simple, scx")
-	}
+	return schedconfig.Validate(schedconfig.Settings{
+		Mode:           c.Mode,
+		SchedulerName:  c.SchedulerName,
+		SliceNsDefault: c.SliceNsDefault,
+		SliceNsMin:     c.SliceNsMin,
+		KernelMode:     c.KernelMode,
+	}, schedconfig.JSONPaths).Err()
 }
 
 type RuntimeConfigApplyOptions struct {
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type RolloutState string
+
+const (
+	RolloutStateRunning   RolloutState = "running"
+	RolloutStatePaused    RolloutState = "paused"
+	RolloutStateCompleted RolloutState = "completed"
+	RolloutStateReverted  RolloutState = "reverted"
+)
+
+type RolloutWaveState string
+
+const (
+	RolloutWaveStatePending RolloutWaveState = "pending"
+	RolloutWaveStateBaking  RolloutWaveState = "baking"
+	RolloutWaveStatePassed  RolloutWaveState = "passed"
+	RolloutWaveStateFailed  RolloutWaveState = "failed"
+)
+
+// RolloutFailurePolicy decides what happens when a wave fails to apply or
+// trips a health gate.
+type RolloutFailurePolicy string
+
+const (
+	// RolloutFailurePause stops the rollout and leaves already updated nodes
+	// on the new config until an operator resumes or rolls back.
+	RolloutFailurePause RolloutFailurePolicy = "pause"
+	// RolloutFailureRevert restores the previous config on every node the
+	// rollout has touched.
+	RolloutFailureRevert RolloutFailurePolicy = "revert"
+)
+
+// RolloutWave selects the nodes of one rollout step. Exactly one of
+// Percentage or NodeIDs must be set. Percentage is cumulative: a wave with
+// Percentage 25 brings the rollout to a quarter of the target nodes, counting
+// the nodes of earlier waves.
+type RolloutWave struct {
+	Percentage  int      `bson:"percentage,omitempty" json:"percentage,omitempty"`
+	NodeIDs     []string `bson:"nodeIds,omitempty" json:"nodeIds,omitempty"`
+	BakeSeconds int64    `bson:"bakeSeconds,omitempty" json:"bakeSeconds,omitempty"`
+}
+
+// RolloutHealthGate holds the thresholds checked on every updated node while
+// a wave bakes. Deltas are measured against the values sampled right before
+// the node received the new config.
+type RolloutHealthGate struct {
+	// MaxRestartCountIncrease is the number of unexpected scheduler restarts
+	// tolerated per node. The default of zero fails on the first crash.
+	MaxRestartCountIncrease int64 `bson:"maxRestartCountIncrease" json:"maxRestartCountIncrease"`
+	// MaxFailedDispatchesIncrease bounds the growth of the scheduler BSS
+	// nr_failed_dispatches counter. Nil disables the check.
+	MaxFailedDispatchesIncrease *uint64 `bson:"maxFailedDispatchesIncrease,omitempty" json:"maxFailedDispatchesIncrease,omitempty"`
+}
+
+// RolloutNodeStatus tracks a single node of a rollout wave.
+type RolloutNodeStatus struct {
+	NodeID                   string                  `bson:"nodeId" json:"nodeId"`
+	PreviousConfig           *RuntimeSchedulerConfig `bson:"previousConfig,omitempty" json:"previousConfig,omitempty"`
+	BaselineRestartCount     int64                   `bson:"baselineRestartCount" json:"baselineRestartCount"`
+	BaselineFailedDispatches *uint64                 `bson:"baselineFailedDispatches,omitempty" json:"baselineFailedDispatches,omitempty"`
+	RestartCount             int64                   `bson:"restartCount" json:"restartCount"`
+	FailedDispatches         *uint64                 `bson:"failedDispatches,omitempty" json:"failedDispatches,omitempty"`
+	Applied                  bool                    `bson:"applied" json:"applied"`
+	Reverted                 bool                    `bson:"reverted,omitempty" json:"reverted,omitempty"`
+	Error                    string                  `bson:"error,omitempty" json:"error,omitempty"`
+	LastCheckedAt            int64                   `bson:"lastCheckedAt,omitempty" json:"lastCheckedAt,omitempty"`
+}
+
+// RolloutWaveStatus is the planned node set and progress of one wave.
+type RolloutWaveStatus struct {
+	Index       int                 `bson:"index" json:"index"`
+	State       RolloutWaveState    `bson:"state" json:"state"`
+	BakeSeconds int64               `bson:"bakeSeconds,omitempty" json:"bakeSeconds,omitempty"`
+	Nodes       []RolloutNodeStatus `bson:"nodes" json:"nodes"`
+	StartedAt   int64               `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	BakeUntil   int64               `bson:"bakeUntil,omitempty" json:"bakeUntil,omitempty"`
+	FinishedAt  int64               `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	Error       string              `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// RuntimeConfigRollout rolls a RuntimeSchedulerConfig out to nodes wave by
+// wave, baking each wave behind health gates before moving on.
+type RuntimeConfigRollout struct {
+	ID            bson.ObjectID          `bson:"_id,omitempty" json:"id"`
+	Config        RuntimeSchedulerConfig `bson:"config" json:"config"`
+	Waves         []RolloutWaveStatus    `bson:"waves" json:"waves"`
+	HealthGate    RolloutHealthGate      `bson:"healthGate" json:"healthGate"`
+	FailurePolicy RolloutFailurePolicy   `bson:"failurePolicy" json:"failurePolicy"`
+	State         RolloutState           `bson:"state" json:"state"`
+	CurrentWave   int                    `bson:"currentWave" json:"currentWave"`
+	Message       string                 `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedBy     string                 `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt     int64                  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     int64                  `bson:"updatedAt" json:"updatedAt"`
+	// Revision is bumped on every write and used for optimistic locking
+	// between the rollout controller and operator actions.
+	Revision int64 `bson:"revision" json:"revision"`
+}
+
+// StartedNodes returns the nodes of every wave that has been applied so far.
+func (r *RuntimeConfigRollout) StartedNodes() []*RolloutNodeStatus {
+	nodes := []*RolloutNodeStatus{}
+	for i := range r.Waves {
+		if r.Waves[i].State == RolloutWaveStatePending {
+			continue
+		}
+		for j := range r.Waves[i].Nodes {
+			nodes = append(nodes, &r.Waves[i].Nodes[j])
+		}
+	}
+	return nodes
+}
+
+type RuntimeConfigRolloutOptions struct {
+	NodeIDs       []string               `json:"nodeIds,omitempty"`
+	Config        RuntimeSchedulerConfig `json:"config"`
+	Waves         []RolloutWave          `json:"waves"`
+	HealthGate    RolloutHealthGate      `json:"healthGate"`
+	FailurePolicy RolloutFailurePolicy   `json:"failurePolicy,omitempty"`
+}
+
+func (opt *RuntimeConfigRolloutOptions) Validate() error {
+	if len(opt.Waves) == 0 {
+		return fmt.Errorf("at least one wave is required")
+	}
+	for i, wave := range opt.Waves {
+		hasNodes := len(wave.NodeIDs) > 0
+		hasPercentage := wave.Percentage != 0
+		if hasNodes == hasPercentage {
+			return fmt.Errorf("waves[%d]: exactly one of percentage or nodeIds must be set", i)
+		}
+		if hasPercentage && (wave.Percentage < 0 || wave.Percentage > 100) {
+			return fmt.Errorf("waves[%d]: percentage must be between 1 and 100", i)
+		}
+		for _, nodeID := range wave.NodeIDs {
+			if strings.TrimSpace(nodeID) == "" {
+				return fmt.Errorf("waves[%d]: nodeIds must not contain empty values", i)
+			}
+		}
+		if wave.BakeSeconds < 0 {
+			return fmt.Errorf("waves[%d]: bakeSeconds must not be negative", i)
+		}
+	}
+	if opt.HealthGate.MaxRestartCountIncrease < 0 {
+		return fmt.Errorf("healthGate.maxRestartCountIncrease must not be negative")
+	}
+	switch opt.FailurePolicy {
+	case "", RolloutFailurePause, RolloutFailureRevert:
+	default:
+		return fmt.Errorf("failurePolicy must be one of pause, revert")
+	}
+	return nil
+}
+
+type QueryRuntimeConfigRolloutOptions struct {
+	IDs    []bson.ObjectID
+	States []RolloutState
+	Result []*RuntimeConfigRollout
+}
+
+// SchedulerBSSMetrics is the subset of scheduler BSS counters a decision
+// maker exports on its /metrics endpoint.
+type SchedulerBSSMetrics struct {
+	NrQueued           uint64 `json:"nrQueued"`
+	NrScheduled        uint64 `json:"nrScheduled"`
+	NrRunning          uint64 `json:"nrRunning"`
+	NrOnlineCPUs       uint64 `json:"nrOnlineCpus"`
+	NrUserDispatches   uint64 `json:"nrUserDispatches"`
+	NrKernelDispatches uint64 `json:"nrKernelDispatches"`
+	NrCancelDispatches uint64 `json:"nrCancelDispatches"`
+	NrBounceDispatches uint64 `json:"nrBounceDispatches"`
+	NrFailedDispatches uint64 `json:"nrFailedDispatches"`
+	NrSchedCongested   uint64 `json:"nrSchedCongested"`
+	// Available is false when the scheduler has not reported any BSS
+	// sample yet, e.g. in scx mode or right after a restart.
+	Available bool `json:"available"`
+}
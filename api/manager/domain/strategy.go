@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"github.com/Gthulhu/api/pkg/intentprecedence"
 	"github.com/Gthulhu/api/pkg/util"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -13,12 +14,27 @@ type ScheduleStrategy struct {
 	CommandRegex      string          `bson:"commandRegex,omitempty"`
 	Priority          int             `bson:"priority,omitempty"`
 	ExecutionTime     int64           `bson:"executionTime,omitempty"`
+	// Precedence is the first rule applied when several strategies or node
+	// policies target the same process: the highest value wins.
+	Precedence int `bson:"precedence,omitempty"`
+	// Schedule limits when the strategy has intents; nil keeps it active.
+	Schedule *StrategySchedule `bson:"schedule,omitempty"`
+	// ClusterSelector names the clusters whose pods the strategy selects;
+	// empty selects the pods of every cluster.
+	ClusterSelector []string `bson:"clusterSelector,omitempty"`
+}
+
+// Specificity scores how narrowly the strategy selects processes; between
+// strategies of equal precedence the more specific one wins.
+func (s *ScheduleStrategy) Specificity() int {
+	return intentprecedence.StrategySpecificity(len(s.LabelSelectors), len(s.K8sNamespace), s.CommandRegex)
 }
 
 func NewScheduleIntent(strategy *ScheduleStrategy, pod *Pod) ScheduleIntent {
 	return ScheduleIntent{
 		BaseEntity:    NewBaseEntity(util.Ptr(strategy.CreatorID), util.Ptr(strategy.UpdaterID)),
 		StrategyID:    strategy.ID,
+		Cluster:       pod.Cluster,
 		PodID:         pod.PodID,
 		NodeID:        pod.NodeID,
 		K8sNamespace:  pod.K8SNamespace,
@@ -28,12 +44,15 @@ func NewScheduleIntent(strategy *ScheduleStrategy, pod *Pod) ScheduleIntent {
 		PodLabels:     pod.Labels,
 		State:         IntentStateInitialized,
 		PodName:       pod.Name,
+		Precedence:    strategy.Precedence,
+		Specificity:   strategy.Specificity(),
 	}
 }
 
 type ScheduleIntent struct {
 	BaseEntity    `bson:",inline"`
 	StrategyID    bson.ObjectID     `bson:"strategyID,omitempty"`
+	Cluster       string            `bson:"cluster,omitempty"`
 	PodID         string            `bson:"podID,omitempty"`
 	PodName       string            `bson:"podName,omitempty"`
 	NodeID        string            `bson:"nodeID,omitempty"`
@@ -43,6 +62,70 @@ type ScheduleIntent struct {
 	ExecutionTime int64             `bson:"executionTime,omitempty"`
 	PodLabels     map[string]string `bson:"podLabels,omitempty"`
 	State         IntentState       `bson:"state,omitempty"`
+	Precedence    int               `bson:"precedence,omitempty"`
+	Specificity   int               `bson:"specificity,omitempty"`
+	// Overrides lists the processes of the pod that decision makers gave to
+	// another strategy or node policy instead.
+	Overrides []IntentOverride `bson:"overrides,omitempty"`
+	// MatchedPIDs, LastAppliedAt (unix milliseconds) and StatusMessage are
+	// the last apply status the decision maker reported, see State.
+	MatchedPIDs   []int  `bson:"matchedPIDs,omitempty"`
+	LastAppliedAt int64  `bson:"lastAppliedAt,omitempty"`
+	StatusMessage string `bson:"statusMessage,omitempty"`
+}
+
+const (
+	IntentOverrideStrategy   = "strategy"
+	IntentOverrideNodePolicy = "nodePolicy"
+)
+
+// IntentOverride is a set of processes an intent targets but lost to a
+// higher-ranked strategy or node policy. Reason names the precedence rule
+// that decided: precedence, pod-level, specificity or age.
+type IntentOverride struct {
+	WinnerKind string `bson:"winnerKind,omitempty"`
+	WinnerID   string `bson:"winnerID,omitempty"`
+	Reason     string `bson:"reason,omitempty"`
+	PIDs       []int  `bson:"pids,omitempty"`
+}
+
+// IntentMerkleNode is a node of a decision maker's intent Merkle tree. Leaf
+// is set on the hash of a single intent; a node without children that is not
+// a leaf was cut off by the requested depth.
+type IntentMerkleNode struct {
+	Hash  string
+	Leaf  bool
+	Left  *IntentMerkleNode
+	Right *IntentMerkleNode
+}
+
+// IntentConflictReport is a conflict a decision maker reported for one
+// process of its node.
+type IntentConflictReport struct {
+	NodeID string
+	PID    int
+	Reason string
+	// WinnerKind and LoserKind are IntentOverrideStrategy or
+	// IntentOverrideNodePolicy. Strategy sides carry the intent and strategy
+	// IDs, node policy sides the policy ID.
+	WinnerKind       string
+	WinnerID         string
+	WinnerStrategyID string
+	LoserKind        string
+	LoserID          string
+	LoserStrategyID  string
+}
+
+// IntentApplyReport is the apply status a decision maker reported for one
+// intent: State is IntentStateResolved, IntentStateApplied,
+// IntentStateNoMatchingProcess or IntentStateFailed.
+type IntentApplyReport struct {
+	NodeID        string
+	IntentID      string
+	State         IntentState
+	PIDs          []int
+	LastAppliedAt int64
+	Message       string
 }
 
 type LabelSelector struct {
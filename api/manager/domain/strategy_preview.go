@@ -0,0 +1,53 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// StrategyPreview is what a schedule strategy would target if it were created
+// now. It is resolved without writing anything or sending intents.
+type StrategyPreview struct {
+	MatchedPods      int
+	MatchedProcesses int
+	Nodes            []*StrategyPreviewNode
+	Conflicts        []*StrategyConflict
+	Warnings         []string
+}
+
+// StrategyPreviewNode holds the matched pods on one node. Processes are only
+// resolved when the node's decision maker answered; otherwise Error says why.
+type StrategyPreviewNode struct {
+	Cluster           string
+	NodeID            string
+	ProcessesResolved bool
+	Error             string
+	Pods              []*StrategyPreviewPod
+}
+
+// StrategyPreviewPod is a matched pod and the processes its command regex hits.
+type StrategyPreviewPod struct {
+	PodID        string
+	PodName      string
+	K8sNamespace string
+	Processes    []PodProcess
+}
+
+// StrategyConflict is an existing strategy or node scheduling policy that
+// already targets a matched pod. PIDs lists the processes both command
+// regexes hit; it is empty when the processes of the pod could not be
+// resolved, so the overlap is only possible. ExistingWins and Reason tell
+// which side decision makers will apply to the overlapping processes.
+type StrategyConflict struct {
+	StrategyID bson.ObjectID
+	// NodePolicyID is set instead of StrategyID for node scheduling policies.
+	NodePolicyID      bson.ObjectID
+	StrategyNamespace string
+	CommandRegex      string
+	Priority          int
+	ExecutionTime     int64
+	PodID             string
+	PodName           string
+	NodeID            string
+	PIDs              []int
+	Precedence        int
+	ExistingWins      bool
+	Reason            string
+}
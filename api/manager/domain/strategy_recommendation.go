@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Classifier recommendation actions. Only the actions listed in
+// StrategyRecommendationActions can be turned into a scheduling strategy.
+const (
+	RecommendationActionIncreaseCPULimit = "increase_cpu_limit"
+	RecommendationActionRaisePriority    = "raise_priority"
+	RecommendationActionEnableCPUPinning = "enable_cpu_pinning"
+	RecommendationActionKeepCurrent      = "keep_current"
+)
+
+// StrategyRecommendationActions are the classifier actions a scheduling
+// strategy can address. enable_cpu_pinning is not one of them: intents carry
+// no CPU affinity, so no strategy would pin anything.
+var StrategyRecommendationActions = []string{
+	RecommendationActionRaisePriority,
+}
+
+// PodClassification is the classifier's view of one pod, handed to the
+// recommendation engine on every classifier feed.
+type PodClassification struct {
+	Namespace  string
+	Pod        string
+	Node       string
+	Cluster    string
+	Phase      string
+	Types      []string
+	Confidence float64
+	Action     string
+	Reason     string
+	// Short-term profile of the pod, see the classifier feature vector.
+	CPUPerRunNS   float64
+	InvolCtxRatio float64
+	WaitRatio     float64
+}
+
+// Stable reports whether the classification no longer changes with new
+// samples, which is required before a recommendation is proposed.
+func (c *PodClassification) Stable() bool {
+	return c.Phase == "stable"
+}
+
+// Drifting reports whether the pod behaves differently from its baseline.
+func (c *PodClassification) Drifting() bool {
+	return c.Phase == "drifting" || c.Phase == "transitioning"
+}
+
+type RecommendationState string
+
+const (
+	// RecommendationStatePending is a draft waiting for a human decision.
+	RecommendationStatePending RecommendationState = "pending"
+	// RecommendationStateApplied has a scheduling strategy in place.
+	RecommendationStateApplied RecommendationState = "applied"
+	// RecommendationStateRejected was declined by an operator and is not
+	// proposed again until the rejection expires.
+	RecommendationStateRejected RecommendationState = "rejected"
+	// RecommendationStateReverted had its strategy removed because the pod
+	// kept drifting after the strategy was auto-applied.
+	RecommendationStateReverted RecommendationState = "reverted"
+	// RecommendationStateObsolete was pending when the classifier stopped
+	// recommending the action, or its pod disappeared.
+	RecommendationStateObsolete RecommendationState = "obsolete"
+)
+
+// StrategyRecommendation is a draft scheduling strategy derived from a stable
+// classifier recommendation for a single pod.
+type StrategyRecommendation struct {
+	ID         bson.ObjectID       `bson:"_id,omitempty" json:"id"`
+	Namespace  string              `bson:"namespace" json:"namespace"`
+	Pod        string              `bson:"pod" json:"pod"`
+	Action     string              `bson:"action" json:"action"`
+	Reason     string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	Confidence float64             `bson:"confidence" json:"confidence"`
+	State      RecommendationState `bson:"state" json:"state"`
+	// Strategy is the draft; LabelSelectors are taken from the pod so the
+	// strategy also covers its replicas.
+	Strategy RecommendedStrategy `bson:"strategy" json:"strategy"`
+	// StrategyID is set once the draft has been applied.
+	StrategyID  string `bson:"strategyId,omitempty" json:"strategyId,omitempty"`
+	AutoApplied bool   `bson:"autoApplied,omitempty" json:"autoApplied,omitempty"`
+	// DriftSince is when the pod started drifting after the strategy was
+	// applied, or zero while it is stable.
+	DriftSince     int64  `bson:"driftSince,omitempty" json:"driftSince,omitempty"`
+	Message        string `bson:"message,omitempty" json:"message,omitempty"`
+	DecidedBy      string `bson:"decidedBy,omitempty" json:"decidedBy,omitempty"`
+	DecidedAt      int64  `bson:"decidedAt,omitempty" json:"decidedAt,omitempty"`
+	LastObservedAt int64  `bson:"lastObservedAt" json:"lastObservedAt"`
+	CreatedAt      int64  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      int64  `bson:"updatedAt" json:"updatedAt"`
+	Revision       int64  `bson:"revision" json:"revision"`
+}
+
+// RecommendedStrategy holds the strategy fields a recommendation proposes.
+type RecommendedStrategy struct {
+	LabelSelectors []LabelSelector `bson:"labelSelectors,omitempty" json:"labelSelectors,omitempty"`
+	Priority       int             `bson:"priority,omitempty" json:"priority,omitempty"`
+	ExecutionTime  int64           `bson:"executionTime,omitempty" json:"executionTime,omitempty"`
+}
+
+// ToScheduleStrategy builds the scheduling strategy the recommendation
+// proposes for its namespace.
+func (r *StrategyRecommendation) ToScheduleStrategy() *ScheduleStrategy {
+	return &ScheduleStrategy{
+		StrategyNamespace: r.Namespace,
+		K8sNamespace:      []string{r.Namespace},
+		LabelSelectors:    r.Strategy.LabelSelectors,
+		Priority:          r.Strategy.Priority,
+		ExecutionTime:     r.Strategy.ExecutionTime,
+	}
+}
+
+// RecommendationAutoApplyPolicy opts a namespace into applying stable
+// recommendations without human approval.
+type RecommendationAutoApplyPolicy struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Namespace string        `bson:"namespace" json:"namespace"`
+	Enabled   bool          `bson:"enabled" json:"enabled"`
+	// MaxPriority caps the priority of auto-applied strategies. Drafts above
+	// the cap are applied with the capped priority.
+	MaxPriority int `bson:"maxPriority" json:"maxPriority"`
+	// CooldownSeconds is the minimum time between two automatic decisions,
+	// apply or revert, on the same pod.
+	CooldownSeconds int64 `bson:"cooldownSeconds" json:"cooldownSeconds"`
+	// RevertAfterDriftSeconds removes an auto-applied strategy when the pod
+	// keeps drifting from its profile for this long. Zero disables reverts.
+	RevertAfterDriftSeconds int64 `bson:"revertAfterDriftSeconds" json:"revertAfterDriftSeconds"`
+	// OwnerID is the user who enabled auto-apply. Auto-applied strategies are
+	// created on their behalf.
+	OwnerID   string `bson:"ownerId" json:"ownerId"`
+	UpdatedAt int64  `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (p *RecommendationAutoApplyPolicy) Validate() error {
+	if strings.TrimSpace(p.Namespace) == "" {
+		return fmt.Errorf("namespace is required")
+	}
+	if p.MaxPriority < 0 {
+		return fmt.Errorf("maxPriority must not be negative")
+	}
+	if p.CooldownSeconds < 0 {
+		return fmt.Errorf("cooldownSeconds must not be negative")
+	}
+	if p.RevertAfterDriftSeconds < 0 {
+		return fmt.Errorf("revertAfterDriftSeconds must not be negative")
+	}
+	return nil
+}
+
+type QueryStrategyRecommendationOptions struct {
+	IDs        []bson.ObjectID
+	Namespaces []string
+	Pods       []string
+	States     []RecommendationState
+	// UpdatedAfter keeps recommendations updated at or after this Unix time
+	// in milliseconds.
+	UpdatedAfter int64
+	Result       []*StrategyRecommendation
+}
+
+type QueryRecommendationAutoApplyPolicyOptions struct {
+	Namespaces []string
+	Result     []*RecommendationAutoApplyPolicy
+}
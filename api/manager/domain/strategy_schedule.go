@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Gthulhu/api/pkg/cronexpr"
+)
+
+// StrategySchedule limits when a schedule strategy is active. A strategy
+// without windows is active all the time until it expires; otherwise it is
+// active while any of its windows is open.
+type StrategySchedule struct {
+	// Timezone is the IANA name windows are evaluated in; UTC when empty.
+	Timezone string             `bson:"timezone,omitempty"`
+	Windows  []ActivationWindow `bson:"windows,omitempty"`
+	// ExpiresAt (Unix milliseconds) deactivates the strategy for good; zero
+	// never expires.
+	ExpiresAt int64 `bson:"expiresAt,omitempty"`
+}
+
+// ActivationWindow is either a cron window or a daily time range.
+//
+// A cron window opens at every match of Cron and stays open for
+// DurationSeconds. A time range opens at Start and closes at End ("HH:MM")
+// on each of Days ("mon".."sun", every day when empty); an End before Start
+// closes on the next day.
+type ActivationWindow struct {
+	Cron            string   `bson:"cron,omitempty"`
+	DurationSeconds int64    `bson:"durationSeconds,omitempty"`
+	Start           string   `bson:"start,omitempty"`
+	End             string   `bson:"end,omitempty"`
+	Days            []string `bson:"days,omitempty"`
+}
+
+var weekdaysByName = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// maxTransitionSteps bounds the search for the next transition of windows
+// that overlap back to back, such as a one-minute cron with a longer
+// duration, which never close.
+const maxTransitionSteps = 1000
+
+// Validate reports the first invalid field of the schedule.
+func (s *StrategySchedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	for i := range s.Windows {
+		if _, err := s.Windows[i].compile(); err != nil {
+			return fmt.Errorf("window %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Active reports whether the schedule is active at now, and the next time
+// that changes. The zero time means it never changes again. An invalid
+// schedule is never active.
+func (s *StrategySchedule) Active(now time.Time) (bool, time.Time) {
+	if s == nil {
+		return true, time.Time{}
+	}
+	loc, err := s.location()
+	if err != nil {
+		return false, time.Time{}
+	}
+	windows := make([]*compiledWindow, 0, len(s.Windows))
+	for i := range s.Windows {
+		w, err := s.Windows[i].compile()
+		if err != nil {
+			return false, time.Time{}
+		}
+		windows = append(windows, w)
+	}
+	now = now.In(loc)
+	var expiry time.Time
+	if s.ExpiresAt > 0 {
+		expiry = time.UnixMilli(s.ExpiresAt).In(loc)
+		if !now.Before(expiry) {
+			return false, time.Time{}
+		}
+	}
+
+	active := len(windows) == 0 || openAt(windows, now)
+	next := now
+	for step := 0; step < maxTransitionSteps && len(windows) > 0; step++ {
+		next = nextBoundary(windows, next)
+		if next.IsZero() || (!expiry.IsZero() && !next.Before(expiry)) {
+			break
+		}
+		if openAt(windows, next) != active {
+			return active, next
+		}
+	}
+	if active && !expiry.IsZero() {
+		return true, expiry
+	}
+	return active, time.Time{}
+}
+
+func (s *StrategySchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
+
+type compiledWindow struct {
+	cron     *cronexpr.Expression
+	duration time.Duration
+	// Time ranges, in minutes since midnight.
+	start, end int
+	days       map[time.Weekday]bool
+}
+
+func (w *ActivationWindow) compile() (*compiledWindow, error) {
+	if w.Cron != "" {
+		if w.Start != "" || w.End != "" || len(w.Days) > 0 {
+			return nil, fmt.Errorf("cron windows cannot set start, end or days")
+		}
+		if w.DurationSeconds <= 0 {
+			return nil, fmt.Errorf("cron windows need a positive durationSeconds")
+		}
+		expr, err := cronexpr.Parse(w.Cron)
+		if err != nil {
+			return nil, err
+		}
+		return &compiledWindow{cron: expr, duration: time.Duration(w.DurationSeconds) * time.Second}, nil
+	}
+	if w.DurationSeconds != 0 {
+		return nil, fmt.Errorf("durationSeconds is only valid with cron")
+	}
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("start and end must differ")
+	}
+	window := &compiledWindow{start: start, end: end}
+	if len(w.Days) > 0 {
+		window.days = make(map[time.Weekday]bool, len(w.Days))
+		for _, day := range w.Days {
+			weekday, ok := weekdaysByName[day]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %q, must be one of sun, mon, tue, wed, thu, fri, sat", day)
+			}
+			window.days[weekday] = true
+		}
+	}
+	return window, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func openAt(windows []*compiledWindow, t time.Time) bool {
+	for _, w := range windows {
+		if w.openAt(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compiledWindow) openAt(t time.Time) bool {
+	if w.cron != nil {
+		start := w.cron.Next(t.Add(-w.duration))
+		return !start.IsZero() && !start.After(t)
+	}
+	for offset := -1; offset <= 0; offset++ {
+		start, end, ok := w.rangeOn(t, offset)
+		if ok && !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeOn returns the time range opening dayOffset days from t's date.
+func (w *compiledWindow) rangeOn(t time.Time, dayOffset int) (time.Time, time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day()+dayOffset, 0, 0, 0, 0, t.Location())
+	if w.days != nil && !w.days[day.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), w.start/60, w.start%60, 0, 0, day.Location())
+	endDay := day
+	if w.end < w.start {
+		endDay = day.AddDate(0, 0, 1)
+	}
+	end := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), w.end/60, w.end%60, 0, 0, day.Location())
+	return start, end, true
+}
+
+// nextBoundary returns the earliest window opening or closing after t.
+func nextBoundary(windows []*compiledWindow, t time.Time) time.Time {
+	var next time.Time
+	consider := func(candidate time.Time) {
+		if candidate.IsZero() || !candidate.After(t) {
+			return
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	for _, w := range windows {
+		if w.cron != nil {
+			consider(w.cron.Next(t))
+			if start := w.cron.Next(t.Add(-w.duration)); !start.IsZero() {
+				consider(start.Add(w.duration))
+			}
+			continue
+		}
+		for offset := -1; offset <= 7; offset++ {
+			if start, end, ok := w.rangeOn(t, offset); ok {
+				consider(start)
+				consider(end)
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategyScheduleNilIsAlwaysActive(t *testing.T) {
+	var schedule *StrategySchedule
+	active, next := schedule.Active(time.Now())
+	assert.True(t, active)
+	assert.True(t, next.IsZero())
+}
+
+func TestStrategyScheduleTimeRange(t *testing.T) {
+	schedule := &StrategySchedule{
+		Windows: []ActivationWindow{{Start: "09:00", End: "18:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}}},
+	}
+	require.NoError(t, schedule.Validate())
+
+	// Friday 17:00 UTC: open until 18:00.
+	active, next := schedule.Active(time.Date(2026, time.March, 6, 17, 0, 0, 0, time.UTC))
+	assert.True(t, active)
+	assert.Equal(t, time.Date(2026, time.March, 6, 18, 0, 0, 0, time.UTC), next.UTC())
+
+	// Friday 18:00: closed over the weekend until Monday 09:00.
+	active, next = schedule.Active(time.Date(2026, time.March, 6, 18, 0, 0, 0, time.UTC))
+	assert.False(t, active)
+	assert.Equal(t, time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestStrategyScheduleOvernightRangeInTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	schedule := &StrategySchedule{
+		Timezone: "Asia/Taipei",
+		Windows:  []ActivationWindow{{Start: "22:00", End: "06:00"}},
+	}
+
+	active, next := schedule.Active(time.Date(2026, time.March, 7, 2, 0, 0, 0, loc))
+	assert.True(t, active)
+	assert.True(t, next.Equal(time.Date(2026, time.March, 7, 6, 0, 0, 0, loc)))
+
+	active, next = schedule.Active(time.Date(2026, time.March, 7, 12, 0, 0, 0, loc))
+	assert.False(t, active)
+	assert.True(t, next.Equal(time.Date(2026, time.March, 7, 22, 0, 0, 0, loc)))
+}
+
+func TestStrategyScheduleCronWindow(t *testing.T) {
+	schedule := &StrategySchedule{
+		Windows: []ActivationWindow{{Cron: "0 22 * * *", DurationSeconds: 8 * 3600}},
+	}
+	require.NoError(t, schedule.Validate())
+
+	active, next := schedule.Active(time.Date(2026, time.March, 7, 5, 59, 0, 0, time.UTC))
+	assert.True(t, active)
+	assert.Equal(t, time.Date(2026, time.March, 7, 6, 0, 0, 0, time.UTC), next)
+
+	active, next = schedule.Active(time.Date(2026, time.March, 7, 6, 0, 0, 0, time.UTC))
+	assert.False(t, active)
+	assert.Equal(t, time.Date(2026, time.March, 7, 22, 0, 0, 0, time.UTC), next)
+}
+
+func TestStrategyScheduleOverlappingWindowsMerge(t *testing.T) {
+	schedule := &StrategySchedule{
+		Windows: []ActivationWindow{
+			{Start: "08:00", End: "12:00"},
+			{Start: "11:00", End: "13:00"},
+		},
+	}
+	active, next := schedule.Active(time.Date(2026, time.March, 7, 9, 0, 0, 0, time.UTC))
+	assert.True(t, active)
+	assert.Equal(t, time.Date(2026, time.March, 7, 13, 0, 0, 0, time.UTC), next)
+}
+
+func TestStrategyScheduleExpiry(t *testing.T) {
+	expiry := time.Date(2026, time.March, 7, 12, 0, 0, 0, time.UTC)
+	schedule := &StrategySchedule{ExpiresAt: expiry.UnixMilli()}
+
+	active, next := schedule.Active(expiry.Add(-time.Hour))
+	assert.True(t, active)
+	assert.True(t, next.Equal(expiry))
+
+	active, next = schedule.Active(expiry)
+	assert.False(t, active)
+	assert.True(t, next.IsZero())
+}
+
+func TestStrategyScheduleValidate(t *testing.T) {
+	for name, schedule := range map[string]*StrategySchedule{
+		"unknown timezone":      {Timezone: "Mars/Olympus"},
+		"bad cron":              {Windows: []ActivationWindow{{Cron: "0 25 * * *", DurationSeconds: 60}}},
+		"cron without duration": {Windows: []ActivationWindow{{Cron: "0 1 * * *"}}},
+		"cron with range":       {Windows: []ActivationWindow{{Cron: "0 1 * * *", DurationSeconds: 60, Start: "01:00"}}},
+		"bad clock":             {Windows: []ActivationWindow{{Start: "9am", End: "18:00"}}},
+		"empty range":           {Windows: []ActivationWindow{{Start: "09:00", End: "09:00"}}},
+		"bad day":               {Windows: []ActivationWindow{{Start: "09:00", End: "18:00", Days: []string{"someday"}}}},
+	} {
+		assert.Error(t, schedule.Validate(), name)
+	}
+}
@@ -39,6 +39,7 @@ type Adapter struct {
 	startWatcher   sync.Once
 	stopWatcher    sync.Once
 	cacheHasSynced atomic.Bool
+	podEventFunc   atomic.Pointer[func(domain.PodEvent)]
 }
 
 func NewAdapter(opt Options) (*Adapter, error) {
@@ -106,23 +107,33 @@ func (a *Adapter) startPodWatcher() {
 				}
 				logger.Logger(context.Background()).Debug().Msgf("pod added: %s/%s", pod.Namespace, pod.Name)
 				a.setPodCache(*pod)
+				a.notifyPodEvent(domain.PodEventAdded, pod, nil)
 			},
-			UpdateFunc: func(_, newObj interface{}) {
+			UpdateFunc: func(oldObj, newObj interface{}) {
 				pod, ok := newObj.(*apiv1.Pod)
 				if !ok {
 					return
 				}
 				logger.Logger(context.Background()).Debug().Msgf("pod updated: %s/%s", pod.Namespace, pod.Name)
 				a.setPodCache(*pod)
+				// Only changes that move the pod in or out of strategies, or
+				// onto a node, are worth an event.
+				oldPod, ok := oldObj.(*apiv1.Pod)
+				if !ok || (oldPod.Spec.NodeName == pod.Spec.NodeName && labels.Equals(oldPod.Labels, pod.Labels)) {
+					return
+				}
+				a.notifyPodEvent(domain.PodEventUpdated, pod, oldPod.Labels)
 			},
 			DeleteFunc: func(obj interface{}) {
 				switch pod := obj.(type) {
 				case *apiv1.Pod:
 					logger.Logger(context.Background()).Debug().Msgf("pod deleted: %s/%s", pod.Namespace, pod.Name)
 					a.deletePodCache(string(pod.UID))
+					a.notifyPodEvent(domain.PodEventDeleted, pod, nil)
 				case cache.DeletedFinalStateUnknown:
 					if p, ok := pod.Obj.(*apiv1.Pod); ok {
 						a.deletePodCache(string(p.UID))
+						a.notifyPodEvent(domain.PodEventDeleted, p, nil)
 					}
 				}
 			},
@@ -136,6 +147,26 @@ func (a *Adapter) startPodWatcher() {
 	})
 }
 
+// SetPodEventHandler makes the pod watcher report pods that are added,
+// deleted, relabelled or scheduled onto a node to handler. The pods listed
+// when the watcher starts are not reported. handler runs on the watcher
+// goroutine and must not block.
+func (a *Adapter) SetPodEventHandler(handler func(domain.PodEvent)) {
+	a.podEventFunc.Store(&handler)
+}
+
+func (a *Adapter) notifyPodEvent(eventType domain.PodEventType, pod *apiv1.Pod, oldLabels map[string]string) {
+	handler := a.podEventFunc.Load()
+	if handler == nil || !a.cacheHasSynced.Load() {
+		return
+	}
+	(*handler)(domain.PodEvent{
+		Type:      eventType,
+		Pod:       toDomainPod(*pod),
+		OldLabels: copyLabels(oldLabels),
+	})
+}
+
 func (a *Adapter) StopPodWatcher() {
 	a.stopWatcher.Do(func() {
 		if a.stopCh != nil {
@@ -165,21 +196,23 @@ func (a *Adapter) QueryPods(ctx context.Context, opt *domain.QueryPodsOptions) (
 	results := make([]*domain.Pod, 0, len(pods))
 
 	for _, pod := range pods {
-		podLabels := copyLabels(pod.Labels)
-		result := &domain.Pod{
-			Name:         pod.Name,
-			K8SNamespace: pod.Namespace,
-			Labels:       podLabels,
-			PodID:        string(pod.UID),
-			NodeID:       pod.Spec.NodeName,
-			Containers:   buildContainers(pod),
-		}
-		results = append(results, result)
+		results = append(results, toDomainPod(pod))
 	}
 
 	return results, nil
 }
 
+func toDomainPod(pod apiv1.Pod) *domain.Pod {
+	return &domain.Pod{
+		Name:         pod.Name,
+		K8SNamespace: pod.Namespace,
+		Labels:       copyLabels(pod.Labels),
+		PodID:        string(pod.UID),
+		NodeID:       pod.Spec.NodeName,
+		Containers:   buildContainers(pod),
+	}
+}
+
 func (a *Adapter) QueryDecisionMakerPods(ctx context.Context, opt *domain.QueryDecisionMakerPodsOptions) ([]*domain.DecisionMakerPod, error) {
 	if opt == nil {
 		return nil, domain.ErrNilQueryInput
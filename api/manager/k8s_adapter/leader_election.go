@@ -0,0 +1,98 @@
+package k8sadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElector campaigns for the Lease of the manager replicas and records
+// the outcome in a domain.Leadership.
+type LeaderElector struct {
+	elector    *leaderelection.LeaderElector
+	leadership *domain.Leadership
+}
+
+// NewLeaderElector builds the elector described by cfg for the cluster opt
+// points to, which holds the Lease.
+func NewLeaderElector(cfg config.LeaderElectionConfig, opt Options, leadership *domain.Leadership) (*LeaderElector, error) {
+	restConfig, err := buildConfig(opt)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
+	return newLeaderElector(client, cfg, leadership)
+}
+
+func newLeaderElector(client kubernetes.Interface, cfg config.LeaderElectionConfig, leadership *domain.Leadership) (*LeaderElector, error) {
+	identity := cfg.ResolvedIdentity()
+	if identity == "" {
+		return nil, fmt.Errorf("leader election identity is required when the host name is unknown")
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      cfg.ResolvedLeaseName(),
+			Namespace: cfg.ResolvedLeaseNamespace(),
+		},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   cfg.LeaseDuration(),
+		RenewDeadline:   cfg.RenewDeadline(),
+		RetryPeriod:     cfg.RetryPeriod(),
+		ReleaseOnCancel: true,
+		Name:            cfg.ResolvedLeaseName(),
+		Callbacks: leaderelection.LeaderCallbacks{
+			// OnStartedLeading runs in its own goroutine and ctx is canceled
+			// before OnStoppedLeading, so checking ctx under the lock keeps a
+			// late start from overriding the stop.
+			OnStartedLeading: func(ctx context.Context) {
+				leadership.Update(func(status *domain.LeaderElectionStatus) {
+					if ctx.Err() == nil {
+						status.IsLeader = true
+					}
+				})
+				logger.Logger(ctx).Info().Msgf("%s became the manager leader", identity)
+			},
+			OnStoppedLeading: func() {
+				var wasLeader bool
+				leadership.Update(func(status *domain.LeaderElectionStatus) {
+					wasLeader = status.IsLeader
+					status.IsLeader = false
+				})
+				if wasLeader {
+					logger.Logger(context.Background()).Warn().Msgf("%s stopped leading the managers", identity)
+				}
+			},
+			OnNewLeader: func(leader string) {
+				leadership.Update(func(status *domain.LeaderElectionStatus) {
+					status.Leader = leader
+				})
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create leader elector: %w", err)
+	}
+	return &LeaderElector{elector: elector, leadership: leadership}, nil
+}
+
+// Run campaigns for the Lease until ctx is done, campaigning again whenever
+// leadership is lost. The Lease is released when ctx is done.
+func (e *LeaderElector) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		e.elector.Run(ctx)
+	}
+}
@@ -0,0 +1,76 @@
+package k8sadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeadershipWithoutElectionAlwaysLeads(t *testing.T) {
+	var nilLeadership *domain.Leadership
+	assert.True(t, nilLeadership.IsLeader())
+	assert.True(t, domain.NewLeadership(false, "manager-0").IsLeader())
+	assert.False(t, domain.NewLeadership(true, "manager-0").IsLeader())
+}
+
+func TestLeaderElectorHandsOverLeaseOnShutdown(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	cfg := config.LeaderElectionConfig{
+		Enabled:        true,
+		LeaseNamespace: "gthulhu",
+		// Long enough for the leader to keep the lease on a busy machine;
+		// the handover below comes from releasing it, not from it expiring.
+		LeaseDurationSeconds: 15,
+		RenewDeadlineSeconds: 10,
+		RetryPeriodSeconds:   1,
+	}
+
+	cfgA := cfg
+	cfgA.Identity = "manager-a"
+	leadershipA := domain.NewLeadership(true, cfgA.Identity)
+	electorA, err := newLeaderElector(client, cfgA, leadershipA)
+	require.NoError(t, err)
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	go func() {
+		defer close(doneA)
+		electorA.Run(ctxA)
+	}()
+	defer func() {
+		cancelA()
+		<-doneA
+	}()
+	require.Eventually(t, leadershipA.IsLeader, 10*time.Second, 50*time.Millisecond)
+
+	cfgB := cfg
+	cfgB.Identity = "manager-b"
+	leadershipB := domain.NewLeadership(true, cfgB.Identity)
+	electorB, err := newLeaderElector(client, cfgB, leadershipB)
+	require.NoError(t, err)
+	ctxB, cancelB := context.WithCancel(context.Background())
+	doneB := make(chan struct{})
+	go func() {
+		defer close(doneB)
+		electorB.Run(ctxB)
+	}()
+	defer func() {
+		cancelB()
+		<-doneB
+	}()
+	require.Eventually(t, func() bool {
+		return leadershipB.Status().Leader == "manager-a"
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.False(t, leadershipB.IsLeader())
+
+	cancelA()
+	<-doneA
+	assert.False(t, leadershipA.IsLeader())
+	require.Eventually(t, leadershipB.IsLeader, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "manager-b", leadershipB.Status().Leader)
+}
@@ -0,0 +1,314 @@
+package k8sadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+var _ domain.K8SAdapter = (*MultiClusterAdapter)(nil)
+
+// Cluster is a Kubernetes cluster federated by the manager, queried through
+// its own adapter and pod cache.
+type Cluster struct {
+	Name    string
+	Adapter domain.K8SAdapter
+}
+
+// MultiClusterAdapter federates the adapters of several clusters behind
+// domain.K8SAdapter. Every query goes to the clusters its Clusters option
+// names, or to all of them, and the pods, nodes and decision makers found
+// carry the name of their cluster.
+//
+// Nodes are told apart by name only, as everywhere else in the manager, so
+// node names must be unique across the federated clusters. Check them with
+// VerifyUniqueNodeNames before use; decision makers of a node name found in
+// several clusters later on are skipped.
+type MultiClusterAdapter struct {
+	clusters []Cluster
+}
+
+// NewMultiClusterAdapter federates clusters, which need distinct, non-empty
+// names.
+func NewMultiClusterAdapter(clusters ...Cluster) (*MultiClusterAdapter, error) {
+	if len(clusters) == 0 {
+		return nil, errors.New("no cluster to federate")
+	}
+	seen := make(map[string]struct{}, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			return nil, errors.New("cluster name is required")
+		}
+		if cluster.Adapter == nil {
+			return nil, fmt.Errorf("cluster %s has no adapter", cluster.Name)
+		}
+		if _, ok := seen[cluster.Name]; ok {
+			return nil, fmt.Errorf("duplicate cluster %s", cluster.Name)
+		}
+		seen[cluster.Name] = struct{}{}
+	}
+	return &MultiClusterAdapter{clusters: clusters}, nil
+}
+
+// ClusterOptions is a cluster to build an adapter for.
+type ClusterOptions struct {
+	Name string
+	Options
+}
+
+// kubeConfigExtensions are stripped from kubeconfig file names to get the
+// name of their cluster.
+var kubeConfigExtensions = []string{".yaml", ".yml", ".kubeconfig"}
+
+// LoadClusterKubeConfigs lists the kubeconfigs in dir, one per cluster named
+// after the file. Hidden files are skipped, which also skips the bookkeeping
+// entries of mounted Secrets and ConfigMaps.
+func LoadClusterKubeConfigs(dir string) ([]ClusterOptions, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read clusters dir %s: %w", dir, err)
+	}
+	var clusters []ClusterOptions
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Stat follows the symlinks mounted Secrets are made of.
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat kubeconfig %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		name := entry.Name()
+		for _, ext := range kubeConfigExtensions {
+			name = strings.TrimSuffix(name, ext)
+		}
+		clusters = append(clusters, ClusterOptions{Name: name, Options: Options{KubeConfigPath: path}})
+	}
+	return clusters, nil
+}
+
+// Clusters returns the names of the federated clusters.
+func (m *MultiClusterAdapter) Clusters() []string {
+	names := make([]string, 0, len(m.clusters))
+	for _, cluster := range m.clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// selected returns the clusters named by names, or every cluster when it is
+// empty. Unknown names select nothing.
+func (m *MultiClusterAdapter) selected(names []string) []Cluster {
+	if len(names) == 0 {
+		return m.clusters
+	}
+	wanted := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		wanted[name] = struct{}{}
+	}
+	clusters := make([]Cluster, 0, len(names))
+	for _, cluster := range m.clusters {
+		if _, ok := wanted[cluster.Name]; ok {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+// QueryPods queries the pods of the selected clusters. It fails when any of
+// them fails, since callers delete the intents of pods that are not found.
+func (m *MultiClusterAdapter) QueryPods(ctx context.Context, opt *domain.QueryPodsOptions) ([]*domain.Pod, error) {
+	if opt == nil {
+		return nil, domain.ErrNilQueryInput
+	}
+	var results []*domain.Pod
+	for _, cluster := range m.selected(opt.Clusters) {
+		pods, err := cluster.Adapter.QueryPods(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+		for _, pod := range pods {
+			pod.Cluster = cluster.Name
+		}
+		results = append(results, pods...)
+	}
+	return results, nil
+}
+
+// QueryDecisionMakerPods queries the decision makers of the selected
+// clusters. Clusters that fail are left out as long as one succeeds: their
+// decision makers are just skipped until the next query.
+func (m *MultiClusterAdapter) QueryDecisionMakerPods(ctx context.Context, opt *domain.QueryDecisionMakerPodsOptions) ([]*domain.DecisionMakerPod, error) {
+	if opt == nil {
+		return nil, domain.ErrNilQueryInput
+	}
+	var (
+		results []*domain.DecisionMakerPod
+		errs    []error
+	)
+	clusters := m.selected(opt.Clusters)
+	for _, cluster := range clusters {
+		dms, err := cluster.Adapter.QueryDecisionMakerPods(ctx, opt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster.Name, err))
+			continue
+		}
+		for _, dm := range dms {
+			dm.Cluster = cluster.Name
+		}
+		results = append(results, dms...)
+	}
+	if len(errs) > 0 {
+		if len(errs) == len(clusters) {
+			return nil, errors.Join(errs...)
+		}
+		logger.Logger(ctx).Warn().Err(errors.Join(errs...)).Msg("failed to query decision maker pods of some clusters")
+	}
+	// Registrations and intents are keyed by node name, so a decision maker
+	// could get the intents of a namesake in another cluster.
+	duplicates := duplicateNodeNames(results, func(dm *domain.DecisionMakerPod) (string, string) { return dm.Cluster, dm.NodeID })
+	if len(duplicates) > 0 {
+		logger.Logger(ctx).Error().Msgf("skipping the decision makers of nodes %v, which exist in several clusters; node names must be unique across clusters", duplicates)
+		results = slices.DeleteFunc(results, func(dm *domain.DecisionMakerPod) bool { return slices.Contains(duplicates, dm.NodeID) })
+	}
+	return results, nil
+}
+
+// ListNodes lists the nodes of every cluster.
+func (m *MultiClusterAdapter) ListNodes(ctx context.Context) ([]*domain.Node, error) {
+	results, err := m.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if duplicates := duplicateNodeNames(results, nodeClusterAndName); len(duplicates) > 0 {
+		logger.Logger(ctx).Error().Msgf("nodes %v exist in several clusters; node names must be unique across clusters", duplicates)
+	}
+	return results, nil
+}
+
+// VerifyUniqueNodeNames fails when a node name exists in more than one
+// cluster.
+func (m *MultiClusterAdapter) VerifyUniqueNodeNames(ctx context.Context) error {
+	nodes, err := m.listNodes(ctx)
+	if err != nil {
+		return err
+	}
+	if duplicates := duplicateNodeNames(nodes, nodeClusterAndName); len(duplicates) > 0 {
+		return fmt.Errorf("nodes %v exist in several clusters; node names must be unique across clusters", duplicates)
+	}
+	return nil
+}
+
+func (m *MultiClusterAdapter) listNodes(ctx context.Context) ([]*domain.Node, error) {
+	var results []*domain.Node
+	for _, cluster := range m.clusters {
+		nodes, err := cluster.Adapter.ListNodes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+		results = append(results, withCluster(nodes, cluster.Name)...)
+	}
+	return results, nil
+}
+
+// QueryNodesBySelectors queries the nodes of the selected clusters. It fails
+// when any of them fails, since callers delete the node intents of nodes that
+// are not found.
+func (m *MultiClusterAdapter) QueryNodesBySelectors(ctx context.Context, opt *domain.QueryNodesOptions) ([]*domain.Node, error) {
+	if opt == nil {
+		return nil, domain.ErrNilQueryInput
+	}
+	var results []*domain.Node
+	for _, cluster := range m.selected(opt.Clusters) {
+		nodes, err := cluster.Adapter.QueryNodesBySelectors(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+		results = append(results, withCluster(nodes, cluster.Name)...)
+	}
+	return results, nil
+}
+
+// QueryNodesByDRA is QueryNodesBySelectors for DRA selectors.
+func (m *MultiClusterAdapter) QueryNodesByDRA(ctx context.Context, opt *domain.QueryNodesByDRAOptions) ([]*domain.Node, error) {
+	if opt == nil {
+		return nil, domain.ErrNilQueryInput
+	}
+	var results []*domain.Node
+	for _, cluster := range m.selected(opt.Clusters) {
+		nodes, err := cluster.Adapter.QueryNodesByDRA(ctx, opt)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+		results = append(results, withCluster(nodes, cluster.Name)...)
+	}
+	return results, nil
+}
+
+// podEventSource is implemented by the adapters that watch pods.
+type podEventSource interface {
+	SetPodEventHandler(handler func(domain.PodEvent))
+}
+
+// SetPodEventHandler reports the pod events of every cluster whose adapter
+// watches pods to handler, with the pod carrying the name of its cluster.
+func (m *MultiClusterAdapter) SetPodEventHandler(handler func(domain.PodEvent)) {
+	for _, cluster := range m.clusters {
+		source, ok := cluster.Adapter.(podEventSource)
+		if !ok {
+			continue
+		}
+		name := cluster.Name
+		source.SetPodEventHandler(func(event domain.PodEvent) {
+			if event.Pod != nil {
+				event.Pod.Cluster = name
+			}
+			handler(event)
+		})
+	}
+}
+
+func withCluster(nodes []*domain.Node, cluster string) []*domain.Node {
+	for _, node := range nodes {
+		node.Cluster = cluster
+	}
+	return nodes
+}
+
+func nodeClusterAndName(node *domain.Node) (string, string) {
+	return node.Cluster, node.Name
+}
+
+// duplicateNodeNames returns the node names that items place in more than
+// one cluster.
+func duplicateNodeNames[T any](items []T, clusterAndName func(T) (string, string)) []string {
+	clusterOf := make(map[string]string, len(items))
+	var duplicates []string
+	for _, item := range items {
+		cluster, name := clusterAndName(item)
+		if seen, ok := clusterOf[name]; ok && seen != cluster {
+			if !slices.Contains(duplicates, name) {
+				duplicates = append(duplicates, name)
+			}
+			continue
+		}
+		clusterOf[name] = cluster
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
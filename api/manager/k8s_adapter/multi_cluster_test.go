@@ -0,0 +1,171 @@
+package k8sadapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMultiClusterAdapterRejectsInvalidClusters(t *testing.T) {
+	adapter := domain.NewMockK8SAdapter(t)
+
+	_, err := NewMultiClusterAdapter()
+	assert.Error(t, err)
+	_, err = NewMultiClusterAdapter(Cluster{Adapter: adapter})
+	assert.Error(t, err)
+	_, err = NewMultiClusterAdapter(Cluster{Name: "east"})
+	assert.Error(t, err)
+	_, err = NewMultiClusterAdapter(Cluster{Name: "east", Adapter: adapter}, Cluster{Name: "east", Adapter: adapter})
+	assert.Error(t, err)
+}
+
+func TestMultiClusterAdapterQueryPodsStampsAndSelectsClusters(t *testing.T) {
+	east := domain.NewMockK8SAdapter(t)
+	west := domain.NewMockK8SAdapter(t)
+	m, err := NewMultiClusterAdapter(Cluster{Name: "east", Adapter: east}, Cluster{Name: "west", Adapter: west})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"east", "west"}, m.Clusters())
+
+	east.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{{PodID: "a", NodeID: "node-1"}}, nil).Once()
+	west.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{{PodID: "b", NodeID: "node-2"}}, nil).Once()
+	pods, err := m.QueryPods(context.Background(), &domain.QueryPodsOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "east", pods[0].Cluster)
+	assert.Equal(t, "west", pods[1].Cluster)
+
+	west.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{{PodID: "b", NodeID: "node-2"}}, nil).Once()
+	pods, err = m.QueryPods(context.Background(), &domain.QueryPodsOptions{Clusters: []string{"west"}})
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, "b", pods[0].PodID)
+
+	pods, err = m.QueryPods(context.Background(), &domain.QueryPodsOptions{Clusters: []string{"north"}})
+	require.NoError(t, err)
+	assert.Empty(t, pods)
+}
+
+func TestMultiClusterAdapterQueryNodesFailsWhenAnyClusterFails(t *testing.T) {
+	east := domain.NewMockK8SAdapter(t)
+	west := domain.NewMockK8SAdapter(t)
+	m, err := NewMultiClusterAdapter(Cluster{Name: "east", Adapter: east}, Cluster{Name: "west", Adapter: west})
+	require.NoError(t, err)
+
+	east.EXPECT().QueryNodesBySelectors(mock.Anything, mock.Anything).Return([]*domain.Node{{Name: "node-1"}}, nil).Once()
+	west.EXPECT().QueryNodesBySelectors(mock.Anything, mock.Anything).Return(nil, errors.New("unreachable")).Once()
+	_, err = m.QueryNodesBySelectors(context.Background(), &domain.QueryNodesOptions{})
+	assert.ErrorContains(t, err, "cluster west")
+}
+
+func TestMultiClusterAdapterQueryDecisionMakerPodsToleratesPartialFailure(t *testing.T) {
+	east := domain.NewMockK8SAdapter(t)
+	west := domain.NewMockK8SAdapter(t)
+	m, err := NewMultiClusterAdapter(Cluster{Name: "east", Adapter: east}, Cluster{Name: "west", Adapter: west})
+	require.NoError(t, err)
+
+	east.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{{NodeID: "node-1", Host: "10.0.0.1"}}, nil).Once()
+	west.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return(nil, errors.New("unreachable")).Once()
+	dms, err := m.QueryDecisionMakerPods(context.Background(), &domain.QueryDecisionMakerPodsOptions{})
+	require.NoError(t, err)
+	require.Len(t, dms, 1)
+	assert.Equal(t, "east", dms[0].Cluster)
+
+	east.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return(nil, errors.New("unreachable")).Once()
+	west.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return(nil, errors.New("unreachable")).Once()
+	_, err = m.QueryDecisionMakerPods(context.Background(), &domain.QueryDecisionMakerPodsOptions{})
+	assert.Error(t, err)
+}
+
+func TestMultiClusterAdapterVerifyUniqueNodeNames(t *testing.T) {
+	east := domain.NewMockK8SAdapter(t)
+	west := domain.NewMockK8SAdapter(t)
+	m, err := NewMultiClusterAdapter(Cluster{Name: "east", Adapter: east}, Cluster{Name: "west", Adapter: west})
+	require.NoError(t, err)
+
+	east.EXPECT().ListNodes(mock.Anything).Return([]*domain.Node{{Name: "node-1"}}, nil).Once()
+	west.EXPECT().ListNodes(mock.Anything).Return([]*domain.Node{{Name: "node-2"}}, nil).Once()
+	require.NoError(t, m.VerifyUniqueNodeNames(context.Background()))
+
+	east.EXPECT().ListNodes(mock.Anything).Return([]*domain.Node{{Name: "node-1"}}, nil).Once()
+	west.EXPECT().ListNodes(mock.Anything).Return([]*domain.Node{{Name: "node-1"}}, nil).Once()
+	assert.ErrorContains(t, m.VerifyUniqueNodeNames(context.Background()), "[node-1]")
+}
+
+func TestMultiClusterAdapterSkipsDecisionMakersOfDuplicateNodes(t *testing.T) {
+	east := domain.NewMockK8SAdapter(t)
+	west := domain.NewMockK8SAdapter(t)
+	m, err := NewMultiClusterAdapter(Cluster{Name: "east", Adapter: east}, Cluster{Name: "west", Adapter: west})
+	require.NoError(t, err)
+
+	east.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{
+		{NodeID: "node-1", Host: "10.0.0.1"}, {NodeID: "node-2", Host: "10.0.0.2"},
+	}, nil).Once()
+	west.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{
+		{NodeID: "node-1", Host: "10.1.0.1"},
+	}, nil).Once()
+	dms, err := m.QueryDecisionMakerPods(context.Background(), &domain.QueryDecisionMakerPodsOptions{})
+	require.NoError(t, err)
+	require.Len(t, dms, 1)
+	assert.Equal(t, "node-2", dms[0].NodeID)
+}
+
+type podEventTestAdapter struct {
+	*domain.MockK8SAdapter
+	handler func(domain.PodEvent)
+}
+
+func (a *podEventTestAdapter) SetPodEventHandler(handler func(domain.PodEvent)) {
+	a.handler = handler
+}
+
+func TestMultiClusterAdapterStampsPodEventsWithCluster(t *testing.T) {
+	east := &podEventTestAdapter{MockK8SAdapter: domain.NewMockK8SAdapter(t)}
+	m, err := NewMultiClusterAdapter(
+		Cluster{Name: "east", Adapter: east},
+		// Adapters that do not watch pods are skipped.
+		Cluster{Name: "west", Adapter: domain.NewMockK8SAdapter(t)},
+	)
+	require.NoError(t, err)
+
+	var events []domain.PodEvent
+	m.SetPodEventHandler(func(event domain.PodEvent) {
+		events = append(events, event)
+	})
+	require.NotNil(t, east.handler)
+	east.handler(domain.PodEvent{Type: domain.PodEventAdded, Pod: &domain.Pod{Name: "web-1"}})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "east", events[0].Pod.Cluster)
+}
+
+func TestLoadClusterKubeConfigs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"east.yaml", "west.kubeconfig", "north", ".hidden"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("apiVersion: v1\n"), 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0o700))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0o700))
+
+	clusters, err := LoadClusterKubeConfigs(dir)
+	require.NoError(t, err)
+	names := make(map[string]string, len(clusters))
+	for _, cluster := range clusters {
+		names[cluster.Name] = cluster.KubeConfigPath
+	}
+	assert.Equal(t, map[string]string{
+		"east":  filepath.Join(dir, "east.yaml"),
+		"north": filepath.Join(dir, "north"),
+		"west":  filepath.Join(dir, "west.kubeconfig"),
+	}, names)
+
+	clusters, err = LoadClusterKubeConfigs("")
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	classifierShardStateCollection = "classifier_shard_states"
+	classifierReplicaCollection    = "classifier_replicas"
+)
+
+// ClaimClassifierShard takes or renews the lease of state.Owner on the shard
+// and, when state.Payload is set, stores it as the shard snapshot. It returns
+// domain.ErrConflict while another replica holds a live lease. On success the
+// stored document, including the latest payload, is decoded into state.
+func (r *repo) ClaimClassifierShard(ctx context.Context, state *domain.ClassifierShardState) error {
+	if state == nil {
+		return errors.New("nil classifier shard state")
+	}
+	if state.Owner == "" {
+		return errors.New("classifier shard owner is required")
+	}
+	now := time.Now().UnixMilli()
+	set := bson.M{
+		"owner":          state.Owner,
+		"ownerAddress":   state.OwnerAddress,
+		"leaseExpiresAt": state.LeaseExpiresAt,
+		"updatedAt":      now,
+	}
+	if len(state.Payload) > 0 {
+		set["payload"] = state.Payload
+		set["snapshotAt"] = now
+	}
+	filter := bson.M{
+		"_id": state.Shard,
+		"$or": bson.A{
+			bson.M{"owner": state.Owner},
+			bson.M{"owner": ""},
+			bson.M{"leaseExpiresAt": bson.M{"$lte": now}},
+		},
+	}
+	res := r.db.Collection(classifierShardStateCollection).FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+	if err := res.Decode(state); err != nil {
+		// The filter misses a shard leased by someone else, so the upsert
+		// collides with the existing _id.
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrConflict
+		}
+		return fmt.Errorf("claim classifier shard, err: %w", err)
+	}
+	return nil
+}
+
+// ReleaseClassifierShard gives up the lease of owner on the shard, keeping the
+// stored snapshot for the next owner.
+func (r *repo) ReleaseClassifierShard(ctx context.Context, shard int, owner string) error {
+	_, err := r.db.Collection(classifierShardStateCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": shard, "owner": owner},
+		bson.M{"$set": bson.M{
+			"owner":          "",
+			"ownerAddress":   "",
+			"leaseExpiresAt": int64(0),
+			"updatedAt":      time.Now().UnixMilli(),
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("release classifier shard, err: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) QueryClassifierShardStates(ctx context.Context, opt *domain.QueryClassifierShardStateOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if len(opt.Shards) > 0 {
+		filter["_id"] = bson.M{"$in": opt.Shards}
+	}
+	cursor, err := r.db.Collection(classifierShardStateCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find classifier shard states, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.ClassifierShardState
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode classifier shard states, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
+
+// HeartbeatClassifierReplica records that the replica is alive.
+func (r *repo) HeartbeatClassifierReplica(ctx context.Context, replica *domain.ClassifierReplica) error {
+	if replica == nil {
+		return errors.New("nil classifier replica")
+	}
+	replica.LastSeenAt = time.Now().UnixMilli()
+	_, err := r.db.Collection(classifierReplicaCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": replica.ID},
+		bson.M{"$set": bson.M{"address": replica.Address, "lastSeenAt": replica.LastSeenAt}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("heartbeat classifier replica, err: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) QueryClassifierReplicas(ctx context.Context, opt *domain.QueryClassifierReplicaOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if opt.SeenAfter > 0 {
+		filter["lastSeenAt"] = bson.M{"$gt": opt.SeenAfter}
+	}
+	cursor, err := r.db.Collection(classifierReplicaCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find classifier replicas, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.ClassifierReplica
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode classifier replicas, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
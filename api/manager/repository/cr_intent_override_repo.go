@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// UpdateIntentOverrides replaces status.overrides of a SchedulingIntent CR.
+// Intents deleted in the meantime are skipped.
+func (r *repo) UpdateIntentOverrides(ctx context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error {
+	return r.updateOverrides(ctx, intentGVR, intentID.Hex(), overrides)
+}
+
+// UpdateNodeIntentOverrides replaces status.overrides of a
+// NodeSchedulingIntent CR.
+func (r *repo) UpdateNodeIntentOverrides(ctx context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error {
+	return r.updateOverrides(ctx, nodeIntentGVR, intentID.Hex(), overrides)
+}
+
+func (r *repo) updateOverrides(ctx context.Context, gvr schema.GroupVersionResource, name string, overrides []domain.IntentOverride) error {
+	obj, err := r.k8sDynamic.Resource(gvr).Namespace(r.crNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get %s CR %s: %w", gvr.Resource, name, err)
+	}
+	if len(overrides) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "status", "overrides")
+	} else if err := unstructured.SetNestedSlice(obj.Object, intentOverridesToUnstructured(overrides), "status", "overrides"); err != nil {
+		return fmt.Errorf("set overrides on %s CR %s: %w", gvr.Resource, name, err)
+	}
+	if _, err := r.k8sDynamic.Resource(gvr).Namespace(r.crNamespace).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update %s CR %s: %w", gvr.Resource, name, err)
+	}
+	return nil
+}
+
+func intentOverridesToUnstructured(overrides []domain.IntentOverride) []interface{} {
+	results := make([]interface{}, 0, len(overrides))
+	for _, override := range overrides {
+		pids := make([]interface{}, 0, len(override.PIDs))
+		for _, pid := range override.PIDs {
+			pids = append(pids, int64(pid))
+		}
+		results = append(results, map[string]interface{}{
+			"winnerKind": override.WinnerKind,
+			"winnerID":   override.WinnerID,
+			"reason":     override.Reason,
+			"pids":       pids,
+		})
+	}
+	return results
+}
+
+func unstructuredToIntentOverrides(obj *unstructured.Unstructured) []domain.IntentOverride {
+	raw, found, err := unstructured.NestedSlice(obj.Object, "status", "overrides")
+	if err != nil || !found {
+		return nil
+	}
+	var overrides []domain.IntentOverride
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		override := domain.IntentOverride{
+			WinnerKind: getStr(m, "winnerKind"),
+			WinnerID:   getStr(m, "winnerID"),
+			Reason:     getStr(m, "reason"),
+		}
+		if pids, ok := m["pids"].([]interface{}); ok {
+			for _, pid := range pids {
+				switch v := pid.(type) {
+				case int64:
+					override.PIDs = append(override.PIDs, int(v))
+				case float64:
+					override.PIDs = append(override.PIDs, int(v))
+				}
+			}
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// UpdateIntentApplyStatus sets spec.state of a SchedulingIntent CR to the
+// state a decision maker reported and replaces status.matchedPIDs,
+// status.lastAppliedAt and status.message. Intents deleted in the meantime
+// are skipped.
+func (r *repo) UpdateIntentApplyStatus(ctx context.Context, intentID bson.ObjectID, report *domain.IntentApplyReport) error {
+	name := intentID.Hex()
+	obj, err := r.k8sDynamic.Resource(intentGVR).Namespace(r.crNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get intent CR %s: %w", name, err)
+	}
+	if err := unstructured.SetNestedField(obj.Object, int64(report.State), "spec", "state"); err != nil {
+		return fmt.Errorf("set state on intent CR %s: %w", name, err)
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[labelState] = strconv.Itoa(int(report.State))
+	obj.SetLabels(labels)
+
+	pids := make([]interface{}, 0, len(report.PIDs))
+	for _, pid := range report.PIDs {
+		pids = append(pids, int64(pid))
+	}
+	setOrRemove := func(value interface{}, empty bool, field string) error {
+		if empty {
+			unstructured.RemoveNestedField(obj.Object, "status", field)
+			return nil
+		}
+		return unstructured.SetNestedField(obj.Object, value, "status", field)
+	}
+	if err := setOrRemove(pids, len(pids) == 0, "matchedPIDs"); err != nil {
+		return fmt.Errorf("set matchedPIDs on intent CR %s: %w", name, err)
+	}
+	if err := setOrRemove(report.LastAppliedAt, report.LastAppliedAt == 0, "lastAppliedAt"); err != nil {
+		return fmt.Errorf("set lastAppliedAt on intent CR %s: %w", name, err)
+	}
+	if err := setOrRemove(report.Message, report.Message == "", "message"); err != nil {
+		return fmt.Errorf("set message on intent CR %s: %w", name, err)
+	}
+	if _, err := r.k8sDynamic.Resource(intentGVR).Namespace(r.crNamespace).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update intent CR %s: %w", name, err)
+	}
+	return nil
+}
+
+// unstructuredToIntentApplyStatus reads the apply status written by
+// UpdateIntentApplyStatus into intent.
+func unstructuredToIntentApplyStatus(obj *unstructured.Unstructured, intent *domain.ScheduleIntent) {
+	status, found, err := unstructured.NestedMap(obj.Object, "status")
+	if err != nil || !found {
+		return
+	}
+	if pids, ok := status["matchedPIDs"].([]interface{}); ok {
+		for _, pid := range pids {
+			switch v := pid.(type) {
+			case int64:
+				intent.MatchedPIDs = append(intent.MatchedPIDs, int(v))
+			case float64:
+				intent.MatchedPIDs = append(intent.MatchedPIDs, int(v))
+			}
+		}
+	}
+	intent.LastAppliedAt = getInt64(status, "lastAppliedAt")
+	intent.StatusMessage = getStr(status, "message")
+}
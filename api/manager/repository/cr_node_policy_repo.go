@@ -343,16 +343,18 @@ func domainNodePolicyToUnstructured(p *domain.NodeSchedulingPolicy, namespace st
 				},
 			},
 			"spec": map[string]interface{}{
-				"nodeSelectors": nodeSelectors,
-				"nodeNames":     nodeNames,
-				"draSelectors":  draSelectors,
-				"commandRegex":  p.CommandRegex,
-				"priority":      int64(p.Priority),
-				"executionTime": p.ExecutionTime,
-				"creatorID":     p.CreatorID.Hex(),
-				"updaterID":     p.UpdaterID.Hex(),
-				"createdTime":   p.CreatedTime,
-				"updatedTime":   p.UpdatedTime,
+				"nodeSelectors":   nodeSelectors,
+				"nodeNames":       nodeNames,
+				"draSelectors":    draSelectors,
+				"commandRegex":    p.CommandRegex,
+				"priority":        int64(p.Priority),
+				"executionTime":   p.ExecutionTime,
+				"precedence":      int64(p.Precedence),
+				"clusterSelector": stringsToUnstructured(p.ClusterSelector),
+				"creatorID":       p.CreatorID.Hex(),
+				"updaterID":       p.UpdaterID.Hex(),
+				"createdTime":     p.CreatedTime,
+				"updatedTime":     p.UpdatedTime,
 			},
 		},
 	}
@@ -375,9 +377,11 @@ func unstructuredToDomainNodePolicy(obj *unstructured.Unstructured) (*domain.Nod
 			CreatedTime: getInt64(spec, "createdTime"),
 			UpdatedTime: getInt64(spec, "updatedTime"),
 		},
-		CommandRegex:  getStr(spec, "commandRegex"),
-		Priority:      int(getInt64(spec, "priority")),
-		ExecutionTime: getInt64(spec, "executionTime"),
+		CommandRegex:    getStr(spec, "commandRegex"),
+		Priority:        int(getInt64(spec, "priority")),
+		ExecutionTime:   getInt64(spec, "executionTime"),
+		Precedence:      int(getInt64(spec, "precedence")),
+		ClusterSelector: getStrSlice(spec, "clusterSelector"),
 	}
 
 	creatorID, err := parseObjectIDField(spec, "creatorID")
@@ -460,10 +464,12 @@ func domainNodeIntentToUnstructured(intent *domain.NodeSchedulingIntent, namespa
 			},
 			"spec": map[string]interface{}{
 				"policyID":      intent.PolicyID.Hex(),
+				"cluster":       intent.Cluster,
 				"nodeID":        intent.NodeID,
 				"commandRegex":  intent.CommandRegex,
 				"priority":      int64(intent.Priority),
 				"executionTime": intent.ExecutionTime,
+				"precedence":    int64(intent.Precedence),
 				"state":         int64(intent.State),
 				"creatorID":     intent.CreatorID.Hex(),
 				"updaterID":     intent.UpdaterID.Hex(),
@@ -491,11 +497,14 @@ func unstructuredToDomainNodeIntent(obj *unstructured.Unstructured) (*domain.Nod
 			CreatedTime: getInt64(spec, "createdTime"),
 			UpdatedTime: getInt64(spec, "updatedTime"),
 		},
+		Cluster:       getStr(spec, "cluster"),
 		NodeID:        getStr(spec, "nodeID"),
 		CommandRegex:  getStr(spec, "commandRegex"),
 		Priority:      int(getInt64(spec, "priority")),
 		ExecutionTime: getInt64(spec, "executionTime"),
 		State:         domain.IntentState(getInt64(spec, "state")),
+		Precedence:    int(getInt64(spec, "precedence")),
+		Overrides:     unstructuredToIntentOverrides(obj),
 	}
 
 	creatorID, err := parseObjectIDField(spec, "creatorID")
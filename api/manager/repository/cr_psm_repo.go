@@ -150,10 +150,12 @@ func domainPSMToUnstructured(psm *domain.PodSchedulingMetrics, namespace string)
 			"enabled":         psm.Scaling.Enabled,
 			"metricName":      psm.Scaling.MetricName,
 			"targetValue":     psm.Scaling.TargetValue,
-			"minReplicaCount": int64(psm.Scaling.MinReplicaCount),
 			"maxReplicaCount": int64(psm.Scaling.MaxReplicaCount),
 			"cooldownPeriod":  int64(psm.Scaling.CooldownPeriod),
 		}
+		if psm.Scaling.MinReplicaCount != nil {
+			scalingMap["minReplicaCount"] = int64(*psm.Scaling.MinReplicaCount)
+		}
 		if psm.Scaling.ScaleTargetRef != nil {
 			scalingMap["scaleTargetRef"] = map[string]interface{}{
 				"apiVersion": psm.Scaling.ScaleTargetRef.APIVersion,
@@ -256,10 +258,13 @@ func unstructuredToDomainPSM(obj *unstructured.Unstructured) (*domain.PodSchedul
 				Enabled:         getBool(m, "enabled"),
 				MetricName:      getStr(m, "metricName"),
 				TargetValue:     getStr(m, "targetValue"),
-				MinReplicaCount: int32(getInt64(m, "minReplicaCount")),
 				MaxReplicaCount: int32(getInt64(m, "maxReplicaCount")),
 				CooldownPeriod:  int32(getInt64(m, "cooldownPeriod")),
 			}
+			if _, ok := m["minReplicaCount"]; ok {
+				minReplicas := int32(getInt64(m, "minReplicaCount"))
+				psm.Scaling.MinReplicaCount = &minReplicas
+			}
 			if ref, ok := m["scaleTargetRef"]; ok {
 				if refMap, ok := ref.(map[string]interface{}); ok {
 					psm.Scaling.ScaleTargetRef = &domain.PSMScaleTargetRef{
@@ -272,6 +277,24 @@ func unstructuredToDomainPSM(obj *unstructured.Unstructured) (*domain.PodSchedul
 		}
 	}
 
+	// status.scaling is written by UpdatePSMScalingStatus.
+	if scaling, found, _ := unstructured.NestedMap(obj.Object, "status", "scaling"); found {
+		psm.ScalingStatus = &domain.PSMScalingStatus{
+			Ready:   getBool(scaling, "ready"),
+			Message: getStr(scaling, "message"),
+		}
+		if arr, ok := scaling["scaledObjects"].([]interface{}); ok {
+			for _, item := range arr {
+				if s, ok := item.(string); ok {
+					psm.ScalingStatus.ScaledObjects = append(psm.ScalingStatus.ScaledObjects, s)
+				}
+			}
+		}
+		if t, err := time.Parse(time.RFC3339, getStr(scaling, "lastTransitionTime")); err == nil {
+			psm.ScalingStatus.LastTransitionTime = t.UnixMilli()
+		}
+	}
+
 	return psm, nil
 }
 
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var scaledObjectGVR = schema.GroupVersionResource{
+	Group:    "keda.sh",
+	Version:  "v1alpha1",
+	Resource: "scaledobjects",
+}
+
+const (
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	labelPSMID     = "gthulhu.io/psm-id"
+
+	managedByManager = "gthulhu-manager"
+)
+
+// ---------------------------------------------------------------------------
+// KEDA ScaledObjects generated from PodSchedulingMetrics
+// ---------------------------------------------------------------------------
+
+// QueryPSMScaledObjects lists the ScaledObjects the manager generated, in all
+// namespaces.
+func (r *repo) QueryPSMScaledObjects(ctx context.Context, opt *domain.QueryPSMScaledObjectOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	sel := labelManagedBy + "=" + managedByManager
+	if len(opt.PSMIDs) > 0 {
+		sel += "," + labelPSMID + " in (" + strings.Join(opt.PSMIDs, ",") + ")"
+	}
+	list, err := r.k8sDynamic.Resource(scaledObjectGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: sel})
+	if err != nil {
+		return fmt.Errorf("list ScaledObjects: %w", err)
+	}
+	for i := range list.Items {
+		opt.Result = append(opt.Result, unstructuredToDomainScaledObject(&list.Items[i]))
+	}
+	return nil
+}
+
+// ApplyPSMScaledObject creates the ScaledObject or updates the spec of the one
+// the manager generated earlier. ScaledObjects in the PodSchedulingMetrics
+// namespace are owned by the CR so they are garbage collected with it; owner
+// references cannot cross namespaces, so the others are only labelled.
+func (r *repo) ApplyPSMScaledObject(ctx context.Context, so *domain.PSMScaledObject) error {
+	if so == nil {
+		return errors.New("nil scaled object")
+	}
+	desired := domainScaledObjectToUnstructured(so)
+	if so.Namespace == r.crNamespace {
+		psm, err := r.k8sDynamic.Resource(psmGVR).Namespace(r.crNamespace).Get(ctx, so.PSMID, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("get PodSchedulingMetrics CR %s: %w", so.PSMID, err)
+		}
+		if err == nil {
+			controller := true
+			desired.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: "gthulhu.io/v1alpha1",
+				Kind:       "PodSchedulingMetrics",
+				Name:       psm.GetName(),
+				UID:        psm.GetUID(),
+				Controller: &controller,
+			}})
+		}
+	}
+
+	client := r.k8sDynamic.Resource(scaledObjectGVR).Namespace(so.Namespace)
+	existing, err := client.Get(ctx, so.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		if _, err := client.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create ScaledObject %s: %w", so.Key(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get ScaledObject %s: %w", so.Key(), err)
+	}
+	if existing.GetLabels()[labelManagedBy] != managedByManager {
+		return fmt.Errorf("ScaledObject %s already exists and is not managed by %s", so.Key(), managedByManager)
+	}
+
+	// Keep what KEDA and other controllers put on the object, e.g. finalizers.
+	labels := existing.GetLabels()
+	for k, v := range desired.GetLabels() {
+		labels[k] = v
+	}
+	existing.SetLabels(labels)
+	existing.SetOwnerReferences(desired.GetOwnerReferences())
+	existing.Object["spec"] = desired.Object["spec"]
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update ScaledObject %s: %w", so.Key(), err)
+	}
+	return nil
+}
+
+func (r *repo) DeletePSMScaledObject(ctx context.Context, namespace, name string) error {
+	err := r.k8sDynamic.Resource(scaledObjectGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("delete ScaledObject %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// UpdatePSMScalingStatus writes status.scaling of the PodSchedulingMetrics CR
+// and leaves the rest of its status alone.
+func (r *repo) UpdatePSMScalingStatus(ctx context.Context, psmID string, status *domain.PSMScalingStatus) error {
+	if status == nil {
+		return errors.New("nil scaling status")
+	}
+	scaledObjects := make([]interface{}, len(status.ScaledObjects))
+	for i, key := range status.ScaledObjects {
+		scaledObjects[i] = key
+	}
+	scaling := map[string]interface{}{
+		"ready":         status.Ready,
+		"scaledObjects": scaledObjects,
+		"message":       status.Message,
+	}
+	if status.LastTransitionTime > 0 {
+		scaling["lastTransitionTime"] = time.UnixMilli(status.LastTransitionTime).UTC().Format(time.RFC3339)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"scaling": scaling},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal scaling status patch: %w", err)
+	}
+	_, err = r.k8sDynamic.Resource(psmGVR).Namespace(r.crNamespace).Patch(ctx, psmID, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("patch PodSchedulingMetrics CR %s status: %w", psmID, err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Conversion helpers
+// ---------------------------------------------------------------------------
+
+func domainScaledObjectToUnstructured(so *domain.PSMScaledObject) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "keda.sh/v1alpha1",
+			"kind":       "ScaledObject",
+			"metadata": map[string]interface{}{
+				"name":      so.Name,
+				"namespace": so.Namespace,
+				"labels": map[string]interface{}{
+					labelManagedBy: managedByManager,
+					labelPSMID:     so.PSMID,
+				},
+			},
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"apiVersion": so.ScaleTargetRef.APIVersion,
+					"kind":       so.ScaleTargetRef.Kind,
+					"name":       so.ScaleTargetRef.Name,
+				},
+				"pollingInterval": int64(so.PollingInterval),
+				"cooldownPeriod":  int64(so.CooldownPeriod),
+				"minReplicaCount": int64(so.MinReplicaCount),
+				"maxReplicaCount": int64(so.MaxReplicaCount),
+				"triggers": []interface{}{
+					map[string]interface{}{
+						"type": "prometheus",
+						"metadata": map[string]interface{}{
+							"serverAddress": so.Trigger.ServerAddress,
+							"query":         so.Trigger.Query,
+							"threshold":     so.Trigger.Threshold,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func unstructuredToDomainScaledObject(obj *unstructured.Unstructured) *domain.PSMScaledObject {
+	so := &domain.PSMScaledObject{
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		PSMID:     obj.GetLabels()[labelPSMID],
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if spec == nil {
+		return so
+	}
+	so.PollingInterval = int32(getInt64(spec, "pollingInterval"))
+	so.CooldownPeriod = int32(getInt64(spec, "cooldownPeriod"))
+	so.MinReplicaCount = int32(getInt64(spec, "minReplicaCount"))
+	so.MaxReplicaCount = int32(getInt64(spec, "maxReplicaCount"))
+	if ref, ok := spec["scaleTargetRef"].(map[string]interface{}); ok {
+		so.ScaleTargetRef = domain.PSMScaleTargetRef{
+			APIVersion: getStr(ref, "apiVersion"),
+			Kind:       getStr(ref, "kind"),
+			Name:       getStr(ref, "name"),
+		}
+	}
+	triggers, _ := spec["triggers"].([]interface{})
+	for _, raw := range triggers {
+		trigger, ok := raw.(map[string]interface{})
+		if !ok || getStr(trigger, "type") != "prometheus" {
+			continue
+		}
+		if metadata, ok := trigger["metadata"].(map[string]interface{}); ok {
+			so.Trigger = domain.PSMPrometheusTrigger{
+				ServerAddress: getStr(metadata, "serverAddress"),
+				Query:         getStr(metadata, "query"),
+				Threshold:     getStr(metadata, "threshold"),
+			}
+		}
+		break
+	}
+	return so
+}
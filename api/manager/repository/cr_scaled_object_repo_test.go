@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestScaledObjectRepo() *repo {
+	scheme := runtime.NewScheme()
+	fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{
+			psmGVR:          "PodSchedulingMetricsList",
+			scaledObjectGVR: "ScaledObjectList",
+		},
+	)
+	return &repo{
+		k8sDynamic:  fakeClient,
+		crNamespace: "test-ns",
+	}
+}
+
+func testScaledObject(namespace, psmID string) *domain.PSMScaledObject {
+	return &domain.PSMScaledObject{
+		Namespace:       namespace,
+		Name:            "gthulhu-psm-" + psmID,
+		PSMID:           psmID,
+		ScaleTargetRef:  domain.PSMScaleTargetRef{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		PollingInterval: 30,
+		CooldownPeriod:  300,
+		MinReplicaCount: 1,
+		MaxReplicaCount: 10,
+		Trigger: domain.PSMPrometheusTrigger{
+			ServerAddress: "http://prometheus:9090",
+			Query:         "sum(rate(gthulhu_pod_run_count_total[2m]))",
+			Threshold:     "100",
+		},
+	}
+}
+
+func TestCRApplyAndQueryPSMScaledObjects(t *testing.T) {
+	r := newTestScaledObjectRepo()
+	ctx := context.Background()
+
+	psm := &domain.PodSchedulingMetrics{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}}
+	require.NoError(t, r.CreatePSM(ctx, psm))
+	psmID := psm.ID.Hex()
+
+	local := testScaledObject("test-ns", psmID)
+	remote := testScaledObject("team-a", psmID)
+	require.NoError(t, r.ApplyPSMScaledObject(ctx, local))
+	require.NoError(t, r.ApplyPSMScaledObject(ctx, remote))
+
+	obj, err := r.k8sDynamic.Resource(scaledObjectGVR).Namespace("test-ns").Get(ctx, local.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, obj.GetOwnerReferences(), 1, "objects next to the CR are owned by it")
+	assert.Equal(t, psmID, obj.GetOwnerReferences()[0].Name)
+	obj, err = r.k8sDynamic.Resource(scaledObjectGVR).Namespace("team-a").Get(ctx, remote.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, obj.GetOwnerReferences(), "owner references cannot cross namespaces")
+
+	// Updates keep metadata other controllers added.
+	obj.SetFinalizers([]string{"finalizer.keda.sh"})
+	_, err = r.k8sDynamic.Resource(scaledObjectGVR).Namespace("team-a").Update(ctx, obj, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	remote.MaxReplicaCount = 20
+	require.NoError(t, r.ApplyPSMScaledObject(ctx, remote))
+	obj, err = r.k8sDynamic.Resource(scaledObjectGVR).Namespace("team-a").Get(ctx, remote.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finalizer.keda.sh"}, obj.GetFinalizers())
+
+	opt := &domain.QueryPSMScaledObjectOptions{PSMIDs: []string{psmID}}
+	require.NoError(t, r.QueryPSMScaledObjects(ctx, opt))
+	require.Len(t, opt.Result, 2)
+	for _, so := range opt.Result {
+		if so.Namespace == "team-a" {
+			assert.Equal(t, remote, so)
+		} else {
+			assert.Equal(t, local, so)
+		}
+	}
+
+	require.NoError(t, r.DeletePSMScaledObject(ctx, "team-a", remote.Name))
+	require.NoError(t, r.DeletePSMScaledObject(ctx, "team-a", remote.Name), "deleting twice is fine")
+	opt = &domain.QueryPSMScaledObjectOptions{}
+	require.NoError(t, r.QueryPSMScaledObjects(ctx, opt))
+	assert.Len(t, opt.Result, 1)
+}
+
+func TestCRApplyPSMScaledObjectLeavesForeignObjects(t *testing.T) {
+	r := newTestScaledObjectRepo()
+	ctx := context.Background()
+
+	so := testScaledObject("team-a", bson.NewObjectID().Hex())
+	foreign := &unstructured.Unstructured{}
+	foreign.SetAPIVersion("keda.sh/v1alpha1")
+	foreign.SetKind("ScaledObject")
+	foreign.SetNamespace(so.Namespace)
+	foreign.SetName(so.Name)
+	_, err := r.k8sDynamic.Resource(scaledObjectGVR).Namespace(so.Namespace).Create(ctx, foreign, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Error(t, r.ApplyPSMScaledObject(ctx, so))
+	opt := &domain.QueryPSMScaledObjectOptions{}
+	require.NoError(t, r.QueryPSMScaledObjects(ctx, opt))
+	assert.Empty(t, opt.Result, "hand-written ScaledObjects are not listed as managed")
+}
+
+func TestCRUpdatePSMScalingStatus(t *testing.T) {
+	r := newTestScaledObjectRepo()
+	ctx := context.Background()
+
+	psm := &domain.PodSchedulingMetrics{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, Enabled: true}
+	require.NoError(t, r.CreatePSM(ctx, psm))
+
+	status := &domain.PSMScalingStatus{
+		Ready:              false,
+		ScaledObjects:      []string{"team-a/gthulhu-psm-" + psm.ID.Hex()},
+		Message:            "update ScaledObject team-b/x: forbidden",
+		LastTransitionTime: 1700000000000,
+	}
+	require.NoError(t, r.UpdatePSMScalingStatus(ctx, psm.ID.Hex(), status))
+
+	opt := &domain.QueryPSMOptions{IDs: []interface{}{psm.ID.Hex()}}
+	require.NoError(t, r.QueryPSMs(ctx, opt))
+	require.Len(t, opt.Result, 1)
+	assert.Equal(t, status, opt.Result[0].ScalingStatus)
+	assert.True(t, opt.Result[0].Enabled, "the spec is left alone")
+}
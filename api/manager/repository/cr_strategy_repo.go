@@ -329,6 +329,23 @@ func domainStrategyToUnstructured(s *domain.ScheduleStrategy, namespace string)
 	for i, ns := range s.K8sNamespace {
 		k8sNS[i] = ns
 	}
+	spec := map[string]interface{}{
+		"strategyNamespace": s.StrategyNamespace,
+		"labelSelectors":    labelSelectors,
+		"k8sNamespaces":     k8sNS,
+		"commandRegex":      s.CommandRegex,
+		"priority":          int64(s.Priority),
+		"executionTime":     s.ExecutionTime,
+		"precedence":        int64(s.Precedence),
+		"clusterSelector":   stringsToUnstructured(s.ClusterSelector),
+		"creatorID":         s.CreatorID.Hex(),
+		"updaterID":         s.UpdaterID.Hex(),
+		"createdTime":       s.CreatedTime,
+		"updatedTime":       s.UpdatedTime,
+	}
+	if s.Schedule != nil {
+		spec["schedule"] = strategyScheduleToUnstructured(s.Schedule)
+	}
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "gthulhu.io/v1alpha1",
@@ -340,22 +357,67 @@ func domainStrategyToUnstructured(s *domain.ScheduleStrategy, namespace string)
 					labelCreatorID: s.CreatorID.Hex(),
 				},
 			},
-			"spec": map[string]interface{}{
-				"strategyNamespace": s.StrategyNamespace,
-				"labelSelectors":    labelSelectors,
-				"k8sNamespaces":     k8sNS,
-				"commandRegex":      s.CommandRegex,
-				"priority":          int64(s.Priority),
-				"executionTime":     s.ExecutionTime,
-				"creatorID":         s.CreatorID.Hex(),
-				"updaterID":         s.UpdaterID.Hex(),
-				"createdTime":       s.CreatedTime,
-				"updatedTime":       s.UpdatedTime,
-			},
+			"spec": spec,
 		},
 	}
 }
 
+func strategyScheduleToUnstructured(schedule *domain.StrategySchedule) map[string]interface{} {
+	windows := make([]interface{}, len(schedule.Windows))
+	for i, w := range schedule.Windows {
+		days := make([]interface{}, len(w.Days))
+		for j, day := range w.Days {
+			days[j] = day
+		}
+		windows[i] = map[string]interface{}{
+			"cron":            w.Cron,
+			"durationSeconds": w.DurationSeconds,
+			"start":           w.Start,
+			"end":             w.End,
+			"days":            days,
+		}
+	}
+	return map[string]interface{}{
+		"timezone":  schedule.Timezone,
+		"windows":   windows,
+		"expiresAt": schedule.ExpiresAt,
+	}
+}
+
+func unstructuredToStrategySchedule(spec map[string]interface{}) *domain.StrategySchedule {
+	m, ok := spec["schedule"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	schedule := &domain.StrategySchedule{
+		Timezone:  getStr(m, "timezone"),
+		ExpiresAt: getInt64(m, "expiresAt"),
+	}
+	if arr, ok := m["windows"].([]interface{}); ok {
+		for _, item := range arr {
+			wm, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			window := domain.ActivationWindow{
+				Cron:            getStr(wm, "cron"),
+				DurationSeconds: getInt64(wm, "durationSeconds"),
+				Start:           getStr(wm, "start"),
+				End:             getStr(wm, "end"),
+			}
+			if days, ok := wm["days"].([]interface{}); ok {
+				for _, day := range days {
+					if d, ok := day.(string); ok {
+						window.Days = append(window.Days, d)
+					}
+				}
+			}
+			schedule.Windows = append(schedule.Windows, window)
+		}
+	}
+	return schedule
+}
+
 func unstructuredToDomainStrategy(obj *unstructured.Unstructured) (*domain.ScheduleStrategy, error) {
 	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
 	if err != nil || !found {
@@ -377,6 +439,9 @@ func unstructuredToDomainStrategy(obj *unstructured.Unstructured) (*domain.Sched
 		CommandRegex:      getStr(spec, "commandRegex"),
 		Priority:          int(getInt64(spec, "priority")),
 		ExecutionTime:     getInt64(spec, "executionTime"),
+		Precedence:        int(getInt64(spec, "precedence")),
+		Schedule:          unstructuredToStrategySchedule(spec),
+		ClusterSelector:   getStrSlice(spec, "clusterSelector"),
 	}
 
 	creatorID, err := parseObjectIDField(spec, "creatorID")
@@ -437,6 +502,7 @@ func domainIntentToUnstructured(intent *domain.ScheduleIntent, namespace string)
 			},
 			"spec": map[string]interface{}{
 				"strategyID":    intent.StrategyID.Hex(),
+				"cluster":       intent.Cluster,
 				"podID":         intent.PodID,
 				"podName":       intent.PodName,
 				"nodeID":        intent.NodeID,
@@ -444,6 +510,8 @@ func domainIntentToUnstructured(intent *domain.ScheduleIntent, namespace string)
 				"commandRegex":  intent.CommandRegex,
 				"priority":      int64(intent.Priority),
 				"executionTime": intent.ExecutionTime,
+				"precedence":    int64(intent.Precedence),
+				"specificity":   int64(intent.Specificity),
 				"podLabels":     podLabels,
 				"state":         int64(intent.State),
 				"creatorID":     intent.CreatorID.Hex(),
@@ -472,6 +540,7 @@ func unstructuredToDomainIntent(obj *unstructured.Unstructured) (*domain.Schedul
 			CreatedTime: getInt64(spec, "createdTime"),
 			UpdatedTime: getInt64(spec, "updatedTime"),
 		},
+		Cluster:       getStr(spec, "cluster"),
 		PodID:         getStr(spec, "podID"),
 		PodName:       getStr(spec, "podName"),
 		NodeID:        getStr(spec, "nodeID"),
@@ -480,7 +549,11 @@ func unstructuredToDomainIntent(obj *unstructured.Unstructured) (*domain.Schedul
 		Priority:      int(getInt64(spec, "priority")),
 		ExecutionTime: getInt64(spec, "executionTime"),
 		State:         domain.IntentState(getInt64(spec, "state")),
+		Precedence:    int(getInt64(spec, "precedence")),
+		Specificity:   int(getInt64(spec, "specificity")),
+		Overrides:     unstructuredToIntentOverrides(obj),
 	}
+	unstructuredToIntentApplyStatus(obj, intent)
 
 	creatorID, err := parseObjectIDField(spec, "creatorID")
 	if err != nil {
@@ -545,6 +618,9 @@ func matchesIntentFilter(intent *domain.ScheduleIntent, opt *domain.QueryIntentO
 	if len(opt.PodIDs) > 0 && !containsStr(opt.PodIDs, intent.PodID) {
 		return false
 	}
+	if len(opt.NodeIDs) > 0 && !containsStr(opt.NodeIDs, intent.NodeID) {
+		return false
+	}
 	return true
 }
 
@@ -644,6 +720,25 @@ func getStr(m map[string]interface{}, key string) string {
 	return v
 }
 
+func getStrSlice(m map[string]interface{}, key string) []string {
+	arr, _ := m[key].([]interface{})
+	var result []string
+	for _, item := range arr {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func stringsToUnstructured(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
 func getInt64(m map[string]interface{}, key string) int64 {
 	switch v := m[key].(type) {
 	case int64:
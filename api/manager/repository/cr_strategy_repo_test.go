@@ -213,6 +213,38 @@ func TestCRUpdateStrategy(t *testing.T) {
 	assert.Equal(t, 99, opt.Result[0].Priority)
 }
 
+func TestCRStrategyScheduleRoundTrip(t *testing.T) {
+	r := newTestCRRepo()
+	ctx := context.Background()
+
+	creatorID := bson.NewObjectID()
+	schedule := &domain.StrategySchedule{
+		Timezone: "Asia/Taipei",
+		Windows: []domain.ActivationWindow{
+			{Cron: "0 22 * * *", DurationSeconds: 28800},
+			{Start: "09:00", End: "18:00", Days: []string{"mon", "fri"}},
+		},
+		ExpiresAt: 1767225600000,
+	}
+	strategy := &domain.ScheduleStrategy{
+		BaseEntity: domain.BaseEntity{CreatorID: creatorID, UpdaterID: creatorID},
+		Schedule:   schedule,
+	}
+	require.NoError(t, r.InsertStrategyAndIntents(ctx, strategy, []*domain.ScheduleIntent{}))
+
+	opt := &domain.QueryStrategyOptions{IDs: []bson.ObjectID{strategy.ID}}
+	require.NoError(t, r.QueryStrategies(ctx, opt))
+	require.Len(t, opt.Result, 1)
+	assert.Equal(t, schedule, opt.Result[0].Schedule)
+
+	strategy.Schedule = nil
+	require.NoError(t, r.UpdateStrategy(ctx, strategy))
+	opt = &domain.QueryStrategyOptions{IDs: []bson.ObjectID{strategy.ID}}
+	require.NoError(t, r.QueryStrategies(ctx, opt))
+	require.Len(t, opt.Result, 1)
+	assert.Nil(t, opt.Result[0].Schedule)
+}
+
 func TestCRInsertAndDeleteIntents(t *testing.T) {
 	r := newTestCRRepo()
 	ctx := context.Background()
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	decisionMakerRegistrationCollection = "decision_maker_registrations"
+	decisionMakerPullCollection         = "decision_maker_pulls"
+)
+
+// UpsertDecisionMakerRegistration stores a registration or heartbeat keyed by
+// node. The stored RegisteredAt is kept unless the node is new or moved to
+// another address, which is reported as changed.
+func (r *repo) UpsertDecisionMakerRegistration(ctx context.Context, reg *domain.DecisionMakerRegistration) (bool, error) {
+	if reg == nil {
+		return false, errors.New("nil decision maker registration")
+	}
+	moved := bson.M{"$or": bson.A{
+		bson.M{"$ne": bson.A{"$host", reg.Host}},
+		bson.M{"$ne": bson.A{"$port", reg.Port}},
+	}}
+	// The first stage still sees the stored address. $literal keeps
+	// reported strings from being read as field paths.
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"registeredAt": bson.M{"$cond": bson.A{moved, reg.RegisteredAt, "$registeredAt"}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"host":          bson.M{"$literal": reg.Host},
+			"port":          reg.Port,
+			"version":       bson.M{"$literal": reg.Version},
+			"capabilities":  bson.M{"$literal": reg.Capabilities},
+			"lastHeartbeat": reg.LastHeartbeat,
+			"expireAt":      reg.ExpireAt,
+		}}},
+	}
+	var prev domain.DecisionMakerRegistration
+	err := r.db.Collection(decisionMakerRegistrationCollection).FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": reg.NodeID},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&prev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("upsert decision maker registration, err: %w", err)
+	}
+	return prev.Host != reg.Host || prev.Port != reg.Port, nil
+}
+
+// DeleteDecisionMakerRegistration removes the registration of a node and
+// reports whether there was one.
+func (r *repo) DeleteDecisionMakerRegistration(ctx context.Context, nodeID string) (bool, error) {
+	res, err := r.db.Collection(decisionMakerRegistrationCollection).DeleteOne(ctx, bson.M{"_id": nodeID})
+	if err != nil {
+		return false, fmt.Errorf("delete decision maker registration, err: %w", err)
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *repo) QueryDecisionMakerRegistrations(ctx context.Context, opt *domain.QueryDecisionMakerRegistrationOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if !opt.HeartbeatAfter.IsZero() {
+		filter["lastHeartbeat"] = bson.M{"$gt": opt.HeartbeatAfter}
+	}
+	cursor, err := r.db.Collection(decisionMakerRegistrationCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find decision maker registrations, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.DecisionMakerRegistration
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode decision maker registrations, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
+
+// MarkDecisionMakerPull records a pull of the node's decision maker. Pulls
+// recorded by other replicas in the meantime are never moved back.
+func (r *repo) MarkDecisionMakerPull(ctx context.Context, pull *domain.DecisionMakerPull) error {
+	if pull == nil {
+		return errors.New("nil decision maker pull")
+	}
+	_, err := r.db.Collection(decisionMakerPullCollection).UpdateOne(
+		ctx,
+		bson.M{"_id": pull.NodeID},
+		bson.M{"$max": bson.M{"pulledAt": pull.PulledAt, "expireAt": pull.ExpireAt}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("mark decision maker pull, err: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) QueryDecisionMakerPulls(ctx context.Context, opt *domain.QueryDecisionMakerPullOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if !opt.PulledAfter.IsZero() {
+		filter["pulledAt"] = bson.M{"$gt": opt.PulledAfter}
+	}
+	cursor, err := r.db.Collection(decisionMakerPullCollection).Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("find decision maker pulls, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.DecisionMakerPull
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode decision maker pulls, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const podSchedulingMetricHistoryCollection = "pod_scheduling_metric_history"
+
+// UpsertPodSchedulingMetricBuckets stores the buckets, overwriting the counters
+// of buckets already observed earlier in the same step.
+func (r *repo) UpsertPodSchedulingMetricBuckets(ctx context.Context, buckets []*domain.PodSchedulingMetricBucket) error {
+	if len(buckets) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(buckets))
+	for _, bucket := range buckets {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"namespace":  bucket.Namespace,
+				"podName":    bucket.PodName,
+				"nodeId":     bucket.NodeID,
+				"stepMillis": bucket.StepMillis,
+				"timestamp":  bucket.Timestamp,
+			}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"observedAt": bucket.ObservedAt,
+				"counters":   bucket.Counters,
+				"expireAt":   bucket.ExpireAt,
+			}}).
+			SetUpsert(true))
+	}
+	_, err := r.db.Collection(podSchedulingMetricHistoryCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("upsert pod scheduling metric buckets, err: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) QueryPodSchedulingMetricBuckets(ctx context.Context, opt *domain.QueryPodSchedulingMetricBucketOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{
+		"namespace":  opt.Namespace,
+		"podName":    opt.PodName,
+		"stepMillis": opt.StepMillis,
+	}
+	timestamp := bson.M{}
+	if opt.From > 0 {
+		timestamp["$gte"] = opt.From
+	}
+	if opt.To > 0 {
+		timestamp["$lte"] = opt.To
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+	cursor, err := r.db.Collection(podSchedulingMetricHistoryCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find pod scheduling metric buckets, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.PodSchedulingMetricBucket
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode pod scheduling metric buckets, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Gthulhu/api/manager/domain"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func (r *repo) CreateUser(ctx context.Context, user *domain.User) error {
@@ -256,16 +257,38 @@ func (r *repo) QueryAuditLogs(ctx context.Context, opt *domain.QueryAuditLogOpti
 		}
 		filter[defaultTimestampField] = timeFilter
 	}
+	if len(opt.Resources) > 0 {
+		filter["resource"] = bson.M{"$in": opt.Resources}
+	}
+	if len(opt.ResourceIDs) > 0 {
+		filter["resource_id"] = bson.M{"$in": opt.ResourceIDs}
+	}
+	if len(opt.Actions) > 0 {
+		filter["action"] = bson.M{"$in": opt.Actions}
+	}
 
-	cursor, err := r.db.Collection(auditLogCollection).Find(ctx, filter)
+	total, err := r.db.Collection(auditLogCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("count audit logs, err: %w", err)
+	}
+	findOpts := options.Find().SetSort(bson.D{{Key: defaultTimestampField, Value: -1}, {Key: "_id", Value: -1}})
+	if opt.Skip > 0 {
+		findOpts.SetSkip(opt.Skip)
+	}
+	if opt.Limit > 0 {
+		findOpts.SetLimit(opt.Limit)
+	}
+	cursor, err := r.db.Collection(auditLogCollection).Find(ctx, filter, findOpts)
 	if err != nil {
 		return fmt.Errorf("find audit logs, err: %w", err)
 	}
+	defer cursor.Close(ctx)
 
 	var result []*domain.AuditLog
 	if err := cursor.All(ctx, &result); err != nil {
 		return fmt.Errorf("decode audit logs, err: %w", err)
 	}
 	opt.Result = result
+	opt.Total = total
 	return nil
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/manager/domain"
@@ -147,3 +148,29 @@ func (suite *RepositoryTestSuite) TestCreateRoleAndPermission() {
 	suite.Len(permOpts.Result, 1, "expect one permission")
 	suite.Equal(perm.Description, permOpts.Result[0].Description, "permission description should match")
 }
+
+func (suite *RepositoryTestSuite) TestUpsertDecisionMakerRegistration() {
+	start := time.Now().UTC().Truncate(time.Millisecond)
+	reg := &domain.DecisionMakerRegistration{NodeID: "node-a", Host: "10.0.0.1", Port: 8080, RegisteredAt: start, LastHeartbeat: start}
+	changed, err := suite.repo.UpsertDecisionMakerRegistration(suite.ctx, reg)
+	suite.Require().NoError(err, "register")
+	suite.True(changed, "a new node is a change")
+
+	heartbeat := *reg
+	heartbeat.RegisteredAt = start.Add(time.Minute)
+	heartbeat.LastHeartbeat = start.Add(time.Minute)
+	changed, err = suite.repo.UpsertDecisionMakerRegistration(suite.ctx, &heartbeat)
+	suite.Require().NoError(err, "heartbeat")
+	suite.False(changed, "a heartbeat from the same address is no change")
+
+	opts := &domain.QueryDecisionMakerRegistrationOptions{HeartbeatAfter: start}
+	suite.Require().NoError(suite.repo.QueryDecisionMakerRegistrations(suite.ctx, opts), "query registrations")
+	suite.Require().Len(opts.Result, 1, "expect one registration")
+	suite.True(start.Equal(opts.Result[0].RegisteredAt), "registeredAt should be kept")
+
+	moved := heartbeat
+	moved.Port = 9090
+	changed, err = suite.repo.UpsertDecisionMakerRegistration(suite.ctx, &moved)
+	suite.Require().NoError(err, "move")
+	suite.True(changed, "a new address is a change")
+}
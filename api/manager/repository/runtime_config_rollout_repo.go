@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const runtimeConfigRolloutCollection = "runtime_config_rollouts"
+
+func (r *repo) CreateRuntimeConfigRollout(ctx context.Context, rollout *domain.RuntimeConfigRollout) error {
+	if rollout == nil {
+		return errors.New("nil runtime config rollout")
+	}
+	if rollout.ID.IsZero() {
+		rollout.ID = bson.NewObjectID()
+	}
+	now := time.Now().UnixMilli()
+	rollout.CreatedAt = now
+	rollout.UpdatedAt = now
+	rollout.Revision = 1
+	_, err := r.db.Collection(runtimeConfigRolloutCollection).InsertOne(ctx, rollout)
+	if err != nil {
+		return fmt.Errorf("insert runtime config rollout, err: %w", err)
+	}
+	return nil
+}
+
+// UpdateRuntimeConfigRollout replaces the rollout only if nobody else wrote
+// it since it was read, returning domain.ErrConflict otherwise.
+func (r *repo) UpdateRuntimeConfigRollout(ctx context.Context, rollout *domain.RuntimeConfigRollout) error {
+	if rollout == nil {
+		return errors.New("nil runtime config rollout")
+	}
+	if rollout.ID.IsZero() {
+		return errors.New("runtime config rollout id is required")
+	}
+	expectedRevision := rollout.Revision
+	rollout.Revision++
+	rollout.UpdatedAt = time.Now().UnixMilli()
+	res, err := r.db.Collection(runtimeConfigRolloutCollection).ReplaceOne(
+		ctx,
+		bson.M{"_id": rollout.ID, "revision": expectedRevision},
+		rollout,
+	)
+	if err != nil {
+		rollout.Revision = expectedRevision
+		return fmt.Errorf("update runtime config rollout, err: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		rollout.Revision = expectedRevision
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (r *repo) QueryRuntimeConfigRollouts(ctx context.Context, opt *domain.QueryRuntimeConfigRolloutOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if len(opt.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": opt.IDs}
+	}
+	if len(opt.States) > 0 {
+		filter["state"] = bson.M{"$in": opt.States}
+	}
+	cursor, err := r.db.Collection(runtimeConfigRolloutCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find runtime config rollouts, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.RuntimeConfigRollout
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode runtime config rollouts, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
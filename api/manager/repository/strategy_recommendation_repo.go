@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	strategyRecommendationCollection        = "strategy_recommendations"
+	recommendationAutoApplyPolicyCollection = "recommendation_auto_apply_policies"
+)
+
+func (r *repo) CreateStrategyRecommendation(ctx context.Context, rec *domain.StrategyRecommendation) error {
+	if rec == nil {
+		return errors.New("nil strategy recommendation")
+	}
+	if rec.ID.IsZero() {
+		rec.ID = bson.NewObjectID()
+	}
+	now := time.Now().UnixMilli()
+	rec.CreatedAt = now
+	rec.UpdatedAt = now
+	rec.Revision = 1
+	_, err := r.db.Collection(strategyRecommendationCollection).InsertOne(ctx, rec)
+	if err != nil {
+		return fmt.Errorf("insert strategy recommendation, err: %w", err)
+	}
+	return nil
+}
+
+// UpdateStrategyRecommendation replaces the recommendation only if nobody
+// else wrote it since it was read, returning domain.ErrConflict otherwise.
+func (r *repo) UpdateStrategyRecommendation(ctx context.Context, rec *domain.StrategyRecommendation) error {
+	if rec == nil {
+		return errors.New("nil strategy recommendation")
+	}
+	if rec.ID.IsZero() {
+		return errors.New("strategy recommendation id is required")
+	}
+	expectedRevision := rec.Revision
+	rec.Revision++
+	rec.UpdatedAt = time.Now().UnixMilli()
+	res, err := r.db.Collection(strategyRecommendationCollection).ReplaceOne(
+		ctx,
+		bson.M{"_id": rec.ID, "revision": expectedRevision},
+		rec,
+	)
+	if err != nil {
+		rec.Revision = expectedRevision
+		return fmt.Errorf("update strategy recommendation, err: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		rec.Revision = expectedRevision
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (r *repo) QueryStrategyRecommendations(ctx context.Context, opt *domain.QueryStrategyRecommendationOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if len(opt.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": opt.IDs}
+	}
+	if len(opt.Namespaces) > 0 {
+		filter["namespace"] = bson.M{"$in": opt.Namespaces}
+	}
+	if len(opt.Pods) > 0 {
+		filter["pod"] = bson.M{"$in": opt.Pods}
+	}
+	if len(opt.States) > 0 {
+		filter["state"] = bson.M{"$in": opt.States}
+	}
+	if opt.UpdatedAfter > 0 {
+		filter["updatedAt"] = bson.M{"$gte": opt.UpdatedAfter}
+	}
+	cursor, err := r.db.Collection(strategyRecommendationCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find strategy recommendations, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.StrategyRecommendation
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode strategy recommendations, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
+
+// UpsertRecommendationAutoApplyPolicy stores the policy of its namespace,
+// replacing any previous one.
+func (r *repo) UpsertRecommendationAutoApplyPolicy(ctx context.Context, policy *domain.RecommendationAutoApplyPolicy) error {
+	if policy == nil {
+		return errors.New("nil recommendation auto-apply policy")
+	}
+	policy.UpdatedAt = time.Now().UnixMilli()
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":                 policy.Enabled,
+			"maxPriority":             policy.MaxPriority,
+			"cooldownSeconds":         policy.CooldownSeconds,
+			"revertAfterDriftSeconds": policy.RevertAfterDriftSeconds,
+			"ownerId":                 policy.OwnerID,
+			"updatedAt":               policy.UpdatedAt,
+		},
+	}
+	res := r.db.Collection(recommendationAutoApplyPolicyCollection).FindOneAndUpdate(
+		ctx,
+		bson.M{"namespace": policy.Namespace},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+	if err := res.Decode(policy); err != nil {
+		return fmt.Errorf("upsert recommendation auto-apply policy, err: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) QueryRecommendationAutoApplyPolicies(ctx context.Context, opt *domain.QueryRecommendationAutoApplyPolicyOptions) error {
+	if opt == nil {
+		return errors.New("nil query options")
+	}
+	filter := bson.M{}
+	if len(opt.Namespaces) > 0 {
+		filter["namespace"] = bson.M{"$in": opt.Namespaces}
+	}
+	cursor, err := r.db.Collection(recommendationAutoApplyPolicyCollection).Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "namespace", Value: 1}}),
+	)
+	if err != nil {
+		return fmt.Errorf("find recommendation auto-apply policies, err: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var result []*domain.RecommendationAutoApplyPolicy
+	if err := cursor.All(ctx, &result); err != nil {
+		return fmt.Errorf("decode recommendation auto-apply policies, err: %w", err)
+	}
+	opt.Result = append(opt.Result, result...)
+	return nil
+}
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	defaultAuditLogPageSize = 50
+	maxAuditLogPageSize     = 500
+)
+
+type AuditLog struct {
+	ID         string               `json:"id"`
+	UserID     string               `json:"userId,omitempty"`
+	Action     string               `json:"action"`
+	Resource   string               `json:"resource,omitempty"`
+	ResourceID string               `json:"resourceId,omitempty"`
+	RequestID  string               `json:"requestId,omitempty"`
+	IP         string               `json:"ip,omitempty"`
+	Timestamp  int64                `json:"timestamp"`
+	Before     map[string]any       `json:"before,omitempty"`
+	After      map[string]any       `json:"after,omitempty"`
+	Changes    []domain.AuditChange `json:"changes,omitempty"`
+}
+
+type ListAuditLogsResponse struct {
+	AuditLogs []*AuditLog `json:"auditLogs"`
+	Total     int64       `json:"total"`
+	Page      int64       `json:"page"`
+	PageSize  int64       `json:"pageSize"`
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description List the audit trail of mutating operations, newest first.
+// @Tags AuditLogs
+// @Produce json
+// @Security BearerAuth
+// @Param from query int false "Only entries at or after this Unix time in milliseconds"
+// @Param to query int false "Only entries at or before this Unix time in milliseconds"
+// @Param userIds query string false "Comma-separated actor user IDs"
+// @Param resources query string false "Comma-separated resource kinds, e.g. schedule_strategy"
+// @Param resourceIds query string false "Comma-separated resource IDs"
+// @Param actions query string false "Comma-separated actions, e.g. schedule_strategy.update"
+// @Param page query int false "Page number, starting at 1"
+// @Param pageSize query int false "Entries per page (default 50, max 500)"
+// @Success 200 {object} SuccessResponse[ListAuditLogsResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/audit-logs [get]
+func (h *Handler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	queryOpt := &domain.QueryAuditLogOptions{
+		Resources:   splitQueryList(query.Get("resources")),
+		ResourceIDs: splitQueryList(query.Get("resourceIds")),
+		Actions:     splitQueryList(query.Get("actions")),
+	}
+	var err error
+	if queryOpt.TimestampGTE, err = parseQueryInt(query.Get("from"), 0); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid from", err)
+		return
+	}
+	if queryOpt.TimestampLTE, err = parseQueryInt(query.Get("to"), 0); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid to", err)
+		return
+	}
+	for _, userID := range splitQueryList(query.Get("userIds")) {
+		uid, err := bson.ObjectIDFromHex(userID)
+		if err != nil {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid user ID "+userID, err)
+			return
+		}
+		queryOpt.UserIDs = append(queryOpt.UserIDs, uid)
+	}
+	page, err := parseQueryInt(query.Get("page"), 1)
+	if err != nil || page < 1 {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid page", err)
+		return
+	}
+	pageSize, err := parseQueryInt(query.Get("pageSize"), defaultAuditLogPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxAuditLogPageSize {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, fmt.Sprintf("pageSize must be between 1 and %d", maxAuditLogPageSize), err)
+		return
+	}
+	queryOpt.Skip = (page - 1) * pageSize
+	queryOpt.Limit = pageSize
+
+	svc, ok := h.Svc.(interface {
+		ListAuditLogs(ctx context.Context, opt *domain.QueryAuditLogOptions) error
+	})
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Audit log is not enabled", nil)
+		return
+	}
+	if err := svc.ListAuditLogs(ctx, queryOpt); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	resp := &ListAuditLogsResponse{
+		AuditLogs: make([]*AuditLog, 0, len(queryOpt.Result)),
+		Total:     queryOpt.Total,
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	for _, log := range queryOpt.Result {
+		resp.AuditLogs = append(resp.AuditLogs, convertDomainAuditLogToResponse(log))
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+func convertDomainAuditLogToResponse(log *domain.AuditLog) *AuditLog {
+	resp := &AuditLog{
+		ID:         log.ID.Hex(),
+		Action:     log.Action,
+		Resource:   log.Resource,
+		ResourceID: log.ResourceID,
+		RequestID:  log.RequestID,
+		IP:         log.IP,
+		Timestamp:  log.Timestamp,
+		Before:     log.Before,
+		After:      log.After,
+		Changes:    log.Changes,
+	}
+	if !log.UserID.IsZero() {
+		resp.UserID = log.UserID.Hex()
+	}
+	return resp
+}
+
+func splitQueryList(param string) []string {
+	var values []string
+	for _, value := range strings.Split(param, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseQueryInt(param string, defaultValue int64) (int64, error) {
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return defaultValue, nil
+	}
+	return strconv.ParseInt(param, 10, 64)
+}
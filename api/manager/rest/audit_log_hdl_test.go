@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeAuditLogService struct {
+	domain.Service
+	opt *domain.QueryAuditLogOptions
+}
+
+func (s *fakeAuditLogService) ListAuditLogs(_ context.Context, opt *domain.QueryAuditLogOptions) error {
+	s.opt = opt
+	opt.Result = []*domain.AuditLog{{ID: bson.NewObjectID(), Action: domain.AuditActionRoleCreate, Resource: domain.AuditResourceRole}}
+	opt.Total = 3
+	return nil
+}
+
+func TestListAuditLogsParsesFiltersAndPagination(t *testing.T) {
+	svc := &fakeAuditLogService{}
+	h := &Handler{Svc: svc}
+	userID := bson.NewObjectID()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs?from=100&to=200&userIds="+userID.Hex()+"&resources=role,%20user&actions=role.create&page=3&pageSize=2", nil)
+	w := httptest.NewRecorder()
+
+	h.ListAuditLogs(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	opt := svc.opt
+	if opt.TimestampGTE != 100 || opt.TimestampLTE != 200 {
+		t.Fatalf("unexpected time range %d-%d", opt.TimestampGTE, opt.TimestampLTE)
+	}
+	if len(opt.UserIDs) != 1 || opt.UserIDs[0] != userID {
+		t.Fatalf("unexpected user IDs %v", opt.UserIDs)
+	}
+	if len(opt.Resources) != 2 || opt.Resources[1] != "user" {
+		t.Fatalf("unexpected resources %v", opt.Resources)
+	}
+	if opt.Skip != 4 || opt.Limit != 2 {
+		t.Fatalf("expected skip 4 limit 2, got skip %d limit %d", opt.Skip, opt.Limit)
+	}
+
+	var resp SuccessResponse[ListAuditLogsResponse]
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Data.Total != 3 || resp.Data.Page != 3 || len(resp.Data.AuditLogs) != 1 || resp.Data.AuditLogs[0].Action != domain.AuditActionRoleCreate {
+		t.Fatalf("unexpected response %+v", resp.Data)
+	}
+}
+
+func TestListAuditLogsRejectsInvalidQuery(t *testing.T) {
+	h := &Handler{Svc: &fakeAuditLogService{}}
+	for _, query := range []string{"from=abc", "userIds=not-an-id", "page=0", "pageSize=501"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/audit-logs?"+query, nil)
+		w := httptest.NewRecorder()
+
+		h.ListAuditLogs(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestClientIPOnlyTrustsForwardedForFromTrustedProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	newRequest := func(remoteAddr, forwardedFor string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return r
+	}
+
+	// A client that is not a trusted proxy cannot forge its address.
+	if ip := clientIP(newRequest("203.0.113.7:5000", "198.51.100.1"), trusted); ip != "203.0.113.7" {
+		t.Fatalf("untrusted peer: got %s", ip)
+	}
+	if ip := clientIP(newRequest("203.0.113.7:5000", "198.51.100.1"), nil); ip != "203.0.113.7" {
+		t.Fatalf("no trusted proxies: got %s", ip)
+	}
+	// Behind trusted proxies the first untrusted hop from the right is the
+	// client; anything it prepended is ignored.
+	if ip := clientIP(newRequest("10.0.0.2:5000", "1.2.3.4, 198.51.100.1, 10.0.0.9"), trusted); ip != "198.51.100.1" {
+		t.Fatalf("trusted chain: got %s", ip)
+	}
+	if ip := clientIP(newRequest("10.0.0.2:5000", ""), trusted); ip != "10.0.0.2" {
+		t.Fatalf("trusted peer without header: got %s", ip)
+	}
+}
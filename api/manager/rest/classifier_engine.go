@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"hash/fnv"
 	"math"
 	"sort"
 	"strings"
@@ -72,6 +73,7 @@ type classificationInput struct {
 	Namespace string
 	Pod       string
 	Node      string
+	Cluster   string
 	Metrics   metricsPayload
 }
 
@@ -437,6 +439,7 @@ type podState struct {
 	namespace       string
 	pod             string
 	node            string
+	cluster         string
 	ewma            ewmaState
 	buffer          tieredBuffer
 	phase           PodPhase
@@ -454,8 +457,9 @@ type podState struct {
 	lastTimestamp  int64
 }
 
-func newPodState(namespace, pod string) *podState {
+func newPodState(cluster, namespace, pod string) *podState {
 	return &podState{
+		cluster:         cluster,
 		namespace:       namespace,
 		pod:             pod,
 		phase:           PodPhaseColdStart,
@@ -664,6 +668,7 @@ type classifyResponseItem struct {
 	Pod            string                 `json:"pod"`
 	Namespace      string                 `json:"namespace"`
 	Node           string                 `json:"node,omitempty"`
+	Cluster        string                 `json:"cluster,omitempty"`
 	Phase          PodPhase               `json:"phase"`
 	Classification classifyResult         `json:"classification"`
 	Drift          classifyDrift          `json:"drift"`
@@ -676,67 +681,128 @@ type listClassifyResponse struct {
 	Items []*classifyResponseItem `json:"items"`
 }
 
+// classifierShard holds the pods of the namespaces hashed onto it together
+// with the clustering model fitted on them. Every shard is ingested by exactly
+// one manager replica; the other replicas serve it read-only from the last
+// snapshot its owner persisted.
+type classifierShard struct {
+	mu    sync.RWMutex
+	pods  map[string]*podState
+	model *adaptiveClusteringModel
+	// owned reports whether this replica ingests into the shard. Shards start
+	// out owned so that a manager without state persistence ingests everything.
+	owned bool
+	// synced is set once the shard has been reconciled with the persisted
+	// state, which is when it gets restored for the first time.
+	synced       bool
+	ownerAddress string
+	// snapshotAt is the SnapshotAt of the persisted state the shard was last
+	// restored from, used to skip re-decoding an unchanged read-only shard.
+	snapshotAt int64
+}
+
 type AdaptiveClassifier struct {
-	mu      sync.RWMutex
-	pods    map[string]*podState
-	model   *adaptiveClusteringModel
-	podTTL  int64
-	maxPods int
+	shards    []*classifierShard
+	nClusters int
+	podTTL    int64
+	maxPods   int
 }
 
 func NewAdaptiveClassifier(nClusters int) *AdaptiveClassifier {
+	return NewShardedAdaptiveClassifier(nClusters, 1)
+}
+
+// NewShardedAdaptiveClassifier spreads namespaces over shardCount shards, each
+// with its own clustering model, so that manager replicas can split ingestion
+// by namespace. The pod limit is divided evenly between the shards.
+func NewShardedAdaptiveClassifier(nClusters, shardCount int) *AdaptiveClassifier {
 	if nClusters <= 0 {
 		nClusters = 5
 	}
-	return &AdaptiveClassifier{
-		pods:    map[string]*podState{},
-		model:   newAdaptiveClusteringModel(nClusters),
-		podTTL:  classifierPodTTLSeconds,
-		maxPods: classifierMaxPods,
+	if shardCount <= 0 {
+		shardCount = 1
 	}
+	c := &AdaptiveClassifier{
+		shards:    make([]*classifierShard, shardCount),
+		nClusters: nClusters,
+		podTTL:    classifierPodTTLSeconds,
+		maxPods:   (classifierMaxPods + shardCount - 1) / shardCount,
+	}
+	for i := range c.shards {
+		c.shards[i] = &classifierShard{
+			pods:  map[string]*podState{},
+			model: newAdaptiveClusteringModel(nClusters),
+			owned: true,
+		}
+	}
+	return c
+}
+
+// ShardCount returns the number of shards namespaces are spread over.
+func (c *AdaptiveClassifier) ShardCount() int {
+	return len(c.shards)
+}
+
+// ShardOf returns the shard the namespace is hashed onto.
+func (c *AdaptiveClassifier) ShardOf(namespace string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.TrimSpace(namespace)))
+	return int(h.Sum32() % uint32(len(c.shards)))
+}
+
+// Owner reports whether this replica ingests the namespace and, if it does
+// not, the advertised address of the replica that does.
+func (c *AdaptiveClassifier) Owner(namespace string) (owned bool, ownerAddress string) {
+	shard := c.shards[c.ShardOf(namespace)]
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.owned, shard.ownerAddress
 }
 
 func (c *AdaptiveClassifier) Ingest(input classificationInput) *classifyResponseItem {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	shard := c.shards[c.ShardOf(input.Namespace)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
 	ts := input.Timestamp
 	if ts <= 0 {
 		ts = time.Now().Unix()
 	}
-	c.cleanupLocked(time.Now().Unix())
-	key := input.Namespace + "/" + input.Pod
-	st, ok := c.pods[key]
+	shard.cleanupLocked(time.Now().Unix(), c.podTTL, c.maxPods)
+	key := podStateKey(input.Cluster, input.Namespace, input.Pod)
+	st, ok := shard.pods[key]
 	if !ok {
-		st = newPodState(input.Namespace, input.Pod)
-		c.pods[key] = st
+		st = newPodState(input.Cluster, input.Namespace, input.Pod)
+		shard.pods[key] = st
 	}
 	fv := computeFeatures(input.Metrics)
-	st.Update(ts, input.Node, fv, c.model)
+	st.Update(ts, input.Node, fv, shard.model)
 	return buildClassifyItem(st)
 }
 
 // Cleanup evicts stale or overflow pod states.
 func (c *AdaptiveClassifier) Cleanup(now time.Time) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.cleanupLocked(now.Unix())
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		shard.cleanupLocked(now.Unix(), c.podTTL, c.maxPods)
+		shard.mu.Unlock()
+	}
 }
 
-func (c *AdaptiveClassifier) cleanupLocked(nowUnix int64) {
-	if c.podTTL > 0 {
-		expireBefore := nowUnix - c.podTTL
-		for key, st := range c.pods {
+func (s *classifierShard) cleanupLocked(nowUnix int64, podTTL int64, maxPods int) {
+	if podTTL > 0 {
+		expireBefore := nowUnix - podTTL
+		for key, st := range s.pods {
 			if st.lastTimestamp <= 0 {
 				continue
 			}
 			if st.lastTimestamp < expireBefore {
-				delete(c.pods, key)
+				delete(s.pods, key)
 			}
 		}
 	}
 
-	if c.maxPods <= 0 || len(c.pods) <= c.maxPods {
+	if maxPods <= 0 || len(s.pods) <= maxPods {
 		return
 	}
 
@@ -744,24 +810,28 @@ func (c *AdaptiveClassifier) cleanupLocked(nowUnix int64) {
 		key       string
 		timestamp int64
 	}
-	entries := make([]podEntry, 0, len(c.pods))
-	for key, st := range c.pods {
+	entries := make([]podEntry, 0, len(s.pods))
+	for key, st := range s.pods {
 		entries = append(entries, podEntry{key: key, timestamp: st.lastTimestamp})
 	}
 	sort.Slice(entries, func(i, j int) bool { return entries[i].timestamp < entries[j].timestamp })
 
-	toDelete := len(entries) - c.maxPods
+	toDelete := len(entries) - maxPods
 	for i := 0; i < toDelete; i++ {
-		delete(c.pods, entries[i].key)
+		delete(s.pods, entries[i].key)
 	}
 }
 
-func (c *AdaptiveClassifier) Get(namespace, pod string) (*classifyResponseItem, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// Get returns the classification of a pod. Pods of a multi-cluster manager
+// are told apart by their cluster, which is empty otherwise.
+func (c *AdaptiveClassifier) Get(cluster, namespace, pod string) (*classifyResponseItem, bool) {
+	cluster = strings.TrimSpace(cluster)
 	namespace = strings.TrimSpace(namespace)
 	pod = strings.TrimSpace(pod)
-	st, ok := c.pods[namespace+"/"+pod]
+	shard := c.shards[c.ShardOf(namespace)]
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	st, ok := shard.pods[podStateKey(cluster, namespace, pod)]
 	if !ok {
 		return nil, false
 	}
@@ -769,21 +839,49 @@ func (c *AdaptiveClassifier) Get(namespace, pod string) (*classifyResponseItem,
 }
 
 func (c *AdaptiveClassifier) List(namespace string, phase PodPhase, t string) []*classifyResponseItem {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	return c.list(namespace, phase, t, false)
+}
 
-	items := make([]*classifyResponseItem, 0, len(c.pods))
-	for _, st := range c.pods {
-		if namespace != "" && st.namespace != namespace {
-			continue
-		}
-		if phase != "" && st.phase != phase {
+// podStateKey keys the state of a pod by namespace and name, prefixed by the
+// cluster of the pod when the manager federates several.
+func podStateKey(cluster, namespace, pod string) string {
+	if cluster == "" {
+		return namespace + "/" + pod
+	}
+	return cluster + "/" + namespace + "/" + pod
+}
+
+// ListOwned lists the pods of the shards this replica ingests.
+func (c *AdaptiveClassifier) ListOwned() []*classifyResponseItem {
+	return c.list("", "", "", true)
+}
+
+func (c *AdaptiveClassifier) list(namespace string, phase PodPhase, t string, ownedOnly bool) []*classifyResponseItem {
+	shards := c.shards
+	if namespace != "" {
+		shards = []*classifierShard{c.shards[c.ShardOf(namespace)]}
+	}
+
+	items := make([]*classifyResponseItem, 0)
+	for _, shard := range shards {
+		shard.mu.RLock()
+		if ownedOnly && !shard.owned {
+			shard.mu.RUnlock()
 			continue
 		}
-		if t != "" && !containsTag(st.currentTypes, t) {
-			continue
+		for _, st := range shard.pods {
+			if namespace != "" && st.namespace != namespace {
+				continue
+			}
+			if phase != "" && st.phase != phase {
+				continue
+			}
+			if t != "" && !containsTag(st.currentTypes, t) {
+				continue
+			}
+			items = append(items, buildClassifyItem(st))
 		}
-		items = append(items, buildClassifyItem(st))
+		shard.mu.RUnlock()
 	}
 
 	sort.Slice(items, func(i, j int) bool {
@@ -811,6 +909,7 @@ func buildClassifyItem(st *podState) *classifyResponseItem {
 		Pod:       st.pod,
 		Namespace: st.namespace,
 		Node:      st.node,
+		Cluster:   st.cluster,
 		Phase:     st.phase,
 		Classification: classifyResult{
 			CurrentType:  cloneStringSlice(st.currentTypes),
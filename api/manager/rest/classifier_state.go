@@ -0,0 +1,244 @@
+package rest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+)
+
+const classifierSnapshotVersion = 1
+
+// classifierShardSnapshot is the persisted form of a classifier shard. The
+// tiered buffers of the pods are left out: nothing reads them when classifying
+// and they refill with new samples.
+type classifierShardSnapshot struct {
+	Version int                     `json:"version"`
+	Model   clusteringModelSnapshot `json:"model"`
+	Pods    []podStateSnapshot      `json:"pods"`
+}
+
+type clusteringModelSnapshot struct {
+	DataBuffer       []featureVector  `json:"dataBuffer"`
+	ClusterCenters   []featureVector  `json:"clusterCenters"`
+	ClusterSemantics map[int][]string `json:"clusterSemantics"`
+	IsFitted         bool             `json:"isFitted"`
+}
+
+type ewmaSnapshot struct {
+	Initialized bool          `json:"initialized"`
+	ShortMean   featureVector `json:"shortMean"`
+	ShortVar    featureVector `json:"shortVar"`
+	LongMean    featureVector `json:"longMean"`
+	LongVar     featureVector `json:"longVar"`
+	UpdateCount int           `json:"updateCount"`
+}
+
+type podStateSnapshot struct {
+	Namespace       string       `json:"namespace"`
+	Pod             string       `json:"pod"`
+	Node            string       `json:"node"`
+	Cluster         string       `json:"cluster,omitempty"`
+	EWMA            ewmaSnapshot `json:"ewma"`
+	Phase           PodPhase     `json:"phase"`
+	CurrentCluster  int          `json:"currentCluster"`
+	PreviousCluster int          `json:"previousCluster"`
+	DriftConfirmed  int          `json:"driftConfirmed"`
+	LastDriftScore  float64      `json:"lastDriftScore"`
+	CurrentTypes    []string     `json:"currentTypes"`
+	PreviousTypes   []string     `json:"previousTypes"`
+	LastTimestamp   int64        `json:"lastTimestamp"`
+}
+
+// snapshotLocked encodes the shard as gzip-compressed JSON.
+func (s *classifierShard) snapshotLocked() ([]byte, error) {
+	snapshot := classifierShardSnapshot{
+		Version: classifierSnapshotVersion,
+		Model: clusteringModelSnapshot{
+			DataBuffer:       s.model.dataBuffer,
+			ClusterCenters:   s.model.clusterCenters,
+			ClusterSemantics: s.model.clusterSemantics,
+			IsFitted:         s.model.isFitted,
+		},
+		Pods: make([]podStateSnapshot, 0, len(s.pods)),
+	}
+	for _, st := range s.pods {
+		snapshot.Pods = append(snapshot.Pods, podStateSnapshot{
+			Namespace: st.namespace,
+			Pod:       st.pod,
+			Node:      st.node,
+			Cluster:   st.cluster,
+			EWMA: ewmaSnapshot{
+				Initialized: st.ewma.initialized,
+				ShortMean:   st.ewma.shortMean,
+				ShortVar:    st.ewma.shortVar,
+				LongMean:    st.ewma.longMean,
+				LongVar:     st.ewma.longVar,
+				UpdateCount: st.ewma.updateCount,
+			},
+			Phase:           st.phase,
+			CurrentCluster:  st.currentCluster,
+			PreviousCluster: st.previousCluster,
+			DriftConfirmed:  st.driftConfirmed,
+			LastDriftScore:  st.lastDriftScore,
+			CurrentTypes:    st.currentTypes,
+			PreviousTypes:   st.previousTypes,
+			LastTimestamp:   st.lastTimestamp,
+		})
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("encode classifier shard snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress classifier shard snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// restoreLocked replaces the shard content with the snapshot. An empty
+// payload resets the shard.
+func (s *classifierShard) restoreLocked(payload []byte, nClusters int) error {
+	pods := map[string]*podState{}
+	model := newAdaptiveClusteringModel(nClusters)
+	if len(payload) > 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("decompress classifier shard snapshot: %w", err)
+		}
+		var snapshot classifierShardSnapshot
+		if err := json.NewDecoder(zr).Decode(&snapshot); err != nil {
+			return fmt.Errorf("decode classifier shard snapshot: %w", err)
+		}
+		if _, err := io.Copy(io.Discard, zr); err != nil {
+			return fmt.Errorf("decompress classifier shard snapshot: %w", err)
+		}
+		if snapshot.Version != classifierSnapshotVersion {
+			return fmt.Errorf("unsupported classifier shard snapshot version %d", snapshot.Version)
+		}
+
+		model.dataBuffer = append(model.dataBuffer, snapshot.Model.DataBuffer...)
+		model.clusterCenters = snapshot.Model.ClusterCenters
+		if snapshot.Model.ClusterSemantics != nil {
+			model.clusterSemantics = snapshot.Model.ClusterSemantics
+		}
+		model.isFitted = snapshot.Model.IsFitted
+		for _, ps := range snapshot.Pods {
+			pods[podStateKey(ps.Cluster, ps.Namespace, ps.Pod)] = &podState{
+				namespace: ps.Namespace,
+				pod:       ps.Pod,
+				node:      ps.Node,
+				cluster:   ps.Cluster,
+				ewma: ewmaState{
+					initialized: ps.EWMA.Initialized,
+					shortMean:   ps.EWMA.ShortMean,
+					shortVar:    ps.EWMA.ShortVar,
+					longMean:    ps.EWMA.LongMean,
+					longVar:     ps.EWMA.LongVar,
+					updateCount: ps.EWMA.UpdateCount,
+				},
+				phase:           ps.Phase,
+				currentCluster:  ps.CurrentCluster,
+				previousCluster: ps.PreviousCluster,
+				driftConfirmed:  ps.DriftConfirmed,
+				lastDriftScore:  ps.LastDriftScore,
+				currentTypes:    ps.CurrentTypes,
+				previousTypes:   ps.PreviousTypes,
+				lastTimestamp:   ps.LastTimestamp,
+			}
+		}
+	}
+	s.pods = pods
+	s.model = model
+	return nil
+}
+
+// SnapshotOwned encodes every shard this replica ingests, keyed by shard.
+// Shards that were not synced yet are skipped so that an empty classifier
+// never overwrites persisted state before restoring it.
+func (c *AdaptiveClassifier) SnapshotOwned() (map[int][]byte, error) {
+	snapshots := map[int][]byte{}
+	for i, shard := range c.shards {
+		shard.mu.RLock()
+		if !shard.owned || !shard.synced {
+			shard.mu.RUnlock()
+			continue
+		}
+		payload, err := shard.snapshotLocked()
+		shard.mu.RUnlock()
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		snapshots[i] = payload
+	}
+	return snapshots, nil
+}
+
+// ApplyShardStates takes over the ownership recorded in the persisted shard
+// states. A shard is restored from its payload unless the replica kept owning
+// it, in which case memory is ahead of the store; read-only shards are
+// refreshed whenever their owner stored a newer snapshot.
+func (c *AdaptiveClassifier) ApplyShardStates(states []*domain.ClassifierShardState, replicaID string) error {
+	var restoreErr error
+	for _, state := range states {
+		if state == nil || state.Shard < 0 || state.Shard >= len(c.shards) {
+			continue
+		}
+		shard := c.shards[state.Shard]
+		ownedNow := state.Owner == replicaID
+
+		shard.mu.Lock()
+		keep := shard.synced && shard.owned && ownedNow
+		unchanged := shard.synced && !ownedNow && shard.snapshotAt == state.SnapshotAt
+		if !keep && !unchanged {
+			if err := shard.restoreLocked(state.Payload, c.nClusters); err != nil {
+				restoreErr = fmt.Errorf("shard %d: %w", state.Shard, err)
+				shard.pods = map[string]*podState{}
+				shard.model = newAdaptiveClusteringModel(c.nClusters)
+			}
+			shard.snapshotAt = state.SnapshotAt
+		}
+		shard.owned = ownedNow
+		shard.ownerAddress = state.OwnerAddress
+		shard.synced = true
+		shard.mu.Unlock()
+	}
+	return restoreErr
+}
+
+// SyncClassifierState persists the shards this replica ingests, renews its
+// shard leases and restores the shards it took over or only serves.
+func (h *Handler) SyncClassifierState(ctx context.Context) error {
+	svc, ok := h.Svc.(interface {
+		SyncClassifierShards(ctx context.Context, sync *domain.ClassifierShardSync) ([]*domain.ClassifierShardState, error)
+	})
+	if !ok {
+		return errs.NewHTTPStatusError(http.StatusNotImplemented, "classifier state persistence is not enabled", nil)
+	}
+	snapshots, err := h.classifier.SnapshotOwned()
+	if err != nil {
+		return err
+	}
+	replicaID := h.classifierCfg.ResolvedReplicaID()
+	states, err := svc.SyncClassifierShards(ctx, &domain.ClassifierShardSync{
+		Replica: domain.ClassifierReplica{
+			ID:      replicaID,
+			Address: h.classifierCfg.AdvertiseAddress,
+		},
+		ShardCount:   h.classifier.ShardCount(),
+		LeaseSeconds: int64(h.classifierCfg.Lease().Seconds()),
+		Snapshots:    snapshots,
+	})
+	if err != nil {
+		return err
+	}
+	return h.classifier.ApplyShardStates(states, replicaID)
+}
@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+func ownedShardStates(c *AdaptiveClassifier, owner, address string, payloads map[int][]byte) []*domain.ClassifierShardState {
+	states := make([]*domain.ClassifierShardState, c.ShardCount())
+	for i := range states {
+		states[i] = &domain.ClassifierShardState{
+			Shard:        i,
+			Owner:        owner,
+			OwnerAddress: address,
+			Payload:      payloads[i],
+			SnapshotAt:   time.Now().UnixMilli(),
+		}
+	}
+	return states
+}
+
+func stableClassificationInput(namespace string, ts int64) classificationInput {
+	return classificationInput{
+		Timestamp: ts,
+		Namespace: namespace,
+		Pod:       "pod-a",
+		Node:      "node-1",
+		Metrics: metricsPayload{
+			VolCtxSW:   19,
+			InvolCtxSW: 9627,
+			CPUTime:    183346997199,
+			WaitTime:   1490167876,
+			RunCount:   9647,
+			L3Migr:     3,
+			NUMAMigr:   1,
+		},
+	}
+}
+
+func TestClassifierShardSnapshotRoundTrip(t *testing.T) {
+	c := NewShardedAdaptiveClassifier(3, 2)
+	if err := c.ApplyShardStates(ownedShardStates(c, "replica-a", "", nil), "replica-a"); err != nil {
+		t.Fatalf("apply shard states: %v", err)
+	}
+	now := time.Now().Unix()
+	for i := 0; i < stableMinSamples; i++ {
+		c.Ingest(stableClassificationInput("team-a", now+int64(i)))
+		c.Ingest(stableClassificationInput("team-b", now+int64(i)))
+	}
+
+	snapshots, err := c.SnapshotOwned()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("expected a snapshot per owned shard, got %d", len(snapshots))
+	}
+
+	restored := NewShardedAdaptiveClassifier(3, 2)
+	if err := restored.ApplyShardStates(ownedShardStates(restored, "replica-a", "", snapshots), "replica-a"); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for _, ns := range []string{"team-a", "team-b"} {
+		want, _ := c.Get("", ns, "pod-a")
+		got, ok := restored.Get("", ns, "pod-a")
+		if !ok {
+			t.Fatalf("expected %s/pod-a to be restored", ns)
+		}
+		if got.Phase != PodPhaseStable || !reflect.DeepEqual(got, want) {
+			t.Fatalf("restored classification differs:\n got=%+v\nwant=%+v", got, want)
+		}
+	}
+
+	// The restored shard keeps classifying like the original one.
+	next := stableClassificationInput("team-a", now+int64(stableMinSamples))
+	if want, got := c.Ingest(next), restored.Ingest(next); !reflect.DeepEqual(got, want) {
+		t.Fatalf("classification after restore differs:\n got=%+v\nwant=%+v", got, want)
+	}
+}
+
+func TestApplyShardStatesServesForeignShardsReadOnly(t *testing.T) {
+	owner := NewShardedAdaptiveClassifier(3, 1)
+	_ = owner.ApplyShardStates(ownedShardStates(owner, "replica-a", "", nil), "replica-a")
+	owner.Ingest(stableClassificationInput("team-a", time.Now().Unix()))
+	snapshots, err := owner.SnapshotOwned()
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	c := NewShardedAdaptiveClassifier(3, 1)
+	c.Ingest(stableClassificationInput("team-z", time.Now().Unix()))
+	if err := c.ApplyShardStates(ownedShardStates(c, "replica-a", "manager-a:8080", snapshots), "replica-b"); err != nil {
+		t.Fatalf("apply shard states: %v", err)
+	}
+
+	if owned, address := c.Owner("team-a"); owned || address != "manager-a:8080" {
+		t.Fatalf("expected shard owned by manager-a:8080, got owned=%v address=%q", owned, address)
+	}
+	if _, ok := c.Get("", "team-z", "pod-a"); ok {
+		t.Fatal("expected local state of a foreign shard to be replaced by its snapshot")
+	}
+	if items := c.List("", "", ""); len(items) != 1 || items[0].Namespace != "team-a" {
+		t.Fatalf("expected the owner's classification, got %+v", items)
+	}
+	if items := c.ListOwned(); len(items) != 0 {
+		t.Fatalf("expected no owned classifications, got %+v", items)
+	}
+	if snapshots, _ := c.SnapshotOwned(); len(snapshots) != 0 {
+		t.Fatalf("expected foreign shards not to be snapshotted, got %d", len(snapshots))
+	}
+}
+
+func TestIngestPodMetricsForwardsToShardOwner(t *testing.T) {
+	var forwarded ingestMetricsRequest
+	var forwardedBy string
+	ownerSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwardedBy = r.Header.Get(classifierForwardedHeader)
+		_ = json.NewDecoder(r.Body).Decode(&forwarded)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ownerSrv.Close()
+
+	h := &Handler{classifier: NewShardedAdaptiveClassifier(2, 1)}
+	h.classifierCfg.ReplicaID = "replica-b"
+	h.classifierCfg.ForwardSecret = "replica-secret"
+	_ = h.classifier.ApplyShardStates(ownedShardStates(h.classifier, "replica-a", ownerSrv.URL, nil), "replica-b")
+
+	ctx := h.SetRolePolicyInContext(context.Background(), domain.RolePolicy{})
+	body, _ := json.Marshal(ingestMetricsRequest{Namespace: "team-a", Pod: "pod-a"})
+	w := httptest.NewRecorder()
+	h.IngestPodMetrics(w, httptest.NewRequest(http.MethodPost, "/api/v1/metrics", bytes.NewReader(body)).WithContext(ctx))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected forwarded response, got %d: %s", w.Code, w.Body.String())
+	}
+	if forwarded.Namespace != "team-a" || forwarded.Pod != "pod-a" || forwardedBy != "replica-secret" {
+		t.Fatalf("unexpected forwarded request %+v by %q", forwarded, forwardedBy)
+	}
+	if _, ok := h.classifier.Get("", "team-a", "pod-a"); ok {
+		t.Fatal("expected forwarded metrics not to be ingested locally")
+	}
+
+	// A request that was already forwarded is not bounced again.
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/metrics", bytes.NewReader(body)).WithContext(ctx)
+	r.Header.Set(classifierForwardedHeader, "replica-secret")
+	w = httptest.NewRecorder()
+	h.IngestPodMetrics(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for a forwarded request, got %d", w.Code)
+	}
+
+	// Clients cannot claim to be a replica to skip forwarding.
+	forwarded = ingestMetricsRequest{}
+	r = httptest.NewRequest(http.MethodPost, "/api/v1/metrics", bytes.NewReader(body)).WithContext(ctx)
+	r.Header.Set(classifierForwardedHeader, "replica-c")
+	w = httptest.NewRecorder()
+	h.IngestPodMetrics(w, r)
+	if w.Code != http.StatusOK || forwarded.Pod != "pod-a" {
+		t.Fatalf("expected a request with a wrong secret to be forwarded, got %d", w.Code)
+	}
+
+	// Without a forward secret metrics are not forwarded at all.
+	h.classifierCfg.ForwardSecret = ""
+	w = httptest.NewRecorder()
+	h.IngestPodMetrics(w, httptest.NewRequest(http.MethodPost, "/api/v1/metrics", bytes.NewReader(body)).WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a forward secret, got %d", w.Code)
+	}
+}
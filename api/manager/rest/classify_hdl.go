@@ -1,16 +1,31 @@
 package rest
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
 )
 
+// classifierForwardedHeader marks metrics forwarded to the replica owning
+// their classifier shard. It carries the classifier forward secret, so only
+// other replicas can set it.
+const classifierForwardedHeader = "X-Gthulhu-Classifier-Forwarded"
+
 type ingestMetricsRequest struct {
-	Timestamp int64          `json:"timestamp"`
-	Namespace string         `json:"namespace"`
-	Pod       string         `json:"pod"`
-	Node      string         `json:"node"`
-	Metrics   metricsPayload `json:"metrics"`
+	Timestamp int64  `json:"timestamp"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Node      string `json:"node"`
+	// Cluster is the cluster of the pod, for managers federating several.
+	Cluster string         `json:"cluster,omitempty"`
+	Metrics metricsPayload `json:"metrics"`
 }
 
 // IngestPodMetrics godoc
@@ -25,6 +40,7 @@ type ingestMetricsRequest struct {
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/metrics [post]
 func (h *Handler) IngestPodMetrics(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -36,27 +52,83 @@ func (h *Handler) IngestPodMetrics(w http.ResponseWriter, r *http.Request) {
 	namespace := strings.TrimSpace(req.Namespace)
 	pod := strings.TrimSpace(req.Pod)
 	node := strings.TrimSpace(req.Node)
+	cluster := strings.TrimSpace(req.Cluster)
 	if namespace == "" || pod == "" {
 		h.ErrorResponse(ctx, w, http.StatusBadRequest, "namespace and pod are required", nil)
 		return
 	}
+	if err := h.VerifyK8SNamespacePolicy(ctx, []string{namespace}); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	if owned, ownerAddress := h.classifier.Owner(namespace); !owned {
+		h.forwardPodMetrics(w, r, &req, ownerAddress)
+		return
+	}
 
 	item := h.classifier.Ingest(classificationInput{
 		Timestamp: req.Timestamp,
 		Namespace: namespace,
 		Pod:       pod,
 		Node:      node,
+		Cluster:   cluster,
 		Metrics:   req.Metrics,
 	})
 	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(item))
 }
 
+// forwardPodMetrics hands metrics of a namespace whose classifier shard is
+// ingested by another replica over to that replica. Forwarded requests are
+// never forwarded again, so replicas disagreeing on ownership answer 503
+// instead of bouncing the request between each other. Without a forward
+// secret replicas cannot tell forwarded requests apart and do not forward.
+func (h *Handler) forwardPodMetrics(w http.ResponseWriter, r *http.Request, req *ingestMetricsRequest, ownerAddress string) {
+	ctx := r.Context()
+	secret := h.classifierCfg.ForwardSecret.Value()
+	if ownerAddress == "" || secret == "" || h.forwardedByReplica(r) {
+		h.ErrorResponse(ctx, w, http.StatusServiceUnavailable, "classifier shard of the namespace is ingested by another replica", nil)
+		return
+	}
+	if !strings.Contains(ownerAddress, "://") {
+		ownerAddress = "http://" + ownerAddress
+	}
+	target, err := url.Parse(ownerAddress)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusServiceUnavailable, "invalid classifier shard owner address", err)
+		return
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Internal Server Error", err)
+		return
+	}
+
+	forwarded := r.Clone(ctx)
+	forwarded.Body = io.NopCloser(bytes.NewReader(body))
+	forwarded.ContentLength = int64(len(body))
+	forwarded.Header.Set(classifierForwardedHeader, secret)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		h.ErrorResponse(r.Context(), w, http.StatusServiceUnavailable, "classifier shard owner is unreachable", err)
+	}
+	proxy.ServeHTTP(w, forwarded)
+}
+
+// forwardedByReplica reports whether r was forwarded by another replica,
+// that is whether it carries the classifier forward secret.
+func (h *Handler) forwardedByReplica(r *http.Request) bool {
+	secret := h.classifierCfg.ForwardSecret.Value()
+	header := r.Header.Get(classifierForwardedHeader)
+	return secret != "" && subtle.ConstantTimeCompare([]byte(header), []byte(secret)) == 1
+}
+
 // GetPodClassification godoc
 // @Summary Get adaptive classification for a pod
 // @Description Returns current classification, drift status and recommendation for a pod.
 // @Tags PodSchedulingMetrics
 // @Produce json
 // @Security BearerAuth
+// @Param cluster query string false "cluster of the pod, for multi-cluster managers"
 // @Success 200 {object} SuccessResponse[classifyResponseItem]
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -70,8 +142,12 @@ func (h *Handler) GetPodClassification(w http.ResponseWriter, r *http.Request) {
 		h.ErrorResponse(ctx, w, http.StatusBadRequest, "namespace and pod path parameters are required", nil)
 		return
 	}
+	if err := h.VerifyK8SNamespacePolicy(ctx, []string{namespace}); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
 
-	item, ok := h.classifier.Get(namespace, pod)
+	item, ok := h.classifier.Get(r.URL.Query().Get("cluster"), namespace, pod)
 	if !ok {
 		h.ErrorResponse(ctx, w, http.StatusNotFound, "classification not found", nil)
 		return
@@ -88,6 +164,7 @@ func (h *Handler) GetPodClassification(w http.ResponseWriter, r *http.Request) {
 // @Param namespace query string false "namespace filter"
 // @Param phase query string false "phase filter"
 // @Param type query string false "classification type filter"
+// @Param cluster query string false "cluster filter"
 // @Success 200 {object} SuccessResponse[listClassifyResponse]
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -97,7 +174,49 @@ func (h *Handler) ListPodClassifications(w http.ResponseWriter, r *http.Request)
 	phase := PodPhase(strings.TrimSpace(r.URL.Query().Get("phase")))
 	tag := strings.TrimSpace(r.URL.Query().Get("type"))
 	namespace := strings.TrimSpace(r.URL.Query().Get("namespace"))
+	cluster := strings.TrimSpace(r.URL.Query().Get("cluster"))
 
-	resp := &listClassifyResponse{Items: h.classifier.List(namespace, phase, tag)}
+	allowed := h.k8sNamespaceFilter(ctx)
+	resp := &listClassifyResponse{Items: []*classifyResponseItem{}}
+	for _, item := range h.classifier.List(namespace, phase, tag) {
+		if cluster != "" && item.Cluster != cluster {
+			continue
+		}
+		if allowed(item.Namespace) {
+			resp.Items = append(resp.Items, item)
+		}
+	}
 	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
 }
+
+// OwnsClassifierNamespace reports whether this replica owns the classifier
+// shard of namespace.
+func (h *Handler) OwnsClassifierNamespace(namespace string) bool {
+	owned, _ := h.classifier.Owner(namespace)
+	return owned
+}
+
+// PodClassifications returns the current classification of every pod in the
+// classifier shards this replica ingests. It is read by the classifier feeder
+// to drive strategy recommendations, so every pod is handled by one replica.
+func (h *Handler) PodClassifications() []*domain.PodClassification {
+	items := h.classifier.ListOwned()
+	result := make([]*domain.PodClassification, 0, len(items))
+	for _, item := range items {
+		result = append(result, &domain.PodClassification{
+			Namespace:     item.Namespace,
+			Pod:           item.Pod,
+			Node:          item.Node,
+			Cluster:       item.Cluster,
+			Phase:         string(item.Phase),
+			Types:         item.Classification.CurrentType,
+			Confidence:    item.Classification.Confidence,
+			Action:        item.Recommendation.Action,
+			Reason:        item.Recommendation.Reason,
+			CPUPerRunNS:   item.Profile.ShortTerm["cpu_per_run"],
+			InvolCtxRatio: item.Profile.ShortTerm["invol_ctx_ratio"],
+			WaitRatio:     item.Profile.ShortTerm["wait_ratio"],
+		})
+	}
+	return result
+}
@@ -0,0 +1,327 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigBundle is the YAML document served by export and accepted by apply.
+// Its entries use the same fields as the update requests of each resource.
+type ConfigBundle struct {
+	APIVersion             string                              `json:"apiVersion"`
+	Kind                   string                              `json:"kind"`
+	Strategies             []UpdateScheduleStrategyRequest     `json:"strategies,omitempty"`
+	NodeSchedulingPolicies []UpdateNodeSchedulingPolicyRequest `json:"nodeSchedulingPolicies,omitempty"`
+	PodSchedulingMetrics   []UpdatePSMRequest                  `json:"podSchedulingMetrics,omitempty"`
+	Roles                  []CreateRoleRequest                 `json:"roles,omitempty"`
+	NodeRuntimeConfigs     []ConfigBundleNodeRuntimeConfig     `json:"nodeRuntimeConfigs,omitempty"`
+}
+
+type ConfigBundleNodeRuntimeConfig struct {
+	NodeID string                        `json:"nodeId"`
+	Config domain.RuntimeSchedulerConfig `json:"config"`
+}
+
+type ApplyConfigBundleResponse struct {
+	DryRun  bool                        `json:"dryRun"`
+	Changes []domain.ConfigBundleChange `json:"changes"`
+}
+
+type configBundleService interface {
+	ExportConfigBundle(ctx context.Context, operator *domain.Claims) (*domain.ConfigBundle, error)
+	ApplyConfigBundle(ctx context.Context, operator *domain.Claims, opt *domain.ApplyConfigBundleOptions) ([]domain.ConfigBundleChange, error)
+}
+
+// ExportConfigBundle godoc
+// @Summary Export configuration bundle
+// @Description Export the caller's schedule strategies and node scheduling policies, and all pod scheduling metrics, roles and node runtime configs, as a versioned YAML bundle.
+// @Tags ConfigBundle
+// @Produce application/yaml
+// @Security BearerAuth
+// @Success 200 {object} ConfigBundle
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/export [get]
+func (h *Handler) ExportConfigBundle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	svc, ok := h.Svc.(configBundleService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Config bundles are not enabled", nil)
+		return
+	}
+
+	bundle, err := svc.ExportConfigBundle(ctx, &claims)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	body, err := yaml.Marshal(convertDomainConfigBundle(bundle))
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusInternalServerError, "Failed to encode config bundle", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// ApplyConfigBundle godoc
+// @Summary Apply configuration bundle
+// @Description Diff a YAML (or JSON) bundle from export against the current objects and create, update or, with prune, delete them. With dryRun only the plan is returned. Every step needs the permission of its resource's endpoint. Changed node runtime configs bypass staged rollouts and are only applied with skipRollout. Steps that fail carry an error and do not stop the rest.
+// @Tags ConfigBundle
+// @Accept application/yaml
+// @Produce json
+// @Security BearerAuth
+// @Param prune query bool false "Delete strategies, node scheduling policies and pod scheduling metrics missing from the bundle"
+// @Param dryRun query bool false "Only compute the plan"
+// @Param skipRollout query bool false "Apply changed node runtime configs directly instead of refusing them"
+// @Param request body ConfigBundle true "Config bundle"
+// @Success 200 {object} SuccessResponse[ApplyConfigBundleResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/apply [post]
+func (h *Handler) ApplyConfigBundle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	prune, err := parseQueryBool(query.Get("prune"))
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid prune", err)
+		return
+	}
+	dryRun, err := parseQueryBool(query.Get("dryRun"))
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid dryRun", err)
+		return
+	}
+	skipRollout, err := parseQueryBool(query.Get("skipRollout"))
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid skipRollout", err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	var req ConfigBundle
+	if err := yaml.UnmarshalStrict(body, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid config bundle", err)
+		return
+	}
+	if req.APIVersion != domain.ConfigBundleAPIVersion || req.Kind != domain.ConfigBundleKind {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, fmt.Sprintf("Config bundle must be apiVersion %s, kind %s", domain.ConfigBundleAPIVersion, domain.ConfigBundleKind), nil)
+		return
+	}
+	bundle, err := convertConfigBundleToDomain(&req)
+	if err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid config bundle", err)
+		return
+	}
+
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	for _, strategy := range bundle.Strategies {
+		if err := h.VerifyK8SNamespacePolicy(ctx, strategy.K8sNamespace); err != nil {
+			h.HandleError(ctx, w, err)
+			return
+		}
+	}
+	svc, ok := h.Svc.(configBundleService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Config bundles are not enabled", nil)
+		return
+	}
+
+	changes, err := svc.ApplyConfigBundle(ctx, &claims, &domain.ApplyConfigBundleOptions{
+		Bundle:      bundle,
+		Prune:       prune,
+		DryRun:      dryRun,
+		SkipRollout: skipRollout,
+	})
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	if changes == nil {
+		changes = []domain.ConfigBundleChange{}
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&ApplyConfigBundleResponse{DryRun: dryRun, Changes: changes}))
+}
+
+func parseQueryBool(param string) (bool, error) {
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(param)
+}
+
+func convertDomainConfigBundle(bundle *domain.ConfigBundle) *ConfigBundle {
+	result := &ConfigBundle{
+		APIVersion: domain.ConfigBundleAPIVersion,
+		Kind:       domain.ConfigBundleKind,
+	}
+	for _, s := range bundle.Strategies {
+		result.Strategies = append(result.Strategies, UpdateScheduleStrategyRequest{
+			StrategyID:        s.ID.Hex(),
+			StrategyNamespace: s.StrategyNamespace,
+			LabelSelectors:    convertDomainLabelSelectorsToResponseLabelSelectors(s.LabelSelectors),
+			K8sNamespace:      s.K8sNamespace,
+			CommandRegex:      s.CommandRegex,
+			Priority:          s.Priority,
+			ExecutionTime:     s.ExecutionTime,
+			Precedence:        s.Precedence,
+			Schedule:          convertDomainScheduleToResponse(s.Schedule),
+			ClusterSelector:   s.ClusterSelector,
+		})
+	}
+	for _, p := range bundle.NodePolicies {
+		policy := convertDomainNodePolicyToResponse(p)
+		result.NodeSchedulingPolicies = append(result.NodeSchedulingPolicies, UpdateNodeSchedulingPolicyRequest{
+			PolicyID:        p.ID.Hex(),
+			NodeSelectors:   policy.NodeSelectors,
+			NodeNames:       policy.NodeNames,
+			DRASelectors:    policy.DRASelectors,
+			CommandRegex:    policy.CommandRegex,
+			Priority:        policy.Priority,
+			ExecutionTime:   policy.ExecutionTime,
+			Precedence:      policy.Precedence,
+			ClusterSelector: policy.ClusterSelector,
+		})
+	}
+	for _, p := range bundle.PodSchedulingMetrics {
+		item := domainPSMToResponse(p)
+		result.PodSchedulingMetrics = append(result.PodSchedulingMetrics, UpdatePSMRequest{
+			ID:                        item.ID,
+			LabelSelectors:            item.LabelSelectors,
+			K8sNamespaces:             item.K8sNamespaces,
+			CommandRegex:              item.CommandRegex,
+			CollectionIntervalSeconds: item.CollectionIntervalSeconds,
+			Enabled:                   &item.Enabled,
+			Metrics:                   item.Metrics,
+			Scaling:                   item.Scaling,
+		})
+	}
+	for _, role := range bundle.Roles {
+		req := CreateRoleRequest{Name: role.Name, Description: role.Description}
+		for _, p := range role.Policies {
+			req.RolePolicies = append(req.RolePolicies, RolePolicy{
+				PermissionKey:   p.PermissionKey,
+				Self:            p.Self,
+				K8SNamespace:    p.K8SNamespace,
+				PolicyNamespace: p.PolicyNamespace,
+			})
+		}
+		result.Roles = append(result.Roles, req)
+	}
+	for _, cfg := range bundle.NodeRuntimeConfigs {
+		result.NodeRuntimeConfigs = append(result.NodeRuntimeConfigs, ConfigBundleNodeRuntimeConfig{
+			NodeID: cfg.NodeID,
+			Config: cfg.Config,
+		})
+	}
+	return result
+}
+
+func convertConfigBundleToDomain(req *ConfigBundle) (*domain.ConfigBundle, error) {
+	bundle := &domain.ConfigBundle{}
+	for i := range req.Strategies {
+		s := &req.Strategies[i]
+		id, err := parseConfigBundleID("strategy", s.StrategyID)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Strategies = append(bundle.Strategies, &domain.ScheduleStrategy{
+			BaseEntity:        domain.BaseEntity{ID: id},
+			StrategyNamespace: s.StrategyNamespace,
+			LabelSelectors:    convertRequestLabelSelectorsToDomain(s.LabelSelectors),
+			K8sNamespace:      s.K8sNamespace,
+			CommandRegex:      s.CommandRegex,
+			Priority:          s.Priority,
+			ExecutionTime:     s.ExecutionTime,
+			Precedence:        s.Precedence,
+			Schedule:          convertRequestScheduleToDomain(s.Schedule),
+			ClusterSelector:   s.ClusterSelector,
+		})
+	}
+	for i := range req.NodeSchedulingPolicies {
+		p := &req.NodeSchedulingPolicies[i]
+		id, err := parseConfigBundleID("node scheduling policy", p.PolicyID)
+		if err != nil {
+			return nil, err
+		}
+		bundle.NodePolicies = append(bundle.NodePolicies, &domain.NodeSchedulingPolicy{
+			BaseEntity:      domain.BaseEntity{ID: id},
+			NodeSelectors:   convertRequestLabelSelectorsToDomain(p.NodeSelectors),
+			NodeNames:       p.NodeNames,
+			DRASelectors:    convertRequestDRASelectorsToDomain(p.DRASelectors),
+			CommandRegex:    p.CommandRegex,
+			Priority:        p.Priority,
+			ExecutionTime:   p.ExecutionTime,
+			Precedence:      p.Precedence,
+			ClusterSelector: p.ClusterSelector,
+		})
+	}
+	for i := range req.PodSchedulingMetrics {
+		p := &req.PodSchedulingMetrics[i]
+		id, err := parseConfigBundleID("pod scheduling metrics", p.ID)
+		if err != nil {
+			return nil, err
+		}
+		psm := updatePSMRequestToDomain(p)
+		psm.ID = id
+		bundle.PodSchedulingMetrics = append(bundle.PodSchedulingMetrics, psm)
+	}
+	for _, r := range req.Roles {
+		role := &domain.Role{Name: r.Name, Description: r.Description}
+		for _, p := range r.RolePolicies {
+			role.Policies = append(role.Policies, domain.RolePolicy{
+				PermissionKey:   p.PermissionKey,
+				Self:            p.Self,
+				K8SNamespace:    p.K8SNamespace,
+				PolicyNamespace: p.PolicyNamespace,
+			})
+		}
+		bundle.Roles = append(bundle.Roles, role)
+	}
+	for _, cfg := range req.NodeRuntimeConfigs {
+		bundle.NodeRuntimeConfigs = append(bundle.NodeRuntimeConfigs, &domain.NodeRuntimeConfig{
+			NodeID: cfg.NodeID,
+			Config: cfg.Config,
+		})
+	}
+	return bundle, nil
+}
+
+func parseConfigBundleID(resource, id string) (bson.ObjectID, error) {
+	if id == "" {
+		return bson.ObjectID{}, fmt.Errorf("every %s needs an ID", resource)
+	}
+	objID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return bson.ObjectID{}, fmt.Errorf("invalid %s ID %q: %w", resource, id, err)
+	}
+	return objID, nil
+}
@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"sigs.k8s.io/yaml"
+)
+
+type fakeConfigBundleService struct {
+	domain.Service
+	bundle  *domain.ConfigBundle
+	applied *domain.ApplyConfigBundleOptions
+}
+
+func (s *fakeConfigBundleService) ExportConfigBundle(_ context.Context, _ *domain.Claims) (*domain.ConfigBundle, error) {
+	return s.bundle, nil
+}
+
+func (s *fakeConfigBundleService) ApplyConfigBundle(_ context.Context, _ *domain.Claims, opt *domain.ApplyConfigBundleOptions) ([]domain.ConfigBundleChange, error) {
+	s.applied = opt
+	return nil, nil
+}
+
+func newConfigBundleRequest(h *Handler, method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := h.SetClaimsInContext(r.Context(), domain.Claims{UID: bson.NewObjectID().Hex()})
+	ctx = h.SetRolePolicyInContext(ctx, domain.RolePolicy{K8SNamespace: domain.AllK8SNamespaces})
+	return r.WithContext(ctx)
+}
+
+func TestExportThenApplyConfigBundleRoundTrips(t *testing.T) {
+	strategyID := bson.NewObjectID()
+	psmID := bson.NewObjectID()
+	svc := &fakeConfigBundleService{bundle: &domain.ConfigBundle{
+		Strategies: []*domain.ScheduleStrategy{{
+			BaseEntity:     domain.BaseEntity{ID: strategyID, CreatedTime: 100},
+			LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "web"}},
+			K8sNamespace:   []string{"default"},
+			Priority:       10,
+			Schedule:       &domain.StrategySchedule{Windows: []domain.ActivationWindow{{Start: "09:00", End: "18:00"}}},
+		}},
+		PodSchedulingMetrics: []*domain.PodSchedulingMetrics{{BaseEntity: domain.BaseEntity{ID: psmID}, Enabled: false}},
+		Roles:                []*domain.Role{{Name: "admin", Policies: []domain.RolePolicy{{PermissionKey: domain.ConfigBundleApply}}}},
+		NodeRuntimeConfigs:   []*domain.NodeRuntimeConfig{{NodeID: "node-a", Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v1", Mode: domain.SchedulerModeNone}}},
+	}}
+	h := &Handler{Svc: svc}
+
+	w := httptest.NewRecorder()
+	h.ExportConfigBundle(w, newConfigBundleRequest(h, http.MethodGet, "/api/v1/export", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var exported ConfigBundle
+	if err := yaml.UnmarshalStrict(w.Body.Bytes(), &exported); err != nil {
+		t.Fatalf("decode export: %v\n%s", err, w.Body.String())
+	}
+	if exported.APIVersion != domain.ConfigBundleAPIVersion || exported.Kind != domain.ConfigBundleKind {
+		t.Fatalf("unexpected header %s/%s", exported.APIVersion, exported.Kind)
+	}
+
+	body := w.Body.String()
+	w = httptest.NewRecorder()
+	h.ApplyConfigBundle(w, newConfigBundleRequest(h, http.MethodPost, "/api/v1/apply?prune=true&dryRun=true&skipRollout=true", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("apply: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	opt := svc.applied
+	if !opt.Prune || !opt.DryRun || !opt.SkipRollout {
+		t.Fatalf("expected prune, dry run and skip rollout, got %+v", opt)
+	}
+	if len(opt.Bundle.Strategies) != 1 || opt.Bundle.Strategies[0].ID != strategyID || opt.Bundle.Strategies[0].Schedule == nil {
+		t.Fatalf("unexpected strategies %+v", opt.Bundle.Strategies)
+	}
+	if len(opt.Bundle.PodSchedulingMetrics) != 1 || opt.Bundle.PodSchedulingMetrics[0].ID != psmID || opt.Bundle.PodSchedulingMetrics[0].Enabled {
+		t.Fatalf("unexpected pod scheduling metrics %+v", opt.Bundle.PodSchedulingMetrics)
+	}
+	if len(opt.Bundle.Roles) != 1 || opt.Bundle.Roles[0].Policies[0].PermissionKey != domain.ConfigBundleApply {
+		t.Fatalf("unexpected roles %+v", opt.Bundle.Roles)
+	}
+	if len(opt.Bundle.NodeRuntimeConfigs) != 1 || opt.Bundle.NodeRuntimeConfigs[0].Config.ConfigVersion != "v1" {
+		t.Fatalf("unexpected runtime configs %+v", opt.Bundle.NodeRuntimeConfigs)
+	}
+}
+
+func TestApplyConfigBundleRejectsInvalidBundles(t *testing.T) {
+	for name, body := range map[string]string{
+		"wrong kind":    "apiVersion: gthulhu.io/v1\nkind: Something\n",
+		"unknown field": "apiVersion: gthulhu.io/v1\nkind: ConfigBundle\nstrategy: []\n",
+		"missing id":    "apiVersion: gthulhu.io/v1\nkind: ConfigBundle\nstrategies:\n- priority: 1\n",
+	} {
+		svc := &fakeConfigBundleService{}
+		h := &Handler{Svc: svc}
+		w := httptest.NewRecorder()
+		h.ApplyConfigBundle(w, newConfigBundleRequest(h, http.MethodPost, "/api/v1/apply", body))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+		if svc.applied != nil {
+			t.Fatalf("%s: bundle must not be applied", name)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+// PulledIntent is a scheduling intent as decision makers in pull mode
+// receive it. It has the fields of the intents the manager pushes to
+// POST /api/v1/intents on decision makers.
+type PulledIntent struct {
+	IntentID      string            `json:"intentID,omitempty"`
+	StrategyID    string            `json:"strategyID,omitempty"`
+	PodName       string            `json:"podName,omitempty"`
+	PodID         string            `json:"podID,omitempty"`
+	NodeID        string            `json:"nodeID,omitempty"`
+	K8sNamespace  string            `json:"k8sNamespace,omitempty"`
+	CommandRegex  string            `json:"commandRegex,omitempty"`
+	Priority      int               `json:"priority,omitempty"`
+	ExecutionTime int64             `json:"executionTime,omitempty"`
+	PodLabels     map[string]string `json:"podLabels,omitempty"`
+	Precedence    int               `json:"precedence,omitempty"`
+	Specificity   int               `json:"specificity,omitempty"`
+}
+
+type PullIntentsResponse struct {
+	Intents []PulledIntent `json:"intents"`
+}
+
+// PulledNodePolicy is a node scheduling intent as decision makers in pull
+// mode receive it.
+type PulledNodePolicy struct {
+	PolicyID      string `json:"policyID,omitempty"`
+	NodeID        string `json:"nodeID,omitempty"`
+	CommandRegex  string `json:"commandRegex,omitempty"`
+	Priority      int    `json:"priority,omitempty"`
+	ExecutionTime int64  `json:"executionTime,omitempty"`
+	Precedence    int    `json:"precedence,omitempty"`
+}
+
+type PullNodePoliciesResponse struct {
+	Policies []PulledNodePolicy `json:"policies"`
+}
+
+type decisionMakerPuller interface {
+	VerifyDecisionMakerPullToken(token string) (string, error)
+	PullNodeIntents(ctx context.Context, nodeID, etag string) (*domain.PullIntentsResult, error)
+	PullNodeSchedulingIntents(ctx context.Context, nodeID, etag string) (*domain.PullNodeIntentsResult, error)
+	PullNodeRuntimeConfig(ctx context.Context, nodeID, etag string) (*domain.PullRuntimeConfigResult, error)
+}
+
+// decisionMakerPuller returns the service and the node the request pulls
+// for if the service supports pull mode and the request carries a valid
+// pull token, writing the error response otherwise.
+func (h *Handler) decisionMakerPuller(w http.ResponseWriter, r *http.Request) (decisionMakerPuller, string, bool) {
+	ctx := r.Context()
+	svc, ok := h.Svc.(decisionMakerPuller)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Decision maker pull mode is not enabled", nil)
+		return nil, "", false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Missing pull token", nil)
+		return nil, "", false
+	}
+	nodeID, err := svc.VerifyDecisionMakerPullToken(token)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return nil, "", false
+	}
+	return svc, nodeID, true
+}
+
+// ifNoneMatch returns the entity tag of the If-None-Match header without
+// quotes.
+func ifNoneMatch(r *http.Request) string {
+	etag := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	etag = strings.TrimPrefix(etag, "W/")
+	return strings.Trim(etag, `"`)
+}
+
+// writeNotModified sets the ETag header and reports whether the response is
+// 304 Not Modified, in which case it is written.
+func writeNotModified(w http.ResponseWriter, etag string, notModified bool) bool {
+	if etag != "" {
+		w.Header().Set("ETag", `"`+etag+`"`)
+	}
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// PullIntents godoc
+// @Summary Pull the scheduling intents of a node
+// @Description Returns the scheduling intents of the node the decision maker's pull token was signed for. The ETag is their Merkle root; a decision maker that sends it in If-None-Match gets 304 Not Modified, which acknowledges the intents.
+// @Tags DecisionMakers
+// @Produce json
+// @Param If-None-Match header string false "Merkle root of the intents the decision maker holds"
+// @Success 200 {object} SuccessResponse[PullIntentsResponse]
+// @Success 304
+// @Failure 401 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/decisionmakers/pull/intents [get]
+func (h *Handler) PullIntents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, nodeID, ok := h.decisionMakerPuller(w, r)
+	if !ok {
+		return
+	}
+	result, err := svc.PullNodeIntents(ctx, nodeID, ifNoneMatch(r))
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	if writeNotModified(w, result.ETag, result.NotModified) {
+		return
+	}
+
+	resp := &PullIntentsResponse{Intents: make([]PulledIntent, 0, len(result.Intents))}
+	for _, intent := range result.Intents {
+		resp.Intents = append(resp.Intents, PulledIntent{
+			IntentID:      intent.ID.Hex(),
+			StrategyID:    intent.StrategyID.Hex(),
+			PodName:       intent.PodName,
+			PodID:         intent.PodID,
+			NodeID:        intent.NodeID,
+			K8sNamespace:  intent.K8sNamespace,
+			CommandRegex:  intent.CommandRegex,
+			Priority:      intent.Priority,
+			ExecutionTime: intent.ExecutionTime,
+			PodLabels:     intent.PodLabels,
+			Precedence:    intent.Precedence,
+			Specificity:   intent.Specificity,
+		})
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+// PullNodePolicies godoc
+// @Summary Pull the node scheduling intents of a node
+// @Description Returns the node scheduling intents of the node the decision maker's pull token was signed for, with their Merkle root as ETag.
+// @Tags DecisionMakers
+// @Produce json
+// @Param If-None-Match header string false "Merkle root of the node policies the decision maker holds"
+// @Success 200 {object} SuccessResponse[PullNodePoliciesResponse]
+// @Success 304
+// @Failure 401 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/decisionmakers/pull/node-intents [get]
+func (h *Handler) PullNodePolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, nodeID, ok := h.decisionMakerPuller(w, r)
+	if !ok {
+		return
+	}
+	result, err := svc.PullNodeSchedulingIntents(ctx, nodeID, ifNoneMatch(r))
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	if writeNotModified(w, result.ETag, result.NotModified) {
+		return
+	}
+
+	resp := &PullNodePoliciesResponse{Policies: make([]PulledNodePolicy, 0, len(result.Intents))}
+	for _, intent := range result.Intents {
+		resp.Policies = append(resp.Policies, PulledNodePolicy{
+			PolicyID:      intent.PolicyID.Hex(),
+			NodeID:        intent.NodeID,
+			CommandRegex:  intent.CommandRegex,
+			Priority:      intent.Priority,
+			ExecutionTime: intent.ExecutionTime,
+			Precedence:    intent.Precedence,
+		})
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+// PullRuntimeConfig godoc
+// @Summary Pull the runtime config of a node
+// @Description Returns the runtime config last applied to the node the decision maker's pull token was signed for, with its version as ETag. Returns 204 No Content when none was applied.
+// @Tags DecisionMakers
+// @Produce json
+// @Param If-None-Match header string false "Version of the runtime config the decision maker runs"
+// @Success 200 {object} SuccessResponse[domain.RuntimeSchedulerConfig]
+// @Success 204
+// @Success 304
+// @Failure 401 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/decisionmakers/pull/runtime-config [get]
+func (h *Handler) PullRuntimeConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, nodeID, ok := h.decisionMakerPuller(w, r)
+	if !ok {
+		return
+	}
+	result, err := svc.PullNodeRuntimeConfig(ctx, nodeID, ifNoneMatch(r))
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	if writeNotModified(w, result.ETag, result.NotModified) {
+		return
+	}
+	if result.Config == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(result.Config))
+}
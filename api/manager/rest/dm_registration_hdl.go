@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+// RegisterDecisionMakerRequest is the request body decision makers send to
+// POST /api/v1/decisionmakers/registrations on startup and as heartbeat.
+type RegisterDecisionMakerRequest struct {
+	NodeID       string                           `json:"nodeID"`
+	Host         string                           `json:"host"`
+	Port         int                              `json:"port"`
+	Version      string                           `json:"version,omitempty"`
+	Capabilities domain.DecisionMakerCapabilities `json:"capabilities"`
+}
+
+// RegisterDecisionMakerResponse tells the decision maker how long its
+// registration stays valid without another heartbeat.
+type RegisterDecisionMakerResponse struct {
+	HeartbeatTTLSeconds int `json:"heartbeatTTLSeconds"`
+}
+
+type decisionMakerRegistrar interface {
+	VerifyDecisionMakerRegistrationToken(token string) error
+	RegisterDecisionMaker(ctx context.Context, reg *domain.DecisionMakerRegistration) (time.Duration, error)
+	DeregisterDecisionMaker(ctx context.Context, nodeID string) error
+}
+
+// decisionMakerRegistrar returns the service if it supports registration and
+// the request carries a valid registration token, writing the error response
+// otherwise. Decision makers authenticate with the shared registration token
+// instead of a user token.
+func (h *Handler) decisionMakerRegistrar(w http.ResponseWriter, r *http.Request) (decisionMakerRegistrar, bool) {
+	ctx := r.Context()
+	svc, ok := h.Svc.(decisionMakerRegistrar)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Decision maker registration is not enabled", nil)
+		return nil, false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Missing registration token", nil)
+		return nil, false
+	}
+	if err := svc.VerifyDecisionMakerRegistrationToken(token); err != nil {
+		h.HandleError(ctx, w, err)
+		return nil, false
+	}
+	return svc, true
+}
+
+// RegisterDecisionMaker godoc
+// @Summary Register a decision maker
+// @Description Registers the decision maker of a node or refreshes its heartbeat. Decision makers authenticate with the shared registration token.
+// @Tags DecisionMakers
+// @Accept json
+// @Produce json
+// @Param request body RegisterDecisionMakerRequest true "Decision maker registration"
+// @Success 200 {object} SuccessResponse[RegisterDecisionMakerResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/decisionmakers/registrations [post]
+func (h *Handler) RegisterDecisionMaker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, ok := h.decisionMakerRegistrar(w, r)
+	if !ok {
+		return
+	}
+
+	var req RegisterDecisionMakerRequest
+	if err := h.JSONBind(r, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	ttl, err := svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{
+		NodeID:       req.NodeID,
+		Host:         req.Host,
+		Port:         req.Port,
+		Version:      req.Version,
+		Capabilities: req.Capabilities,
+	})
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&RegisterDecisionMakerResponse{
+		HeartbeatTTLSeconds: int(ttl / time.Second),
+	}))
+}
+
+// DeregisterDecisionMaker godoc
+// @Summary Deregister a decision maker
+// @Description Removes the registration of a node's decision maker, e.g. when it shuts down.
+// @Tags DecisionMakers
+// @Produce json
+// @Param nodeID path string true "Node ID"
+// @Success 200 {object} SuccessResponse[EmptyResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/decisionmakers/registrations/{nodeID} [delete]
+func (h *Handler) DeregisterDecisionMaker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, ok := h.decisionMakerRegistrar(w, r)
+	if !ok {
+		return
+	}
+
+	if err := svc.DeregisterDecisionMaker(ctx, h.GetPathParam(r, "nodeID")); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse[EmptyResponse](nil))
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/netip"
 	"time"
 
+	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/manager/domain"
 	"github.com/Gthulhu/api/manager/errs"
 	"github.com/Gthulhu/api/pkg/logger"
@@ -31,9 +34,19 @@ type VersionResponse struct {
 
 // HealthResponse describes the health check payload.
 type HealthResponse struct {
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Service   string `json:"service"`
+	Status         string                  `json:"status"`
+	Timestamp      string                  `json:"timestamp"`
+	Service        string                  `json:"service"`
+	LeaderElection *LeaderElectionResponse `json:"leaderElection,omitempty"`
+}
+
+// LeaderElectionResponse is the leader election state of the replica that
+// answered the health check.
+type LeaderElectionResponse struct {
+	Enabled  bool   `json:"enabled"`
+	Identity string `json:"identity,omitempty"`
+	Leader   string `json:"leader,omitempty"`
+	IsLeader bool   `json:"isLeader"`
 }
 
 func NewSuccessResponse[T any](data *T) SuccessResponse[T] {
@@ -53,19 +66,32 @@ type SuccessResponse[T any] struct {
 
 type Params struct {
 	fx.In
-	Svc domain.Service
+	Svc           domain.Service
+	ClassifierCfg config.ClassifierConfig `optional:"true"`
+	ServerCfg     config.ServerConfig     `optional:"true"`
+	Leadership    *domain.Leadership      `optional:"true"`
 }
 
 func NewHandler(params Params) (*Handler, error) {
+	trustedProxies, err := params.ServerCfg.TrustedProxyPrefixes()
+	if err != nil {
+		return nil, err
+	}
 	return &Handler{
-		Svc:        params.Svc,
-		classifier: NewAdaptiveClassifier(5),
+		Svc:            params.Svc,
+		classifier:     NewShardedAdaptiveClassifier(5, params.ClassifierCfg.ShardCount),
+		classifierCfg:  params.ClassifierCfg,
+		trustedProxies: trustedProxies,
+		leadership:     params.Leadership,
 	}, nil
 }
 
 type Handler struct {
-	Svc        domain.Service
-	classifier *AdaptiveClassifier
+	Svc            domain.Service
+	classifier     *AdaptiveClassifier
+	classifierCfg  config.ClassifierConfig
+	trustedProxies []netip.Prefix
+	leadership     *domain.Leadership
 }
 
 func (h *Handler) JSONResponse(ctx context.Context, w http.ResponseWriter, status int, data any) {
@@ -129,7 +155,7 @@ func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
 
 // HealthCheck godoc
 // @Summary Health check
-// @Description Basic health check for readiness probes.
+// @Description Basic health check for readiness probes. Followers are healthy too; leaderElection tells whether this replica runs the background loops.
 // @Tags System
 // @Produce json
 // @Success 200 {object} HealthResponse
@@ -140,6 +166,15 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Service:   "BSS Metrics API Server",
 	}
+	if h.leadership != nil {
+		status := h.leadership.Status()
+		response.LeaderElection = &LeaderElectionResponse{
+			Enabled:  status.Enabled,
+			Identity: status.Identity,
+			Leader:   status.Leader,
+			IsLeader: status.IsLeader,
+		}
+	}
 	h.JSONResponse(r.Context(), w, http.StatusOK, response)
 }
 
@@ -155,15 +190,12 @@ func (h *Handler) SetClaimsInContext(ctx context.Context, claims domain.Claims)
 	return context.WithValue(ctx, claimsKey{}, claims)
 }
 
-type rolePolicyKey struct{}
-
 func (h *Handler) SetRolePolicyInContext(ctx context.Context, rolePolicy domain.RolePolicy) context.Context {
-	return context.WithValue(ctx, rolePolicyKey{}, rolePolicy)
+	return domain.WithRolePolicy(ctx, rolePolicy)
 }
 
 func (h *Handler) GetRolePolicyFromContext(ctx context.Context) (domain.RolePolicy, bool) {
-	rolePolicy, ok := ctx.Value(rolePolicyKey{}).(domain.RolePolicy)
-	return rolePolicy, ok
+	return domain.RolePolicyFromContext(ctx)
 }
 
 func (h *Handler) VerifyResourcePolicy(ctx context.Context, resourceOwnerID string) error {
@@ -180,3 +212,30 @@ func (h *Handler) VerifyResourcePolicy(ctx context.Context, resourceOwnerID stri
 	}
 	return nil
 }
+
+// VerifyK8SNamespacePolicy rejects requests that target Kubernetes namespaces
+// outside the caller's role policy. An empty namespaces list selects every
+// namespace and is only allowed to unrestricted policies.
+func (h *Handler) VerifyK8SNamespacePolicy(ctx context.Context, namespaces []string) error {
+	rolePolicy, ok := h.GetRolePolicyFromContext(ctx)
+	if !ok {
+		return errs.NewHTTPStatusError(http.StatusUnauthorized, "unauthorized", errors.New("role policy not found in context"))
+	}
+	if !rolePolicy.AllowsK8SNamespaces(namespaces) {
+		return errs.NewHTTPStatusError(http.StatusForbidden, "forbidden", fmt.Errorf("namespaces %v are outside the allowed namespaces %v", namespaces, rolePolicy.AllowedK8SNamespaces()))
+	}
+	return nil
+}
+
+// k8sNamespaceFilter returns a predicate selecting the list results whose
+// namespaces fall inside the caller's role policy. Without a policy in the
+// context nothing is visible.
+func (h *Handler) k8sNamespaceFilter(ctx context.Context) func(namespaces ...string) bool {
+	rolePolicy, ok := h.GetRolePolicyFromContext(ctx)
+	if !ok {
+		return func(...string) bool { return false }
+	}
+	return func(namespaces ...string) bool {
+		return rolePolicy.AllowsK8SNamespaces(namespaces)
+	}
+}
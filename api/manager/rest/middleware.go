@@ -2,8 +2,11 @@ package rest
 
 import (
 	"bytes"
+	"net"
 	"net/http"
+	"net/netip"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
@@ -43,7 +46,7 @@ func (h *Handler) GetAuthMiddleware(permissionKey domain.PermissionKey) func(nex
 	}
 }
 
-func LoggerMiddleware(next http.Handler) http.Handler {
+func (h *Handler) LoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		ctx := r.Context()
@@ -64,6 +67,7 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 		}()
 
 		ctx = log.WithContext(ctx)
+		ctx = domain.WithRequestInfo(ctx, domain.RequestInfo{RequestID: reqID, IP: clientIP(r, h.trustedProxies)})
 		r = r.WithContext(ctx)
 		responseWriter := NewResponseWriter(w)
 		next.ServeHTTP(responseWriter, r)
@@ -110,3 +114,47 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.responseBody.Write(b)
 	return rw.ResponseWriter.Write(b)
 }
+
+// clientIP returns the address of the client that sent r. X-Forwarded-For is
+// only believed when the request comes from a trusted proxy: the chain is
+// walked from the right, past every trusted proxy, to the first address a
+// trusted proxy saw, so a client cannot forge its address by sending the
+// header itself.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host, trustedProxies) {
+		return host
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !isTrustedProxy(hops[i], trustedProxies) {
+			return hops[i]
+		}
+		host = hops[i]
+	}
+	return host
+}
+
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+func TestRolePolicyAllowsK8SNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		namespaces []string
+		want       bool
+	}{
+		{name: "empty policy is unrestricted", policy: "", namespaces: []string{"prod"}, want: true},
+		{name: "wildcard is unrestricted", policy: "*", namespaces: nil, want: true},
+		{name: "listed namespace", policy: "team-a, team-b", namespaces: []string{"team-b"}, want: true},
+		{name: "all listed namespaces", policy: "team-a,team-b", namespaces: []string{"team-a", "team-b"}, want: true},
+		{name: "namespace outside the list", policy: "team-a", namespaces: []string{"team-a", "prod"}, want: false},
+		{name: "every namespace needs an unrestricted policy", policy: "team-a", namespaces: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := domain.RolePolicy{K8SNamespace: tt.policy}
+			if got := policy.AllowsK8SNamespaces(tt.namespaces); got != tt.want {
+				t.Fatalf("AllowsK8SNamespaces(%v) with policy %q = %v, want %v", tt.namespaces, tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
+func newNamespacePolicyTestHandler() *Handler {
+	h := &Handler{classifier: NewAdaptiveClassifier(2)}
+	for _, ns := range []string{"team-a", "team-b"} {
+		h.classifier.Ingest(classificationInput{Timestamp: time.Now().Unix(), Namespace: ns, Pod: "pod"})
+	}
+	return h
+}
+
+func TestListPodClassificationsFiltersByNamespacePolicy(t *testing.T) {
+	h := newNamespacePolicyTestHandler()
+	ctx := h.SetRolePolicyInContext(context.Background(), domain.RolePolicy{K8SNamespace: "team-a"})
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/classify", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	h.ListPodClassifications(w, r)
+
+	var resp SuccessResponse[listClassifyResponse]
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Data.Items) != 1 || resp.Data.Items[0].Namespace != "team-a" {
+		t.Fatalf("expected only the team-a classification, got %+v", resp.Data.Items)
+	}
+}
+
+func TestIngestPodMetricsRejectsNamespaceOutsidePolicy(t *testing.T) {
+	h := newNamespacePolicyTestHandler()
+	ctx := h.SetRolePolicyInContext(context.Background(), domain.RolePolicy{K8SNamespace: "team-a"})
+	body, _ := json.Marshal(ingestMetricsRequest{Namespace: "team-b", Pod: "pod"})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/metrics", bytes.NewReader(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	h.IngestPodMetrics(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -19,6 +19,10 @@ type CreateNodeSchedulingPolicyRequest struct {
 	CommandRegex  string               `json:"commandRegex,omitempty"`
 	Priority      int                  `json:"priority,omitempty"`
 	ExecutionTime int64                `json:"executionTime,omitempty"`
+	Precedence    int                  `json:"precedence,omitempty"`
+	// ClusterSelector names the clusters whose nodes the policy matches;
+	// omit it to match the nodes of every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
 }
 
 type UpdateNodeSchedulingPolicyRequest struct {
@@ -29,6 +33,10 @@ type UpdateNodeSchedulingPolicyRequest struct {
 	CommandRegex  string               `json:"commandRegex,omitempty"`
 	Priority      int                  `json:"priority,omitempty"`
 	ExecutionTime int64                `json:"executionTime,omitempty"`
+	Precedence    int                  `json:"precedence,omitempty"`
+	// ClusterSelector names the clusters whose nodes the policy matches;
+	// omit it to match the nodes of every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
 }
 
 func convertRequestDRASelectorsToDomain(selectors []DRASelectorPayload) []domain.DRASelector {
@@ -77,12 +85,14 @@ func (h *Handler) CreateNodeSchedulingPolicy(w http.ResponseWriter, r *http.Requ
 	}
 
 	policy := &domain.NodeSchedulingPolicy{
-		NodeSelectors: convertRequestLabelSelectorsToDomain(req.NodeSelectors),
-		NodeNames:     req.NodeNames,
-		DRASelectors:  convertRequestDRASelectorsToDomain(req.DRASelectors),
-		CommandRegex:  req.CommandRegex,
-		Priority:      req.Priority,
-		ExecutionTime: req.ExecutionTime,
+		NodeSelectors:   convertRequestLabelSelectorsToDomain(req.NodeSelectors),
+		NodeNames:       req.NodeNames,
+		DRASelectors:    convertRequestDRASelectorsToDomain(req.DRASelectors),
+		CommandRegex:    req.CommandRegex,
+		Priority:        req.Priority,
+		ExecutionTime:   req.ExecutionTime,
+		Precedence:      req.Precedence,
+		ClusterSelector: req.ClusterSelector,
 	}
 
 	claims, ok := h.GetClaimsFromContext(ctx)
@@ -128,12 +138,14 @@ func (h *Handler) UpdateNodeSchedulingPolicy(w http.ResponseWriter, r *http.Requ
 	}
 
 	policy := &domain.NodeSchedulingPolicy{
-		NodeSelectors: convertRequestLabelSelectorsToDomain(req.NodeSelectors),
-		NodeNames:     req.NodeNames,
-		DRASelectors:  convertRequestDRASelectorsToDomain(req.DRASelectors),
-		CommandRegex:  req.CommandRegex,
-		Priority:      req.Priority,
-		ExecutionTime: req.ExecutionTime,
+		NodeSelectors:   convertRequestLabelSelectorsToDomain(req.NodeSelectors),
+		NodeNames:       req.NodeNames,
+		DRASelectors:    convertRequestDRASelectorsToDomain(req.DRASelectors),
+		CommandRegex:    req.CommandRegex,
+		Priority:        req.Priority,
+		ExecutionTime:   req.ExecutionTime,
+		Precedence:      req.Precedence,
+		ClusterSelector: req.ClusterSelector,
 	}
 
 	claims, ok := h.GetClaimsFromContext(ctx)
@@ -159,6 +171,10 @@ type NodeSchedulingPolicyResponse struct {
 	CommandRegex  string               `json:"commandRegex,omitempty"`
 	Priority      int                  `json:"priority,omitempty"`
 	ExecutionTime int64                `json:"executionTime,omitempty"`
+	Precedence    int                  `json:"precedence,omitempty"`
+	// ClusterSelector names the clusters whose nodes the policy matches;
+	// omit it to match the nodes of every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
 }
 
 func convertDomainNodePolicyToResponse(p *domain.NodeSchedulingPolicy) *NodeSchedulingPolicyResponse {
@@ -170,13 +186,15 @@ func convertDomainNodePolicyToResponse(p *domain.NodeSchedulingPolicy) *NodeSche
 		}
 	}
 	return &NodeSchedulingPolicyResponse{
-		ID:            p.ID,
-		NodeSelectors: convertDomainLabelSelectorsToResponseLabelSelectors(p.NodeSelectors),
-		NodeNames:     p.NodeNames,
-		DRASelectors:  attrs,
-		CommandRegex:  p.CommandRegex,
-		Priority:      p.Priority,
-		ExecutionTime: p.ExecutionTime,
+		ID:              p.ID,
+		NodeSelectors:   convertDomainLabelSelectorsToResponseLabelSelectors(p.NodeSelectors),
+		NodeNames:       p.NodeNames,
+		DRASelectors:    attrs,
+		CommandRegex:    p.CommandRegex,
+		Priority:        p.Priority,
+		ExecutionTime:   p.ExecutionTime,
+		Precedence:      p.Precedence,
+		ClusterSelector: p.ClusterSelector,
 	}
 }
 
@@ -232,11 +250,14 @@ func (h *Handler) ListSelfNodeSchedulingPolicies(w http.ResponseWriter, r *http.
 type NodeSchedulingIntentResponse struct {
 	ID            bson.ObjectID      `json:"id"`
 	PolicyID      bson.ObjectID      `json:"policyId"`
+	Cluster       string             `json:"cluster,omitempty"`
 	NodeID        string             `json:"nodeId"`
 	CommandRegex  string             `json:"commandRegex,omitempty"`
 	Priority      int                `json:"priority,omitempty"`
 	ExecutionTime int64              `json:"executionTime,omitempty"`
+	Precedence    int                `json:"precedence,omitempty"`
 	State         domain.IntentState `json:"state,omitempty"`
+	Overrides     []IntentOverride   `json:"overrides,omitempty"`
 }
 
 type ListNodeSchedulingIntentsResponse struct {
@@ -285,11 +306,14 @@ func (h *Handler) ListSelfNodeSchedulingIntents(w http.ResponseWriter, r *http.R
 		resp.Intents[i] = &NodeSchedulingIntentResponse{
 			ID:            in.ID,
 			PolicyID:      in.PolicyID,
+			Cluster:       in.Cluster,
 			NodeID:        in.NodeID,
 			CommandRegex:  in.CommandRegex,
 			Priority:      in.Priority,
 			ExecutionTime: in.ExecutionTime,
+			Precedence:    in.Precedence,
 			State:         in.State,
+			Overrides:     convertDomainIntentOverrides(in.Overrides),
 		}
 	}
 	response := NewSuccessResponse[ListNodeSchedulingIntentsResponse](&resp)
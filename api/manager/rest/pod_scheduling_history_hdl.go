@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+type PodSchedulingMetricHistoryPoint struct {
+	Timestamp              int64  `json:"timestamp"`
+	VoluntaryCtxSwitches   uint64 `json:"voluntaryCtxSwitches"`
+	InvoluntaryCtxSwitches uint64 `json:"involuntaryCtxSwitches"`
+	CPUTimeNs              uint64 `json:"cpuTimeNs"`
+	WaitTimeNs             uint64 `json:"waitTimeNs"`
+	RunCount               uint64 `json:"runCount"`
+	CPUMigrations          uint64 `json:"cpuMigrations"`
+	SMTMigrations          uint64 `json:"smtMigrations"`
+	L3Migrations           uint64 `json:"l3Migrations"`
+	NUMAMigrations         uint64 `json:"numaMigrations"`
+}
+
+type PodSchedulingMetricHistoryResponse struct {
+	Namespace   string                             `json:"namespace"`
+	PodName     string                             `json:"podName"`
+	From        int64                              `json:"from"`
+	To          int64                              `json:"to"`
+	StepSeconds int64                              `json:"stepSeconds"`
+	Points      []*PodSchedulingMetricHistoryPoint `json:"points"`
+}
+
+// GetPodSchedulingMetricHistory godoc
+// @Summary Get the scheduling metric history of a pod
+// @Description Returns how much each scheduling counter of the pod grew per step. The manager keeps 10s steps for 24 hours and 5m steps for 30 days.
+// @Tags PodSchedulingMetrics
+// @Produce json
+// @Security BearerAuth
+// @Param namespace query string true "Pod namespace"
+// @Param pod query string true "Pod name"
+// @Param from query int false "Start as Unix time in milliseconds (default: one hour before to)"
+// @Param to query int false "End as Unix time in milliseconds (default: now)"
+// @Param step query string false "Step as a duration, e.g. 30s or 5m (default: fits the range in 2000 points)"
+// @Success 200 {object} SuccessResponse[PodSchedulingMetricHistoryResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/pod-scheduling-metrics/history [get]
+func (h *Handler) GetPodSchedulingMetricHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	queryOpt := &domain.QueryPodSchedulingMetricHistoryOptions{
+		Namespace: strings.TrimSpace(query.Get("namespace")),
+		PodName:   strings.TrimSpace(query.Get("pod")),
+	}
+	if queryOpt.Namespace == "" || queryOpt.PodName == "" {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "namespace and pod are required", nil)
+		return
+	}
+	var err error
+	if queryOpt.From, err = parseQueryInt(query.Get("from"), 0); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid from", err)
+		return
+	}
+	if queryOpt.To, err = parseQueryInt(query.Get("to"), 0); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid to", err)
+		return
+	}
+	if step := strings.TrimSpace(query.Get("step")); step != "" {
+		if queryOpt.Step, err = time.ParseDuration(step); err != nil || queryOpt.Step <= 0 {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid step", err)
+			return
+		}
+	}
+	if err := h.VerifyK8SNamespacePolicy(ctx, []string{queryOpt.Namespace}); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	svc, ok := h.Svc.(interface {
+		QueryPodSchedulingMetricHistory(ctx context.Context, opt *domain.QueryPodSchedulingMetricHistoryOptions) error
+	})
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Pod scheduling metric history is not enabled", nil)
+		return
+	}
+	if err := svc.QueryPodSchedulingMetricHistory(ctx, queryOpt); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	resp := &PodSchedulingMetricHistoryResponse{
+		Namespace:   queryOpt.Namespace,
+		PodName:     queryOpt.PodName,
+		From:        queryOpt.From,
+		To:          queryOpt.To,
+		StepSeconds: int64(queryOpt.Step / time.Second),
+		Points:      make([]*PodSchedulingMetricHistoryPoint, 0, len(queryOpt.Result)),
+	}
+	for _, point := range queryOpt.Result {
+		resp.Points = append(resp.Points, &PodSchedulingMetricHistoryPoint{
+			Timestamp:              point.Timestamp,
+			VoluntaryCtxSwitches:   point.VoluntaryCtxSwitches,
+			InvoluntaryCtxSwitches: point.InvoluntaryCtxSwitches,
+			CPUTimeNs:              point.CPUTimeNs,
+			WaitTimeNs:             point.WaitTimeNs,
+			RunCount:               point.RunCount,
+			CPUMigrations:          point.CPUMigrations,
+			SMTMigrations:          point.SMTMigrations,
+			L3Migrations:           point.L3Migrations,
+			NUMAMigrations:         point.NUMAMigrations,
+		})
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
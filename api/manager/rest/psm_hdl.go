@@ -35,11 +35,18 @@ type PSMScalingDTO struct {
 	MetricName      string                `json:"metricName,omitempty"`
 	TargetValue     string                `json:"targetValue,omitempty"`
 	ScaleTargetRef  *PSMScaleTargetRefDTO `json:"scaleTargetRef,omitempty"`
-	MinReplicaCount int32                 `json:"minReplicaCount,omitempty"`
+	MinReplicaCount *int32                `json:"minReplicaCount,omitempty"`
 	MaxReplicaCount int32                 `json:"maxReplicaCount,omitempty"`
 	CooldownPeriod  int32                 `json:"cooldownPeriod,omitempty"`
 }
 
+type PSMScalingStatusDTO struct {
+	Ready              bool     `json:"ready"`
+	ScaledObjects      []string `json:"scaledObjects,omitempty"`
+	Message            string   `json:"message,omitempty"`
+	LastTransitionTime int64    `json:"lastTransitionTime,omitempty"`
+}
+
 type CreatePSMRequest struct {
 	LabelSelectors            []PSMLabelSelector `json:"labelSelectors"`
 	K8sNamespaces             []string           `json:"k8sNamespaces,omitempty"`
@@ -66,16 +73,17 @@ type DeletePSMRequest struct {
 }
 
 type PSMResponseItem struct {
-	ID                        string             `json:"id"`
-	LabelSelectors            []PSMLabelSelector `json:"labelSelectors"`
-	K8sNamespaces             []string           `json:"k8sNamespaces,omitempty"`
-	CommandRegex              string             `json:"commandRegex,omitempty"`
-	CollectionIntervalSeconds int32              `json:"collectionIntervalSeconds"`
-	Enabled                   bool               `json:"enabled"`
-	Metrics                   *PSMMetricsDTO     `json:"metrics,omitempty"`
-	Scaling                   *PSMScalingDTO     `json:"scaling,omitempty"`
-	CreatedTime               int64              `json:"createdTime,omitempty"`
-	UpdatedTime               int64              `json:"updatedTime,omitempty"`
+	ID                        string               `json:"id"`
+	LabelSelectors            []PSMLabelSelector   `json:"labelSelectors"`
+	K8sNamespaces             []string             `json:"k8sNamespaces,omitempty"`
+	CommandRegex              string               `json:"commandRegex,omitempty"`
+	CollectionIntervalSeconds int32                `json:"collectionIntervalSeconds"`
+	Enabled                   bool                 `json:"enabled"`
+	Metrics                   *PSMMetricsDTO       `json:"metrics,omitempty"`
+	Scaling                   *PSMScalingDTO       `json:"scaling,omitempty"`
+	ScalingStatus             *PSMScalingStatusDTO `json:"scalingStatus,omitempty"`
+	CreatedTime               int64                `json:"createdTime,omitempty"`
+	UpdatedTime               int64                `json:"updatedTime,omitempty"`
 }
 
 type ListPSMResponse struct {
@@ -119,6 +127,10 @@ func (h *Handler) CreatePodSchedulingMetrics(w http.ResponseWriter, r *http.Requ
 	}
 
 	psm := psmRequestToDomain(&req)
+	if err := h.VerifyK8SNamespacePolicy(ctx, psm.K8sNamespaces); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
 
 	if err := h.Svc.CreatePodSchedulingMetrics(ctx, &claims, psm); err != nil {
 		h.HandleError(ctx, w, err)
@@ -148,11 +160,15 @@ func (h *Handler) ListPodSchedulingMetrics(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	allowed := h.k8sNamespaceFilter(ctx)
 	resp := ListPSMResponse{
-		Items: make([]*PSMResponseItem, len(queryOpt.Result)),
+		Items: make([]*PSMResponseItem, 0, len(queryOpt.Result)),
 	}
-	for i, d := range queryOpt.Result {
-		resp.Items[i] = domainPSMToResponse(d)
+	for _, d := range queryOpt.Result {
+		if !allowed(d.K8sNamespaces...) {
+			continue
+		}
+		resp.Items = append(resp.Items, domainPSMToResponse(d))
 	}
 	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&resp))
 }
@@ -191,6 +207,10 @@ func (h *Handler) UpdatePodSchedulingMetrics(w http.ResponseWriter, r *http.Requ
 	}
 
 	psm := updatePSMRequestToDomain(&req)
+	if err := h.VerifyK8SNamespacePolicy(ctx, psm.K8sNamespaces); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
 
 	if err := h.Svc.UpdatePodSchedulingMetrics(ctx, &claims, req.ID, psm); err != nil {
 		h.HandleError(ctx, w, err)
@@ -359,5 +379,13 @@ func domainPSMToResponse(d *domain.PodSchedulingMetrics) *PSMResponseItem {
 			}
 		}
 	}
+	if d.ScalingStatus != nil {
+		item.ScalingStatus = &PSMScalingStatusDTO{
+			Ready:              d.ScalingStatus.Ready,
+			ScaledObjects:      d.ScalingStatus.ScaledObjects,
+			Message:            d.ScalingStatus.Message,
+			LastTransitionTime: d.ScalingStatus.LastTransitionTime,
+		}
+	}
 	return item
 }
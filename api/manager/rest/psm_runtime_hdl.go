@@ -12,6 +12,7 @@ type PodSchedulingMetricValueItem struct {
 	Namespace              string `json:"namespace"`
 	PodName                string `json:"podName"`
 	NodeID                 string `json:"nodeID,omitempty"`
+	Cluster                string `json:"cluster,omitempty"`
 	VoluntaryCtxSwitches   uint64 `json:"voluntaryCtxSwitches"`
 	InvoluntaryCtxSwitches uint64 `json:"involuntaryCtxSwitches"`
 	CPUTimeNs              uint64 `json:"cpuTimeNs"`
@@ -52,7 +53,11 @@ func (h *Handler) ListPodSchedulingMetricValues(w http.ResponseWriter, r *http.R
 		Items:    make([]*PodSchedulingMetricValueItem, 0, len(result.Items)),
 		Warnings: result.Warnings,
 	}
+	allowed := h.k8sNamespaceFilter(ctx)
 	for _, item := range result.Items {
+		if !allowed(item.Namespace) {
+			continue
+		}
 		resp.Items = append(resp.Items, domainPodSchedulingMetricValueToResponse(item))
 	}
 
@@ -61,7 +66,8 @@ func (h *Handler) ListPodSchedulingMetricValues(w http.ResponseWriter, r *http.R
 
 // IngestMetricsIntoClassifier is called by the background classifier feeder goroutine.
 // It feeds the latest pod scheduling metrics into the adaptive classifier without
-// causing side effects on read endpoints.
+// causing side effects on read endpoints. Pods of classifier shards owned by
+// another replica are skipped; that replica's feeder ingests them.
 func (h *Handler) IngestMetricsIntoClassifier(result *domain.PodSchedulingMetricValuesResult) {
 	if result == nil {
 		return
@@ -71,11 +77,15 @@ func (h *Handler) IngestMetricsIntoClassifier(result *domain.PodSchedulingMetric
 		if item == nil {
 			continue
 		}
+		if owned, _ := h.classifier.Owner(item.Namespace); !owned {
+			continue
+		}
 		h.classifier.Ingest(classificationInput{
 			Timestamp: now,
 			Namespace: strings.TrimSpace(item.Namespace),
 			Pod:       strings.TrimSpace(item.PodName),
 			Node:      strings.TrimSpace(item.NodeID),
+			Cluster:   item.Cluster,
 			Metrics: metricsPayload{
 				VolCtxSW:   item.VoluntaryCtxSwitches,
 				InvolCtxSW: item.InvoluntaryCtxSwitches,
@@ -90,11 +100,31 @@ func (h *Handler) IngestMetricsIntoClassifier(result *domain.PodSchedulingMetric
 	}
 }
 
+// OwnedPodSchedulingMetricValues keeps the metric values of the pods whose
+// classifier shard this replica ingests, so that every pod's history is
+// recorded by a single replica.
+func (h *Handler) OwnedPodSchedulingMetricValues(result *domain.PodSchedulingMetricValuesResult) []*domain.PodSchedulingMetricValue {
+	if result == nil {
+		return nil
+	}
+	values := make([]*domain.PodSchedulingMetricValue, 0, len(result.Items))
+	for _, item := range result.Items {
+		if item == nil {
+			continue
+		}
+		if owned, _ := h.classifier.Owner(item.Namespace); owned {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 func domainPodSchedulingMetricValueToResponse(item *domain.PodSchedulingMetricValue) *PodSchedulingMetricValueItem {
 	return &PodSchedulingMetricValueItem{
 		Namespace:              item.Namespace,
 		PodName:                item.PodName,
 		NodeID:                 item.NodeID,
+		Cluster:                item.Cluster,
 		VoluntaryCtxSwitches:   item.VoluntaryCtxSwitches,
 		InvoluntaryCtxSwitches: item.InvoluntaryCtxSwitches,
 		CPUTimeNs:              item.CPUTimeNs,
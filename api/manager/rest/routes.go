@@ -16,7 +16,7 @@ func (h *Handler) SetupRoutes(engine *echo.Echo) {
 	docs.SwaggerInfo.BasePath = "/"
 	engine.GET("/swagger/*", echoSwagger.WrapHandler)
 
-	api := engine.Group("/api", echo.WrapMiddleware(LoggerMiddleware))
+	api := engine.Group("/api", echo.WrapMiddleware(h.LoggerMiddleware))
 	// v1 routes
 	{
 		apiV1 := api.Group("/v1")
@@ -44,6 +44,7 @@ func (h *Handler) SetupRoutes(engine *echo.Echo) {
 
 		// strategy routes
 		apiV1.POST("/strategies", h.echoHandler(h.CreateScheduleStrategy), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ScheduleStrategyCreate)))
+		apiV1.POST("/strategies/preview", h.echoHandler(h.PreviewScheduleStrategy), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ScheduleStrategyCreate)))
 		apiV1.PUT("/strategies", h.echoHandler(h.UpdateScheduleStrategy), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ScheduleStrategyUpdate)))
 		apiV1.GET("/strategies/self", h.echoHandler(h.ListSelfScheduleStrategies), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ScheduleStrategyRead)))
 		apiV1.DELETE("/strategies", h.echoHandler(h.DeleteScheduleStrategy), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ScheduleStrategyDelete)))
@@ -60,13 +61,24 @@ func (h *Handler) SetupRoutes(engine *echo.Echo) {
 
 		// pod-pid mapping routes
 		apiV1.GET("/nodes", h.echoHandler(h.ListNodes), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PodPIDMappingRead)))
+		apiV1.GET("/clusters", h.echoHandler(h.ListClusters), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PodPIDMappingRead)))
 		apiV1.GET("/nodes/:nodeID/pods/pids", h.echoHandlerWithParams(h.GetNodePodPIDMapping), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PodPIDMappingRead)))
 
+		// decision maker registration routes, authenticated by the registration token
+		apiV1.POST("/decisionmakers/registrations", h.echoHandler(h.RegisterDecisionMaker))
+		apiV1.DELETE("/decisionmakers/registrations/:nodeID", h.echoHandlerWithParams(h.DeregisterDecisionMaker))
+
+		// decision maker pull mode routes, authenticated by a token the decision maker signs
+		apiV1.GET("/decisionmakers/pull/intents", h.echoHandler(h.PullIntents))
+		apiV1.GET("/decisionmakers/pull/node-intents", h.echoHandler(h.PullNodePolicies))
+		apiV1.GET("/decisionmakers/pull/runtime-config", h.echoHandler(h.PullRuntimeConfig))
+
 		// pod scheduling metrics routes
 		apiV1.POST("/metrics", h.echoHandler(h.IngestPodMetrics), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMCreate)))
 		apiV1.POST("/pod-scheduling-metrics", h.echoHandler(h.CreatePodSchedulingMetrics), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMCreate)))
 		apiV1.GET("/pod-scheduling-metrics", h.echoHandler(h.ListPodSchedulingMetrics), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMRead)))
 		apiV1.GET("/pod-scheduling-metrics/runtime", h.echoHandler(h.ListPodSchedulingMetricValues), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMRead)))
+		apiV1.GET("/pod-scheduling-metrics/history", h.echoHandler(h.GetPodSchedulingMetricHistory), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMRead)))
 		apiV1.GET("/classify", h.echoHandler(h.ListPodClassifications), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMRead)))
 		apiV1.GET("/classify/:namespace/:pod", h.echoHandlerWithParams(h.GetPodClassification), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMRead)))
 		apiV1.PUT("/pod-scheduling-metrics", h.echoHandler(h.UpdatePodSchedulingMetrics), echo.WrapMiddleware(h.GetAuthMiddleware(domain.PSMUpdate)))
@@ -75,6 +87,27 @@ func (h *Handler) SetupRoutes(engine *echo.Echo) {
 		// scheduler runtime config routes
 		apiV1.POST("/scheduler/runtime-config/apply", h.echoHandler(h.ApplyRuntimeConfig), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigUpdate)))
 		apiV1.GET("/scheduler/runtime-config/status", h.echoHandler(h.GetRuntimeConfigStatus), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigRead)))
+		apiV1.POST("/scheduler/runtime-config/rollouts", h.echoHandler(h.CreateRuntimeConfigRollout), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigUpdate)))
+		apiV1.GET("/scheduler/runtime-config/rollouts", h.echoHandler(h.ListRuntimeConfigRollouts), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigRead)))
+		apiV1.GET("/scheduler/runtime-config/rollouts/:rolloutID", h.echoHandlerWithParams(h.GetRuntimeConfigRollout), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigRead)))
+		apiV1.POST("/scheduler/runtime-config/rollouts/:rolloutID/pause", h.echoHandlerWithParams(h.PauseRuntimeConfigRollout), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigUpdate)))
+		apiV1.POST("/scheduler/runtime-config/rollouts/:rolloutID/resume", h.echoHandlerWithParams(h.ResumeRuntimeConfigRollout), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigUpdate)))
+		apiV1.POST("/scheduler/runtime-config/rollouts/:rolloutID/rollback", h.echoHandlerWithParams(h.RollbackRuntimeConfigRollout), echo.WrapMiddleware(h.GetAuthMiddleware(domain.SchedulerConfigUpdate)))
+
+		// strategy recommendation routes
+		apiV1.GET("/strategy-recommendations", h.echoHandler(h.ListStrategyRecommendations), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationRead)))
+		apiV1.GET("/strategy-recommendations/auto-apply", h.echoHandler(h.ListRecommendationAutoApplyPolicies), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationRead)))
+		apiV1.PUT("/strategy-recommendations/auto-apply", h.echoHandler(h.SetRecommendationAutoApplyPolicy), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationUpdate)))
+		apiV1.GET("/strategy-recommendations/:recommendationID", h.echoHandlerWithParams(h.GetStrategyRecommendation), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationRead)))
+		apiV1.POST("/strategy-recommendations/:recommendationID/approve", h.echoHandlerWithParams(h.ApproveStrategyRecommendation), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationUpdate)))
+		apiV1.POST("/strategy-recommendations/:recommendationID/reject", h.echoHandlerWithParams(h.RejectStrategyRecommendation), echo.WrapMiddleware(h.GetAuthMiddleware(domain.StrategyRecommendationUpdate)))
+
+		// config bundle routes
+		apiV1.GET("/export", h.echoHandler(h.ExportConfigBundle), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ConfigBundleExport)))
+		apiV1.POST("/apply", h.echoHandler(h.ApplyConfigBundle), echo.WrapMiddleware(h.GetAuthMiddleware(domain.ConfigBundleApply)))
+
+		// audit log routes
+		apiV1.GET("/audit-logs", h.echoHandler(h.ListAuditLogs), echo.WrapMiddleware(h.GetAuthMiddleware(domain.AuditLogRead)))
 	}
 
 }
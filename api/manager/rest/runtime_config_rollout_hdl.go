@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+type CreateRuntimeConfigRolloutRequest struct {
+	NodeIDs       []string                      `json:"nodeIds,omitempty"`
+	Config        domain.RuntimeSchedulerConfig `json:"config"`
+	Waves         []domain.RolloutWave          `json:"waves"`
+	HealthGate    domain.RolloutHealthGate      `json:"healthGate"`
+	FailurePolicy domain.RolloutFailurePolicy   `json:"failurePolicy,omitempty"`
+}
+
+type RuntimeConfigRolloutResponse struct {
+	Rollout *domain.RuntimeConfigRollout `json:"rollout"`
+}
+
+type ListRuntimeConfigRolloutsResponse struct {
+	Rollouts []*domain.RuntimeConfigRollout `json:"rollouts"`
+}
+
+type runtimeConfigRolloutService interface {
+	CreateRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, opt *domain.RuntimeConfigRolloutOptions) (*domain.RuntimeConfigRollout, error)
+	ListRuntimeConfigRollouts(ctx context.Context, opt *domain.QueryRuntimeConfigRolloutOptions) error
+	GetRuntimeConfigRollout(ctx context.Context, rolloutID string) (*domain.RuntimeConfigRollout, error)
+	PauseRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error)
+	ResumeRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error)
+	RollbackRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error)
+}
+
+func (h *Handler) CreateRuntimeConfigRollout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateRuntimeConfigRolloutRequest
+	if err := h.JSONBind(r, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	svc, ok := h.Svc.(runtimeConfigRolloutService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Runtime config rollout is not enabled", nil)
+		return
+	}
+
+	rollout, err := svc.CreateRuntimeConfigRollout(ctx, &claims, &domain.RuntimeConfigRolloutOptions{
+		NodeIDs:       req.NodeIDs,
+		Config:        req.Config,
+		Waves:         req.Waves,
+		HealthGate:    req.HealthGate,
+		FailurePolicy: req.FailurePolicy,
+	})
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&RuntimeConfigRolloutResponse{Rollout: rollout}))
+}
+
+func (h *Handler) ListRuntimeConfigRollouts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queryOpt := &domain.QueryRuntimeConfigRolloutOptions{}
+	if statesParam := strings.TrimSpace(r.URL.Query().Get("states")); statesParam != "" {
+		for _, state := range strings.Split(statesParam, ",") {
+			state = strings.TrimSpace(state)
+			if state != "" {
+				queryOpt.States = append(queryOpt.States, domain.RolloutState(state))
+			}
+		}
+	}
+
+	svc, ok := h.Svc.(runtimeConfigRolloutService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Runtime config rollout is not enabled", nil)
+		return
+	}
+
+	if err := svc.ListRuntimeConfigRollouts(ctx, queryOpt); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	rollouts := queryOpt.Result
+	if rollouts == nil {
+		rollouts = []*domain.RuntimeConfigRollout{}
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&ListRuntimeConfigRolloutsResponse{Rollouts: rollouts}))
+}
+
+func (h *Handler) GetRuntimeConfigRollout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rolloutID := h.GetPathParam(r, "rolloutID")
+
+	svc, ok := h.Svc.(runtimeConfigRolloutService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Runtime config rollout is not enabled", nil)
+		return
+	}
+
+	rollout, err := svc.GetRuntimeConfigRollout(ctx, rolloutID)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&RuntimeConfigRolloutResponse{Rollout: rollout}))
+}
+
+func (h *Handler) PauseRuntimeConfigRollout(w http.ResponseWriter, r *http.Request) {
+	h.runtimeConfigRolloutAction(w, r, runtimeConfigRolloutService.PauseRuntimeConfigRollout)
+}
+
+func (h *Handler) ResumeRuntimeConfigRollout(w http.ResponseWriter, r *http.Request) {
+	h.runtimeConfigRolloutAction(w, r, runtimeConfigRolloutService.ResumeRuntimeConfigRollout)
+}
+
+func (h *Handler) RollbackRuntimeConfigRollout(w http.ResponseWriter, r *http.Request) {
+	h.runtimeConfigRolloutAction(w, r, runtimeConfigRolloutService.RollbackRuntimeConfigRollout)
+}
+
+func (h *Handler) runtimeConfigRolloutAction(w http.ResponseWriter, r *http.Request, action func(svc runtimeConfigRolloutService, ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error)) {
+	ctx := r.Context()
+	rolloutID := h.GetPathParam(r, "rolloutID")
+
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	svc, ok := h.Svc.(runtimeConfigRolloutService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Runtime config rollout is not enabled", nil)
+		return
+	}
+
+	rollout, err := action(svc, ctx, &claims, rolloutID)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&RuntimeConfigRolloutResponse{Rollout: rollout}))
+}
@@ -1,7 +1,10 @@
 package rest
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -19,6 +22,16 @@ type CreateScheduleStrategyRequest struct {
 	CommandRegex      string          `json:"commandRegex,omitempty"`
 	Priority          int             `json:"priority,omitempty"`
 	ExecutionTime     int64           `json:"executionTime,omitempty"`
+	// Precedence picks the winner when strategies or node scheduling
+	// policies target the same process; the higher one wins before
+	// pod-level and specificity are compared.
+	Precedence int `json:"precedence,omitempty"`
+	// Schedule limits when the strategy is active; omit it to keep the
+	// strategy always active.
+	Schedule *StrategySchedule `json:"schedule,omitempty"`
+	// ClusterSelector names the clusters whose pods the strategy selects;
+	// omit it to select the pods of every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
 }
 
 type UpdateScheduleStrategyRequest struct {
@@ -29,17 +42,27 @@ type UpdateScheduleStrategyRequest struct {
 	CommandRegex      string          `json:"commandRegex,omitempty"`
 	Priority          int             `json:"priority,omitempty"`
 	ExecutionTime     int64           `json:"executionTime,omitempty"`
+	// Precedence picks the winner when strategies or node scheduling
+	// policies target the same process; the higher one wins before
+	// pod-level and specificity are compared.
+	Precedence int `json:"precedence,omitempty"`
+	// Schedule limits when the strategy is active; omit it to keep the
+	// strategy always active.
+	Schedule *StrategySchedule `json:"schedule,omitempty"`
+	// ClusterSelector names the clusters whose pods the strategy selects;
+	// omit it to select the pods of every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
 }
 
 // CreateScheduleStrategy godoc
 // @Summary Create schedule strategy
-// @Description Create a new schedule strategy.
+// @Description Create a new schedule strategy. When it overlaps existing strategies or node scheduling policies, the conflicts are returned as warnings.
 // @Tags Strategies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateScheduleStrategyRequest true "Schedule strategy payload"
-// @Success 200 {object} SuccessResponse[EmptyResponse]
+// @Success 200 {object} SuccessResponse[ScheduleStrategyConflictsResponse]
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -61,6 +84,9 @@ func (h *Handler) CreateScheduleStrategy(w http.ResponseWriter, r *http.Request)
 		CommandRegex:      req.CommandRegex,
 		Priority:          req.Priority,
 		ExecutionTime:     req.ExecutionTime,
+		Precedence:        req.Precedence,
+		Schedule:          convertRequestScheduleToDomain(req.Schedule),
+		ClusterSelector:   req.ClusterSelector,
 	}
 	for i, ls := range req.LabelSelectors {
 		strategy.LabelSelectors[i] = domain.LabelSelector{
@@ -75,25 +101,30 @@ func (h *Handler) CreateScheduleStrategy(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.VerifyK8SNamespacePolicy(ctx, strategy.K8sNamespace); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
 	err = h.Svc.CreateScheduleStrategy(ctx, &claims, strategy)
 	if err != nil {
 		h.HandleError(ctx, w, err)
 		return
 	}
 
-	response := NewSuccessResponse[string](nil)
+	response := NewSuccessResponse(h.strategyConflictWarnings(ctx, strategy))
 	h.JSONResponse(ctx, w, http.StatusOK, response)
 }
 
 // UpdateScheduleStrategy godoc
 // @Summary Update schedule strategy
-// @Description Update an existing schedule strategy.
+// @Description Update an existing schedule strategy. When it overlaps other strategies or node scheduling policies, the conflicts are returned as warnings.
 // @Tags Strategies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body UpdateScheduleStrategyRequest true "Schedule strategy payload"
-// @Success 200 {object} SuccessResponse[EmptyResponse]
+// @Success 200 {object} SuccessResponse[ScheduleStrategyConflictsResponse]
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -119,6 +150,9 @@ func (h *Handler) UpdateScheduleStrategy(w http.ResponseWriter, r *http.Request)
 		CommandRegex:      req.CommandRegex,
 		Priority:          req.Priority,
 		ExecutionTime:     req.ExecutionTime,
+		Precedence:        req.Precedence,
+		Schedule:          convertRequestScheduleToDomain(req.Schedule),
+		ClusterSelector:   req.ClusterSelector,
 	}
 	for i, ls := range req.LabelSelectors {
 		strategy.LabelSelectors[i] = domain.LabelSelector{
@@ -133,12 +167,20 @@ func (h *Handler) UpdateScheduleStrategy(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.VerifyK8SNamespacePolicy(ctx, strategy.K8sNamespace); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
 	if err := h.Svc.UpdateScheduleStrategy(ctx, &claims, req.StrategyID, strategy); err != nil {
 		h.HandleError(ctx, w, err)
 		return
 	}
 
-	response := NewSuccessResponse[string](nil)
+	if strategyID, err := bson.ObjectIDFromHex(req.StrategyID); err == nil {
+		strategy.ID = strategyID
+	}
+	response := NewSuccessResponse(h.strategyConflictWarnings(ctx, strategy))
 	h.JSONResponse(ctx, w, http.StatusOK, response)
 }
 
@@ -147,13 +189,68 @@ type ListSchedulerStrategiesResponse struct {
 }
 
 type ScheduleStrategy struct {
-	ID                bson.ObjectID   `bson:"_id,omitempty"`
-	StrategyNamespace string          `bson:"strategyNamespace,omitempty"`
-	LabelSelectors    []LabelSelector `bson:"labelSelectors,omitempty"`
-	K8sNamespace      []string        `bson:"k8sNamespace,omitempty"`
-	CommandRegex      string          `bson:"commandRegex,omitempty"`
-	Priority          int             `bson:"priority,omitempty"`
-	ExecutionTime     int64           `bson:"executionTime,omitempty"`
+	ID                bson.ObjectID     `bson:"_id,omitempty"`
+	StrategyNamespace string            `bson:"strategyNamespace,omitempty"`
+	LabelSelectors    []LabelSelector   `bson:"labelSelectors,omitempty"`
+	K8sNamespace      []string          `bson:"k8sNamespace,omitempty"`
+	CommandRegex      string            `bson:"commandRegex,omitempty"`
+	Priority          int               `bson:"priority,omitempty"`
+	Precedence        int               `bson:"precedence,omitempty"`
+	ExecutionTime     int64             `bson:"executionTime,omitempty"`
+	Schedule          *StrategySchedule `bson:"schedule,omitempty"`
+	ClusterSelector   []string          `bson:"clusterSelector,omitempty"`
+	// Active tells whether the strategy is inside its activation windows
+	// now, and NextTransition (Unix milliseconds) when that changes; zero
+	// means never.
+	Active         bool  `bson:"active"`
+	NextTransition int64 `bson:"nextTransition,omitempty"`
+}
+
+// StrategySchedule holds activation windows evaluated in Timezone (IANA,
+// default UTC). A window is either a cron expression opening it for
+// durationSeconds, or a daily start/end time ("HH:MM") on the given days.
+type StrategySchedule struct {
+	Timezone  string             `json:"timezone,omitempty"`
+	Windows   []ActivationWindow `json:"windows,omitempty"`
+	ExpiresAt int64              `json:"expiresAt,omitempty"`
+}
+
+type ActivationWindow struct {
+	Cron            string   `json:"cron,omitempty"`
+	DurationSeconds int64    `json:"durationSeconds,omitempty"`
+	Start           string   `json:"start,omitempty"`
+	End             string   `json:"end,omitempty"`
+	Days            []string `json:"days,omitempty"`
+}
+
+func convertRequestScheduleToDomain(schedule *StrategySchedule) *domain.StrategySchedule {
+	if schedule == nil {
+		return nil
+	}
+	result := &domain.StrategySchedule{
+		Timezone:  schedule.Timezone,
+		ExpiresAt: schedule.ExpiresAt,
+		Windows:   make([]domain.ActivationWindow, len(schedule.Windows)),
+	}
+	for i, w := range schedule.Windows {
+		result.Windows[i] = domain.ActivationWindow(w)
+	}
+	return result
+}
+
+func convertDomainScheduleToResponse(schedule *domain.StrategySchedule) *StrategySchedule {
+	if schedule == nil {
+		return nil
+	}
+	result := &StrategySchedule{
+		Timezone:  schedule.Timezone,
+		ExpiresAt: schedule.ExpiresAt,
+		Windows:   make([]ActivationWindow, len(schedule.Windows)),
+	}
+	for i, w := range schedule.Windows {
+		result.Windows[i] = ActivationWindow(w)
+	}
+	return result
 }
 
 // ListSelfScheduleStrategies godoc
@@ -192,17 +289,26 @@ func (h *Handler) ListSelfScheduleStrategies(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	allowed := h.k8sNamespaceFilter(ctx)
 	resp := ListSchedulerStrategiesResponse{
-		Strategies: make([]*ScheduleStrategy, len(queryOpt.Result)),
+		Strategies: make([]*ScheduleStrategy, 0, len(queryOpt.Result)),
 	}
-	for i, ds := range queryOpt.Result {
-		resp.Strategies[i] = h.convertDomainStrategyToResponseStrategy(ds)
+	for _, ds := range queryOpt.Result {
+		if !allowed(ds.K8sNamespace...) {
+			continue
+		}
+		resp.Strategies = append(resp.Strategies, h.convertDomainStrategyToResponseStrategy(ds))
 	}
 	response := NewSuccessResponse[ListSchedulerStrategiesResponse](&resp)
 	h.JSONResponse(ctx, w, http.StatusOK, response)
 }
 
 func (h *Handler) convertDomainStrategyToResponseStrategy(domainStrategy *domain.ScheduleStrategy) *ScheduleStrategy {
+	active, next := domainStrategy.Schedule.Active(time.Now())
+	var nextTransition int64
+	if !next.IsZero() {
+		nextTransition = next.UnixMilli()
+	}
 	return &ScheduleStrategy{
 		ID:                domainStrategy.ID,
 		StrategyNamespace: domainStrategy.StrategyNamespace,
@@ -210,7 +316,12 @@ func (h *Handler) convertDomainStrategyToResponseStrategy(domainStrategy *domain
 		K8sNamespace:      domainStrategy.K8sNamespace,
 		CommandRegex:      domainStrategy.CommandRegex,
 		Priority:          domainStrategy.Priority,
+		Precedence:        domainStrategy.Precedence,
 		ExecutionTime:     domainStrategy.ExecutionTime,
+		Schedule:          convertDomainScheduleToResponse(domainStrategy.Schedule),
+		ClusterSelector:   domainStrategy.ClusterSelector,
+		Active:            active,
+		NextTransition:    nextTransition,
 	}
 }
 
@@ -232,6 +343,7 @@ type ListScheduleIntentsResponse struct {
 type ScheduleIntent struct {
 	ID            bson.ObjectID      `bson:"_id,omitempty"`
 	StrategyID    bson.ObjectID      `bson:"strategyID,omitempty"`
+	Cluster       string             `bson:"cluster,omitempty"`
 	PodID         string             `bson:"podID,omitempty"`
 	NodeID        string             `bson:"nodeID,omitempty"`
 	K8sNamespace  string             `bson:"k8sNamespace,omitempty"`
@@ -240,6 +352,43 @@ type ScheduleIntent struct {
 	ExecutionTime int64              `bson:"executionTime,omitempty"`
 	PodLabels     map[string]string  `bson:"podLabels,omitempty"`
 	State         domain.IntentState `bson:"state,omitempty"`
+	Precedence    int                `bson:"precedence,omitempty"`
+	Specificity   int                `bson:"specificity,omitempty"`
+	// Overrides lists the processes decision makers gave to a higher-ranked
+	// strategy or node scheduling policy instead of this intent.
+	Overrides []IntentOverride `bson:"overrides,omitempty"`
+	// StateName spells out State. Once the decision maker reports back it is
+	// Applied, NoMatchingProcess or Failed, with the PIDs the intent was
+	// applied to, the last apply time (unix milliseconds) and why it was not.
+	StateName     string `bson:"stateName,omitempty"`
+	MatchedPIDs   []int  `bson:"matchedPIDs,omitempty"`
+	LastAppliedAt int64  `bson:"lastAppliedAt,omitempty"`
+	StatusMessage string `bson:"statusMessage,omitempty"`
+}
+
+// IntentOverride is a set of processes decision makers gave to another
+// strategy or node scheduling policy.
+type IntentOverride struct {
+	WinnerKind string `bson:"winnerKind,omitempty"`
+	WinnerID   string `bson:"winnerID,omitempty"`
+	Reason     string `bson:"reason,omitempty"`
+	PIDs       []int  `bson:"pids,omitempty"`
+}
+
+func convertDomainIntentOverrides(overrides []domain.IntentOverride) []IntentOverride {
+	if len(overrides) == 0 {
+		return nil
+	}
+	results := make([]IntentOverride, len(overrides))
+	for i, override := range overrides {
+		results[i] = IntentOverride{
+			WinnerKind: override.WinnerKind,
+			WinnerID:   override.WinnerID,
+			Reason:     override.Reason,
+			PIDs:       override.PIDs,
+		}
+	}
+	return results
 }
 
 // ListSelfScheduleIntents godoc
@@ -278,11 +427,15 @@ func (h *Handler) ListSelfScheduleIntents(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	allowed := h.k8sNamespaceFilter(ctx)
 	resp := ListScheduleIntentsResponse{
-		Intents: make([]*ScheduleIntent, len(queryOpt.Result)),
+		Intents: make([]*ScheduleIntent, 0, len(queryOpt.Result)),
 	}
-	for i, di := range queryOpt.Result {
-		resp.Intents[i] = h.convertDomainIntentToResponseIntent(di)
+	for _, di := range queryOpt.Result {
+		if !allowed(di.K8sNamespace) {
+			continue
+		}
+		resp.Intents = append(resp.Intents, h.convertDomainIntentToResponseIntent(di))
 	}
 	response := NewSuccessResponse[ListScheduleIntentsResponse](&resp)
 	h.JSONResponse(ctx, w, http.StatusOK, response)
@@ -292,6 +445,7 @@ func (h *Handler) convertDomainIntentToResponseIntent(domainIntent *domain.Sched
 	return &ScheduleIntent{
 		ID:            domainIntent.ID,
 		StrategyID:    domainIntent.StrategyID,
+		Cluster:       domainIntent.Cluster,
 		PodID:         domainIntent.PodID,
 		NodeID:        domainIntent.NodeID,
 		K8sNamespace:  domainIntent.K8sNamespace,
@@ -300,6 +454,13 @@ func (h *Handler) convertDomainIntentToResponseIntent(domainIntent *domain.Sched
 		ExecutionTime: domainIntent.ExecutionTime,
 		PodLabels:     domainIntent.PodLabels,
 		State:         domainIntent.State,
+		Precedence:    domainIntent.Precedence,
+		Specificity:   domainIntent.Specificity,
+		Overrides:     convertDomainIntentOverrides(domainIntent.Overrides),
+		StateName:     domainIntent.State.String(),
+		MatchedPIDs:   domainIntent.MatchedPIDs,
+		LastAppliedAt: domainIntent.LastAppliedAt,
+		StatusMessage: domainIntent.StatusMessage,
 	}
 }
 
@@ -482,8 +643,22 @@ func (h *Handler) GetNodePodPIDMapping(w http.ResponseWriter, r *http.Request) {
 
 // NodeInfo represents node information for API response
 type NodeInfo struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	Cluster       string             `json:"cluster,omitempty"`
+	DecisionMaker *DecisionMakerInfo `json:"decisionMaker,omitempty"`
+}
+
+// DecisionMakerInfo is the registration of a node's decision maker, set for
+// nodes whose decision maker registered itself with the manager.
+type DecisionMakerInfo struct {
+	Host          string                           `json:"host"`
+	Port          int                              `json:"port"`
+	Version       string                           `json:"version,omitempty"`
+	Capabilities  domain.DecisionMakerCapabilities `json:"capabilities"`
+	RegisteredAt  time.Time                        `json:"registeredAt"`
+	LastHeartbeat time.Time                        `json:"lastHeartbeat"`
+	Online        bool                             `json:"online"`
 }
 
 // ListNodesResponse is the response structure for the GET /api/v1/nodes endpoint
@@ -493,10 +668,11 @@ type ListNodesResponse struct {
 
 // ListNodes godoc
 // @Summary List all Kubernetes nodes
-// @Description Returns all nodes in the Kubernetes cluster
+// @Description Returns all nodes in the Kubernetes cluster with the registration of their decision maker, if any. A multi-cluster manager lists the nodes of every cluster unless cluster names one.
 // @Tags Nodes
 // @Produce json
 // @Security BearerAuth
+// @Param cluster query string false "Only list the nodes of this cluster"
 // @Success 200 {object} SuccessResponse[ListNodesResponse]
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -511,16 +687,66 @@ func (h *Handler) ListNodes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	cluster := strings.TrimSpace(r.URL.Query().Get("cluster"))
 	resp := ListNodesResponse{
-		Nodes: make([]NodeInfo, len(nodes)),
+		Nodes: make([]NodeInfo, 0, len(nodes)),
 	}
-	for i, node := range nodes {
-		resp.Nodes[i] = NodeInfo{
-			Name:   node.Name,
-			Status: node.Status,
+	for _, node := range nodes {
+		if cluster != "" && node.Cluster != cluster {
+			continue
+		}
+		info := NodeInfo{
+			Name:    node.Name,
+			Status:  node.Status,
+			Cluster: node.Cluster,
+		}
+		if dm := node.DecisionMaker; dm != nil {
+			info.DecisionMaker = &DecisionMakerInfo{
+				Host:          dm.Host,
+				Port:          dm.Port,
+				Version:       dm.Version,
+				Capabilities:  dm.Capabilities,
+				RegisteredAt:  dm.RegisteredAt,
+				LastHeartbeat: dm.LastHeartbeat,
+				Online:        dm.Online,
+			}
 		}
+		resp.Nodes = append(resp.Nodes, info)
 	}
 
 	response := NewSuccessResponse[ListNodesResponse](&resp)
 	h.JSONResponse(ctx, w, http.StatusOK, response)
 }
+
+// ListClustersResponse represents the response for listing clusters
+type ListClustersResponse struct {
+	Clusters []string `json:"clusters"`
+}
+
+// ListClusters godoc
+// @Summary List the federated Kubernetes clusters
+// @Description Returns the names of the clusters the manager federates, which strategies and node policies select with clusterSelector
+// @Tags Nodes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse[ListClustersResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /api/v1/clusters [get]
+func (h *Handler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, ok := h.Svc.(interface {
+		ListClusters(ctx context.Context) ([]string, error)
+	})
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Multi-cluster is not enabled", nil)
+		return
+	}
+	clusters, err := svc.ListClusters(ctx)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&ListClustersResponse{Clusters: clusters}))
+}
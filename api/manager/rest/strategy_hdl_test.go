@@ -114,7 +114,7 @@ func (suite *HandlerTestSuite) TestIntegrationDeleteIntentsHandler() {
 }
 
 func (suite *HandlerTestSuite) createStrategy(token string, strategyReq *rest.CreateScheduleStrategyRequest, expectedStatus int) {
-	createStrategyResp := rest.SuccessResponse[string]{}
+	createStrategyResp := rest.SuccessResponse[rest.ScheduleStrategyConflictsResponse]{}
 	_, resp := suite.sendV1Request("POST", "/strategies", strategyReq, &createStrategyResp, token)
 	suite.Require().Equal(expectedStatus, resp.Code, "Unexpected status code on create strategy")
 }
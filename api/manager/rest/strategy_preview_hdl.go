@@ -0,0 +1,230 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type PreviewScheduleStrategyRequest struct {
+	// StrategyID previews an update of an existing strategy, so its own
+	// intents are not reported as conflicts.
+	StrategyID        string          `json:"strategyId,omitempty"`
+	StrategyNamespace string          `json:"strategyNamespace,omitempty"`
+	LabelSelectors    []LabelSelector `json:"labelSelectors,omitempty"`
+	K8sNamespace      []string        `json:"k8sNamespace,omitempty"`
+	CommandRegex      string          `json:"commandRegex,omitempty"`
+	Priority          int             `json:"priority,omitempty"`
+	ExecutionTime     int64           `json:"executionTime,omitempty"`
+	Precedence        int             `json:"precedence,omitempty"`
+	// ClusterSelector names the clusters whose pods are matched; empty
+	// matches every cluster.
+	ClusterSelector []string `json:"clusterSelector,omitempty"`
+}
+
+type StrategyPreviewProcess struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+}
+
+type StrategyPreviewPod struct {
+	PodID        string                   `json:"podId"`
+	PodName      string                   `json:"podName"`
+	K8sNamespace string                   `json:"k8sNamespace"`
+	Processes    []StrategyPreviewProcess `json:"processes"`
+}
+
+type StrategyPreviewNode struct {
+	Cluster           string               `json:"cluster,omitempty"`
+	NodeID            string               `json:"nodeId"`
+	ProcessesResolved bool                 `json:"processesResolved"`
+	Error             string               `json:"error,omitempty"`
+	Pods              []StrategyPreviewPod `json:"pods"`
+}
+
+type StrategyConflict struct {
+	StrategyID        string `json:"strategyId,omitempty"`
+	NodePolicyID      string `json:"nodePolicyId,omitempty"`
+	StrategyNamespace string `json:"strategyNamespace,omitempty"`
+	CommandRegex      string `json:"commandRegex,omitempty"`
+	Priority          int    `json:"priority"`
+	ExecutionTime     int64  `json:"executionTime"`
+	Precedence        int    `json:"precedence"`
+	PodID             string `json:"podId"`
+	PodName           string `json:"podName"`
+	NodeID            string `json:"nodeId"`
+	PIDs              []int  `json:"pids,omitempty"`
+	// ExistingWins is true when decision makers keep the existing strategy
+	// or policy for the overlapping processes; Reason names the rule that
+	// decided it.
+	ExistingWins bool   `json:"existingWins"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// ScheduleStrategyConflictsResponse is returned by create and update when
+// the strategy overlaps existing strategies or node scheduling policies.
+type ScheduleStrategyConflictsResponse struct {
+	Conflicts []StrategyConflict `json:"conflicts"`
+}
+
+type strategyPreviewer interface {
+	PreviewScheduleStrategy(ctx context.Context, strategy *domain.ScheduleStrategy) (*domain.StrategyPreview, error)
+}
+
+type PreviewScheduleStrategyResponse struct {
+	MatchedPods      int                   `json:"matchedPods"`
+	MatchedProcesses int                   `json:"matchedProcesses"`
+	Nodes            []StrategyPreviewNode `json:"nodes"`
+	Conflicts        []StrategyConflict    `json:"conflicts"`
+	Warnings         []string              `json:"warnings"`
+}
+
+// PreviewScheduleStrategy godoc
+// @Summary Preview schedule strategy
+// @Description Resolve the pods, nodes and processes a schedule strategy would target, and the existing strategies already targeting them, without creating it or sending intents.
+// @Tags Strategies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PreviewScheduleStrategyRequest true "Schedule strategy payload"
+// @Success 200 {object} SuccessResponse[PreviewScheduleStrategyResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/strategies/preview [post]
+func (h *Handler) PreviewScheduleStrategy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req PreviewScheduleStrategyRequest
+	if err := h.JSONBind(r, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	strategy := &domain.ScheduleStrategy{
+		StrategyNamespace: req.StrategyNamespace,
+		LabelSelectors:    make([]domain.LabelSelector, len(req.LabelSelectors)),
+		K8sNamespace:      req.K8sNamespace,
+		CommandRegex:      req.CommandRegex,
+		Priority:          req.Priority,
+		ExecutionTime:     req.ExecutionTime,
+		Precedence:        req.Precedence,
+		ClusterSelector:   req.ClusterSelector,
+	}
+	for i, ls := range req.LabelSelectors {
+		strategy.LabelSelectors[i] = domain.LabelSelector{
+			Key:   ls.Key,
+			Value: ls.Value,
+		}
+	}
+	if req.StrategyID != "" {
+		strategyID, err := bson.ObjectIDFromHex(req.StrategyID)
+		if err != nil {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid strategy ID", err)
+			return
+		}
+		strategy.ID = strategyID
+	}
+
+	if err := h.VerifyK8SNamespacePolicy(ctx, strategy.K8sNamespace); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	svc, ok := h.Svc.(strategyPreviewer)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy preview is not enabled", nil)
+		return
+	}
+	preview, err := svc.PreviewScheduleStrategy(ctx, strategy)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	resp := &PreviewScheduleStrategyResponse{
+		MatchedPods:      preview.MatchedPods,
+		MatchedProcesses: preview.MatchedProcesses,
+		Nodes:            make([]StrategyPreviewNode, 0, len(preview.Nodes)),
+		Conflicts:        make([]StrategyConflict, 0, len(preview.Conflicts)),
+		Warnings:         append([]string{}, preview.Warnings...),
+	}
+	for _, node := range preview.Nodes {
+		nodeResp := StrategyPreviewNode{
+			Cluster:           node.Cluster,
+			NodeID:            node.NodeID,
+			ProcessesResolved: node.ProcessesResolved,
+			Error:             node.Error,
+			Pods:              make([]StrategyPreviewPod, 0, len(node.Pods)),
+		}
+		for _, pod := range node.Pods {
+			podResp := StrategyPreviewPod{
+				PodID:        pod.PodID,
+				PodName:      pod.PodName,
+				K8sNamespace: pod.K8sNamespace,
+				Processes:    make([]StrategyPreviewProcess, 0, len(pod.Processes)),
+			}
+			for _, process := range pod.Processes {
+				podResp.Processes = append(podResp.Processes, StrategyPreviewProcess{PID: process.PID, Command: process.Command})
+			}
+			nodeResp.Pods = append(nodeResp.Pods, podResp)
+		}
+		resp.Nodes = append(resp.Nodes, nodeResp)
+	}
+	for _, conflict := range preview.Conflicts {
+		resp.Conflicts = append(resp.Conflicts, convertDomainStrategyConflict(conflict))
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+func convertDomainStrategyConflict(conflict *domain.StrategyConflict) StrategyConflict {
+	resp := StrategyConflict{
+		StrategyNamespace: conflict.StrategyNamespace,
+		CommandRegex:      conflict.CommandRegex,
+		Priority:          conflict.Priority,
+		ExecutionTime:     conflict.ExecutionTime,
+		Precedence:        conflict.Precedence,
+		PodID:             conflict.PodID,
+		PodName:           conflict.PodName,
+		NodeID:            conflict.NodeID,
+		PIDs:              conflict.PIDs,
+		ExistingWins:      conflict.ExistingWins,
+		Reason:            conflict.Reason,
+	}
+	if !conflict.StrategyID.IsZero() {
+		resp.StrategyID = conflict.StrategyID.Hex()
+	}
+	if !conflict.NodePolicyID.IsZero() {
+		resp.NodePolicyID = conflict.NodePolicyID.Hex()
+	}
+	return resp
+}
+
+// strategyConflictWarnings previews a strategy that was just saved and
+// returns the strategies and node scheduling policies it overlaps. It
+// returns nil when there are none or the preview fails, since the strategy
+// is already saved and the warnings are best effort.
+func (h *Handler) strategyConflictWarnings(ctx context.Context, strategy *domain.ScheduleStrategy) *ScheduleStrategyConflictsResponse {
+	svc, ok := h.Svc.(strategyPreviewer)
+	if !ok {
+		return nil
+	}
+	preview, err := svc.PreviewScheduleStrategy(ctx, strategy)
+	if err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msg("failed to check schedule strategy conflicts")
+		return nil
+	}
+	if len(preview.Conflicts) == 0 {
+		return nil
+	}
+	resp := &ScheduleStrategyConflictsResponse{
+		Conflicts: make([]StrategyConflict, 0, len(preview.Conflicts)),
+	}
+	for _, conflict := range preview.Conflicts {
+		resp.Conflicts = append(resp.Conflicts, convertDomainStrategyConflict(conflict))
+	}
+	return resp
+}
@@ -0,0 +1,296 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+)
+
+type RejectStrategyRecommendationRequest struct {
+	Message string `json:"message,omitempty"`
+}
+
+type SetRecommendationAutoApplyPolicyRequest struct {
+	Namespace               string `json:"namespace"`
+	Enabled                 bool   `json:"enabled"`
+	MaxPriority             int    `json:"maxPriority"`
+	CooldownSeconds         int64  `json:"cooldownSeconds"`
+	RevertAfterDriftSeconds int64  `json:"revertAfterDriftSeconds"`
+}
+
+type StrategyRecommendationResponse struct {
+	Recommendation *domain.StrategyRecommendation `json:"recommendation"`
+}
+
+type ListStrategyRecommendationsResponse struct {
+	Recommendations []*domain.StrategyRecommendation `json:"recommendations"`
+}
+
+type RecommendationAutoApplyPolicyResponse struct {
+	Policy *domain.RecommendationAutoApplyPolicy `json:"policy"`
+}
+
+type ListRecommendationAutoApplyPoliciesResponse struct {
+	Policies []*domain.RecommendationAutoApplyPolicy `json:"policies"`
+}
+
+type strategyRecommendationService interface {
+	ListStrategyRecommendations(ctx context.Context, opt *domain.QueryStrategyRecommendationOptions) error
+	GetStrategyRecommendation(ctx context.Context, recommendationID string) (*domain.StrategyRecommendation, error)
+	ApproveStrategyRecommendation(ctx context.Context, operator *domain.Claims, recommendationID string) (*domain.StrategyRecommendation, error)
+	RejectStrategyRecommendation(ctx context.Context, operator *domain.Claims, recommendationID string, message string) (*domain.StrategyRecommendation, error)
+	ListRecommendationAutoApplyPolicies(ctx context.Context, opt *domain.QueryRecommendationAutoApplyPolicyOptions) error
+	SetRecommendationAutoApplyPolicy(ctx context.Context, operator *domain.Claims, policy *domain.RecommendationAutoApplyPolicy) error
+}
+
+// ListStrategyRecommendations godoc
+// @Summary List strategy recommendations
+// @Description List draft scheduling strategies derived from stable classifier recommendations.
+// @Tags StrategyRecommendations
+// @Produce json
+// @Security BearerAuth
+// @Param namespace query string false "Comma-separated Kubernetes namespaces"
+// @Param states query string false "Comma-separated states: pending, applied, rejected, reverted, obsolete"
+// @Success 200 {object} SuccessResponse[ListStrategyRecommendationsResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations [get]
+func (h *Handler) ListStrategyRecommendations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queryOpt := &domain.QueryStrategyRecommendationOptions{
+		Namespaces: splitQueryList(r.URL.Query().Get("namespace")),
+	}
+	for _, state := range splitQueryList(r.URL.Query().Get("states")) {
+		queryOpt.States = append(queryOpt.States, domain.RecommendationState(state))
+	}
+
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+	if err := svc.ListStrategyRecommendations(ctx, queryOpt); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	allowed := h.k8sNamespaceFilter(ctx)
+	resp := &ListStrategyRecommendationsResponse{Recommendations: []*domain.StrategyRecommendation{}}
+	for _, rec := range queryOpt.Result {
+		if allowed(rec.Namespace) {
+			resp.Recommendations = append(resp.Recommendations, rec)
+		}
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+// GetStrategyRecommendation godoc
+// @Summary Get a strategy recommendation
+// @Tags StrategyRecommendations
+// @Produce json
+// @Security BearerAuth
+// @Param recommendationID path string true "Recommendation ID"
+// @Success 200 {object} SuccessResponse[StrategyRecommendationResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations/{recommendationID} [get]
+func (h *Handler) GetStrategyRecommendation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+	rec, err := h.getAllowedStrategyRecommendation(ctx, svc, h.GetPathParam(r, "recommendationID"))
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&StrategyRecommendationResponse{Recommendation: rec}))
+}
+
+// ApproveStrategyRecommendation godoc
+// @Summary Approve a strategy recommendation
+// @Description Create the drafted scheduling strategy on behalf of the caller.
+// @Tags StrategyRecommendations
+// @Produce json
+// @Security BearerAuth
+// @Param recommendationID path string true "Recommendation ID"
+// @Success 200 {object} SuccessResponse[StrategyRecommendationResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations/{recommendationID}/approve [post]
+func (h *Handler) ApproveStrategyRecommendation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+	recommendationID := h.GetPathParam(r, "recommendationID")
+	if _, err := h.getAllowedStrategyRecommendation(ctx, svc, recommendationID); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	rec, err := svc.ApproveStrategyRecommendation(ctx, &claims, recommendationID)
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&StrategyRecommendationResponse{Recommendation: rec}))
+}
+
+// RejectStrategyRecommendation godoc
+// @Summary Reject a strategy recommendation
+// @Description Decline a pending recommendation; the same action is not proposed again for the pod for a day.
+// @Tags StrategyRecommendations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param recommendationID path string true "Recommendation ID"
+// @Param request body RejectStrategyRecommendationRequest false "Rejection reason"
+// @Success 200 {object} SuccessResponse[StrategyRecommendationResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations/{recommendationID}/reject [post]
+func (h *Handler) RejectStrategyRecommendation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req RejectStrategyRecommendationRequest
+	if r.ContentLength != 0 {
+		if err := h.JSONBind(r, &req); err != nil {
+			h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+	}
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+	recommendationID := h.GetPathParam(r, "recommendationID")
+	if _, err := h.getAllowedStrategyRecommendation(ctx, svc, recommendationID); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	rec, err := svc.RejectStrategyRecommendation(ctx, &claims, recommendationID, strings.TrimSpace(req.Message))
+	if err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&StrategyRecommendationResponse{Recommendation: rec}))
+}
+
+func (h *Handler) getAllowedStrategyRecommendation(ctx context.Context, svc strategyRecommendationService, recommendationID string) (*domain.StrategyRecommendation, error) {
+	rec, err := svc.GetStrategyRecommendation(ctx, recommendationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.VerifyK8SNamespacePolicy(ctx, []string{rec.Namespace}); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ListRecommendationAutoApplyPolicies godoc
+// @Summary List recommendation auto-apply policies
+// @Tags StrategyRecommendations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse[ListRecommendationAutoApplyPoliciesResponse]
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations/auto-apply [get]
+func (h *Handler) ListRecommendationAutoApplyPolicies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+	queryOpt := &domain.QueryRecommendationAutoApplyPolicyOptions{}
+	if err := svc.ListRecommendationAutoApplyPolicies(ctx, queryOpt); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+
+	allowed := h.k8sNamespaceFilter(ctx)
+	resp := &ListRecommendationAutoApplyPoliciesResponse{Policies: []*domain.RecommendationAutoApplyPolicy{}}
+	for _, policy := range queryOpt.Result {
+		if allowed(policy.Namespace) {
+			resp.Policies = append(resp.Policies, policy)
+		}
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(resp))
+}
+
+// SetRecommendationAutoApplyPolicy godoc
+// @Summary Configure recommendation auto-apply for a namespace
+// @Description Opt a namespace in or out of applying stable recommendations without approval. Auto-applied strategies are owned by the caller.
+// @Tags StrategyRecommendations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SetRecommendationAutoApplyPolicyRequest true "Auto-apply policy"
+// @Success 200 {object} SuccessResponse[RecommendationAutoApplyPolicyResponse]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/strategy-recommendations/auto-apply [put]
+func (h *Handler) SetRecommendationAutoApplyPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req SetRecommendationAutoApplyPolicyRequest
+	if err := h.JSONBind(r, &req); err != nil {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	claims, ok := h.GetClaimsFromContext(ctx)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+	namespace := strings.TrimSpace(req.Namespace)
+	if namespace == "" {
+		h.ErrorResponse(ctx, w, http.StatusBadRequest, "namespace is required", nil)
+		return
+	}
+	if err := h.VerifyK8SNamespacePolicy(ctx, []string{namespace}); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	svc, ok := h.Svc.(strategyRecommendationService)
+	if !ok {
+		h.ErrorResponse(ctx, w, http.StatusNotImplemented, "Strategy recommendation is not enabled", nil)
+		return
+	}
+
+	policy := &domain.RecommendationAutoApplyPolicy{
+		Namespace:               namespace,
+		Enabled:                 req.Enabled,
+		MaxPriority:             req.MaxPriority,
+		CooldownSeconds:         req.CooldownSeconds,
+		RevertAfterDriftSeconds: req.RevertAfterDriftSeconds,
+	}
+	if err := svc.SetRecommendationAutoApplyPolicy(ctx, &claims, policy); err != nil {
+		h.HandleError(ctx, w, err)
+		return
+	}
+	h.JSONResponse(ctx, w, http.StatusOK, NewSuccessResponse(&RecommendationAutoApplyPolicyResponse{Policy: policy}))
+}
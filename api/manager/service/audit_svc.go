@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// auditSecretFields are never copied into audit snapshots.
+var auditSecretFields = []string{"password", "refreshTokens", "tokenVersion"}
+
+// recordAudit writes an audit entry for a mutating call. Auditing is best
+// effort: a failed write is logged and never fails the audited operation.
+func (svc *Service) recordAudit(ctx context.Context, actorID string, action, resource, resourceID string, before, after any) {
+	entry := newAuditLog(ctx, actorID, action, resource, resourceID, before, after)
+	if err := svc.Repo.CreateAuditLog(ctx, entry); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("record audit log %s on %s %s failed", action, resource, resourceID)
+	}
+}
+
+func newAuditLog(ctx context.Context, actorID string, action, resource, resourceID string, before, after any) *domain.AuditLog {
+	info := domain.RequestInfoFromContext(ctx)
+	entry := &domain.AuditLog{
+		Action:     action,
+		RequestID:  info.RequestID,
+		IP:         info.IP,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Before:     auditSnapshot(ctx, before),
+		After:      auditSnapshot(ctx, after),
+	}
+	if uid, err := bson.ObjectIDFromHex(actorID); err == nil {
+		entry.UserID = uid
+	}
+	entry.Changes = diffAuditSnapshots(entry.Before, entry.After)
+	return entry
+}
+
+// auditSnapshot converts v to its stored BSON document form, without secrets.
+func auditSnapshot(ctx context.Context, v any) bson.M {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("marshal audit snapshot of %T failed", v)
+		return nil
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("unmarshal audit snapshot of %T failed", v)
+		return nil
+	}
+	for _, field := range auditSecretFields {
+		delete(doc, field)
+	}
+	return doc
+}
+
+// diffAuditSnapshots lists the top-level fields that differ, sorted by name.
+func diffAuditSnapshots(before, after bson.M) []domain.AuditChange {
+	fields := make(map[string]struct{}, len(before)+len(after))
+	for field := range before {
+		fields[field] = struct{}{}
+	}
+	for field := range after {
+		fields[field] = struct{}{}
+	}
+	changes := make([]domain.AuditChange, 0, len(fields))
+	for field := range fields {
+		b, a := before[field], after[field]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, domain.AuditChange{Field: field, Before: b, After: a})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func (svc *Service) ListAuditLogs(ctx context.Context, opt *domain.QueryAuditLogOptions) error {
+	return svc.Repo.QueryAuditLogs(ctx, opt)
+}
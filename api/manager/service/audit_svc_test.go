@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestNewAuditLogStripsSecretsAndDiffsFields(t *testing.T) {
+	actorID := bson.NewObjectID()
+	ctx := domain.WithRequestInfo(context.Background(), domain.RequestInfo{RequestID: "req-1", IP: "10.0.0.1"})
+	before := &domain.User{
+		UserName:       "alice",
+		Password:       "old-hash",
+		PermissionKeys: []string{"user.read"},
+		TokenVersion:   1,
+	}
+	after := *before
+	after.Password = "new-hash"
+	after.PermissionKeys = []string{"user.read", "user.update"}
+	after.TokenVersion = 2
+
+	entry := newAuditLog(ctx, actorID.Hex(), domain.AuditActionUserPermissionUpdate, domain.AuditResourceUser, "user-1", before, &after)
+
+	assert.Equal(t, actorID, entry.UserID)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, "10.0.0.1", entry.IP)
+	assert.Equal(t, "user-1", entry.ResourceID)
+	for _, field := range auditSecretFields {
+		assert.NotContains(t, entry.Before, field)
+		assert.NotContains(t, entry.After, field)
+	}
+	require.Len(t, entry.Changes, 1)
+	assert.Equal(t, "permissionKeys", entry.Changes[0].Field)
+}
+
+func TestNewAuditLogCreateHasNoBefore(t *testing.T) {
+	role := &domain.Role{Name: "ops"}
+	entry := newAuditLog(context.Background(), "system", domain.AuditActionRoleCreate, domain.AuditResourceRole, "", (*domain.Role)(nil), role)
+
+	assert.True(t, entry.UserID.IsZero())
+	assert.Nil(t, entry.Before)
+	assert.Equal(t, "ops", entry.After["name"])
+	require.Len(t, entry.Changes, 1)
+	assert.Equal(t, domain.AuditChange{Field: "name", After: "ops"}, entry.Changes[0])
+}
+
+func TestUserManagementIsAudited(t *testing.T) {
+	operatorID := bson.NewObjectID()
+	operator := domain.Claims{UID: operatorID.Hex()}
+	userID := bson.NewObjectID()
+	stored := &domain.User{BaseEntity: domain.BaseEntity{ID: userID}, UserName: "alice", Password: "hash"}
+
+	mockRepo := domain.NewMockRepository(t)
+	mockRepo.EXPECT().CreateUser(mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.EXPECT().UpdateUser(mock.Anything, mock.Anything).Return(nil).Twice()
+	mockRepo.EXPECT().
+		QueryUsers(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryUserOptions) {
+			opt.Result = []*domain.User{stored}
+		}).
+		Return(nil).Twice()
+	var actions []string
+	mockRepo.EXPECT().
+		CreateAuditLog(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, entry *domain.AuditLog) {
+			actions = append(actions, entry.Action)
+			assert.Equal(t, operatorID, entry.UserID)
+			assert.NotContains(t, entry.Before, "password")
+			assert.NotContains(t, entry.After, "password")
+		}).
+		Return(nil).Times(3)
+	svc := &Service{Repo: mockRepo}
+
+	require.NoError(t, svc.CreateUser(context.Background(), operator, &domain.User{UserName: "bob", Password: "hash"}))
+	require.NoError(t, svc.UpdateUser(context.Background(), operator, &domain.User{BaseEntity: domain.BaseEntity{ID: userID}, UserName: "alice2"}))
+	require.NoError(t, svc.DeleteUser(context.Background(), operator, userID))
+
+	assert.Equal(t, []string{domain.AuditActionUserCreate, domain.AuditActionUserUpdate, domain.AuditActionUserDelete}, actions)
+}
@@ -7,6 +7,8 @@ import (
 	goerrors "errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
@@ -32,6 +34,7 @@ func (svc *Service) CreateNewUser(ctx context.Context, operator *domain.Claims,
 	if err != nil {
 		return errors.WithMessagef(err, "db: create user %s failed", username)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserCreate, domain.AuditResourceUser, user.ID.Hex(), nil, user)
 	return nil
 }
 
@@ -51,7 +54,12 @@ func (svc *Service) Login(ctx context.Context, username, password string) (domai
 	if !ok {
 		return domain.TokenPair{}, errs.NewHTTPStatusError(http.StatusUnauthorized, "invalid password", fmt.Errorf("compare password for username %s not match", username))
 	}
-	return svc.issueTokenPair(ctx, user)
+	tokens, err := svc.issueTokenPair(ctx, user)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	svc.recordAudit(ctx, user.ID.Hex(), domain.AuditActionLogin, domain.AuditResourceUser, user.ID.Hex(), nil, nil)
+	return tokens, nil
 }
 
 func (svc *Service) RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
@@ -142,7 +150,11 @@ func (svc *Service) Logout(ctx context.Context, refreshToken string) error {
 	}
 
 	user.UpdatedTime = time.Now().UnixMilli()
-	return svc.Repo.UpdateUser(ctx, user)
+	if err := svc.Repo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, user.ID.Hex(), domain.AuditActionLogout, domain.AuditResourceUser, user.ID.Hex(), nil, nil)
+	return nil
 }
 
 func (svc *Service) LogoutAll(ctx context.Context, userClaims *domain.Claims) error {
@@ -158,7 +170,11 @@ func (svc *Service) LogoutAll(ctx context.Context, userClaims *domain.Claims) er
 	user.TokenVersion++
 	user.RefreshTokens = nil
 	user.UpdatedTime = time.Now().UnixMilli()
-	return svc.Repo.UpdateUser(ctx, user)
+	if err := svc.Repo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, user.ID.Hex(), domain.AuditActionLogoutAll, domain.AuditResourceUser, user.ID.Hex(), nil, nil)
+	return nil
 }
 
 func (svc *Service) issueTokenPair(ctx context.Context, user *domain.User) (domain.TokenPair, error) {
@@ -295,6 +311,7 @@ func (svc *Service) ChangePassword(ctx context.Context, userClaims *domain.Claim
 	if !ok {
 		return errs.NewHTTPStatusError(http.StatusUnauthorized, "invalid password", fmt.Errorf("change password failed, compare password for uid %s not match", uid))
 	}
+	before := *user
 	user.Status = domain.UserStatusActive
 	user.Password = domain.EncryptedPassword(newPassword)
 	user.UpdatedTime = time.Now().UnixMilli()
@@ -303,6 +320,7 @@ func (svc *Service) ChangePassword(ctx context.Context, userClaims *domain.Claim
 	if err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, userClaims.UID, domain.AuditActionUserPasswordChange, domain.AuditResourceUser, uid.Hex(), &before, user)
 	return nil
 }
 
@@ -320,6 +338,7 @@ func (svc *Service) UpdateUserPermissions(ctx context.Context, operator *domain.
 	if err != nil {
 		return err
 	}
+	before := *user
 	if opt.Roles != nil {
 		query := &domain.QueryRoleOptions{
 			Names: *opt.Roles,
@@ -342,6 +361,7 @@ func (svc *Service) UpdateUserPermissions(ctx context.Context, operator *domain.
 	if err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserPermissionUpdate, domain.AuditResourceUser, uid.Hex(), &before, user)
 	return nil
 }
 
@@ -359,6 +379,7 @@ func (svc *Service) ResetPassword(ctx context.Context, operator *domain.Claims,
 	if err != nil {
 		return err
 	}
+	before := *user
 	user.Password = domain.EncryptedPassword(newPassword)
 	user.Status = domain.UserStatusWaitChangePassword
 	user.UpdatedTime = time.Now().UnixMilli()
@@ -367,6 +388,7 @@ func (svc *Service) ResetPassword(ctx context.Context, operator *domain.Claims,
 	if err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserPasswordReset, domain.AuditResourceUser, uid.Hex(), &before, user)
 	return nil
 }
 
@@ -438,31 +460,57 @@ func (svc *Service) VerifyJWTToken(ctx context.Context, tokenString string, perm
 		return *claims, domain.RolePolicy{}, nil
 	}
 
+	rolePolicy, err := svc.userRolePolicy(ctx, user, permissionKey)
+	if err != nil {
+		return domain.Claims{}, domain.RolePolicy{}, err
+	}
+	return *claims, rolePolicy, nil
+}
+
+// userRolePolicy returns the policy the roles of user grant for
+// permissionKey, or a 403 error when none of them grants it.
+func (svc *Service) userRolePolicy(ctx context.Context, user *domain.User, permissionKey domain.PermissionKey) (domain.RolePolicy, error) {
 	roles, err := svc.getRolesByNames(ctx, user.Roles)
 	if err != nil {
-		return domain.Claims{}, domain.RolePolicy{}, errors.WithMessage(err, "get roles by IDs failed")
+		return domain.RolePolicy{}, errors.WithMessage(err, "get roles by IDs failed")
 	}
 	if len(roles) == 0 {
-		return domain.Claims{}, domain.RolePolicy{}, errs.NewHTTPStatusError(http.StatusForbidden, "permission denied", fmt.Errorf("user %s has no roles assigned", claims.UID))
+		return domain.RolePolicy{}, errs.NewHTTPStatusError(http.StatusForbidden, "permission denied", fmt.Errorf("user %s has no roles assigned", user.ID.Hex()))
 	}
 	hasPermission := false
 	rolePolicy := domain.RolePolicy{}
 	for _, role := range roles {
 		for _, policy := range role.Policies {
-			if policy.PermissionKey == permissionKey {
+			if policy.PermissionKey != permissionKey {
+				continue
+			}
+			if !hasPermission {
 				hasPermission = true
 				rolePolicy = policy
-				break
+				continue
 			}
-		}
-		if hasPermission {
-			break
+			// Several roles may grant the same permission; the caller gets
+			// the union of their namespaces.
+			rolePolicy.K8SNamespace = mergeK8SNamespaces(rolePolicy, policy)
 		}
 	}
 	if !hasPermission {
-		return domain.Claims{}, domain.RolePolicy{}, errs.NewHTTPStatusError(http.StatusForbidden, "permission denied", fmt.Errorf("user %s does not have permission %s", claims.UID, permissionKey))
+		return domain.RolePolicy{}, errs.NewHTTPStatusError(http.StatusForbidden, "permission denied", fmt.Errorf("user %s does not have permission %s", user.ID.Hex(), permissionKey))
 	}
-	return *claims, rolePolicy, nil
+	return rolePolicy, nil
+}
+
+func mergeK8SNamespaces(a, b domain.RolePolicy) string {
+	nsA, nsB := a.AllowedK8SNamespaces(), b.AllowedK8SNamespaces()
+	if nsA == nil || nsB == nil {
+		return domain.AllK8SNamespaces
+	}
+	for _, ns := range nsB {
+		if !slices.Contains(nsA, ns) {
+			nsA = append(nsA, ns)
+		}
+	}
+	return strings.Join(nsA, ",")
 }
 
 func (svc *Service) CreateAdminUserIfNotExists(ctx context.Context, username, password string) error {
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+)
+
+type classifierStateRepository interface {
+	ClaimClassifierShard(ctx context.Context, state *domain.ClassifierShardState) error
+	ReleaseClassifierShard(ctx context.Context, shard int, owner string) error
+	QueryClassifierShardStates(ctx context.Context, opt *domain.QueryClassifierShardStateOptions) error
+	HeartbeatClassifierReplica(ctx context.Context, replica *domain.ClassifierReplica) error
+	QueryClassifierReplicas(ctx context.Context, opt *domain.QueryClassifierReplicaOptions) error
+}
+
+// SyncClassifierShards persists the snapshots of the classifier shards the
+// replica ingests and rebalances shard ownership between live replicas.
+//
+// Every replica keeps at most ceil(shards/replicas) leases: it renews the
+// shards it holds in shard order, releases the ones above its share and
+// claims free or expired shards until it reaches it. A replica counts as live
+// while its heartbeat is younger than one lease. The returned slice has one
+// state per shard; shards the replica did not hold before carry the payload
+// it has to restore.
+func (svc *Service) SyncClassifierShards(ctx context.Context, sync *domain.ClassifierShardSync) ([]*domain.ClassifierShardState, error) {
+	repo, ok := svc.Repo.(classifierStateRepository)
+	if !ok {
+		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "classifier state repository is not enabled", nil)
+	}
+	if sync == nil || sync.Replica.ID == "" || sync.ShardCount <= 0 || sync.LeaseSeconds <= 0 {
+		return nil, errors.New("classifier shard sync requires a replica id, shard count and lease")
+	}
+
+	replica := sync.Replica
+	if err := repo.HeartbeatClassifierReplica(ctx, &replica); err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	leaseMilli := sync.LeaseSeconds * 1000
+	replicaOpt := &domain.QueryClassifierReplicaOptions{SeenAfter: now - leaseMilli}
+	if err := repo.QueryClassifierReplicas(ctx, replicaOpt); err != nil {
+		return nil, err
+	}
+	liveReplicas := max(len(replicaOpt.Result), 1)
+	share := (sync.ShardCount + liveReplicas - 1) / liveReplicas
+
+	stateOpt := &domain.QueryClassifierShardStateOptions{}
+	if err := repo.QueryClassifierShardStates(ctx, stateOpt); err != nil {
+		return nil, err
+	}
+	states := make([]*domain.ClassifierShardState, sync.ShardCount)
+	for _, state := range stateOpt.Result {
+		if state.Shard >= 0 && state.Shard < sync.ShardCount {
+			states[state.Shard] = state
+		}
+	}
+
+	claim := func(shard int) (bool, error) {
+		state := &domain.ClassifierShardState{
+			Shard:          shard,
+			Owner:          replica.ID,
+			OwnerAddress:   replica.Address,
+			LeaseExpiresAt: now + leaseMilli,
+			Payload:        sync.Snapshots[shard],
+		}
+		if err := repo.ClaimClassifierShard(ctx, state); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				return false, nil
+			}
+			return false, fmt.Errorf("claim classifier shard %d: %w", shard, err)
+		}
+		states[shard] = state
+		return true, nil
+	}
+
+	held := 0
+	for shard, state := range states {
+		if state == nil || state.Owner != replica.ID {
+			continue
+		}
+		// Renewing also stores the latest snapshot, so a released shard
+		// hands over up-to-date state.
+		ok, err := claim(shard)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Another replica took the shard over since it was queried:
+			// report the current owner instead of this replica, so the
+			// shard is no longer ingested here.
+			lost, err := queryClassifierShardState(ctx, repo, shard)
+			if err != nil {
+				return nil, err
+			}
+			if lost.Owner == replica.ID {
+				lost.Owner = ""
+				lost.OwnerAddress = ""
+				lost.LeaseExpiresAt = 0
+			}
+			states[shard] = lost
+			continue
+		}
+		if held < share {
+			held++
+			continue
+		}
+		if err := repo.ReleaseClassifierShard(ctx, shard, replica.ID); err != nil {
+			return nil, err
+		}
+		states[shard].Owner = ""
+		states[shard].OwnerAddress = ""
+		states[shard].LeaseExpiresAt = 0
+	}
+	for shard, state := range states {
+		if held >= share {
+			break
+		}
+		if state != nil && (state.Owner == replica.ID || state.LeaseActive(now)) {
+			continue
+		}
+		ok, err := claim(shard)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			held++
+		}
+	}
+
+	for shard, state := range states {
+		if state == nil {
+			states[shard] = &domain.ClassifierShardState{Shard: shard}
+		}
+	}
+	return states, nil
+}
+
+// queryClassifierShardState returns the stored state of one shard, or an
+// unowned state when none is stored.
+func queryClassifierShardState(ctx context.Context, repo classifierStateRepository, shard int) (*domain.ClassifierShardState, error) {
+	opt := &domain.QueryClassifierShardStateOptions{Shards: []int{shard}}
+	if err := repo.QueryClassifierShardStates(ctx, opt); err != nil {
+		return nil, err
+	}
+	for _, state := range opt.Result {
+		if state.Shard == shard {
+			return state, nil
+		}
+	}
+	return &domain.ClassifierShardState{Shard: shard}, nil
+}
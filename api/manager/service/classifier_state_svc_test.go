@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClassifierStateRepo keeps shard leases and replica heartbeats in memory
+// and enforces leases like the Mongo repository.
+type fakeClassifierStateRepo struct {
+	domain.Repository
+	shards   map[int]*domain.ClassifierShardState
+	replicas map[string]*domain.ClassifierReplica
+}
+
+func newFakeClassifierStateRepo() *fakeClassifierStateRepo {
+	return &fakeClassifierStateRepo{
+		shards:   map[int]*domain.ClassifierShardState{},
+		replicas: map[string]*domain.ClassifierReplica{},
+	}
+}
+
+func (r *fakeClassifierStateRepo) ClaimClassifierShard(_ context.Context, state *domain.ClassifierShardState) error {
+	now := time.Now().UnixMilli()
+	stored, ok := r.shards[state.Shard]
+	if !ok {
+		stored = &domain.ClassifierShardState{Shard: state.Shard}
+		r.shards[state.Shard] = stored
+	}
+	if stored.Owner != state.Owner && stored.LeaseActive(now) {
+		return domain.ErrConflict
+	}
+	stored.Owner = state.Owner
+	stored.OwnerAddress = state.OwnerAddress
+	stored.LeaseExpiresAt = state.LeaseExpiresAt
+	stored.UpdatedAt = now
+	if len(state.Payload) > 0 {
+		stored.Payload = state.Payload
+		stored.SnapshotAt = now
+	}
+	*state = *stored
+	return nil
+}
+
+func (r *fakeClassifierStateRepo) ReleaseClassifierShard(_ context.Context, shard int, owner string) error {
+	if stored, ok := r.shards[shard]; ok && stored.Owner == owner {
+		stored.Owner = ""
+		stored.OwnerAddress = ""
+		stored.LeaseExpiresAt = 0
+	}
+	return nil
+}
+
+func (r *fakeClassifierStateRepo) QueryClassifierShardStates(_ context.Context, opt *domain.QueryClassifierShardStateOptions) error {
+	for _, stored := range r.shards {
+		state := *stored
+		opt.Result = append(opt.Result, &state)
+	}
+	return nil
+}
+
+func (r *fakeClassifierStateRepo) HeartbeatClassifierReplica(_ context.Context, replica *domain.ClassifierReplica) error {
+	replica.LastSeenAt = time.Now().UnixMilli()
+	stored := *replica
+	r.replicas[replica.ID] = &stored
+	return nil
+}
+
+func (r *fakeClassifierStateRepo) QueryClassifierReplicas(_ context.Context, opt *domain.QueryClassifierReplicaOptions) error {
+	for _, replica := range r.replicas {
+		if replica.LastSeenAt > opt.SeenAfter {
+			opt.Result = append(opt.Result, replica)
+		}
+	}
+	return nil
+}
+
+func classifierSync(replicaID string, snapshots map[int][]byte) *domain.ClassifierShardSync {
+	return &domain.ClassifierShardSync{
+		Replica:      domain.ClassifierReplica{ID: replicaID, Address: replicaID + ":8080"},
+		ShardCount:   4,
+		LeaseSeconds: 60,
+		Snapshots:    snapshots,
+	}
+}
+
+func ownedShards(states []*domain.ClassifierShardState, replicaID string) []int {
+	var shards []int
+	for _, state := range states {
+		if state.Owner == replicaID {
+			shards = append(shards, state.Shard)
+		}
+	}
+	return shards
+}
+
+func TestSyncClassifierShardsSpreadsShardsOverReplicas(t *testing.T) {
+	repo := newFakeClassifierStateRepo()
+	svc := &Service{Repo: repo}
+	ctx := context.Background()
+
+	states, err := svc.SyncClassifierShards(ctx, classifierSync("replica-a", nil))
+	require.NoError(t, err)
+	require.Len(t, states, 4)
+	assert.Equal(t, []int{0, 1, 2, 3}, ownedShards(states, "replica-a"), "a lone replica ingests every shard")
+
+	// A second replica sees every shard leased and waits for its share.
+	states, err = svc.SyncClassifierShards(ctx, classifierSync("replica-b", nil))
+	require.NoError(t, err)
+	assert.Empty(t, ownedShards(states, "replica-b"))
+	assert.Equal(t, "replica-a:8080", states[3].OwnerAddress)
+
+	// The first replica saves its snapshots and releases the shards above
+	// its share, which the second one then takes over together with them.
+	snapshots := map[int][]byte{0: []byte("s0"), 1: []byte("s1"), 2: []byte("s2"), 3: []byte("s3")}
+	states, err = svc.SyncClassifierShards(ctx, classifierSync("replica-a", snapshots))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, ownedShards(states, "replica-a"))
+	assert.Equal(t, []byte("s3"), repo.shards[3].Payload)
+
+	states, err = svc.SyncClassifierShards(ctx, classifierSync("replica-b", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ownedShards(states, "replica-b"))
+	assert.Equal(t, []byte("s2"), states[2].Payload)
+}
+
+func TestSyncClassifierShardsTakesOverExpiredLeases(t *testing.T) {
+	repo := newFakeClassifierStateRepo()
+	svc := &Service{Repo: repo}
+	ctx := context.Background()
+
+	_, err := svc.SyncClassifierShards(ctx, classifierSync("replica-a", nil))
+	require.NoError(t, err)
+	// replica-a stops syncing: its heartbeat and leases run out.
+	repo.replicas["replica-a"].LastSeenAt -= 61_000
+	for _, state := range repo.shards {
+		state.LeaseExpiresAt = time.Now().Add(-time.Second).UnixMilli()
+	}
+
+	states, err := svc.SyncClassifierShards(ctx, classifierSync("replica-b", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, ownedShards(states, "replica-b"))
+}
+
+// staleShardStateRepo answers the first shard query with a stale snapshot,
+// as if another replica claimed shards right after it was read.
+type staleShardStateRepo struct {
+	*fakeClassifierStateRepo
+	stale []*domain.ClassifierShardState
+}
+
+func (r *staleShardStateRepo) QueryClassifierShardStates(ctx context.Context, opt *domain.QueryClassifierShardStateOptions) error {
+	if r.stale != nil {
+		opt.Result, r.stale = r.stale, nil
+		return nil
+	}
+	return r.fakeClassifierStateRepo.QueryClassifierShardStates(ctx, opt)
+}
+
+func TestSyncClassifierShardsDropsShardLostOnRenew(t *testing.T) {
+	repo := &staleShardStateRepo{fakeClassifierStateRepo: newFakeClassifierStateRepo()}
+	svc := &Service{Repo: repo}
+	ctx := context.Background()
+
+	_, err := svc.SyncClassifierShards(ctx, classifierSync("replica-a", nil))
+	require.NoError(t, err)
+	for _, state := range repo.shards {
+		stale := *state
+		repo.stale = append(repo.stale, &stale)
+	}
+	// replica-b took shard 1 over after replica-a read the shard states.
+	repo.shards[1].Owner = "replica-b"
+	repo.shards[1].OwnerAddress = "replica-b:8080"
+
+	states, err := svc.SyncClassifierShards(ctx, classifierSync("replica-a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 3}, ownedShards(states, "replica-a"))
+	assert.Equal(t, "replica-b:8080", states[1].OwnerAddress)
+}
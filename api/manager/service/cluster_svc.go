@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+)
+
+// clusterFederation is implemented by K8S adapters that federate several
+// clusters.
+type clusterFederation interface {
+	Clusters() []string
+}
+
+// ListClusters returns the names of the clusters the manager federates.
+func (svc *Service) ListClusters(ctx context.Context) ([]string, error) {
+	federation, ok := svc.K8SAdapter.(clusterFederation)
+	if !ok {
+		return nil, domain.ErrNoClient
+	}
+	return federation.Clusters(), nil
+}
+
+// validateClusterSelector rejects cluster selectors naming clusters the
+// manager does not federate, which would select nothing.
+func (svc *Service) validateClusterSelector(selector []string) error {
+	if len(selector) == 0 {
+		return nil
+	}
+	federation, ok := svc.K8SAdapter.(clusterFederation)
+	if !ok {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "cluster selectors need a multi-cluster manager", nil)
+	}
+	known := make(map[string]struct{})
+	for _, name := range federation.Clusters() {
+		known[name] = struct{}{}
+	}
+	var unknown []string
+	for _, name := range selector {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, fmt.Sprintf("unknown clusters in clusterSelector: %s", strings.Join(unknown, ", ")), nil)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	k8sadapter "github.com/Gthulhu/api/manager/k8s_adapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateClusterSelector(t *testing.T) {
+	federation, err := k8sadapter.NewMultiClusterAdapter(
+		k8sadapter.Cluster{Name: "east", Adapter: domain.NewMockK8SAdapter(t)},
+		k8sadapter.Cluster{Name: "west", Adapter: domain.NewMockK8SAdapter(t)},
+	)
+	require.NoError(t, err)
+	svc := &Service{K8SAdapter: federation}
+
+	clusters, err := svc.ListClusters(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"east", "west"}, clusters)
+
+	assert.NoError(t, svc.validateClusterSelector(nil))
+	assert.NoError(t, svc.validateClusterSelector([]string{"west"}))
+
+	err = svc.validateClusterSelector([]string{"west", "north"})
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Contains(t, httpErr.Message, "north")
+
+	single := &Service{K8SAdapter: domain.NewMockK8SAdapter(t)}
+	assert.NoError(t, single.validateClusterSelector(nil))
+	_, ok = errs.IsHTTPStatusError(single.validateClusterSelector([]string{"east"}))
+	assert.True(t, ok)
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// defaultPSMCollectionIntervalSeconds is what CreatePodSchedulingMetrics
+// stores when the interval is left empty.
+const defaultPSMCollectionIntervalSeconds = 10
+
+// errConfigBundleBypassesRollout refuses node runtime configs of a bundle
+// applied without SkipRollout.
+var errConfigBundleBypassesRollout = errors.New("node runtime configs in a bundle bypass staged rollouts; apply with skipRollout=true or use a runtime config rollout")
+
+// configBundlePermissions maps each bundle step to the permission of the
+// endpoint it stands in for.
+var configBundlePermissions = map[domain.ConfigBundleResource]map[domain.ConfigBundleOperation]domain.PermissionKey{
+	domain.ConfigBundleResourceRole: {
+		domain.ConfigBundleOperationCreate: domain.RoleCrete,
+		domain.ConfigBundleOperationUpdate: domain.RoleUpdate,
+	},
+	domain.ConfigBundleResourcePodSchedulingMetrics: {
+		domain.ConfigBundleOperationCreate: domain.PSMCreate,
+		domain.ConfigBundleOperationUpdate: domain.PSMUpdate,
+		domain.ConfigBundleOperationDelete: domain.PSMDelete,
+	},
+	domain.ConfigBundleResourceNodePolicy: {
+		domain.ConfigBundleOperationCreate: domain.NodeSchedulingPolicyCreate,
+		domain.ConfigBundleOperationUpdate: domain.NodeSchedulingPolicyUpdate,
+		domain.ConfigBundleOperationDelete: domain.NodeSchedulingPolicyDelete,
+	},
+	domain.ConfigBundleResourceStrategy: {
+		domain.ConfigBundleOperationCreate: domain.ScheduleStrategyCreate,
+		domain.ConfigBundleOperationUpdate: domain.ScheduleStrategyUpdate,
+		domain.ConfigBundleOperationDelete: domain.ScheduleStrategyDelete,
+	},
+	domain.ConfigBundleResourceNodeRuntimeConfig: {
+		domain.ConfigBundleOperationCreate: domain.SchedulerConfigUpdate,
+		domain.ConfigBundleOperationUpdate: domain.SchedulerConfigUpdate,
+	},
+}
+
+// configBundleReadPermissions maps each bundle resource to the permission of
+// its list endpoint.
+var configBundleReadPermissions = map[domain.ConfigBundleResource]domain.PermissionKey{
+	domain.ConfigBundleResourceRole:                 domain.RoleRead,
+	domain.ConfigBundleResourcePodSchedulingMetrics: domain.PSMRead,
+	domain.ConfigBundleResourceNodePolicy:           domain.NodeSchedulingPolicyRead,
+	domain.ConfigBundleResourceStrategy:             domain.ScheduleStrategyRead,
+	domain.ConfigBundleResourceNodeRuntimeConfig:    domain.SchedulerConfigRead,
+}
+
+// ExportConfigBundle returns the operator's strategies and node scheduling
+// policies together with the pod scheduling metrics, roles and node runtime
+// configs, each sorted by key so that exports diff cleanly. Like the list
+// endpoints, every resource needs its read permission and is left out
+// without it, and strategies and pod scheduling metrics outside the
+// namespaces that permission grants are left out too.
+func (svc *Service) ExportConfigBundle(ctx context.Context, operator *domain.Claims) (*domain.ConfigBundle, error) {
+	bundle, err := svc.currentConfigBundle(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+	authorize, err := svc.configBundleAuthorizer(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+	readable := func(resource domain.ConfigBundleResource) (func(namespaces ...string) bool, error) {
+		readCtx, err := authorize(configBundleReadPermissions[resource])
+		var httpErr *errs.HTTPStatusError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden {
+			return func(...string) bool { return false }, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(namespaces ...string) bool {
+			return verifyK8SNamespacePolicy(readCtx, namespaces) == nil
+		}, nil
+	}
+
+	allowed, err := readable(domain.ConfigBundleResourceStrategy)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Strategies = slices.DeleteFunc(bundle.Strategies, func(s *domain.ScheduleStrategy) bool { return !allowed(s.K8sNamespace...) })
+	if allowed, err = readable(domain.ConfigBundleResourceNodePolicy); err != nil {
+		return nil, err
+	}
+	bundle.NodePolicies = slices.DeleteFunc(bundle.NodePolicies, func(*domain.NodeSchedulingPolicy) bool { return !allowed() })
+	if allowed, err = readable(domain.ConfigBundleResourcePodSchedulingMetrics); err != nil {
+		return nil, err
+	}
+	bundle.PodSchedulingMetrics = slices.DeleteFunc(bundle.PodSchedulingMetrics, func(p *domain.PodSchedulingMetrics) bool { return !allowed(p.K8sNamespaces...) })
+	if allowed, err = readable(domain.ConfigBundleResourceRole); err != nil {
+		return nil, err
+	}
+	bundle.Roles = slices.DeleteFunc(bundle.Roles, func(*domain.Role) bool { return !allowed() })
+	if allowed, err = readable(domain.ConfigBundleResourceNodeRuntimeConfig); err != nil {
+		return nil, err
+	}
+	bundle.NodeRuntimeConfigs = slices.DeleteFunc(bundle.NodeRuntimeConfigs, func(*domain.NodeRuntimeConfig) bool { return !allowed() })
+	return bundle, nil
+}
+
+// currentConfigBundle returns the operator's strategies and node scheduling
+// policies together with every pod scheduling metrics, role and node runtime
+// config, each sorted by key.
+func (svc *Service) currentConfigBundle(ctx context.Context, operator *domain.Claims) (*domain.ConfigBundle, error) {
+	operatorID, err := operator.GetBsonObjectUID()
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid user ID"))
+	}
+
+	strategyOpt := &domain.QueryStrategyOptions{CreatorIDs: []bson.ObjectID{operatorID}}
+	if err := svc.Repo.QueryStrategies(ctx, strategyOpt); err != nil {
+		return nil, fmt.Errorf("query strategies: %w", err)
+	}
+	policyOpt := &domain.QueryNodePolicyOptions{CreatorIDs: []bson.ObjectID{operatorID}}
+	if err := svc.Repo.QueryNodePolicies(ctx, policyOpt); err != nil {
+		return nil, fmt.Errorf("query node policies: %w", err)
+	}
+	psmOpt := &domain.QueryPSMOptions{}
+	if err := svc.Repo.QueryPSMs(ctx, psmOpt); err != nil {
+		return nil, fmt.Errorf("query pod scheduling metrics: %w", err)
+	}
+	roleOpt := &domain.QueryRoleOptions{}
+	if err := svc.Repo.QueryRoles(ctx, roleOpt); err != nil {
+		return nil, fmt.Errorf("query roles: %w", err)
+	}
+	runtimeConfigOpt := &domain.QueryNodeRuntimeConfigOptions{}
+	if repo, ok := svc.Repo.(runtimeConfigRepository); ok {
+		if err := repo.QueryNodeRuntimeConfigs(ctx, runtimeConfigOpt); err != nil {
+			return nil, fmt.Errorf("query node runtime configs: %w", err)
+		}
+	}
+
+	bundle := &domain.ConfigBundle{
+		Strategies:           strategyOpt.Result,
+		NodePolicies:         policyOpt.Result,
+		PodSchedulingMetrics: psmOpt.Result,
+		Roles:                roleOpt.Result,
+		NodeRuntimeConfigs:   runtimeConfigOpt.Result,
+	}
+	sort.Slice(bundle.Strategies, func(i, j int) bool {
+		return bundle.Strategies[i].ID.Hex() < bundle.Strategies[j].ID.Hex()
+	})
+	sort.Slice(bundle.NodePolicies, func(i, j int) bool {
+		return bundle.NodePolicies[i].ID.Hex() < bundle.NodePolicies[j].ID.Hex()
+	})
+	sort.Slice(bundle.PodSchedulingMetrics, func(i, j int) bool {
+		return bundle.PodSchedulingMetrics[i].ID.Hex() < bundle.PodSchedulingMetrics[j].ID.Hex()
+	})
+	sort.Slice(bundle.Roles, func(i, j int) bool {
+		return bundle.Roles[i].Name < bundle.Roles[j].Name
+	})
+	sort.Slice(bundle.NodeRuntimeConfigs, func(i, j int) bool {
+		return bundle.NodeRuntimeConfigs[i].NodeID < bundle.NodeRuntimeConfigs[j].NodeID
+	})
+	return bundle, nil
+}
+
+// ApplyConfigBundle diffs the bundle against the current objects and, unless
+// it is a dry run, executes the plan through the same service methods as the
+// REST endpoints, so intents, decision makers and the audit log are updated
+// as usual. Every step requires the permission of its endpoint and runs with
+// the namespace policy that permission grants; config_bundle.apply alone
+// changes nothing. Changed node runtime configs are sent to the nodes at
+// once, so they are only applied with SkipRollout. A failing step does not
+// stop the others; its error is reported on the returned change.
+func (svc *Service) ApplyConfigBundle(ctx context.Context, operator *domain.Claims, opt *domain.ApplyConfigBundleOptions) ([]domain.ConfigBundleChange, error) {
+	if opt == nil || opt.Bundle == nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "invalid request", fmt.Errorf("config bundle is nil"))
+	}
+	desired := opt.Bundle
+	if err := validateConfigBundle(desired); err != nil {
+		return nil, err
+	}
+	current, err := svc.currentConfigBundle(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+	for _, psm := range desired.PodSchedulingMetrics {
+		if psm.CollectionIntervalSeconds == 0 {
+			psm.CollectionIntervalSeconds = defaultPSMCollectionIntervalSeconds
+		}
+	}
+	for _, cfg := range desired.NodeRuntimeConfigs {
+		cfg.Config.Normalize()
+	}
+
+	authorize, err := svc.configBundleAuthorizer(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []domain.ConfigBundleChange
+	run := func(change domain.ConfigBundleChange, apply func(ctx context.Context) error) {
+		if change.Operation == domain.ConfigBundleOperationUnchanged {
+			changes = append(changes, change)
+			return
+		}
+		stepCtx, err := authorize(configBundlePermissions[change.Resource][change.Operation])
+		if err == nil && change.Resource == domain.ConfigBundleResourceNodeRuntimeConfig {
+			change.BypassesRollout = true
+			if !opt.SkipRollout {
+				err = errConfigBundleBypassesRollout
+			}
+		}
+		if err == nil && !opt.DryRun {
+			err = apply(stepCtx)
+		}
+		if err != nil {
+			change.Error = err.Error()
+			logger.Logger(ctx).Warn().Err(err).Msgf("apply config bundle: %s %s %s failed", change.Operation, change.Resource, change.Key)
+		}
+		changes = append(changes, change)
+	}
+
+	for _, step := range planConfigBundle(domain.ConfigBundleResourceRole, desired.Roles, current.Roles, false,
+		func(r *domain.Role) string { return r.Name },
+		func(a, b *domain.Role) bool { return sameSpec(stripRole(a), stripRole(b)) }) {
+		run(step.change, func(ctx context.Context) error {
+			if step.current == nil {
+				return svc.CreateRole(ctx, operator, &domain.Role{Name: step.desired.Name, Description: step.desired.Description, Policies: step.desired.Policies})
+			}
+			return svc.UpdateRole(ctx, operator, step.current.ID.Hex(), domain.UpdateRoleOptions{
+				Description: &step.desired.Description,
+				Policies:    &step.desired.Policies,
+			})
+		})
+	}
+
+	for _, step := range planConfigBundle(domain.ConfigBundleResourcePodSchedulingMetrics, desired.PodSchedulingMetrics, current.PodSchedulingMetrics, opt.Prune,
+		func(p *domain.PodSchedulingMetrics) string { return p.ID.Hex() },
+		func(a, b *domain.PodSchedulingMetrics) bool { return sameSpec(stripPSM(a), stripPSM(b)) }) {
+		run(step.change, func(ctx context.Context) error {
+			switch step.change.Operation {
+			case domain.ConfigBundleOperationCreate:
+				return svc.CreatePodSchedulingMetrics(ctx, operator, stripPSM(step.desired))
+			case domain.ConfigBundleOperationUpdate:
+				return svc.UpdatePodSchedulingMetrics(ctx, operator, step.change.Key, stripPSM(step.desired))
+			default:
+				return svc.DeletePodSchedulingMetrics(ctx, operator, step.change.Key)
+			}
+		})
+	}
+
+	for _, step := range planConfigBundle(domain.ConfigBundleResourceNodePolicy, desired.NodePolicies, current.NodePolicies, opt.Prune,
+		func(p *domain.NodeSchedulingPolicy) string { return p.ID.Hex() },
+		func(a, b *domain.NodeSchedulingPolicy) bool { return sameSpec(stripNodePolicy(a), stripNodePolicy(b)) }) {
+		run(step.change, func(ctx context.Context) error {
+			switch step.change.Operation {
+			case domain.ConfigBundleOperationCreate:
+				return svc.CreateNodeSchedulingPolicy(ctx, operator, stripNodePolicy(step.desired))
+			case domain.ConfigBundleOperationUpdate:
+				return svc.UpdateNodeSchedulingPolicy(ctx, operator, step.change.Key, stripNodePolicy(step.desired))
+			default:
+				return svc.DeleteNodeSchedulingPolicy(ctx, operator, step.change.Key)
+			}
+		})
+	}
+
+	for _, step := range planConfigBundle(domain.ConfigBundleResourceStrategy, desired.Strategies, current.Strategies, opt.Prune,
+		func(s *domain.ScheduleStrategy) string { return s.ID.Hex() },
+		func(a, b *domain.ScheduleStrategy) bool { return sameSpec(stripStrategy(a), stripStrategy(b)) }) {
+		run(step.change, func(ctx context.Context) error {
+			switch step.change.Operation {
+			case domain.ConfigBundleOperationCreate:
+				return svc.CreateScheduleStrategy(ctx, operator, stripStrategy(step.desired))
+			case domain.ConfigBundleOperationUpdate:
+				return svc.UpdateScheduleStrategy(ctx, operator, step.change.Key, stripStrategy(step.desired))
+			default:
+				return svc.DeleteScheduleStrategy(ctx, operator, step.change.Key)
+			}
+		})
+	}
+
+	for _, step := range planConfigBundle(domain.ConfigBundleResourceNodeRuntimeConfig, desired.NodeRuntimeConfigs, current.NodeRuntimeConfigs, false,
+		func(c *domain.NodeRuntimeConfig) string { return c.NodeID },
+		func(a, b *domain.NodeRuntimeConfig) bool { return reflect.DeepEqual(a.Config, b.Config) }) {
+		run(step.change, func(ctx context.Context) error {
+			results, err := svc.ApplyRuntimeConfig(ctx, operator, &domain.RuntimeConfigApplyOptions{
+				NodeIDs: []string{step.change.Key},
+				Config:  step.desired.Config,
+			})
+			if err != nil {
+				return err
+			}
+			for _, result := range results {
+				if !result.Success {
+					return fmt.Errorf("node %s: %s", result.NodeID, result.Error)
+				}
+			}
+			return nil
+		})
+	}
+
+	logger.Logger(ctx).Info().Msgf("applied config bundle with %d changes (dryRun=%t, prune=%t)", len(changes), opt.DryRun, opt.Prune)
+	return changes, nil
+}
+
+// configBundleAuthorizer returns a function that checks the operator holds a
+// permission and returns ctx carrying the role policy it grants, which the
+// service methods check namespaces against. Without a role policy in ctx the
+// bundle is applied by the manager itself and is not restricted.
+func (svc *Service) configBundleAuthorizer(ctx context.Context, operator *domain.Claims) (func(domain.PermissionKey) (context.Context, error), error) {
+	if _, ok := domain.RolePolicyFromContext(ctx); !ok {
+		return func(domain.PermissionKey) (context.Context, error) { return ctx, nil }, nil
+	}
+	operatorID, err := operator.GetBsonObjectUID()
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid user ID"))
+	}
+	user, err := svc.getUserByID(ctx, operatorID)
+	if err != nil {
+		return nil, err
+	}
+	return func(permissionKey domain.PermissionKey) (context.Context, error) {
+		rolePolicy, err := svc.userRolePolicy(ctx, user, permissionKey)
+		if err != nil {
+			return nil, err
+		}
+		return domain.WithRolePolicy(ctx, rolePolicy), nil
+	}, nil
+}
+
+func validateConfigBundle(bundle *domain.ConfigBundle) error {
+	keys := map[domain.ConfigBundleResource][]string{}
+	for _, s := range bundle.Strategies {
+		if s.ID.IsZero() {
+			return errs.NewHTTPStatusError(http.StatusBadRequest, "every strategy in the bundle needs an ID", nil)
+		}
+		keys[domain.ConfigBundleResourceStrategy] = append(keys[domain.ConfigBundleResourceStrategy], s.ID.Hex())
+	}
+	for _, p := range bundle.NodePolicies {
+		if p.ID.IsZero() {
+			return errs.NewHTTPStatusError(http.StatusBadRequest, "every node scheduling policy in the bundle needs an ID", nil)
+		}
+		keys[domain.ConfigBundleResourceNodePolicy] = append(keys[domain.ConfigBundleResourceNodePolicy], p.ID.Hex())
+	}
+	for _, p := range bundle.PodSchedulingMetrics {
+		if p.ID.IsZero() {
+			return errs.NewHTTPStatusError(http.StatusBadRequest, "every pod scheduling metrics in the bundle needs an ID", nil)
+		}
+		keys[domain.ConfigBundleResourcePodSchedulingMetrics] = append(keys[domain.ConfigBundleResourcePodSchedulingMetrics], p.ID.Hex())
+	}
+	for _, r := range bundle.Roles {
+		if r.Name == "" {
+			return errs.NewHTTPStatusError(http.StatusBadRequest, "every role in the bundle needs a name", nil)
+		}
+		keys[domain.ConfigBundleResourceRole] = append(keys[domain.ConfigBundleResourceRole], r.Name)
+	}
+	for _, c := range bundle.NodeRuntimeConfigs {
+		if c.NodeID == "" {
+			return errs.NewHTTPStatusError(http.StatusBadRequest, "every node runtime config in the bundle needs a node ID", nil)
+		}
+		keys[domain.ConfigBundleResourceNodeRuntimeConfig] = append(keys[domain.ConfigBundleResourceNodeRuntimeConfig], c.NodeID)
+	}
+	for resource, resourceKeys := range keys {
+		seen := make(map[string]struct{}, len(resourceKeys))
+		for _, key := range resourceKeys {
+			if _, ok := seen[key]; ok {
+				return errs.NewHTTPStatusError(http.StatusBadRequest, fmt.Sprintf("duplicate %s %s in the bundle", resource, key), nil)
+			}
+			seen[key] = struct{}{}
+		}
+	}
+	return nil
+}
+
+type configBundleStep[T any] struct {
+	change           domain.ConfigBundleChange
+	desired, current T
+}
+
+// planConfigBundle pairs desired and current objects by key. Objects that
+// only exist currently are deleted when prune is set and left alone
+// otherwise.
+func planConfigBundle[T any](resource domain.ConfigBundleResource, desired, current []T, prune bool, key func(T) string, same func(a, b T) bool) []configBundleStep[T] {
+	currentByKey := make(map[string]T, len(current))
+	for _, obj := range current {
+		currentByKey[key(obj)] = obj
+	}
+	steps := make([]configBundleStep[T], 0, len(desired))
+	wanted := make(map[string]struct{}, len(desired))
+	for _, obj := range desired {
+		k := key(obj)
+		wanted[k] = struct{}{}
+		step := configBundleStep[T]{
+			change:  domain.ConfigBundleChange{Resource: resource, Key: k, Operation: domain.ConfigBundleOperationCreate},
+			desired: obj,
+		}
+		if existing, ok := currentByKey[k]; ok {
+			step.current = existing
+			step.change.Operation = domain.ConfigBundleOperationUpdate
+			if same(obj, existing) {
+				step.change.Operation = domain.ConfigBundleOperationUnchanged
+			}
+		}
+		steps = append(steps, step)
+	}
+	if !prune {
+		return steps
+	}
+	for _, obj := range current {
+		k := key(obj)
+		if _, ok := wanted[k]; ok {
+			continue
+		}
+		steps = append(steps, configBundleStep[T]{
+			change:  domain.ConfigBundleChange{Resource: resource, Key: k, Operation: domain.ConfigBundleOperationDelete},
+			current: obj,
+		})
+	}
+	return steps
+}
+
+// sameSpec compares two objects by their BSON encoding, so that nil and
+// empty lists, which both are omitted, are equal.
+func sameSpec(a, b any) bool {
+	encodedA, errA := bson.Marshal(a)
+	encodedB, errB := bson.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(encodedA, encodedB)
+}
+
+// The strip helpers copy the declarative part of an object, leaving out
+// metadata and status the bundle does not carry. The copies keep the ID,
+// which is the bundle key.
+
+func stripStrategy(s *domain.ScheduleStrategy) *domain.ScheduleStrategy {
+	c := *s
+	c.BaseEntity = domain.BaseEntity{ID: s.ID}
+	return &c
+}
+
+func stripNodePolicy(p *domain.NodeSchedulingPolicy) *domain.NodeSchedulingPolicy {
+	c := *p
+	c.BaseEntity = domain.BaseEntity{ID: p.ID}
+	return &c
+}
+
+func stripPSM(p *domain.PodSchedulingMetrics) *domain.PodSchedulingMetrics {
+	c := *p
+	c.BaseEntity = domain.BaseEntity{ID: p.ID}
+	c.ScalingStatus = nil
+	return &c
+}
+
+func stripRole(r *domain.Role) *domain.Role {
+	return &domain.Role{Name: r.Name, Description: r.Description, Policies: r.Policies}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeBundleRepo struct {
+	domain.Repository
+	strategies     []*domain.ScheduleStrategy
+	psms           []*domain.PodSchedulingMetrics
+	roles          []*domain.Role
+	users          []*domain.User
+	runtimeConfigs []*domain.NodeRuntimeConfig
+	createdRoles   []*domain.Role
+	updatedRoles   []*domain.Role
+}
+
+func (r *fakeBundleRepo) QueryStrategies(_ context.Context, opt *domain.QueryStrategyOptions) error {
+	opt.Result = append(opt.Result, r.strategies...)
+	return nil
+}
+
+func (r *fakeBundleRepo) QueryNodePolicies(_ context.Context, _ *domain.QueryNodePolicyOptions) error {
+	return nil
+}
+
+func (r *fakeBundleRepo) QueryPSMs(_ context.Context, opt *domain.QueryPSMOptions) error {
+	opt.Result = append(opt.Result, r.psms...)
+	return nil
+}
+
+func (r *fakeBundleRepo) QueryRoles(_ context.Context, opt *domain.QueryRoleOptions) error {
+	for _, role := range r.roles {
+		if len(opt.IDs) > 0 && role.ID != opt.IDs[0] {
+			continue
+		}
+		if len(opt.Names) > 0 && !slices.Contains(opt.Names, role.Name) {
+			continue
+		}
+		opt.Result = append(opt.Result, role)
+	}
+	return nil
+}
+
+func (r *fakeBundleRepo) QueryUsers(_ context.Context, opt *domain.QueryUserOptions) error {
+	opt.Result = append(opt.Result, r.users...)
+	return nil
+}
+
+func (r *fakeBundleRepo) QueryNodeRuntimeConfigs(_ context.Context, opt *domain.QueryNodeRuntimeConfigOptions) error {
+	opt.Result = append(opt.Result, r.runtimeConfigs...)
+	return nil
+}
+
+func (r *fakeBundleRepo) UpsertNodeRuntimeConfig(_ context.Context, _ *domain.NodeRuntimeConfig) error {
+	return nil
+}
+
+func (r *fakeBundleRepo) CreateRole(_ context.Context, role *domain.Role) error {
+	r.createdRoles = append(r.createdRoles, role)
+	return nil
+}
+
+func (r *fakeBundleRepo) UpdateRole(_ context.Context, role *domain.Role) error {
+	r.updatedRoles = append(r.updatedRoles, role)
+	return nil
+}
+
+func (r *fakeBundleRepo) CreateAuditLog(_ context.Context, _ *domain.AuditLog) error {
+	return nil
+}
+
+func TestExportConfigBundleSortsByKey(t *testing.T) {
+	repo := &fakeBundleRepo{
+		roles: []*domain.Role{{Name: "viewer"}, {Name: "admin"}},
+		runtimeConfigs: []*domain.NodeRuntimeConfig{
+			{NodeID: "node-b"}, {NodeID: "node-a"},
+		},
+	}
+	svc := &Service{Repo: repo}
+
+	bundle, err := svc.ExportConfigBundle(context.Background(), &domain.Claims{UID: bson.NewObjectID().Hex()})
+	require.NoError(t, err)
+
+	require.Len(t, bundle.Roles, 2)
+	assert.Equal(t, "admin", bundle.Roles[0].Name)
+	require.Len(t, bundle.NodeRuntimeConfigs, 2)
+	assert.Equal(t, "node-a", bundle.NodeRuntimeConfigs[0].NodeID)
+}
+
+func TestExportConfigBundleFiltersByReadPermission(t *testing.T) {
+	operatorID := bson.NewObjectID()
+	teamA := &domain.ScheduleStrategy{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID(), CreatorID: operatorID}, K8sNamespace: []string{"team-a"}}
+	teamB := &domain.ScheduleStrategy{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID(), CreatorID: operatorID}, K8sNamespace: []string{"team-b"}}
+	repo := &fakeBundleRepo{
+		strategies: []*domain.ScheduleStrategy{teamA, teamB},
+		psms: []*domain.PodSchedulingMetrics{
+			{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, K8sNamespaces: []string{"team-a"}},
+			{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, K8sNamespaces: []string{"team-b"}},
+		},
+		roles: []*domain.Role{{Name: "team-a-viewer", Policies: []domain.RolePolicy{
+			{PermissionKey: domain.ConfigBundleExport},
+			{PermissionKey: domain.ScheduleStrategyRead, K8SNamespace: "team-a"},
+			{PermissionKey: domain.PSMRead, K8SNamespace: "team-a"},
+		}}},
+		users:          []*domain.User{{BaseEntity: domain.BaseEntity{ID: operatorID}, Roles: []string{"team-a-viewer"}}},
+		runtimeConfigs: []*domain.NodeRuntimeConfig{{NodeID: "node-a"}},
+	}
+	svc := &Service{Repo: repo}
+	ctx := domain.WithRolePolicy(context.Background(), domain.RolePolicy{PermissionKey: domain.ConfigBundleExport})
+
+	bundle, err := svc.ExportConfigBundle(ctx, &domain.Claims{UID: operatorID.Hex()})
+	require.NoError(t, err)
+
+	require.Len(t, bundle.Strategies, 1)
+	assert.Equal(t, teamA.ID, bundle.Strategies[0].ID)
+	require.Len(t, bundle.PodSchedulingMetrics, 1)
+	assert.Equal(t, []string{"team-a"}, bundle.PodSchedulingMetrics[0].K8sNamespaces)
+	// Neither role.read nor scheduler_config.read is granted.
+	assert.Empty(t, bundle.Roles)
+	assert.Empty(t, bundle.NodeRuntimeConfigs)
+}
+
+func TestApplyConfigBundleDryRunPlansWithoutChanges(t *testing.T) {
+	unchanged := &domain.ScheduleStrategy{
+		BaseEntity:     domain.BaseEntity{ID: bson.NewObjectID(), CreatedTime: 100},
+		LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "web"}},
+		Priority:       10,
+	}
+	changed := &domain.ScheduleStrategy{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, Priority: 1}
+	pruned := &domain.ScheduleStrategy{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}}
+	psm := &domain.PodSchedulingMetrics{
+		BaseEntity:                domain.BaseEntity{ID: bson.NewObjectID()},
+		CollectionIntervalSeconds: defaultPSMCollectionIntervalSeconds,
+		Enabled:                   true,
+		ScalingStatus:             &domain.PSMScalingStatus{Ready: true},
+	}
+	repo := &fakeBundleRepo{
+		strategies:     []*domain.ScheduleStrategy{unchanged, changed, pruned},
+		psms:           []*domain.PodSchedulingMetrics{psm},
+		roles:          []*domain.Role{{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, Name: "admin"}},
+		runtimeConfigs: []*domain.NodeRuntimeConfig{{NodeID: "node-a", Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v1", Mode: domain.SchedulerModeNone}}},
+	}
+	svc := &Service{Repo: repo}
+	created := bson.NewObjectID()
+
+	changes, err := svc.ApplyConfigBundle(context.Background(), &domain.Claims{UID: bson.NewObjectID().Hex()}, &domain.ApplyConfigBundleOptions{
+		Bundle: &domain.ConfigBundle{
+			Strategies: []*domain.ScheduleStrategy{
+				// Nil and empty lists are the same.
+				{BaseEntity: domain.BaseEntity{ID: unchanged.ID}, LabelSelectors: unchanged.LabelSelectors, K8sNamespace: []string{}, Priority: 10},
+				{BaseEntity: domain.BaseEntity{ID: changed.ID}, Priority: 2},
+				{BaseEntity: domain.BaseEntity{ID: created}},
+			},
+			// The collection interval defaults like on create and the
+			// scaling status is not part of the spec.
+			PodSchedulingMetrics: []*domain.PodSchedulingMetrics{{BaseEntity: domain.BaseEntity{ID: psm.ID}, Enabled: true}},
+			Roles:                []*domain.Role{{Name: "admin"}, {Name: "viewer"}},
+			NodeRuntimeConfigs:   []*domain.NodeRuntimeConfig{{NodeID: "node-a", Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v1"}}},
+		},
+		Prune:  true,
+		DryRun: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.ConfigBundleChange{
+		{Resource: domain.ConfigBundleResourceRole, Key: "admin", Operation: domain.ConfigBundleOperationUnchanged},
+		{Resource: domain.ConfigBundleResourceRole, Key: "viewer", Operation: domain.ConfigBundleOperationCreate},
+		{Resource: domain.ConfigBundleResourcePodSchedulingMetrics, Key: psm.ID.Hex(), Operation: domain.ConfigBundleOperationUnchanged},
+		{Resource: domain.ConfigBundleResourceStrategy, Key: unchanged.ID.Hex(), Operation: domain.ConfigBundleOperationUnchanged},
+		{Resource: domain.ConfigBundleResourceStrategy, Key: changed.ID.Hex(), Operation: domain.ConfigBundleOperationUpdate},
+		{Resource: domain.ConfigBundleResourceStrategy, Key: created.Hex(), Operation: domain.ConfigBundleOperationCreate},
+		{Resource: domain.ConfigBundleResourceStrategy, Key: pruned.ID.Hex(), Operation: domain.ConfigBundleOperationDelete},
+		{Resource: domain.ConfigBundleResourceNodeRuntimeConfig, Key: "node-a", Operation: domain.ConfigBundleOperationUnchanged},
+	}, changes)
+	assert.Empty(t, repo.createdRoles)
+}
+
+func TestApplyConfigBundleCreatesAndUpdatesRoles(t *testing.T) {
+	admin := &domain.Role{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, Name: "admin", Description: "old"}
+	repo := &fakeBundleRepo{roles: []*domain.Role{admin}}
+	svc := &Service{Repo: repo}
+	policies := []domain.RolePolicy{{PermissionKey: domain.ScheduleStrategyRead, Self: true}}
+
+	changes, err := svc.ApplyConfigBundle(context.Background(), &domain.Claims{UID: bson.NewObjectID().Hex()}, &domain.ApplyConfigBundleOptions{
+		Bundle: &domain.ConfigBundle{Roles: []*domain.Role{
+			{Name: "admin", Description: "new"},
+			{Name: "viewer", Policies: policies},
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, changes, 2)
+	assert.Empty(t, changes[0].Error)
+	assert.Empty(t, changes[1].Error)
+	require.Len(t, repo.updatedRoles, 1)
+	assert.Equal(t, "new", repo.updatedRoles[0].Description)
+	require.Len(t, repo.createdRoles, 1)
+	assert.Equal(t, "viewer", repo.createdRoles[0].Name)
+	assert.Equal(t, policies, repo.createdRoles[0].Policies)
+}
+
+func TestApplyConfigBundleRejectsDuplicateKeys(t *testing.T) {
+	svc := &Service{Repo: &fakeBundleRepo{}}
+
+	_, err := svc.ApplyConfigBundle(context.Background(), &domain.Claims{UID: bson.NewObjectID().Hex()}, &domain.ApplyConfigBundleOptions{
+		Bundle: &domain.ConfigBundle{Roles: []*domain.Role{{Name: "admin"}, {Name: "admin"}}},
+	})
+
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, 400, httpErr.StatusCode)
+}
+
+func TestApplyConfigBundleChecksEachResourcePermission(t *testing.T) {
+	operatorID := bson.NewObjectID()
+	foreign := &domain.ScheduleStrategy{
+		BaseEntity:   domain.BaseEntity{ID: bson.NewObjectID(), CreatorID: operatorID},
+		K8sNamespace: []string{"team-b"},
+	}
+	repo := &fakeBundleRepo{
+		strategies: []*domain.ScheduleStrategy{foreign},
+		roles: []*domain.Role{{Name: "team-a-operator", Policies: []domain.RolePolicy{
+			{PermissionKey: domain.ConfigBundleApply},
+			{PermissionKey: domain.ScheduleStrategyDelete, K8SNamespace: "team-a"},
+			{PermissionKey: domain.SchedulerConfigUpdate},
+		}}},
+		users: []*domain.User{{BaseEntity: domain.BaseEntity{ID: operatorID}, Roles: []string{"team-a-operator"}}},
+	}
+	svc := &Service{Repo: repo}
+	ctx := domain.WithRolePolicy(context.Background(), domain.RolePolicy{PermissionKey: domain.ConfigBundleApply})
+
+	changes, err := svc.ApplyConfigBundle(ctx, &domain.Claims{UID: operatorID.Hex()}, &domain.ApplyConfigBundleOptions{
+		Bundle: &domain.ConfigBundle{
+			Roles:              []*domain.Role{{Name: "escalated", Policies: []domain.RolePolicy{{PermissionKey: domain.RoleCrete}}}},
+			NodeRuntimeConfigs: []*domain.NodeRuntimeConfig{{NodeID: "node-a", Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v2"}}},
+		},
+		Prune: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	// config_bundle.apply does not grant role.create.
+	assert.Equal(t, domain.ConfigBundleResourceRole, changes[0].Resource)
+	assert.Contains(t, changes[0].Error, "permission denied")
+	assert.Empty(t, repo.createdRoles)
+	// The strategy delete is allowed, but not in a namespace outside the
+	// role's policy.
+	assert.Equal(t, domain.ConfigBundleResourceStrategy, changes[1].Resource)
+	assert.Contains(t, changes[1].Error, "forbidden")
+	// Runtime configs are only sent straight to the nodes when asked to.
+	assert.Equal(t, domain.ConfigBundleResourceNodeRuntimeConfig, changes[2].Resource)
+	assert.True(t, changes[2].BypassesRollout)
+	assert.Contains(t, changes[2].Error, "skipRollout")
+}
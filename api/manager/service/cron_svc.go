@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
 	"github.com/Gthulhu/api/pkg/logger"
@@ -18,12 +19,14 @@ import (
 //  1. Manager restart: re-sends all intents from DB to DM pods
 //  2. Decision Maker restart: detects Merkle root mismatch and re-sends intents
 //  3. Pod restart: detects stale intents (pods that no longer exist) and refreshes them
+//  4. Activation windows: removes the intents of strategies that became
+//     inactive and recreates those of strategies that became active
 func (svc *Service) ReconcileIntents(ctx context.Context) error {
 	if svc.K8SAdapter == nil {
 		return domain.ErrNoClient
 	}
 
-	// Step 1: Refresh stale intents (handle pod restarts)
+	// Step 1: Refresh stale intents (handle pod restarts and activation windows)
 	if err := svc.refreshStaleIntents(ctx); err != nil {
 		logger.Logger(ctx).Warn().Err(err).Msg("failed to refresh stale intents during reconciliation")
 	}
@@ -47,6 +50,16 @@ func (svc *Service) ReconcileIntents(ctx context.Context) error {
 	if err := svc.resyncNodeIntentsToDMs(ctx); err != nil {
 		logger.Logger(ctx).Warn().Err(err).Msg("failed to resync node intents during reconciliation")
 	}
+
+	// Step 6: Record which intents lost processes to higher-ranked ones
+	if err := svc.syncIntentOverrides(ctx); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msg("failed to sync intent overrides during reconciliation")
+	}
+
+	// Step 7: Record whether decision makers applied each intent, and to which PIDs
+	if err := svc.syncIntentStatuses(ctx); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msg("failed to sync intent statuses during reconciliation")
+	}
 	return nil
 }
 
@@ -67,9 +80,7 @@ func (svc *Service) resyncRuntimeConfigsToDMs(ctx context.Context) error {
 		return nil
 	}
 
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: domain.LabelSelector{Key: "app", Value: "decisionmaker"},
-	})
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{})
 	if err != nil {
 		return fmt.Errorf("query decision maker pods: %w", err)
 	}
@@ -99,7 +110,8 @@ func (svc *Service) resyncRuntimeConfigsToDMs(ctx context.Context) error {
 }
 
 // refreshStaleIntents checks all strategies for pods that no longer exist
-// and creates new intents for replacement pods.
+// and creates new intents for replacement pods. Strategies outside their
+// activation windows lose all their intents and get them back once active.
 func (svc *Service) refreshStaleIntents(ctx context.Context) error {
 	if svc.Repo == nil {
 		return fmt.Errorf("repository is nil")
@@ -110,96 +122,130 @@ func (svc *Service) refreshStaleIntents(ctx context.Context) error {
 		return fmt.Errorf("query strategies: %w", err)
 	}
 
+	now := time.Now()
 	for _, strategy := range strategyOpt.Result {
+		if _, err := svc.refreshStrategyIntents(ctx, strategy, now); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to refresh intents for strategy %s", strategy.ID.Hex())
+		}
+	}
+	return nil
+}
+
+// refreshStrategyIntents brings the intents of strategy in line with the pods
+// it currently selects: intents of pods that are gone or moved to another
+// node are deleted, and pods without an intent get one. Pods that are not
+// scheduled yet are skipped until they are. It returns the nodes whose
+// intents changed, also together with an error for the ones changed before
+// it.
+func (svc *Service) refreshStrategyIntents(ctx context.Context, strategy *domain.ScheduleStrategy, now time.Time) (map[string]struct{}, error) {
+	// Refreshes triggered by pod events run alongside the periodic one, and
+	// both insert intents for pods that have none.
+	svc.intentRefreshMu.Lock()
+	defer svc.intentRefreshMu.Unlock()
+
+	// An inactive strategy is treated as matching no pods, so all its
+	// intents are removed until its next activation window opens.
+	var currentPods []*domain.Pod
+	if active, _ := strategy.Schedule.Active(now); active {
 		queryOpt := &domain.QueryPodsOptions{
+			Clusters:       strategy.ClusterSelector,
 			K8SNamespace:   strategy.K8sNamespace,
 			LabelSelectors: strategy.LabelSelectors,
 		}
-		currentPods, err := svc.K8SAdapter.QueryPods(ctx, queryOpt)
+		pods, err := svc.K8SAdapter.QueryPods(ctx, queryOpt)
 		if err != nil {
-			logger.Logger(ctx).Warn().Err(err).Msgf("failed to query pods for strategy %s", strategy.ID.Hex())
-			continue
+			return nil, fmt.Errorf("query pods: %w", err)
 		}
+		currentPods = pods
+	}
 
-		intentOpt := &domain.QueryIntentOptions{
-			StrategyIDs: []bson.ObjectID{strategy.ID},
-		}
-		if err := svc.Repo.QueryIntents(ctx, intentOpt); err != nil {
-			logger.Logger(ctx).Warn().Err(err).Msgf("failed to query intents for strategy %s", strategy.ID.Hex())
+	intentOpt := &domain.QueryIntentOptions{
+		StrategyIDs: []bson.ObjectID{strategy.ID},
+	}
+	if err := svc.Repo.QueryIntents(ctx, intentOpt); err != nil {
+		return nil, fmt.Errorf("query intents: %w", err)
+	}
+
+	currentPodIDs := make(map[string]*domain.Pod, len(currentPods))
+	for _, pod := range currentPods {
+		currentPodIDs[pod.PodID] = pod
+	}
+	existingIntentPodIDs := make(map[string]*domain.ScheduleIntent, len(intentOpt.Result))
+	changedNodes := make(map[string]struct{})
+
+	// Delete stale intents (pod no longer exists in K8S, or was rescheduled)
+	staleIntentIDs := make([]bson.ObjectID, 0)
+	stalePodIDs := make([]string, 0)
+	staleNodeIDsMap := make(map[string]struct{})
+	for _, intent := range intentOpt.Result {
+		if pod, exists := currentPodIDs[intent.PodID]; exists && (pod.NodeID == "" || pod.NodeID == intent.NodeID) {
+			existingIntentPodIDs[intent.PodID] = intent
 			continue
 		}
-
-		currentPodIDs := make(map[string]*domain.Pod, len(currentPods))
-		for _, pod := range currentPods {
-			currentPodIDs[pod.PodID] = pod
+		staleIntentIDs = append(staleIntentIDs, intent.ID)
+		stalePodIDs = append(stalePodIDs, intent.PodID)
+		staleNodeIDsMap[intent.NodeID] = struct{}{}
+	}
+	if len(staleIntentIDs) > 0 {
+		if err := svc.Repo.DeleteIntents(ctx, staleIntentIDs); err != nil {
+			return changedNodes, fmt.Errorf("delete stale intents: %w", err)
 		}
-		existingIntentPodIDs := make(map[string]*domain.ScheduleIntent, len(intentOpt.Result))
-		for _, intent := range intentOpt.Result {
-			existingIntentPodIDs[intent.PodID] = intent
+		logger.Logger(ctx).Info().Msgf("deleted %d stale intents for strategy %s (stale pods: %v)", len(staleIntentIDs), strategy.ID.Hex(), stalePodIDs)
+		for nodeID := range staleNodeIDsMap {
+			changedNodes[nodeID] = struct{}{}
 		}
 
-		// Delete stale intents (pod no longer exists in K8S)
-		staleIntentIDs := make([]bson.ObjectID, 0)
-		stalePodIDs := make([]string, 0)
-		staleNodeIDsMap := make(map[string]struct{})
-		for _, intent := range intentOpt.Result {
-			if _, exists := currentPodIDs[intent.PodID]; !exists {
-				staleIntentIDs = append(staleIntentIDs, intent.ID)
-				stalePodIDs = append(stalePodIDs, intent.PodID)
-				staleNodeIDsMap[intent.NodeID] = struct{}{}
-			}
-		}
-		if len(staleIntentIDs) > 0 {
-			if err := svc.Repo.DeleteIntents(ctx, staleIntentIDs); err != nil {
-				logger.Logger(ctx).Warn().Err(err).Msgf("failed to delete stale intents for strategy %s", strategy.ID.Hex())
-			} else {
-				logger.Logger(ctx).Info().Msgf("deleted %d stale intents for strategy %s (stale pods: %v)", len(staleIntentIDs), strategy.ID.Hex(), stalePodIDs)
-			}
+		// Notify decision makers to remove stale pod intents from their in-memory cache
+		svc.notifyDMsDeleteIntents(ctx, staleNodeIDsMap, stalePodIDs)
+	}
 
-			// Notify decision makers to remove stale pod intents from their in-memory cache
-			svc.notifyDMsDeleteIntents(ctx, staleNodeIDsMap, stalePodIDs)
+	// Create new intents for pods that don't have intents yet
+	newIntents := make([]*domain.ScheduleIntent, 0)
+	for _, pod := range currentPods {
+		if pod.NodeID == "" {
+			continue
 		}
-
-		// Create new intents for pods that don't have intents yet
-		newIntents := make([]*domain.ScheduleIntent, 0)
-		for _, pod := range currentPods {
-			if _, exists := existingIntentPodIDs[pod.PodID]; !exists {
-				intent := domain.NewScheduleIntent(strategy, pod)
-				newIntents = append(newIntents, &intent)
-			}
+		if _, exists := existingIntentPodIDs[pod.PodID]; !exists {
+			intent := domain.NewScheduleIntent(strategy, pod)
+			newIntents = append(newIntents, &intent)
 		}
-		if len(newIntents) > 0 {
-			if err := svc.Repo.InsertIntents(ctx, newIntents); err != nil {
-				logger.Logger(ctx).Warn().Err(err).Msgf("failed to insert new intents for strategy %s", strategy.ID.Hex())
-			} else {
-				logger.Logger(ctx).Info().Msgf("created %d new intents for strategy %s", len(newIntents), strategy.ID.Hex())
-			}
+	}
+	if len(newIntents) > 0 {
+		if err := svc.Repo.InsertIntents(ctx, newIntents); err != nil {
+			return changedNodes, fmt.Errorf("insert new intents: %w", err)
+		}
+		logger.Logger(ctx).Info().Msgf("created %d new intents for strategy %s", len(newIntents), strategy.ID.Hex())
+		for _, intent := range newIntents {
+			changedNodes[intent.NodeID] = struct{}{}
 		}
 	}
-	return nil
+	return changedNodes, nil
 }
 
 // resyncIntentsToDMs compares Merkle roots between Manager DB and each DM pod.
 // When a mismatch is detected (e.g. DM restarted and lost in-memory intents),
-// all intents for that node are re-sent.
+// only the intents that differ are sent when the DM adapter supports walking
+// the tree, otherwise all intents for that node are re-sent.
 func (svc *Service) resyncIntentsToDMs(ctx context.Context) error {
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
-	}
-	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	return svc.resyncIntentsToNodes(ctx, nil)
+}
+
+// resyncIntentsToNodes is resyncIntentsToDMs for the decision makers of
+// nodeIDs only, or of every node when nodeIDs is empty.
+func (svc *Service) resyncIntentsToNodes(ctx context.Context, nodeIDs []string) error {
+	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{NodeIDs: nodeIDs}
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return err
 	}
 	if len(dms) == 0 {
-		logger.Logger(ctx).Warn().Msg("no decision maker pods found for intent reconciliation")
+		if len(nodeIDs) == 0 {
+			logger.Logger(ctx).Warn().Msg("no decision maker pods found for intent reconciliation")
+		}
 		return nil
 	}
 
-	queryOpt := &domain.QueryIntentOptions{}
+	queryOpt := &domain.QueryIntentOptions{NodeIDs: nodeIDs}
 	if err := svc.Repo.QueryIntents(ctx, queryOpt); err != nil {
 		return err
 	}
@@ -248,6 +294,23 @@ func (svc *Service) resyncIntentsToDMs(ctx context.Context) error {
 			}
 			continue
 		}
+		if dmAdapter, ok := svc.DMAdapter.(intentMerkleDMAdapter); ok {
+			sent, err := svc.patchIntentsToDM(ctx, dmAdapter, dm, rootHash, nodeIntents)
+			if err == nil {
+				sentIDs := make([]bson.ObjectID, 0, len(sent))
+				for _, intent := range sent {
+					sentIDs = append(sentIDs, intent.ID)
+				}
+				if len(sentIDs) > 0 {
+					if err := svc.Repo.BatchUpdateIntentsState(ctx, sentIDs, domain.IntentStateSent); err != nil {
+						logger.Logger(ctx).Warn().Err(err).Msgf("failed to update intent states for dm %s", dm)
+					}
+				}
+				logger.Logger(ctx).Info().Msgf("patched %d of %d intents on dm %s", len(sent), len(nodeIntents), dm)
+				continue
+			}
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to patch intents on dm %s, re-sending all intents", dm)
+		}
 		err = svc.DMAdapter.SendSchedulingIntent(ctx, dm, nodeIntents)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msgf("failed to re-send intents to dm %s", dm)
@@ -277,15 +340,10 @@ func (svc *Service) notifyDMsDeleteIntents(ctx context.Context, nodeIDsMap map[s
 		nodeIDs = append(nodeIDs, nodeID)
 	}
 
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
-	}
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dmPods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dmPods, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods for stale intent deletion notification")
 		return
@@ -408,14 +466,8 @@ func (svc *Service) resyncNodeIntentsToDMs(ctx context.Context) error {
 		return nil
 	}
 
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
-	}
-	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{}
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return err
 	}
@@ -492,24 +544,31 @@ func sortNodeIntentsByKey(intents []*domain.NodeSchedulingIntent) []*domain.Node
 }
 
 func nodeIntentSortKey(intent *domain.NodeSchedulingIntent) string {
-	return strings.Join([]string{
+	key := []string{
 		intent.NodeID,
 		intent.PolicyID.Hex(),
 		intent.CommandRegex,
 		strconv.Itoa(intent.Priority),
 		strconv.FormatInt(intent.ExecutionTime, 10),
-	}, "|")
+	}
+	if intent.Precedence != 0 {
+		key = append(key, strconv.Itoa(intent.Precedence))
+	}
+	return strings.Join(key, "|")
 }
 
 func hashNodeSchedulingIntent(intent *domain.NodeSchedulingIntent) string {
-	serialized := strings.Join([]string{
+	fields := []string{
 		"nodeID=" + intent.NodeID,
 		"policyID=" + intent.PolicyID.Hex(),
 		"commandRegex=" + intent.CommandRegex,
 		"priority=" + strconv.Itoa(intent.Priority),
 		"executionTime=" + strconv.FormatInt(intent.ExecutionTime, 10),
-	}, "|")
-	return util.HashStringSHA256Hex(serialized)
+	}
+	if intent.Precedence != 0 {
+		fields = append(fields, "precedence="+strconv.Itoa(intent.Precedence))
+	}
+	return util.HashStringSHA256Hex(strings.Join(fields, "|"))
 }
 
 func buildExpectedNodeIntentRootsByNode(intents []*domain.NodeSchedulingIntent) map[string]string {
@@ -563,7 +622,7 @@ func scheduleIntentSortKey(intent *domain.ScheduleIntent) string {
 		labels = append(labels, key+"="+value)
 	}
 	sort.Strings(labels)
-	return strings.Join([]string{
+	key := []string{
 		intent.PodName,
 		intent.PodID,
 		intent.NodeID,
@@ -572,7 +631,14 @@ func scheduleIntentSortKey(intent *domain.ScheduleIntent) string {
 		strconv.Itoa(intent.Priority),
 		strconv.FormatInt(intent.ExecutionTime, 10),
 		strings.Join(labels, ","),
-	}, "|")
+	}
+	if intent.Precedence != 0 {
+		key = append(key, strconv.Itoa(intent.Precedence))
+	}
+	if intent.Specificity != 0 {
+		key = append(key, strconv.Itoa(intent.Specificity))
+	}
+	return strings.Join(key, "|")
 }
 
 func hashScheduleIntent(intent *domain.ScheduleIntent) string {
@@ -581,7 +647,7 @@ func hashScheduleIntent(intent *domain.ScheduleIntent) string {
 		labels = append(labels, key+"="+value)
 	}
 	sort.Strings(labels)
-	serialized := strings.Join([]string{
+	fields := []string{
 		"podName=" + intent.PodName,
 		"podID=" + intent.PodID,
 		"nodeID=" + intent.NodeID,
@@ -590,8 +656,17 @@ func hashScheduleIntent(intent *domain.ScheduleIntent) string {
 		"priority=" + strconv.Itoa(intent.Priority),
 		"executionTime=" + strconv.FormatInt(intent.ExecutionTime, 10),
 		"podLabels=" + strings.Join(labels, ","),
-	}, "|")
-	return util.HashStringSHA256Hex(serialized)
+	}
+	// Only non-zero ranks are hashed so unranked intents keep the roots the
+	// decision makers already report; decisionmaker/service hashes the same
+	// way.
+	if intent.Precedence != 0 {
+		fields = append(fields, "precedence="+strconv.Itoa(intent.Precedence))
+	}
+	if intent.Specificity != 0 {
+		fields = append(fields, "specificity="+strconv.Itoa(intent.Specificity))
+	}
+	return util.HashStringSHA256Hex(strings.Join(fields, "|"))
 }
 
 func buildExpectedIntentRootsByNode(intents []*domain.ScheduleIntent) map[string]string {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
 	"github.com/Gthulhu/api/pkg/util"
@@ -34,8 +35,11 @@ func TestCheckDMIntentsQueryDecisionMakerPodsError(t *testing.T) {
 		}).
 		Return(nil, expectedErr).
 		Once()
+	mockRepo := newDMRegistryMockRepo(t)
+	mockRepo.EXPECT().QueryStrategies(mock.Anything, mock.Anything).Return(nil).Maybe()
 
 	svc := &Service{
+		Repo:       mockRepo,
 		K8SAdapter: mockK8S,
 	}
 	err := svc.CheckDMIntents(ctx)
@@ -50,11 +54,16 @@ func TestCheckDMIntentsNoDecisionMakerPods(t *testing.T) {
 		QueryDecisionMakerPods(mock.Anything, mock.Anything).
 		Return([]*domain.DecisionMakerPod{}, nil).
 		Once()
+	// QueryIntents is intentionally not expected. If code regresses and tries
+	// to query intents, this test will fail via the unexpected call.
+	mockRepo := newDMRegistryMockRepo(t)
+	mockRepo.EXPECT().QueryStrategies(mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockRepo.EXPECT().QueryNodePolicies(mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockRepo.EXPECT().QueryNodeIntents(mock.Anything, mock.Anything).Return(nil).Maybe()
 
 	svc := &Service{
+		Repo:       mockRepo,
 		K8SAdapter: mockK8S,
-		// Repo intentionally left nil. If code regresses and tries to query intents,
-		// this test will fail via nil dereference.
 	}
 
 	err := svc.CheckDMIntents(ctx)
@@ -64,7 +73,7 @@ func TestCheckDMIntentsNoDecisionMakerPods(t *testing.T) {
 func TestCheckDMIntentsDMAdapterNilForOnlineNode(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	dm := &domain.DecisionMakerPod{
 		NodeID: "node-online",
 		Host:   "127.0.0.1",
@@ -104,7 +113,7 @@ func TestCheckDMIntentsDMAdapterNilForOnlineNode(t *testing.T) {
 func TestCheckDMIntentsHappyPathOnlineOnly(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	onlineDM := &domain.DecisionMakerPod{
@@ -196,7 +205,7 @@ func TestCheckDMIntentsHappyPathOnlineOnly(t *testing.T) {
 func TestCheckDMIntentsComparesNodeScopedMerkleRoots(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	dmNodeA := &domain.DecisionMakerPod{
@@ -307,7 +316,7 @@ func TestCheckDMIntentsComparesNodeScopedMerkleRoots(t *testing.T) {
 func TestReconcileIntentsResendOnMerkleMismatch(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	dm := &domain.DecisionMakerPod{
@@ -388,7 +397,7 @@ func TestReconcileIntentsResendOnMerkleMismatch(t *testing.T) {
 func TestReconcileIntentsNoResendOnMatchingMerkle(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	dm := &domain.DecisionMakerPod{
@@ -457,7 +466,7 @@ func TestReconcileIntentsNoResendOnMatchingMerkle(t *testing.T) {
 func TestReconcileIntentsRefreshStaleIntents(t *testing.T) {
 	ctx := context.Background()
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	strategyID := bson.NewObjectID()
@@ -636,3 +645,40 @@ func TestSortScheduleIntentsByKeyAndHashDeterministic(t *testing.T) {
 		},
 	}))
 }
+
+func TestRefreshStaleIntentsRemovesIntentsOfInactiveStrategy(t *testing.T) {
+	ctx := context.Background()
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockRepo := newDMRegistryMockRepo(t)
+	mockDM := domain.NewMockDecisionMakerAdapter(t)
+
+	strategyID := bson.NewObjectID()
+	strategy := &domain.ScheduleStrategy{
+		BaseEntity:   domain.BaseEntity{ID: strategyID},
+		K8sNamespace: []string{"default"},
+		Schedule:     &domain.StrategySchedule{ExpiresAt: time.Now().Add(-time.Minute).UnixMilli()},
+	}
+	intentID := bson.NewObjectID()
+	dm := &domain.DecisionMakerPod{NodeID: "node-a", Host: "10.0.0.1", State: domain.NodeStateOnline}
+
+	mockRepo.EXPECT().
+		QueryStrategies(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryStrategyOptions) {
+			opt.Result = []*domain.ScheduleStrategy{strategy}
+		}).
+		Return(nil).Once()
+	mockRepo.EXPECT().
+		QueryIntents(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryIntentOptions) {
+			opt.Result = []*domain.ScheduleIntent{{BaseEntity: domain.BaseEntity{ID: intentID}, StrategyID: strategyID, PodID: "pod-id", NodeID: "node-a"}}
+		}).
+		Return(nil).Once()
+	mockRepo.EXPECT().DeleteIntents(mock.Anything, []bson.ObjectID{intentID}).Return(nil).Once()
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{dm}, nil).Once()
+	mockDM.EXPECT().
+		DeleteSchedulingIntents(mock.Anything, dm, &domain.DeleteIntentsRequest{PodIDs: []string{"pod-id"}}).
+		Return(nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, Repo: mockRepo, DMAdapter: mockDM}
+	require.NoError(t, svc.refreshStaleIntents(ctx))
+}
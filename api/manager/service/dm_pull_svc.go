@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// dmPullTokenIssuer and dmPullTokenAudience are set by decision makers on
+	// the tokens they pull with; decisionmaker/service signs them the same
+	// way. The audience keeps the tokens decision makers issue to the manager
+	// from being replayed against it.
+	dmPullTokenIssuer   = "decision-maker-service"
+	dmPullTokenAudience = "gthulhu-manager"
+)
+
+// VerifyDecisionMakerPullToken checks a token a decision maker in pull mode
+// signed with its RSA private key against key.dm_public_key_pem, and returns
+// the node it pulls for.
+func (svc *Service) VerifyDecisionMakerPullToken(tokenString string) (string, error) {
+	if svc.dmPublicKey == nil {
+		return "", errs.NewHTTPStatusError(http.StatusNotImplemented, "decision maker pull mode is not enabled", nil)
+	}
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return svc.dmPublicKey, nil
+	}, jwt.WithIssuer(dmPullTokenIssuer), jwt.WithAudience(dmPullTokenAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", errs.NewHTTPStatusError(http.StatusUnauthorized, "invalid or expired pull token", err)
+	}
+	nodeID := strings.TrimSpace(claims.Subject)
+	if nodeID == "" {
+		return "", errs.NewHTTPStatusError(http.StatusUnauthorized, "invalid pull token claims", errors.New("pull token has no node"))
+	}
+	return nodeID, nil
+}
+
+// PullNodeIntents returns the scheduling intents of nodeID unless the
+// decision maker already holds their Merkle root, etag. Holding it
+// acknowledges the intents, which are marked Sent. The node stops being
+// pushed to while it keeps pulling.
+func (svc *Service) PullNodeIntents(ctx context.Context, nodeID, etag string) (*domain.PullIntentsResult, error) {
+	svc.markDecisionMakerPull(ctx, nodeID)
+	queryOpt := &domain.QueryIntentOptions{NodeIDs: []string{nodeID}}
+	if err := svc.Repo.QueryIntents(ctx, queryOpt); err != nil {
+		return nil, fmt.Errorf("query intents: %w", err)
+	}
+	result := &domain.PullIntentsResult{ETag: buildScheduleIntentMerkleRoot(queryOpt.Result)}
+	if etag != result.ETag {
+		result.Intents = sortScheduleIntentsByKey(queryOpt.Result)
+		return result, nil
+	}
+	result.NotModified = true
+
+	var delivered []bson.ObjectID
+	for _, intent := range queryOpt.Result {
+		if intent.State == domain.IntentStateInitialized {
+			delivered = append(delivered, intent.ID)
+		}
+	}
+	if len(delivered) > 0 {
+		if err := svc.Repo.BatchUpdateIntentsState(ctx, delivered, domain.IntentStateSent); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update intent states for node %s", nodeID)
+		}
+	}
+	return result, nil
+}
+
+// PullNodeSchedulingIntents is PullNodeIntents for node scheduling intents.
+func (svc *Service) PullNodeSchedulingIntents(ctx context.Context, nodeID, etag string) (*domain.PullNodeIntentsResult, error) {
+	svc.markDecisionMakerPull(ctx, nodeID)
+	queryOpt := &domain.QueryNodeIntentOptions{NodeIDs: []string{nodeID}}
+	if err := svc.Repo.QueryNodeIntents(ctx, queryOpt); err != nil {
+		return nil, fmt.Errorf("query node intents: %w", err)
+	}
+	result := &domain.PullNodeIntentsResult{ETag: buildNodeSchedulingIntentMerkleRoot(queryOpt.Result)}
+	if etag != result.ETag {
+		result.Intents = sortNodeIntentsByKey(queryOpt.Result)
+		return result, nil
+	}
+	result.NotModified = true
+
+	var delivered []bson.ObjectID
+	for _, intent := range queryOpt.Result {
+		if intent.State == domain.IntentStateInitialized {
+			delivered = append(delivered, intent.ID)
+		}
+	}
+	if len(delivered) > 0 {
+		if err := svc.Repo.BatchUpdateNodeIntentsState(ctx, delivered, domain.IntentStateSent); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update node intent states for node %s", nodeID)
+		}
+	}
+	return result, nil
+}
+
+// PullNodeRuntimeConfig returns the runtime config last applied to nodeID
+// unless the decision maker already runs its version, etag. Running it is
+// recorded as a successful apply.
+func (svc *Service) PullNodeRuntimeConfig(ctx context.Context, nodeID, etag string) (*domain.PullRuntimeConfigResult, error) {
+	svc.markDecisionMakerPull(ctx, nodeID)
+	repo, ok := svc.Repo.(runtimeConfigRepository)
+	if !ok {
+		return &domain.PullRuntimeConfigResult{}, nil
+	}
+	queryOpt := &domain.QueryNodeRuntimeConfigOptions{NodeIDs: []string{nodeID}}
+	if err := repo.QueryNodeRuntimeConfigs(ctx, queryOpt); err != nil {
+		return nil, fmt.Errorf("query node runtime configs: %w", err)
+	}
+	if len(queryOpt.Result) == 0 {
+		return &domain.PullRuntimeConfigResult{}, nil
+	}
+	desired := queryOpt.Result[0]
+	result := &domain.PullRuntimeConfigResult{ETag: desired.ConfigVersion}
+	if etag != result.ETag {
+		config := desired.Config
+		result.Config = &config
+		return result, nil
+	}
+	result.NotModified = true
+
+	if last := desired.LastApplyResult; !last.Success || last.ConfigVersion != desired.ConfigVersion {
+		config := desired.Config
+		desired.LastApplyResult = domain.RuntimeConfigApplyResult{
+			NodeID:        nodeID,
+			Success:       true,
+			ConfigVersion: desired.ConfigVersion,
+			AppliedAt:     time.Now().UTC().Format(time.RFC3339),
+			Config:        &config,
+			DesiredConfig: &config,
+		}
+		if err := repo.UpsertNodeRuntimeConfig(ctx, desired); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update runtime config apply result for node %s", nodeID)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakePullRepo struct {
+	domain.Repository
+	intents []*domain.ScheduleIntent
+	configs []*domain.NodeRuntimeConfig
+	sent    []bson.ObjectID
+}
+
+func (r *fakePullRepo) QueryIntents(_ context.Context, opt *domain.QueryIntentOptions) error {
+	for _, intent := range r.intents {
+		if len(opt.NodeIDs) == 0 || intent.NodeID == opt.NodeIDs[0] {
+			opt.Result = append(opt.Result, intent)
+		}
+	}
+	return nil
+}
+
+func (r *fakePullRepo) BatchUpdateIntentsState(_ context.Context, intentIDs []bson.ObjectID, _ domain.IntentState) error {
+	r.sent = append(r.sent, intentIDs...)
+	return nil
+}
+
+func (r *fakePullRepo) QueryNodeRuntimeConfigs(_ context.Context, opt *domain.QueryNodeRuntimeConfigOptions) error {
+	opt.Result = append(opt.Result, r.configs...)
+	return nil
+}
+
+func (r *fakePullRepo) UpsertNodeRuntimeConfig(_ context.Context, cfg *domain.NodeRuntimeConfig) error {
+	r.configs = []*domain.NodeRuntimeConfig{cfg}
+	return nil
+}
+
+func signPullToken(t *testing.T, key *rsa.PrivateKey, nodeID, audience string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    dmPullTokenIssuer,
+		Subject:   nodeID,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifyDecisionMakerPullToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	svc := &Service{}
+	_, err = svc.VerifyDecisionMakerPullToken(signPullToken(t, key, "node-a", dmPullTokenAudience))
+	assertHTTPStatus(t, http.StatusNotImplemented, err)
+
+	svc.dmPublicKey = &key.PublicKey
+	nodeID, err := svc.VerifyDecisionMakerPullToken(signPullToken(t, key, "node-a", dmPullTokenAudience))
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", nodeID)
+
+	_, err = svc.VerifyDecisionMakerPullToken(signPullToken(t, key, "node-a", "decision-maker"))
+	assertHTTPStatus(t, http.StatusUnauthorized, err)
+	_, err = svc.VerifyDecisionMakerPullToken(signPullToken(t, key, "", dmPullTokenAudience))
+	assertHTTPStatus(t, http.StatusUnauthorized, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = svc.VerifyDecisionMakerPullToken(signPullToken(t, other, "node-a", dmPullTokenAudience))
+	assertHTTPStatus(t, http.StatusUnauthorized, err)
+}
+
+func TestPullNodeIntentsAcknowledgesByETag(t *testing.T) {
+	ctx := context.Background()
+	initialized := &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, NodeID: "node-a", PodID: "pod-a", State: domain.IntentStateInitialized}
+	applied := &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, NodeID: "node-a", PodID: "pod-b", State: domain.IntentStateApplied}
+	other := &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, NodeID: "node-b", PodID: "pod-c"}
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	repo := &fakePullRepo{Repository: mockRepo, intents: []*domain.ScheduleIntent{initialized, applied, other}}
+	svc := &Service{Repo: repo}
+
+	result, err := svc.PullNodeIntents(ctx, "node-a", "")
+	require.NoError(t, err)
+	assert.False(t, result.NotModified)
+	assert.Len(t, result.Intents, 2)
+	assert.Equal(t, buildScheduleIntentMerkleRoot([]*domain.ScheduleIntent{applied, initialized}), result.ETag)
+	assert.Empty(t, repo.sent)
+
+	result, err = svc.PullNodeIntents(ctx, "node-a", result.ETag)
+	require.NoError(t, err)
+	assert.True(t, result.NotModified)
+	assert.Empty(t, result.Intents)
+	assert.Equal(t, []bson.ObjectID{initialized.ID}, repo.sent)
+
+	// A node that pulls is no longer pushed to.
+	for i, nodeID := range []string{"node-a", "node-b"} {
+		_, err = svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{NodeID: nodeID, Host: fmt.Sprintf("10.0.0.%d", i+1), Port: 8080})
+		require.NoError(t, err)
+	}
+	dms, err := svc.queryDecisionMakers(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, dms, 1)
+	assert.Equal(t, "node-b", dms[0].NodeID)
+}
+
+func TestPullNodeRuntimeConfigRecordsApply(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	repo := &fakePullRepo{Repository: mockRepo}
+	svc := &Service{Repo: repo}
+
+	result, err := svc.PullNodeRuntimeConfig(ctx, "node-a", "")
+	require.NoError(t, err)
+	assert.Nil(t, result.Config)
+
+	repo.configs = []*domain.NodeRuntimeConfig{{
+		NodeID:        "node-a",
+		ConfigVersion: "v2",
+		Config:        domain.RuntimeSchedulerConfig{ConfigVersion: "v2", Mode: "gthulhu"},
+	}}
+	result, err = svc.PullNodeRuntimeConfig(ctx, "node-a", "v1")
+	require.NoError(t, err)
+	require.NotNil(t, result.Config)
+	assert.Equal(t, "v2", result.ETag)
+	assert.False(t, repo.configs[0].LastApplyResult.Success)
+
+	result, err = svc.PullNodeRuntimeConfig(ctx, "node-a", "v2")
+	require.NoError(t, err)
+	assert.True(t, result.NotModified)
+	assert.True(t, repo.configs[0].LastApplyResult.Success)
+	assert.Equal(t, "v2", repo.configs[0].LastApplyResult.ConfigVersion)
+}
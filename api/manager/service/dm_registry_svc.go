@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+// dmRegistrationRetention is how many heartbeat TTLs an offline
+// registration is kept for, so /api/v1/nodes still reports it as offline.
+const dmRegistrationRetention = 10
+
+// VerifyDecisionMakerRegistrationToken checks the token a decision maker
+// registers with against discovery.registration_token.
+func (svc *Service) VerifyDecisionMakerRegistrationToken(token string) error {
+	expected := svc.discoveryCfg.RegistrationToken.Value()
+	if expected == "" {
+		return errs.NewHTTPStatusError(http.StatusNotImplemented, "decision maker registration is not enabled", nil)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return errs.NewHTTPStatusError(http.StatusUnauthorized, "invalid registration token", nil)
+	}
+	return nil
+}
+
+// RegisterDecisionMaker records a decision maker registration or heartbeat
+// and returns the heartbeat TTL, after which the registration is considered
+// offline.
+func (svc *Service) RegisterDecisionMaker(ctx context.Context, reg *domain.DecisionMakerRegistration) (time.Duration, error) {
+	if reg == nil {
+		return 0, errs.NewHTTPStatusError(http.StatusBadRequest, "registration is required", nil)
+	}
+	reg.NodeID = strings.TrimSpace(reg.NodeID)
+	reg.Host = strings.TrimSpace(reg.Host)
+	if reg.NodeID == "" || reg.Host == "" {
+		return 0, errs.NewHTTPStatusError(http.StatusBadRequest, "nodeID and host are required", nil)
+	}
+	if reg.Port <= 0 || reg.Port > 65535 {
+		return 0, errs.NewHTTPStatusError(http.StatusBadRequest, "port must be between 1 and 65535", fmt.Errorf("port %d", reg.Port))
+	}
+	changed, err := svc.upsertDecisionMakerRegistration(ctx, reg, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if changed {
+		logger.Logger(ctx).Info().Msgf("decision maker on node %s registered at %s:%d (version %q, sched_ext %t, kernel %q)",
+			reg.NodeID, reg.Host, reg.Port, reg.Version, reg.Capabilities.SchedExtSupported, reg.Capabilities.KernelVersion)
+	}
+	return svc.discoveryCfg.HeartbeatTTL(), nil
+}
+
+// DeregisterDecisionMaker removes the registration of a node, e.g. when its
+// decision maker shuts down. The node is found by label again afterwards.
+func (svc *Service) DeregisterDecisionMaker(ctx context.Context, nodeID string) error {
+	removed, err := svc.Repo.DeleteDecisionMakerRegistration(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errs.NewHTTPStatusError(http.StatusNotFound, "decision maker is not registered", fmt.Errorf("node %s", nodeID))
+	}
+	logger.Logger(ctx).Info().Msgf("decision maker on node %s deregistered", nodeID)
+	return nil
+}
+
+// upsertDecisionMakerRegistration records a registration or heartbeat and
+// reports whether the node is new or moved to another address.
+func (svc *Service) upsertDecisionMakerRegistration(ctx context.Context, reg *domain.DecisionMakerRegistration, now time.Time) (bool, error) {
+	entry := *reg
+	entry.RegisteredAt = now
+	entry.LastHeartbeat = now
+	entry.ExpireAt = now.Add(dmRegistrationRetention * svc.discoveryCfg.HeartbeatTTL())
+	return svc.Repo.UpsertDecisionMakerRegistration(ctx, &entry)
+}
+
+// decisionMakerRegistrations returns every registration sorted by node, with
+// Online set from the heartbeat TTL. Registrations offline for longer than
+// dmRegistrationRetention TTLs are left out.
+func (svc *Service) decisionMakerRegistrations(ctx context.Context) ([]*domain.DecisionMakerRegistration, error) {
+	now, ttl := time.Now(), svc.discoveryCfg.HeartbeatTTL()
+	opt := &domain.QueryDecisionMakerRegistrationOptions{HeartbeatAfter: now.Add(-dmRegistrationRetention * ttl)}
+	if err := svc.Repo.QueryDecisionMakerRegistrations(ctx, opt); err != nil {
+		return nil, err
+	}
+	for _, reg := range opt.Result {
+		reg.Online = now.Sub(reg.LastHeartbeat) <= ttl
+	}
+	return opt.Result, nil
+}
+
+// markDecisionMakerPull records that the decision maker of nodeID pulled.
+// Failing to record it only means the node may still be pushed to.
+func (svc *Service) markDecisionMakerPull(ctx context.Context, nodeID string) {
+	now := time.Now()
+	pull := &domain.DecisionMakerPull{
+		NodeID:   nodeID,
+		PulledAt: now,
+		ExpireAt: now.Add(dmRegistrationRetention * svc.discoveryCfg.HeartbeatTTL()),
+	}
+	if err := svc.Repo.MarkDecisionMakerPull(ctx, pull); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("failed to record the pull of node %s", nodeID)
+	}
+}
+
+// pullingNodes returns the nodes whose decision maker pulled within the
+// heartbeat TTL.
+func (svc *Service) pullingNodes(ctx context.Context) (map[string]struct{}, error) {
+	now, ttl := time.Now(), svc.discoveryCfg.HeartbeatTTL()
+	opt := &domain.QueryDecisionMakerPullOptions{PulledAfter: now.Add(-ttl)}
+	if err := svc.Repo.QueryDecisionMakerPulls(ctx, opt); err != nil {
+		return nil, err
+	}
+	result := make(map[string]struct{}, len(opt.Result))
+	for _, pull := range opt.Result {
+		result[pull.NodeID] = struct{}{}
+	}
+	return result, nil
+}
+
+// queryDecisionMakers finds the decision makers of opt.NodeIDs, or of every
+// node when it is empty. A node's online registration wins over the pod
+// found by label, and nodes without one are found by
+// discovery.decision_maker_label unless opt sets its own label. Nodes whose
+// decision maker is in pull mode are left out, since the manager cannot
+// reach them.
+func (svc *Service) queryDecisionMakers(ctx context.Context, opt *domain.QueryDecisionMakerPodsOptions) ([]*domain.DecisionMakerPod, error) {
+	query := domain.QueryDecisionMakerPodsOptions{}
+	if opt != nil {
+		query = *opt
+	}
+	if query.DecisionMakerLabel.Key == "" {
+		key, value := svc.discoveryCfg.Label()
+		query.DecisionMakerLabel = domain.LabelSelector{Key: key, Value: value}
+	}
+	nodeFilter := make(map[string]struct{}, len(query.NodeIDs))
+	for _, nodeID := range query.NodeIDs {
+		nodeFilter[nodeID] = struct{}{}
+	}
+	pulling, err := svc.pullingNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registrations, err := svc.decisionMakerRegistrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]*domain.DecisionMakerPod)
+	var registeredNodes []string
+	for _, reg := range registrations {
+		if !reg.Online {
+			continue
+		}
+		if _, ok := nodeFilter[reg.NodeID]; len(nodeFilter) > 0 && !ok {
+			continue
+		}
+		if _, ok := pulling[reg.NodeID]; ok {
+			continue
+		}
+		registered[reg.NodeID] = reg.DecisionMakerPod()
+		registeredNodes = append(registeredNodes, reg.NodeID)
+	}
+
+	var labelled []*domain.DecisionMakerPod
+	if svc.K8SAdapter != nil {
+		pods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, &query)
+		if err != nil {
+			if len(registered) == 0 {
+				return nil, err
+			}
+			logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods by label, using registered decision makers only")
+		}
+		labelled = pods
+	} else if len(registered) == 0 {
+		return nil, domain.ErrNoClient
+	}
+
+	result := make([]*domain.DecisionMakerPod, 0, len(labelled)+len(registered))
+	for _, dm := range labelled {
+		if _, ok := registered[dm.NodeID]; ok {
+			continue
+		}
+		if _, ok := pulling[dm.NodeID]; ok {
+			continue
+		}
+		result = append(result, dm)
+	}
+	for _, nodeID := range registeredNodes {
+		result = append(result, registered[nodeID])
+	}
+	return result, nil
+}
+
+// listRegisteredNodes returns the registered nodes as the node list when
+// there is no Kubernetes client.
+func listRegisteredNodes(registrations []*domain.DecisionMakerRegistration) ([]*domain.Node, error) {
+	if len(registrations) == 0 {
+		return nil, domain.ErrNoClient
+	}
+	nodes := make([]*domain.Node, 0, len(registrations))
+	for _, reg := range registrations {
+		nodes = append(nodes, &domain.Node{Name: reg.NodeID, Status: "Unknown", DecisionMaker: reg})
+	}
+	return nodes, nil
+}
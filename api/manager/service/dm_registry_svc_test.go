@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryDecisionMakersPrefersRegistrations(t *testing.T) {
+	ctx := context.Background()
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockK8S.EXPECT().
+		QueryDecisionMakerPods(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryDecisionMakerPodsOptions) {
+			assert.Equal(t, "gthulhu.io/component", opt.DecisionMakerLabel.Key)
+			assert.Equal(t, "dm", opt.DecisionMakerLabel.Value)
+		}).
+		Return([]*domain.DecisionMakerPod{
+			{NodeID: "node-a", Host: "10.0.0.1", Port: 8080, State: domain.NodeStateOnline},
+			{NodeID: "node-b", Host: "10.0.0.2", Port: 8080, State: domain.NodeStateOnline},
+		}, nil).
+		Once()
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+
+	svc := &Service{
+		K8SAdapter:   mockK8S,
+		Repo:         mockRepo,
+		discoveryCfg: config.DiscoveryConfig{DecisionMakerLabel: "gthulhu.io/component=dm"},
+	}
+	_, err := svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{
+		NodeID: "node-b", Host: "10.1.0.2", Port: 9090, Version: "1.2.0",
+	})
+	require.NoError(t, err)
+	_, err = svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{
+		NodeID: "node-c", Host: "10.1.0.3", Port: 9090,
+	})
+	require.NoError(t, err)
+
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{NodeIDs: []string{"node-a", "node-b"}})
+	require.NoError(t, err)
+	require.Len(t, dms, 2)
+	assert.Equal(t, "10.0.0.1", dms[0].Host)
+	assert.Equal(t, "node-b", dms[1].NodeID)
+	assert.Equal(t, "10.1.0.2", dms[1].Host)
+	assert.Equal(t, 9090, dms[1].Port)
+}
+
+func TestQueryDecisionMakersWithoutK8SAdapter(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	svc := &Service{Repo: mockRepo}
+
+	_, err := svc.queryDecisionMakers(ctx, nil)
+	assert.ErrorIs(t, err, domain.ErrNoClient)
+
+	_, err = svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{NodeID: "node-a", Host: "10.1.0.1", Port: 8080})
+	require.NoError(t, err)
+	dms, err := svc.queryDecisionMakers(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, dms, 1)
+	assert.Equal(t, domain.NodeStateOnline, dms[0].State)
+
+	nodes, err := svc.ListNodes(ctx)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "node-a", nodes[0].Name)
+	require.NotNil(t, nodes[0].DecisionMaker)
+	assert.True(t, nodes[0].DecisionMaker.Online)
+}
+
+func TestDecisionMakerRegistrationsHeartbeatTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	mockRepo := domain.NewMockRepository(t)
+	mockRepo.EXPECT().
+		QueryDecisionMakerRegistrations(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, opt *domain.QueryDecisionMakerRegistrationOptions) error {
+			assert.WithinDuration(t, now.Add(-dmRegistrationRetention*time.Minute), opt.HeartbeatAfter, time.Second)
+			opt.Result = []*domain.DecisionMakerRegistration{
+				{NodeID: "node-a", LastHeartbeat: now.Add(-30 * time.Second)},
+				{NodeID: "node-b", LastHeartbeat: now.Add(-2 * time.Minute)},
+			}
+			return nil
+		}).
+		Once()
+	svc := &Service{Repo: mockRepo, discoveryCfg: config.DiscoveryConfig{HeartbeatTTLSeconds: 60}}
+
+	regs, err := svc.decisionMakerRegistrations(ctx)
+	require.NoError(t, err)
+	require.Len(t, regs, 2)
+	assert.True(t, regs[0].Online)
+	assert.False(t, regs[1].Online)
+}
+
+func TestVerifyDecisionMakerRegistrationToken(t *testing.T) {
+	svc := &Service{}
+	assertHTTPStatus(t, http.StatusNotImplemented, svc.VerifyDecisionMakerRegistrationToken("secret"))
+
+	svc.discoveryCfg.RegistrationToken = "secret"
+	assertHTTPStatus(t, http.StatusUnauthorized, svc.VerifyDecisionMakerRegistrationToken("wrong"))
+	assert.NoError(t, svc.VerifyDecisionMakerRegistrationToken("secret"))
+}
+
+func TestDeregisterDecisionMaker(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	svc := &Service{Repo: mockRepo}
+	assertHTTPStatus(t, http.StatusNotFound, svc.DeregisterDecisionMaker(ctx, "node-a"))
+
+	_, err := svc.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{NodeID: "node-a", Host: "10.1.0.1", Port: 8080})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeregisterDecisionMaker(ctx, "node-a"))
+	regs, err := svc.decisionMakerRegistrations(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, regs)
+}
+
+func assertHTTPStatus(t *testing.T, code int, err error) {
+	t.Helper()
+	var httpErr *errs.HTTPStatusError
+	require.True(t, errors.As(err, &httpErr), "expected HTTPStatusError, got %v", err)
+	assert.Equal(t, code, httpErr.StatusCode)
+}
+
+// dmRegistryStore keeps the decision maker registrations and pulls of a
+// repository mock in memory like the Mongo repository, shared by every
+// replica that uses the mock.
+type dmRegistryStore struct {
+	mu            sync.Mutex
+	registrations map[string]*domain.DecisionMakerRegistration
+	pulls         map[string]*domain.DecisionMakerPull
+}
+
+// newDMRegistryMockRepo returns a repository mock with no decision maker
+// registered or pulling.
+func newDMRegistryMockRepo(t *testing.T) *domain.MockRepository {
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	return mockRepo
+}
+
+func newDMRegistryStore(mockRepo *domain.MockRepository) *dmRegistryStore {
+	s := &dmRegistryStore{
+		registrations: map[string]*domain.DecisionMakerRegistration{},
+		pulls:         map[string]*domain.DecisionMakerPull{},
+	}
+	mockRepo.EXPECT().UpsertDecisionMakerRegistration(mock.Anything, mock.Anything).RunAndReturn(s.upsert).Maybe()
+	mockRepo.EXPECT().DeleteDecisionMakerRegistration(mock.Anything, mock.Anything).RunAndReturn(s.delete).Maybe()
+	mockRepo.EXPECT().QueryDecisionMakerRegistrations(mock.Anything, mock.Anything).RunAndReturn(s.queryRegistrations).Maybe()
+	mockRepo.EXPECT().MarkDecisionMakerPull(mock.Anything, mock.Anything).RunAndReturn(s.markPull).Maybe()
+	mockRepo.EXPECT().QueryDecisionMakerPulls(mock.Anything, mock.Anything).RunAndReturn(s.queryPulls).Maybe()
+	return s
+}
+
+func (s *dmRegistryStore) upsert(_ context.Context, reg *domain.DecisionMakerRegistration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *reg
+	prev, ok := s.registrations[reg.NodeID]
+	changed := !ok || prev.Host != reg.Host || prev.Port != reg.Port
+	if !changed {
+		stored.RegisteredAt = prev.RegisteredAt
+	}
+	s.registrations[reg.NodeID] = &stored
+	return changed, nil
+}
+
+func (s *dmRegistryStore) delete(_ context.Context, nodeID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.registrations[nodeID]
+	delete(s.registrations, nodeID)
+	return ok, nil
+}
+
+func (s *dmRegistryStore) queryRegistrations(_ context.Context, opt *domain.QueryDecisionMakerRegistrationOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, reg := range s.registrations {
+		if reg.LastHeartbeat.After(opt.HeartbeatAfter) {
+			stored := *reg
+			opt.Result = append(opt.Result, &stored)
+		}
+	}
+	sort.Slice(opt.Result, func(i, j int) bool { return opt.Result[i].NodeID < opt.Result[j].NodeID })
+	return nil
+}
+
+func (s *dmRegistryStore) markPull(_ context.Context, pull *domain.DecisionMakerPull) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *pull
+	s.pulls[pull.NodeID] = &stored
+	return nil
+}
+
+func (s *dmRegistryStore) queryPulls(_ context.Context, opt *domain.QueryDecisionMakerPullOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pull := range s.pulls {
+		if pull.PulledAt.After(opt.PulledAfter) {
+			opt.Result = append(opt.Result, pull)
+		}
+	}
+	return nil
+}
+
+func TestDecisionMakerRegistryIsSharedByReplicas(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := domain.NewMockRepository(t)
+	newDMRegistryStore(mockRepo)
+	mockRepo.EXPECT().QueryIntents(mock.Anything, mock.Anything).Return(nil)
+	follower := &Service{Repo: mockRepo}
+	leader := &Service{Repo: mockRepo}
+
+	// The follower receives the heartbeats and pulls, the leader pushes.
+	for _, nodeID := range []string{"node-a", "node-b"} {
+		_, err := follower.RegisterDecisionMaker(ctx, &domain.DecisionMakerRegistration{NodeID: nodeID, Host: "10.0.0.1", Port: 8080})
+		require.NoError(t, err)
+	}
+	_, err := follower.PullNodeIntents(ctx, "node-a", "")
+	require.NoError(t, err)
+
+	dms, err := leader.queryDecisionMakers(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, dms, 1, "the leader skips the node that pulls")
+	assert.Equal(t, "node-b", dms[0].NodeID)
+
+	nodes, err := leader.ListNodes(ctx)
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	assert.True(t, nodes[1].DecisionMaker.Online)
+
+	require.NoError(t, leader.DeregisterDecisionMaker(ctx, "node-b"))
+	assertHTTPStatus(t, http.StatusNotFound, follower.DeregisterDecisionMaker(ctx, "node-b"))
+}
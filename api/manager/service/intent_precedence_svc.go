@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/intentprecedence"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type intentOverrideRepository interface {
+	UpdateIntentOverrides(ctx context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error
+	UpdateNodeIntentOverrides(ctx context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error
+}
+
+type intentConflictDMAdapter interface {
+	GetIntentConflicts(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.IntentConflictReport, error)
+}
+
+// strategyRank ranks a strategy the way decision makers rank its intents. A
+// strategy that is not created yet gets a fresh ID, so it loses the age tie
+// against every existing one.
+func strategyRank(strategy *domain.ScheduleStrategy) intentprecedence.Rank {
+	id := strategy.ID
+	if id.IsZero() {
+		id = bson.NewObjectID()
+	}
+	return intentprecedence.Rank{
+		Precedence:  strategy.Precedence,
+		Level:       intentprecedence.LevelPod,
+		Specificity: strategy.Specificity(),
+		Key:         id.Hex(),
+	}
+}
+
+func scheduleIntentRank(intent *domain.ScheduleIntent) intentprecedence.Rank {
+	return intentprecedence.Rank{
+		Precedence:  intent.Precedence,
+		Level:       intentprecedence.LevelPod,
+		Specificity: intent.Specificity,
+		Key:         intent.StrategyID.Hex(),
+	}
+}
+
+func nodeSchedulingIntentRank(intent *domain.NodeSchedulingIntent) intentprecedence.Rank {
+	return intentprecedence.Rank{
+		Precedence: intent.Precedence,
+		Level:      intentprecedence.LevelNode,
+		Key:        intent.PolicyID.Hex(),
+	}
+}
+
+// syncIntentOverrides asks every online decision maker which processes were
+// targeted by more than one intent and records on each losing intent the
+// processes it lost and to whom. Intents on nodes whose decision maker could
+// not be asked keep their last overrides.
+func (svc *Service) syncIntentOverrides(ctx context.Context) error {
+	repo, ok := svc.Repo.(intentOverrideRepository)
+	if !ok {
+		return nil
+	}
+	dmAdapter, ok := svc.DMAdapter.(intentConflictDMAdapter)
+	if !ok {
+		return nil
+	}
+
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{})
+	if err != nil {
+		return err
+	}
+	reachedNodes := make(map[string]struct{}, len(dms))
+	var reports []*domain.IntentConflictReport
+	for _, dm := range dms {
+		if dm.State != domain.NodeStateOnline {
+			continue
+		}
+		nodeReports, err := dmAdapter.GetIntentConflicts(ctx, dm)
+		if err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to get intent conflicts from dm %s", dm)
+			continue
+		}
+		reachedNodes[dm.NodeID] = struct{}{}
+		reports = append(reports, nodeReports...)
+	}
+	if len(reachedNodes) == 0 {
+		return nil
+	}
+	intentOverrides, nodeIntentOverrides := overridesFromConflictReports(reports)
+
+	intentOpt := &domain.QueryIntentOptions{}
+	if err := svc.Repo.QueryIntents(ctx, intentOpt); err != nil {
+		return fmt.Errorf("query intents: %w", err)
+	}
+	for _, intent := range intentOpt.Result {
+		if _, ok := reachedNodes[intent.NodeID]; !ok {
+			continue
+		}
+		want := intentOverrides[intent.ID.Hex()]
+		if reflect.DeepEqual(intent.Overrides, want) {
+			continue
+		}
+		if err := repo.UpdateIntentOverrides(ctx, intent.ID, want); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update overrides of intent %s", intent.ID.Hex())
+		}
+	}
+
+	nodeIntentOpt := &domain.QueryNodeIntentOptions{}
+	if err := svc.Repo.QueryNodeIntents(ctx, nodeIntentOpt); err != nil {
+		return fmt.Errorf("query node intents: %w", err)
+	}
+	for _, intent := range nodeIntentOpt.Result {
+		if _, ok := reachedNodes[intent.NodeID]; !ok {
+			continue
+		}
+		want := nodeIntentOverrides[intent.NodeID+"/"+intent.PolicyID.Hex()]
+		if reflect.DeepEqual(intent.Overrides, want) {
+			continue
+		}
+		if err := repo.UpdateNodeIntentOverrides(ctx, intent.ID, want); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update overrides of node intent %s", intent.ID.Hex())
+		}
+	}
+	return nil
+}
+
+// overridesFromConflictReports groups the reported processes by losing
+// intent and winner. Strategy losers are keyed by intent ID, node policy
+// losers by node ID and policy ID, since a policy has one intent per node.
+func overridesFromConflictReports(reports []*domain.IntentConflictReport) (map[string][]domain.IntentOverride, map[string][]domain.IntentOverride) {
+	type overrideKey struct {
+		loserKind  string
+		loserID    string
+		winnerKind string
+		winnerID   string
+		reason     string
+	}
+	pidsByKey := make(map[overrideKey][]int)
+	var keys []overrideKey
+	for _, report := range reports {
+		if report.LoserID == "" {
+			continue
+		}
+		loserID := report.LoserID
+		if report.LoserKind == domain.IntentOverrideNodePolicy {
+			loserID = report.NodeID + "/" + report.LoserID
+		}
+		winnerID := report.WinnerID
+		if report.WinnerKind == domain.IntentOverrideStrategy && report.WinnerStrategyID != "" {
+			winnerID = report.WinnerStrategyID
+		}
+		key := overrideKey{
+			loserKind:  report.LoserKind,
+			loserID:    loserID,
+			winnerKind: report.WinnerKind,
+			winnerID:   winnerID,
+			reason:     report.Reason,
+		}
+		if _, ok := pidsByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		pidsByKey[key] = append(pidsByKey[key], report.PID)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].winnerKind != keys[j].winnerKind {
+			return keys[i].winnerKind < keys[j].winnerKind
+		}
+		if keys[i].winnerID != keys[j].winnerID {
+			return keys[i].winnerID < keys[j].winnerID
+		}
+		return keys[i].reason < keys[j].reason
+	})
+
+	intentOverrides := make(map[string][]domain.IntentOverride)
+	nodeIntentOverrides := make(map[string][]domain.IntentOverride)
+	for _, key := range keys {
+		pids := pidsByKey[key]
+		sort.Ints(pids)
+		override := domain.IntentOverride{
+			WinnerKind: key.winnerKind,
+			WinnerID:   key.winnerID,
+			Reason:     key.reason,
+			PIDs:       pids,
+		}
+		switch key.loserKind {
+		case domain.IntentOverrideStrategy:
+			intentOverrides[key.loserID] = append(intentOverrides[key.loserID], override)
+		case domain.IntentOverrideNodePolicy:
+			nodeIntentOverrides[key.loserID] = append(nodeIntentOverrides[key.loserID], override)
+		}
+	}
+	return intentOverrides, nodeIntentOverrides
+}
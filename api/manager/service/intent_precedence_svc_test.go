@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeOverrideRepo struct {
+	domain.Repository
+	intents     []*domain.ScheduleIntent
+	nodeIntents []*domain.NodeSchedulingIntent
+	updated     map[bson.ObjectID][]domain.IntentOverride
+}
+
+func (r *fakeOverrideRepo) QueryIntents(_ context.Context, opt *domain.QueryIntentOptions) error {
+	opt.Result = append(opt.Result, r.intents...)
+	return nil
+}
+
+func (r *fakeOverrideRepo) QueryNodeIntents(_ context.Context, opt *domain.QueryNodeIntentOptions) error {
+	opt.Result = append(opt.Result, r.nodeIntents...)
+	return nil
+}
+
+func (r *fakeOverrideRepo) UpdateIntentOverrides(_ context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error {
+	r.updated[intentID] = overrides
+	return nil
+}
+
+func (r *fakeOverrideRepo) UpdateNodeIntentOverrides(_ context.Context, intentID bson.ObjectID, overrides []domain.IntentOverride) error {
+	r.updated[intentID] = overrides
+	return nil
+}
+
+type fakeConflictDMAdapter struct {
+	domain.DecisionMakerAdapter
+	reports map[string][]*domain.IntentConflictReport
+}
+
+func (a *fakeConflictDMAdapter) GetIntentConflicts(_ context.Context, dm *domain.DecisionMakerPod) ([]*domain.IntentConflictReport, error) {
+	return a.reports[dm.NodeID], nil
+}
+
+func TestOverridesFromConflictReportsGroupsPIDs(t *testing.T) {
+	reports := []*domain.IntentConflictReport{
+		{NodeID: "node-a", PID: 12, Reason: "specificity", WinnerKind: domain.IntentOverrideStrategy, WinnerID: "i-win", WinnerStrategyID: "s-win", LoserKind: domain.IntentOverrideStrategy, LoserID: "i-lose"},
+		{NodeID: "node-a", PID: 11, Reason: "specificity", WinnerKind: domain.IntentOverrideStrategy, WinnerID: "i-win", WinnerStrategyID: "s-win", LoserKind: domain.IntentOverrideStrategy, LoserID: "i-lose"},
+		{NodeID: "node-a", PID: 11, Reason: "pod-level", WinnerKind: domain.IntentOverrideStrategy, WinnerID: "i-win", WinnerStrategyID: "s-win", LoserKind: domain.IntentOverrideNodePolicy, LoserID: "p1"},
+	}
+
+	intentOverrides, nodeIntentOverrides := overridesFromConflictReports(reports)
+
+	assert.Equal(t, map[string][]domain.IntentOverride{
+		"i-lose": {{WinnerKind: domain.IntentOverrideStrategy, WinnerID: "s-win", Reason: "specificity", PIDs: []int{11, 12}}},
+	}, intentOverrides)
+	assert.Equal(t, map[string][]domain.IntentOverride{
+		"node-a/p1": {{WinnerKind: domain.IntentOverrideStrategy, WinnerID: "s-win", Reason: "pod-level", PIDs: []int{11}}},
+	}, nodeIntentOverrides)
+}
+
+func TestSyncIntentOverridesUpdatesReachedNodesOnly(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	loser := &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, NodeID: "node-a"}
+	resolved := &domain.ScheduleIntent{
+		BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()},
+		NodeID:     "node-a",
+		Overrides:  []domain.IntentOverride{{WinnerKind: domain.IntentOverrideStrategy, WinnerID: "gone", Reason: "age", PIDs: []int{5}}},
+	}
+	unreached := &domain.ScheduleIntent{
+		BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()},
+		NodeID:     "node-b",
+		Overrides:  []domain.IntentOverride{{WinnerKind: domain.IntentOverrideStrategy, WinnerID: "kept", Reason: "age", PIDs: []int{6}}},
+	}
+	policyID := bson.NewObjectID()
+	nodeLoser := &domain.NodeSchedulingIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, PolicyID: policyID, NodeID: "node-a"}
+	repo := &fakeOverrideRepo{
+		Repository:  newDMRegistryMockRepo(t),
+		intents:     []*domain.ScheduleIntent{loser, resolved, unreached},
+		nodeIntents: []*domain.NodeSchedulingIntent{nodeLoser},
+		updated:     map[bson.ObjectID][]domain.IntentOverride{},
+	}
+	dmAdapter := &fakeConflictDMAdapter{reports: map[string][]*domain.IntentConflictReport{
+		"node-a": {
+			{NodeID: "node-a", PID: 7, Reason: "precedence", WinnerKind: domain.IntentOverrideNodePolicy, WinnerID: policyID.Hex(), LoserKind: domain.IntentOverrideStrategy, LoserID: loser.ID.Hex()},
+			{NodeID: "node-a", PID: 8, Reason: "pod-level", WinnerKind: domain.IntentOverrideStrategy, WinnerID: loser.ID.Hex(), WinnerStrategyID: "s1", LoserKind: domain.IntentOverrideNodePolicy, LoserID: policyID.Hex()},
+		},
+	}}
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{
+		{NodeID: "node-a", State: domain.NodeStateOnline},
+		{NodeID: "node-b", State: domain.NodeStateOffline},
+	}, nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, DMAdapter: dmAdapter, Repo: repo}
+	require.NoError(t, svc.syncIntentOverrides(context.Background()))
+
+	assert.Len(t, repo.updated, 3)
+	assert.Equal(t, []domain.IntentOverride{{WinnerKind: domain.IntentOverrideNodePolicy, WinnerID: policyID.Hex(), Reason: "precedence", PIDs: []int{7}}}, repo.updated[loser.ID])
+	assert.Equal(t, []domain.IntentOverride{{WinnerKind: domain.IntentOverrideStrategy, WinnerID: "s1", Reason: "pod-level", PIDs: []int{8}}}, repo.updated[nodeLoser.ID])
+	overrides, ok := repo.updated[resolved.ID]
+	assert.True(t, ok, "overrides that no longer apply must be cleared")
+	assert.Empty(t, overrides)
+	_, ok = repo.updated[unreached.ID]
+	assert.False(t, ok, "intents on unreachable nodes keep their overrides")
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// intentLastAppliedRefreshInterval is how stale the recorded last apply time
+// of an intent may get before it is rewritten. Decision makers apply intents
+// every time the scheduler polls them, so it is not written on every sync.
+const intentLastAppliedRefreshInterval = 5 * time.Minute
+
+type intentApplyStatusRepository interface {
+	UpdateIntentApplyStatus(ctx context.Context, intentID bson.ObjectID, report *domain.IntentApplyReport) error
+}
+
+type intentStatusDMAdapter interface {
+	GetIntentStatuses(ctx context.Context, decisionMaker *domain.DecisionMakerPod) ([]*domain.IntentApplyReport, error)
+}
+
+// syncIntentStatuses asks every online decision maker what became of its
+// intents the last time the scheduler listed them, and records the state,
+// matched PIDs and last apply time on each intent. Intents a decision maker
+// has not reported yet stay Sent; intents on nodes whose decision maker
+// could not be asked keep their last status.
+func (svc *Service) syncIntentStatuses(ctx context.Context) error {
+	repo, ok := svc.Repo.(intentApplyStatusRepository)
+	if !ok {
+		return nil
+	}
+	dmAdapter, ok := svc.DMAdapter.(intentStatusDMAdapter)
+	if !ok {
+		return nil
+	}
+
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{})
+	if err != nil {
+		return err
+	}
+	reachedNodes := make(map[string]struct{}, len(dms))
+	reports := make(map[string]*domain.IntentApplyReport)
+	for _, dm := range dms {
+		if dm.State != domain.NodeStateOnline {
+			continue
+		}
+		nodeReports, err := dmAdapter.GetIntentStatuses(ctx, dm)
+		if err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to get intent statuses from dm %s", dm)
+			continue
+		}
+		reachedNodes[dm.NodeID] = struct{}{}
+		for _, report := range nodeReports {
+			if report.State != domain.IntentStateUnknown {
+				reports[report.IntentID] = report
+			}
+		}
+	}
+	if len(reachedNodes) == 0 {
+		return nil
+	}
+
+	intentOpt := &domain.QueryIntentOptions{}
+	if err := svc.Repo.QueryIntents(ctx, intentOpt); err != nil {
+		return fmt.Errorf("query intents: %w", err)
+	}
+	for _, intent := range intentOpt.Result {
+		if _, ok := reachedNodes[intent.NodeID]; !ok {
+			continue
+		}
+		report, ok := reports[intent.ID.Hex()]
+		if !ok || report.NodeID != intent.NodeID || intentApplyStatusEqual(intent, report) {
+			continue
+		}
+		if err := repo.UpdateIntentApplyStatus(ctx, intent.ID, report); err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to update apply status of intent %s", intent.ID.Hex())
+		}
+	}
+	return nil
+}
+
+// intentApplyStatusEqual reports whether the intent already records the
+// reported status, give or take a last apply time that is not yet
+// intentLastAppliedRefreshInterval old.
+func intentApplyStatusEqual(intent *domain.ScheduleIntent, report *domain.IntentApplyReport) bool {
+	if intent.State != report.State || intent.StatusMessage != report.Message || !slices.Equal(intent.MatchedPIDs, report.PIDs) {
+		return false
+	}
+	if (intent.LastAppliedAt == 0) != (report.LastAppliedAt == 0) {
+		return false
+	}
+	return report.LastAppliedAt-intent.LastAppliedAt < intentLastAppliedRefreshInterval.Milliseconds()
+}
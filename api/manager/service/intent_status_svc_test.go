@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeIntentStatusRepo struct {
+	domain.Repository
+	intents []*domain.ScheduleIntent
+	updated map[bson.ObjectID]*domain.IntentApplyReport
+}
+
+func (r *fakeIntentStatusRepo) QueryIntents(_ context.Context, opt *domain.QueryIntentOptions) error {
+	opt.Result = append(opt.Result, r.intents...)
+	return nil
+}
+
+func (r *fakeIntentStatusRepo) UpdateIntentApplyStatus(_ context.Context, intentID bson.ObjectID, report *domain.IntentApplyReport) error {
+	r.updated[intentID] = report
+	return nil
+}
+
+type fakeIntentStatusDMAdapter struct {
+	domain.DecisionMakerAdapter
+	reports map[string][]*domain.IntentApplyReport
+}
+
+func (a *fakeIntentStatusDMAdapter) GetIntentStatuses(_ context.Context, dm *domain.DecisionMakerPod) ([]*domain.IntentApplyReport, error) {
+	return a.reports[dm.NodeID], nil
+}
+
+func TestSyncIntentStatusesRecordsReportedStates(t *testing.T) {
+	now := time.Now().UnixMilli()
+	newIntent := func(nodeID string, state domain.IntentState) *domain.ScheduleIntent {
+		return &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, NodeID: nodeID, State: state}
+	}
+	applied := newIntent("node-a", domain.IntentStateSent)
+	noMatch := newIntent("node-a", domain.IntentStateApplied)
+	noMatch.MatchedPIDs = []int{9}
+	unchanged := newIntent("node-a", domain.IntentStateApplied)
+	unchanged.MatchedPIDs = []int{3}
+	unchanged.LastAppliedAt = now - time.Minute.Milliseconds()
+	stale := newIntent("node-a", domain.IntentStateApplied)
+	stale.MatchedPIDs = []int{4}
+	stale.LastAppliedAt = now - time.Hour.Milliseconds()
+	notReported := newIntent("node-a", domain.IntentStateSent)
+	unreached := newIntent("node-b", domain.IntentStateSent)
+
+	repo := &fakeIntentStatusRepo{
+		Repository: newDMRegistryMockRepo(t),
+		intents:    []*domain.ScheduleIntent{applied, noMatch, unchanged, stale, notReported, unreached},
+		updated:    map[bson.ObjectID]*domain.IntentApplyReport{},
+	}
+	report := func(intent *domain.ScheduleIntent, state domain.IntentState, pids ...int) *domain.IntentApplyReport {
+		r := &domain.IntentApplyReport{NodeID: intent.NodeID, IntentID: intent.ID.Hex(), State: state, PIDs: pids}
+		if len(pids) > 0 {
+			r.LastAppliedAt = now
+		}
+		return r
+	}
+	dmAdapter := &fakeIntentStatusDMAdapter{reports: map[string][]*domain.IntentApplyReport{
+		"node-a": {
+			report(applied, domain.IntentStateApplied, 1, 2),
+			report(noMatch, domain.IntentStateNoMatchingProcess),
+			report(unchanged, domain.IntentStateApplied, 3),
+			report(stale, domain.IntentStateApplied, 4),
+		},
+		"node-b": {report(unreached, domain.IntentStateFailed)},
+	}}
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{
+		{NodeID: "node-a", State: domain.NodeStateOnline},
+		{NodeID: "node-b", State: domain.NodeStateOffline},
+	}, nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, DMAdapter: dmAdapter, Repo: repo}
+	require.NoError(t, svc.syncIntentStatuses(context.Background()))
+
+	assert.Len(t, repo.updated, 3)
+	require.Contains(t, repo.updated, applied.ID)
+	assert.Equal(t, domain.IntentStateApplied, repo.updated[applied.ID].State)
+	assert.Equal(t, []int{1, 2}, repo.updated[applied.ID].PIDs)
+	require.Contains(t, repo.updated, noMatch.ID)
+	assert.Equal(t, domain.IntentStateNoMatchingProcess, repo.updated[noMatch.ID].State)
+	assert.Contains(t, repo.updated, stale.ID, "an old last apply time is refreshed")
+	assert.NotContains(t, repo.updated, unchanged.ID, "a recent last apply time is not rewritten")
+	assert.NotContains(t, repo.updated, notReported.ID)
+	assert.NotContains(t, repo.updated, unreached.ID)
+}
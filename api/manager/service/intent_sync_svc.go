@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/util"
+)
+
+// intentMerkleDMAdapter is implemented by decision maker adapters that can
+// walk the intent Merkle tree and apply a delta. Without it a root mismatch
+// re-sends every intent of the node.
+type intentMerkleDMAdapter interface {
+	GetIntentMerkleTree(ctx context.Context, decisionMaker *domain.DecisionMakerPod, rootHash string, depth int) (*domain.IntentMerkleNode, error)
+	PatchSchedulingIntents(ctx context.Context, decisionMaker *domain.DecisionMakerPod, upserts []*domain.ScheduleIntent, removedHashes []string) error
+}
+
+const (
+	// intentMerkleWalkDepth is how many levels of the remote tree each
+	// request returns.
+	intentMerkleWalkDepth = 4
+	// maxIntentMerkleWalkRequests bounds the walk of one decision maker; a
+	// tree that differs that much is cheaper to re-send in full.
+	maxIntentMerkleWalkRequests = 32
+)
+
+var errIntentDeltaEmpty = errors.New("merkle roots differ but no intent delta was found")
+
+// intentDelta is what a decision maker is missing and what it holds that the
+// manager does not. A changed intent shows up in both, with its new content
+// added and its old hash removed.
+type intentDelta struct {
+	Added         []*domain.ScheduleIntent
+	RemovedHashes []string
+}
+
+// patchIntentsToDM walks the decision maker's intent Merkle tree from
+// remoteRoot, descending only into subtrees the manager does not have, and
+// sends the difference. It returns the intents that were sent.
+func (svc *Service) patchIntentsToDM(ctx context.Context, dmAdapter intentMerkleDMAdapter, dm *domain.DecisionMakerPod, remoteRoot string, intents []*domain.ScheduleIntent) ([]*domain.ScheduleIntent, error) {
+	delta, err := diffIntentMerkleTree(ctx, dmAdapter, dm, remoteRoot, intents)
+	if err != nil {
+		return nil, err
+	}
+	if err := dmAdapter.PatchSchedulingIntents(ctx, dm, delta.Added, delta.RemovedHashes); err != nil {
+		return nil, fmt.Errorf("patch intents: %w", err)
+	}
+	return delta.Added, nil
+}
+
+func diffIntentMerkleTree(ctx context.Context, dmAdapter intentMerkleDMAdapter, dm *domain.DecisionMakerPod, remoteRoot string, intents []*domain.ScheduleIntent) (*intentDelta, error) {
+	sortedIntents := sortScheduleIntentsByKey(intents)
+	leafHashes := make([]string, 0, len(sortedIntents))
+	localByHash := make(map[string]*domain.ScheduleIntent, len(sortedIntents))
+	for _, intent := range sortedIntents {
+		hash := hashScheduleIntent(intent)
+		leafHashes = append(leafHashes, hash)
+		localByHash[hash] = intent
+	}
+	localNodes := util.IndexMerkleTree(util.BuildMerkleTree(leafHashes))
+
+	remoteLeaves := make(map[string]struct{})
+	if remoteRoot != util.BuildMerkleTree(nil).Hash {
+		var pending []string
+		var collect func(node *domain.IntentMerkleNode)
+		collect = func(node *domain.IntentMerkleNode) {
+			if node == nil {
+				return
+			}
+			if local, ok := localNodes[node.Hash]; ok {
+				// The same subtree exists locally, so its leaves are known
+				// without asking for them.
+				for _, hash := range util.MerkleLeafHashes(local) {
+					remoteLeaves[hash] = struct{}{}
+				}
+				return
+			}
+			switch {
+			case node.Leaf:
+				remoteLeaves[node.Hash] = struct{}{}
+			case node.Left == nil && node.Right == nil:
+				pending = append(pending, node.Hash)
+			default:
+				collect(node.Left)
+				collect(node.Right)
+			}
+		}
+
+		pending = append(pending, remoteRoot)
+		for requests := 0; len(pending) > 0; requests++ {
+			if requests == maxIntentMerkleWalkRequests {
+				return nil, fmt.Errorf("merkle walk of dm %s needs more than %d requests", dm, maxIntentMerkleWalkRequests)
+			}
+			hash := pending[0]
+			pending = pending[1:]
+			node, err := dmAdapter.GetIntentMerkleTree(ctx, dm, hash, intentMerkleWalkDepth)
+			if err != nil {
+				return nil, fmt.Errorf("get merkle subtree %s: %w", hash, err)
+			}
+			if node == nil {
+				// The decision maker's intents changed during the walk.
+				return nil, fmt.Errorf("merkle subtree %s no longer exists on dm %s", hash, dm)
+			}
+			collect(node)
+		}
+	}
+
+	delta := &intentDelta{}
+	for _, hash := range leafHashes {
+		if _, ok := remoteLeaves[hash]; !ok {
+			delta.Added = append(delta.Added, localByHash[hash])
+			// Send duplicated intents once.
+			remoteLeaves[hash] = struct{}{}
+		}
+	}
+	for hash := range remoteLeaves {
+		if _, ok := localByHash[hash]; !ok {
+			delta.RemovedHashes = append(delta.RemovedHashes, hash)
+		}
+	}
+	sort.Strings(delta.RemovedHashes)
+	if len(delta.Added) == 0 && len(delta.RemovedHashes) == 0 {
+		return nil, errIntentDeltaEmpty
+	}
+	return delta, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeMerkleDMAdapter serves the intent Merkle tree of the intents a decision
+// maker holds.
+type fakeMerkleDMAdapter struct {
+	domain.DecisionMakerAdapter
+	root          *util.MerkleNode
+	treeRequests  int
+	patched       bool
+	upserts       []*domain.ScheduleIntent
+	removedHashes []string
+}
+
+func newFakeMerkleDMAdapter(intents []*domain.ScheduleIntent) *fakeMerkleDMAdapter {
+	leafHashes := make([]string, 0, len(intents))
+	for _, intent := range sortScheduleIntentsByKey(intents) {
+		leafHashes = append(leafHashes, hashScheduleIntent(intent))
+	}
+	return &fakeMerkleDMAdapter{root: util.BuildMerkleTree(leafHashes)}
+}
+
+func (a *fakeMerkleDMAdapter) GetIntentMerkleTree(_ context.Context, _ *domain.DecisionMakerPod, rootHash string, depth int) (*domain.IntentMerkleNode, error) {
+	a.treeRequests++
+	return convertFakeMerkleNode(util.FindMerkleNode(a.root, rootHash), depth), nil
+}
+
+func convertFakeMerkleNode(node *util.MerkleNode, depth int) *domain.IntentMerkleNode {
+	if node == nil {
+		return nil
+	}
+	result := &domain.IntentMerkleNode{Hash: node.Hash, Leaf: node.Left == nil && node.Right == nil}
+	if depth > 0 {
+		result.Left = convertFakeMerkleNode(node.Left, depth-1)
+		result.Right = convertFakeMerkleNode(node.Right, depth-1)
+	}
+	return result
+}
+
+func (a *fakeMerkleDMAdapter) PatchSchedulingIntents(_ context.Context, _ *domain.DecisionMakerPod, upserts []*domain.ScheduleIntent, removedHashes []string) error {
+	a.patched = true
+	a.upserts = upserts
+	a.removedHashes = removedHashes
+	return nil
+}
+
+func newSyncTestIntents(n int) []*domain.ScheduleIntent {
+	intents := make([]*domain.ScheduleIntent, 0, n)
+	for i := 0; i < n; i++ {
+		intents = append(intents, &domain.ScheduleIntent{
+			BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()},
+			PodID:      fmt.Sprintf("pod-%03d", i),
+			NodeID:     "node-a",
+			Priority:   1,
+		})
+	}
+	return intents
+}
+
+func TestPatchIntentsToDMSendsOnlyTheDelta(t *testing.T) {
+	dm := &domain.DecisionMakerPod{NodeID: "node-a"}
+	remote := newSyncTestIntents(40)
+	local := append([]*domain.ScheduleIntent{}, remote...)
+	// pod-010 changed, pod-020 was deleted and pod-999 was added.
+	changed := *local[10]
+	changed.Priority = 2
+	local[10] = &changed
+	local = append(local[:20], local[21:]...)
+	added := &domain.ScheduleIntent{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, PodID: "pod-999", NodeID: "node-a"}
+	local = append(local, added)
+
+	dmAdapter := newFakeMerkleDMAdapter(remote)
+	svc := &Service{}
+	sent, err := svc.patchIntentsToDM(context.Background(), dmAdapter, dm, dmAdapter.root.Hash, local)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*domain.ScheduleIntent{&changed, added}, sent)
+	assert.Equal(t, sent, dmAdapter.upserts)
+	assert.ElementsMatch(t, []string{hashScheduleIntent(remote[10]), hashScheduleIntent(remote[20])}, dmAdapter.removedHashes)
+}
+
+func TestPatchIntentsToDMDescendsOnlyIntoMismatchedSubtrees(t *testing.T) {
+	remote := newSyncTestIntents(40)
+	local := append([]*domain.ScheduleIntent{}, remote...)
+	changed := *local[10]
+	changed.ExecutionTime = 100
+	local[10] = &changed
+
+	dmAdapter := newFakeMerkleDMAdapter(remote)
+	sent, err := (&Service{}).patchIntentsToDM(context.Background(), dmAdapter, &domain.DecisionMakerPod{}, dmAdapter.root.Hash, local)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*domain.ScheduleIntent{&changed}, sent)
+	assert.Equal(t, []string{hashScheduleIntent(remote[10])}, dmAdapter.removedHashes)
+	// The 40 leaves are 6 levels deep: one request for the top 4 levels and
+	// one for the only subtree that differs.
+	assert.Equal(t, 2, dmAdapter.treeRequests)
+}
+
+func TestPatchIntentsToDMEmptyRemoteTree(t *testing.T) {
+	local := newSyncTestIntents(3)
+	dmAdapter := newFakeMerkleDMAdapter(nil)
+
+	sent, err := (&Service{}).patchIntentsToDM(context.Background(), dmAdapter, &domain.DecisionMakerPod{}, dmAdapter.root.Hash, local)
+	require.NoError(t, err)
+
+	assert.Len(t, sent, 3)
+	assert.Empty(t, dmAdapter.removedHashes)
+	assert.Zero(t, dmAdapter.treeRequests)
+}
+
+func TestPatchIntentsToDMFailsWhenRemoteTreeChanges(t *testing.T) {
+	dmAdapter := newFakeMerkleDMAdapter(newSyncTestIntents(2))
+
+	_, err := (&Service{}).patchIntentsToDM(context.Background(), dmAdapter, &domain.DecisionMakerPod{}, "gone", newSyncTestIntents(1))
+	require.Error(t, err)
+	assert.False(t, dmAdapter.patched)
+}
+
+func TestResyncIntentsToDMsPatchesMismatchedDM(t *testing.T) {
+	ctx := context.Background()
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockRepo := newDMRegistryMockRepo(t)
+
+	dm := &domain.DecisionMakerPod{NodeID: "node-a", State: domain.NodeStateOnline}
+	local := newSyncTestIntents(5)
+	dmAdapter := &fakeResyncDMAdapter{fakeMerkleDMAdapter: newFakeMerkleDMAdapter(local[:4])}
+
+	mockK8S.EXPECT().
+		QueryDecisionMakerPods(mock.Anything, mock.Anything).
+		Return([]*domain.DecisionMakerPod{dm}, nil).Once()
+	mockRepo.EXPECT().
+		QueryIntents(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryIntentOptions) {
+			opt.Result = local
+		}).
+		Return(nil).Once()
+	// Only the intent the decision maker was missing is marked as sent.
+	mockRepo.EXPECT().
+		BatchUpdateIntentsState(mock.Anything, []bson.ObjectID{local[4].ID}, domain.IntentStateSent).
+		Return(nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, Repo: mockRepo, DMAdapter: dmAdapter}
+	require.NoError(t, svc.resyncIntentsToDMs(ctx))
+
+	assert.Equal(t, []*domain.ScheduleIntent{local[4]}, dmAdapter.upserts)
+	assert.Empty(t, dmAdapter.removedHashes)
+}
+
+type fakeResyncDMAdapter struct {
+	*fakeMerkleDMAdapter
+}
+
+func (a *fakeResyncDMAdapter) GetIntentMerkleRoot(_ context.Context, _ *domain.DecisionMakerPod) (string, error) {
+	return a.root.Hash, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+)
+
+// verifyK8SNamespacePolicy rejects changes to objects in Kubernetes
+// namespaces outside the role policy the caller was authorized with. Every
+// namespace set involved is checked: for an update both the stored and the
+// new one, so an object can neither be changed in nor moved out of a
+// namespace the caller does not own. Calls without a role policy in ctx come
+// from the manager itself and are not restricted.
+func verifyK8SNamespacePolicy(ctx context.Context, namespaceSets ...[]string) error {
+	rolePolicy, ok := domain.RolePolicyFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, namespaces := range namespaceSets {
+		if !rolePolicy.AllowsK8SNamespaces(namespaces) {
+			return errs.NewHTTPStatusError(http.StatusForbidden, "forbidden", fmt.Errorf("namespaces %v are outside the allowed namespaces %v", namespaces, rolePolicy.AllowedK8SNamespaces()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func requireForbidden(t *testing.T, err error) {
+	t.Helper()
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok, "expected an HTTP status error, got %v", err)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
+
+func TestNamespaceRestrictedRoleCannotTouchOtherNamespaces(t *testing.T) {
+	operatorID := bson.NewObjectID()
+	operator := &domain.Claims{UID: operatorID.Hex()}
+	ctx := domain.WithRolePolicy(context.Background(), domain.RolePolicy{K8SNamespace: "team-a"})
+
+	stored := &domain.ScheduleStrategy{
+		BaseEntity:   domain.BaseEntity{ID: bson.NewObjectID(), CreatorID: operatorID},
+		K8sNamespace: []string{"team-b"},
+	}
+	storedPSM := &domain.PodSchedulingMetrics{
+		BaseEntity:    domain.BaseEntity{ID: bson.NewObjectID(), CreatorID: operatorID},
+		K8sNamespaces: []string{"team-a"},
+	}
+	mockRepo := domain.NewMockRepository(t)
+	mockRepo.EXPECT().
+		QueryStrategies(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryStrategyOptions) {
+			opt.Result = []*domain.ScheduleStrategy{stored}
+		}).
+		Return(nil)
+	mockRepo.EXPECT().
+		QueryIntents(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryIntentOptions) {
+			opt.Result = []*domain.ScheduleIntent{{
+				BaseEntity:   domain.BaseEntity{ID: opt.IDs[0], CreatorID: operatorID},
+				K8sNamespace: "team-b",
+			}}
+		}).
+		Return(nil)
+	mockRepo.EXPECT().
+		QueryPSMs(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryPSMOptions) {
+			opt.Result = []*domain.PodSchedulingMetrics{storedPSM}
+		}).
+		Return(nil)
+	svc := &Service{Repo: mockRepo}
+
+	// Deleting and updating objects stored in a namespace the role does not
+	// own is refused, even when the update names an owned namespace.
+	requireForbidden(t, svc.DeleteScheduleStrategy(ctx, operator, stored.ID.Hex()))
+	requireForbidden(t, svc.UpdateScheduleStrategy(ctx, operator, stored.ID.Hex(), &domain.ScheduleStrategy{K8sNamespace: []string{"team-a"}}))
+	requireForbidden(t, svc.DeleteScheduleIntents(ctx, operator, []string{bson.NewObjectID().Hex()}))
+
+	// Moving an owned object out of the role's namespaces is refused too.
+	requireForbidden(t, svc.UpdatePodSchedulingMetrics(ctx, operator, storedPSM.ID.Hex(), &domain.PodSchedulingMetrics{K8sNamespaces: []string{"team-b"}}))
+	requireForbidden(t, svc.CreatePodSchedulingMetrics(ctx, operator, &domain.PodSchedulingMetrics{K8sNamespaces: []string{"team-b"}}))
+}
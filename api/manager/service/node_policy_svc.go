@@ -12,8 +12,6 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-var dmLabelSelector = domain.LabelSelector{Key: "app", Value: "decisionmaker"}
-
 // resolveNodesForPolicy returns the set of nodes that match every non-empty
 // selector type on the policy (AND semantics across selector types). When no
 // selector is set at all, no nodes match (an empty policy should not
@@ -28,6 +26,7 @@ func (svc *Service) resolveNodesForPolicy(ctx context.Context, policy *domain.No
 
 	if len(policy.NodeSelectors) > 0 || len(policy.NodeNames) > 0 {
 		nodes, err := svc.K8SAdapter.QueryNodesBySelectors(ctx, &domain.QueryNodesOptions{
+			Clusters:      policy.ClusterSelector,
 			NodeSelectors: policy.NodeSelectors,
 			NodeNames:     policy.NodeNames,
 		})
@@ -40,6 +39,7 @@ func (svc *Service) resolveNodesForPolicy(ctx context.Context, policy *domain.No
 
 	if len(policy.DRASelectors) > 0 {
 		nodes, err := svc.K8SAdapter.QueryNodesByDRA(ctx, &domain.QueryNodesByDRAOptions{
+			Clusters:     policy.ClusterSelector,
 			DRASelectors: policy.DRASelectors,
 		})
 		if err != nil {
@@ -84,6 +84,9 @@ func (svc *Service) CreateNodeSchedulingPolicy(ctx context.Context, operator *do
 	if err != nil {
 		return errors.WithMessagef(err, "invalid operator ID %s", operator.UID)
 	}
+	if err := svc.validateClusterSelector(policy.ClusterSelector); err != nil {
+		return err
+	}
 
 	nodes, err := svc.resolveNodesForPolicy(ctx, policy)
 	if err != nil {
@@ -93,7 +96,10 @@ func (svc *Service) CreateNodeSchedulingPolicy(ctx context.Context, operator *do
 		return errs.NewHTTPStatusError(http.StatusNotFound, "no nodes match the policy criteria", fmt.Errorf("no nodes found for the given selectors"))
 	}
 
+	// Keep an ID chosen by the caller, such as one from an applied bundle.
+	policyID := policy.ID
 	policy.BaseEntity = domain.NewBaseEntity(&operatorID, &operatorID)
+	policy.ID = policyID
 
 	intents := make([]*domain.NodeSchedulingIntent, 0, len(nodes))
 	for _, node := range nodes {
@@ -104,6 +110,7 @@ func (svc *Service) CreateNodeSchedulingPolicy(ctx context.Context, operator *do
 	if err := svc.Repo.InsertNodePolicyAndIntents(ctx, policy, intents); err != nil {
 		return fmt.Errorf("insert node policy and intents into repository: %w", err)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionNodeSchedulingPolicyCreate, domain.AuditResourceNodeSchedulingPolicy, policy.ID.Hex(), nil, policy)
 
 	return svc.sendNodeIntentsToDMs(ctx, intents)
 }
@@ -125,10 +132,9 @@ func (svc *Service) sendNodeIntentsToDMs(ctx context.Context, intents []*domain.
 	}
 
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabelSelector,
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return err
 	}
@@ -195,6 +201,9 @@ func (svc *Service) UpdateNodeSchedulingPolicy(ctx context.Context, operator *do
 		return errs.NewHTTPStatusError(http.StatusNotFound, "node policy not found or you don't have permission to update it", nil)
 	}
 	currentPolicy := queryOpt.Result[0]
+	if err := svc.validateClusterSelector(policy.ClusterSelector); err != nil {
+		return err
+	}
 
 	nodes, err := svc.resolveNodesForPolicy(ctx, policy)
 	if err != nil {
@@ -219,6 +228,7 @@ func (svc *Service) UpdateNodeSchedulingPolicy(ctx context.Context, operator *do
 	if err := svc.Repo.UpdateNodePolicy(ctx, policy); err != nil {
 		return fmt.Errorf("update node policy: %w", err)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionNodeSchedulingPolicyUpdate, domain.AuditResourceNodeSchedulingPolicy, policyID, currentPolicy, policy)
 	if err := svc.Repo.DeleteNodeIntentsByPolicyID(ctx, policyObjID); err != nil {
 		return fmt.Errorf("delete node intents by policy ID: %w", err)
 	}
@@ -282,6 +292,7 @@ func (svc *Service) DeleteNodeSchedulingPolicy(ctx context.Context, operator *do
 	if err := svc.Repo.DeleteNodePolicy(ctx, policyObjID); err != nil {
 		return fmt.Errorf("delete node policy: %w", err)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionNodeSchedulingPolicyDelete, domain.AuditResourceNodeSchedulingPolicy, policyID, queryOpt.Result[0], nil)
 
 	if len(intentQueryOpt.Result) > 0 {
 		svc.notifyDMsDeleteNodeIntents(ctx, intentQueryOpt.Result)
@@ -326,6 +337,9 @@ func (svc *Service) DeleteNodeSchedulingIntents(ctx context.Context, operator *d
 	if err := svc.Repo.DeleteNodeIntents(ctx, intentObjIDs); err != nil {
 		return fmt.Errorf("delete node intents: %w", err)
 	}
+	for _, intent := range queryOpt.Result {
+		svc.recordAudit(ctx, operator.UID, domain.AuditActionNodeSchedulingIntentDelete, domain.AuditResourceNodeSchedulingIntent, intent.ID.Hex(), intent, nil)
+	}
 
 	svc.notifyDMsDeleteNodeIntents(ctx, queryOpt.Result)
 	return nil
@@ -356,10 +370,9 @@ func (svc *Service) notifyDMsDeleteNodeIntents(ctx context.Context, intents []*d
 	}
 
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabelSelector,
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dmPods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dmPods, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods for node intent deletion notification")
 		return
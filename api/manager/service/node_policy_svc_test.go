@@ -105,7 +105,7 @@ func TestCreateNodeSchedulingPolicyNoMatchingNodesReturnsNotFound(t *testing.T)
 
 func TestCreateNodeSchedulingPolicyHappyPath(t *testing.T) {
 	mockK8S := domain.NewMockK8SAdapter(t)
-	mockRepo := domain.NewMockRepository(t)
+	mockRepo := newDMRegistryMockRepo(t)
 	mockDM := domain.NewMockDecisionMakerAdapter(t)
 
 	nodeA := &domain.Node{Name: "node-a"}
@@ -130,6 +130,11 @@ func TestCreateNodeSchedulingPolicyHappyPath(t *testing.T) {
 	mockRepo.EXPECT().
 		BatchUpdateNodeIntentsState(mock.Anything, mock.Anything, domain.IntentStateSent).
 		Return(nil).Once()
+	mockRepo.EXPECT().
+		CreateAuditLog(mock.Anything, mock.MatchedBy(func(log *domain.AuditLog) bool {
+			return log.Action == domain.AuditActionNodeSchedulingPolicyCreate && log.After["commandRegex"] == "sshd"
+		})).
+		Return(nil).Once()
 
 	svc := &Service{K8SAdapter: mockK8S, Repo: mockRepo, DMAdapter: mockDM}
 	claims := newTestClaims(t)
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// podEventMaxRetries is how often a failed item of the pod event queue is
+// retried, with backoff, before it is left to the periodic reconciliation.
+const podEventMaxRetries = 5
+
+type podEventKeyKind int
+
+const (
+	// podEventKeyPod looks up the strategies selecting a pod.
+	podEventKeyPod podEventKeyKind = iota
+	// podEventKeyStrategy refreshes the intents of a strategy.
+	podEventKeyStrategy
+	// podEventKeyNode resyncs the intents of a node to its decision maker.
+	podEventKeyNode
+)
+
+type podEventKey struct {
+	kind podEventKeyKind
+	name string
+}
+
+// podEventQueue is the rate-limited workqueue pod events are reconciled
+// through. A pod event queues its pod, which queues the strategies selecting
+// it, which queue the nodes whose intents changed. The workqueue collapses
+// repeated keys, so a burst of events for the same pods or strategies is
+// reconciled once.
+type podEventQueue struct {
+	queue workqueue.TypedRateLimitingInterface[podEventKey]
+
+	mu sync.Mutex
+	// pods holds what the events of every queued pod reported. All its
+	// label sets are kept, so that strategies it was relabelled out of are
+	// refreshed too.
+	pods map[string]*queuedPod
+}
+
+type queuedPod struct {
+	cluster   string
+	namespace string
+	labelSets []map[string]string
+}
+
+func newPodEventQueue() *podEventQueue {
+	return &podEventQueue{
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[podEventKey](),
+			workqueue.TypedRateLimitingQueueConfig[podEventKey]{Name: "pod-events"},
+		),
+		pods: make(map[string]*queuedPod),
+	}
+}
+
+func (q *podEventQueue) addPod(event domain.PodEvent) {
+	pod := event.Pod
+	name := pod.Cluster + "/" + pod.K8SNamespace + "/" + pod.Name
+
+	q.mu.Lock()
+	queued, ok := q.pods[name]
+	if !ok {
+		queued = &queuedPod{cluster: pod.Cluster, namespace: pod.K8SNamespace}
+		q.pods[name] = queued
+	}
+	queued.labelSets = append(queued.labelSets, pod.Labels)
+	if event.OldLabels != nil {
+		queued.labelSets = append(queued.labelSets, event.OldLabels)
+	}
+	q.mu.Unlock()
+
+	q.queue.Add(podEventKey{kind: podEventKeyPod, name: name})
+}
+
+// takePod removes and returns what was reported for a queued pod.
+func (q *podEventQueue) takePod(name string) *queuedPod {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queued := q.pods[name]
+	delete(q.pods, name)
+	return queued
+}
+
+// restorePod puts back what takePod returned, for a retry.
+func (q *podEventQueue) restorePod(name string, queued *queuedPod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if current, ok := q.pods[name]; ok {
+		current.labelSets = append(current.labelSets, queued.labelSets...)
+		return
+	}
+	q.pods[name] = queued
+}
+
+// EnqueuePodEvent queues the reconciliation of the strategies a pod event
+// affects: those selecting the pod before or after the change. It does not
+// block, so it can be called from the pod watcher.
+func (svc *Service) EnqueuePodEvent(event domain.PodEvent) {
+	if svc.podEvents == nil || event.Pod == nil {
+		return
+	}
+	svc.podEvents.addPod(event)
+}
+
+// RunPodEventReconciler reconciles the queued pod events until ctx is done,
+// so that pods get their intents as soon as they are scheduled instead of on
+// the next periodic reconciliation. Items that keep failing are left to the
+// periodic reconciliation.
+func (svc *Service) RunPodEventReconciler(ctx context.Context) {
+	if svc.podEvents == nil {
+		return
+	}
+	queue := svc.podEvents.queue
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+	for svc.processNextPodEvent(ctx) {
+	}
+}
+
+func (svc *Service) processNextPodEvent(ctx context.Context) bool {
+	queue := svc.podEvents.queue
+	key, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+	defer queue.Done(key)
+
+	err := svc.reconcilePodEventKey(ctx, key)
+	if err == nil {
+		queue.Forget(key)
+		return true
+	}
+	if queue.NumRequeues(key) < podEventMaxRetries {
+		logger.Logger(ctx).Warn().Err(err).Msgf("failed to reconcile pod event item %s, retrying", key.name)
+		queue.AddRateLimited(key)
+		return true
+	}
+	queue.Forget(key)
+	logger.Logger(ctx).Warn().Err(err).Msgf("giving up on pod event item %s until the next periodic reconciliation", key.name)
+	return true
+}
+
+func (svc *Service) reconcilePodEventKey(ctx context.Context, key podEventKey) error {
+	queue := svc.podEvents.queue
+	switch key.kind {
+	case podEventKeyPod:
+		queued := svc.podEvents.takePod(key.name)
+		if queued == nil {
+			return nil
+		}
+		strategyOpt := &domain.QueryStrategyOptions{}
+		if err := svc.Repo.QueryStrategies(ctx, strategyOpt); err != nil {
+			svc.podEvents.restorePod(key.name, queued)
+			return fmt.Errorf("query strategies: %w", err)
+		}
+		for _, strategy := range strategyOpt.Result {
+			for _, labels := range queued.labelSets {
+				if strategySelectsPod(strategy, queued.cluster, queued.namespace, labels) {
+					queue.Add(podEventKey{kind: podEventKeyStrategy, name: strategy.ID.Hex()})
+					break
+				}
+			}
+		}
+		return nil
+
+	case podEventKeyStrategy:
+		strategyID, err := bson.ObjectIDFromHex(key.name)
+		if err != nil {
+			return nil
+		}
+		strategyOpt := &domain.QueryStrategyOptions{IDs: []bson.ObjectID{strategyID}}
+		if err := svc.Repo.QueryStrategies(ctx, strategyOpt); err != nil {
+			return fmt.Errorf("query strategy %s: %w", key.name, err)
+		}
+		if len(strategyOpt.Result) == 0 {
+			return nil
+		}
+		// Nodes changed before a failed write are resynced all the same,
+		// the retry no longer sees their intents as stale.
+		changedNodes, err := svc.refreshStrategyIntents(ctx, strategyOpt.Result[0], time.Now())
+		for nodeID := range changedNodes {
+			if nodeID != "" {
+				queue.Add(podEventKey{kind: podEventKeyNode, name: nodeID})
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("refresh intents of strategy %s: %w", key.name, err)
+		}
+		return nil
+
+	case podEventKeyNode:
+		return svc.resyncIntentsToNodes(ctx, []string{key.name})
+	}
+	return nil
+}
+
+// strategySelectsPod reports whether strategy selects a pod with labels in
+// namespace of cluster, the way the K8S adapter matches pods for it.
+func strategySelectsPod(strategy *domain.ScheduleStrategy, cluster, namespace string, labels map[string]string) bool {
+	if len(strategy.ClusterSelector) > 0 && !slices.Contains(strategy.ClusterSelector, cluster) {
+		return false
+	}
+	if len(strategy.K8sNamespace) > 0 && !slices.Contains(strategy.K8sNamespace, namespace) {
+		return false
+	}
+	for _, selector := range strategy.LabelSelectors {
+		if selector.Key == "" {
+			continue
+		}
+		value, ok := labels[selector.Key]
+		if !ok || (selector.Value != "" && value != selector.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestStrategySelectsPod(t *testing.T) {
+	strategy := &domain.ScheduleStrategy{
+		ClusterSelector: []string{"east"},
+		K8sNamespace:    []string{"default"},
+		LabelSelectors: []domain.LabelSelector{
+			{Key: "app", Value: "web"},
+			{Key: "tier"},
+		},
+	}
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+
+	assert.True(t, strategySelectsPod(strategy, "east", "default", labels))
+	assert.False(t, strategySelectsPod(strategy, "west", "default", labels))
+	assert.False(t, strategySelectsPod(strategy, "east", "kube-system", labels))
+	assert.False(t, strategySelectsPod(strategy, "east", "default", map[string]string{"app": "db", "tier": "backend"}))
+	assert.False(t, strategySelectsPod(strategy, "east", "default", map[string]string{"app": "web"}))
+	assert.True(t, strategySelectsPod(&domain.ScheduleStrategy{}, "west", "kube-system", nil))
+}
+
+func TestPodEventReconcilesSelectingStrategies(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockRepo := newDMRegistryMockRepo(t)
+
+	web := &domain.ScheduleStrategy{
+		BaseEntity:     domain.BaseEntity{ID: bson.NewObjectID()},
+		LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "web"}},
+	}
+	db := &domain.ScheduleStrategy{
+		BaseEntity:     domain.BaseEntity{ID: bson.NewObjectID()},
+		LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "db"}},
+	}
+	pod := &domain.Pod{
+		Name:         "web-1",
+		K8SNamespace: "default",
+		Labels:       map[string]string{"app": "web"},
+		PodID:        "pod-web-1",
+		NodeID:       "node-a",
+	}
+
+	mockRepo.EXPECT().
+		QueryStrategies(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryStrategyOptions) {
+			if len(opt.IDs) == 0 {
+				opt.Result = []*domain.ScheduleStrategy{web, db}
+				return
+			}
+			// Only the strategy selecting the pod is refreshed.
+			require.Equal(t, []bson.ObjectID{web.ID}, opt.IDs)
+			opt.Result = []*domain.ScheduleStrategy{web}
+		}).
+		Return(nil).Twice()
+	mockK8S.EXPECT().
+		QueryPods(mock.Anything, mock.Anything).
+		Return([]*domain.Pod{pod}, nil).Once()
+	mockRepo.EXPECT().
+		QueryIntents(mock.Anything, mock.Anything).
+		Return(nil).Once()
+	mockRepo.EXPECT().
+		InsertIntents(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, intents []*domain.ScheduleIntent) {
+			require.Len(t, intents, 1)
+			assert.Equal(t, "pod-web-1", intents[0].PodID)
+			assert.Equal(t, "node-a", intents[0].NodeID)
+		}).
+		Return(nil).Once()
+
+	resynced := make(chan []string, 1)
+	mockK8S.EXPECT().
+		QueryDecisionMakerPods(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryDecisionMakerPodsOptions) {
+			resynced <- opt.NodeIDs
+		}).
+		Return(nil, nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, Repo: mockRepo, podEvents: newPodEventQueue()}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.RunPodEventReconciler(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	svc.EnqueuePodEvent(domain.PodEvent{Type: domain.PodEventAdded, Pod: pod})
+
+	select {
+	case nodeIDs := <-resynced:
+		assert.Equal(t, []string{"node-a"}, nodeIDs)
+	case <-time.After(time.Second):
+		t.Fatal("the node of the new pod was not resynced within a second")
+	}
+}
+
+func TestPodEventRefreshesStrategyThePodWasRelabelledOutOf(t *testing.T) {
+	queue := newPodEventQueue()
+	defer queue.queue.ShutDown()
+
+	queue.addPod(domain.PodEvent{
+		Type:      domain.PodEventUpdated,
+		Pod:       &domain.Pod{Name: "web-1", K8SNamespace: "default", Labels: map[string]string{"app": "db"}},
+		OldLabels: map[string]string{"app": "web"},
+	})
+
+	queued := queue.takePod("/default/web-1")
+	require.NotNil(t, queued)
+	web := &domain.ScheduleStrategy{LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "web"}}}
+	selected := false
+	for _, labels := range queued.labelSets {
+		selected = selected || strategySelectsPod(web, queued.cluster, queued.namespace, labels)
+	}
+	assert.True(t, selected)
+	assert.Nil(t, queue.takePod("/default/web-1"))
+}
+
+func TestPodEventRetriesFailedIntentWrites(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockRepo := domain.NewMockRepository(t)
+
+	web := &domain.ScheduleStrategy{
+		BaseEntity:     domain.BaseEntity{ID: bson.NewObjectID()},
+		LabelSelectors: []domain.LabelSelector{{Key: "app", Value: "web"}},
+	}
+	mockRepo.EXPECT().
+		QueryStrategies(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryStrategyOptions) {
+			opt.Result = []*domain.ScheduleStrategy{web}
+		}).
+		Return(nil).Once()
+	mockK8S.EXPECT().
+		QueryPods(mock.Anything, mock.Anything).
+		Return(nil, nil).Once()
+	mockRepo.EXPECT().
+		QueryIntents(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, opt *domain.QueryIntentOptions) {
+			opt.Result = []*domain.ScheduleIntent{{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, PodID: "pod-web-1", NodeID: "node-a"}}
+		}).
+		Return(nil).Once()
+	// The decision makers are not told to drop intents that are still
+	// stored, so QueryDecisionMakerPods is not expected.
+	mockRepo.EXPECT().
+		DeleteIntents(mock.Anything, mock.Anything).
+		Return(errors.New("mongo is down")).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, Repo: mockRepo, podEvents: newPodEventQueue()}
+	defer svc.podEvents.queue.ShutDown()
+	key := podEventKey{kind: podEventKeyStrategy, name: web.ID.Hex()}
+	svc.podEvents.queue.Add(key)
+
+	require.True(t, svc.processNextPodEvent(context.Background()))
+	assert.Equal(t, 1, svc.podEvents.queue.NumRequeues(key))
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+)
+
+const (
+	// podSchedulingHistoryDefaultRange is queried when no from is given.
+	podSchedulingHistoryDefaultRange = time.Hour
+	// podSchedulingHistoryMaxPoints bounds the points of one history query.
+	podSchedulingHistoryMaxPoints = 2000
+)
+
+type podSchedulingHistoryRepository interface {
+	UpsertPodSchedulingMetricBuckets(ctx context.Context, buckets []*domain.PodSchedulingMetricBucket) error
+	QueryPodSchedulingMetricBuckets(ctx context.Context, opt *domain.QueryPodSchedulingMetricBucketOptions) error
+}
+
+func (svc *Service) getPodSchedulingHistoryRepo() (podSchedulingHistoryRepository, error) {
+	repo, ok := svc.Repo.(podSchedulingHistoryRepository)
+	if !ok {
+		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "pod scheduling metric history is not enabled", nil)
+	}
+	return repo, nil
+}
+
+// RecordPodSchedulingMetricHistory stores the scraped counters in the current
+// bucket of every history tier.
+func (svc *Service) RecordPodSchedulingMetricHistory(ctx context.Context, values []*domain.PodSchedulingMetricValue) error {
+	repo, err := svc.getPodSchedulingHistoryRepo()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	buckets := make([]*domain.PodSchedulingMetricBucket, 0, len(values)*len(domain.PodSchedulingMetricHistoryTiers))
+	for _, tier := range domain.PodSchedulingMetricHistoryTiers {
+		start := now.Truncate(tier.Step)
+		for _, value := range values {
+			if value == nil || value.Namespace == "" || value.PodName == "" {
+				continue
+			}
+			buckets = append(buckets, &domain.PodSchedulingMetricBucket{
+				Namespace:  value.Namespace,
+				PodName:    value.PodName,
+				NodeID:     value.NodeID,
+				StepMillis: tier.Step.Milliseconds(),
+				Timestamp:  start.UnixMilli(),
+				ObservedAt: now.UnixMilli(),
+				Counters:   value.Counters(),
+				ExpireAt:   start.Add(tier.Step + tier.Retention),
+			})
+		}
+	}
+	return repo.UpsertPodSchedulingMetricBuckets(ctx, buckets)
+}
+
+// QueryPodSchedulingMetricHistory returns how much the pod's counters grew in
+// every step between opt.From and opt.To. It reads the finest tier that still
+// retains opt.From, or the coarsest one whose step fits a requested step.
+func (svc *Service) QueryPodSchedulingMetricHistory(ctx context.Context, opt *domain.QueryPodSchedulingMetricHistoryOptions) error {
+	repo, err := svc.getPodSchedulingHistoryRepo()
+	if err != nil {
+		return err
+	}
+	if opt.Namespace == "" || opt.PodName == "" {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "namespace and pod are required", nil)
+	}
+	now := time.Now().UnixMilli()
+	if opt.To <= 0 {
+		opt.To = now
+	}
+	if opt.From <= 0 {
+		opt.From = opt.To - podSchedulingHistoryDefaultRange.Milliseconds()
+	}
+	if opt.From >= opt.To {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "from must be before to", nil)
+	}
+	if opt.Step < 0 {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "step must be positive", nil)
+	}
+
+	tier := selectPodSchedulingHistoryTier(now, opt.From, opt.Step)
+	rangeMillis := opt.To - opt.From
+	stepMillis := opt.Step.Milliseconds()
+	if stepMillis == 0 {
+		stepMillis = max(tier.Step.Milliseconds(), (rangeMillis+podSchedulingHistoryMaxPoints-1)/podSchedulingHistoryMaxPoints)
+	}
+	// Steps are whole multiples of the tier step so every bucket falls into
+	// exactly one step.
+	tierMillis := tier.Step.Milliseconds()
+	stepMillis = (stepMillis + tierMillis - 1) / tierMillis * tierMillis
+	if rangeMillis/stepMillis > podSchedulingHistoryMaxPoints {
+		return errs.NewHTTPStatusError(http.StatusBadRequest,
+			fmt.Sprintf("step is too small for the range, at most %d points are returned", podSchedulingHistoryMaxPoints), nil)
+	}
+	opt.Step = time.Duration(stepMillis) * time.Millisecond
+
+	// The bucket before From is the baseline of the first increase.
+	bucketOpt := &domain.QueryPodSchedulingMetricBucketOptions{
+		Namespace:  opt.Namespace,
+		PodName:    opt.PodName,
+		StepMillis: tierMillis,
+		From:       opt.From - tierMillis,
+		To:         opt.To,
+	}
+	if err := repo.QueryPodSchedulingMetricBuckets(ctx, bucketOpt); err != nil {
+		return err
+	}
+	opt.Result = podSchedulingHistoryPoints(bucketOpt.Result, opt.From, stepMillis)
+	return nil
+}
+
+func selectPodSchedulingHistoryTier(now, from int64, step time.Duration) domain.PodSchedulingMetricHistoryTier {
+	tiers := domain.PodSchedulingMetricHistoryTiers
+	var covering []domain.PodSchedulingMetricHistoryTier
+	for _, tier := range tiers {
+		if from >= now-tier.Retention.Milliseconds() {
+			covering = append(covering, tier)
+		}
+	}
+	if len(covering) == 0 {
+		return tiers[len(tiers)-1]
+	}
+	selected := covering[0]
+	for _, tier := range covering[1:] {
+		if step > 0 && tier.Step <= step {
+			selected = tier
+		}
+	}
+	return selected
+}
+
+// podSchedulingHistoryPoints turns the cumulative buckets of every node into
+// increases and sums them per step. Steps without observations are omitted.
+func podSchedulingHistoryPoints(buckets []*domain.PodSchedulingMetricBucket, from, stepMillis int64) []*domain.PodSchedulingMetricHistoryPoint {
+	byNode := map[string][]*domain.PodSchedulingMetricBucket{}
+	for _, bucket := range buckets {
+		byNode[bucket.NodeID] = append(byNode[bucket.NodeID], bucket)
+	}
+
+	points := map[int64]*domain.PodSchedulingMetricHistoryPoint{}
+	for _, nodeBuckets := range byNode {
+		sort.Slice(nodeBuckets, func(i, j int) bool { return nodeBuckets[i].Timestamp < nodeBuckets[j].Timestamp })
+		for i := 1; i < len(nodeBuckets); i++ {
+			bucket := nodeBuckets[i]
+			if bucket.Timestamp < from {
+				continue
+			}
+			start := bucket.Timestamp - bucket.Timestamp%stepMillis
+			point, ok := points[start]
+			if !ok {
+				point = &domain.PodSchedulingMetricHistoryPoint{Timestamp: start}
+				points[start] = point
+			}
+			point.Add(bucket.Counters.IncreaseSince(nodeBuckets[i-1].Counters))
+		}
+	}
+
+	result := make([]*domain.PodSchedulingMetricHistoryPoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, point)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp < result[j].Timestamp })
+	return result
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePodSchedulingHistoryRepo struct {
+	domain.Repository
+	buckets []*domain.PodSchedulingMetricBucket
+}
+
+func (r *fakePodSchedulingHistoryRepo) UpsertPodSchedulingMetricBuckets(_ context.Context, buckets []*domain.PodSchedulingMetricBucket) error {
+	r.buckets = append(r.buckets, buckets...)
+	return nil
+}
+
+func (r *fakePodSchedulingHistoryRepo) QueryPodSchedulingMetricBuckets(_ context.Context, opt *domain.QueryPodSchedulingMetricBucketOptions) error {
+	for _, bucket := range r.buckets {
+		if bucket.Namespace != opt.Namespace || bucket.PodName != opt.PodName || bucket.StepMillis != opt.StepMillis {
+			continue
+		}
+		if bucket.Timestamp < opt.From || bucket.Timestamp > opt.To {
+			continue
+		}
+		opt.Result = append(opt.Result, bucket)
+	}
+	return nil
+}
+
+func historyBucket(step time.Duration, ts time.Time, node string, cpuTimeNs, runCount uint64) *domain.PodSchedulingMetricBucket {
+	return &domain.PodSchedulingMetricBucket{
+		Namespace:  "team-a",
+		PodName:    "web",
+		NodeID:     node,
+		StepMillis: step.Milliseconds(),
+		Timestamp:  ts.UnixMilli(),
+		Counters:   domain.PodSchedulingMetricCounters{CPUTimeNs: cpuTimeNs, RunCount: runCount},
+	}
+}
+
+func TestRecordPodSchedulingMetricHistoryFillsEveryTier(t *testing.T) {
+	repo := &fakePodSchedulingHistoryRepo{}
+	svc := &Service{Repo: repo}
+
+	err := svc.RecordPodSchedulingMetricHistory(context.Background(), []*domain.PodSchedulingMetricValue{
+		{Namespace: "team-a", PodName: "web", NodeID: "node-a", CPUTimeNs: 100, RunCount: 4},
+		{Namespace: "", PodName: "nameless"},
+	})
+	require.NoError(t, err)
+	require.Len(t, repo.buckets, len(domain.PodSchedulingMetricHistoryTiers))
+	for i, tier := range domain.PodSchedulingMetricHistoryTiers {
+		bucket := repo.buckets[i]
+		assert.Equal(t, tier.Step.Milliseconds(), bucket.StepMillis)
+		assert.Zero(t, bucket.Timestamp%bucket.StepMillis, "buckets start on a step boundary")
+		assert.Equal(t, uint64(100), bucket.Counters.CPUTimeNs)
+		assert.Equal(t, time.UnixMilli(bucket.Timestamp).Add(tier.Step+tier.Retention), bucket.ExpireAt)
+	}
+}
+
+func TestQueryPodSchedulingMetricHistorySumsIncreasesPerStep(t *testing.T) {
+	step := 10 * time.Second
+	base := time.Now().Add(-10 * time.Minute).Truncate(time.Minute)
+	repo := &fakePodSchedulingHistoryRepo{buckets: []*domain.PodSchedulingMetricBucket{
+		historyBucket(step, base.Add(-step), "node-a", 1000, 10), // baseline before from
+		historyBucket(step, base, "node-a", 1500, 15),
+		historyBucket(step, base.Add(step), "node-a", 2500, 25),
+		historyBucket(step, base.Add(2*step), "node-a", 300, 3), // counters reset
+		historyBucket(step, base, "node-b", 50, 1),
+		historyBucket(step, base.Add(step), "node-b", 80, 2),
+	}}
+	svc := &Service{Repo: repo}
+
+	opt := &domain.QueryPodSchedulingMetricHistoryOptions{
+		Namespace: "team-a",
+		PodName:   "web",
+		From:      base.UnixMilli(),
+		To:        base.Add(time.Minute).UnixMilli(),
+		Step:      20 * time.Second,
+	}
+	require.NoError(t, svc.QueryPodSchedulingMetricHistory(context.Background(), opt))
+	assert.Equal(t, 20*time.Second, opt.Step)
+	require.Len(t, opt.Result, 2)
+
+	assert.Equal(t, base.UnixMilli(), opt.Result[0].Timestamp)
+	assert.Equal(t, uint64(500+1000+30), opt.Result[0].CPUTimeNs, "node-b has no baseline for its first bucket")
+	assert.Equal(t, uint64(5+10+1), opt.Result[0].RunCount)
+	assert.Equal(t, base.Add(20*time.Second).UnixMilli(), opt.Result[1].Timestamp)
+	assert.Equal(t, uint64(300), opt.Result[1].CPUTimeNs, "a reset counts its new value as the increase")
+}
+
+func TestQueryPodSchedulingMetricHistorySelectsTier(t *testing.T) {
+	now := time.Now()
+	fine, coarse := domain.PodSchedulingMetricHistoryTiers[0], domain.PodSchedulingMetricHistoryTiers[1]
+
+	assert.Equal(t, fine, selectPodSchedulingHistoryTier(now.UnixMilli(), now.Add(-time.Hour).UnixMilli(), 0))
+	assert.Equal(t, coarse, selectPodSchedulingHistoryTier(now.UnixMilli(), now.Add(-time.Hour).UnixMilli(), time.Hour),
+		"a coarse step reads the coarse tier")
+	assert.Equal(t, coarse, selectPodSchedulingHistoryTier(now.UnixMilli(), now.Add(-48*time.Hour).UnixMilli(), 0),
+		"ranges past the fine retention read the coarse tier")
+
+	svc := &Service{Repo: &fakePodSchedulingHistoryRepo{}}
+	opt := &domain.QueryPodSchedulingMetricHistoryOptions{
+		Namespace: "team-a",
+		PodName:   "web",
+		From:      now.Add(-7 * 24 * time.Hour).UnixMilli(),
+	}
+	require.NoError(t, svc.QueryPodSchedulingMetricHistory(context.Background(), opt))
+	assert.Equal(t, 10*time.Minute, opt.Step, "the default step fits the range in the point limit")
+
+	opt = &domain.QueryPodSchedulingMetricHistoryOptions{
+		Namespace: "team-a",
+		PodName:   "web",
+		From:      now.Add(-20 * time.Hour).UnixMilli(),
+		Step:      10 * time.Second,
+	}
+	err := svc.QueryPodSchedulingMetricHistory(context.Background(), opt)
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, 400, httpErr.StatusCode)
+}
@@ -14,14 +14,9 @@ func (svc *Service) ListPodSchedulingMetricValues(ctx context.Context) (*domain.
 		return nil, domain.ErrNoClient
 	}
 
-	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: domain.LabelSelector{
-			Key:   "app",
-			Value: "decisionmaker",
-		},
-	}
+	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{}
 
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return nil, fmt.Errorf("query decision maker pods: %w", err)
 	}
@@ -54,6 +49,7 @@ func (svc *Service) ListPodSchedulingMetricValues(ctx context.Context) (*domain.
 			if item.NodeID == "" {
 				item.NodeID = dm.NodeID
 			}
+			item.Cluster = dm.Cluster
 
 			key := item.Namespace + "/" + item.PodName + "/" + item.NodeID
 			existing, ok := aggregated[key]
@@ -62,6 +58,7 @@ func (svc *Service) ListPodSchedulingMetricValues(ctx context.Context) (*domain.
 					Namespace:              item.Namespace,
 					PodName:                item.PodName,
 					NodeID:                 item.NodeID,
+					Cluster:                item.Cluster,
 					VoluntaryCtxSwitches:   item.VoluntaryCtxSwitches,
 					InvoluntaryCtxSwitches: item.InvoluntaryCtxSwitches,
 					CPUTimeNs:              item.CPUTimeNs,
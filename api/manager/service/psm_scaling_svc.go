@@ -0,0 +1,220 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+)
+
+const (
+	// psmScaledObjectPrefix prefixes the name of generated ScaledObjects; the
+	// PodSchedulingMetrics ID follows.
+	psmScaledObjectPrefix = "gthulhu-psm-"
+	// psmMetricPrefix is shared by the pod metrics the decision makers export.
+	psmMetricPrefix = "gthulhu_pod_"
+	// psmRateWindow is the range the trigger query takes the rate of counters over.
+	psmRateWindow = "2m"
+
+	defaultPSMPollingInterval = 30
+	defaultPSMMinReplicaCount = 1
+	defaultPSMMaxReplicaCount = 10
+	defaultPSMCooldownPeriod  = 300
+)
+
+type psmScalingRepository interface {
+	QueryPSMScaledObjects(ctx context.Context, opt *domain.QueryPSMScaledObjectOptions) error
+	ApplyPSMScaledObject(ctx context.Context, so *domain.PSMScaledObject) error
+	DeletePSMScaledObject(ctx context.Context, namespace, name string) error
+	UpdatePSMScalingStatus(ctx context.Context, psmID string, status *domain.PSMScalingStatus) error
+}
+
+func (svc *Service) getPSMScalingRepo() (psmScalingRepository, error) {
+	repo, ok := svc.Repo.(psmScalingRepository)
+	if !ok {
+		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "KEDA scaling is not enabled", nil)
+	}
+	return repo, nil
+}
+
+// ReconcilePSMScaledObjects creates, updates and deletes the KEDA ScaledObjects
+// generated from the scaling hints of every PodSchedulingMetrics, and reports
+// the outcome in the scaling status of each. ScaledObjects the manager did not
+// generate are never touched.
+func (svc *Service) ReconcilePSMScaledObjects(ctx context.Context) error {
+	if !svc.kedaCfg.Enabled {
+		return nil
+	}
+	repo, err := svc.getPSMScalingRepo()
+	if err != nil {
+		return err
+	}
+	psmOpt := &domain.QueryPSMOptions{}
+	if err := svc.Repo.QueryPSMs(ctx, psmOpt); err != nil {
+		return err
+	}
+	soOpt := &domain.QueryPSMScaledObjectOptions{}
+	if err := repo.QueryPSMScaledObjects(ctx, soOpt); err != nil {
+		return err
+	}
+	stale := make(map[string]*domain.PSMScaledObject, len(soOpt.Result))
+	for _, so := range soOpt.Result {
+		stale[so.Key()] = so
+	}
+
+	var reconcileErrs []error
+	for _, psm := range psmOpt.Result {
+		psmID := psm.ID.Hex()
+		desired, buildErr := svc.desiredPSMScaledObjects(psm)
+		status := &domain.PSMScalingStatus{Ready: buildErr == nil && len(desired) > 0}
+		var messages []string
+		if buildErr != nil {
+			messages = append(messages, buildErr.Error())
+		}
+		for _, so := range desired {
+			current, exists := stale[so.Key()]
+			delete(stale, so.Key())
+			if !exists || !reflect.DeepEqual(current, so) {
+				if err := repo.ApplyPSMScaledObject(ctx, so); err != nil {
+					status.Ready = false
+					messages = append(messages, err.Error())
+					reconcileErrs = append(reconcileErrs, err)
+					continue
+				}
+			}
+			status.ScaledObjects = append(status.ScaledObjects, so.Key())
+		}
+		status.Message = strings.Join(messages, "; ")
+
+		if psm.Scaling == nil && psm.ScalingStatus == nil {
+			continue
+		}
+		if psmScalingStatusEqual(psm.ScalingStatus, status) {
+			continue
+		}
+		status.LastTransitionTime = time.Now().UnixMilli()
+		if err := repo.UpdatePSMScalingStatus(ctx, psmID, status); err != nil {
+			reconcileErrs = append(reconcileErrs, err)
+		}
+	}
+
+	// Whatever is left belongs to deleted PodSchedulingMetrics, disabled
+	// scaling hints or namespaces that were removed from the selector.
+	for _, so := range stale {
+		if err := repo.DeletePSMScaledObject(ctx, so.Namespace, so.Name); err != nil {
+			reconcileErrs = append(reconcileErrs, err)
+			continue
+		}
+		logger.Logger(ctx).Info().Msgf("deleted ScaledObject %s of PodSchedulingMetrics %s", so.Key(), so.PSMID)
+	}
+	return errors.Join(reconcileErrs...)
+}
+
+// reconcilePSMScaledObjectsAfterChange applies a PodSchedulingMetrics change
+// right away instead of waiting for the next periodic reconcile.
+func (svc *Service) reconcilePSMScaledObjectsAfterChange(ctx context.Context) {
+	if err := svc.ReconcilePSMScaledObjects(ctx); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msg("reconcile ScaledObjects after PodSchedulingMetrics change failed")
+	}
+}
+
+// desiredPSMScaledObjects returns one ScaledObject per namespace of the
+// PodSchedulingMetrics, since a ScaledObject has to live next to the workload
+// it scales. Nothing is generated unless both collection and scaling are
+// enabled.
+func (svc *Service) desiredPSMScaledObjects(psm *domain.PodSchedulingMetrics) ([]*domain.PSMScaledObject, error) {
+	scaling := psm.Scaling
+	if scaling == nil || !scaling.Enabled || !psm.Enabled {
+		return nil, nil
+	}
+	if svc.kedaCfg.PrometheusAddress == "" {
+		return nil, errors.New("keda prometheus_address is not configured")
+	}
+	if scaling.ScaleTargetRef == nil || scaling.ScaleTargetRef.Name == "" {
+		return nil, errors.New("scaleTargetRef.name is required")
+	}
+	if !strings.HasPrefix(scaling.MetricName, psmMetricPrefix) {
+		return nil, fmt.Errorf("metricName must be one of the %s* metrics, got %q", psmMetricPrefix, scaling.MetricName)
+	}
+	if threshold, err := strconv.ParseFloat(scaling.TargetValue, 64); err != nil || threshold <= 0 {
+		return nil, fmt.Errorf("targetValue must be a positive number, got %q", scaling.TargetValue)
+	}
+	if len(psm.K8sNamespaces) == 0 {
+		return nil, errors.New("k8sNamespaces is required to place ScaledObjects next to scaleTargetRef")
+	}
+
+	target := domain.PSMScaleTargetRef{
+		APIVersion: scaling.ScaleTargetRef.APIVersion,
+		Kind:       scaling.ScaleTargetRef.Kind,
+		Name:       scaling.ScaleTargetRef.Name,
+	}
+	if target.APIVersion == "" {
+		target.APIVersion = "apps/v1"
+	}
+	if target.Kind == "" {
+		target.Kind = "Deployment"
+	}
+	// The CRD defaults do not apply to values the API writes explicitly. A
+	// zero maximum means unset, while minReplicaCount 0 scales to zero.
+	minReplicas := int32(defaultPSMMinReplicaCount)
+	if scaling.MinReplicaCount != nil {
+		minReplicas = *scaling.MinReplicaCount
+	}
+	maxReplicas := cmp.Or(scaling.MaxReplicaCount, defaultPSMMaxReplicaCount)
+	if minReplicas < 0 || maxReplicas < minReplicas {
+		return nil, fmt.Errorf("replica counts must satisfy 0 <= minReplicaCount <= maxReplicaCount, got %d and %d", minReplicas, maxReplicas)
+	}
+
+	namespaces := slices.Clone(psm.K8sNamespaces)
+	slices.Sort(namespaces)
+	namespaces = slices.Compact(namespaces)
+	result := make([]*domain.PSMScaledObject, 0, len(namespaces))
+	for _, ns := range namespaces {
+		result = append(result, &domain.PSMScaledObject{
+			Namespace:       ns,
+			Name:            psmScaledObjectPrefix + psm.ID.Hex(),
+			PSMID:           psm.ID.Hex(),
+			ScaleTargetRef:  target,
+			PollingInterval: cmp.Or(svc.kedaCfg.PollingIntervalSeconds, defaultPSMPollingInterval),
+			CooldownPeriod:  cmp.Or(scaling.CooldownPeriod, defaultPSMCooldownPeriod),
+			MinReplicaCount: minReplicas,
+			MaxReplicaCount: maxReplicas,
+			Trigger: domain.PSMPrometheusTrigger{
+				ServerAddress: svc.kedaCfg.PrometheusAddress,
+				Query:         psmScalingQuery(scaling.MetricName, ns, target.Name),
+				Threshold:     scaling.TargetValue,
+			},
+		})
+	}
+	return result, nil
+}
+
+// psmScalingQuery sums the metric over the pods of the scale target, which
+// are named after the workload. Counters are turned into per-second rates.
+func psmScalingQuery(metricName, namespace, workload string) string {
+	// Workload names may contain dots, which are escaped once for the regex
+	// and once more for the PromQL string.
+	podRegex := strings.ReplaceAll(workload, ".", `\\.`) + "-.*"
+	selector := fmt.Sprintf(`%s{namespace="%s",pod_name=~"%s"}`, metricName, namespace, podRegex)
+	if strings.HasSuffix(metricName, "_total") {
+		return fmt.Sprintf("sum(rate(%s[%s]))", selector, psmRateWindow)
+	}
+	return fmt.Sprintf("sum(%s)", selector)
+}
+
+func psmScalingStatusEqual(a, b *domain.PSMScalingStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Ready == b.Ready && a.Message == b.Message && slices.Equal(a.ScaledObjects, b.ScaledObjects)
+}
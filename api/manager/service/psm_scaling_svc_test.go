@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gthulhu/api/config"
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakePSMScalingRepo struct {
+	domain.Repository
+	psms          []*domain.PodSchedulingMetrics
+	scaledObjects map[string]*domain.PSMScaledObject
+	applied       []string
+	statuses      map[string]*domain.PSMScalingStatus
+}
+
+func newFakePSMScalingRepo(psms ...*domain.PodSchedulingMetrics) *fakePSMScalingRepo {
+	return &fakePSMScalingRepo{
+		psms:          psms,
+		scaledObjects: map[string]*domain.PSMScaledObject{},
+		statuses:      map[string]*domain.PSMScalingStatus{},
+	}
+}
+
+func (r *fakePSMScalingRepo) QueryPSMs(_ context.Context, opt *domain.QueryPSMOptions) error {
+	opt.Result = append(opt.Result, r.psms...)
+	return nil
+}
+
+func (r *fakePSMScalingRepo) QueryPSMScaledObjects(_ context.Context, opt *domain.QueryPSMScaledObjectOptions) error {
+	for _, so := range r.scaledObjects {
+		copied := *so
+		opt.Result = append(opt.Result, &copied)
+	}
+	return nil
+}
+
+func (r *fakePSMScalingRepo) ApplyPSMScaledObject(_ context.Context, so *domain.PSMScaledObject) error {
+	r.applied = append(r.applied, so.Key())
+	r.scaledObjects[so.Key()] = so
+	return nil
+}
+
+func (r *fakePSMScalingRepo) DeletePSMScaledObject(_ context.Context, namespace, name string) error {
+	delete(r.scaledObjects, namespace+"/"+name)
+	return nil
+}
+
+func (r *fakePSMScalingRepo) UpdatePSMScalingStatus(_ context.Context, psmID string, status *domain.PSMScalingStatus) error {
+	r.statuses[psmID] = status
+	for _, psm := range r.psms {
+		if psm.ID.Hex() == psmID {
+			psm.ScalingStatus = status
+		}
+	}
+	return nil
+}
+
+func scalingPSM(namespaces ...string) *domain.PodSchedulingMetrics {
+	return &domain.PodSchedulingMetrics{
+		BaseEntity:    domain.BaseEntity{ID: bson.NewObjectID()},
+		K8sNamespaces: namespaces,
+		Enabled:       true,
+		Scaling: &domain.PSMScaling{
+			Enabled:        true,
+			MetricName:     "gthulhu_pod_run_count_total",
+			TargetValue:    "100",
+			ScaleTargetRef: &domain.PSMScaleTargetRef{Name: "web"},
+		},
+	}
+}
+
+func newScalingService(repo domain.Repository) *Service {
+	return &Service{Repo: repo, kedaCfg: config.KEDAConfig{
+		Enabled:           true,
+		PrometheusAddress: "http://prometheus:9090",
+	}}
+}
+
+func TestReconcilePSMScaledObjectsCreatesOnePerNamespace(t *testing.T) {
+	psm := scalingPSM("team-b", "team-a", "team-a")
+	repo := newFakePSMScalingRepo(psm)
+	svc := newScalingService(repo)
+
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	name := "gthulhu-psm-" + psm.ID.Hex()
+	require.ElementsMatch(t, []string{"team-a/" + name, "team-b/" + name}, repo.applied)
+
+	so := repo.scaledObjects["team-a/"+name]
+	assert.Equal(t, domain.PSMScaleTargetRef{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}, so.ScaleTargetRef)
+	assert.Equal(t, int32(1), so.MinReplicaCount)
+	assert.Equal(t, int32(10), so.MaxReplicaCount)
+	assert.Equal(t, int32(300), so.CooldownPeriod)
+	assert.Equal(t, "http://prometheus:9090", so.Trigger.ServerAddress)
+	assert.Equal(t, `sum(rate(gthulhu_pod_run_count_total{namespace="team-a",pod_name=~"web-.*"}[2m]))`, so.Trigger.Query)
+	assert.Equal(t, "100", so.Trigger.Threshold)
+
+	status := repo.statuses[psm.ID.Hex()]
+	require.NotNil(t, status)
+	assert.True(t, status.Ready)
+	assert.Equal(t, []string{"team-a/" + name, "team-b/" + name}, status.ScaledObjects)
+
+	// A second pass with nothing changed neither applies nor rewrites status.
+	repo.applied = nil
+	repo.statuses = map[string]*domain.PSMScalingStatus{}
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	assert.Empty(t, repo.applied)
+	assert.Empty(t, repo.statuses)
+}
+
+func TestReconcilePSMScaledObjectsDeletesStaleObjects(t *testing.T) {
+	psm := scalingPSM("team-a", "team-b")
+	repo := newFakePSMScalingRepo(psm)
+	svc := newScalingService(repo)
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	require.Len(t, repo.scaledObjects, 2)
+
+	psm.K8sNamespaces = []string{"team-a"}
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	require.Len(t, repo.scaledObjects, 1)
+	assert.Contains(t, repo.scaledObjects, "team-a/gthulhu-psm-"+psm.ID.Hex())
+
+	psm.Scaling.Enabled = false
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	assert.Empty(t, repo.scaledObjects)
+	assert.False(t, psm.ScalingStatus.Ready)
+	assert.Empty(t, psm.ScalingStatus.ScaledObjects)
+
+	psm.Scaling.Enabled = true
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	repo.psms = nil
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	assert.Empty(t, repo.scaledObjects, "objects of deleted PodSchedulingMetrics are removed")
+}
+
+func TestReconcilePSMScaledObjectsKeepsExplicitZeroMinReplicas(t *testing.T) {
+	psm := scalingPSM("team-a")
+	psm.Scaling.MinReplicaCount = util.Ptr[int32](0)
+	repo := newFakePSMScalingRepo(psm)
+
+	require.NoError(t, newScalingService(repo).ReconcilePSMScaledObjects(context.Background()))
+	so := repo.scaledObjects["team-a/gthulhu-psm-"+psm.ID.Hex()]
+	require.NotNil(t, so)
+	assert.Equal(t, int32(0), so.MinReplicaCount, "an explicit zero scales to zero instead of taking the default")
+}
+
+func TestReconcilePSMScaledObjectsReportsInvalidHints(t *testing.T) {
+	cases := map[string]func(psm *domain.PodSchedulingMetrics){
+		"metric":     func(psm *domain.PodSchedulingMetrics) { psm.Scaling.MetricName = "up" },
+		"target":     func(psm *domain.PodSchedulingMetrics) { psm.Scaling.TargetValue = "lots" },
+		"ref":        func(psm *domain.PodSchedulingMetrics) { psm.Scaling.ScaleTargetRef = nil },
+		"namespaces": func(psm *domain.PodSchedulingMetrics) { psm.K8sNamespaces = nil },
+		"replicas": func(psm *domain.PodSchedulingMetrics) {
+			psm.Scaling.MinReplicaCount, psm.Scaling.MaxReplicaCount = util.Ptr[int32](5), 2
+		},
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			psm := scalingPSM("team-a")
+			mutate(psm)
+			repo := newFakePSMScalingRepo(psm)
+
+			require.NoError(t, newScalingService(repo).ReconcilePSMScaledObjects(context.Background()))
+			assert.Empty(t, repo.applied)
+			status := repo.statuses[psm.ID.Hex()]
+			require.NotNil(t, status)
+			assert.False(t, status.Ready)
+			assert.NotEmpty(t, status.Message)
+		})
+	}
+}
+
+func TestReconcilePSMScaledObjectsDisabled(t *testing.T) {
+	repo := newFakePSMScalingRepo(scalingPSM("team-a"))
+	svc := &Service{Repo: repo}
+
+	require.NoError(t, svc.ReconcilePSMScaledObjects(context.Background()))
+	assert.Empty(t, repo.applied)
+	assert.Empty(t, repo.statuses)
+}
+
+func TestPSMScalingQuery(t *testing.T) {
+	assert.Equal(t, `sum(gthulhu_pod_process_count{namespace="ns",pod_name=~"api\\.v2-.*"})`,
+		psmScalingQuery("gthulhu_pod_process_count", "ns", "api.v2"))
+}
@@ -17,6 +17,10 @@ func (svc *Service) CreatePodSchedulingMetrics(ctx context.Context, operator *do
 		return errors.WithMessagef(err, "invalid operator ID %s", operator.UID)
 	}
 
+	if err := verifyK8SNamespacePolicy(ctx, psm.K8sNamespaces); err != nil {
+		return err
+	}
+
 	psm.BaseEntity = domain.NewBaseEntity(&operatorID, &operatorID)
 	// NewBaseEntity does not assign an ID. The PSM repo stores the CR using
 	// psm.ID.Hex() as metadata.name, so a missing ID would always produce
@@ -32,8 +36,10 @@ func (svc *Service) CreatePodSchedulingMetrics(ctx context.Context, operator *do
 	if err := svc.Repo.CreatePSM(ctx, psm); err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionPSMCreate, domain.AuditResourcePSM, psm.ID.Hex(), nil, psm)
 
 	logger.Logger(ctx).Info().Msgf("created PodSchedulingMetrics %s", psm.ID.Hex())
+	svc.reconcilePSMScaledObjectsAfterChange(ctx)
 	return nil
 }
 
@@ -64,6 +70,9 @@ func (svc *Service) UpdatePodSchedulingMetrics(ctx context.Context, operator *do
 	}
 
 	existing := queryOpt.Result[0]
+	if err := verifyK8SNamespacePolicy(ctx, existing.K8sNamespaces, psm.K8sNamespaces); err != nil {
+		return err
+	}
 	psm.ID = psmID
 	psm.CreatedTime = existing.CreatedTime
 	psm.CreatorID = existing.CreatorID
@@ -72,8 +81,10 @@ func (svc *Service) UpdatePodSchedulingMetrics(ctx context.Context, operator *do
 	if err := svc.Repo.UpdatePSM(ctx, psm); err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionPSMUpdate, domain.AuditResourcePSM, name, existing, psm)
 
 	logger.Logger(ctx).Info().Msgf("updated PodSchedulingMetrics %s", name)
+	svc.reconcilePSMScaledObjectsAfterChange(ctx)
 	return nil
 }
 
@@ -88,11 +99,16 @@ func (svc *Service) DeletePodSchedulingMetrics(ctx context.Context, operator *do
 	if len(queryOpt.Result) == 0 {
 		return errs.NewHTTPStatusError(http.StatusNotFound, "PodSchedulingMetrics not found", nil)
 	}
+	if err := verifyK8SNamespacePolicy(ctx, queryOpt.Result[0].K8sNamespaces); err != nil {
+		return err
+	}
 
 	if err := svc.Repo.DeletePSM(ctx, name); err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionPSMDelete, domain.AuditResourcePSM, name, queryOpt.Result[0], nil)
 
 	logger.Logger(ctx).Info().Msgf("deleted PodSchedulingMetrics %s", name)
+	svc.reconcilePSMScaledObjectsAfterChange(ctx)
 	return nil
 }
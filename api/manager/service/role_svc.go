@@ -17,7 +17,11 @@ func (svc *Service) CreateRole(ctx context.Context, operator *domain.Claims, rol
 		return errs.NewHTTPStatusError(http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid user ID"))
 	}
 	role.BaseEntity = domain.NewBaseEntity(&operatorID, &operatorID)
-	return svc.Repo.CreateRole(ctx, role)
+	if err := svc.Repo.CreateRole(ctx, role); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionRoleCreate, domain.AuditResourceRole, role.ID.Hex(), nil, role)
+	return nil
 }
 
 func (svc *Service) UpdateRole(ctx context.Context, operator *domain.Claims, roleID string, opt domain.UpdateRoleOptions) error {
@@ -33,6 +37,7 @@ func (svc *Service) UpdateRole(ctx context.Context, operator *domain.Claims, rol
 		return errs.NewHTTPStatusError(http.StatusUnprocessableEntity, "role not found", fmt.Errorf("role with ID %s not found", roleID))
 	}
 	role := roles[0]
+	before := *role
 	if opt.Name != nil {
 		role.Name = *opt.Name
 	}
@@ -51,7 +56,11 @@ func (svc *Service) UpdateRole(ctx context.Context, operator *domain.Claims, rol
 		}
 	}
 	role.UpdaterID = operatorID
-	return svc.Repo.UpdateRole(ctx, role)
+	if err := svc.Repo.UpdateRole(ctx, role); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionRoleUpdate, domain.AuditResourceRole, roleID, &before, role)
+	return nil
 }
 
 func (svc *Service) DeleteRole(ctx context.Context, operator *domain.Claims, roleID string) error {
@@ -97,6 +106,6 @@ func (svc *Service) getRolesByIDs(ctx context.Context, roleIDs []string) ([]*dom
 	return opts.Result, nil
 }
 
-func (svc Service) QueryPermissions(ctx context.Context, opt *domain.QueryPermissionOptions) error {
+func (svc *Service) QueryPermissions(ctx context.Context, opt *domain.QueryPermissionOptions) error {
 	return svc.Repo.QueryPermissions(ctx, opt)
 }
@@ -0,0 +1,671 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type runtimeConfigRolloutRepository interface {
+	CreateRuntimeConfigRollout(ctx context.Context, rollout *domain.RuntimeConfigRollout) error
+	UpdateRuntimeConfigRollout(ctx context.Context, rollout *domain.RuntimeConfigRollout) error
+	QueryRuntimeConfigRollouts(ctx context.Context, opt *domain.QueryRuntimeConfigRolloutOptions) error
+}
+
+type schedulerBSSMetricsDMAdapter interface {
+	GetSchedulerBSSMetrics(ctx context.Context, decisionMaker *domain.DecisionMakerPod) (domain.SchedulerBSSMetrics, error)
+}
+
+// rolloutDeps bundles the optional capabilities a rollout needs so each
+// entry point checks them once.
+type rolloutDeps struct {
+	rolloutRepo runtimeConfigRolloutRepository
+	configRepo  runtimeConfigRepository
+	dmAdapter   runtimeConfigDMAdapter
+	bssAdapter  schedulerBSSMetricsDMAdapter
+}
+
+func (svc *Service) getRolloutDeps() (rolloutDeps, error) {
+	deps := rolloutDeps{}
+	var ok bool
+	if deps.rolloutRepo, ok = svc.Repo.(runtimeConfigRolloutRepository); !ok {
+		return deps, errs.NewHTTPStatusError(http.StatusNotImplemented, "runtime config rollout repository is not enabled", nil)
+	}
+	if deps.configRepo, ok = svc.Repo.(runtimeConfigRepository); !ok {
+		return deps, errs.NewHTTPStatusError(http.StatusNotImplemented, "runtime config repository is not enabled", nil)
+	}
+	if deps.dmAdapter, ok = svc.DMAdapter.(runtimeConfigDMAdapter); !ok {
+		return deps, errs.NewHTTPStatusError(http.StatusNotImplemented, "decision maker runtime config adapter is not enabled", nil)
+	}
+	deps.bssAdapter, _ = svc.DMAdapter.(schedulerBSSMetricsDMAdapter)
+	return deps, nil
+}
+
+// CreateRuntimeConfigRollout plans the waves of a new rollout and starts the
+// first one. Only one rollout may be running or paused at a time.
+func (svc *Service) CreateRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, opt *domain.RuntimeConfigRolloutOptions) (*domain.RuntimeConfigRollout, error) {
+	if svc.K8SAdapter == nil {
+		return nil, domain.ErrNoClient
+	}
+	if opt == nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "invalid request", fmt.Errorf("runtime config rollout options is nil"))
+	}
+	if opt.Config.ConfigVersion == "" {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "configVersion is required", nil)
+	}
+	opt.Config.Normalize()
+	if err := opt.Config.Validate(); err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "invalid runtime config", err)
+	}
+	if err := opt.Validate(); err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "invalid rollout", err)
+	}
+	deps, err := svc.getRolloutDeps()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := svc.activeRuntimeConfigRollout(ctx, deps.rolloutRepo)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "another runtime config rollout is in progress", fmt.Errorf("rollout %s is %s", active.ID.Hex(), active.State))
+	}
+
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{
+		NodeIDs: opt.NodeIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	discovered := map[string]struct{}{}
+	for _, dm := range dms {
+		discovered[dm.NodeID] = struct{}{}
+	}
+	for _, nodeID := range opt.NodeIDs {
+		if _, ok := discovered[nodeID]; !ok {
+			return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "decision maker is not discovered on every target node", fmt.Errorf("node %s has no decision maker", nodeID))
+		}
+	}
+	targets := make([]string, 0, len(discovered))
+	for nodeID := range discovered {
+		targets = append(targets, nodeID)
+	}
+	if len(targets) == 0 {
+		return nil, errs.NewHTTPStatusError(http.StatusNotFound, "no decision maker pods found", nil)
+	}
+	sort.Strings(targets)
+
+	waves, err := planRolloutWaves(targets, opt.Waves)
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, "invalid rollout waves", err)
+	}
+
+	previousOpt := &domain.QueryNodeRuntimeConfigOptions{NodeIDs: targets}
+	if err := deps.configRepo.QueryNodeRuntimeConfigs(ctx, previousOpt); err != nil {
+		return nil, fmt.Errorf("query node runtime configs: %w", err)
+	}
+	previousByNode := make(map[string]domain.RuntimeSchedulerConfig, len(previousOpt.Result))
+	for _, previous := range previousOpt.Result {
+		previousByNode[previous.NodeID] = previous.Config
+	}
+	for i := range waves {
+		for j := range waves[i].Nodes {
+			if previous, ok := previousByNode[waves[i].Nodes[j].NodeID]; ok {
+				waves[i].Nodes[j].PreviousConfig = &previous
+			}
+		}
+	}
+
+	failurePolicy := opt.FailurePolicy
+	if failurePolicy == "" {
+		failurePolicy = domain.RolloutFailurePause
+	}
+	rollout := &domain.RuntimeConfigRollout{
+		Config:        opt.Config,
+		Waves:         waves,
+		HealthGate:    opt.HealthGate,
+		FailurePolicy: failurePolicy,
+		State:         domain.RolloutStateRunning,
+	}
+	if operator != nil {
+		rollout.CreatedBy = operator.UID
+	}
+	if err := deps.rolloutRepo.CreateRuntimeConfigRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionSchedulerConfigRollout, domain.AuditResourceSchedulerConfig, rollout.ID.Hex(), nil, rollout)
+
+	if err := svc.advanceRuntimeConfigRollout(ctx, deps, rollout); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("failed to start runtime config rollout %s", rollout.ID.Hex())
+	}
+	return rollout, nil
+}
+
+// planRolloutWaves assigns every target node to exactly one wave. Explicit
+// node lists are taken as-is, percentage waves take the next nodes in sorted
+// order until the cumulative share is reached.
+func planRolloutWaves(targets []string, waves []domain.RolloutWave) ([]domain.RolloutWaveStatus, error) {
+	known := make(map[string]struct{}, len(targets))
+	for _, nodeID := range targets {
+		known[nodeID] = struct{}{}
+	}
+	assigned := make(map[string]struct{}, len(targets))
+	result := make([]domain.RolloutWaveStatus, 0, len(waves))
+	for i, wave := range waves {
+		status := domain.RolloutWaveStatus{
+			Index:       i,
+			State:       domain.RolloutWaveStatePending,
+			BakeSeconds: wave.BakeSeconds,
+			Nodes:       []domain.RolloutNodeStatus{},
+		}
+		if len(wave.NodeIDs) > 0 {
+			for _, nodeID := range wave.NodeIDs {
+				nodeID = strings.TrimSpace(nodeID)
+				if _, ok := known[nodeID]; !ok {
+					return nil, fmt.Errorf("waves[%d]: node %s is not a rollout target", i, nodeID)
+				}
+				if _, ok := assigned[nodeID]; ok {
+					return nil, fmt.Errorf("waves[%d]: node %s is already part of an earlier wave", i, nodeID)
+				}
+				assigned[nodeID] = struct{}{}
+				status.Nodes = append(status.Nodes, domain.RolloutNodeStatus{NodeID: nodeID})
+			}
+		} else {
+			want := (wave.Percentage*len(targets) + 99) / 100
+			for _, nodeID := range targets {
+				if len(assigned) >= want {
+					break
+				}
+				if _, ok := assigned[nodeID]; ok {
+					continue
+				}
+				assigned[nodeID] = struct{}{}
+				status.Nodes = append(status.Nodes, domain.RolloutNodeStatus{NodeID: nodeID})
+			}
+		}
+		result = append(result, status)
+	}
+	if len(assigned) < len(targets) {
+		return nil, fmt.Errorf("waves cover %d of %d target nodes, end with a 100 percent wave", len(assigned), len(targets))
+	}
+	return result, nil
+}
+
+func (svc *Service) activeRuntimeConfigRollout(ctx context.Context, repo runtimeConfigRolloutRepository) (*domain.RuntimeConfigRollout, error) {
+	queryOpt := &domain.QueryRuntimeConfigRolloutOptions{
+		States: []domain.RolloutState{domain.RolloutStateRunning, domain.RolloutStatePaused},
+	}
+	if err := repo.QueryRuntimeConfigRollouts(ctx, queryOpt); err != nil {
+		return nil, fmt.Errorf("query runtime config rollouts: %w", err)
+	}
+	if len(queryOpt.Result) == 0 {
+		return nil, nil
+	}
+	return queryOpt.Result[0], nil
+}
+
+func (svc *Service) ListRuntimeConfigRollouts(ctx context.Context, opt *domain.QueryRuntimeConfigRolloutOptions) error {
+	repo, ok := svc.Repo.(runtimeConfigRolloutRepository)
+	if !ok {
+		return errs.NewHTTPStatusError(http.StatusNotImplemented, "runtime config rollout repository is not enabled", nil)
+	}
+	return repo.QueryRuntimeConfigRollouts(ctx, opt)
+}
+
+func (svc *Service) GetRuntimeConfigRollout(ctx context.Context, rolloutID string) (*domain.RuntimeConfigRollout, error) {
+	repo, ok := svc.Repo.(runtimeConfigRolloutRepository)
+	if !ok {
+		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "runtime config rollout repository is not enabled", nil)
+	}
+	return getRuntimeConfigRollout(ctx, repo, rolloutID)
+}
+
+func getRuntimeConfigRollout(ctx context.Context, repo runtimeConfigRolloutRepository, rolloutID string) (*domain.RuntimeConfigRollout, error) {
+	objID, err := bson.ObjectIDFromHex(rolloutID)
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusUnprocessableEntity, "invalid rollout ID", fmt.Errorf("invalid rollout ID %s: %v", rolloutID, err))
+	}
+	queryOpt := &domain.QueryRuntimeConfigRolloutOptions{IDs: []bson.ObjectID{objID}}
+	if err := repo.QueryRuntimeConfigRollouts(ctx, queryOpt); err != nil {
+		return nil, err
+	}
+	if len(queryOpt.Result) == 0 {
+		return nil, errs.NewHTTPStatusError(http.StatusNotFound, "runtime config rollout not found", nil)
+	}
+	return queryOpt.Result[0], nil
+}
+
+// PauseRuntimeConfigRollout stops a running rollout before its next step.
+// Nodes that already received the new config keep it.
+func (svc *Service) PauseRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error) {
+	deps, err := svc.getRolloutDeps()
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getRuntimeConfigRollout(ctx, deps.rolloutRepo, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.State != domain.RolloutStateRunning {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "only a running rollout can be paused", fmt.Errorf("rollout %s is %s", rolloutID, rollout.State))
+	}
+	before := auditSnapshot(ctx, rollout)
+	rollout.State = domain.RolloutStatePaused
+	rollout.Message = "paused by " + operatorUID(operator)
+	if err := updateRuntimeConfigRollout(ctx, deps.rolloutRepo, rollout); err != nil {
+		return nil, err
+	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionSchedulerConfigPause, domain.AuditResourceSchedulerConfig, rolloutID, before, rollout)
+	return rollout, nil
+}
+
+// ResumeRuntimeConfigRollout continues a paused rollout. A wave that failed
+// is started again with fresh health baselines.
+func (svc *Service) ResumeRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error) {
+	if svc.K8SAdapter == nil {
+		return nil, domain.ErrNoClient
+	}
+	deps, err := svc.getRolloutDeps()
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getRuntimeConfigRollout(ctx, deps.rolloutRepo, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.State != domain.RolloutStatePaused {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "only a paused rollout can be resumed", fmt.Errorf("rollout %s is %s", rolloutID, rollout.State))
+	}
+	before := auditSnapshot(ctx, rollout)
+	if rollout.CurrentWave < len(rollout.Waves) {
+		wave := &rollout.Waves[rollout.CurrentWave]
+		if wave.State == domain.RolloutWaveStateFailed {
+			wave.State = domain.RolloutWaveStatePending
+			wave.Error = ""
+			wave.FinishedAt = 0
+			for i := range wave.Nodes {
+				wave.Nodes[i].Applied = false
+				wave.Nodes[i].Error = ""
+				wave.Nodes[i].BaselineFailedDispatches = nil
+				wave.Nodes[i].FailedDispatches = nil
+			}
+		}
+	}
+	rollout.State = domain.RolloutStateRunning
+	rollout.Message = "resumed by " + operatorUID(operator)
+	if err := svc.advanceRuntimeConfigRollout(ctx, deps, rollout); err != nil {
+		return nil, rolloutUpdateError(err)
+	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionSchedulerConfigResume, domain.AuditResourceSchedulerConfig, rolloutID, before, rollout)
+	return rollout, nil
+}
+
+// RollbackRuntimeConfigRollout restores the previous config on every node the
+// rollout has touched and ends the rollout.
+func (svc *Service) RollbackRuntimeConfigRollout(ctx context.Context, operator *domain.Claims, rolloutID string) (*domain.RuntimeConfigRollout, error) {
+	if svc.K8SAdapter == nil {
+		return nil, domain.ErrNoClient
+	}
+	deps, err := svc.getRolloutDeps()
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := getRuntimeConfigRollout(ctx, deps.rolloutRepo, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.State == domain.RolloutStateReverted {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "rollout is already reverted", nil)
+	}
+	before := auditSnapshot(ctx, rollout)
+	reverted, failed := svc.revertRolloutNodes(ctx, deps, rollout)
+	rollout.State = domain.RolloutStateReverted
+	rollout.Message = fmt.Sprintf("rolled back by %s: %d node(s) reverted, %d failed", operatorUID(operator), reverted, failed)
+	if err := updateRuntimeConfigRollout(ctx, deps.rolloutRepo, rollout); err != nil {
+		return nil, err
+	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionSchedulerConfigRollback, domain.AuditResourceSchedulerConfig, rolloutID, before, rollout)
+	return rollout, nil
+}
+
+// AdvanceRuntimeConfigRollouts drives every running rollout one step: it
+// starts pending waves, checks health gates of baking waves and promotes
+// waves whose bake time has elapsed.
+func (svc *Service) AdvanceRuntimeConfigRollouts(ctx context.Context) error {
+	if svc.K8SAdapter == nil {
+		return domain.ErrNoClient
+	}
+	deps, err := svc.getRolloutDeps()
+	if err != nil {
+		// rollouts are not supported by the wired repository or adapter
+		return nil
+	}
+	queryOpt := &domain.QueryRuntimeConfigRolloutOptions{States: []domain.RolloutState{domain.RolloutStateRunning}}
+	if err := deps.rolloutRepo.QueryRuntimeConfigRollouts(ctx, queryOpt); err != nil {
+		return fmt.Errorf("query runtime config rollouts: %w", err)
+	}
+	for _, rollout := range queryOpt.Result {
+		if err := svc.advanceRuntimeConfigRollout(ctx, deps, rollout); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				logger.Logger(ctx).Info().Msgf("runtime config rollout %s changed concurrently, retrying next round", rollout.ID.Hex())
+				continue
+			}
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to advance runtime config rollout %s", rollout.ID.Hex())
+		}
+	}
+	return nil
+}
+
+func (svc *Service) advanceRuntimeConfigRollout(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout) error {
+loop:
+	for rollout.State == domain.RolloutStateRunning {
+		if rollout.CurrentWave >= len(rollout.Waves) {
+			rollout.State = domain.RolloutStateCompleted
+			rollout.Message = fmt.Sprintf("all %d wave(s) passed", len(rollout.Waves))
+			break
+		}
+		wave := &rollout.Waves[rollout.CurrentWave]
+		switch wave.State {
+		case domain.RolloutWaveStatePending:
+			if err := svc.startRolloutWave(ctx, deps, rollout, wave); err != nil {
+				svc.failRolloutWave(ctx, deps, rollout, wave, err.Error())
+				break loop
+			}
+		case domain.RolloutWaveStateBaking:
+			if failures := svc.checkRolloutHealth(ctx, deps, rollout); len(failures) > 0 {
+				svc.failRolloutWave(ctx, deps, rollout, wave, strings.Join(failures, "; "))
+				break loop
+			}
+			now := time.Now().UnixMilli()
+			if now < wave.BakeUntil {
+				break loop
+			}
+			wave.State = domain.RolloutWaveStatePassed
+			wave.FinishedAt = now
+			rollout.CurrentWave++
+		default:
+			rollout.CurrentWave++
+		}
+	}
+	return deps.rolloutRepo.UpdateRuntimeConfigRollout(ctx, rollout)
+}
+
+func (svc *Service) startRolloutWave(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout, wave *domain.RolloutWaveStatus) error {
+	now := time.Now()
+	wave.State = domain.RolloutWaveStateBaking
+	wave.StartedAt = now.UnixMilli()
+	wave.BakeUntil = now.Add(time.Duration(wave.BakeSeconds) * time.Second).UnixMilli()
+
+	dmByNode, err := svc.rolloutDecisionMakers(ctx, wave.Nodes)
+	if err != nil {
+		return err
+	}
+
+	failures := []string{}
+	for i := range wave.Nodes {
+		node := &wave.Nodes[i]
+		node.Error = ""
+		dm := dmByNode[node.NodeID]
+		if dm == nil {
+			node.Error = "decision maker is not discovered"
+		} else if dm.State != domain.NodeStateOnline {
+			node.Error = "decision maker is offline"
+		} else {
+			node.Error = svc.applyRolloutNode(ctx, deps, rollout, node, dm, now.UnixMilli())
+		}
+		if node.Error != "" {
+			failures = append(failures, node.NodeID+": "+node.Error)
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// applyRolloutNode samples the health baseline of a node, pushes the rollout
+// config and records it as the node's desired config. It returns a non-empty
+// message on failure.
+func (svc *Service) applyRolloutNode(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout, node *domain.RolloutNodeStatus, dm *domain.DecisionMakerPod, now int64) string {
+	status, err := deps.dmAdapter.GetRuntimeConfigStatus(ctx, dm)
+	if err != nil {
+		return fmt.Sprintf("read runtime config status: %v", err)
+	}
+	node.BaselineRestartCount = status.RestartCount
+	node.RestartCount = status.RestartCount
+	if node.PreviousConfig == nil && status.Config != nil {
+		previous := *status.Config
+		node.PreviousConfig = &previous
+	}
+	if deps.bssAdapter != nil && rollout.HealthGate.MaxFailedDispatchesIncrease != nil {
+		metrics, err := deps.bssAdapter.GetSchedulerBSSMetrics(ctx, dm)
+		if err != nil {
+			logger.Logger(ctx).Warn().Err(err).Msgf("failed to sample scheduler metrics on node %s before rollout", node.NodeID)
+		} else if metrics.Available {
+			baseline := metrics.NrFailedDispatches
+			node.BaselineFailedDispatches = &baseline
+		}
+	}
+
+	result := domain.RuntimeConfigApplyResult{NodeID: dm.NodeID, Host: dm.Host, ConfigVersion: rollout.Config.ConfigVersion}
+	if err := deps.dmAdapter.ApplyRuntimeConfig(ctx, dm, rollout.Config); err != nil {
+		return err.Error()
+	}
+	node.Applied = true
+	node.LastCheckedAt = now
+	result.Success = true
+	desired := &domain.NodeRuntimeConfig{
+		NodeID:          node.NodeID,
+		ConfigVersion:   rollout.Config.ConfigVersion,
+		Config:          rollout.Config,
+		UpdatedBy:       rollout.CreatedBy,
+		UpdatedAt:       now,
+		LastApplyResult: result,
+	}
+	desired.LastApplyResult.DesiredConfig = &desired.Config
+	if err := deps.configRepo.UpsertNodeRuntimeConfig(ctx, desired); err != nil {
+		return fmt.Sprintf("persist desired runtime config: %v", err)
+	}
+	return ""
+}
+
+// checkRolloutHealth evaluates the health gate on every node the rollout has
+// applied so far and returns one message per unhealthy node.
+func (svc *Service) checkRolloutHealth(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout) []string {
+	nodes := []*domain.RolloutNodeStatus{}
+	for _, node := range rollout.StartedNodes() {
+		if node.Applied && !node.Reverted {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	nodeStatuses := make([]domain.RolloutNodeStatus, 0, len(nodes))
+	for _, node := range nodes {
+		nodeStatuses = append(nodeStatuses, *node)
+	}
+	dmByNode, err := svc.rolloutDecisionMakers(ctx, nodeStatuses)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	gate := rollout.HealthGate
+	now := time.Now().UnixMilli()
+	failures := []string{}
+	for _, node := range nodes {
+		node.LastCheckedAt = now
+		node.Error = svc.checkRolloutNodeHealth(ctx, deps, gate, node, dmByNode[node.NodeID])
+		if node.Error != "" {
+			failures = append(failures, node.NodeID+": "+node.Error)
+		}
+	}
+	return failures
+}
+
+func (svc *Service) checkRolloutNodeHealth(ctx context.Context, deps rolloutDeps, gate domain.RolloutHealthGate, node *domain.RolloutNodeStatus, dm *domain.DecisionMakerPod) string {
+	if dm == nil {
+		return "decision maker is not discovered"
+	}
+	if dm.State != domain.NodeStateOnline {
+		return "decision maker is offline"
+	}
+	status, err := deps.dmAdapter.GetRuntimeConfigStatus(ctx, dm)
+	if err != nil {
+		return fmt.Sprintf("read runtime config status: %v", err)
+	}
+	node.RestartCount = status.RestartCount
+	if delta := status.RestartCount - node.BaselineRestartCount; delta > gate.MaxRestartCountIncrease {
+		return fmt.Sprintf("scheduler restarted %d time(s), gate allows %d", delta, gate.MaxRestartCountIncrease)
+	}
+
+	if gate.MaxFailedDispatchesIncrease == nil || deps.bssAdapter == nil {
+		return ""
+	}
+	metrics, err := deps.bssAdapter.GetSchedulerBSSMetrics(ctx, dm)
+	if err != nil {
+		return fmt.Sprintf("read scheduler metrics: %v", err)
+	}
+	if !metrics.Available {
+		return ""
+	}
+	current := metrics.NrFailedDispatches
+	node.FailedDispatches = &current
+	// A missing baseline means the scheduler had not reported before the
+	// rollout; a smaller value means the counters were reset by a restart.
+	// Either way the current sample becomes the new baseline.
+	if node.BaselineFailedDispatches == nil || current < *node.BaselineFailedDispatches {
+		baseline := current
+		node.BaselineFailedDispatches = &baseline
+		return ""
+	}
+	if delta := current - *node.BaselineFailedDispatches; delta > *gate.MaxFailedDispatchesIncrease {
+		return fmt.Sprintf("nr_failed_dispatches increased by %d, gate allows %d", delta, *gate.MaxFailedDispatchesIncrease)
+	}
+	return ""
+}
+
+func (svc *Service) failRolloutWave(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout, wave *domain.RolloutWaveStatus, reason string) {
+	wave.State = domain.RolloutWaveStateFailed
+	wave.Error = reason
+	wave.FinishedAt = time.Now().UnixMilli()
+	logger.Logger(ctx).Warn().Msgf("runtime config rollout %s wave %d failed: %s", rollout.ID.Hex(), wave.Index, reason)
+
+	if rollout.FailurePolicy != domain.RolloutFailureRevert {
+		rollout.State = domain.RolloutStatePaused
+		rollout.Message = fmt.Sprintf("wave %d failed, rollout paused", wave.Index)
+		return
+	}
+	reverted, failed := svc.revertRolloutNodes(ctx, deps, rollout)
+	rollout.State = domain.RolloutStateReverted
+	rollout.Message = fmt.Sprintf("wave %d failed, %d node(s) reverted, %d failed", wave.Index, reverted, failed)
+}
+
+// revertRolloutNodes restores PreviousConfig on every applied node. The
+// previous config is persisted as the desired config even when the decision
+// maker is unreachable, so the reconciler pushes it once the node is back.
+func (svc *Service) revertRolloutNodes(ctx context.Context, deps rolloutDeps, rollout *domain.RuntimeConfigRollout) (int, int) {
+	nodes := []*domain.RolloutNodeStatus{}
+	nodeStatuses := []domain.RolloutNodeStatus{}
+	for _, node := range rollout.StartedNodes() {
+		if node.Applied && !node.Reverted {
+			nodes = append(nodes, node)
+			nodeStatuses = append(nodeStatuses, *node)
+		}
+	}
+	if len(nodes) == 0 {
+		return 0, 0
+	}
+	dmByNode, err := svc.rolloutDecisionMakers(ctx, nodeStatuses)
+	if err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("failed to query decision makers while reverting rollout %s", rollout.ID.Hex())
+		dmByNode = map[string]*domain.DecisionMakerPod{}
+	}
+
+	reverted, failed := 0, 0
+	now := time.Now().UnixMilli()
+	for _, node := range nodes {
+		if node.PreviousConfig == nil {
+			node.Error = "no previous config recorded, node left on rollout config"
+			failed++
+			continue
+		}
+		previous := *node.PreviousConfig
+		result := domain.RuntimeConfigApplyResult{NodeID: node.NodeID, ConfigVersion: previous.ConfigVersion, DesiredConfig: &previous}
+		if dm := dmByNode[node.NodeID]; dm != nil && dm.State == domain.NodeStateOnline {
+			result.Host = dm.Host
+			if err := deps.dmAdapter.ApplyRuntimeConfig(ctx, dm, previous); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+		} else {
+			result.Error = "decision maker is offline"
+			result.Drift = true
+		}
+		desired := &domain.NodeRuntimeConfig{
+			NodeID:          node.NodeID,
+			ConfigVersion:   previous.ConfigVersion,
+			Config:          previous,
+			UpdatedBy:       rollout.CreatedBy,
+			UpdatedAt:       now,
+			LastApplyResult: result,
+		}
+		if err := deps.configRepo.UpsertNodeRuntimeConfig(ctx, desired); err != nil {
+			node.Error = fmt.Sprintf("persist previous runtime config: %v", err)
+			failed++
+			continue
+		}
+		node.Reverted = true
+		node.Error = result.Error
+		reverted++
+	}
+	return reverted, failed
+}
+
+func (svc *Service) rolloutDecisionMakers(ctx context.Context, nodes []domain.RolloutNodeStatus) (map[string]*domain.DecisionMakerPod, error) {
+	nodeIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.NodeID)
+	}
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{
+		NodeIDs: nodeIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query decision maker pods: %w", err)
+	}
+	dmByNode := make(map[string]*domain.DecisionMakerPod, len(dms))
+	for _, dm := range dms {
+		dmByNode[dm.NodeID] = dm
+	}
+	return dmByNode, nil
+}
+
+func updateRuntimeConfigRollout(ctx context.Context, repo runtimeConfigRolloutRepository, rollout *domain.RuntimeConfigRollout) error {
+	return rolloutUpdateError(repo.UpdateRuntimeConfigRollout(ctx, rollout))
+}
+
+func rolloutUpdateError(err error) error {
+	if errors.Is(err, domain.ErrConflict) {
+		return errs.NewHTTPStatusError(http.StatusConflict, "runtime config rollout was modified concurrently, retry", err)
+	}
+	return err
+}
+
+func operatorUID(operator *domain.Claims) string {
+	if operator == nil || operator.UID == "" {
+		return "system"
+	}
+	return operator.UID
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeRolloutRepo keeps rollouts and node runtime configs in memory. The
+// embedded domain.Repository is nil, so any unexpected call panics.
+type fakeRolloutRepo struct {
+	domain.Repository
+	rollouts map[bson.ObjectID]domain.RuntimeConfigRollout
+	configs  map[string]domain.NodeRuntimeConfig
+	audits   []*domain.AuditLog
+}
+
+func newFakeRolloutRepo(t *testing.T) *fakeRolloutRepo {
+	return &fakeRolloutRepo{
+		Repository: newDMRegistryMockRepo(t),
+		rollouts:   map[bson.ObjectID]domain.RuntimeConfigRollout{},
+		configs:    map[string]domain.NodeRuntimeConfig{},
+	}
+}
+
+func (r *fakeRolloutRepo) CreateRuntimeConfigRollout(_ context.Context, rollout *domain.RuntimeConfigRollout) error {
+	rollout.ID = bson.NewObjectID()
+	rollout.Revision = 1
+	r.rollouts[rollout.ID] = cloneRollout(rollout)
+	return nil
+}
+
+func (r *fakeRolloutRepo) CreateAuditLog(_ context.Context, log *domain.AuditLog) error {
+	r.audits = append(r.audits, log)
+	return nil
+}
+
+func (r *fakeRolloutRepo) UpdateRuntimeConfigRollout(_ context.Context, rollout *domain.RuntimeConfigRollout) error {
+	stored, ok := r.rollouts[rollout.ID]
+	if !ok || stored.Revision != rollout.Revision {
+		return domain.ErrConflict
+	}
+	rollout.Revision++
+	r.rollouts[rollout.ID] = cloneRollout(rollout)
+	return nil
+}
+
+func (r *fakeRolloutRepo) QueryRuntimeConfigRollouts(_ context.Context, opt *domain.QueryRuntimeConfigRolloutOptions) error {
+	for _, rollout := range r.rollouts {
+		if len(opt.IDs) > 0 && !containsObjectID(opt.IDs, rollout.ID) {
+			continue
+		}
+		if len(opt.States) > 0 && !containsRolloutState(opt.States, rollout.State) {
+			continue
+		}
+		cloned := cloneRollout(&rollout)
+		opt.Result = append(opt.Result, &cloned)
+	}
+	return nil
+}
+
+func (r *fakeRolloutRepo) UpsertNodeRuntimeConfig(_ context.Context, cfg *domain.NodeRuntimeConfig) error {
+	r.configs[cfg.NodeID] = *cfg
+	return nil
+}
+
+func (r *fakeRolloutRepo) QueryNodeRuntimeConfigs(_ context.Context, opt *domain.QueryNodeRuntimeConfigOptions) error {
+	for nodeID, cfg := range r.configs {
+		if len(opt.NodeIDs) > 0 && !containsString(opt.NodeIDs, nodeID) {
+			continue
+		}
+		cfg := cfg
+		opt.Result = append(opt.Result, &cfg)
+	}
+	return nil
+}
+
+// cloneRollout deep copies the wave slices so the stored rollout does not
+// alias the one the service keeps mutating.
+func cloneRollout(rollout *domain.RuntimeConfigRollout) domain.RuntimeConfigRollout {
+	cloned := *rollout
+	cloned.Waves = make([]domain.RolloutWaveStatus, len(rollout.Waves))
+	for i, wave := range rollout.Waves {
+		cloned.Waves[i] = wave
+		cloned.Waves[i].Nodes = append([]domain.RolloutNodeStatus(nil), wave.Nodes...)
+	}
+	return cloned
+}
+
+func containsObjectID(ids []bson.ObjectID, id bson.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRolloutState(states []domain.RolloutState, state domain.RolloutState) bool {
+	for _, candidate := range states {
+		if candidate == state {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeRolloutDM records applied configs and serves per-node health samples.
+type fakeRolloutDM struct {
+	domain.DecisionMakerAdapter
+	applied          map[string][]domain.RuntimeSchedulerConfig
+	restartCount     map[string]int64
+	failedDispatches map[string]uint64
+}
+
+func newFakeRolloutDM() *fakeRolloutDM {
+	return &fakeRolloutDM{
+		applied:          map[string][]domain.RuntimeSchedulerConfig{},
+		restartCount:     map[string]int64{},
+		failedDispatches: map[string]uint64{},
+	}
+}
+
+func (dm *fakeRolloutDM) ApplyRuntimeConfig(_ context.Context, decisionMaker *domain.DecisionMakerPod, config domain.RuntimeSchedulerConfig) error {
+	dm.applied[decisionMaker.NodeID] = append(dm.applied[decisionMaker.NodeID], config)
+	return nil
+}
+
+func (dm *fakeRolloutDM) GetRuntimeConfigStatus(_ context.Context, decisionMaker *domain.DecisionMakerPod) (domain.RuntimeConfigApplyResult, error) {
+	return domain.RuntimeConfigApplyResult{NodeID: decisionMaker.NodeID, RestartCount: dm.restartCount[decisionMaker.NodeID]}, nil
+}
+
+func (dm *fakeRolloutDM) GetSchedulerBSSMetrics(_ context.Context, decisionMaker *domain.DecisionMakerPod) (domain.SchedulerBSSMetrics, error) {
+	return domain.SchedulerBSSMetrics{NrFailedDispatches: dm.failedDispatches[decisionMaker.NodeID], Available: true}, nil
+}
+
+func newRolloutTestService(t *testing.T, nodeIDs ...string) (*Service, *fakeRolloutRepo, *fakeRolloutDM) {
+	t.Helper()
+	dms := make([]*domain.DecisionMakerPod, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		dms = append(dms, &domain.DecisionMakerPod{NodeID: nodeID, Host: nodeID, Port: 8080, State: domain.NodeStateOnline})
+	}
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockK8S.EXPECT().
+		QueryDecisionMakerPods(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, opt *domain.QueryDecisionMakerPodsOptions) ([]*domain.DecisionMakerPod, error) {
+			if len(opt.NodeIDs) == 0 {
+				return dms, nil
+			}
+			result := []*domain.DecisionMakerPod{}
+			for _, dm := range dms {
+				if containsString(opt.NodeIDs, dm.NodeID) {
+					result = append(result, dm)
+				}
+			}
+			return result, nil
+		}).
+		Maybe()
+
+	repo := newFakeRolloutRepo(t)
+	dmAdapter := newFakeRolloutDM()
+	return &Service{K8SAdapter: mockK8S, Repo: repo, DMAdapter: dmAdapter}, repo, dmAdapter
+}
+
+func TestPlanRolloutWaves(t *testing.T) {
+	targets := []string{"node-a", "node-b", "node-c", "node-d"}
+
+	waves, err := planRolloutWaves(targets, []domain.RolloutWave{
+		{NodeIDs: []string{"node-c"}},
+		{Percentage: 50},
+		{Percentage: 100},
+	})
+	require.NoError(t, err)
+	require.Len(t, waves, 3)
+	assert.Equal(t, []domain.RolloutNodeStatus{{NodeID: "node-c"}}, waves[0].Nodes)
+	assert.Equal(t, []domain.RolloutNodeStatus{{NodeID: "node-a"}}, waves[1].Nodes)
+	assert.Equal(t, []domain.RolloutNodeStatus{{NodeID: "node-b"}, {NodeID: "node-d"}}, waves[2].Nodes)
+
+	_, err = planRolloutWaves(targets, []domain.RolloutWave{{Percentage: 50}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cover 2 of 4")
+
+	_, err = planRolloutWaves(targets, []domain.RolloutWave{{NodeIDs: []string{"node-x"}}, {Percentage: 100}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a rollout target")
+}
+
+func TestCreateRuntimeConfigRolloutStartsFirstWaveOnly(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, dmAdapter := newRolloutTestService(t, "node-a", "node-b", "node-c", "node-d")
+	repo.configs["node-a"] = domain.NodeRuntimeConfig{NodeID: "node-a", Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v1", Mode: domain.SchedulerModeGthulhu}}
+
+	rollout, err := svc.CreateRuntimeConfigRollout(ctx, newTestClaims(t), &domain.RuntimeConfigRolloutOptions{
+		Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v2", Mode: domain.SchedulerModeSCX, SchedulerName: "scx_lavd"},
+		Waves:  []domain.RolloutWave{{Percentage: 25, BakeSeconds: 600}, {Percentage: 100}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RolloutStateRunning, rollout.State)
+	assert.Equal(t, 0, rollout.CurrentWave)
+	assert.Equal(t, domain.RolloutWaveStateBaking, rollout.Waves[0].State)
+	assert.Equal(t, domain.RolloutWaveStatePending, rollout.Waves[1].State)
+
+	require.Len(t, dmAdapter.applied["node-a"], 1)
+	assert.Equal(t, "scx_lavd", dmAdapter.applied["node-a"][0].SchedulerName)
+	assert.Empty(t, dmAdapter.applied["node-b"])
+	assert.Equal(t, "v2", repo.configs["node-a"].ConfigVersion)
+	require.NotNil(t, rollout.Waves[0].Nodes[0].PreviousConfig)
+	assert.Equal(t, "v1", rollout.Waves[0].Nodes[0].PreviousConfig.ConfigVersion)
+
+	_, err = svc.CreateRuntimeConfigRollout(ctx, newTestClaims(t), &domain.RuntimeConfigRolloutOptions{
+		Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v3", Mode: domain.SchedulerModeGthulhu},
+		Waves:  []domain.RolloutWave{{Percentage: 100}},
+	})
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, httpErr.StatusCode)
+
+	_, err = svc.ApplyRuntimeConfig(ctx, newTestClaims(t), &domain.RuntimeConfigApplyOptions{
+		Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v3", Mode: domain.SchedulerModeGthulhu},
+	})
+	httpErr, ok = errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, httpErr.StatusCode)
+}
+
+func TestCreateRuntimeConfigRolloutWithoutBakeCompletes(t *testing.T) {
+	svc, repo, dmAdapter := newRolloutTestService(t, "node-a", "node-b")
+
+	rollout, err := svc.CreateRuntimeConfigRollout(context.Background(), newTestClaims(t), &domain.RuntimeConfigRolloutOptions{
+		Config: domain.RuntimeSchedulerConfig{ConfigVersion: "v2", Mode: domain.SchedulerModeSimple},
+		Waves:  []domain.RolloutWave{{NodeIDs: []string{"node-b"}}, {Percentage: 100}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RolloutStateCompleted, rollout.State)
+	assert.Len(t, dmAdapter.applied["node-a"], 1)
+	assert.Len(t, dmAdapter.applied["node-b"], 1)
+	assert.Equal(t, domain.RolloutStateCompleted, repo.rollouts[rollout.ID].State)
+}
+
+func TestAdvanceRuntimeConfigRolloutsRevertsOnRestartGate(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, dmAdapter := newRolloutTestService(t, "node-a", "node-b")
+	previous := domain.RuntimeSchedulerConfig{ConfigVersion: "v1", Mode: domain.SchedulerModeGthulhu, SchedulerEnabled: true}
+	repo.configs["node-a"] = domain.NodeRuntimeConfig{NodeID: "node-a", ConfigVersion: "v1", Config: previous}
+
+	rollout, err := svc.CreateRuntimeConfigRollout(ctx, newTestClaims(t), &domain.RuntimeConfigRolloutOptions{
+		Config:        domain.RuntimeSchedulerConfig{ConfigVersion: "v2", Mode: domain.SchedulerModeSCX, SchedulerName: "scx_lavd"},
+		Waves:         []domain.RolloutWave{{Percentage: 50, BakeSeconds: 600}, {Percentage: 100}},
+		FailurePolicy: domain.RolloutFailureRevert,
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.RolloutWaveStateBaking, rollout.Waves[0].State)
+
+	dmAdapter.restartCount["node-a"] = 1
+	require.NoError(t, svc.AdvanceRuntimeConfigRollouts(ctx))
+
+	stored := repo.rollouts[rollout.ID]
+	assert.Equal(t, domain.RolloutStateReverted, stored.State)
+	assert.Equal(t, domain.RolloutWaveStateFailed, stored.Waves[0].State)
+	assert.Contains(t, stored.Waves[0].Error, "restarted 1 time(s)")
+	assert.True(t, stored.Waves[0].Nodes[0].Reverted)
+	require.Len(t, dmAdapter.applied["node-a"], 2)
+	assert.Equal(t, previous, dmAdapter.applied["node-a"][1])
+	assert.Equal(t, "v1", repo.configs["node-a"].ConfigVersion)
+	assert.Empty(t, dmAdapter.applied["node-b"])
+}
+
+func TestAdvanceRuntimeConfigRolloutsPausesOnFailedDispatchesGate(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, dmAdapter := newRolloutTestService(t, "node-a", "node-b")
+	dmAdapter.failedDispatches["node-a"] = 10
+	maxFailed := uint64(5)
+
+	rollout, err := svc.CreateRuntimeConfigRollout(ctx, newTestClaims(t), &domain.RuntimeConfigRolloutOptions{
+		Config:     domain.RuntimeSchedulerConfig{ConfigVersion: "v2", Mode: domain.SchedulerModeSimple},
+		Waves:      []domain.RolloutWave{{Percentage: 50, BakeSeconds: 600}, {Percentage: 100}},
+		HealthGate: domain.RolloutHealthGate{MaxFailedDispatchesIncrease: &maxFailed},
+	})
+	require.NoError(t, err)
+
+	dmAdapter.failedDispatches["node-a"] = 14
+	require.NoError(t, svc.AdvanceRuntimeConfigRollouts(ctx))
+	assert.Equal(t, domain.RolloutStateRunning, repo.rollouts[rollout.ID].State)
+
+	dmAdapter.failedDispatches["node-a"] = 20
+	require.NoError(t, svc.AdvanceRuntimeConfigRollouts(ctx))
+	stored := repo.rollouts[rollout.ID]
+	assert.Equal(t, domain.RolloutStatePaused, stored.State)
+	assert.Contains(t, stored.Waves[0].Error, "nr_failed_dispatches increased by 10")
+	assert.Len(t, dmAdapter.applied["node-a"], 1)
+
+	dmAdapter.failedDispatches["node-a"] = 0
+	resumed, err := svc.ResumeRuntimeConfigRollout(ctx, newTestClaims(t), rollout.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, domain.RolloutStateRunning, resumed.State)
+	assert.Equal(t, domain.RolloutWaveStateBaking, resumed.Waves[0].State)
+	assert.Len(t, dmAdapter.applied["node-a"], 2)
+}
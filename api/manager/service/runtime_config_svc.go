@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/Gthulhu/api/manager/domain"
@@ -40,6 +41,15 @@ func (svc *Service) ApplyRuntimeConfig(ctx context.Context, operator *domain.Cla
 	if !ok {
 		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "decision maker runtime config adapter is not enabled", nil)
 	}
+	if rolloutRepo, ok := svc.Repo.(runtimeConfigRolloutRepository); ok {
+		active, err := svc.activeRuntimeConfigRollout(ctx, rolloutRepo)
+		if err != nil {
+			return nil, err
+		}
+		if active != nil {
+			return nil, errs.NewHTTPStatusError(http.StatusConflict, "a runtime config rollout is in progress, finish or roll it back first", fmt.Errorf("rollout %s is %s", active.ID.Hex(), active.State))
+		}
+	}
 	repo, _ := svc.Repo.(runtimeConfigRepository)
 	updatedBy := ""
 	if operator != nil {
@@ -48,20 +58,30 @@ func (svc *Service) ApplyRuntimeConfig(ctx context.Context, operator *domain.Cla
 	now := time.Now().UnixMilli()
 
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: domain.LabelSelector{Key: "app", Value: "decisionmaker"},
-		NodeIDs:            opt.NodeIDs,
+		NodeIDs: opt.NodeIDs,
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return nil, err
 	}
+	// Decision makers in pull mode are not discovered; they pull the
+	// persisted config instead.
+	pulling, err := svc.pullingNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unreachableNodeIDs := opt.NodeIDs
+	if len(unreachableNodeIDs) == 0 {
+		unreachableNodeIDs = sortedNodeIDs(pulling)
+	}
 	if len(dms) == 0 {
-		if repo != nil && len(opt.NodeIDs) > 0 {
-			results := make([]domain.RuntimeConfigApplyResult, 0, len(opt.NodeIDs))
-			for _, nodeID := range opt.NodeIDs {
-				result := persistUnreachableRuntimeConfig(ctx, repo, nodeID, opt.Config, updatedBy, now, "decision maker is not discovered")
+		if repo != nil && len(unreachableNodeIDs) > 0 {
+			results := make([]domain.RuntimeConfigApplyResult, 0, len(unreachableNodeIDs))
+			for _, nodeID := range unreachableNodeIDs {
+				result := persistUnreachableRuntimeConfig(ctx, repo, nodeID, opt.Config, updatedBy, now, unreachableRuntimeConfigReason(pulling, nodeID))
 				results = append(results, result)
 			}
+			svc.auditRuntimeConfigApply(ctx, operator, opt, results)
 			return results, nil
 		}
 		return nil, errs.NewHTTPStatusError(http.StatusNotFound, "no decision maker pods found", nil)
@@ -116,17 +136,43 @@ func (svc *Service) ApplyRuntimeConfig(ctx context.Context, operator *domain.Cla
 		}
 	}
 	if repo != nil {
-		for _, nodeID := range opt.NodeIDs {
+		for _, nodeID := range unreachableNodeIDs {
 			if _, ok := seenNodes[nodeID]; ok {
 				continue
 			}
-			result := persistUnreachableRuntimeConfig(ctx, repo, nodeID, opt.Config, updatedBy, now, "decision maker is not discovered")
+			result := persistUnreachableRuntimeConfig(ctx, repo, nodeID, opt.Config, updatedBy, now, unreachableRuntimeConfigReason(pulling, nodeID))
 			results = append(results, result)
 		}
 	}
+	svc.auditRuntimeConfigApply(ctx, operator, opt, results)
 	return results, nil
 }
 
+func (svc *Service) auditRuntimeConfigApply(ctx context.Context, operator *domain.Claims, opt *domain.RuntimeConfigApplyOptions, results []domain.RuntimeConfigApplyResult) {
+	after := struct {
+		Config  domain.RuntimeSchedulerConfig     `bson:"config"`
+		NodeIDs []string                          `bson:"nodeIds,omitempty"`
+		Results []domain.RuntimeConfigApplyResult `bson:"results"`
+	}{Config: opt.Config, NodeIDs: opt.NodeIDs, Results: results}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionSchedulerConfigApply, domain.AuditResourceSchedulerConfig, opt.Config.ConfigVersion, nil, after)
+}
+
+func unreachableRuntimeConfigReason(pulling map[string]struct{}, nodeID string) string {
+	if _, ok := pulling[nodeID]; ok {
+		return "waiting for the decision maker to pull the config"
+	}
+	return "decision maker is not discovered"
+}
+
+func sortedNodeIDs(nodes map[string]struct{}) []string {
+	nodeIDs := make([]string, 0, len(nodes))
+	for nodeID := range nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	return nodeIDs
+}
+
 func persistUnreachableRuntimeConfig(ctx context.Context, repo runtimeConfigRepository, nodeID string, config domain.RuntimeSchedulerConfig, updatedBy string, updatedAt int64, errMsg string) domain.RuntimeConfigApplyResult {
 	result := domain.RuntimeConfigApplyResult{
 		NodeID:        nodeID,
@@ -161,10 +207,9 @@ func (svc *Service) GetRuntimeConfigStatus(ctx context.Context, nodeIDs []string
 	}
 
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: domain.LabelSelector{Key: "app", Value: "decisionmaker"},
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return nil, err
 	}
@@ -219,6 +264,10 @@ func (svc *Service) GetRuntimeConfigStatus(ctx context.Context, nodeIDs []string
 		results = append(results, result)
 	}
 
+	pulling, err := svc.pullingNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
 	for nodeID, desired := range desiredByNode {
 		if _, ok := seenNodes[nodeID]; ok {
 			continue
@@ -226,7 +275,12 @@ func (svc *Service) GetRuntimeConfigStatus(ctx context.Context, nodeIDs []string
 		result := domain.RuntimeConfigApplyResult{
 			NodeID:  nodeID,
 			Success: false,
-			Error:   "decision maker is not discovered",
+			Error:   unreachableRuntimeConfigReason(pulling, nodeID),
+		}
+		if _, ok := pulling[nodeID]; ok && desired.LastApplyResult.Success {
+			// The decision maker reports what it runs by pulling.
+			result = desired.LastApplyResult
+			result.NodeID = nodeID
 		}
 		attachDesiredRuntimeConfig(&result, desired)
 		results = append(results, result)
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/intentprecedence"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// pauseCommand is the pod sandbox process, which decision makers never
+// schedule.
+const pauseCommand = "pause"
+
+// PreviewScheduleStrategy resolves the pods, nodes and processes the strategy
+// would target and the existing strategies already targeting them, without
+// persisting the strategy or sending intents. A non-zero strategy.ID previews
+// an update of that strategy, so its own intents are not reported as
+// conflicts.
+func (svc *Service) PreviewScheduleStrategy(ctx context.Context, strategy *domain.ScheduleStrategy) (*domain.StrategyPreview, error) {
+	if err := svc.validateClusterSelector(strategy.ClusterSelector); err != nil {
+		return nil, err
+	}
+	commandRegex, err := regexp.Compile(strategy.CommandRegex)
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusBadRequest, fmt.Sprintf("invalid commandRegex %q", strategy.CommandRegex), err)
+	}
+	queryOpt := &domain.QueryPodsOptions{
+		Clusters:       strategy.ClusterSelector,
+		K8SNamespace:   strategy.K8sNamespace,
+		LabelSelectors: strategy.LabelSelectors,
+	}
+	pods, err := svc.K8SAdapter.QueryPods(ctx, queryOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &domain.StrategyPreview{MatchedPods: len(pods)}
+	if len(pods) == 0 {
+		preview.Warnings = append(preview.Warnings, "no pods match the strategy criteria")
+		return preview, nil
+	}
+
+	nodesByID := make(map[string]*domain.StrategyPreviewNode)
+	podsByID := make(map[string]*domain.StrategyPreviewPod, len(pods))
+	podIDs := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		node, ok := nodesByID[pod.NodeID]
+		if !ok {
+			node = &domain.StrategyPreviewNode{Cluster: pod.Cluster, NodeID: pod.NodeID}
+			nodesByID[pod.NodeID] = node
+			preview.Nodes = append(preview.Nodes, node)
+		}
+		previewPod := &domain.StrategyPreviewPod{
+			PodID:        pod.PodID,
+			PodName:      pod.Name,
+			K8sNamespace: pod.K8SNamespace,
+		}
+		node.Pods = append(node.Pods, previewPod)
+		podsByID[pod.PodID] = previewPod
+		podIDs = append(podIDs, pod.PodID)
+	}
+	sort.Slice(preview.Nodes, func(i, j int) bool { return preview.Nodes[i].NodeID < preview.Nodes[j].NodeID })
+
+	podProcesses := svc.previewPodProcesses(ctx, preview.Nodes)
+	podsWithoutProcess := 0
+	for _, node := range preview.Nodes {
+		for _, pod := range node.Pods {
+			for _, process := range podProcesses[pod.PodID] {
+				if process.Command != pauseCommand && commandRegex.MatchString(process.Command) {
+					pod.Processes = append(pod.Processes, process)
+				}
+			}
+			preview.MatchedProcesses += len(pod.Processes)
+			if node.ProcessesResolved && len(pod.Processes) == 0 {
+				podsWithoutProcess++
+			}
+		}
+		if !node.ProcessesResolved {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("processes on node %s are unknown: %s", node.NodeID, node.Error))
+		}
+	}
+	if podsWithoutProcess > 0 {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("commandRegex %q matches no process in %d of the matched pods", strategy.CommandRegex, podsWithoutProcess))
+	}
+
+	conflicts, err := svc.previewStrategyConflicts(ctx, strategy, podIDs, podsByID, nodesByID)
+	if err != nil {
+		return nil, err
+	}
+	preview.Conflicts = conflicts
+	return preview, nil
+}
+
+// previewPodProcesses asks the decision maker of every node for its pod-PID
+// mapping and returns the processes by pod UID. Nodes whose decision maker
+// cannot be reached are marked unresolved.
+func (svc *Service) previewPodProcesses(ctx context.Context, nodes []*domain.StrategyPreviewNode) map[string][]domain.PodProcess {
+	nodeIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.NodeID)
+	}
+	dms, err := svc.queryDecisionMakers(ctx, &domain.QueryDecisionMakerPodsOptions{
+		NodeIDs: nodeIDs,
+	})
+	if err != nil {
+		for _, node := range nodes {
+			node.Error = fmt.Sprintf("query decision maker pods: %v", err)
+		}
+		return nil
+	}
+	dmByNode := make(map[string]*domain.DecisionMakerPod, len(dms))
+	for _, dm := range dms {
+		dmByNode[dm.NodeID] = dm
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		processes = make(map[string][]domain.PodProcess)
+	)
+	for _, node := range nodes {
+		dm, ok := dmByNode[node.NodeID]
+		if !ok {
+			node.Error = "no decision maker pod found on the node"
+			continue
+		}
+		if dm.State != domain.NodeStateOnline {
+			node.Error = fmt.Sprintf("decision maker is not online (state: %d)", dm.State)
+			continue
+		}
+		wg.Add(1)
+		go func(node *domain.StrategyPreviewNode, dm *domain.DecisionMakerPod) {
+			defer wg.Done()
+			mapping, err := svc.DMAdapter.GetPodPIDMapping(ctx, dm)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				node.Error = fmt.Sprintf("get pod-pid mapping from decision maker: %v", err)
+				return
+			}
+			node.ProcessesResolved = true
+			for _, pod := range mapping.Pods {
+				processes[pod.PodUID] = append(processes[pod.PodUID], pod.Processes...)
+			}
+		}(node, dm)
+	}
+	wg.Wait()
+	return processes
+}
+
+// previewStrategyConflicts reports the existing strategies whose intents
+// target the matched pods and the node scheduling policies whose command
+// regex hits their processes. On resolved nodes a strategy only conflicts when
+// both command regexes hit the same process; node policies are only checked
+// on resolved nodes since they match by process name alone.
+func (svc *Service) previewStrategyConflicts(
+	ctx context.Context,
+	strategy *domain.ScheduleStrategy,
+	podIDs []string,
+	podsByID map[string]*domain.StrategyPreviewPod,
+	nodesByID map[string]*domain.StrategyPreviewNode,
+) ([]*domain.StrategyConflict, error) {
+	intentOpt := &domain.QueryIntentOptions{PodIDs: podIDs}
+	if err := svc.Repo.QueryIntents(ctx, intentOpt); err != nil {
+		return nil, fmt.Errorf("query intents of matched pods: %w", err)
+	}
+
+	rank := strategyRank(strategy)
+	type conflictKey struct {
+		strategyID bson.ObjectID
+		podID      string
+	}
+	seen := make(map[conflictKey]struct{})
+	var conflicts []*domain.StrategyConflict
+	var strategyIDs []bson.ObjectID
+	strategySeen := make(map[bson.ObjectID]struct{})
+	for _, intent := range intentOpt.Result {
+		if intent.StrategyID.IsZero() || intent.StrategyID == strategy.ID {
+			continue
+		}
+		pod, ok := podsByID[intent.PodID]
+		if !ok {
+			continue
+		}
+		key := conflictKey{strategyID: intent.StrategyID, podID: intent.PodID}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		conflict := &domain.StrategyConflict{
+			StrategyID:    intent.StrategyID,
+			CommandRegex:  intent.CommandRegex,
+			Priority:      intent.Priority,
+			ExecutionTime: intent.ExecutionTime,
+			PodID:         intent.PodID,
+			PodName:       pod.PodName,
+			NodeID:        intent.NodeID,
+			Precedence:    intent.Precedence,
+		}
+		conflict.ExistingWins, conflict.Reason = intentprecedence.Outranks(scheduleIntentRank(intent), rank)
+		if node := nodesByID[intent.NodeID]; node != nil && node.ProcessesResolved {
+			conflict.PIDs = matchingPIDs(intent.CommandRegex, pod.Processes)
+			if len(conflict.PIDs) == 0 {
+				continue
+			}
+		}
+		if _, ok := strategySeen[intent.StrategyID]; !ok {
+			strategySeen[intent.StrategyID] = struct{}{}
+			strategyIDs = append(strategyIDs, intent.StrategyID)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	nodeConflicts, err := svc.previewNodePolicyConflicts(ctx, rank, podsByID, nodesByID)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, nodeConflicts...)
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+
+	if len(strategyIDs) > 0 {
+		strategyOpt := &domain.QueryStrategyOptions{IDs: strategyIDs}
+		if err := svc.Repo.QueryStrategies(ctx, strategyOpt); err != nil {
+			return nil, fmt.Errorf("query conflicting strategies: %w", err)
+		}
+		namespaces := make(map[bson.ObjectID]string, len(strategyOpt.Result))
+		for _, s := range strategyOpt.Result {
+			namespaces[s.ID] = s.StrategyNamespace
+		}
+		for _, conflict := range conflicts {
+			conflict.StrategyNamespace = namespaces[conflict.StrategyID]
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].StrategyID != conflicts[j].StrategyID {
+			return conflicts[i].StrategyID.Hex() < conflicts[j].StrategyID.Hex()
+		}
+		if conflicts[i].NodePolicyID != conflicts[j].NodePolicyID {
+			return conflicts[i].NodePolicyID.Hex() < conflicts[j].NodePolicyID.Hex()
+		}
+		return conflicts[i].PodName < conflicts[j].PodName
+	})
+	return conflicts, nil
+}
+
+// previewNodePolicyConflicts reports the node scheduling policies of the
+// resolved nodes whose command regex hits a matched process.
+func (svc *Service) previewNodePolicyConflicts(
+	ctx context.Context,
+	rank intentprecedence.Rank,
+	podsByID map[string]*domain.StrategyPreviewPod,
+	nodesByID map[string]*domain.StrategyPreviewNode,
+) ([]*domain.StrategyConflict, error) {
+	var nodeIDs []string
+	for nodeID, node := range nodesByID {
+		if node.ProcessesResolved {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+	nodeIntentOpt := &domain.QueryNodeIntentOptions{NodeIDs: nodeIDs}
+	if err := svc.Repo.QueryNodeIntents(ctx, nodeIntentOpt); err != nil {
+		return nil, fmt.Errorf("query node intents of matched nodes: %w", err)
+	}
+
+	var conflicts []*domain.StrategyConflict
+	for _, nodeIntent := range nodeIntentOpt.Result {
+		node, ok := nodesByID[nodeIntent.NodeID]
+		if !ok || !node.ProcessesResolved {
+			continue
+		}
+		existingWins, reason := intentprecedence.Outranks(nodeSchedulingIntentRank(nodeIntent), rank)
+		for _, pod := range node.Pods {
+			pids := matchingPIDs(nodeIntent.CommandRegex, podsByID[pod.PodID].Processes)
+			if len(pids) == 0 {
+				continue
+			}
+			conflicts = append(conflicts, &domain.StrategyConflict{
+				NodePolicyID:  nodeIntent.PolicyID,
+				CommandRegex:  nodeIntent.CommandRegex,
+				Priority:      nodeIntent.Priority,
+				ExecutionTime: nodeIntent.ExecutionTime,
+				PodID:         pod.PodID,
+				PodName:       pod.PodName,
+				NodeID:        nodeIntent.NodeID,
+				PIDs:          pids,
+				Precedence:    nodeIntent.Precedence,
+				ExistingWins:  existingWins,
+				Reason:        reason,
+			})
+		}
+	}
+	return conflicts, nil
+}
+
+// matchingPIDs returns the processes the command regex hits. An invalid
+// regex hits nothing, as on the decision makers.
+func matchingPIDs(commandRegex string, processes []domain.PodProcess) []int {
+	re, err := regexp.Compile(commandRegex)
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, process := range processes {
+		if re.MatchString(process.Command) {
+			pids = append(pids, process.PID)
+		}
+	}
+	return pids
+}
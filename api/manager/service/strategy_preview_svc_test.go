@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/intentprecedence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakePreviewRepo struct {
+	domain.Repository
+	strategies  []*domain.ScheduleStrategy
+	intents     []*domain.ScheduleIntent
+	nodeIntents []*domain.NodeSchedulingIntent
+}
+
+func (r *fakePreviewRepo) QueryNodeIntents(_ context.Context, opt *domain.QueryNodeIntentOptions) error {
+	for _, intent := range r.nodeIntents {
+		for _, nodeID := range opt.NodeIDs {
+			if intent.NodeID == nodeID {
+				opt.Result = append(opt.Result, intent)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakePreviewRepo) QueryIntents(_ context.Context, opt *domain.QueryIntentOptions) error {
+	for _, intent := range r.intents {
+		for _, podID := range opt.PodIDs {
+			if intent.PodID == podID {
+				opt.Result = append(opt.Result, intent)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakePreviewRepo) QueryStrategies(_ context.Context, opt *domain.QueryStrategyOptions) error {
+	for _, strategy := range r.strategies {
+		for _, id := range opt.IDs {
+			if strategy.ID == id {
+				opt.Result = append(opt.Result, strategy)
+			}
+		}
+	}
+	return nil
+}
+
+func TestPreviewScheduleStrategyResolvesProcessesAndConflicts(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockDM := domain.NewMockDecisionMakerAdapter(t)
+	existing := &domain.ScheduleStrategy{BaseEntity: domain.BaseEntity{ID: bson.NewObjectID()}, StrategyNamespace: "batch"}
+	self := bson.NewObjectID()
+	repo := &fakePreviewRepo{
+		Repository: newDMRegistryMockRepo(t),
+		strategies: []*domain.ScheduleStrategy{existing},
+		intents: []*domain.ScheduleIntent{
+			{StrategyID: existing.ID, PodID: "uid-a", NodeID: "node-a", CommandRegex: "nginx: worker", Priority: 5},
+			{StrategyID: existing.ID, PodID: "uid-b", NodeID: "node-a", CommandRegex: "sidecar"},
+			{StrategyID: existing.ID, PodID: "uid-c", NodeID: "node-b", CommandRegex: "nginx"},
+			{StrategyID: self, PodID: "uid-a", NodeID: "node-a", CommandRegex: "nginx"},
+		},
+	}
+
+	mockK8S.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{
+		{Name: "web-a", K8SNamespace: "default", PodID: "uid-a", NodeID: "node-a"},
+		{Name: "web-b", K8SNamespace: "default", PodID: "uid-b", NodeID: "node-a"},
+		{Name: "web-c", K8SNamespace: "default", PodID: "uid-c", NodeID: "node-b"},
+	}, nil).Once()
+	dmA := &domain.DecisionMakerPod{NodeID: "node-a", Host: "10.0.0.1", State: domain.NodeStateOnline}
+	dmB := &domain.DecisionMakerPod{NodeID: "node-b", Host: "10.0.0.2", State: domain.NodeStateOnline}
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{dmA, dmB}, nil).Once()
+	mockDM.EXPECT().GetPodPIDMapping(mock.Anything, dmA).Return(&domain.PodPIDMappingResponse{Pods: []domain.PodPIDInfo{
+		{PodUID: "uid-a", Processes: []domain.PodProcess{{PID: 1, Command: "pause"}, {PID: 10, Command: "nginx: master"}, {PID: 11, Command: "nginx: worker"}}},
+		{PodUID: "uid-b", Processes: []domain.PodProcess{{PID: 20, Command: "nginx: master"}, {PID: 21, Command: "sidecar"}}},
+	}}, nil).Once()
+	mockDM.EXPECT().GetPodPIDMapping(mock.Anything, dmB).Return(nil, errors.New("connection refused")).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, DMAdapter: mockDM, Repo: repo}
+	preview, err := svc.PreviewScheduleStrategy(context.Background(), &domain.ScheduleStrategy{
+		BaseEntity:   domain.BaseEntity{ID: self},
+		K8sNamespace: []string{"default"},
+		CommandRegex: "nginx",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, preview.MatchedPods)
+	assert.Equal(t, 3, preview.MatchedProcesses, "pause is never matched")
+	require.Len(t, preview.Nodes, 2)
+	nodeA, nodeB := preview.Nodes[0], preview.Nodes[1]
+	assert.True(t, nodeA.ProcessesResolved)
+	require.Len(t, nodeA.Pods, 2)
+	assert.Equal(t, []domain.PodProcess{{PID: 10, Command: "nginx: master"}, {PID: 11, Command: "nginx: worker"}}, nodeA.Pods[0].Processes)
+	assert.False(t, nodeB.ProcessesResolved)
+	assert.Contains(t, nodeB.Error, "connection refused")
+
+	// uid-a overlaps on the worker, uid-b targets a different process and
+	// uid-c cannot be checked; the previewed strategy's own intent is skipped.
+	require.Len(t, preview.Conflicts, 2)
+	assert.Equal(t, "web-a", preview.Conflicts[0].PodName)
+	assert.Equal(t, []int{11}, preview.Conflicts[0].PIDs)
+	assert.Equal(t, "batch", preview.Conflicts[0].StrategyNamespace)
+	assert.False(t, preview.Conflicts[0].ExistingWins, "the previewed strategy is more specific")
+	assert.Equal(t, intentprecedence.ReasonSpecificity, preview.Conflicts[0].Reason)
+	assert.Equal(t, "web-c", preview.Conflicts[1].PodName)
+	assert.Empty(t, preview.Conflicts[1].PIDs)
+	assert.Len(t, preview.Warnings, 1)
+}
+
+func TestPreviewScheduleStrategyReportsNodePolicyConflicts(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockDM := domain.NewMockDecisionMakerAdapter(t)
+	policyID := bson.NewObjectID()
+	repo := &fakePreviewRepo{
+		Repository: newDMRegistryMockRepo(t),
+		nodeIntents: []*domain.NodeSchedulingIntent{
+			{PolicyID: policyID, NodeID: "node-a", CommandRegex: "^nginx", Priority: 7, Precedence: 5},
+			{PolicyID: policyID, NodeID: "node-a", CommandRegex: "^kworker"},
+		},
+	}
+
+	mockK8S.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{
+		{Name: "web-a", K8SNamespace: "default", PodID: "uid-a", NodeID: "node-a"},
+	}, nil).Once()
+	dmA := &domain.DecisionMakerPod{NodeID: "node-a", Host: "10.0.0.1", State: domain.NodeStateOnline}
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{dmA}, nil).Once()
+	mockDM.EXPECT().GetPodPIDMapping(mock.Anything, dmA).Return(&domain.PodPIDMappingResponse{Pods: []domain.PodPIDInfo{
+		{PodUID: "uid-a", Processes: []domain.PodProcess{{PID: 10, Command: "nginx: master"}, {PID: 11, Command: "nginx: worker"}}},
+	}}, nil).Once()
+
+	svc := &Service{K8SAdapter: mockK8S, DMAdapter: mockDM, Repo: repo}
+	preview, err := svc.PreviewScheduleStrategy(context.Background(), &domain.ScheduleStrategy{
+		K8sNamespace: []string{"default"},
+		CommandRegex: "worker",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, preview.Conflicts, 1)
+	conflict := preview.Conflicts[0]
+	assert.Equal(t, policyID, conflict.NodePolicyID)
+	assert.True(t, conflict.StrategyID.IsZero())
+	assert.Equal(t, []int{11}, conflict.PIDs)
+	assert.True(t, conflict.ExistingWins, "explicit precedence beats pod-level")
+	assert.Equal(t, intentprecedence.ReasonPrecedence, conflict.Reason)
+}
+
+func TestPreviewScheduleStrategyWarnsWhenNothingMatches(t *testing.T) {
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockDM := domain.NewMockDecisionMakerAdapter(t)
+
+	mockK8S.EXPECT().QueryPods(mock.Anything, mock.Anything).Return(nil, nil).Once()
+	svc := &Service{K8SAdapter: mockK8S, DMAdapter: mockDM, Repo: &fakePreviewRepo{Repository: newDMRegistryMockRepo(t)}}
+	preview, err := svc.PreviewScheduleStrategy(context.Background(), &domain.ScheduleStrategy{CommandRegex: "nginx"})
+	require.NoError(t, err)
+	assert.Zero(t, preview.MatchedPods)
+	assert.Equal(t, []string{"no pods match the strategy criteria"}, preview.Warnings)
+
+	pod := &domain.Pod{Name: "web-a", PodID: "uid-a", NodeID: "node-a"}
+	dm := &domain.DecisionMakerPod{NodeID: "node-a", State: domain.NodeStateOnline}
+	mockK8S.EXPECT().QueryPods(mock.Anything, mock.Anything).Return([]*domain.Pod{pod}, nil).Once()
+	mockK8S.EXPECT().QueryDecisionMakerPods(mock.Anything, mock.Anything).Return([]*domain.DecisionMakerPod{dm}, nil).Once()
+	mockDM.EXPECT().GetPodPIDMapping(mock.Anything, dm).Return(&domain.PodPIDMappingResponse{Pods: []domain.PodPIDInfo{
+		{PodUID: "uid-a", Processes: []domain.PodProcess{{PID: 10, Command: "redis-server"}}},
+	}}, nil).Once()
+	preview, err = svc.PreviewScheduleStrategy(context.Background(), &domain.ScheduleStrategy{CommandRegex: "nginx"})
+	require.NoError(t, err)
+	assert.Zero(t, preview.MatchedProcesses)
+	require.Len(t, preview.Warnings, 1)
+	assert.Contains(t, preview.Warnings[0], "matches no process in 1 of the matched pods")
+}
+
+func TestPreviewScheduleStrategyRejectsInvalidRegex(t *testing.T) {
+	svc := &Service{}
+	_, err := svc.PreviewScheduleStrategy(context.Background(), &domain.ScheduleStrategy{CommandRegex: "("})
+	httpErr, ok := errs.IsHTTPStatusError(err)
+	require.True(t, ok)
+	assert.Equal(t, 400, httpErr.StatusCode)
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/manager/errs"
+	"github.com/Gthulhu/api/pkg/logger"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// recommendationRejectionTTL is how long a rejected recommendation keeps
+	// the same action from being proposed again for its pod.
+	recommendationRejectionTTL = 24 * time.Hour
+	// recommendationStaleAfter retires pending recommendations of pods the
+	// classifier has not reported for this long. It matches the classifier
+	// pod TTL so a manager restart does not discard drafts.
+	recommendationStaleAfter = 30 * time.Minute
+
+	recommendedMaxPriority      = 10
+	recommendedMinExecutionTime = int64(time.Millisecond)
+	recommendedMaxExecutionTime = int64(20 * time.Millisecond)
+)
+
+// recommendationVolatileLabels change with every rollout of a workload and are
+// left out of recommended label selectors so strategies survive new replicas.
+var recommendationVolatileLabels = []string{"pod-template-hash", "controller-revision-hash", "pod-template-generation"}
+
+type strategyRecommendationRepository interface {
+	CreateStrategyRecommendation(ctx context.Context, rec *domain.StrategyRecommendation) error
+	UpdateStrategyRecommendation(ctx context.Context, rec *domain.StrategyRecommendation) error
+	QueryStrategyRecommendations(ctx context.Context, opt *domain.QueryStrategyRecommendationOptions) error
+	UpsertRecommendationAutoApplyPolicy(ctx context.Context, policy *domain.RecommendationAutoApplyPolicy) error
+	QueryRecommendationAutoApplyPolicies(ctx context.Context, opt *domain.QueryRecommendationAutoApplyPolicyOptions) error
+}
+
+func (svc *Service) getRecommendationRepo() (strategyRecommendationRepository, error) {
+	repo, ok := svc.Repo.(strategyRecommendationRepository)
+	if !ok {
+		return nil, errs.NewHTTPStatusError(http.StatusNotImplemented, "strategy recommendation repository is not enabled", nil)
+	}
+	return repo, nil
+}
+
+// deriveRecommendedStrategy turns a classifier profile into strategy values.
+// raise_priority scales the priority with the preemption rate and sizes the
+// time slice so a typical run finishes without being preempted.
+func deriveRecommendedStrategy(c *domain.PodClassification) (priority int, executionTime int64) {
+	switch c.Action {
+	case domain.RecommendationActionRaisePriority:
+		priority = int(math.Ceil(c.InvolCtxRatio * recommendedMaxPriority))
+		priority = max(1, min(priority, recommendedMaxPriority))
+		executionTime = int64(c.CPUPerRunNS * 1.5)
+	}
+	executionTime = max(recommendedMinExecutionTime, min(executionTime, recommendedMaxExecutionTime))
+	return priority, executionTime
+}
+
+func recommendationLabelSelectors(pod *domain.Pod) []domain.LabelSelector {
+	selectors := make([]domain.LabelSelector, 0, len(pod.Labels))
+	for _, selector := range pod.LabelsToSelectors() {
+		if !slices.Contains(recommendationVolatileLabels, selector.Key) {
+			selectors = append(selectors, selector)
+		}
+	}
+	sort.Slice(selectors, func(i, j int) bool { return selectors[i].Key < selectors[j].Key })
+	return selectors
+}
+
+func labelSelectorsKey(selectors []domain.LabelSelector) string {
+	parts := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		parts = append(parts, selector.Key+"="+selector.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func podKey(namespace, pod string) string {
+	return namespace + "/" + pod
+}
+
+// recommendationSync holds the state of one SyncStrategyRecommendations pass.
+type recommendationSync struct {
+	svc      *Service
+	repo     strategyRecommendationRepository
+	now      int64
+	policies map[string]*domain.RecommendationAutoApplyPolicy
+	// open holds pending and applied recommendations by pod.
+	open map[string][]*domain.StrategyRecommendation
+	// recent holds rejected and reverted recommendations by pod.
+	recent map[string][]*domain.StrategyRecommendation
+	pods   map[string][]*domain.Pod
+}
+
+// SyncStrategyRecommendations turns stable classifier recommendations into
+// draft strategies, applies them in namespaces that opted into auto-apply and
+// reverts auto-applied strategies whose pods keep drifting. It is called by
+// the classifier feeder after every feed. Only recommendations in namespaces
+// owns reports are reconciled, since the classifications of the others are
+// held by the replicas owning their classifier shards; a nil owns owns every
+// namespace.
+func (svc *Service) SyncStrategyRecommendations(ctx context.Context, classifications []*domain.PodClassification, owns func(namespace string) bool) error {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return err
+	}
+	rs := &recommendationSync{
+		svc:      svc,
+		repo:     repo,
+		now:      time.Now().UnixMilli(),
+		policies: map[string]*domain.RecommendationAutoApplyPolicy{},
+		open:     map[string][]*domain.StrategyRecommendation{},
+		recent:   map[string][]*domain.StrategyRecommendation{},
+		pods:     map[string][]*domain.Pod{},
+	}
+	if err := rs.load(ctx, owns); err != nil {
+		return err
+	}
+
+	byPod := make(map[string]*domain.PodClassification, len(classifications))
+	for _, c := range classifications {
+		if c != nil {
+			byPod[podKey(c.Namespace, c.Pod)] = c
+		}
+	}
+	for key, recs := range rs.open {
+		for _, rec := range recs {
+			rs.reconcileOpen(ctx, rec, byPod[key])
+		}
+	}
+	for _, c := range classifications {
+		if c != nil {
+			rs.propose(ctx, c)
+		}
+	}
+	return nil
+}
+
+func (s *recommendationSync) load(ctx context.Context, owns func(namespace string) bool) error {
+	policyOpt := &domain.QueryRecommendationAutoApplyPolicyOptions{}
+	if err := s.repo.QueryRecommendationAutoApplyPolicies(ctx, policyOpt); err != nil {
+		return err
+	}
+	history := recommendationRejectionTTL.Milliseconds()
+	for _, policy := range policyOpt.Result {
+		s.policies[policy.Namespace] = policy
+		history = max(history, policy.CooldownSeconds*1000)
+	}
+
+	openOpt := &domain.QueryStrategyRecommendationOptions{
+		States: []domain.RecommendationState{domain.RecommendationStatePending, domain.RecommendationStateApplied},
+	}
+	if err := s.repo.QueryStrategyRecommendations(ctx, openOpt); err != nil {
+		return err
+	}
+	for _, rec := range openOpt.Result {
+		if owns != nil && !owns(rec.Namespace) {
+			continue
+		}
+		key := podKey(rec.Namespace, rec.Pod)
+		s.open[key] = append(s.open[key], rec)
+	}
+
+	recentOpt := &domain.QueryStrategyRecommendationOptions{
+		States:       []domain.RecommendationState{domain.RecommendationStateRejected, domain.RecommendationStateReverted},
+		UpdatedAfter: s.now - history,
+	}
+	if err := s.repo.QueryStrategyRecommendations(ctx, recentOpt); err != nil {
+		return err
+	}
+	for _, rec := range recentOpt.Result {
+		key := podKey(rec.Namespace, rec.Pod)
+		s.recent[key] = append(s.recent[key], rec)
+	}
+	return nil
+}
+
+// workloadCovered reports whether a pending or applied recommendation already
+// targets the same label selectors, so replicas of a workload share one.
+func (s *recommendationSync) workloadCovered(namespace string, selectors []domain.LabelSelector) bool {
+	key := labelSelectorsKey(selectors)
+	for _, recs := range s.open {
+		for _, rec := range recs {
+			if rec.Namespace != namespace || (rec.State != domain.RecommendationStatePending && rec.State != domain.RecommendationStateApplied) {
+				continue
+			}
+			if labelSelectorsKey(rec.Strategy.LabelSelectors) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reconcileOpen retires pending drafts the classifier no longer supports and
+// watches auto-applied strategies for persistent drift.
+func (s *recommendationSync) reconcileOpen(ctx context.Context, rec *domain.StrategyRecommendation, c *domain.PodClassification) {
+	switch rec.State {
+	case domain.RecommendationStatePending:
+		switch {
+		case c == nil:
+			if s.now-rec.LastObservedAt < recommendationStaleAfter.Milliseconds() {
+				return
+			}
+			rec.State = domain.RecommendationStateObsolete
+			rec.Message = "pod is no longer classified"
+		case c.Stable() && c.Action != rec.Action:
+			rec.State = domain.RecommendationStateObsolete
+			rec.Message = fmt.Sprintf("classifier now recommends %s", c.Action)
+		case c.Stable():
+			rec.Strategy.Priority, rec.Strategy.ExecutionTime = deriveRecommendedStrategy(c)
+			rec.Confidence = c.Confidence
+			rec.Reason = c.Reason
+			rec.LastObservedAt = s.now
+			if s.update(ctx, rec) {
+				s.autoApply(ctx, rec)
+			}
+			return
+		default:
+			return
+		}
+		s.update(ctx, rec)
+	case domain.RecommendationStateApplied:
+		if !rec.AutoApplied || c == nil {
+			return
+		}
+		if !c.Drifting() {
+			if rec.DriftSince != 0 {
+				rec.DriftSince = 0
+				s.update(ctx, rec)
+			}
+			return
+		}
+		if rec.DriftSince == 0 {
+			rec.DriftSince = s.now
+			s.update(ctx, rec)
+			return
+		}
+		policy := s.policies[rec.Namespace]
+		if policy == nil || policy.RevertAfterDriftSeconds == 0 {
+			return
+		}
+		if s.now-rec.DriftSince < policy.RevertAfterDriftSeconds*1000 || s.inCooldown(rec.Namespace, rec.Pod, policy) {
+			return
+		}
+		s.revert(ctx, rec, policy)
+	}
+}
+
+func (s *recommendationSync) propose(ctx context.Context, c *domain.PodClassification) {
+	if !c.Stable() || !slices.Contains(domain.StrategyRecommendationActions, c.Action) {
+		return
+	}
+	key := podKey(c.Namespace, c.Pod)
+	for _, rec := range s.open[key] {
+		if rec.State == domain.RecommendationStateApplied || (rec.State == domain.RecommendationStatePending && rec.Action == c.Action) {
+			return
+		}
+	}
+	for _, rec := range s.recent[key] {
+		if rec.State == domain.RecommendationStateRejected && rec.Action == c.Action && s.now-rec.DecidedAt < recommendationRejectionTTL.Milliseconds() {
+			return
+		}
+	}
+
+	pod, err := s.findPod(ctx, c.Namespace, c.Pod)
+	if err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("strategy recommendation: query pods of namespace %s failed", c.Namespace)
+		return
+	}
+	if pod == nil {
+		return
+	}
+	selectors := recommendationLabelSelectors(pod)
+	if len(selectors) == 0 || s.workloadCovered(c.Namespace, selectors) {
+		return
+	}
+
+	rec := &domain.StrategyRecommendation{
+		Namespace:      c.Namespace,
+		Pod:            c.Pod,
+		Action:         c.Action,
+		Reason:         c.Reason,
+		Confidence:     c.Confidence,
+		State:          domain.RecommendationStatePending,
+		Strategy:       domain.RecommendedStrategy{LabelSelectors: selectors},
+		LastObservedAt: s.now,
+	}
+	rec.Strategy.Priority, rec.Strategy.ExecutionTime = deriveRecommendedStrategy(c)
+	if err := s.repo.CreateStrategyRecommendation(ctx, rec); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("strategy recommendation: create %s for %s failed", c.Action, key)
+		return
+	}
+	s.open[key] = append(s.open[key], rec)
+	logger.Logger(ctx).Info().Msgf("strategy recommendation: proposed %s for %s", c.Action, key)
+	s.autoApply(ctx, rec)
+}
+
+// autoApply applies a pending recommendation if its namespace opted in and
+// the pod is out of cooldown.
+func (s *recommendationSync) autoApply(ctx context.Context, rec *domain.StrategyRecommendation) {
+	policy := s.policies[rec.Namespace]
+	if policy == nil || !policy.Enabled || s.inCooldown(rec.Namespace, rec.Pod, policy) {
+		return
+	}
+	operator := &domain.Claims{UID: policy.OwnerID}
+	if _, err := s.svc.applyStrategyRecommendation(ctx, s.repo, operator, rec, policy.MaxPriority, true); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("strategy recommendation: auto-apply %s for %s/%s failed", rec.Action, rec.Namespace, rec.Pod)
+		return
+	}
+	logger.Logger(ctx).Info().Msgf("strategy recommendation: auto-applied %s for %s/%s as strategy %s", rec.Action, rec.Namespace, rec.Pod, rec.StrategyID)
+}
+
+// inCooldown reports whether an automatic or manual decision was taken on
+// the pod within the cooldown of the namespace policy.
+func (s *recommendationSync) inCooldown(namespace, pod string, policy *domain.RecommendationAutoApplyPolicy) bool {
+	if policy.CooldownSeconds == 0 {
+		return false
+	}
+	key := podKey(namespace, pod)
+	for _, rec := range slices.Concat(s.open[key], s.recent[key]) {
+		if rec.DecidedAt > 0 && s.now-rec.DecidedAt < policy.CooldownSeconds*1000 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *recommendationSync) findPod(ctx context.Context, namespace, name string) (*domain.Pod, error) {
+	pods, ok := s.pods[namespace]
+	if !ok {
+		var err error
+		pods, err = s.svc.K8SAdapter.QueryPods(ctx, &domain.QueryPodsOptions{K8SNamespace: []string{namespace}})
+		if err != nil {
+			return nil, err
+		}
+		s.pods[namespace] = pods
+	}
+	for _, pod := range pods {
+		if pod.Name == name {
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *recommendationSync) revert(ctx context.Context, rec *domain.StrategyRecommendation, policy *domain.RecommendationAutoApplyPolicy) {
+	before := auditSnapshot(ctx, rec)
+	operator := &domain.Claims{UID: policy.OwnerID}
+	if err := s.svc.DeleteScheduleStrategy(ctx, operator, rec.StrategyID); err != nil {
+		if httpErr, ok := errs.IsHTTPStatusError(err); !ok || httpErr.StatusCode != http.StatusNotFound {
+			logger.Logger(ctx).Warn().Err(err).Msgf("strategy recommendation: revert strategy %s of %s/%s failed", rec.StrategyID, rec.Namespace, rec.Pod)
+			return
+		}
+	}
+	rec.State = domain.RecommendationStateReverted
+	rec.Message = fmt.Sprintf("pod kept drifting for %ds after the strategy was applied", (s.now-rec.DriftSince)/1000)
+	rec.DecidedBy = "system"
+	rec.DecidedAt = s.now
+	if s.update(ctx, rec) {
+		s.svc.recordAudit(ctx, "", domain.AuditActionRecommendationRevert, domain.AuditResourceRecommendation, rec.ID.Hex(), before, rec)
+	}
+}
+
+func (s *recommendationSync) update(ctx context.Context, rec *domain.StrategyRecommendation) bool {
+	if err := s.repo.UpdateStrategyRecommendation(ctx, rec); err != nil {
+		logger.Logger(ctx).Warn().Err(err).Msgf("strategy recommendation: update %s failed", rec.ID.Hex())
+		return false
+	}
+	return true
+}
+
+// applyStrategyRecommendation creates the recommended strategy on behalf of
+// operator. A positive maxPriority caps the strategy priority.
+func (svc *Service) applyStrategyRecommendation(ctx context.Context, repo strategyRecommendationRepository, operator *domain.Claims, rec *domain.StrategyRecommendation, maxPriority int, auto bool) (*domain.StrategyRecommendation, error) {
+	before := auditSnapshot(ctx, rec)
+	strategy := rec.ToScheduleStrategy()
+	if maxPriority > 0 && strategy.Priority > maxPriority {
+		strategy.Priority = maxPriority
+	}
+	if err := svc.CreateScheduleStrategy(ctx, operator, strategy); err != nil {
+		return nil, err
+	}
+	rec.State = domain.RecommendationStateApplied
+	rec.StrategyID = strategy.ID.Hex()
+	rec.Strategy.Priority = strategy.Priority
+	rec.AutoApplied = auto
+	rec.DriftSince = 0
+	rec.DecidedBy = operatorUID(operator)
+	rec.DecidedAt = time.Now().UnixMilli()
+	if err := recommendationUpdateError(repo.UpdateStrategyRecommendation(ctx, rec)); err != nil {
+		return nil, err
+	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionRecommendationApply, domain.AuditResourceRecommendation, rec.ID.Hex(), before, rec)
+	return rec, nil
+}
+
+func (svc *Service) ListStrategyRecommendations(ctx context.Context, opt *domain.QueryStrategyRecommendationOptions) error {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return err
+	}
+	return repo.QueryStrategyRecommendations(ctx, opt)
+}
+
+func (svc *Service) GetStrategyRecommendation(ctx context.Context, recommendationID string) (*domain.StrategyRecommendation, error) {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return nil, err
+	}
+	return getStrategyRecommendation(ctx, repo, recommendationID)
+}
+
+func getStrategyRecommendation(ctx context.Context, repo strategyRecommendationRepository, recommendationID string) (*domain.StrategyRecommendation, error) {
+	objID, err := bson.ObjectIDFromHex(recommendationID)
+	if err != nil {
+		return nil, errs.NewHTTPStatusError(http.StatusUnprocessableEntity, "invalid recommendation ID", fmt.Errorf("invalid recommendation ID %s: %v", recommendationID, err))
+	}
+	queryOpt := &domain.QueryStrategyRecommendationOptions{IDs: []bson.ObjectID{objID}}
+	if err := repo.QueryStrategyRecommendations(ctx, queryOpt); err != nil {
+		return nil, err
+	}
+	if len(queryOpt.Result) == 0 {
+		return nil, errs.NewHTTPStatusError(http.StatusNotFound, "strategy recommendation not found", nil)
+	}
+	return queryOpt.Result[0], nil
+}
+
+// ApproveStrategyRecommendation creates the drafted strategy on behalf of the
+// approving operator.
+func (svc *Service) ApproveStrategyRecommendation(ctx context.Context, operator *domain.Claims, recommendationID string) (*domain.StrategyRecommendation, error) {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return nil, err
+	}
+	rec, err := getStrategyRecommendation(ctx, repo, recommendationID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.State != domain.RecommendationStatePending {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "only a pending recommendation can be approved", fmt.Errorf("recommendation %s is %s", recommendationID, rec.State))
+	}
+	return svc.applyStrategyRecommendation(ctx, repo, operator, rec, 0, false)
+}
+
+// RejectStrategyRecommendation declines a pending recommendation. The same
+// action is not proposed again for the pod until the rejection expires.
+func (svc *Service) RejectStrategyRecommendation(ctx context.Context, operator *domain.Claims, recommendationID string, message string) (*domain.StrategyRecommendation, error) {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return nil, err
+	}
+	rec, err := getStrategyRecommendation(ctx, repo, recommendationID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.State != domain.RecommendationStatePending {
+		return nil, errs.NewHTTPStatusError(http.StatusConflict, "only a pending recommendation can be rejected", fmt.Errorf("recommendation %s is %s", recommendationID, rec.State))
+	}
+	before := auditSnapshot(ctx, rec)
+	rec.State = domain.RecommendationStateRejected
+	rec.Message = message
+	rec.DecidedBy = operatorUID(operator)
+	rec.DecidedAt = time.Now().UnixMilli()
+	if err := recommendationUpdateError(repo.UpdateStrategyRecommendation(ctx, rec)); err != nil {
+		return nil, err
+	}
+	svc.recordAudit(ctx, operatorUID(operator), domain.AuditActionRecommendationReject, domain.AuditResourceRecommendation, recommendationID, before, rec)
+	return rec, nil
+}
+
+func (svc *Service) ListRecommendationAutoApplyPolicies(ctx context.Context, opt *domain.QueryRecommendationAutoApplyPolicyOptions) error {
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return err
+	}
+	return repo.QueryRecommendationAutoApplyPolicies(ctx, opt)
+}
+
+// SetRecommendationAutoApplyPolicy stores the auto-apply policy of a
+// namespace. The operator becomes the owner of strategies applied under it.
+func (svc *Service) SetRecommendationAutoApplyPolicy(ctx context.Context, operator *domain.Claims, policy *domain.RecommendationAutoApplyPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "invalid auto-apply policy", err)
+	}
+	if _, err := operator.GetBsonObjectUID(); err != nil {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, "invalid operator ID", err)
+	}
+	repo, err := svc.getRecommendationRepo()
+	if err != nil {
+		return err
+	}
+	queryOpt := &domain.QueryRecommendationAutoApplyPolicyOptions{Namespaces: []string{policy.Namespace}}
+	if err := repo.QueryRecommendationAutoApplyPolicies(ctx, queryOpt); err != nil {
+		return err
+	}
+	var before *domain.RecommendationAutoApplyPolicy
+	if len(queryOpt.Result) > 0 {
+		before = queryOpt.Result[0]
+	}
+	policy.OwnerID = operator.UID
+	if err := repo.UpsertRecommendationAutoApplyPolicy(ctx, policy); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionRecommendationAutoApplySet, domain.AuditResourceRecommendation, policy.Namespace, before, policy)
+	return nil
+}
+
+func recommendationUpdateError(err error) error {
+	if errors.Is(err, domain.ErrConflict) {
+		return errs.NewHTTPStatusError(http.StatusConflict, "strategy recommendation was modified concurrently, retry", err)
+	}
+	return err
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Gthulhu/api/manager/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeRecommendationRepo keeps recommendations, policies and strategies in
+// memory. Strategies are stored without intents.
+type fakeRecommendationRepo struct {
+	domain.Repository
+	recs       map[bson.ObjectID]*domain.StrategyRecommendation
+	policies   map[string]*domain.RecommendationAutoApplyPolicy
+	strategies map[bson.ObjectID]*domain.ScheduleStrategy
+	audits     []*domain.AuditLog
+}
+
+func newFakeRecommendationRepo(t *testing.T) *fakeRecommendationRepo {
+	return &fakeRecommendationRepo{
+		Repository: newDMRegistryMockRepo(t),
+		recs:       map[bson.ObjectID]*domain.StrategyRecommendation{},
+		policies:   map[string]*domain.RecommendationAutoApplyPolicy{},
+		strategies: map[bson.ObjectID]*domain.ScheduleStrategy{},
+	}
+}
+
+func (r *fakeRecommendationRepo) CreateStrategyRecommendation(_ context.Context, rec *domain.StrategyRecommendation) error {
+	rec.ID = bson.NewObjectID()
+	rec.Revision = 1
+	rec.UpdatedAt = time.Now().UnixMilli()
+	r.recs[rec.ID] = rec
+	return nil
+}
+
+func (r *fakeRecommendationRepo) UpdateStrategyRecommendation(_ context.Context, rec *domain.StrategyRecommendation) error {
+	rec.Revision++
+	rec.UpdatedAt = time.Now().UnixMilli()
+	r.recs[rec.ID] = rec
+	return nil
+}
+
+func (r *fakeRecommendationRepo) QueryStrategyRecommendations(_ context.Context, opt *domain.QueryStrategyRecommendationOptions) error {
+	for _, rec := range r.recs {
+		if len(opt.IDs) > 0 && !containsObjectID(opt.IDs, rec.ID) {
+			continue
+		}
+		if len(opt.States) > 0 && !slices.Contains(opt.States, rec.State) {
+			continue
+		}
+		if rec.UpdatedAt < opt.UpdatedAfter {
+			continue
+		}
+		opt.Result = append(opt.Result, rec)
+	}
+	return nil
+}
+
+func (r *fakeRecommendationRepo) UpsertRecommendationAutoApplyPolicy(_ context.Context, policy *domain.RecommendationAutoApplyPolicy) error {
+	r.policies[policy.Namespace] = policy
+	return nil
+}
+
+func (r *fakeRecommendationRepo) QueryRecommendationAutoApplyPolicies(_ context.Context, opt *domain.QueryRecommendationAutoApplyPolicyOptions) error {
+	for _, policy := range r.policies {
+		opt.Result = append(opt.Result, policy)
+	}
+	return nil
+}
+
+func (r *fakeRecommendationRepo) InsertStrategyAndIntents(_ context.Context, strategy *domain.ScheduleStrategy, _ []*domain.ScheduleIntent) error {
+	strategy.ID = bson.NewObjectID()
+	r.strategies[strategy.ID] = strategy
+	return nil
+}
+
+func (r *fakeRecommendationRepo) QueryStrategies(_ context.Context, opt *domain.QueryStrategyOptions) error {
+	for _, id := range opt.IDs {
+		if strategy, ok := r.strategies[id]; ok {
+			opt.Result = append(opt.Result, strategy)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRecommendationRepo) QueryIntents(_ context.Context, _ *domain.QueryIntentOptions) error {
+	return nil
+}
+
+func (r *fakeRecommendationRepo) DeleteIntentsByStrategyID(_ context.Context, _ bson.ObjectID) error {
+	return nil
+}
+
+func (r *fakeRecommendationRepo) DeleteStrategy(_ context.Context, id bson.ObjectID) error {
+	delete(r.strategies, id)
+	return nil
+}
+
+func (r *fakeRecommendationRepo) CreateAuditLog(_ context.Context, log *domain.AuditLog) error {
+	r.audits = append(r.audits, log)
+	return nil
+}
+
+func newRecommendationTestService(t *testing.T, repo *fakeRecommendationRepo) *Service {
+	t.Helper()
+	mockK8S := domain.NewMockK8SAdapter(t)
+	mockK8S.EXPECT().
+		QueryPods(mock.Anything, mock.Anything).
+		Return([]*domain.Pod{{
+			Name:         "web-7d9f-abc",
+			K8SNamespace: "team-a",
+			NodeID:       "node-a",
+			Labels:       map[string]string{"app": "web", "pod-template-hash": "7d9f"},
+		}}, nil).Maybe()
+	mockK8S.EXPECT().
+		QueryDecisionMakerPods(mock.Anything, mock.Anything).
+		Return(nil, nil).Maybe()
+	return &Service{Repo: repo, K8SAdapter: mockK8S}
+}
+
+func raisePriorityClassification() *domain.PodClassification {
+	return &domain.PodClassification{
+		Namespace:     "team-a",
+		Pod:           "web-7d9f-abc",
+		Phase:         "stable",
+		Confidence:    0.9,
+		Action:        domain.RecommendationActionRaisePriority,
+		CPUPerRunNS:   float64(4 * time.Millisecond),
+		InvolCtxRatio: 0.72,
+	}
+}
+
+func onlyRecommendation(t *testing.T, repo *fakeRecommendationRepo) *domain.StrategyRecommendation {
+	t.Helper()
+	require.Len(t, repo.recs, 1)
+	for _, rec := range repo.recs {
+		return rec
+	}
+	return nil
+}
+
+func TestDeriveRecommendedStrategy(t *testing.T) {
+	priority, executionTime := deriveRecommendedStrategy(raisePriorityClassification())
+	assert.Equal(t, 8, priority)
+	assert.Equal(t, int64(6*time.Millisecond), executionTime)
+}
+
+func TestSyncStrategyRecommendationsSkipsCPUPinning(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	svc := newRecommendationTestService(t, repo)
+
+	pinning := raisePriorityClassification()
+	pinning.Action = domain.RecommendationActionEnableCPUPinning
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{pinning}, nil))
+	assert.Empty(t, repo.recs, "no strategy can pin CPUs, so none is drafted")
+}
+
+func TestSyncStrategyRecommendationsProposesDraft(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	svc := newRecommendationTestService(t, repo)
+
+	unstable := raisePriorityClassification()
+	unstable.Phase = "warming_up"
+	keep := raisePriorityClassification()
+	keep.Pod = "other"
+	keep.Action = domain.RecommendationActionKeepCurrent
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{unstable, keep}, nil))
+	assert.Empty(t, repo.recs)
+
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{raisePriorityClassification()}, nil))
+	rec := onlyRecommendation(t, repo)
+	assert.Equal(t, domain.RecommendationStatePending, rec.State)
+	assert.Equal(t, []domain.LabelSelector{{Key: "app", Value: "web"}}, rec.Strategy.LabelSelectors)
+	assert.Equal(t, 8, rec.Strategy.Priority)
+	assert.Empty(t, repo.strategies)
+
+	// Another feed refreshes the draft instead of adding a second one.
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{raisePriorityClassification()}, nil))
+	assert.Len(t, repo.recs, 1)
+}
+
+func TestSyncStrategyRecommendationsLeavesOtherShards(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	svc := newRecommendationTestService(t, repo)
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{raisePriorityClassification()}, nil))
+	rec := onlyRecommendation(t, repo)
+	rec.LastObservedAt -= recommendationStaleAfter.Milliseconds() + 1
+
+	// A replica owning another shard does not see the pod classified.
+	notTeamA := func(namespace string) bool { return namespace != "team-a" }
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), nil, notTeamA))
+	assert.Equal(t, domain.RecommendationStatePending, rec.State)
+
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), nil, nil))
+	assert.Equal(t, domain.RecommendationStateObsolete, rec.State)
+}
+
+func TestSyncStrategyRecommendationsAutoAppliesWithGuardRails(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	owner := bson.NewObjectID().Hex()
+	repo.policies["team-a"] = &domain.RecommendationAutoApplyPolicy{
+		Namespace:               "team-a",
+		Enabled:                 true,
+		MaxPriority:             5,
+		RevertAfterDriftSeconds: 60,
+		OwnerID:                 owner,
+	}
+	svc := newRecommendationTestService(t, repo)
+
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{raisePriorityClassification()}, nil))
+	rec := onlyRecommendation(t, repo)
+	require.Equal(t, domain.RecommendationStateApplied, rec.State)
+	assert.True(t, rec.AutoApplied)
+	assert.Equal(t, owner, rec.DecidedBy)
+	require.Len(t, repo.strategies, 1)
+	strategy := repo.strategies[mustObjectID(t, rec.StrategyID)]
+	require.NotNil(t, strategy)
+	assert.Equal(t, 5, strategy.Priority, "priority is capped by the namespace policy")
+	assert.Equal(t, []string{"team-a"}, strategy.K8sNamespace)
+
+	drifting := raisePriorityClassification()
+	drifting.Phase = "drifting"
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{drifting}, nil))
+	assert.NotZero(t, rec.DriftSince)
+	assert.Len(t, repo.strategies, 1, "a single drifting feed does not revert")
+
+	rec.DriftSince -= 61_000
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{drifting}, nil))
+	assert.Equal(t, domain.RecommendationStateReverted, rec.State)
+	assert.Empty(t, repo.strategies)
+}
+
+func TestSyncStrategyRecommendationsHonorsCooldown(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	repo.policies["team-a"] = &domain.RecommendationAutoApplyPolicy{
+		Namespace:       "team-a",
+		Enabled:         true,
+		CooldownSeconds: 3600,
+		OwnerID:         bson.NewObjectID().Hex(),
+	}
+	reverted := &domain.StrategyRecommendation{
+		Namespace: "team-a",
+		Pod:       "web-7d9f-abc",
+		Action:    domain.RecommendationActionEnableCPUPinning,
+		State:     domain.RecommendationStateReverted,
+		DecidedAt: time.Now().Add(-10 * time.Minute).UnixMilli(),
+	}
+	require.NoError(t, repo.CreateStrategyRecommendation(context.Background(), reverted))
+	svc := newRecommendationTestService(t, repo)
+
+	require.NoError(t, svc.SyncStrategyRecommendations(context.Background(), []*domain.PodClassification{raisePriorityClassification()}, nil))
+	require.Len(t, repo.recs, 2)
+	for _, rec := range repo.recs {
+		if rec.ID != reverted.ID {
+			assert.Equal(t, domain.RecommendationStatePending, rec.State, "cooldown keeps the draft pending")
+		}
+	}
+	assert.Empty(t, repo.strategies)
+}
+
+func TestRejectStrategyRecommendationSuppressesProposal(t *testing.T) {
+	repo := newFakeRecommendationRepo(t)
+	svc := newRecommendationTestService(t, repo)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SyncStrategyRecommendations(ctx, []*domain.PodClassification{raisePriorityClassification()}, nil))
+	rec := onlyRecommendation(t, repo)
+	_, err := svc.RejectStrategyRecommendation(ctx, newTestClaims(t), rec.ID.Hex(), "not now")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RecommendationStateRejected, rec.State)
+
+	require.NoError(t, svc.SyncStrategyRecommendations(ctx, []*domain.PodClassification{raisePriorityClassification()}, nil))
+	assert.Len(t, repo.recs, 1)
+
+	_, err = svc.ApproveStrategyRecommendation(ctx, newTestClaims(t), rec.ID.Hex())
+	require.Error(t, err, "only pending recommendations can be approved")
+}
+
+func mustObjectID(t *testing.T, hex string) bson.ObjectID {
+	t.Helper()
+	id, err := bson.ObjectIDFromHex(hex)
+	require.NoError(t, err)
+	return id
+}
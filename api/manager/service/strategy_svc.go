@@ -18,7 +18,17 @@ func (svc *Service) CreateScheduleStrategy(ctx context.Context, operator *domain
 	if err != nil {
 		return errors.WithMessagef(err, "invalid operator ID %s", operator.UID)
 	}
+	if err := validateStrategySchedule(strategy); err != nil {
+		return err
+	}
+	if err := svc.validateClusterSelector(strategy.ClusterSelector); err != nil {
+		return err
+	}
+	if err := verifyK8SNamespacePolicy(ctx, strategy.K8sNamespace); err != nil {
+		return err
+	}
 	queryOpt := &domain.QueryPodsOptions{
+		Clusters:       strategy.ClusterSelector,
 		K8SNamespace:   strategy.K8sNamespace,
 		LabelSelectors: strategy.LabelSelectors,
 	}
@@ -32,7 +42,15 @@ func (svc *Service) CreateScheduleStrategy(ctx context.Context, operator *domain
 
 	logger.Logger(ctx).Debug().Msgf("found %d pods matching the strategy criteria", len(pods))
 
+	// Keep an ID chosen by the caller, such as one from an applied bundle.
+	strategyID := strategy.ID
 	strategy.BaseEntity = domain.NewBaseEntity(&operatorID, &operatorID)
+	strategy.ID = strategyID
+	if active, _ := strategy.Schedule.Active(time.Now()); !active {
+		// Intents are created by the reconcile loop once the strategy
+		// activates.
+		pods = nil
+	}
 
 	intents := make([]*domain.ScheduleIntent, 0, len(pods))
 	nodeIDsMap := make(map[string]struct{})
@@ -50,17 +68,16 @@ func (svc *Service) CreateScheduleStrategy(ctx context.Context, operator *domain
 	if err != nil {
 		return fmt.Errorf("insert strategy and intents into repository: %w", err)
 	}
-
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionScheduleStrategyCreate, domain.AuditResourceScheduleStrategy, strategy.ID.Hex(), nil, strategy)
+	if len(intents) == 0 {
+		logger.Logger(ctx).Info().Msgf("strategy %s is outside its activation windows, no intents created", strategy.ID.Hex())
+		return nil
 	}
 
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return err
 	}
@@ -116,6 +133,12 @@ func (svc *Service) UpdateScheduleStrategy(ctx context.Context, operator *domain
 	if err != nil {
 		return errors.WithMessagef(err, "invalid operator ID %s", operator.UID)
 	}
+	if err := validateStrategySchedule(strategy); err != nil {
+		return err
+	}
+	if err := svc.validateClusterSelector(strategy.ClusterSelector); err != nil {
+		return err
+	}
 
 	// Validate ownership and load existing strategy
 	queryOpt := &domain.QueryStrategyOptions{
@@ -129,9 +152,13 @@ func (svc *Service) UpdateScheduleStrategy(ctx context.Context, operator *domain
 		return errs.NewHTTPStatusError(http.StatusNotFound, "strategy not found or you don't have permission to update it", nil)
 	}
 	currentStrategy := queryOpt.Result[0]
+	if err := verifyK8SNamespacePolicy(ctx, currentStrategy.K8sNamespace, strategy.K8sNamespace); err != nil {
+		return err
+	}
 
 	// Query pods based on new strategy criteria before making changes
 	queryPodsOpt := &domain.QueryPodsOptions{
+		Clusters:       strategy.ClusterSelector,
 		K8SNamespace:   strategy.K8sNamespace,
 		LabelSelectors: strategy.LabelSelectors,
 	}
@@ -162,12 +189,16 @@ func (svc *Service) UpdateScheduleStrategy(ctx context.Context, operator *domain
 	if err := svc.Repo.UpdateStrategy(ctx, strategy); err != nil {
 		return fmt.Errorf("update strategy: %w", err)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionScheduleStrategyUpdate, domain.AuditResourceScheduleStrategy, strategyID, currentStrategy, strategy)
 
 	// Replace intents for the strategy
 	if err := svc.Repo.DeleteIntentsByStrategyID(ctx, strategyObjID); err != nil {
 		return fmt.Errorf("delete intents by strategy ID: %w", err)
 	}
 
+	if active, _ := strategy.Schedule.Active(time.Now()); !active {
+		pods = nil
+	}
 	intents := make([]*domain.ScheduleIntent, 0, len(pods))
 	nodeIDsMap := make(map[string]struct{})
 	nodeIDs := make([]string, 0)
@@ -201,15 +232,10 @@ func (svc *Service) UpdateScheduleStrategy(ctx context.Context, operator *domain
 			oldPodIDs = append(oldPodIDs, podID)
 		}
 
-		dmLabel := domain.LabelSelector{
-			Key:   "app",
-			Value: "decisionmaker",
-		}
 		dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-			DecisionMakerLabel: dmLabel,
-			NodeIDs:            oldNodeIDs,
+			NodeIDs: oldNodeIDs,
 		}
-		dmPods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+		dmPods, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods for update deletion notification")
 		} else if len(oldPodIDs) > 0 {
@@ -222,16 +248,16 @@ func (svc *Service) UpdateScheduleStrategy(ctx context.Context, operator *domain
 		}
 	}
 
-	// Send new intents to decision makers
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
+	if len(intents) == 0 {
+		logger.Logger(ctx).Info().Msgf("updated strategy %s, outside its activation windows so no intents created", strategyID)
+		return nil
 	}
+
+	// Send new intents to decision makers
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-		NodeIDs:            nodeIDs,
+		NodeIDs: nodeIDs,
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return err
 	}
@@ -292,6 +318,9 @@ func (svc *Service) DeleteScheduleStrategy(ctx context.Context, operator *domain
 	if len(queryOpt.Result) == 0 {
 		return errs.NewHTTPStatusError(http.StatusNotFound, "strategy not found or you don't have permission to delete it", nil)
 	}
+	if err := verifyK8SNamespacePolicy(ctx, queryOpt.Result[0].K8sNamespace); err != nil {
+		return err
+	}
 
 	// Query intents associated with this strategy to get node IDs and pod IDs for DM notification
 	intentQueryOpt := &domain.QueryIntentOptions{
@@ -329,18 +358,14 @@ func (svc *Service) DeleteScheduleStrategy(ctx context.Context, operator *domain
 	if err != nil {
 		return fmt.Errorf("delete strategy: %w", err)
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionScheduleStrategyDelete, domain.AuditResourceScheduleStrategy, strategyID, queryOpt.Result[0], nil)
 
 	// Notify decision makers to remove intents from their in-memory cache
 	if len(nodeIDs) > 0 && len(podIDs) > 0 {
-		dmLabel := domain.LabelSelector{
-			Key:   "app",
-			Value: "decisionmaker",
-		}
 		dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-			DecisionMakerLabel: dmLabel,
-			NodeIDs:            nodeIDs,
+			NodeIDs: nodeIDs,
 		}
-		dmPods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+		dmPods, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods for deletion notification")
 		} else {
@@ -411,6 +436,9 @@ func (svc *Service) DeleteScheduleIntents(ctx context.Context, operator *domain.
 		if _, ok := requestedIDs[intent.ID]; ok {
 			matchedCount++
 		}
+		if err := verifyK8SNamespacePolicy(ctx, []string{intent.K8sNamespace}); err != nil {
+			return err
+		}
 	}
 
 	if matchedCount != len(intentObjIDs) {
@@ -438,18 +466,16 @@ func (svc *Service) DeleteScheduleIntents(ctx context.Context, operator *domain.
 	if err != nil {
 		return fmt.Errorf("delete intents: %w", err)
 	}
+	for _, intent := range queryOpt.Result {
+		svc.recordAudit(ctx, operator.UID, domain.AuditActionScheduleIntentDelete, domain.AuditResourceScheduleIntent, intent.ID.Hex(), intent, nil)
+	}
 
 	// Notify decision makers to remove intents from their in-memory cache
 	if len(nodeIDs) > 0 && len(podIDs) > 0 {
-		dmLabel := domain.LabelSelector{
-			Key:   "app",
-			Value: "decisionmaker",
-		}
 		dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-			DecisionMakerLabel: dmLabel,
-			NodeIDs:            nodeIDs,
+			NodeIDs: nodeIDs,
 		}
-		dmPods, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+		dmPods, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 		if err != nil {
 			logger.Logger(ctx).Warn().Err(err).Msg("failed to query decision maker pods for deletion notification")
 		} else {
@@ -473,15 +499,10 @@ func (svc *Service) GetPodPIDMapping(ctx context.Context, nodeID string) (*domai
 		return nil, domain.ErrNoClient
 	}
 
-	dmLabel := domain.LabelSelector{
-		Key:   "app",
-		Value: "decisionmaker",
-	}
 	dmQueryOpt := &domain.QueryDecisionMakerPodsOptions{
-		DecisionMakerLabel: dmLabel,
-		NodeIDs:            []string{nodeID},
+		NodeIDs: []string{nodeID},
 	}
-	dms, err := svc.K8SAdapter.QueryDecisionMakerPods(ctx, dmQueryOpt)
+	dms, err := svc.queryDecisionMakers(ctx, dmQueryOpt)
 	if err != nil {
 		return nil, fmt.Errorf("query decision maker pods: %w", err)
 	}
@@ -507,11 +528,38 @@ func (svc *Service) GetPodPIDMapping(ctx context.Context, nodeID string) (*domai
 	return result, nil
 }
 
-// ListNodes returns all nodes in the Kubernetes cluster
+// ListNodes returns all nodes in the Kubernetes cluster with the
+// registration of their decision maker, if any. Without a Kubernetes client
+// the registered nodes are returned.
 func (svc *Service) ListNodes(ctx context.Context) ([]*domain.Node, error) {
+	registrations, err := svc.decisionMakerRegistrations(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if svc.K8SAdapter == nil {
-		return nil, domain.ErrNoClient
+		return listRegisteredNodes(registrations)
 	}
 
-	return svc.K8SAdapter.ListNodes(ctx)
+	nodes, err := svc.K8SAdapter.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string]*domain.DecisionMakerRegistration, len(registrations))
+	for _, reg := range registrations {
+		byNode[reg.NodeID] = reg
+	}
+	for _, node := range nodes {
+		node.DecisionMaker = byNode[node.Name]
+	}
+	return nodes, nil
+}
+
+func validateStrategySchedule(strategy *domain.ScheduleStrategy) error {
+	if strategy.Schedule == nil {
+		return nil
+	}
+	if err := strategy.Schedule.Validate(); err != nil {
+		return errs.NewHTTPStatusError(http.StatusBadRequest, fmt.Sprintf("invalid schedule: %v", err), err)
+	}
+	return nil
 }
@@ -5,12 +5,13 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
-	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Gthulhu/api/config"
 	"github.com/Gthulhu/api/manager/domain"
+	"github.com/Gthulhu/api/pkg/util"
 	"go.uber.org/fx"
 )
 
@@ -21,6 +22,8 @@ type Params struct {
 	AccountConfig config.AccountConfig
 	K8SAdapter    domain.K8SAdapter
 	DMAdapter     domain.DecisionMakerAdapter
+	KEDAConfig    config.KEDAConfig      `optional:"true"`
+	Discovery     config.DiscoveryConfig `optional:"true"`
 }
 
 func NewService(params Params) (domain.Service, error) {
@@ -28,12 +31,23 @@ func NewService(params Params) (domain.Service, error) {
 	if err != nil {
 		return nil, fmt.Errorf("initialize RSA private key: %w", err)
 	}
+	var dmPublicKey *rsa.PublicKey
+	if pemStr := params.KeyConfig.DMPublicKeyPem.Value(); pemStr != "" {
+		dmPublicKey, err = util.PEMToRSAPublicKey(pemStr)
+		if err != nil {
+			return nil, fmt.Errorf("initialize decision maker RSA public key: %w", err)
+		}
+	}
 
 	svc := &Service{
 		K8SAdapter:    params.K8SAdapter,
 		DMAdapter:     params.DMAdapter,
 		Repo:          params.Repo,
 		jwtPrivateKey: jwtPrivateKey,
+		dmPublicKey:   dmPublicKey,
+		kedaCfg:       params.KEDAConfig,
+		discoveryCfg:  params.Discovery,
+		podEvents:     newPodEventQueue(),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -51,6 +65,12 @@ type Service struct {
 	DMAdapter     domain.DecisionMakerAdapter
 	Repo          domain.Repository
 	jwtPrivateKey *rsa.PrivateKey
+	dmPublicKey   *rsa.PublicKey
+	kedaCfg       config.KEDAConfig
+	discoveryCfg  config.DiscoveryConfig
+	// intentRefreshMu serializes refreshes of strategy intents.
+	intentRefreshMu sync.Mutex
+	podEvents       *podEventQueue
 }
 
 func initRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
@@ -74,7 +94,3 @@ func initRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
 	}
 	return key, nil
 }
-
-func (svc Service) ListAuditLogs(ctx context.Context, opt *domain.QueryAuditLogOptions) error {
-	return errors.New("not implemented")
-}
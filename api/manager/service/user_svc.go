@@ -17,8 +17,11 @@ func (svc *Service) CreateUser(ctx context.Context, operator domain.Claims, user
 
 	user.BaseEntity = domain.NewBaseEntity(util.Ptr(operatorID), util.Ptr(operatorID))
 	user.Status = domain.UserStatusWaitChangePassword
-	return svc.Repo.CreateUser(ctx, user)
-
+	if err := svc.Repo.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserCreate, domain.AuditResourceUser, user.ID.Hex(), nil, user)
+	return nil
 }
 
 func (svc *Service) DeleteUser(ctx context.Context, operator domain.Claims, userID bson.ObjectID) error {
@@ -31,10 +34,12 @@ func (svc *Service) DeleteUser(ctx context.Context, operator domain.Claims, user
 	}
 	updateUser.ID = userID
 	updateUser.DeletedTime = time.Now().UnixMilli()
+	before := svc.auditUser(ctx, userID)
 	err = svc.Repo.UpdateUser(ctx, updateUser)
 	if err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserDelete, domain.AuditResourceUser, userID.Hex(), before, nil)
 	return nil
 }
 
@@ -45,9 +50,22 @@ func (svc *Service) UpdateUser(ctx context.Context, operator domain.Claims, user
 	}
 	user.UpdaterID = operatorID
 	user.UpdatedTime = time.Now().UnixMilli()
+	before := svc.auditUser(ctx, user.ID)
 	err = svc.Repo.UpdateUser(ctx, user)
 	if err != nil {
 		return err
 	}
+	svc.recordAudit(ctx, operator.UID, domain.AuditActionUserUpdate, domain.AuditResourceUser, user.ID.Hex(), before, user)
 	return nil
 }
+
+// auditUser loads the user the audit entry of a change shows as before. The
+// lookup is best effort, like the audit itself: without it the entry only
+// lacks the previous state.
+func (svc *Service) auditUser(ctx context.Context, userID bson.ObjectID) *domain.User {
+	opt := &domain.QueryUserOptions{IDs: []bson.ObjectID{userID}}
+	if err := svc.Repo.QueryUsers(ctx, opt); err != nil || len(opt.Result) == 0 {
+		return nil
+	}
+	return opt.Result[0]
+}
@@ -0,0 +1,71 @@
+package cronexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, time.March, 6, 17, 30, 0, 0, time.UTC) // a Friday
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{spec: "* * * * *", from: base, want: base.Add(time.Minute)},
+		{spec: "0 22 * * *", from: base, want: time.Date(2026, time.March, 6, 22, 0, 0, 0, time.UTC)},
+		{spec: "0 9 * * mon-fri", from: base, want: time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)},
+		{spec: "*/15 17 * * *", from: base, want: time.Date(2026, time.March, 6, 17, 45, 0, 0, time.UTC)},
+		{spec: "0 0 1 jan *", from: base, want: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", from: base, want: time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		// Day of month and day of week both restricted: either matches.
+		{spec: "0 0 13 * 1", from: base, want: time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", from: base, want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			expr, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := expr.Next(tt.from); !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextUsesLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	expr, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC).In(loc) // 08:00 in Taipei
+	want := time.Date(2026, time.March, 6, 1, 0, 0, 0, time.UTC)
+	if got := expr.Next(from); !got.Equal(want) {
+		t.Fatalf("Next = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Package intentprecedence holds the rule that decides which scheduling
+// intent wins when several of them target the same process. Decision makers
+// apply it when they resolve intents and the manager applies it when it warns
+// about conflicts, so both layers agree on the outcome.
+package intentprecedence
+
+// Level is where an intent comes from. Pod-level intents (schedule
+// strategies) outrank node-level ones (node scheduling policies).
+type Level int
+
+const (
+	LevelNode Level = iota
+	LevelPod
+)
+
+// Reasons reported for the losing side of a conflict, named after the first
+// rule that separated the two intents.
+const (
+	ReasonPrecedence  = "precedence"
+	ReasonLevel       = "pod-level"
+	ReasonSpecificity = "specificity"
+	ReasonAge         = "age"
+)
+
+// Rank is everything the rule looks at. The rules apply in field order:
+// higher explicit Precedence, then pod-level over node-level, then higher
+// Specificity, then the lower Key. Keys are strategy or policy IDs, whose
+// hex form starts with the creation time, so the older one wins the tie.
+type Rank struct {
+	Precedence  int
+	Level       Level
+	Specificity int
+	Key         string
+}
+
+// Outranks reports whether a wins over b and, if so, the rule that decided
+// it. Equal ranks never outrank each other.
+func Outranks(a, b Rank) (bool, string) {
+	switch {
+	case a.Precedence != b.Precedence:
+		return a.Precedence > b.Precedence, ReasonPrecedence
+	case a.Level != b.Level:
+		return a.Level > b.Level, ReasonLevel
+	case a.Specificity != b.Specificity:
+		return a.Specificity > b.Specificity, ReasonSpecificity
+	case a.Key != b.Key:
+		return a.Key < b.Key, ReasonAge
+	}
+	return false, ""
+}
+
+// StrategySpecificity scores how narrowly a strategy selects its processes:
+// one point per label selector, one for restricting namespaces and one for
+// a command regex.
+func StrategySpecificity(labelSelectors, namespaces int, commandRegex string) int {
+	specificity := labelSelectors
+	if namespaces > 0 {
+		specificity++
+	}
+	if commandRegex != "" {
+		specificity++
+	}
+	return specificity
+}
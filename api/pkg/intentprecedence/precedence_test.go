@@ -0,0 +1,35 @@
+package intentprecedence
+
+import "testing"
+
+func TestOutranks(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       Rank
+		wantWins   bool
+		wantReason string
+	}{
+		{name: "explicit precedence beats level", a: Rank{Precedence: 1}, b: Rank{Level: LevelPod}, wantWins: true, wantReason: ReasonPrecedence},
+		{name: "pod beats node", a: Rank{Level: LevelPod}, b: Rank{Level: LevelNode, Specificity: 5}, wantWins: true, wantReason: ReasonLevel},
+		{name: "more specific wins", a: Rank{Specificity: 1, Key: "b"}, b: Rank{Specificity: 3, Key: "a"}, wantWins: false, wantReason: ReasonSpecificity},
+		{name: "older wins the tie", a: Rank{Key: "65a0"}, b: Rank{Key: "65b0"}, wantWins: true, wantReason: ReasonAge},
+		{name: "equal", a: Rank{Key: "a"}, b: Rank{Key: "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wins, reason := Outranks(tt.a, tt.b)
+			if wins != tt.wantWins || reason != tt.wantReason {
+				t.Fatalf("Outranks() = %v, %q, want %v, %q", wins, reason, tt.wantWins, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestStrategySpecificity(t *testing.T) {
+	if got := StrategySpecificity(0, 0, ""); got != 0 {
+		t.Fatalf("empty strategy specificity = %d, want 0", got)
+	}
+	if got := StrategySpecificity(2, 1, "nginx"); got != 4 {
+		t.Fatalf("specificity = %d, want 4", got)
+	}
+}
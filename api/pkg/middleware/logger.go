@@ -74,6 +74,15 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	rw.responseBody.Write(b)
+	// Only error bodies are logged; keeping the others would grow without
+	// bound on streaming responses.
+	if rw.statusCode >= 400 {
+		rw.responseBody.Write(b)
+	}
 	return rw.ResponseWriter.Write(b)
 }
+
+// Unwrap lets http.ResponseController flush streaming responses.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,160 @@
+// Package schedconfig holds the scheduler configuration rules shared by the
+// node config file, the daemon control API, the decision maker and the
+// manager, so every layer rejects the same invalid settings.
+package schedconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	ModeNone    = "none"
+	ModeGthulhu = "gthulhu"
+	ModeSimple  = "simple"
+	ModeSCX     = "scx"
+)
+
+// AllowedSCXSchedulers lists the scx scheduler binaries that may be started
+// in scx mode.
+var AllowedSCXSchedulers = map[string]struct{}{
+	"scx_beerland":    {},
+	"scx_bpfland":     {},
+	"scx_cake":        {},
+	"scx_chaos":       {},
+	"scx_cosmos":      {},
+	"scx_flash":       {},
+	"scx_lavd":        {},
+	"scx_layered":     {},
+	"scx_mitosis":     {},
+	"scx_p2dq":        {},
+	"scx_pandemonium": {},
+	"scx_rlfifo":      {},
+	"scx_rustland":    {},
+	"scx_rusty":       {},
+	"scx_tickless":    {},
+	"scx_timely":      {},
+	"scx_wd40":        {},
+}
+
+// Settings is the part of the scheduler configuration that is validated the
+// same way everywhere.
+type Settings struct {
+	Mode           string
+	SchedulerName  string
+	SliceNsDefault uint64
+	SliceNsMin     uint64
+	KernelMode     bool
+}
+
+// FieldPaths names every Settings field in the caller's schema, so errors
+// point at the key the user actually wrote.
+type FieldPaths struct {
+	Mode           string
+	SchedulerName  string
+	SliceNsDefault string
+	SliceNsMin     string
+	KernelMode     string
+}
+
+var (
+	// YAMLPaths matches the node configuration file.
+	YAMLPaths = FieldPaths{
+		Mode:           "scheduler.mode",
+		SchedulerName:  "scheduler.scheduler_name",
+		SliceNsDefault: "scheduler.slice_ns_default",
+		SliceNsMin:     "scheduler.slice_ns_min",
+		KernelMode:     "scheduler.kernel_mode",
+	}
+	// JSONPaths matches the runtime config API payloads.
+	JSONPaths = FieldPaths{
+		Mode:           "mode",
+		SchedulerName:  "schedulerName",
+		SliceNsDefault: "sliceNsDefault",
+		SliceNsMin:     "sliceNsMin",
+		KernelMode:     "kernelMode",
+	}
+)
+
+// FieldError describes one invalid configuration value.
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// FieldErrors collects every problem found in a configuration.
+type FieldErrors []FieldError
+
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Add records a new field error.
+func (errs *FieldErrors) Add(path string, format string, args ...any) {
+	*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns nil when no error was collected, so callers can return it as a
+// plain error without ending up with a non-nil empty slice.
+func (errs FieldErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks s and returns every violation. An empty mode is accepted;
+// callers decide what it defaults to.
+func Validate(s Settings, paths FieldPaths) FieldErrors {
+	var errs FieldErrors
+	mode := strings.TrimSpace(s.Mode)
+	switch mode {
+	case "", ModeNone, ModeGthulhu, ModeSimple:
+	case ModeSCX:
+		validateSCXSchedulerName(&errs, strings.TrimSpace(s.SchedulerName), paths.SchedulerName)
+	default:
+		errs.Add(paths.Mode, "unsupported scheduler mode %q, must be one of %s, %s, %s, %s", mode, ModeNone, ModeGthulhu, ModeSimple, ModeSCX)
+	}
+
+	if s.SliceNsDefault != 0 && s.SliceNsMin > s.SliceNsDefault {
+		errs.Add(paths.SliceNsMin, "must not exceed %s (%d > %d)", paths.SliceNsDefault, s.SliceNsMin, s.SliceNsDefault)
+	}
+
+	if s.KernelMode && (mode == ModeSimple || mode == ModeSCX) {
+		errs.Add(paths.KernelMode, "is only supported in %s mode, not %s", ModeGthulhu, mode)
+	}
+	return errs
+}
+
+func validateSCXSchedulerName(errs *FieldErrors, name string, path string) {
+	if name == "" {
+		errs.Add(path, "is required when mode is %s", ModeSCX)
+		return
+	}
+	if filepath.Base(name) != name || strings.ContainsAny(name, `/\`) {
+		errs.Add(path, "must be a binary name, got %q", name)
+		return
+	}
+	if _, ok := AllowedSCXSchedulers[name]; !ok {
+		errs.Add(path, "%q is not in the allowed scx scheduler list (%s)", name, strings.Join(allowedSCXSchedulerNames(), ", "))
+	}
+}
+
+func allowedSCXSchedulerNames() []string {
+	names := make([]string, 0, len(AllowedSCXSchedulers))
+	for name := range AllowedSCXSchedulers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
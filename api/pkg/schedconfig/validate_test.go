@@ -0,0 +1,55 @@
+package schedconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  Settings
+		wantPaths []string
+	}{
+		{name: "empty mode", settings: Settings{}},
+		{name: "gthulhu kernel mode", settings: Settings{Mode: ModeGthulhu, KernelMode: true, SliceNsDefault: 20, SliceNsMin: 1}},
+		{name: "scx allowed", settings: Settings{Mode: ModeSCX, SchedulerName: "scx_lavd"}},
+		{name: "unknown mode", settings: Settings{Mode: "fifo"}, wantPaths: []string{"scheduler.mode"}},
+		{name: "scx missing name", settings: Settings{Mode: ModeSCX}, wantPaths: []string{"scheduler.scheduler_name"}},
+		{name: "scx path", settings: Settings{Mode: ModeSCX, SchedulerName: "../scx_lavd"}, wantPaths: []string{"scheduler.scheduler_name"}},
+		{name: "scx disallowed", settings: Settings{Mode: ModeSCX, SchedulerName: "scx_unknown"}, wantPaths: []string{"scheduler.scheduler_name"}},
+		{name: "slice min above default", settings: Settings{Mode: ModeGthulhu, SliceNsDefault: 1, SliceNsMin: 2}, wantPaths: []string{"scheduler.slice_ns_min"}},
+		{
+			name:      "reports every error",
+			settings:  Settings{Mode: ModeSimple, KernelMode: true, SliceNsDefault: 1, SliceNsMin: 2},
+			wantPaths: []string{"scheduler.slice_ns_min", "scheduler.kernel_mode"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(tt.settings, YAMLPaths)
+			if len(errs) != len(tt.wantPaths) {
+				t.Fatalf("Validate() = %v, want errors for %v", errs, tt.wantPaths)
+			}
+			for i, path := range tt.wantPaths {
+				if errs[i].Path != path {
+					t.Fatalf("errs[%d].Path = %q, want %q", i, errs[i].Path, path)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldErrorsErr(t *testing.T) {
+	var errs FieldErrors
+	if errs.Err() != nil {
+		t.Fatalf("expected nil error for empty FieldErrors")
+	}
+	errs.Add("mode", "is invalid")
+	errs.Add("sliceNsMin", "is too large")
+	err := errs.Err()
+	if err == nil || !strings.Contains(err.Error(), "mode: is invalid; sliceNsMin: is too large") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -78,6 +78,41 @@ func TruncateMerkleTree(root *MerkleNode, depth int64) *MerkleNode {
 	}
 }
 
+// IndexMerkleTree maps the hash of every node under root to the node.
+func IndexMerkleTree(root *MerkleNode) map[string]*MerkleNode {
+	index := make(map[string]*MerkleNode)
+	var walk func(node *MerkleNode)
+	walk = func(node *MerkleNode) {
+		if node == nil {
+			return
+		}
+		if _, ok := index[node.Hash]; ok {
+			return
+		}
+		index[node.Hash] = node
+		walk(node.Left)
+		walk(node.Right)
+	}
+	walk(root)
+	return index
+}
+
+// MerkleLeafHashes returns the leaf hashes under node, once each.
+func MerkleLeafHashes(node *MerkleNode) []string {
+	if node == nil {
+		return nil
+	}
+	if node.Left == nil && node.Right == nil {
+		return []string{node.Hash}
+	}
+	leaves := MerkleLeafHashes(node.Left)
+	// An odd node is paired with itself.
+	if node.Right != node.Left {
+		leaves = append(leaves, MerkleLeafHashes(node.Right)...)
+	}
+	return leaves
+}
+
 func hashMerklePair(leftHash, rightHash string) string {
 	leftBytes, errLeft := hex.DecodeString(leftHash)
 	rightBytes, errRight := hex.DecodeString(rightHash)
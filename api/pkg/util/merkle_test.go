@@ -33,3 +33,32 @@ func TestMerkleTreeTraverseAndTruncate(t *testing.T) {
 		t.Fatalf("expected depth 0 to have no children")
 	}
 }
+
+func TestIndexMerkleTreeAndLeafHashes(t *testing.T) {
+	leaves := []string{
+		HashStringSHA256Hex("a"),
+		HashStringSHA256Hex("b"),
+		HashStringSHA256Hex("c"),
+	}
+	root := BuildMerkleTree(leaves)
+
+	index := IndexMerkleTree(root)
+	for _, leaf := range leaves {
+		if index[leaf] == nil {
+			t.Fatalf("expected leaf %s in index", leaf)
+		}
+	}
+	if index[root.Hash] != root || index[root.Left.Hash] != root.Left {
+		t.Fatalf("expected inner nodes in index")
+	}
+
+	got := MerkleLeafHashes(root)
+	if len(got) != len(leaves) {
+		t.Fatalf("expected %d leaves, got %v", len(leaves), got)
+	}
+	for i := range leaves {
+		if got[i] != leaves[i] {
+			t.Fatalf("expected leaves %v, got %v", leaves, got)
+		}
+	}
+}
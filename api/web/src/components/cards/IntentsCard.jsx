@@ -95,20 +95,25 @@ export default function IntentsCard() {
 
   const getStateLabel = (state) => {
     const states = {
-      0: 'Pending',
-      1: 'Active', 
-      2: 'Applied',
-      3: 'Failed'
+      0: 'Unknown',
+      1: 'Pending',
+      2: 'Sent',
+      3: 'Applied',
+      4: 'No Matching Process',
+      5: 'Failed',
+      6: 'Resolved'
     };
     return states[state] || `Unknown (${state})`;
   };
 
   const getStateClass = (state) => {
     const classes = {
-      0: 'pending',
-      1: 'active',
-      2: 'applied',
-      3: 'failed'
+      1: 'pending',
+      2: 'active',
+      3: 'applied',
+      4: 'pending',
+      5: 'failed',
+      6: 'active'
     };
     return classes[state] || 'unknown';
   };
@@ -251,6 +256,20 @@ export default function IntentsCard() {
                         <span className="detail-value">{intent.CommandRegex}</span>
                       </div>
                     )}
+                    <div className="detail-item">
+                      <span className="detail-label">Matched PIDs</span>
+                      <span className="detail-value">{intent.MatchedPIDs?.length ? intent.MatchedPIDs.join(', ') : '--'}</span>
+                    </div>
+                    <div className="detail-item">
+                      <span className="detail-label">Last Applied</span>
+                      <span className="detail-value">{intent.LastAppliedAt ? new Date(intent.LastAppliedAt).toLocaleString() : '--'}</span>
+                    </div>
+                    {intent.StatusMessage && (
+                      <div className="detail-item">
+                        <span className="detail-label">Status</span>
+                        <span className="detail-value">{intent.StatusMessage}</span>
+                      </div>
+                    )}
                   </div>
                   
                   {intent.PodLabels && Object.keys(intent.PodLabels).length > 0 && (
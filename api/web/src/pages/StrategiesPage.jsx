@@ -252,8 +252,8 @@ export default function StrategiesPage() {
     }
   };
 
-  const stateLabel = { 0: 'Pending', 1: 'Active', 2: 'Applied', 3: 'Failed' };
-  const stateBadge = { 0: 'badge-warning', 1: 'badge-primary', 2: 'badge-success', 3: 'badge-danger' };
+  const stateLabel = { 0: 'Unknown', 1: 'Pending', 2: 'Sent', 3: 'Applied', 4: 'No Matching Process', 5: 'Failed', 6: 'Resolved' };
+  const stateBadge = { 1: 'badge-warning', 2: 'badge-primary', 3: 'badge-success', 4: 'badge-warning', 5: 'badge-danger', 6: 'badge-primary' };
 
   return (
     <div>
@@ -286,12 +286,12 @@ export default function StrategiesPage() {
           <div className="stat-card-value">{intents.length}</div>
         </div>
         <div className="stat-card">
-          <div className="stat-card-label">Active Intents</div>
-          <div className="stat-card-value">{intents.filter((i) => i.State === 1).length}</div>
+          <div className="stat-card-label">Applied Intents</div>
+          <div className="stat-card-value">{intents.filter((i) => i.State === 3).length}</div>
         </div>
         <div className="stat-card">
           <div className="stat-card-label">Failed Intents</div>
-          <div className="stat-card-value">{intents.filter((i) => i.State === 3).length}</div>
+          <div className="stat-card-value">{intents.filter((i) => i.State === 5).length}</div>
         </div>
       </div>
 
@@ -432,7 +432,7 @@ export default function StrategiesPage() {
                     <td>{intent.Priority}</td>
                     <td>{intent.ExecutionTime} ns</td>
                     <td>
-                      <span className={`badge ${stateBadge[intent.State] || 'badge-secondary'}`}>
+                      <span className={`badge ${stateBadge[intent.State] || 'badge-secondary'}`} title={intent.StatusMessage || undefined}>
                         {stateLabel[intent.State] || `Unknown(${intent.State})`}
                       </span>
                     </td>
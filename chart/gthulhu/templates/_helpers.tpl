@@ -89,3 +89,14 @@ mTLS Secret name — returns existingSecret if set, otherwise the chart-managed
 {{- printf "%s-mtls-certs" (include "gthulhu.fullname" .) }}
 {{- end }}
 {{- end }}
+
+{{/*
+Name of the Secret holding the decision maker registration token.
+*/}}
+{{- define "gthulhu.registrationSecretName" -}}
+{{- if .Values.discovery.registration.existingSecret }}
+{{- .Values.discovery.registration.existingSecret }}
+{{- else }}
+{{- printf "%s-dm-registration" (include "gthulhu.fullname" .) }}
+{{- end }}
+{{- end }}
@@ -160,6 +160,7 @@ func (c *Config) IsSchedulerEnabled() bool {
 func ExplainConfig() string {
 	var sb strings.Builder
 	sb.WriteString("Gthulhu Configuration Keys:\n\n")
+	sb.WriteString(fmt.Sprintf("Precedence: %s\n\n", Precedence))
 	explainStruct(&sb, reflect.TypeOf(Config{}), "")
 	return sb.String()
 }
@@ -167,28 +168,25 @@ func ExplainConfig() string {
 func explainStruct(sb *strings.Builder, t reflect.Type, prefix string) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		yamlTag := field.Tag.Get("yaml")
 		desc := field.Tag.Get("description")
 
-		// Extract yaml key name (before any comma options)
-		yamlKey := yamlTag
-		if idx := strings.Index(yamlTag, ","); idx != -1 {
-			yamlKey = yamlTag[:idx]
-		}
-
-		fullKey := yamlKey
+		fullKey := yamlKey(field)
 		if prefix != "" {
-			fullKey = prefix + "." + yamlKey
-		}
-
-		if desc != "" {
-			sb.WriteString(fmt.Sprintf("  %-40s %s\n", fullKey, desc))
+			fullKey = prefix + "." + fullKey
 		}
 
 		// Recurse into nested structs
 		ft := field.Type
 		if ft.Kind() == reflect.Struct {
+			if desc != "" {
+				sb.WriteString(fmt.Sprintf("  %-40s %s\n", fullKey, desc))
+			}
 			explainStruct(sb, ft, fullKey)
+			continue
+		}
+
+		if desc != "" {
+			sb.WriteString(fmt.Sprintf("  %-40s %s [%s]\n", fullKey, desc, EnvName(fullKey)))
 		}
 	}
 }
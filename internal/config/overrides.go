@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Gthulhu/api/pkg/schedconfig"
+)
+
+// EnvPrefix prefixes the environment variable of every configuration key.
+const EnvPrefix = "GTHULHU_"
+
+// Precedence documents how configuration sources are merged, lowest first.
+const Precedence = "built-in defaults < YAML file (-config) < GTHULHU_<PATH> environment variables < -set path=value flags"
+
+// EnvName returns the environment variable that overrides the given dotted
+// key, e.g. scheduler.slice_ns_min becomes GTHULHU_SCHEDULER_SLICE_NS_MIN.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys returns every overridable configuration key, in ExplainConfig order.
+func Keys() []string {
+	var keys []string
+	walkLeafFields(reflect.TypeOf(Config{}), "", nil, func(key string, _ []int) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Overrides collects repeated -set path=value flags. It implements flag.Value.
+type Overrides []string
+
+func (o *Overrides) String() string {
+	if o == nil {
+		return ""
+	}
+	return strings.Join(*o, ",")
+}
+
+// Set records one path=value pair; the value is checked by ApplyOverrides.
+func (o *Overrides) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected path=value, got %q", value)
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// ApplyOverrides applies GTHULHU_<PATH> variables from environ (in the
+// os.Environ format) and then the -set pairs, so flags win over the
+// environment. Every malformed entry is reported, keyed by path.
+// Variables with the GTHULHU_ prefix that do not match a key are ignored so
+// unrelated deployment settings do not break startup.
+func (c *Config) ApplyOverrides(environ []string, sets []string) error {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(name, EnvPrefix) {
+			env[name] = value
+		}
+	}
+
+	var errs schedconfig.FieldErrors
+	for _, key := range Keys() {
+		name := EnvName(key)
+		value, ok := env[name]
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			errs.Add(key, "invalid value in %s: %v", name, err)
+		}
+	}
+	for _, kv := range sets {
+		key, value, ok := strings.Cut(kv, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			errs.Add(kv, "expected path=value")
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			errs.Add(key, "invalid value in -set: %v", err)
+		}
+	}
+	return errs.Err()
+}
+
+// Set assigns value to the configuration key, parsing it according to the
+// field type.
+func (c *Config) Set(key, value string) error {
+	var index []int
+	walkLeafFields(reflect.TypeOf(Config{}), "", nil, func(k string, i []int) {
+		if k == key {
+			index = i
+		}
+	})
+	if index == nil {
+		return fmt.Errorf("unknown configuration key %q, run with -explain to list the keys", key)
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(index)
+	value = strings.TrimSpace(value)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an unsigned integer, got %q", value)
+		}
+		field.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// walkLeafFields calls fn for every non-struct field reachable from t with
+// its dotted YAML key and reflect index path, the same keys ExplainConfig
+// documents.
+func walkLeafFields(t reflect.Type, prefix string, index []int, fn func(key string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fullKey := yamlKey(field)
+		if prefix != "" {
+			fullKey = prefix + "." + fullKey
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			walkLeafFields(field.Type, fullKey, fieldIndex, fn)
+			continue
+		}
+		fn(fullKey, fieldIndex)
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("api.mtls.cert_pem"); got != "GTHULHU_API_MTLS_CERT_PEM" {
+		t.Fatalf("EnvName() = %q", got)
+	}
+}
+
+func TestKeysMatchExplainConfig(t *testing.T) {
+	output := ExplainConfig()
+	for _, key := range Keys() {
+		if !strings.Contains(output, key) {
+			t.Errorf("ExplainConfig output missing key %q", key)
+		}
+		if !strings.Contains(output, EnvName(key)) {
+			t.Errorf("ExplainConfig output missing env var %q", EnvName(key))
+		}
+	}
+}
+
+func TestApplyOverridesPrecedence(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Scheduler.Mode = "gthulhu"
+	environ := []string{
+		"GTHULHU_SCHEDULER_MODE=simple",
+		"GTHULHU_MONITOR_PROMETHEUS_PORT=9191",
+		"GTHULHU_API_ENABLED=true",
+		"GTHULHU_NOT_A_KEY=1",
+		"HOME=/root",
+	}
+	sets := []string{"scheduler.mode=none", "api.url=http://api:8080"}
+
+	if err := cfg.ApplyOverrides(environ, sets); err != nil {
+		t.Fatalf("ApplyOverrides() error: %v", err)
+	}
+	if cfg.Scheduler.Mode != "none" {
+		t.Fatalf("scheduler.mode = %q, want -set to win over env", cfg.Scheduler.Mode)
+	}
+	if cfg.Monitor.PrometheusPort != 9191 {
+		t.Fatalf("monitor.prometheus_port = %d, want 9191", cfg.Monitor.PrometheusPort)
+	}
+	if !cfg.Api.Enabled || cfg.Api.Url != "http://api:8080" {
+		t.Fatalf("api = %+v, want enabled with url from -set", cfg.Api)
+	}
+	if cfg.Scheduler.SliceNsDefault != DefaultConfig().Scheduler.SliceNsDefault {
+		t.Fatalf("untouched keys should keep their value")
+	}
+}
+
+func TestApplyOverridesReportsAllErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	environ := []string{"GTHULHU_SCHEDULER_KERNEL_MODE=maybe"}
+	sets := []string{"scheduler.slice_ns_min=-1", "scheduler.unknown=1", "=1"}
+
+	paths := fieldErrorPaths(t, cfg.ApplyOverrides(environ, sets))
+	want := []string{"scheduler.kernel_mode", "scheduler.slice_ns_min", "scheduler.unknown", "=1"}
+	if len(paths) != len(want) {
+		t.Fatalf("got error paths %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("got error paths %v, want %v", paths, want)
+		}
+	}
+}
+
+func TestOverridesFlagValue(t *testing.T) {
+	var o Overrides
+	if err := o.Set("scheduler.mode"); err == nil {
+		t.Fatalf("expected error without '='")
+	}
+	if err := o.Set("scheduler.mode=simple"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if err := o.Set("debug=true"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if o.String() != "scheduler.mode=simple,debug=true" {
+		t.Fatalf("String() = %q", o.String())
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/Gthulhu/api/pkg/schedconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings returns the scheduler section in the shape of the validator
+// shared with the daemon, the decision maker and the manager.
+func (s SchedulerConfig) Settings() schedconfig.Settings {
+	return schedconfig.Settings{
+		Mode:           s.Mode,
+		SchedulerName:  s.SchedulerName,
+		SliceNsDefault: s.SliceNsDefault,
+		SliceNsMin:     s.SliceNsMin,
+		KernelMode:     s.KernelMode,
+	}
+}
+
+// Validate checks the whole configuration and returns every problem found,
+// keyed by YAML path, or nil when the configuration is valid.
+func (c *Config) Validate() error {
+	errs := schedconfig.Validate(c.Scheduler.Settings(), schedconfig.YAMLPaths)
+
+	mode := strings.TrimSpace(c.Scheduler.Mode)
+	if (mode == schedconfig.ModeGthulhu || mode == schedconfig.ModeSimple) && c.Scheduler.SliceNsDefault == 0 {
+		errs.Add("scheduler.slice_ns_default", "must be greater than zero when mode is %s", mode)
+	}
+
+	if c.Monitor.Enabled {
+		if c.Monitor.BPFObjectPath == "" {
+			errs.Add("monitor.bpf_object_path", "is required when the monitor is enabled")
+		}
+		if c.Monitor.CollectionIntervalSec <= 0 {
+			errs.Add("monitor.collection_interval_sec", "must be greater than zero, got %d", c.Monitor.CollectionIntervalSec)
+		}
+	}
+	if c.Monitor.PrometheusPort < 0 || c.Monitor.PrometheusPort > 65535 {
+		errs.Add("monitor.prometheus_port", "must be between 0 and 65535, got %d", c.Monitor.PrometheusPort)
+	}
+
+	if c.Api.Enabled {
+		if c.Api.Url == "" {
+			errs.Add("api.url", "is required when the API is enabled")
+		} else if u, err := url.Parse(c.Api.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add("api.url", "must be an absolute http or https URL, got %q", c.Api.Url)
+		}
+		if c.Api.Interval <= 0 {
+			errs.Add("api.interval", "must be greater than zero, got %d", c.Api.Interval)
+		}
+	}
+	if c.Api.MTLS.Enable {
+		if c.Api.MTLS.CertPem == "" {
+			errs.Add("api.mtls.cert_pem", "is required when mTLS is enabled")
+		}
+		if c.Api.MTLS.KeyPem == "" {
+			errs.Add("api.mtls.key_pem", "is required when mTLS is enabled")
+		}
+		if c.Api.MTLS.CAPem == "" {
+			errs.Add("api.mtls.ca_pem", "is required when mTLS is enabled")
+		}
+	}
+	return errs.Err()
+}
+
+// ValidateFile loads filename strictly, applies the environment and -set
+// overrides and validates the result, reporting unknown keys, bad overrides
+// and invalid values together. The returned config is the effective
+// configuration and is nil only when the file cannot be read or parsed.
+func ValidateFile(filename string, environ []string, sets []string) (*Config, error) {
+	_, strictErr := LoadConfigStrict(filename)
+	var errs schedconfig.FieldErrors
+	if strictErr != nil && !errors.As(strictErr, &errs) {
+		return nil, strictErr
+	}
+	config, err := LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	var overrideErrs schedconfig.FieldErrors
+	if errors.As(config.ApplyOverrides(environ, sets), &overrideErrs) {
+		errs = append(errs, overrideErrs...)
+	}
+	var validationErrs schedconfig.FieldErrors
+	if errors.As(config.Validate(), &validationErrs) {
+		errs = append(errs, validationErrs...)
+	}
+	return config, errs.Err()
+}
+
+// LoadConfigStrict loads filename like LoadConfig but fails when the file
+// does not exist or contains keys that do not map to a configuration field.
+// All unknown keys are reported at once.
+func LoadConfigStrict(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML config: %w", err)
+	}
+	config := DefaultConfig()
+	if len(root.Content) == 0 {
+		return config, nil
+	}
+	var errs schedconfig.FieldErrors
+	collectUnknownKeys(&errs, root.Content[0], reflect.TypeOf(Config{}), "")
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to decode YAML config: %w", err)
+	}
+	return config, nil
+}
+
+func collectUnknownKeys(errs *schedconfig.FieldErrors, node *yaml.Node, t reflect.Type, prefix string) {
+	if node.Kind != yaml.MappingNode || t.Kind() != reflect.Struct {
+		return
+	}
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fields[yamlKey(field)] = field.Type
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		fieldType, ok := fields[key]
+		if !ok {
+			errs.Add(fullKey, "unknown configuration key (line %d)", node.Content[i].Line)
+			continue
+		}
+		collectUnknownKeys(errs, node.Content[i+1], fieldType, fullKey)
+	}
+}
+
+// EffectiveYAML renders the configuration after defaults and file values
+// have been merged.
+func (c *Config) EffectiveYAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// yamlKey returns the YAML key of a struct field, without tag options.
+func yamlKey(field reflect.StructField) string {
+	yamlTag := field.Tag.Get("yaml")
+	if idx := strings.Index(yamlTag, ","); idx != -1 {
+		return yamlTag[:idx]
+	}
+	return yamlTag
+}
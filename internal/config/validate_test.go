@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gthulhu/api/pkg/schedconfig"
+)
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func fieldErrorPaths(t *testing.T, err error) []string {
+	t.Helper()
+	var fieldErrs schedconfig.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+	paths := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		paths = append(paths, fieldErr.Path)
+	}
+	return paths
+}
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config should be valid: %v", err)
+	}
+}
+
+func TestShippedConfigIsValid(t *testing.T) {
+	if _, err := ValidateFile(filepath.Join("..", "..", "config", "config.yaml"), nil, nil); err != nil {
+		t.Fatalf("config/config.yaml should be valid: %v", err)
+	}
+}
+
+func TestValidateReportsAllFieldErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Scheduler.Mode = "simple"
+	cfg.Scheduler.KernelMode = true
+	cfg.Scheduler.SliceNsMin = cfg.Scheduler.SliceNsDefault + 1
+	cfg.Api.Enabled = true
+	cfg.Api.Url = "127.0.0.1:8080"
+
+	paths := fieldErrorPaths(t, cfg.Validate())
+	want := []string{"scheduler.slice_ns_min", "scheduler.kernel_mode", "api.url", "api.interval"}
+	if len(paths) != len(want) {
+		t.Fatalf("got error paths %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("got error paths %v, want %v", paths, want)
+		}
+	}
+}
+
+func TestLoadConfigStrictRejectsUnknownKeys(t *testing.T) {
+	path := writeTestConfig(t, `
+scheduler:
+  mode: gthulhu
+  slice_ns: 100
+monitor:
+  enabled: true
+debugging: true
+`)
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig should ignore unknown keys: %v", err)
+	}
+
+	_, err := LoadConfigStrict(path)
+	paths := fieldErrorPaths(t, err)
+	if len(paths) != 2 || paths[0] != "scheduler.slice_ns" || paths[1] != "debugging" {
+		t.Fatalf("unexpected unknown key paths %v", paths)
+	}
+}
+
+func TestLoadConfigStrictRequiresFile(t *testing.T) {
+	if _, err := LoadConfigStrict(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("expected error for missing config file")
+	}
+}
+
+func TestValidateFileCombinesUnknownKeysAndInvalidValues(t *testing.T) {
+	path := writeTestConfig(t, `
+scheduler:
+  mode: fifo
+  kernal_mode: true
+`)
+
+	cfg, err := ValidateFile(path, nil, nil)
+	if cfg == nil {
+		t.Fatalf("expected effective config to be returned")
+	}
+	paths := fieldErrorPaths(t, err)
+	if len(paths) != 2 || paths[0] != "scheduler.kernal_mode" || paths[1] != "scheduler.mode" {
+		t.Fatalf("unexpected error paths %v", paths)
+	}
+}
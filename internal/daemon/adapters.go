@@ -1,9 +1,12 @@
 package daemon
 
-type fileRuntimeConfigStore struct{}
+type fileRuntimeConfigStore struct {
+	environ   []string
+	overrides []string
+}
 
-func (fileRuntimeConfigStore) InitializeRuntimeConfig(bootstrapConfigPath, runtimeConfigPath string) error {
-	return initializeRuntimeConfig(bootstrapConfigPath, runtimeConfigPath)
+func (s fileRuntimeConfigStore) InitializeRuntimeConfig(bootstrapConfigPath, runtimeConfigPath string) error {
+	return initializeRuntimeConfig(bootstrapConfigPath, runtimeConfigPath, s.environ, s.overrides)
 }
 
 func (fileRuntimeConfigStore) ApplyRuntimeConfig(runtimeConfigPath, schedulerBinPath string, req runtimeConfigRequest) (bool, error) {
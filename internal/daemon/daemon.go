@@ -15,6 +15,7 @@ import (
 
 	"github.com/Gthulhu/Gthulhu/internal/config"
 	"github.com/Gthulhu/Gthulhu/internal/schedext"
+	"github.com/Gthulhu/api/pkg/schedconfig"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,7 +24,6 @@ const modeScheduler = "scheduler"
 var schedExtSupportChecker = schedext.CheckSupport
 
 func Run(args []string) error {
-	runtimeStore := RuntimeConfigStore(fileRuntimeConfigStore{})
 	commandResolver := SchedulerCommandResolver(defaultSchedulerCommandResolver{})
 
 	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
@@ -32,15 +32,20 @@ func Run(args []string) error {
 		fmt.Fprintf(os.Stdout, "Usage: %s daemon [flags]\n", os.Args[0])
 		fmt.Fprintf(os.Stdout, "Runs gthulhud supervisor mode; starts and restarts scheduler child process.\n\n")
 		fs.PrintDefaults()
+		fmt.Fprintf(os.Stdout, "\nGTHULHU_<PATH> environment variables and -set overrides are merged into the runtime config once at startup.\n")
+		fmt.Fprintf(os.Stdout, "Precedence: %s < runtime config API\n", config.Precedence)
 	}
 	configFile := fs.String("config", "", "Path to YAML configuration file")
 	restartDelay := fs.Duration("restart-delay", 2*time.Second, "Delay before restarting scheduler process")
 	schedulerBin := fs.String("scheduler-bin", "", "Path to scheduler binary (default: current executable)")
 	runtimeConfigPath := fs.String("runtime-config-path", "/tmp/gthulhu/runtime-config.yaml", "Path to daemon-managed runtime YAML config file")
 	controlAddr := fs.String("control-addr", ":18080", "Daemon control API bind address")
+	var overrides config.Overrides
+	fs.Var(&overrides, "set", "Override a configuration key as path=value, e.g. -set scheduler.mode=simple (repeatable)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	runtimeStore := RuntimeConfigStore(fileRuntimeConfigStore{environ: os.Environ(), overrides: overrides})
 
 	binPath := *schedulerBin
 	if binPath == "" {
@@ -96,6 +101,7 @@ func Run(args []string) error {
 		}
 
 		cmd := exec.Command(childBinPath, childArgs...)
+		cmd.Env = schedulerChildEnv(os.Environ())
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
@@ -170,11 +176,14 @@ func isUnsupportedSchedExtExit(err error) bool {
 	return errors.As(err, &exitErr) && exitErr.ExitCode() == schedext.UnsupportedExitCode
 }
 
-func initializeRuntimeConfig(bootstrapConfigPath string, runtimeConfigPath string) error {
+func initializeRuntimeConfig(bootstrapConfigPath string, runtimeConfigPath string, environ []string, overrides []string) error {
 	cfg, err := config.LoadConfig(bootstrapConfigPath)
 	if err != nil {
 		return fmt.Errorf("load bootstrap config for daemon: %w", err)
 	}
+	if err := cfg.ApplyOverrides(environ, overrides); err != nil {
+		return fmt.Errorf("apply config overrides for daemon: %w", err)
+	}
 	if err := os.MkdirAll(filepath.Dir(runtimeConfigPath), 0o755); err != nil {
 		return fmt.Errorf("create runtime config directory: %w", err)
 	}
@@ -185,6 +194,25 @@ func initializeRuntimeConfig(bootstrapConfigPath string, runtimeConfigPath strin
 	return nil
 }
 
+// schedulerChildEnv drops the GTHULHU_<PATH> configuration overrides from
+// environ. They are already merged into the runtime config at startup and
+// would otherwise shadow later runtime config updates in the child.
+func schedulerChildEnv(environ []string) []string {
+	overrideNames := make(map[string]struct{})
+	for _, key := range config.Keys() {
+		overrideNames[config.EnvName(key)] = struct{}{}
+	}
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrideNames[name]; ok {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
 func applyRuntimeConfigToFile(runtimeConfigPath string, schedulerBinPath string, req runtimeConfigRequest) (bool, error) {
 	cfg, err := config.LoadConfig(runtimeConfigPath)
 	if err != nil {
@@ -245,7 +273,7 @@ func applyRuntimeConfigToFile(runtimeConfigPath string, schedulerBinPath string,
 			cfg.Scheduler.Mode = "gthulhu"
 		}
 	}
-	if err := validateScheduler(cfg.Scheduler.Mode, cfg.Scheduler.SchedulerName); err != nil {
+	if err := validateSchedulerConfig(cfg.Scheduler); err != nil {
 		return false, err
 	}
 	if cfg.Scheduler.Mode == "scx" {
@@ -325,28 +353,17 @@ func schedulerCommandFromConfig(configPath string, gthulhuBin string) (string, [
 }
 
 func validateScheduler(mode string, schedulerName string) error {
-	mode = strings.TrimSpace(mode)
-	if mode == "" {
-		mode = "gthulhu"
-	}
-	switch mode {
-	case "none", "gthulhu", "simple":
-		return nil
-	case "scx":
-		name := strings.TrimSpace(schedulerName)
-		if name == "" {
-			return fmt.Errorf("schedulerName is required when mode is scx")
-		}
-		if filepath.Base(name) != name || strings.Contains(name, string(filepath.Separator)) {
-			return fmt.Errorf("schedulerName must be a binary name, got %q", schedulerName)
-		}
-		if _, ok := allowedSCXSchedulers[name]; !ok {
-			return fmt.Errorf("schedulerName %q is not in the allowed scx scheduler list", name)
-		}
-		return nil
-	default:
-		return fmt.Errorf("unsupported scheduler mode %q", mode)
-	}
+	return validateSchedulerConfig(config.SchedulerConfig{Mode: mode, SchedulerName: schedulerName})
+}
+
+// validateSchedulerConfig applies the scheduler rules shared with the manager
+// and the decision maker. An empty mode defaults to gthulhu on the daemon.
+func validateSchedulerConfig(sc config.SchedulerConfig) error {
+	sc.Mode = strings.TrimSpace(sc.Mode)
+	if sc.Mode == "" {
+		sc.Mode = schedconfig.ModeGthulhu
+	}
+	return schedconfig.Validate(sc.Settings(), schedconfig.YAMLPaths).Err()
 }
 
 // ensureExecutableInDir verifies that name resolves to an executable file
@@ -110,3 +110,37 @@ func TestSchedulerCommandFromConfig_SchedExtUnsupportedWithoutMonitorReturnsErro
 		t.Fatalf("error=%v, want ErrUnsupported", err)
 	}
 }
+
+func TestInitializeRuntimeConfigAppliesOverrides(t *testing.T) {
+	tmp := t.TempDir()
+	runtimePath := filepath.Join(tmp, "runtime.yaml")
+	environ := []string{"GTHULHU_SCHEDULER_MODE=simple", "GTHULHU_SCHEDULER_SLICE_NS_MIN=5000"}
+	overrides := []string{"scheduler.slice_ns_min=7000"}
+
+	if err := initializeRuntimeConfig("", runtimePath, environ, overrides); err != nil {
+		t.Fatalf("initializeRuntimeConfig error: %v", err)
+	}
+	cfg, err := config.LoadConfig(runtimePath)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	if cfg.Scheduler.Mode != "simple" {
+		t.Fatalf("mode=%q, want simple", cfg.Scheduler.Mode)
+	}
+	if cfg.Scheduler.SliceNsMin != 7000 {
+		t.Fatalf("slice_ns_min=%d, want 7000 (-set wins over env)", cfg.Scheduler.SliceNsMin)
+	}
+}
+
+func TestSchedulerChildEnvDropsConfigOverrides(t *testing.T) {
+	env := schedulerChildEnv([]string{"PATH=/bin", "GTHULHU_SCHEDULER_MODE=simple", "GTHULHU_UNRELATED=1", "NODE_NAME=n1"})
+	want := []string{"PATH=/bin", "GTHULHU_UNRELATED=1", "NODE_NAME=n1"}
+	if len(env) != len(want) {
+		t.Fatalf("env=%v, want %v", env, want)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Fatalf("env=%v, want %v", env, want)
+		}
+	}
+}
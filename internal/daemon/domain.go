@@ -66,23 +66,3 @@ type currentConfig struct {
 	SchedulerEnabled  bool
 	MonitoringEnabled bool
 }
-
-var allowedSCXSchedulers = map[string]struct{}{
-	"scx_beerland":    {},
-	"scx_bpfland":     {},
-	"scx_cake":        {},
-	"scx_chaos":       {},
-	"scx_cosmos":      {},
-	"scx_flash":       {},
-	"scx_lavd":        {},
-	"scx_layered":     {},
-	"scx_mitosis":     {},
-	"scx_p2dq":        {},
-	"scx_pandemonium": {},
-	"scx_rlfifo":      {},
-	"scx_rustland":    {},
-	"scx_rusty":       {},
-	"scx_tickless":    {},
-	"scx_timely":      {},
-	"scx_wd40":        {},
-}
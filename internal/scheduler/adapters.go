@@ -5,8 +5,10 @@ import (
 	"log/slog"
 
 	"github.com/Gthulhu/Gthulhu/internal/schedext"
+	"github.com/Gthulhu/Gthulhu/internal/scheduler/prioritysync"
 	"github.com/Gthulhu/Gthulhu/monitor"
 	"github.com/Gthulhu/plugin/plugin"
+	core "github.com/Gthulhu/qumun/goland_core"
 )
 
 type defaultSchedExtChecker struct{}
@@ -26,3 +28,18 @@ type defaultSchedulerPluginFactory struct{}
 func (defaultSchedulerPluginFactory) New(ctx context.Context, cfg *plugin.SchedConfig) (plugin.CustomScheduler, error) {
 	return plugin.NewSchedulerPlugin(ctx, cfg)
 }
+
+// bpfPriorityTaskMap writes priority_tasks through the loaded scheduler and
+// reads it back with bpftool.
+type bpfPriorityTaskMap struct {
+	prioritysync.BpftoolLister
+	sched *core.Sched
+}
+
+func (m bpfPriorityTaskMap) Update(task prioritysync.Task) error {
+	return m.sched.UpdatePriorityTaskWithPrio(task.PID, task.ExecutionTime, task.Priority)
+}
+
+func (m bpfPriorityTaskMap) Remove(pid uint32) error {
+	return m.sched.RemovePriorityTask(pid)
+}
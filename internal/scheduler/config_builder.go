@@ -21,7 +21,10 @@ func buildMonitorConfig(cfg *config.Config) monitor.Config {
 	}
 }
 
-func buildPluginConfig(cfg *config.Config) *plugin.SchedConfig {
+// buildPluginConfig configures the plugin. Its API client is left disabled
+// while the scheduler watches the intents, so it neither polls them nor
+// competes with the watcher's fallback polling.
+func buildPluginConfig(cfg *config.Config, watching bool) *plugin.SchedConfig {
 	schedConfig := cfg.GetSchedulerConfig()
 	pluginConfig := &plugin.SchedConfig{
 		Mode: schedConfig.Mode,
@@ -33,7 +36,7 @@ func buildPluginConfig(cfg *config.Config) *plugin.SchedConfig {
 			BaseURL:       cfg.Api.Url,
 			Interval:      cfg.Api.Interval,
 			PublicKeyPath: cfg.Api.PublicKeyPath,
-			Enabled:       cfg.Api.Enabled,
+			Enabled:       cfg.Api.Enabled && !watching,
 			AuthEnabled:   cfg.Api.AuthEnabled,
 			MTLS: plugin.MTLSConfig{
 				Enable:  cfg.Api.MTLS.Enable,
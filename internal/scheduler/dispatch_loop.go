@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/Gthulhu/Gthulhu/internal/scheduler/intentwatch"
 	"github.com/Gthulhu/plugin/models"
 	core "github.com/Gthulhu/qumun/goland_core"
 )
@@ -11,6 +12,7 @@ import (
 func runSchedulerLoop(
 	ctx context.Context,
 	bpfModule *core.Sched,
+	intentWatcher *intentwatch.Watcher,
 	sliceNsDefault,
 	sliceNsMin uint64,
 ) error {
@@ -48,7 +50,10 @@ func runSchedulerLoop(
 				task.Vtime += min(t.SumExecRuntime, sliceNsDefault*100)
 			}
 
-			customTime := bpfModule.DetermineTimeSlice(t)
+			customTime, ok := watchedTimeSlice(intentWatcher, t.Pid)
+			if !ok {
+				customTime = bpfModule.DetermineTimeSlice(t)
+			}
 			if customTime > 0 {
 				task.SliceNs = min(customTime, (t.StopTs-t.StartTs)*11/10)
 			} else {
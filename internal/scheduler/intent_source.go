@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Gthulhu/Gthulhu/internal/config"
+	"github.com/Gthulhu/Gthulhu/internal/scheduler/intentwatch"
+	"github.com/Gthulhu/plugin/plugin"
+)
+
+// startIntentWatcher watches the decision maker's scheduling intents so the
+// scheduler picks them up as soon as they change, and polls them every
+// api.interval while the watch is down. In user-space mode the intents are
+// looked up as soon as they arrive, so every revision is acknowledged on
+// receipt; kernel mode acknowledges it once the priority map is synced. It returns nil when the API is
+// disabled or the watch cannot be set up; the plugin's polling is used then.
+func startIntentWatcher(ctx context.Context, cfg *config.Config) *intentwatch.Watcher {
+	if !cfg.Api.Enabled || cfg.Api.Url == "" {
+		return nil
+	}
+	httpClient := http.DefaultClient
+	if cfg.Api.MTLS.Enable {
+		client, err := intentwatch.NewMTLSClient(cfg.Api.MTLS.CertPem, cfg.Api.MTLS.KeyPem, cfg.Api.MTLS.CAPem)
+		if err != nil {
+			slog.Warn("scheduling intent watch disabled, polling only", "error", err)
+			return nil
+		}
+		httpClient = client
+	}
+	opts := intentwatch.Options{
+		BaseURL:      cfg.Api.Url,
+		HTTPClient:   httpClient,
+		PollInterval: time.Duration(cfg.Api.Interval) * time.Second,
+		AckOnReceive: !cfg.Scheduler.KernelMode,
+		Logger:       slog.Default(),
+	}
+	if cfg.Api.AuthEnabled {
+		opts.Token = intentwatch.NewTokenSource(cfg.Api.Url, cfg.Api.PublicKeyPath, httpClient)
+	}
+	watcher := intentwatch.NewWatcher(opts)
+	go watcher.Run(ctx)
+	return watcher
+}
+
+// changedIntents returns the intent changes from the watcher, which polls
+// while its watch is down, or from the plugin's polling without a watcher.
+func changedIntents(p plugin.CustomScheduler, watcher *intentwatch.Watcher) (changed, removed []intentwatch.Intent) {
+	if watcher != nil {
+		return watcher.Changes()
+	}
+	polledChanged, polledRemoved := p.GetChangedStrategies()
+	for _, strategy := range polledChanged {
+		changed = append(changed, intentwatch.Intent{
+			PID:           int(strategy.PID),
+			Priority:      int(strategy.Priority),
+			ExecutionTime: uint64(strategy.ExecutionTime),
+		})
+	}
+	for _, strategy := range polledRemoved {
+		removed = append(removed, intentwatch.Intent{PID: int(strategy.PID)})
+	}
+	return changed, removed
+}
+
+// watchedTimeSlice returns the time slice the watched intent of pid asks
+// for, so user-space scheduling follows the watch as well, since the plugin
+// does not poll while it is used. It reports false without a watch or
+// intent; the plugin decides the slice then.
+func watchedTimeSlice(watcher *intentwatch.Watcher, pid int32) (uint64, bool) {
+	if watcher == nil {
+		return 0, false
+	}
+	intent, ok := watcher.Lookup(int(pid))
+	if !ok || intent.ExecutionTime == 0 {
+		return 0, false
+	}
+	return intent.ExecutionTime, true
+}
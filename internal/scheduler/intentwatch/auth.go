@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package intentwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenPath = "/api/v1/auth/token"
+	clientID  = "gthulhu-scheduler"
+	// tokenRefreshMargin renews a token this long before it expires.
+	tokenRefreshMargin = time.Minute
+)
+
+// NewTokenSource returns a Token func that exchanges the public key at
+// publicKeyPath for a JWT, like the scheduler plugin does, and caches it
+// until shortly before it expires.
+func NewTokenSource(baseURL, publicKeyPath string, client *http.Client) func(ctx context.Context) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + tokenPath
+	var (
+		mu        sync.Mutex
+		token     string
+		expiredAt time.Time
+	)
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if token != "" && time.Now().Add(tokenRefreshMargin).Before(expiredAt) {
+			return token, nil
+		}
+		publicKey, err := os.ReadFile(publicKeyPath)
+		if err != nil {
+			return "", fmt.Errorf("read public key: %w", err)
+		}
+		body, err := json.Marshal(map[string]string{
+			"public_key": string(publicKey),
+			"client_id":  clientID,
+		})
+		if err != nil {
+			return "", err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("decision maker returned %s", resp.Status)
+		}
+		var tokenResp struct {
+			Data *struct {
+				Token     string `json:"token"`
+				ExpiredAt int64  `json:"expired_at"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+			return "", fmt.Errorf("decode token: %w", err)
+		}
+		if tokenResp.Data == nil || tokenResp.Data.Token == "" {
+			return "", fmt.Errorf("decision maker returned an empty token")
+		}
+		token = tokenResp.Data.Token
+		expiredAt = time.Unix(tokenResp.Data.ExpiredAt, 0)
+		return token, nil
+	}
+}
+
+// NewMTLSClient returns an HTTP client presenting the certificate at
+// certPath and trusting only the CA at caPath. The watch stream stays open,
+// so the client has no overall timeout.
+func NewMTLSClient(certPath, keyPath, caPath string) (*http.Client, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load client certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no CA certificates found in %s", caPath)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caPool,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return &http.Client{Transport: transport}, nil
+}
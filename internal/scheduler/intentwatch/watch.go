@@ -0,0 +1,422 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package intentwatch follows the decision maker's scheduling intents over
+// its server-sent events endpoint, GET /api/v1/scheduling/strategies/watch,
+// instead of polling GET /api/v1/scheduling/strategies.
+//
+// The Watcher keeps the last revision it saw and resumes from it after a
+// reconnect. Once the scheduler applied a revision's intents, Ack tells the
+// decision maker so, which reports them Applied. While it is disconnected,
+// the Watcher polls the intents every PollInterval instead. The scheduler does not set up the plugin's API
+// client while it watches, so the Watcher also reports the scheduler's
+// metrics.
+package intentwatch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	watchPath         = "/api/v1/scheduling/strategies/watch"
+	pollPath          = "/api/v1/scheduling/strategies"
+	metricsPath       = "/api/v1/metrics"
+	ackPath           = "/api/v1/intents/ack"
+	minRetryBackoff   = time.Second
+	maxRetryBackoff   = 30 * time.Second
+	maxEventLineBytes = 16 << 20
+)
+
+// Intent is one resolved scheduling intent of a process.
+type Intent struct {
+	PID           int    `json:"pid,omitempty"`
+	Priority      int    `json:"priority"`
+	ExecutionTime uint64 `json:"execution_time"`
+}
+
+// Event is one event of the stream. A snapshot carries every intent in
+// Upserted.
+type Event struct {
+	Revision uint64   `json:"revision"`
+	Snapshot bool     `json:"-"`
+	Upserted []Intent `json:"upserted"`
+	Removed  []Intent `json:"removed,omitempty"`
+}
+
+// Options configures a Watcher.
+type Options struct {
+	// BaseURL is the decision maker URL, the same as api.url.
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token returns the bearer token sent with every request; no
+	// Authorization header is sent when it is nil.
+	Token func(ctx context.Context) (string, error)
+	// PollInterval is how often the intents are polled while the watch is
+	// down; they are not polled when it is zero.
+	PollInterval time.Duration
+	// AckOnReceive acknowledges every watched revision as soon as it is
+	// received, for a scheduler that looks the intents up rather than
+	// applying Changes.
+	AckOnReceive bool
+	Logger       *slog.Logger
+}
+
+// Watcher applies the watched events to a desired view of the intents and
+// hands out the changes since the last call to Changes.
+type Watcher struct {
+	mu        sync.Mutex
+	desired   map[int]Intent
+	changed   map[int]Intent
+	removed   map[int]Intent
+	revision  uint64
+	acked     uint64
+	connected bool
+
+	baseURL      string
+	httpClient   *http.Client
+	token        func(ctx context.Context) (string, error)
+	pollInterval time.Duration
+	lastPoll     time.Time
+	ackOnReceive bool
+	logger       *slog.Logger
+}
+
+// NewWatcher creates a Watcher; call Run to start watching.
+func NewWatcher(opts Options) *Watcher {
+	w := &Watcher{
+		desired:      make(map[int]Intent),
+		changed:      make(map[int]Intent),
+		removed:      make(map[int]Intent),
+		baseURL:      strings.TrimRight(opts.BaseURL, "/"),
+		httpClient:   opts.HTTPClient,
+		token:        opts.Token,
+		pollInterval: opts.PollInterval,
+		ackOnReceive: opts.AckOnReceive,
+		logger:       opts.Logger,
+	}
+	if w.httpClient == nil {
+		w.httpClient = http.DefaultClient
+	}
+	if w.logger == nil {
+		w.logger = slog.Default()
+	}
+	return w
+}
+
+// Run watches until ctx is done, reconnecting with backoff and polling in
+// the meantime.
+func (w *Watcher) Run(ctx context.Context) {
+	backoff := minRetryBackoff
+	for {
+		err := w.watch(ctx)
+		w.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.logger.Warn("scheduling intent watch failed, polling until it reconnects", "error", err, "retryIn", backoff)
+		} else {
+			backoff = minRetryBackoff
+		}
+		if !w.pollUntilRetry(ctx, backoff) {
+			return
+		}
+		if err != nil {
+			backoff = min(backoff*2, maxRetryBackoff)
+		}
+	}
+}
+
+// Connected reports whether the watch stream is up; the intents are polled
+// while it is not.
+func (w *Watcher) Connected() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.connected
+}
+
+// Lookup returns the last watched or polled intent of pid.
+func (w *Watcher) Lookup(pid int) (Intent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if pid == 0 {
+		return Intent{}, false
+	}
+	intent, ok := w.desired[pid]
+	return intent, ok
+}
+
+// Revision returns the last watched revision. Read it before Changes and
+// pass it to Ack once the changes are applied.
+func (w *Watcher) Revision() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.revision
+}
+
+// Ack tells the decision maker that the intents of revision are applied. It
+// does nothing for revision zero or a revision already acknowledged.
+func (w *Watcher) Ack(ctx context.Context, revision uint64) error {
+	w.mu.Lock()
+	acked := w.acked
+	w.mu.Unlock()
+	if revision == 0 || revision <= acked {
+		return nil
+	}
+	body, err := json.Marshal(struct {
+		Revision uint64 `json:"revision"`
+	}{Revision: revision})
+	if err != nil {
+		return err
+	}
+	if err := w.post(ctx, ackPath, body); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.acked = max(w.acked, revision)
+	return nil
+}
+
+// Changes returns the intents added or changed and the ones removed since the
+// previous call.
+func (w *Watcher) Changes() (changed, removed []Intent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, intent := range w.changed {
+		changed = append(changed, intent)
+		delete(w.changed, key)
+	}
+	for key, intent := range w.removed {
+		removed = append(removed, intent)
+		delete(w.removed, key)
+	}
+	return changed, removed
+}
+
+func (w *Watcher) setConnected(connected bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.connected = connected
+}
+
+// pollUntilRetry polls the intents every PollInterval until the next watch
+// attempt is due after backoff. It returns false when ctx is done.
+func (w *Watcher) pollUntilRetry(ctx context.Context, backoff time.Duration) bool {
+	retry := time.NewTimer(backoff)
+	defer retry.Stop()
+	for {
+		var nextPoll <-chan time.Time
+		if w.pollInterval > 0 {
+			wait := w.pollInterval - time.Since(w.lastPoll)
+			if wait <= 0 {
+				w.lastPoll = time.Now()
+				if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+					w.logger.Warn("failed to poll scheduling intents", "error", err)
+				}
+				continue
+			}
+			nextPoll = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-retry.C:
+			return true
+		case <-nextPoll:
+		}
+	}
+}
+
+// poll fetches every intent and applies them like a snapshot.
+func (w *Watcher) poll(ctx context.Context) error {
+	req, err := w.newRequest(ctx, http.MethodGet, pollPath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("decision maker returned %s", resp.Status)
+	}
+	var body struct {
+		Scheduling []Intent `json:"scheduling"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode intents: %w", err)
+	}
+	w.apply(Event{Snapshot: true, Upserted: body.Scheduling})
+	return nil
+}
+
+// Metrics are the scheduler counters reported to the decision maker.
+type Metrics struct {
+	UserschedLastRunAt uint64 `json:"usersched_last_run_at"`
+	NrQueued           uint64 `json:"nr_queued"`
+	NrScheduled        uint64 `json:"nr_scheduled"`
+	NrRunning          uint64 `json:"nr_running"`
+	NrOnlineCpus       uint64 `json:"nr_online_cpus"`
+	NrUserDispatches   uint64 `json:"nr_user_dispatches"`
+	NrKernelDispatches uint64 `json:"nr_kernel_dispatches"`
+	NrCancelDispatches uint64 `json:"nr_cancel_dispatches"`
+	NrBounceDispatches uint64 `json:"nr_bounce_dispatches"`
+	NrFailedDispatches uint64 `json:"nr_failed_dispatches"`
+	NrSchedCongested   uint64 `json:"nr_sched_congested"`
+}
+
+// SendMetrics reports metrics to the decision maker.
+func (w *Watcher) SendMetrics(ctx context.Context, metrics Metrics) error {
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	return w.post(ctx, metricsPath, body)
+}
+
+func (w *Watcher) post(ctx context.Context, path string, body []byte) error {
+	req, err := w.newRequest(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("decision maker returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *Watcher) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, w.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if w.token != nil {
+		token, err := w.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	req, err := w.newRequest(ctx, http.MethodGet, watchPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	w.mu.Lock()
+	if w.revision != 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(w.revision, 10))
+	}
+	w.mu.Unlock()
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("decision maker returned %s", resp.Status)
+	}
+	w.setConnected(true)
+	w.logger.Info("watching scheduling intents", "url", w.baseURL+watchPath)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventLineBytes)
+	var name, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data != "" {
+				if err := w.handle(name, data); err != nil {
+					return err
+				}
+				if w.ackOnReceive {
+					if err := w.Ack(ctx, w.Revision()); err != nil && ctx.Err() == nil {
+						w.logger.Warn("failed to acknowledge scheduling intents", "error", err)
+					}
+				}
+			}
+			name, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// Heartbeat.
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("decision maker closed the watch")
+}
+
+func (w *Watcher) handle(name, data string) error {
+	var event Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return fmt.Errorf("decode %s event: %w", name, err)
+	}
+	event.Snapshot = name == "snapshot"
+	w.apply(event)
+	return nil
+}
+
+func (w *Watcher) apply(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if event.Snapshot {
+		// Everything the snapshot does not list is gone.
+		next := make(map[int]Intent, len(event.Upserted))
+		for _, intent := range event.Upserted {
+			next[intent.PID] = intent
+		}
+		for key, intent := range w.desired {
+			if _, ok := next[key]; !ok {
+				event.Removed = append(event.Removed, intent)
+			}
+		}
+	}
+	for _, intent := range event.Removed {
+		delete(w.desired, intent.PID)
+		delete(w.changed, intent.PID)
+		w.removed[intent.PID] = intent
+	}
+	for _, intent := range event.Upserted {
+		if prev, ok := w.desired[intent.PID]; ok && prev == intent {
+			continue
+		}
+		w.desired[intent.PID] = intent
+		delete(w.removed, intent.PID)
+		w.changed[intent.PID] = intent
+	}
+	// Polled intents carry no revision; the watch resumes from the last
+	// one it saw.
+	if event.Revision != 0 {
+		w.revision = event.Revision
+	}
+}
@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package intentwatch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sortIntents(intents []Intent) []Intent {
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].PID < intents[j].PID
+	})
+	return intents
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatcherAppliesSnapshotAndDiffs(t *testing.T) {
+	lastEventIDs := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != watchPath || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		lastEventIDs <- r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "id: 7\nevent: snapshot\ndata: {\"revision\":7,\"upserted\":[{\"pid\":1,\"priority\":1,\"execution_time\":10},{\"pid\":3,\"priority\":1,\"execution_time\":10}]}\n\n")
+		fmt.Fprint(w, "id: 8\nevent: diff\ndata: {\"revision\":8,\"upserted\":[{\"pid\":2,\"priority\":2,\"execution_time\":20}],\"removed\":[{\"pid\":1,\"priority\":1,\"execution_time\":10}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{
+		BaseURL: server.URL,
+		Token:   func(context.Context) (string, error) { return "token", nil },
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	if got := <-lastEventIDs; got != "" {
+		t.Fatalf("first watch sent Last-Event-ID %q", got)
+	}
+	waitFor(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.revision == 8
+	})
+	if !w.Connected() {
+		t.Fatal("expected watcher to be connected")
+	}
+	changed, removed := w.Changes()
+	// pid 1 was added and removed again before anyone asked.
+	want := []Intent{{PID: 2, Priority: 2, ExecutionTime: 20}, {PID: 3, Priority: 1, ExecutionTime: 10}}
+	if got := sortIntents(changed); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("changed = %v, want %v", got, want)
+	}
+	if len(removed) != 1 || removed[0].PID != 1 {
+		t.Fatalf("removed = %v, want pid 1", removed)
+	}
+	if changed, removed := w.Changes(); len(changed) != 0 || len(removed) != 0 {
+		t.Fatalf("changes not drained: %v %v", changed, removed)
+	}
+}
+
+func TestWatcherSnapshotReplacesIntents(t *testing.T) {
+	w := NewWatcher(Options{})
+	w.apply(Event{Revision: 1, Snapshot: true, Upserted: []Intent{{PID: 1, Priority: 1}, {PID: 2, Priority: 1}}})
+	w.Changes()
+
+	w.apply(Event{Revision: 5, Snapshot: true, Upserted: []Intent{{PID: 2, Priority: 1}, {PID: 3, Priority: 1}}})
+	changed, removed := w.Changes()
+	if len(changed) != 1 || changed[0].PID != 3 {
+		t.Fatalf("changed = %v, want pid 3", changed)
+	}
+	if len(removed) != 1 || removed[0].PID != 1 {
+		t.Fatalf("removed = %v, want pid 1", removed)
+	}
+}
+
+func TestWatcherResumesFromLastRevision(t *testing.T) {
+	lastEventIDs := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs <- r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 3\nevent: snapshot\ndata: {\"revision\":3,\"upserted\":[]}\n\n")
+		// Closing the stream makes the watcher reconnect.
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{BaseURL: server.URL, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	<-lastEventIDs
+	select {
+	case got := <-lastEventIDs:
+		if got != "3" {
+			t.Fatalf("reconnect sent Last-Event-ID %q, want 3", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("watcher did not reconnect")
+	}
+}
+
+func TestWatcherLookup(t *testing.T) {
+	w := NewWatcher(Options{})
+	w.apply(Event{Revision: 1, Snapshot: true, Upserted: []Intent{{PID: 1, Priority: 1, ExecutionTime: 10}}})
+	if got, ok := w.Lookup(1); !ok || got.ExecutionTime != 10 {
+		t.Fatalf("Lookup(1) = %v, %v, want execution time 10", got, ok)
+	}
+	if _, ok := w.Lookup(2); ok {
+		t.Fatal("lookup found an unknown pid")
+	}
+}
+
+func TestWatcherPollsWhileDisconnected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != pollPath || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "no watch here", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"scheduling":[{"pid":1,"priority":1,"execution_time":10}]}`)
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{
+		BaseURL:      server.URL,
+		Token:        func(context.Context) (string, error) { return "token", nil },
+		PollInterval: 10 * time.Millisecond,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	w.apply(Event{Revision: 4, Snapshot: true, Upserted: []Intent{{PID: 2, Priority: 1}}})
+	w.Changes()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	waitFor(t, func() bool {
+		_, ok := w.Lookup(1)
+		return ok
+	})
+	if w.Connected() {
+		t.Fatal("expected watcher to be disconnected")
+	}
+	changed, removed := w.Changes()
+	if len(changed) != 1 || changed[0].PID != 1 {
+		t.Fatalf("changed = %v, want pid 1", changed)
+	}
+	if len(removed) != 1 || removed[0].PID != 2 {
+		t.Fatalf("removed = %v, want pid 2", removed)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.revision != 4 {
+		t.Fatalf("revision = %d, want 4 kept for the next watch", w.revision)
+	}
+}
+
+func TestWatcherSendMetrics(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != metricsPath {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{BaseURL: server.URL})
+	if err := w.SendMetrics(context.Background(), Metrics{NrQueued: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-bodies; !strings.Contains(got, `"nr_queued":3`) {
+		t.Fatalf("body = %s, want nr_queued 3", got)
+	}
+}
+
+func TestWatcherAck(t *testing.T) {
+	bodies := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != ackPath {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{BaseURL: server.URL})
+	for _, revision := range []uint64{0, 5, 5, 4} {
+		if err := w.Ack(context.Background(), revision); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := <-bodies; got != `{"revision":5}` {
+		t.Fatalf("body = %s, want revision 5", got)
+	}
+	select {
+	case got := <-bodies:
+		t.Fatalf("acknowledged again: %s", got)
+	default:
+	}
+}
+
+func TestWatcherAcksOnReceive(t *testing.T) {
+	acks := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ackPath {
+			body, _ := io.ReadAll(r.Body)
+			acks <- string(body)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "id: 7\nevent: snapshot\ndata: {\"revision\":7,\"upserted\":[{\"pid\":1,\"priority\":1}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	w := NewWatcher(Options{
+		BaseURL:      server.URL,
+		AckOnReceive: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	select {
+	case got := <-acks:
+		if got != `{"revision":7}` {
+			t.Fatalf("ack = %s, want revision 7", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not acknowledge the snapshot")
+	}
+}
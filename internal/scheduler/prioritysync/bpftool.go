@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prioritysync
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TasksMapName maps a PID to its execution time.
+	TasksMapName = "priority_tasks"
+	// PrioMapName maps a PID to its priority. BPF object names are truncated
+	// to 15 characters, so priority_tasks_prio shows up as priority_tasks_.
+	PrioMapName = "priority_tasks_"
+)
+
+// BpftoolLister reads the priority maps with `bpftool -j map dump`, the same
+// tool `gthulhu-cli priority-map` uses, so the reconciler compares against
+// what the kernel actually holds rather than what user space last wrote.
+type BpftoolLister struct {
+	// Path is the bpftool binary; it defaults to "bpftool" from PATH.
+	Path string
+}
+
+// List returns the merged content of the priority_tasks maps.
+func (l BpftoolLister) List() (map[uint32]Task, error) {
+	tasks, err := l.dump(TasksMapName)
+	if err != nil {
+		return nil, err
+	}
+	prio, err := l.dump(PrioMapName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBpftoolDump(tasks, prio)
+}
+
+func (l BpftoolLister) dump(mapName string) ([]byte, error) {
+	path := l.Path
+	if path == "" {
+		path = "bpftool"
+	}
+	out, err := exec.Command(path, "-j", "map", "dump", "name", mapName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("bpftool map dump %s: %w", mapName, err)
+	}
+	return out, nil
+}
+
+type bpftoolEntry struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ParseBpftoolDump merges the JSON dumps of the execution time and priority
+// maps into Tasks. Both the BTF-formatted (plain numbers) and raw (hex byte
+// arrays, little endian) bpftool outputs are accepted.
+func ParseBpftoolDump(tasksJSON, prioJSON []byte) (map[uint32]Task, error) {
+	var taskEntries, prioEntries []bpftoolEntry
+	if err := json.Unmarshal(tasksJSON, &taskEntries); err != nil {
+		return nil, fmt.Errorf("decode %s dump: %w", TasksMapName, err)
+	}
+	if len(prioJSON) > 0 {
+		if err := json.Unmarshal(prioJSON, &prioEntries); err != nil {
+			return nil, fmt.Errorf("decode %s dump: %w", PrioMapName, err)
+		}
+	}
+
+	tasks := make(map[uint32]Task, len(taskEntries))
+	for _, entry := range taskEntries {
+		pid, err := decodeUint(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s key: %w", TasksMapName, err)
+		}
+		execTime, err := decodeUint(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s value for pid %d: %w", TasksMapName, pid, err)
+		}
+		tasks[uint32(pid)] = Task{PID: uint32(pid), ExecutionTime: execTime}
+	}
+	for _, entry := range prioEntries {
+		pid, err := decodeUint(entry.Key)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s key: %w", PrioMapName, err)
+		}
+		prio, err := decodeUint(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s value for pid %d: %w", PrioMapName, pid, err)
+		}
+		task, ok := tasks[uint32(pid)]
+		if !ok {
+			// A priority without an execution time entry is still drift the
+			// reconciler has to clean up.
+			task = Task{PID: uint32(pid)}
+		}
+		task.Priority = uint32(prio)
+		tasks[uint32(pid)] = task
+	}
+	return tasks, nil
+}
+
+func decodeUint(raw json.RawMessage) (uint64, error) {
+	var n uint64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n, nil
+	}
+	var hexBytes []string
+	if err := json.Unmarshal(raw, &hexBytes); err != nil {
+		return 0, fmt.Errorf("unsupported value %s", string(raw))
+	}
+	if len(hexBytes) > 8 {
+		return 0, fmt.Errorf("value of %d bytes does not fit in 64 bits", len(hexBytes))
+	}
+	buf := make([]byte, 8)
+	for i, h := range hexBytes {
+		b, err := strconv.ParseUint(strings.TrimPrefix(h, "0x"), 16, 8)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte %q: %w", h, err)
+		}
+		buf[i] = byte(b)
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
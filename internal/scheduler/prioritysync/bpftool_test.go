@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prioritysync
+
+import "testing"
+
+func TestParseBpftoolDump(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks string
+		prio  string
+		want  map[uint32]Task
+	}{
+		{
+			name:  "btf formatted",
+			tasks: `[{"key":1234,"value":20000000},{"key":42,"value":5}]`,
+			prio:  `[{"key":1234,"value":3}]`,
+			want: map[uint32]Task{
+				1234: {PID: 1234, ExecutionTime: 20000000, Priority: 3},
+				42:   {PID: 42, ExecutionTime: 5},
+			},
+		},
+		{
+			name:  "raw bytes",
+			tasks: `[{"key":["0xd2","0x04","0x00","0x00"],"value":["0x00","0x2d","0x31","0x01","0x00","0x00","0x00","0x00"]}]`,
+			prio:  `[{"key":["0xd2","0x04","0x00","0x00"],"value":["0x03","0x00","0x00","0x00"]}]`,
+			want:  map[uint32]Task{1234: {PID: 1234, ExecutionTime: 20000000, Priority: 3}},
+		},
+		{
+			name:  "priority only",
+			tasks: `[]`,
+			prio:  `[{"key":7,"value":1}]`,
+			want:  map[uint32]Task{7: {PID: 7, Priority: 1}},
+		},
+		{name: "empty", tasks: `[]`, want: map[uint32]Task{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBpftoolDump([]byte(tt.tasks), []byte(tt.prio))
+			if err != nil {
+				t.Fatalf("ParseBpftoolDump() error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBpftoolDump() = %+v, want %+v", got, tt.want)
+			}
+			for pid, task := range tt.want {
+				if got[pid] != task {
+					t.Fatalf("pid %d = %+v, want %+v", pid, got[pid], task)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBpftoolDumpRejectsGarbage(t *testing.T) {
+	if _, err := ParseBpftoolDump([]byte(`[{"key":"pid","value":1}]`), nil); err == nil {
+		t.Fatalf("expected error for non-numeric key")
+	}
+	if _, err := ParseBpftoolDump([]byte(`not json`), nil); err == nil {
+		t.Fatalf("expected error for invalid JSON")
+	}
+}
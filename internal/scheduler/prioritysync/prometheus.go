@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prioritysync
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	metricsNamespace = "gthulhu"
+	metricsSubsystem = "priority_sync"
+)
+
+// Collector exposes the Reconciler counters to Prometheus.
+type Collector struct {
+	reconciler *Reconciler
+
+	reconciles     *prometheus.Desc
+	listFailures   *prometheus.Desc
+	updateFailures *prometheus.Desc
+	removeFailures *prometheus.Desc
+	drift          *prometheus.Desc
+	exitedPIDs     *prometheus.Desc
+	desired        *prometheus.Desc
+	outOfSync      *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a Prometheus collector for r.
+func NewCollector(r *Reconciler) *Collector {
+	return &Collector{
+		reconciler: r,
+		reconciles: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "reconciles_total"),
+			"Total priority_tasks reconcile passes",
+			nil, nil,
+		),
+		listFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "list_failures_total"),
+			"Total failures to read the priority_tasks BPF map",
+			nil, nil,
+		),
+		updateFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "update_failures_total"),
+			"Total failed priority_tasks updates",
+			nil, nil,
+		),
+		removeFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "remove_failures_total"),
+			"Total failed priority_tasks removals",
+			nil, nil,
+		),
+		drift: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "drift_total"),
+			"Total priority_tasks entries found diverged from the intents, by kind (missing, mismatch, stale)",
+			[]string{"kind"}, nil,
+		),
+		exitedPIDs: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "exited_pids_total"),
+			"Total priority_tasks entries garbage-collected because the process exited",
+			nil, nil,
+		),
+		desired: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "desired_tasks"),
+			"Number of priority tasks requested by the current intents",
+			nil, nil,
+		),
+		outOfSync: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "out_of_sync_tasks"),
+			"Number of priority_tasks entries left diverged after the last reconcile pass",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.reconciles
+	ch <- c.listFailures
+	ch <- c.updateFailures
+	ch <- c.removeFailures
+	ch <- c.drift
+	ch <- c.exitedPIDs
+	ch <- c.desired
+	ch <- c.outOfSync
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.reconciler.Stats()
+	ch <- prometheus.MustNewConstMetric(c.reconciles, prometheus.CounterValue, float64(s.Reconciles))
+	ch <- prometheus.MustNewConstMetric(c.listFailures, prometheus.CounterValue, float64(s.ListFailures))
+	ch <- prometheus.MustNewConstMetric(c.updateFailures, prometheus.CounterValue, float64(s.UpdateFailures))
+	ch <- prometheus.MustNewConstMetric(c.removeFailures, prometheus.CounterValue, float64(s.RemoveFailures))
+	ch <- prometheus.MustNewConstMetric(c.drift, prometheus.CounterValue, float64(s.DriftMissing), "missing")
+	ch <- prometheus.MustNewConstMetric(c.drift, prometheus.CounterValue, float64(s.DriftMismatch), "mismatch")
+	ch <- prometheus.MustNewConstMetric(c.drift, prometheus.CounterValue, float64(s.DriftStale), "stale")
+	ch <- prometheus.MustNewConstMetric(c.exitedPIDs, prometheus.CounterValue, float64(s.ExitedPIDs))
+	ch <- prometheus.MustNewConstMetric(c.desired, prometheus.GaugeValue, float64(s.Desired))
+	ch <- prometheus.MustNewConstMetric(c.outOfSync, prometheus.GaugeValue, float64(s.OutOfSync))
+}
@@ -0,0 +1,262 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package prioritysync keeps the kernel-mode priority_tasks BPF map in line
+// with the strategies received from the decision maker.
+//
+// The Reconciler holds the desired state, diffs it against what is actually
+// in the map, retries failed writes with backoff and removes entries whose
+// process has exited, so a partial failure cannot leave the map silently
+// diverged from the intents.
+package prioritysync
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultResyncInterval is how often the map is diffed even when no
+	// strategy changed, to catch exited PIDs and out-of-band writes.
+	DefaultResyncInterval = 30 * time.Second
+	minRetryBackoff       = time.Second
+	maxRetryBackoff       = 30 * time.Second
+)
+
+// Task is one priority_tasks entry.
+type Task struct {
+	PID           uint32
+	ExecutionTime uint64
+	Priority      uint32
+}
+
+// Map is the priority_tasks BPF map.
+type Map interface {
+	Update(task Task) error
+	Remove(pid uint32) error
+	// List returns the entries currently stored in the map.
+	List() (map[uint32]Task, error)
+}
+
+// Stats are the cumulative reconcile counters.
+type Stats struct {
+	Reconciles     uint64
+	ListFailures   uint64
+	UpdateFailures uint64
+	RemoveFailures uint64
+	// DriftMissing counts desired entries that were absent from the map.
+	DriftMissing uint64
+	// DriftMismatch counts entries whose values differed from the desired ones.
+	DriftMismatch uint64
+	// DriftStale counts map entries that were not desired anymore.
+	DriftStale uint64
+	// ExitedPIDs counts entries garbage-collected because the process exited.
+	ExitedPIDs uint64
+	// Desired is the current number of desired entries.
+	Desired int
+	// OutOfSync is the number of entries left diverged after the last pass.
+	OutOfSync int
+}
+
+// Options configures a Reconciler.
+type Options struct {
+	ResyncInterval time.Duration
+	// ProcessAlive reports whether pid still exists; it defaults to checking
+	// /proc/<pid>.
+	ProcessAlive func(pid uint32) bool
+	Logger       *slog.Logger
+	Now          func() time.Time
+}
+
+// Reconciler keeps the desired priority_tasks view and reconciles it into a Map.
+type Reconciler struct {
+	mu      sync.Mutex
+	desired map[uint32]Task
+	dirty   bool
+
+	lastSync time.Time
+	retryAt  time.Time
+	backoff  time.Duration
+
+	stats Stats
+
+	resyncInterval time.Duration
+	processAlive   func(pid uint32) bool
+	logger         *slog.Logger
+	now            func() time.Time
+}
+
+// NewReconciler creates a Reconciler with an empty desired state.
+func NewReconciler(opts Options) *Reconciler {
+	r := &Reconciler{
+		desired:        make(map[uint32]Task),
+		resyncInterval: opts.ResyncInterval,
+		processAlive:   opts.ProcessAlive,
+		logger:         opts.Logger,
+		now:            opts.Now,
+	}
+	if r.resyncInterval <= 0 {
+		r.resyncInterval = DefaultResyncInterval
+	}
+	if r.processAlive == nil {
+		r.processAlive = procAlive
+	}
+	if r.logger == nil {
+		r.logger = slog.Default()
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+	return r
+}
+
+// Apply records strategy changes in the desired state. It does not touch the
+// map; the next Sync does.
+func (r *Reconciler) Apply(changed []Task, removed []uint32) {
+	if len(changed) == 0 && len(removed) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, task := range changed {
+		r.desired[task.PID] = task
+	}
+	for _, pid := range removed {
+		delete(r.desired, pid)
+	}
+	r.stats.Desired = len(r.desired)
+	r.dirty = true
+}
+
+// Sync reconciles the map when the desired state changed, a failed pass is
+// due for retry, or the resync interval elapsed. It reports whether a pass ran.
+func (r *Reconciler) Sync(m Map) (bool, error) {
+	r.mu.Lock()
+	now := r.now()
+	due := r.dirty
+	if r.backoff > 0 {
+		due = due || !now.Before(r.retryAt)
+	} else {
+		due = due || now.Sub(r.lastSync) >= r.resyncInterval
+	}
+	r.mu.Unlock()
+	if !due {
+		return false, nil
+	}
+	return true, r.Reconcile(m)
+}
+
+// Reconcile runs a full diff of the desired state against the map contents.
+// Entries that could not be written stay out of sync and are retried with
+// exponential backoff. When the map cannot be listed, every desired entry is
+// written and stale entries are removed on the retry.
+func (r *Reconciler) Reconcile(m Map) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.Reconciles++
+	r.dirty = false
+	r.lastSync = r.now()
+
+	// Without the map contents the desired entries are still written, so a
+	// failing dump cannot stop priority updates; only stale entries, which
+	// cannot be told apart, are left for the retry.
+	actual, listErr := m.List()
+	if listErr != nil {
+		r.stats.ListFailures++
+		r.logger.Warn("list priority tasks failed, writing desired entries without removing stale ones", "error", listErr)
+	}
+
+	outOfSync := 0
+	var firstErr error
+	recordErr := func(err error) {
+		outOfSync++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for pid, want := range r.desired {
+		if !r.processAlive(pid) {
+			// Its map entry, if any, is removed below as not desired.
+			delete(r.desired, pid)
+			r.logger.Info("dropping priority task for exited process", "pid", pid)
+			continue
+		}
+		if listErr == nil {
+			got, ok := actual[pid]
+			if ok && got == want {
+				continue
+			}
+			if ok {
+				r.stats.DriftMismatch++
+				r.logger.Warn("priority task drifted from intent", "pid", pid, "want", want, "got", got)
+			} else {
+				r.stats.DriftMissing++
+			}
+		}
+		if err := m.Update(want); err != nil {
+			r.stats.UpdateFailures++
+			r.logger.Warn("update priority task failed", "pid", pid, "error", err)
+			recordErr(fmt.Errorf("update pid %d: %w", pid, err))
+		}
+	}
+
+	for pid := range actual {
+		if _, ok := r.desired[pid]; ok {
+			continue
+		}
+		if r.processAlive(pid) {
+			r.stats.DriftStale++
+		} else {
+			r.stats.ExitedPIDs++
+		}
+		if err := m.Remove(pid); err != nil {
+			r.stats.RemoveFailures++
+			r.logger.Warn("remove priority task failed", "pid", pid, "error", err)
+			recordErr(fmt.Errorf("remove pid %d: %w", pid, err))
+		}
+	}
+
+	r.stats.Desired = len(r.desired)
+	r.stats.OutOfSync = outOfSync
+	if listErr != nil {
+		r.scheduleRetry()
+		if firstErr != nil {
+			return fmt.Errorf("list priority tasks: %w; %d priority task(s) out of sync, first error: %w", listErr, outOfSync, firstErr)
+		}
+		return fmt.Errorf("list priority tasks: %w", listErr)
+	}
+	if firstErr != nil {
+		r.scheduleRetry()
+		return fmt.Errorf("%d priority task(s) out of sync, first error: %w", outOfSync, firstErr)
+	}
+	r.backoff = 0
+	return nil
+}
+
+// Stats returns a snapshot of the reconcile counters.
+func (r *Reconciler) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+func (r *Reconciler) scheduleRetry() {
+	if r.backoff == 0 {
+		r.backoff = minRetryBackoff
+	} else {
+		r.backoff = min(r.backoff*2, maxRetryBackoff)
+	}
+	r.retryAt = r.now().Add(r.backoff)
+}
+
+func procAlive(pid uint32) bool {
+	_, err := os.Stat("/proc/" + strconv.FormatUint(uint64(pid), 10))
+	return err == nil
+}
@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2025 Gthulhu Team
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prioritysync
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeMap struct {
+	entries    map[uint32]Task
+	failUpdate map[uint32]bool
+	failRemove map[uint32]bool
+	listErr    error
+	updates    int
+	removes    int
+}
+
+func newFakeMap() *fakeMap {
+	return &fakeMap{
+		entries:    make(map[uint32]Task),
+		failUpdate: make(map[uint32]bool),
+		failRemove: make(map[uint32]bool),
+	}
+}
+
+func (m *fakeMap) Update(task Task) error {
+	m.updates++
+	if m.failUpdate[task.PID] {
+		return errors.New("update failed")
+	}
+	m.entries[task.PID] = task
+	return nil
+}
+
+func (m *fakeMap) Remove(pid uint32) error {
+	m.removes++
+	if m.failRemove[pid] {
+		return errors.New("remove failed")
+	}
+	delete(m.entries, pid)
+	return nil
+}
+
+func (m *fakeMap) List() (map[uint32]Task, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	out := make(map[uint32]Task, len(m.entries))
+	for pid, task := range m.entries {
+		out[pid] = task
+	}
+	return out, nil
+}
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestReconciler(alive map[uint32]bool, clock *testClock) *Reconciler {
+	return NewReconciler(Options{
+		ResyncInterval: 10 * time.Second,
+		ProcessAlive:   func(pid uint32) bool { return alive[pid] },
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Now:            clock.Now,
+	})
+}
+
+func TestReconcileConvergesMapToDesiredState(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	alive := map[uint32]bool{1: true, 2: true, 3: true, 4: true}
+	r := newTestReconciler(alive, clock)
+	m := newFakeMap()
+	m.entries[2] = Task{PID: 2, ExecutionTime: 5, Priority: 1}
+	m.entries[3] = Task{PID: 3, ExecutionTime: 7}
+
+	r.Apply([]Task{{PID: 1, ExecutionTime: 10, Priority: 2}, {PID: 2, ExecutionTime: 20, Priority: 1}}, nil)
+	if err := r.Reconcile(m); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+
+	if len(m.entries) != 2 || m.entries[1].ExecutionTime != 10 || m.entries[2].ExecutionTime != 20 {
+		t.Fatalf("map = %+v, want pids 1 and 2 with desired values", m.entries)
+	}
+	s := r.Stats()
+	if s.DriftMissing != 1 || s.DriftMismatch != 1 || s.DriftStale != 1 || s.OutOfSync != 0 || s.Desired != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	// A converged map needs no writes.
+	m.updates, m.removes = 0, 0
+	if err := r.Reconcile(m); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	if m.updates != 0 || m.removes != 0 {
+		t.Fatalf("expected no writes on a converged map, got %d updates and %d removes", m.updates, m.removes)
+	}
+}
+
+func TestReconcileGarbageCollectsExitedPIDs(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	alive := map[uint32]bool{1: true, 2: true}
+	r := newTestReconciler(alive, clock)
+	m := newFakeMap()
+
+	r.Apply([]Task{{PID: 1, ExecutionTime: 10}, {PID: 2, ExecutionTime: 20}}, nil)
+	if err := r.Reconcile(m); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+	alive[2] = false
+	m.entries[9] = Task{PID: 9}
+	if err := r.Reconcile(m); err != nil {
+		t.Fatalf("Reconcile() error: %v", err)
+	}
+
+	if _, ok := m.entries[2]; ok {
+		t.Fatalf("entry for exited pid 2 should be removed")
+	}
+	if _, ok := m.entries[9]; ok {
+		t.Fatalf("entry for unknown exited pid 9 should be removed")
+	}
+	s := r.Stats()
+	if s.ExitedPIDs != 2 || s.Desired != 1 || s.DriftStale != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestSyncRetriesFailedUpdatesWithBackoff(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	r := newTestReconciler(map[uint32]bool{1: true, 2: true}, clock)
+	m := newFakeMap()
+	m.failUpdate[2] = true
+
+	r.Apply([]Task{{PID: 1, ExecutionTime: 10}, {PID: 2, ExecutionTime: 20}}, nil)
+	ran, err := r.Sync(m)
+	if !ran || err == nil {
+		t.Fatalf("Sync() = %v, %v; want a pass reporting the failed update", ran, err)
+	}
+	if s := r.Stats(); s.OutOfSync != 1 || s.UpdateFailures != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	if ran, _ := r.Sync(m); ran {
+		t.Fatalf("Sync() should wait for the retry backoff")
+	}
+
+	m.failUpdate[2] = false
+	clock.now = clock.now.Add(minRetryBackoff)
+	ran, err = r.Sync(m)
+	if !ran || err != nil {
+		t.Fatalf("Sync() = %v, %v; want a successful retry", ran, err)
+	}
+	if m.entries[2].ExecutionTime != 20 || r.Stats().OutOfSync != 0 {
+		t.Fatalf("retry did not converge: map=%+v stats=%+v", m.entries, r.Stats())
+	}
+}
+
+func TestSyncRunsOnChangeAndResyncInterval(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	r := newTestReconciler(map[uint32]bool{1: true}, clock)
+	m := newFakeMap()
+
+	if ran, _ := r.Sync(m); !ran {
+		t.Fatalf("first Sync() should run")
+	}
+	if ran, _ := r.Sync(m); ran {
+		t.Fatalf("Sync() without changes should not run before the resync interval")
+	}
+
+	r.Apply([]Task{{PID: 1, ExecutionTime: 10}}, nil)
+	if ran, _ := r.Sync(m); !ran {
+		t.Fatalf("Sync() should run after a change")
+	}
+
+	// Out-of-band removal is only noticed by the periodic resync.
+	delete(m.entries, 1)
+	clock.now = clock.now.Add(10 * time.Second)
+	if ran, err := r.Sync(m); !ran || err != nil {
+		t.Fatalf("Sync() = %v, %v; want a resync pass", ran, err)
+	}
+	if _, ok := m.entries[1]; !ok {
+		t.Fatalf("resync should restore the missing entry")
+	}
+}
+
+func TestReconcileListFailure(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	r := newTestReconciler(map[uint32]bool{1: true, 2: true}, clock)
+	m := newFakeMap()
+	m.entries[2] = Task{PID: 2, ExecutionTime: 5}
+	m.listErr = errors.New("bpftool missing")
+
+	r.Apply([]Task{{PID: 1, ExecutionTime: 10}}, nil)
+	if err := r.Reconcile(m); err == nil {
+		t.Fatalf("expected list error")
+	}
+	if r.Stats().ListFailures != 1 {
+		t.Fatalf("expected one list failure, got %+v", r.Stats())
+	}
+	if got := m.entries[1]; got.ExecutionTime != 10 {
+		t.Fatalf("desired entries must still be written when listing fails, got %+v", m.entries)
+	}
+	if m.removes != 0 {
+		t.Fatalf("stale entries cannot be told apart without a listing, removes=%d", m.removes)
+	}
+
+	// The retry lists again and removes the stale entry.
+	m.listErr = nil
+	clock.now = clock.now.Add(time.Second)
+	if ran, err := r.Sync(m); !ran || err != nil {
+		t.Fatalf("expected the retry to run cleanly: ran=%v err=%v", ran, err)
+	}
+	if _, ok := m.entries[2]; ok {
+		t.Fatalf("retry should remove the stale entry")
+	}
+}
@@ -3,8 +3,10 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -15,7 +17,10 @@ import (
 	_ "net/http/pprof"
 
 	"github.com/Gthulhu/Gthulhu/internal/config"
+	"github.com/Gthulhu/Gthulhu/internal/scheduler/intentwatch"
 	"github.com/Gthulhu/Gthulhu/internal/scheduler/policy"
+	"github.com/Gthulhu/Gthulhu/internal/scheduler/prioritysync"
+	"github.com/Gthulhu/api/pkg/schedconfig"
 	"github.com/Gthulhu/plugin/plugin"
 	"github.com/Gthulhu/plugin/plugin/gthulhu"
 	core "github.com/Gthulhu/qumun/goland_core"
@@ -33,10 +38,17 @@ func Run(args []string) error {
 		fmt.Fprintf(os.Stdout, "Usage: %s [scheduler] [flags]\n", os.Args[0])
 		fmt.Fprintf(os.Stdout, "Default behavior is scheduler mode when no mode is specified.\n\n")
 		fs.PrintDefaults()
+		fmt.Fprintf(os.Stdout, "\nEvery configuration key can also be set with a GTHULHU_<PATH> environment variable (see -explain).\n")
+		fmt.Fprintf(os.Stdout, "Precedence: %s\n", config.Precedence)
 	}
 	configFile := fs.String("config", "", "Path to YAML configuration file")
 	showHelper := fs.Bool("help", false, "Show help message")
 	showExplain := fs.Bool("explain", false, "Explain configuration options")
+	validateOnly := fs.Bool("validate", false, "Validate the configuration file and exit")
+	strict := fs.Bool("strict", false, "Reject unknown configuration keys and invalid values instead of warning about invalid values (implied by -validate)")
+	printConfig := fs.Bool("print-config", false, "Print the effective configuration after defaults are merged (with -validate)")
+	var overrides config.Overrides
+	fs.Var(&overrides, "set", "Override a configuration key as path=value, e.g. -set scheduler.mode=simple (repeatable)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -51,16 +63,45 @@ func Run(args []string) error {
 		return nil
 	}
 
-	cfg, err := config.LoadConfig(*configFile)
+	if *validateOnly {
+		return validateConfig(os.Stdout, *configFile, overrides, *printConfig)
+	}
+
+	var cfg *config.Config
+	var err error
+	if *strict {
+		cfg, err = config.LoadConfigStrict(*configFile)
+	} else {
+		cfg, err = config.LoadConfig(*configFile)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if err := cfg.ApplyOverrides(os.Environ(), overrides); err != nil {
+		return fmt.Errorf("invalid configuration override: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		// Configurations that started before the validator existed keep
+		// starting; only -strict turns the problems into a failure.
+		if *strict {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+		warnInvalidConfig(err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	var prioritySync *prioritysync.Reconciler
+	if cfg.IsSchedulerEnabled() && cfg.Scheduler.KernelMode {
+		prioritySync = prioritysync.NewReconciler(prioritysync.Options{Logger: slog.Default()})
+	}
+
 	if cfg.IsMonitorEnabled() {
 		monCfg := buildMonitorConfig(cfg)
+		if prioritySync != nil {
+			monCfg.Collectors = append(monCfg.Collectors, prioritysync.NewCollector(prioritySync))
+		}
 		go func() {
 			slog.Info("starting scheduling monitor",
 				"bpfObject", monCfg.BPFObjectPath,
@@ -95,7 +136,8 @@ func Run(args []string) error {
 	sliceNsDefault = cfg.Scheduler.SliceNsDefault
 	sliceNsMin = cfg.Scheduler.SliceNsMin
 	slog.Info("Scheduler configuration", "SliceNsDefault", sliceNsDefault, "SliceNsMin", sliceNsMin)
-	pluginConfig := buildPluginConfig(cfg)
+	intentWatcher := startIntentWatcher(ctx, cfg)
+	pluginConfig := buildPluginConfig(cfg, intentWatcher != nil)
 	p, err = pluginFactory.New(ctx, pluginConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create plugin: %w", err)
@@ -199,7 +241,25 @@ func Run(args []string) error {
 						slog.Warn("json.Marshal failed", "error", err)
 					} else {
 						slog.Info("bss data", "data", string(b))
-						if cfg.Api.Enabled {
+						if cfg.Api.Enabled && intentWatcher != nil {
+							// The plugin's API client is not set up while
+							// the intents are watched.
+							if err := intentWatcher.SendMetrics(ctx, intentwatch.Metrics{
+								UserschedLastRunAt: bss.Usersched_last_run_at,
+								NrQueued:           bss.Nr_queued,
+								NrScheduled:        bss.Nr_scheduled,
+								NrRunning:          bss.Nr_running,
+								NrOnlineCpus:       bss.Nr_online_cpus,
+								NrUserDispatches:   bss.Nr_user_dispatches,
+								NrKernelDispatches: bss.Nr_kernel_dispatches,
+								NrCancelDispatches: bss.Nr_cancel_dispatches,
+								NrBounceDispatches: bss.Nr_bounce_dispatches,
+								NrFailedDispatches: bss.Nr_failed_dispatches,
+								NrSchedCongested:   bss.Nr_sched_congested,
+							}); err != nil {
+								slog.Warn("failed to send metrics", "error", err)
+							}
+						} else if cfg.Api.Enabled {
 							metricsData := gthulhu.BssData{
 								UserschedLastRunAt: bss.Usersched_last_run_at,
 								NrQueued:           bss.Nr_queued,
@@ -239,24 +299,36 @@ func Run(args []string) error {
 	}
 
 	if cfg.Scheduler.KernelMode {
+		priorityMap := bpfPriorityTaskMap{sched: bpfModule}
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
 		for {
-			changed, removed := p.GetChangedStrategies()
+			var revision uint64
+			if intentWatcher != nil {
+				revision = intentWatcher.Revision()
+			}
+			changed, removed := changedIntents(p, intentWatcher)
 			if len(changed) > 0 || len(removed) > 0 {
+				tasks := make([]prioritysync.Task, 0, len(changed))
 				for _, strategy := range changed {
-					err = bpfModule.UpdatePriorityTaskWithPrio(uint32(strategy.PID), strategy.ExecutionTime, uint32(strategy.Priority))
-					if err != nil {
-						slog.Warn("UpdatePriorityTaskWithPrio failed", "error", err, "pid", strategy.PID)
-					} else {
-						slog.Info("Updated priority task", "pid", strategy.PID, "executionTime", strategy.ExecutionTime, "priority", strategy.Priority)
-					}
+					tasks = append(tasks, prioritysync.Task{
+						PID:           uint32(strategy.PID),
+						ExecutionTime: strategy.ExecutionTime,
+						Priority:      uint32(strategy.Priority),
+					})
 				}
+				pids := make([]uint32, 0, len(removed))
 				for _, strategy := range removed {
-					err = bpfModule.RemovePriorityTask(uint32(strategy.PID))
-					if err != nil {
-						slog.Warn("RemovePriorityTask failed", "error", err, "pid", strategy.PID)
-					} else {
-						slog.Info("Removed priority task", "pid", strategy.PID)
-					}
+					pids = append(pids, uint32(strategy.PID))
+				}
+				prioritySync.Apply(tasks, pids)
+				slog.Info("Priority task intents changed", "changed", len(tasks), "removed", len(pids))
+			}
+			if _, err := prioritySync.Sync(priorityMap); err != nil {
+				slog.Warn("priority task reconcile incomplete, will retry", "error", err)
+			} else if intentWatcher != nil {
+				if err := intentWatcher.Ack(ctx, revision); err != nil {
+					slog.Warn("failed to acknowledge scheduling intents", "error", err)
 				}
 			}
 			if bpfModule.Stopped() {
@@ -272,13 +344,12 @@ func Run(args []string) error {
 			case <-ctx.Done():
 				slog.Info("context done, exiting kernel mode scheduler loop")
 				return nil
-			default:
+			case <-ticker.C:
 			}
-			time.Sleep(1 * time.Second)
 		}
 	}
 
-	if err = runSchedulerLoop(ctx, bpfModule, sliceNsDefault, sliceNsMin); err != nil {
+	if err = runSchedulerLoop(ctx, bpfModule, intentWatcher, sliceNsDefault, sliceNsMin); err != nil {
 		slog.Info("Scheduler loop exited with error", "error", err)
 		uei, err := bpfModule.GetUeiData()
 		if err == nil {
@@ -292,3 +363,45 @@ func Run(args []string) error {
 	slog.Info("scheduler exit")
 	return nil
 }
+
+// warnInvalidConfig logs every problem cfg.Validate found.
+func warnInvalidConfig(err error) {
+	var fieldErrs schedconfig.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		slog.Warn("invalid configuration, rerun with -strict to reject it", "error", err)
+		return
+	}
+	for _, fieldErr := range fieldErrs {
+		slog.Warn("invalid configuration value, rerun with -strict to reject it", "path", fieldErr.Path, "problem", fieldErr.Message)
+	}
+}
+
+// validateConfig implements -validate: it prints every problem found in
+// filename and the overrides and, on success, optionally the effective
+// configuration.
+func validateConfig(w io.Writer, filename string, overrides []string, printConfig bool) error {
+	if filename == "" {
+		return fmt.Errorf("-validate requires -config")
+	}
+	cfg, err := config.ValidateFile(filename, os.Environ(), overrides)
+	var fieldErrs schedconfig.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		fmt.Fprintf(w, "%s: %d error(s)\n", filename, len(fieldErrs))
+		for _, fieldErr := range fieldErrs {
+			fmt.Fprintf(w, "  %s\n", fieldErr)
+		}
+		return fmt.Errorf("configuration %s is invalid", filename)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s: configuration is valid\n", filename)
+	if printConfig {
+		out, err := cfg.EffectiveYAML()
+		if err != nil {
+			return fmt.Errorf("render effective configuration: %w", err)
+		}
+		_, _ = w.Write(out)
+	}
+	return nil
+}
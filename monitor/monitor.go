@@ -48,6 +48,9 @@ type Config struct {
 	NodeName              string
 	EnableCRDWatcher      bool
 	KubeConfigPath        string
+	// Collectors are extra Prometheus collectors (e.g. scheduler internals)
+	// exposed on the same /metrics endpoint.
+	Collectors []prometheus.Collector
 }
 
 // StartMonitor loads the eBPF monitor, starts the collector poll loop and
@@ -122,6 +125,9 @@ func StartMonitor(ctx context.Context, cfg Config, logger *slog.Logger) error {
 	reg.MustRegister(collector.NewPodSchedMetricsCollector(col))
 	reg.MustRegister(prometheus.NewGoCollector())
 	reg.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	for _, c := range cfg.Collectors {
+		reg.MustRegister(c)
+	}
 
 	// Prometheus HTTP server
 	port := cfg.PrometheusPort